@@ -31,6 +31,7 @@ type Client interface {
 	GetLoggerLevel(ctx context.Context, loggerName string, options ...rpc.Option) (map[string]LogAndDisplayLevels, error)
 	GetConfig(ctx context.Context, options ...rpc.Option) (interface{}, error)
 	DBGet(ctx context.Context, key []byte, options ...rpc.Option) ([]byte, error)
+	Checkpoint(ctx context.Context, dir string, options ...rpc.Option) (*CheckpointManifest, error)
 }
 
 // Client implementation for the Lux Platform Info API Endpoint
@@ -163,3 +164,11 @@ func (c *client) DBGet(ctx context.Context, key []byte, options ...rpc.Option) (
 	}
 	return formatting.Decode(formatting.HexNC, res.Value)
 }
+
+func (c *client) Checkpoint(ctx context.Context, dir string, options ...rpc.Option) (*CheckpointManifest, error) {
+	res := &CheckpointManifest{}
+	err := c.requester.SendRequest(ctx, "admin.checkpoint", &CheckpointArgs{
+		Dir: dir,
+	}, res, options...)
+	return res, err
+}
@@ -65,6 +65,9 @@ func (mc *mockClient) SendRequest(_ context.Context, _ string, _ interface{}, re
 	case *LoggerLevelReply:
 		response := mc.response.(*LoggerLevelReply)
 		*p = *response
+	case *CheckpointManifest:
+		response := mc.response.(*CheckpointManifest)
+		*p = *response
 	case *interface{}:
 		response := mc.response.(*interface{})
 		*p = *response
@@ -356,3 +359,33 @@ func TestGetConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckpoint(t *testing.T) {
+	t.Run("successful", func(t *testing.T) {
+		require := require.New(t)
+
+		expectedReply := &CheckpointManifest{
+			DBType: "pebbledb",
+			Native: true,
+			Chains: []CheckpointChain{
+				{
+					ChainID:            ids.GenerateTestID(),
+					Name:               "P",
+					LastAcceptedID:     ids.GenerateTestID(),
+					LastAcceptedHeight: 10,
+				},
+			},
+		}
+		mockClient := client{requester: NewMockClient(expectedReply, nil)}
+
+		reply, err := mockClient.Checkpoint(context.Background(), "dir")
+		require.NoError(err)
+		require.Equal(expectedReply, reply)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		mockClient := client{requester: NewMockClient(&CheckpointManifest{}, errTest)}
+		_, err := mockClient.Checkpoint(context.Background(), "dir")
+		require.ErrorIs(t, err, errTest)
+	})
+}
@@ -4,30 +4,45 @@
 package admin
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
 	"net/http"
+	"os"
 	"path"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/gorilla/rpc/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 
 	"github.com/skychains/chain/api"
 	"github.com/skychains/chain/api/server"
 	"github.com/skychains/chain/chains"
 	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/factory"
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/migrate"
 	"github.com/skychains/chain/database/rpcdb"
 	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/snow/engine/snowman/block"
 	"github.com/skychains/chain/utils"
 	"github.com/skychains/chain/utils/constants"
 	"github.com/skychains/chain/utils/formatting"
-	"github.com/skychains/chain/utils/json"
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/perms"
 	"github.com/skychains/chain/utils/profiler"
 	"github.com/skychains/chain/vms"
 	"github.com/skychains/chain/vms/registry"
 
+	avajson "github.com/skychains/chain/utils/json"
+
 	rpcdbpb "github.com/skychains/chain/proto/pb/rpcdb"
 )
 
@@ -36,19 +51,30 @@ const (
 
 	// Name of file that stacktraces are written to
 	stacktraceFile = "stacktrace.txt"
+
+	// Name of the file, in a checkpoint directory, that the checkpoint
+	// manifest is written to
+	checkpointManifestFile = "manifest.json"
 )
 
 var (
-	errAliasTooLong = errors.New("alias length is too long")
-	errNoLogLevel   = errors.New("need to specify either displayLevel or logLevel")
+	_ chains.Registrant = (*Admin)(nil)
+
+	errAliasTooLong        = errors.New("alias length is too long")
+	errNoLogLevel          = errors.New("need to specify either displayLevel or logLevel")
+	errNoCheckpointDir     = errors.New("need to specify a checkpoint directory")
+	errCheckpointDirExists = errors.New("checkpoint directory already exists")
 )
 
 type Config struct {
-	Log          logging.Logger
-	ProfileDir   string
-	LogFactory   logging.Factory
-	NodeConfig   interface{}
-	DB           database.Database
+	Log        logging.Logger
+	ProfileDir string
+	LogFactory logging.Factory
+	NodeConfig interface{}
+	DB         database.Database
+	// DBName is the type of [DB], used to describe checkpoints that are
+	// created natively by [DB].
+	DBName       string
 	ChainManager chains.Manager
 	HTTPServer   server.PathAdderWithReadLock
 	VMRegistry   registry.VMRegistry
@@ -60,22 +86,56 @@ type Admin struct {
 	Config
 	lock     sync.RWMutex
 	profiler profiler.Profiler
+
+	// checkpointLock is held while a checkpoint is being created to prevent
+	// concurrent checkpoints.
+	checkpointLock sync.Mutex
+
+	chainsLock sync.Mutex
+	// Key: Chain's ID
+	// Value: The chain
+	chains map[ids.ID]*registeredChain
+}
+
+type registeredChain struct {
+	name string
+	ctx  *snow.ConsensusContext
+	vm   block.ChainVM
 }
 
 // NewService returns a new admin API service.
 // All of the fields in [config] must be set.
 func NewService(config Config) (http.Handler, error) {
 	server := rpc.NewServer()
-	codec := json.NewCodec()
+	codec := avajson.NewCodec()
 	server.RegisterCodec(codec, "application/json")
 	server.RegisterCodec(codec, "application/json;charset=UTF-8")
-	return server, server.RegisterService(
-		&Admin{
-			Config:   config,
-			profiler: profiler.New(config.ProfileDir),
-		},
-		"admin",
-	)
+
+	admin := &Admin{
+		Config:   config,
+		profiler: profiler.New(config.ProfileDir),
+		chains:   make(map[ids.ID]*registeredChain),
+	}
+	config.ChainManager.AddRegistrant(admin)
+	return server, server.RegisterService(admin, "admin")
+}
+
+// RegisterChain tracks linear chains so that their last accepted blocks can be
+// recorded in checkpoint manifests.
+func (a *Admin) RegisterChain(chainName string, ctx *snow.ConsensusContext, vm common.VM) {
+	chainVM, ok := vm.(block.ChainVM)
+	if !ok {
+		return
+	}
+
+	a.chainsLock.Lock()
+	defer a.chainsLock.Unlock()
+
+	a.chains[ctx.ChainID] = &registeredChain{
+		name: chainName,
+		ctx:  ctx,
+		vm:   chainVM,
+	}
 }
 
 // StartCPUProfiler starts a cpu profile writing to the specified file
@@ -413,3 +473,204 @@ func (a *Admin) DbGet(_ *http.Request, args *DBGetArgs, reply *DBGetReply) error
 	reply.Value, err = formatting.Encode(formatting.HexNC, value)
 	return err
 }
+
+type CheckpointArgs struct {
+	// Dir is the directory to write the checkpoint into. It must not already
+	// exist.
+	Dir string `json:"dir"`
+}
+
+// CheckpointManifest describes the contents of a database checkpoint. It is
+// written into the checkpoint directory alongside the database files.
+type CheckpointManifest struct {
+	// Time is when the checkpoint was started.
+	Time time.Time `json:"time"`
+	// DBType is the type of database stored in the checkpoint. The checkpoint
+	// directory can be used as a network's database directory by a node
+	// started with this db-type.
+	DBType string `json:"dbType"`
+	// Native is true if the checkpoint was created by the database itself,
+	// rather than by copying a snapshot of its contents.
+	Native bool `json:"native"`
+	// Chains are the last accepted blocks of the linear chains running on
+	// this node. The checkpoint includes at least these blocks.
+	Chains []CheckpointChain `json:"chains"`
+	// ChainDBs are the databases of the chains that store their state in
+	// their own database. Like in a network's database directory, they are
+	// stored in the checkpoint directory under chains/[chainID].
+	ChainDBs []CheckpointChainDB `json:"chainDBs"`
+}
+
+type CheckpointChainDB struct {
+	ChainID ids.ID `json:"chainID"`
+	DBType  string `json:"dbType"`
+	Native  bool   `json:"native"`
+}
+
+type CheckpointChain struct {
+	ChainID            ids.ID         `json:"chainID"`
+	Name               string         `json:"name"`
+	LastAcceptedID     ids.ID         `json:"lastAcceptedID"`
+	LastAcceptedHeight avajson.Uint64 `json:"lastAcceptedHeight"`
+}
+
+// Checkpoint creates a consistent copy of the node's database, and of the
+// databases of isolated chains, while the node is running and writes a
+// manifest describing them.
+//
+// Each database is copied at a different point in time, but each is copied
+// after the last accepted blocks are recorded.
+func (a *Admin) Checkpoint(r *http.Request, args *CheckpointArgs, reply *CheckpointManifest) error {
+	a.Log.Debug("API called",
+		zap.String("service", "admin"),
+		zap.String("method", "checkpoint"),
+		logging.UserString("dir", args.Dir),
+	)
+
+	if len(args.Dir) == 0 {
+		return errNoCheckpointDir
+	}
+
+	a.checkpointLock.Lock()
+	defer a.checkpointLock.Unlock()
+
+	switch _, err := os.Stat(args.Dir); {
+	case err == nil:
+		return fmt.Errorf("%w: %s", errCheckpointDirExists, args.Dir)
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+	if err := os.MkdirAll(args.Dir, perms.ReadWriteExecute); err != nil {
+		return err
+	}
+
+	ctx := r.Context()
+	manifest := CheckpointManifest{
+		Time: time.Now(),
+	}
+
+	// The last accepted blocks are recorded before the checkpoint is created
+	// so that the checkpoint is guaranteed to contain them.
+	var err error
+	manifest.Chains, err = a.getLastAccepted(ctx)
+	if err != nil {
+		return err
+	}
+
+	manifest.DBType, manifest.Native, err = a.checkpoint(ctx, a.DB, a.DBName, args.Dir)
+	if err != nil {
+		return fmt.Errorf("couldn't create checkpoint: %w", err)
+	}
+
+	manifest.ChainDBs, err = a.checkpointChainDBs(ctx, args.Dir)
+	if err != nil {
+		return err
+	}
+
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	manifestPath := filepath.Join(args.Dir, checkpointManifestFile)
+	if err := perms.WriteFile(manifestPath, manifestBytes, perms.ReadWrite); err != nil {
+		return err
+	}
+
+	a.Log.Info("created database checkpoint",
+		zap.String("dir", args.Dir),
+		zap.String("dbType", manifest.DBType),
+		zap.Bool("native", manifest.Native),
+	)
+	*reply = manifest
+	return nil
+}
+
+// checkpointChainDBs writes a copy of the database of every isolated chain
+// into its directory under [dir].
+func (a *Admin) checkpointChainDBs(ctx context.Context, dir string) ([]CheckpointChainDB, error) {
+	chainDBs := a.ChainManager.IsolatedDBs()
+	checkpointChainDBs := make([]CheckpointChainDB, 0, len(chainDBs))
+	for chainID, chainDB := range chainDBs {
+		chainDir := filepath.Join(dir, chains.DBDirName, chainID.String())
+		if err := os.MkdirAll(chainDir, perms.ReadWriteExecute); err != nil {
+			return nil, err
+		}
+		dbType, native, err := a.checkpoint(ctx, chainDB.DB, chainDB.Name, chainDir)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create checkpoint of chain %s: %w", chainID, err)
+		}
+		checkpointChainDBs = append(checkpointChainDBs, CheckpointChainDB{
+			ChainID: chainID,
+			DBType:  dbType,
+			Native:  native,
+		})
+	}
+	return checkpointChainDBs, nil
+}
+
+// checkpoint writes a copy of [db], of type [dbName], into [dir] and returns
+// the type of database that was written and whether the database created the
+// checkpoint natively.
+//
+// If the database doesn't support native checkpoints, a snapshot of the
+// database is iterated over and copied into a new leveldb instance.
+func (a *Admin) checkpoint(ctx context.Context, db database.Database, dbName string, dir string) (string, bool, error) {
+	if checkpointer, ok := db.(database.Checkpointer); ok {
+		err := checkpointer.Checkpoint(factory.Path(dbName, dir))
+		if !errors.Is(err, errors.ErrUnsupported) {
+			return dbName, true, err
+		}
+	}
+
+	dst, err := leveldb.New(
+		factory.Path(leveldb.Name, dir),
+		nil,
+		a.Log,
+		prometheus.NewRegistry(),
+	)
+	if err != nil {
+		return "", false, err
+	}
+
+	// Iterators are guaranteed to provide a consistent view of the database,
+	// so the copy is a snapshot even though the database is concurrently
+	// being written to. The copy can't be verified against the database for
+	// the same reason.
+	err = migrate.Copy(ctx, a.Log, migrate.DefaultBatchSize, db, dst)
+	return leveldb.Name, false, errors.Join(err, dst.Close())
+}
+
+func (a *Admin) getLastAccepted(ctx context.Context) ([]CheckpointChain, error) {
+	a.chainsLock.Lock()
+	defer a.chainsLock.Unlock()
+
+	checkpointChains := make([]CheckpointChain, 0, len(a.chains))
+	for chainID, chain := range a.chains {
+		lastAcceptedID, height, err := getLastAccepted(ctx, chain)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get last accepted block of %s: %w", chain.name, err)
+		}
+		checkpointChains = append(checkpointChains, CheckpointChain{
+			ChainID:            chainID,
+			Name:               chain.name,
+			LastAcceptedID:     lastAcceptedID,
+			LastAcceptedHeight: avajson.Uint64(height),
+		})
+	}
+	return checkpointChains, nil
+}
+
+func getLastAccepted(ctx context.Context, chain *registeredChain) (ids.ID, uint64, error) {
+	chain.ctx.Lock.Lock()
+	defer chain.ctx.Lock.Unlock()
+
+	lastAcceptedID, err := chain.vm.LastAccepted(ctx)
+	if err != nil {
+		return ids.Empty, 0, err
+	}
+	lastAccepted, err := chain.vm.GetBlock(ctx, lastAcceptedID)
+	if err != nil {
+		return ids.Empty, 0, err
+	}
+	return lastAcceptedID, lastAccepted.Height(), nil
+}
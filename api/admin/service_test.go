@@ -4,12 +4,19 @@
 package admin
 
 import (
+	"encoding/json"
 	"net/http"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/skychains/chain/chains"
+	"github.com/skychains/chain/database/factory"
+	"github.com/skychains/chain/database/leveldb"
 	"github.com/skychains/chain/database/memdb"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils/formatting"
@@ -167,3 +174,94 @@ func TestServiceDBGet(t *testing.T) {
 		})
 	}
 }
+
+// isolatedDBsManager is a chain manager with isolated chain databases.
+type isolatedDBsManager struct {
+	chains.Manager
+	dbs map[ids.ID]chains.IsolatedDB
+}
+
+func (m *isolatedDBsManager) IsolatedDBs() map[ids.ID]chains.IsolatedDB {
+	return m.dbs
+}
+
+func TestServiceCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	chainID := ids.GenerateTestID()
+	chainDB := memdb.New()
+	a := &Admin{Config: Config{
+		Log:    logging.NoLog{},
+		DB:     memdb.New(),
+		DBName: memdb.Name,
+		ChainManager: &isolatedDBsManager{
+			Manager: chains.TestManager,
+			dbs: map[ids.ID]chains.IsolatedDB{
+				chainID: {
+					Name: memdb.Name,
+					DB:   chainDB,
+				},
+			},
+		},
+	}}
+
+	key := []byte("hello")
+	value := []byte("world")
+	require.NoError(a.DB.Put(key, value))
+
+	chainKey := []byte("chain")
+	chainValue := []byte("state")
+	require.NoError(chainDB.Put(chainKey, chainValue))
+
+	dir := filepath.Join(t.TempDir(), "checkpoint")
+	reply := &CheckpointManifest{}
+	require.NoError(a.Checkpoint(
+		&http.Request{},
+		&CheckpointArgs{
+			Dir: dir,
+		},
+		reply,
+	))
+	require.Equal(leveldb.Name, reply.DBType)
+	require.False(reply.Native)
+	require.Empty(reply.Chains)
+	require.Equal(
+		[]CheckpointChainDB{
+			{
+				ChainID: chainID,
+				DBType:  leveldb.Name,
+			},
+		},
+		reply.ChainDBs,
+	)
+
+	manifestBytes, err := os.ReadFile(filepath.Join(dir, checkpointManifestFile))
+	require.NoError(err)
+	manifest := CheckpointManifest{}
+	require.NoError(json.Unmarshal(manifestBytes, &manifest))
+	require.Equal(reply.DBType, manifest.DBType)
+
+	db, err := factory.New(leveldb.Name, dir, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+	gotValue, err := db.Get(key)
+	require.NoError(err)
+	require.Equal(value, gotValue)
+	require.NoError(db.Close())
+
+	chainDir := filepath.Join(dir, chains.DBDirName, chainID.String())
+	db, err = factory.New(leveldb.Name, chainDir, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+	gotValue, err = db.Get(chainKey)
+	require.NoError(err)
+	require.Equal(chainValue, gotValue)
+	require.NoError(db.Close())
+
+	err = a.Checkpoint(
+		&http.Request{},
+		&CheckpointArgs{
+			Dir: dir,
+		},
+		&CheckpointManifest{},
+	)
+	require.ErrorIs(err, errCheckpointDirExists)
+}
@@ -21,12 +21,15 @@ var _ keystore.BlockchainKeystore = (*Client)(nil)
 // Client is a snow.Keystore that talks over RPC.
 type Client struct {
 	client keystorepb.KeystoreClient
+	// keys derived from the passwords of the databases opened by this client
+	keys *encdb.KeyCache
 }
 
 // NewClient returns a keystore instance connected to a remote keystore instance
 func NewClient(client keystorepb.KeystoreClient) *Client {
 	return &Client{
 		client: client,
+		keys:   encdb.NewKeyCache(),
 	}
 }
 
@@ -35,7 +38,12 @@ func (c *Client) GetDatabase(username, password string) (*encdb.Database, error)
 	if err != nil {
 		return nil, err
 	}
-	return encdb.New([]byte(password), bcDB)
+	// Databases of users created before encdb stored its metadata are in the
+	// legacy format.
+	config := encdb.DefaultConfig
+	config.AllowLegacy = true
+	config.KeyCache = c.keys
+	return encdb.NewWithConfig([]byte(password), bcDB, config)
 }
 
 func (c *Client) GetRawDatabase(username, password string) (database.Database, error) {
@@ -99,6 +99,11 @@ type keystore struct {
 	// Value: The hash of that user's password
 	usernameToPassword map[string]*password.Hash
 
+	// Key: username
+	// Value: The keys derived from that user's password by the databases
+	// opened by the user
+	usernameToKeys map[string]*encdb.KeyCache
+
 	// Used to persist users and their data
 	userDB database.Database
 	bcDB   database.Database
@@ -108,6 +113,7 @@ func New(log logging.Logger, db database.Database) Keystore {
 	return &keystore{
 		log:                log,
 		usernameToPassword: make(map[string]*password.Hash),
+		usernameToKeys:     make(map[string]*encdb.KeyCache),
 		userDB:             prefixdb.New(usersPrefix, db),
 		bcDB:               prefixdb.New(bcsPrefix, db),
 	}
@@ -132,16 +138,28 @@ func (ks *keystore) NewBlockchainKeyStore(blockchainID ids.ID) BlockchainKeystor
 }
 
 func (ks *keystore) GetDatabase(bID ids.ID, username, password string) (*encdb.Database, error) {
-	bcDB, err := ks.GetRawDatabase(bID, username, password)
+	bcDB, keys, err := ks.getRawDatabase(bID, username, password)
 	if err != nil {
 		return nil, err
 	}
-	return encdb.New([]byte(password), bcDB)
+	// Databases of users created before encdb stored its metadata are in the
+	// legacy format.
+	config := encdb.DefaultConfig
+	config.AllowLegacy = true
+	config.KeyCache = keys
+	return encdb.NewWithConfig([]byte(password), bcDB, config)
 }
 
 func (ks *keystore) GetRawDatabase(bID ids.ID, username, pw string) (database.Database, error) {
+	bcDB, _, err := ks.getRawDatabase(bID, username, pw)
+	return bcDB, err
+}
+
+// getRawDatabase returns the database of [username] for [bID] along with the
+// cache of the keys derived from the user's password.
+func (ks *keystore) getRawDatabase(bID ids.ID, username, pw string) (database.Database, *encdb.KeyCache, error) {
 	if username == "" {
-		return nil, errEmptyUsername
+		return nil, nil, errEmptyUsername
 	}
 
 	ks.lock.Lock()
@@ -149,15 +167,21 @@ func (ks *keystore) GetRawDatabase(bID ids.ID, username, pw string) (database.Da
 
 	passwordHash, err := ks.getPassword(username)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if passwordHash == nil || !passwordHash.Check(pw) {
-		return nil, fmt.Errorf("%w: user %q", errIncorrectPassword, username)
+		return nil, nil, fmt.Errorf("%w: user %q", errIncorrectPassword, username)
+	}
+
+	keys, ok := ks.usernameToKeys[username]
+	if !ok {
+		keys = encdb.NewKeyCache()
+		ks.usernameToKeys[username] = keys
 	}
 
 	userDB := prefixdb.New([]byte(username), ks.bcDB)
 	bcDB := prefixdb.NewNested(bID[:], userDB)
-	return bcDB, nil
+	return bcDB, keys, nil
 }
 
 func (ks *keystore) CreateUser(username, pw string) error {
@@ -251,6 +275,7 @@ func (ks *keystore) DeleteUser(username, pw string) error {
 
 	// delete from users map.
 	delete(ks.usernameToPassword, username)
+	delete(ks.usernameToKeys, username)
 	return nil
 }
 
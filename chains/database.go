@@ -0,0 +1,359 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/api/metrics"
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/cachedb"
+	"github.com/skychains/chain/database/factory"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/database/migrate"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/database/versiondb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/vms/proposervm"
+	"github.com/skychains/chain/vms/rpcchainvm"
+)
+
+// DBDirName is the directory, under the node's database directory, that
+// isolated chains store their databases in.
+const DBDirName = "chains"
+
+var (
+	// IsolatedChainsDBPrefix is the prefix, of the node's database, that
+	// records which chains have had their state moved into their own
+	// database.
+	IsolatedChainsDBPrefix = []byte("isolated_chains")
+
+	errChainIsolated       = errors.New("chain state was moved into its own database")
+	errReadOnlyIsolation   = errors.New("can't move chain state into its own database when the database is read-only")
+	errUnmovableChainState = errors.New("can't move chain state into its own database")
+	errCacheNotIsolated    = errors.New("can't cache chain state that isn't in its own database")
+
+	// nestedStateVMs are the VMs, run in-process, that only create prefixdbs on
+	// top of a wrapper of the database they are initialized with, such as a
+	// versiondb. Because prefixdb only flattens prefixes of other prefixdbs,
+	// all of their state is stored under the chain's VM prefix.
+	nestedStateVMs = set.Of(
+		constants.PlatformVMID,
+		constants.AVMID,
+	)
+)
+
+// IsolatedDB is the database that an isolated chain stores its state in.
+type IsolatedDB struct {
+	// Name is the type of [DB].
+	Name string
+	DB   database.Database
+}
+
+// DatabaseConfig configures where a chain's state is stored.
+type DatabaseConfig struct {
+	// Isolated stores the chain's state in its own database, in its own
+	// directory, rather than in the node's database.
+	//
+	// When a chain is first isolated, any of its state that is stored in the
+	// node's database is moved into its own database. If the chain's VM runs
+	// in-process and may store state under prefixes that can't be located,
+	// the chain can only be isolated before it has any state. Once a chain has
+	// been isolated, it can't be moved back into the node's database.
+	Isolated bool `json:"isolated"`
+
+	// Name of the database type to store the chain's state in when it is
+	// isolated. Defaults to the node's database type.
+	Name string `json:"name"`
+
+	// Config of the database to store the chain's state in when it is
+	// isolated. Defaults to the node's database config if [Name] is the node's
+	// database type.
+	Config json.RawMessage `json:"config,omitempty"`
+
+	// Cache, if provided, caches the chain's state in memory. Its fields
+	// override the fields of cachedb.DefaultConfig.
+	//
+	// The cache's bloom filter is built from every key in the chain's
+	// database when the chain is created, so only isolated chains can be
+	// cached. The keys of a chain that is stored in the node's database can't
+	// be told apart from the keys of other chains.
+	Cache json.RawMessage `json:"cache,omitempty"`
+}
+
+// chainPrefixes returns the prefixes, of the node's database, that a chain's
+// consensus engines, the proposervm and the chain's VM store state under.
+//
+// Note: prefixdb flattens nested prefixes, so a VM that creates a prefixdb
+// directly on top of the database it is initialized with stores that state
+// under a prefix that can't be derived here. See keepsStateNested.
+func chainPrefixes(chainID ids.ID) [][]byte {
+	chainPrefix := prefixdb.MakePrefix(chainID[:])
+	vmPrefix := prefixdb.JoinPrefixes(chainPrefix, VMDBPrefix)
+	return [][]byte{
+		chainPrefix,
+		vmPrefix,
+		prefixdb.JoinPrefixes(vmPrefix, proposervm.DBPrefix),
+		prefixdb.JoinPrefixes(chainPrefix, VertexDBPrefix),
+		prefixdb.JoinPrefixes(chainPrefix, VertexBootstrappingDBPrefix),
+		prefixdb.JoinPrefixes(chainPrefix, TxBootstrappingDBPrefix),
+		prefixdb.JoinPrefixes(chainPrefix, BlockBootstrappingDBPrefix),
+		prefixdb.JoinPrefixes(chainPrefix, ChainBootstrappingDBPrefix),
+	}
+}
+
+// keepsStateNested returns true if all of [vm]'s state is known to be stored
+// under chainPrefixes.
+//
+// VMs served over rpcchainvm access their database through rpcdb, so every
+// prefixdb they create is nested inside of the chain's VM prefix.
+func keepsStateNested(vmID ids.ID, vm interface{}) bool {
+	if _, ok := vm.(*rpcchainvm.VMClient); ok {
+		return true
+	}
+	return nestedStateVMs.Contains(vmID)
+}
+
+// moveChainState moves the state of [chainID] from [nodeDB] into [chainDB].
+//
+// If the chain's VM doesn't keep its state nested, some of the chain's state
+// may be stored under prefixes that can't be derived. Moving the rest of its
+// state would leave the chain's state incomplete, so the chain's state is only
+// moved if it doesn't have any.
+func moveChainState(
+	ctx context.Context,
+	log logging.Logger,
+	nodeDB database.Database,
+	chainDB database.Database,
+	chainID ids.ID,
+	nested bool,
+) error {
+	prefixes := chainPrefixes(chainID)
+	if !nested {
+		for _, prefix := range prefixes {
+			hasState, err := hasPrefix(nodeDB, prefix)
+			if err != nil {
+				return err
+			}
+			if hasState {
+				return fmt.Errorf("%w: %s has state in the node's database and its VM may store state under unknown prefixes",
+					errUnmovableChainState,
+					chainID,
+				)
+			}
+		}
+	}
+
+	return migrate.MovePrefixes(
+		ctx,
+		log,
+		migrate.DefaultConfig,
+		nodeDB,
+		chainDB,
+		prefixes,
+	)
+}
+
+// hasPrefix returns true if [db] contains a key that starts with [prefix].
+func hasPrefix(db database.Iteratee, prefix []byte) (bool, error) {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	return it.Next(), it.Error()
+}
+
+// isIsolated returns true if [chainID] should store its state in its own
+// database.
+func (m *manager) isIsolated(chainID ids.ID, config DatabaseConfig) (bool, error) {
+	if config.Isolated || m.IsolatedChains.Contains(chainID.String()) {
+		return true, nil
+	}
+	aliases, err := m.Aliases(chainID)
+	if err != nil {
+		return false, err
+	}
+	for _, alias := range aliases {
+		if m.IsolatedChains.Contains(alias) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// openChainDB returns the database that [chainID] stores its state in. The
+// returned database is keyed the same way as the node's database, so the chain
+// must still prefix its state with its ID.
+//
+// If the chain is isolated, its own database is opened and any of its state
+// that is still stored in the node's database is moved into it. Because the
+// keys are unchanged, this only requires copying them. [nested] reports
+// whether the chain's VM keeps its state nested. See moveChainState.
+func (m *manager) openChainDB(chainID ids.ID, primaryAlias string, nested bool) (database.Database, error) {
+	chainConfig, err := m.getChainConfig(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching chain config: %w", err)
+	}
+
+	isolated, err := m.isIsolated(chainID, chainConfig.Database)
+	if err != nil {
+		return nil, err
+	}
+
+	isolatedChainsDB := prefixdb.New(IsolatedChainsDBPrefix, m.DB)
+	wasIsolated, err := isolatedChainsDB.Has(chainID[:])
+	if err != nil {
+		return nil, err
+	}
+	if !isolated {
+		if wasIsolated {
+			return nil, fmt.Errorf("%w: %s", errChainIsolated, chainID)
+		}
+		return m.DB, nil
+	}
+	if !wasIsolated && m.DBReadOnly {
+		return nil, fmt.Errorf("%w: %s", errReadOnlyIsolation, chainID)
+	}
+
+	dbName := chainConfig.Database.Name
+	if dbName == "" {
+		dbName = m.DBName
+	}
+	dbConfig := []byte(chainConfig.Database.Config)
+	if len(dbConfig) == 0 && dbName == m.DBName {
+		dbConfig = m.DBConfig
+	}
+
+	dbReg, err := metrics.MakeAndRegister(
+		m.dbGatherer,
+		primaryAlias,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	dbPath := filepath.Join(m.ChainDBDir, chainID.String())
+	db, err := factory.New(
+		dbName,
+		dbPath,
+		dbConfig,
+		m.Log,
+		dbReg,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !wasIsolated {
+		m.Log.Info("moving chain state into its own database",
+			zap.Stringer("chainID", chainID),
+			zap.String("path", dbPath),
+		)
+
+		err := moveChainState(
+			context.TODO(),
+			m.Log,
+			m.DB,
+			db,
+			chainID,
+			nested,
+		)
+		if err == nil {
+			err = isolatedChainsDB.Put(chainID[:], nil)
+		}
+		if err != nil {
+			return nil, errors.Join(
+				fmt.Errorf("couldn't move chain state: %w", err),
+				db.Close(),
+			)
+		}
+	}
+
+	m.chainDBsLock.Lock()
+	m.chainDBs[chainID] = IsolatedDB{
+		Name: dbName,
+		DB:   db,
+	}
+	m.chainDBsLock.Unlock()
+
+	if m.DBReadOnly && dbName != memdb.Name {
+		return versiondb.New(db), nil
+	}
+	return db, nil
+}
+
+// cacheChainDB wraps [db], the database that [chainID] stores its state in,
+// with a cache if the chain's database config provides one.
+//
+// The cache can't wrap the chain's prefixdb of the node's database, because
+// prefixdb only flattens the prefixes of other prefixdbs. The chain's state
+// would be stored under different keys depending on whether it is cached. So,
+// only the database of an isolated chain, which only holds the chain's keys,
+// is cached.
+func (m *manager) cacheChainDB(chainID ids.ID, primaryAlias string, db database.Database) (database.Database, error) {
+	chainConfig, err := m.getChainConfig(chainID)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching chain config: %w", err)
+	}
+	if len(chainConfig.Database.Cache) == 0 {
+		return db, nil
+	}
+
+	m.chainDBsLock.Lock()
+	_, isolated := m.chainDBs[chainID]
+	m.chainDBsLock.Unlock()
+	if !isolated {
+		return nil, fmt.Errorf("%w: %s", errCacheNotIsolated, chainID)
+	}
+
+	cacheConfig := cachedb.DefaultConfig
+	if err := json.Unmarshal(chainConfig.Database.Cache, &cacheConfig); err != nil {
+		return nil, fmt.Errorf("couldn't parse cache config: %w", err)
+	}
+	if err := cacheConfig.Verify(); err != nil {
+		return nil, fmt.Errorf("invalid cache config: %w", err)
+	}
+
+	cacheReg, err := metrics.MakeAndRegister(
+		m.cacheDBGatherer,
+		primaryAlias,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return cachedb.New(cacheReg, db, cacheConfig)
+}
+
+func (m *manager) IsolatedDBs() map[ids.ID]IsolatedDB {
+	m.chainDBsLock.Lock()
+	defer m.chainDBsLock.Unlock()
+
+	return maps.Clone(m.chainDBs)
+}
+
+// closeChainDBs closes every database opened by openChainDB. Must only be
+// called after every chain has been shut down.
+func (m *manager) closeChainDBs() {
+	m.chainDBsLock.Lock()
+	defer m.chainDBsLock.Unlock()
+
+	for chainID, db := range m.chainDBs {
+		if err := db.DB.Close(); err != nil {
+			m.Log.Warn("error during chain database shutdown",
+				zap.Stringer("chainID", chainID),
+				zap.Error(err),
+			)
+		}
+	}
+	clear(m.chainDBs)
+}
@@ -0,0 +1,170 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package chains
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/api/metrics"
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/cachedb"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/database/versiondb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/vms/proposervm"
+)
+
+var warpPrefix = []byte("warp")
+
+// newVMDB returns the database that the VM of [chainID] is initialized with
+// when the chain's state is stored in [db].
+func newVMDB(db database.Database, chainID ids.ID) *prefixdb.Database {
+	return prefixdb.New(VMDBPrefix, prefixdb.New(chainID[:], db))
+}
+
+func TestMoveChainState(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		nodeDB       = memdb.New()
+		chainDB      = memdb.New()
+		chainID      = ids.GenerateTestID()
+		otherChainID = ids.GenerateTestID()
+	)
+
+	// A VM that keeps its state nested creates its prefixdbs on top of a
+	// wrapper of its database.
+	vmDB := newVMDB(nodeDB, chainID)
+	versionDB := versiondb.New(vmDB)
+	require.NoError(prefixdb.New(warpPrefix, versionDB).Put([]byte("message"), []byte("warp")))
+	require.NoError(versionDB.Commit())
+	require.NoError(vmDB.Put([]byte("key"), []byte("vm")))
+	require.NoError(prefixdb.New(proposervm.DBPrefix, vmDB).Put([]byte("key"), []byte("proposervm")))
+
+	otherVMDB := newVMDB(nodeDB, otherChainID)
+	require.NoError(otherVMDB.Put([]byte("key"), []byte("other")))
+
+	require.NoError(moveChainState(
+		context.Background(),
+		logging.NoLog{},
+		nodeDB,
+		chainDB,
+		chainID,
+		true,
+	))
+
+	vmDB = newVMDB(chainDB, chainID)
+	value, err := prefixdb.New(warpPrefix, versiondb.New(vmDB)).Get([]byte("message"))
+	require.NoError(err)
+	require.Equal([]byte("warp"), value)
+
+	value, err = vmDB.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("vm"), value)
+
+	value, err = prefixdb.New(proposervm.DBPrefix, vmDB).Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("proposervm"), value)
+
+	// Only the other chain's state is left in the node's database.
+	numKeys, err := database.Count(nodeDB)
+	require.NoError(err)
+	require.Equal(1, numKeys)
+
+	value, err = otherVMDB.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("other"), value)
+}
+
+func TestMoveChainStateUnknownPrefixes(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		nodeDB  = memdb.New()
+		chainDB = memdb.New()
+		chainID = ids.GenerateTestID()
+	)
+
+	// Without existing state, the chain's state can always be moved.
+	require.NoError(moveChainState(
+		context.Background(),
+		logging.NoLog{},
+		nodeDB,
+		chainDB,
+		chainID,
+		false,
+	))
+
+	// A prefixdb created directly on top of the VM's database is flattened, so
+	// its state isn't stored under the chain's VM prefix.
+	vmDB := newVMDB(nodeDB, chainID)
+	require.NoError(prefixdb.New(warpPrefix, vmDB).Put([]byte("message"), []byte("warp")))
+	require.NoError(vmDB.Put([]byte("key"), []byte("vm")))
+
+	err := moveChainState(
+		context.Background(),
+		logging.NoLog{},
+		nodeDB,
+		chainDB,
+		chainID,
+		false,
+	)
+	require.ErrorIs(err, errUnmovableChainState)
+
+	// None of the chain's state was moved.
+	numKeys, err := database.Count(nodeDB)
+	require.NoError(err)
+	require.Equal(2, numKeys)
+
+	isEmpty, err := database.IsEmpty(chainDB)
+	require.NoError(err)
+	require.True(isEmpty)
+}
+
+func TestCacheChainDB(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		cachedChainID   = ids.GenerateTestID()
+		uncachedChainID = ids.GenerateTestID()
+		m               = &manager{
+			ManagerConfig: ManagerConfig{
+				ChainConfigs: map[string]ChainConfig{
+					cachedChainID.String(): {
+						Database: DatabaseConfig{
+							Isolated: true,
+							Cache:    json.RawMessage(`{}`),
+						},
+					},
+				},
+			},
+			cacheDBGatherer: metrics.NewLabelGatherer(ChainLabel),
+			chainDBs:        make(map[ids.ID]IsolatedDB),
+		}
+		db = memdb.New()
+	)
+
+	// A chain without a cache config isn't cached.
+	uncachedDB, err := m.cacheChainDB(uncachedChainID, "uncached", db)
+	require.NoError(err)
+	require.Equal(db, uncachedDB)
+
+	// The node's database can't be cached.
+	_, err = m.cacheChainDB(cachedChainID, "cached", db)
+	require.ErrorIs(err, errCacheNotIsolated)
+
+	m.chainDBs[cachedChainID] = IsolatedDB{
+		Name: memdb.Name,
+		DB:   db,
+	}
+	cachedDB, err := m.cacheChainDB(cachedChainID, "cached", db)
+	require.NoError(err)
+	require.IsType(&cachedb.Database{}, cachedDB)
+}
@@ -86,6 +86,8 @@ const (
 	p2pNamespace          = constants.PlatformName + metric.NamespaceSeparator + "p2p"
 	snowmanNamespace      = constants.PlatformName + metric.NamespaceSeparator + "snowman"
 	stakeNamespace        = constants.PlatformName + metric.NamespaceSeparator + "stake"
+	dbNamespace           = constants.PlatformName + metric.NamespaceSeparator + "chain_db"
+	cacheDBNamespace      = constants.PlatformName + metric.NamespaceSeparator + "cachedb"
 )
 
 var (
@@ -143,6 +145,10 @@ type Manager interface {
 	// Returns true iff the chain with the given ID exists and is finished bootstrapping
 	IsBootstrapped(ids.ID) bool
 
+	// Returns the databases of the chains that store their state in their own
+	// database
+	IsolatedDBs() map[ids.ID]IsolatedDB
+
 	// Starts the chain creator with the initial platform chain parameters, must
 	// be called once.
 	StartChainCreator(platformChain ChainParameters) error
@@ -176,9 +182,11 @@ type chain struct {
 // ChainConfig is configuration settings for the current execution.
 // [Config] is the user-provided config blob for the chain.
 // [Upgrade] is a chain-specific blob for coordinating upgrades.
+// [Database] configures where the chain's state is stored.
 type ChainConfig struct {
-	Config  []byte
-	Upgrade []byte
+	Config   []byte
+	Upgrade  []byte
+	Database DatabaseConfig
 }
 
 type ManagerConfig struct {
@@ -196,6 +204,11 @@ type ManagerConfig struct {
 	TxAcceptorGroup           snow.AcceptorGroup
 	VertexAcceptorGroup       snow.AcceptorGroup
 	DB                        database.Database
+	DBName                    string          // Type of [DB]
+	DBConfig                  []byte          // Config of [DB]
+	DBReadOnly                bool            // If true, writes to [DB] are discarded
+	ChainDBDir                string          // Directory isolated chains store their databases in
+	IsolatedChains            set.Set[string] // IDs or aliases of chains that store their state in their own database
 	MsgCreator                message.OutboundMsgBuilder // message creator, shared with network
 	Router                    router.Router              // Routes incoming messages to the appropriate chain
 	Net                       network.Network            // Sends consensus messages to other validators
@@ -281,6 +294,13 @@ type manager struct {
 	snowmanGatherer      metrics.MultiGatherer            // chainID
 	stakeGatherer        metrics.MultiGatherer            // chainID
 	vmGatherer           map[ids.ID]metrics.MultiGatherer // vmID -> chainID
+	dbGatherer           metrics.MultiGatherer            // chainID
+	cacheDBGatherer      metrics.MultiGatherer            // chainID
+
+	chainDBsLock sync.Mutex
+	// Key: Chain's ID
+	// Value: The chain's own database, if it is isolated
+	chainDBs map[ids.ID]IsolatedDB
 }
 
 // New returns a new Manager
@@ -325,6 +345,16 @@ func New(config *ManagerConfig) (Manager, error) {
 		return nil, err
 	}
 
+	dbGatherer := metrics.NewLabelGatherer(ChainLabel)
+	if err := config.Metrics.Register(dbNamespace, dbGatherer); err != nil {
+		return nil, err
+	}
+
+	cacheDBGatherer := metrics.NewLabelGatherer(ChainLabel)
+	if err := config.Metrics.Register(cacheDBNamespace, cacheDBGatherer); err != nil {
+		return nil, err
+	}
+
 	return &manager{
 		Aliaser:                ids.NewAliaser(),
 		ManagerConfig:          *config,
@@ -342,6 +372,9 @@ func New(config *ManagerConfig) (Manager, error) {
 		snowmanGatherer:      snowmanGatherer,
 		stakeGatherer:        stakeGatherer,
 		vmGatherer:           make(map[ids.ID]metrics.MultiGatherer),
+		dbGatherer:           dbGatherer,
+		cacheDBGatherer:      cacheDBGatherer,
+		chainDBs:             make(map[ids.ID]IsolatedDB),
 	}, nil
 }
 
@@ -565,6 +598,7 @@ func (m *manager) buildChain(chainParams ChainParameters, sb subnets.Subnet) (*c
 	case vertex.LinearizableVMWithEngine:
 		chain, err = m.createLuxChain(
 			ctx,
+			chainParams.VMID,
 			chainParams.GenesisData,
 			m.Validators,
 			vm,
@@ -582,6 +616,7 @@ func (m *manager) buildChain(chainParams ChainParameters, sb subnets.Subnet) (*c
 
 		chain, err = m.createSnowmanChain(
 			ctx,
+			chainParams.VMID,
 			chainParams.GenesisData,
 			m.Validators,
 			beacons,
@@ -611,6 +646,7 @@ func (m *manager) AddRegistrant(r Registrant) {
 // Create a DAG-based blockchain that uses Lux
 func (m *manager) createLuxChain(
 	ctx *snow.ConsensusContext,
+	vmID ids.ID,
 	genesisData []byte,
 	vdrs validators.Manager,
 	vm vertex.LinearizableVMWithEngine,
@@ -634,12 +670,22 @@ func (m *manager) createLuxChain(
 		return nil, err
 	}
 
-	meterDB, err := meterdb.New(meterDBReg, m.DB)
+	chainDB, err := m.openChainDB(ctx.ChainID, primaryAlias, keepsStateNested(vmID, vm))
+	if err != nil {
+		return nil, err
+	}
+
+	meterDB, err := meterdb.New(meterDBReg, chainDB)
 	if err != nil {
 		return nil, err
 	}
 
-	prefixDB := prefixdb.New(ctx.ChainID[:], meterDB)
+	cacheDB, err := m.cacheChainDB(ctx.ChainID, primaryAlias, meterDB)
+	if err != nil {
+		return nil, err
+	}
+
+	prefixDB := prefixdb.New(ctx.ChainID[:], cacheDB)
 	vmDB := prefixdb.New(VMDBPrefix, prefixDB)
 	vertexDB := prefixdb.New(VertexDBPrefix, prefixDB)
 	vertexBootstrappingDB := prefixdb.New(VertexBootstrappingDBPrefix, prefixDB)
@@ -1055,6 +1101,7 @@ func (m *manager) createLuxChain(
 // Create a linear chain using the Snowman consensus engine
 func (m *manager) createSnowmanChain(
 	ctx *snow.ConsensusContext,
+	vmID ids.ID,
 	genesisData []byte,
 	vdrs validators.Manager,
 	beacons validators.Manager,
@@ -1079,12 +1126,22 @@ func (m *manager) createSnowmanChain(
 		return nil, err
 	}
 
-	meterDB, err := meterdb.New(meterDBReg, m.DB)
+	chainDB, err := m.openChainDB(ctx.ChainID, primaryAlias, keepsStateNested(vmID, vm))
+	if err != nil {
+		return nil, err
+	}
+
+	meterDB, err := meterdb.New(meterDBReg, chainDB)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDB, err := m.cacheChainDB(ctx.ChainID, primaryAlias, meterDB)
 	if err != nil {
 		return nil, err
 	}
 
-	prefixDB := prefixdb.New(ctx.ChainID[:], meterDB)
+	prefixDB := prefixdb.New(ctx.ChainID[:], cacheDB)
 	vmDB := prefixdb.New(VMDBPrefix, prefixDB)
 	bootstrappingDB := prefixdb.New(ChainBootstrappingDBPrefix, prefixDB)
 
@@ -1525,6 +1582,7 @@ func (m *manager) Shutdown() {
 	close(m.chainCreatorShutdownCh)
 	m.chainCreatorExited.Wait()
 	m.ManagerConfig.Router.Shutdown(context.TODO())
+	m.closeChainDBs()
 }
 
 // LookupVM returns the ID of the VM associated with an alias
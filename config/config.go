@@ -44,15 +44,17 @@ import (
 	"github.com/skychains/chain/utils/storage"
 	"github.com/skychains/chain/utils/timer"
 	"github.com/skychains/chain/version"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/txs/fee"
 	"github.com/skychains/chain/vms/proposervm"
 )
 
 const (
-	chainConfigFileName  = "config"
-	chainUpgradeFileName = "upgrade"
-	subnetConfigFileExt  = ".json"
+	chainConfigFileName   = "config"
+	chainUpgradeFileName  = "upgrade"
+	chainDatabaseFileName = "database"
+	subnetConfigFileExt   = ".json"
 
 	keystoreDeprecationMsg = "keystore API is deprecated"
 )
@@ -779,6 +781,30 @@ func getTxFeeConfig(v *viper.Viper, networkID uint32) fee.StaticConfig {
 	return genesis.GetTxFeeConfig(networkID)
 }
 
+func getDynamicFeeConfig(v *viper.Viper, networkID uint32) (gas.Config, error) {
+	if networkID == constants.MainnetID || networkID == constants.FujiID {
+		return genesis.GetDynamicFeeConfig(networkID), nil
+	}
+
+	config := gas.Config{
+		Weights: gas.Dimensions{
+			gas.Bandwidth: v.GetUint64(DynamicFeesBandwidthWeightKey),
+			gas.DBRead:    v.GetUint64(DynamicFeesDBReadWeightKey),
+			gas.DBWrite:   v.GetUint64(DynamicFeesDBWriteWeightKey),
+			gas.Compute:   v.GetUint64(DynamicFeesComputeWeightKey),
+		},
+		MaxCapacity:              gas.Gas(v.GetUint64(DynamicFeesMaxGasCapacityKey)),
+		MaxPerSecond:             gas.Gas(v.GetUint64(DynamicFeesMaxGasPerSecondKey)),
+		TargetPerSecond:          gas.Gas(v.GetUint64(DynamicFeesTargetGasPerSecondKey)),
+		MinPrice:                 gas.Price(v.GetUint64(DynamicFeesMinGasPriceKey)),
+		ExcessConversionConstant: gas.Gas(v.GetUint64(DynamicFeesExcessConversionKey)),
+	}
+	if err := config.Verify(); err != nil {
+		return gas.Config{}, fmt.Errorf("invalid dynamic fee config: %w", err)
+	}
+	return config, nil
+}
+
 func getGenesisData(v *viper.Viper, networkID uint32, stakingCfg *genesis.StakingConfig) ([]byte, ids.ID, error) {
 	// try first loading genesis content directly from flag/env-var
 	if v.IsSet(GenesisFileContentKey) {
@@ -843,7 +869,9 @@ func getDatabaseConfig(v *viper.Viper, networkID uint32) (node.DatabaseConfig, e
 			GetExpandedArg(v, DBPathKey),
 			constants.NetworkName(networkID),
 		),
-		Config: configBytes,
+		Config:         configBytes,
+		MigrateFrom:    v.GetString(DBMigrateFromKey),
+		IsolatedChains: v.GetStringSlice(DBIsolatedChainsKey),
 	}, nil
 }
 
@@ -974,9 +1002,23 @@ func readChainConfigPath(chainConfigPath string) (map[string]chains.ChainConfig,
 			return chainConfigMap, err
 		}
 
+		// chainconfigdir/chainId/database.*
+		databaseData, err := storage.ReadFileWithName(chainDir, chainDatabaseFileName)
+		if err != nil {
+			return chainConfigMap, err
+		}
+
+		var databaseConfig chains.DatabaseConfig
+		if len(databaseData) != 0 {
+			if err := json.Unmarshal(databaseData, &databaseConfig); err != nil {
+				return chainConfigMap, fmt.Errorf("couldn't parse database config of %s: %w", dirInfo.Name(), err)
+			}
+		}
+
 		chainConfigMap[dirInfo.Name()] = chains.ChainConfig{
-			Config:  configData,
-			Upgrade: upgradeData,
+			Config:   configData,
+			Upgrade:  upgradeData,
+			Database: databaseConfig,
 		}
 	}
 	return chainConfigMap, nil
@@ -1330,6 +1372,11 @@ func GetNodeConfig(v *viper.Viper) (node.Config, error) {
 
 	// Tx Fee
 	nodeConfig.StaticConfig = getTxFeeConfig(v, nodeConfig.NetworkID)
+	nodeConfig.DynamicFeeConfig, err = getDynamicFeeConfig(v, nodeConfig.NetworkID)
+	if err != nil {
+		return node.Config{}, err
+	}
+	nodeConfig.SubnetOnlyValidatorFee = genesis.GetSubnetOnlyValidatorFee(nodeConfig.NetworkID)
 
 	// Genesis Data
 	genesisStakingCfg := nodeConfig.StakingConfig.StakingConfig
@@ -26,9 +26,10 @@ const chainConfigFilenameExtention = ".ex"
 
 func TestGetChainConfigsFromFiles(t *testing.T) {
 	tests := map[string]struct {
-		configs  map[string]string
-		upgrades map[string]string
-		expected map[string]chains.ChainConfig
+		configs   map[string]string
+		upgrades  map[string]string
+		databases map[string]string
+		expected  map[string]chains.ChainConfig
 	}{
 		"no chain configs": {
 			configs:  map[string]string{},
@@ -62,6 +63,21 @@ func TestGetChainConfigsFromFiles(t *testing.T) {
 				return m
 			}(),
 		},
+		"database config": {
+			configs:   map[string]string{"C": "hello"},
+			databases: map[string]string{"C": `{"isolated": true, "name": "pebbledb", "config": {"cacheSize": 1}}`},
+			expected: map[string]chains.ChainConfig{
+				"C": {
+					Config:  []byte("hello"),
+					Upgrade: []byte(nil),
+					Database: chains.DatabaseConfig{
+						Isolated: true,
+						Name:     "pebbledb",
+						Config:   []byte(`{"cacheSize": 1}`),
+					},
+				},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -80,6 +96,10 @@ func TestGetChainConfigsFromFiles(t *testing.T) {
 				chainDir := filepath.Join(chainsDir, key)
 				setupFile(t, chainDir, chainUpgradeFileName+chainConfigFilenameExtention, value)
 			}
+			for key, value := range test.databases {
+				chainDir := filepath.Join(chainsDir, key)
+				setupFile(t, chainDir, chainDatabaseFileName+chainConfigFilenameExtention, value)
+			}
 
 			v := setupViper(configFile)
 
@@ -205,6 +225,21 @@ func TestGetChainConfigsFromFlags(t *testing.T) {
 				return m
 			}(),
 		},
+		"database config": {
+			fullConfigs: map[string]chains.ChainConfig{
+				"C": {
+					Config:   []byte("hello"),
+					Database: chains.DatabaseConfig{Isolated: true, Config: []byte(`{"cacheSize":1}`)},
+				},
+			},
+			expected: map[string]chains.ChainConfig{
+				"C": {
+					Config:   []byte("hello"),
+					Upgrade:  []byte(nil),
+					Database: chains.DatabaseConfig{Isolated: true, Config: []byte(`{"cacheSize":1}`)},
+				},
+			},
+		},
 		"valid alias": {
 			fullConfigs: map[string]chains.ChainConfig{
 				"C": {Config: []byte("hello"), Upgrade: []byte("upgradess")},
@@ -24,6 +24,7 @@ import (
 	"github.com/skychains/chain/utils/dynamicip"
 	"github.com/skychains/chain/utils/ulimit"
 	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/vms/components/gas"
 )
 
 const (
@@ -108,6 +109,15 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.Uint64(AddPrimaryNetworkDelegatorFeeKey, genesis.LocalParams.AddPrimaryNetworkDelegatorFee, "Transaction fee, in nLUX, for transactions that add new primary network delegators")
 	fs.Uint64(AddSubnetValidatorFeeKey, genesis.LocalParams.AddSubnetValidatorFee, "Transaction fee, in nLUX, for transactions that add new subnet validators")
 	fs.Uint64(AddSubnetDelegatorFeeKey, genesis.LocalParams.AddSubnetDelegatorFee, "Transaction fee, in nLUX, for transactions that add new subnet delegators")
+	fs.Uint64(DynamicFeesBandwidthWeightKey, genesis.LocalParams.DynamicFeeConfig.Weights[gas.Bandwidth], "Complexity multiplier used to convert Bandwidth into Gas")
+	fs.Uint64(DynamicFeesDBReadWeightKey, genesis.LocalParams.DynamicFeeConfig.Weights[gas.DBRead], "Complexity multiplier used to convert DB Reads into Gas")
+	fs.Uint64(DynamicFeesDBWriteWeightKey, genesis.LocalParams.DynamicFeeConfig.Weights[gas.DBWrite], "Complexity multiplier used to convert DB Writes into Gas")
+	fs.Uint64(DynamicFeesComputeWeightKey, genesis.LocalParams.DynamicFeeConfig.Weights[gas.Compute], "Complexity multiplier used to convert Compute into Gas")
+	fs.Uint64(DynamicFeesMaxGasCapacityKey, uint64(genesis.LocalParams.DynamicFeeConfig.MaxCapacity), "Maximum amount of Gas the chain is allowed to store for future use")
+	fs.Uint64(DynamicFeesMaxGasPerSecondKey, uint64(genesis.LocalParams.DynamicFeeConfig.MaxPerSecond), "Rate at which Gas is stored for future use")
+	fs.Uint64(DynamicFeesTargetGasPerSecondKey, uint64(genesis.LocalParams.DynamicFeeConfig.TargetPerSecond), "Target rate of Gas usage")
+	fs.Uint64(DynamicFeesMinGasPriceKey, uint64(genesis.LocalParams.DynamicFeeConfig.MinPrice), "Minimum Gas price")
+	fs.Uint64(DynamicFeesExcessConversionKey, uint64(genesis.LocalParams.DynamicFeeConfig.ExcessConversionConstant), "Constant to convert excess Gas to the Gas price")
 
 	// Database
 	fs.String(DBTypeKey, leveldb.Name, fmt.Sprintf("Database type to use. Must be one of {%s, %s, %s}", leveldb.Name, memdb.Name, pebbledb.Name))
@@ -115,6 +125,8 @@ func addNodeFlags(fs *pflag.FlagSet) {
 	fs.String(DBPathKey, defaultDBDir, "Path to database directory")
 	fs.String(DBConfigFileKey, "", fmt.Sprintf("Path to database config file. Ignored if %s is specified", DBConfigContentKey))
 	fs.String(DBConfigContentKey, "", "Specifies base64 encoded database config content")
+	fs.String(DBMigrateFromKey, "", fmt.Sprintf("If set, the database of this type in the database directory is migrated into the %s database during startup. Must be one of {%s, %s}", DBTypeKey, leveldb.Name, pebbledb.Name))
+	fs.StringSlice(DBIsolatedChainsKey, nil, fmt.Sprintf("IDs or aliases of chains that store their state in their own database, in the database directory, rather than in the %s database. Any existing state of these chains is moved into their own database during startup. Chains whose in-process VM may store state under prefixes that can't be located, such as the C-Chain, can only be isolated before they have any state", DBTypeKey))
 
 	// Logging
 	fs.String(LogsDirKey, defaultLogDir, "Logging directory for Lux")
@@ -28,6 +28,15 @@ const (
 	AddPrimaryNetworkDelegatorFeeKey = "add-primary-network-delegator-fee"
 	AddSubnetValidatorFeeKey         = "add-subnet-validator-fee"
 	AddSubnetDelegatorFeeKey         = "add-subnet-delegator-fee"
+	DynamicFeesBandwidthWeightKey    = "dynamic-fees-bandwidth-weight"
+	DynamicFeesDBReadWeightKey       = "dynamic-fees-db-read-weight"
+	DynamicFeesDBWriteWeightKey      = "dynamic-fees-db-write-weight"
+	DynamicFeesComputeWeightKey      = "dynamic-fees-compute-weight"
+	DynamicFeesMaxGasCapacityKey     = "dynamic-fees-max-gas-capacity"
+	DynamicFeesMaxGasPerSecondKey    = "dynamic-fees-max-gas-per-second"
+	DynamicFeesTargetGasPerSecondKey = "dynamic-fees-target-gas-per-second"
+	DynamicFeesMinGasPriceKey        = "dynamic-fees-min-gas-price"
+	DynamicFeesExcessConversionKey   = "dynamic-fees-excess-conversion-constant"
 	UptimeRequirementKey             = "uptime-requirement"
 	MinValidatorStakeKey             = "min-validator-stake"
 	MaxValidatorStakeKey             = "max-validator-stake"
@@ -44,6 +53,8 @@ const (
 	DBPathKey                        = "db-dir"
 	DBConfigFileKey                  = "db-config-file"
 	DBConfigContentKey               = "db-config-file-content"
+	DBMigrateFromKey                 = "db-migrate-from"
+	DBIsolatedChainsKey              = "db-isolated-chains"
 	PublicIPKey                      = "public-ip"
 	PublicIPResolutionFreqKey        = "public-ip-resolution-frequency"
 	PublicIPResolutionServiceKey     = "public-ip-resolution-service"
@@ -7,13 +7,24 @@
 
 package database
 
-import "slices"
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var errRangeDeleteUnsupported = errors.New("range deletion is unsupported")
 
 // Batch is a write-only database that commits changes to its host database
 // when Write is called. A batch cannot be used concurrently.
 type Batch interface {
 	KeyValueWriterDeleter
 
+	// DeleteRange queues the removal of every key in the range
+	// [start, limit). Operations are applied in order, so keys written to the
+	// batch before DeleteRange are removed, while keys written after are not.
+	RangeDeleter
+
 	// Size retrieves the amount of data queued up for writing, this includes
 	// the keys, values, and deleted keys.
 	Size() int
@@ -26,6 +37,8 @@ type Batch interface {
 
 	// Replay replays the batch contents in the same order they were written
 	// to the batch.
+	//
+	// If the batch contains range deletions, [w] must implement RangeDeleter.
 	Replay(w KeyValueWriterDeleter) error
 
 	// Inner returns a Batch writing to the inner database, if one exists. If
@@ -45,6 +58,11 @@ type BatchOp struct {
 	Key    []byte
 	Value  []byte
 	Delete bool
+
+	// If DeleteRange is true, this op removes every key in the range
+	// [Key, Limit) and [Value] and [Delete] are ignored.
+	DeleteRange bool
+	Limit       []byte
 }
 
 type BatchOps struct {
@@ -70,6 +88,16 @@ func (b *BatchOps) Delete(key []byte) error {
 	return nil
 }
 
+func (b *BatchOps) DeleteRange(start, limit []byte) error {
+	b.Ops = append(b.Ops, BatchOp{
+		Key:         slices.Clone(start),
+		DeleteRange: true,
+		Limit:       slices.Clone(limit),
+	})
+	b.size += len(start) + len(limit)
+	return nil
+}
+
 func (b *BatchOps) Size() int {
 	return b.size
 }
@@ -81,13 +109,30 @@ func (b *BatchOps) Reset() {
 
 func (b *BatchOps) Replay(w KeyValueWriterDeleter) error {
 	for _, op := range b.Ops {
-		if op.Delete {
+		switch {
+		case op.DeleteRange:
+			if err := ReplayDeleteRange(w, op.Key, op.Limit); err != nil {
+				return err
+			}
+		case op.Delete:
 			if err := w.Delete(op.Key); err != nil {
 				return err
 			}
-		} else if err := w.Put(op.Key, op.Value); err != nil {
-			return err
+		default:
+			if err := w.Put(op.Key, op.Value); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
+
+// ReplayDeleteRange removes the range [start, limit) from [w] while replaying
+// a batch. An error is returned if [w] doesn't support range deletions.
+func ReplayDeleteRange(w KeyValueWriterDeleter, start, limit []byte) error {
+	rangeDeleter, ok := w.(RangeDeleter)
+	if !ok {
+		return fmt.Errorf("%w: %T", errRangeDeleteUnsupported, w)
+	}
+	return rangeDeleter.DeleteRange(start, limit)
+}
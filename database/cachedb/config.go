@@ -0,0 +1,65 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cachedb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skychains/chain/utils/units"
+)
+
+var (
+	DefaultConfig = Config{
+		CacheSize:                           64 * units.MiB,
+		BloomMinTargetElements:              1_000_000,
+		BloomTargetFalsePositiveProbability: .01,
+		BloomResetFalsePositiveProbability:  .05,
+	}
+
+	errInvalidCacheSize                = errors.New("invalid cache size")
+	errInvalidFalsePositiveProbability = errors.New("invalid false positive probability")
+)
+
+type Config struct {
+	// CacheSize is the maximum number of bytes of keys and values to cache.
+	// Lookups of keys that don't exist are cached as well.
+	CacheSize int `json:"cacheSize"`
+
+	// BloomMinTargetElements is the minimum number of keys that the bloom
+	// filter is sized for. The bloom filter grows beyond this if the database
+	// contains more keys.
+	BloomMinTargetElements int `json:"bloomMinTargetElements"`
+
+	// BloomTargetFalsePositiveProbability is the probability of the bloom
+	// filter reporting that a missing key may exist after it is built.
+	BloomTargetFalsePositiveProbability float64 `json:"bloomTargetFalsePositiveProbability"`
+
+	// BloomResetFalsePositiveProbability is the probability of the bloom
+	// filter reporting that a missing key may exist at which the bloom filter
+	// is rebuilt from the keys in the database.
+	BloomResetFalsePositiveProbability float64 `json:"bloomResetFalsePositiveProbability"`
+}
+
+func (c Config) Verify() error {
+	if c.CacheSize <= 0 {
+		return fmt.Errorf("%w: %d", errInvalidCacheSize, c.CacheSize)
+	}
+	if c.BloomTargetFalsePositiveProbability <= 0 || c.BloomTargetFalsePositiveProbability >= 1 {
+		return fmt.Errorf("%w: target=%f",
+			errInvalidFalsePositiveProbability,
+			c.BloomTargetFalsePositiveProbability,
+		)
+	}
+	// The bloom filter must be able to hold more keys than it is built with,
+	// otherwise it would be rebuilt after every write.
+	if c.BloomResetFalsePositiveProbability <= c.BloomTargetFalsePositiveProbability || c.BloomResetFalsePositiveProbability >= 1 {
+		return fmt.Errorf("%w: target=%f reset=%f",
+			errInvalidFalsePositiveProbability,
+			c.BloomTargetFalsePositiveProbability,
+			c.BloomResetFalsePositiveProbability,
+		)
+	}
+	return nil
+}
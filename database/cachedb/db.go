@@ -0,0 +1,482 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cachedb
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/skychains/chain/cache"
+	"github.com/skychains/chain/cache/metercacher"
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/bloom"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/maybe"
+)
+
+const (
+	methodLabel = "method"
+	resultLabel = "result"
+
+	// cacheResult is reported when a lookup is answered by the value cache.
+	cacheResult = "cache"
+	// bloomResult is reported when a lookup is answered by the bloom filter.
+	bloomResult = "bloom"
+	// dbResult is reported when a lookup is answered by the database.
+	dbResult = "db"
+)
+
+var (
+	_ database.Database     = (*Database)(nil)
+	_ database.Checkpointer = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
+	_ database.Batch        = (*batch)(nil)
+
+	lookupLabels  = []string{methodLabel, resultLabel}
+	hasCacheLabel = prometheus.Labels{
+		methodLabel: "has",
+		resultLabel: cacheResult,
+	}
+	hasBloomLabel = prometheus.Labels{
+		methodLabel: "has",
+		resultLabel: bloomResult,
+	}
+	hasDBLabel = prometheus.Labels{
+		methodLabel: "has",
+		resultLabel: dbResult,
+	}
+	getCacheLabel = prometheus.Labels{
+		methodLabel: "get",
+		resultLabel: cacheResult,
+	}
+	getBloomLabel = prometheus.Labels{
+		methodLabel: "get",
+		resultLabel: bloomResult,
+	}
+	getDBLabel = prometheus.Labels{
+		methodLabel: "get",
+		resultLabel: dbResult,
+	}
+)
+
+// Database caches the values of recently used keys, and keeps a bloom filter
+// of the keys in the underlying database, so that lookups of recently used
+// keys and of keys that don't exist rarely read from the underlying database.
+//
+// Writes are applied to the underlying database before they are applied to
+// the cache, so iterators and snapshots, which are served by the underlying
+// database, always observe the same state as Has and Get.
+//
+// The bloom filter is built by iterating over every key in the underlying
+// database when the Database is created. Because keys can't be removed from a
+// bloom filter, it is rebuilt once enough keys have been written to it that
+// its false positive probability exceeds the configured reset probability.
+type Database struct {
+	config Config
+
+	// lock is held for reading while reading from [db] and for writing while
+	// writing to [db], so that a value read from [db] can't be cached after a
+	// concurrent write has replaced it.
+	lock   sync.RWMutex
+	closed bool
+	db     database.Database
+
+	// values maps a key to its value, or to Nothing if the key doesn't exist.
+	values cache.Cacher[string, maybe.Maybe[[]byte]]
+
+	bloom         *bloom.Filter
+	bloomSalt     ids.ID
+	bloomMaxCount int
+	bloomMetrics  *bloom.Metrics
+
+	lookups *prometheus.CounterVec
+}
+
+// New returns a new cached database. Every key in [db] is iterated over to
+// build the bloom filter.
+func New(
+	reg prometheus.Registerer,
+	db database.Database,
+	config Config,
+) (*Database, error) {
+	if err := config.Verify(); err != nil {
+		return nil, err
+	}
+
+	values, err := metercacher.New[string, maybe.Maybe[[]byte]](
+		"cache",
+		reg,
+		cache.NewSizedLRU[string, maybe.Maybe[[]byte]](
+			config.CacheSize,
+			entrySize,
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	bloomMetrics, err := bloom.NewMetrics("bloom", reg)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheDB := &Database{
+		config:       config,
+		db:           db,
+		values:       values,
+		bloomMetrics: bloomMetrics,
+		lookups: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "lookups",
+				Help: "number of lookups of keys, by how they were answered",
+			},
+			lookupLabels,
+		),
+	}
+	if err := reg.Register(cacheDB.lookups); err != nil {
+		return nil, err
+	}
+	return cacheDB, cacheDB.resetBloom()
+}
+
+func entrySize(key string, value maybe.Maybe[[]byte]) int {
+	return len(key) + len(value.Value()) + constants.PointerOverhead
+}
+
+func (db *Database) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return false, database.ErrClosed
+	}
+	if value, ok := db.values.Get(string(key)); ok {
+		db.lookups.With(hasCacheLabel).Inc()
+		return value.HasValue(), nil
+	}
+	if !bloom.Contains(db.bloom, key, db.bloomSalt[:]) {
+		db.lookups.With(hasBloomLabel).Inc()
+		return false, nil
+	}
+
+	db.lookups.With(hasDBLabel).Inc()
+	has, err := db.db.Has(key)
+	if err == nil && !has {
+		db.values.Put(string(key), maybe.Nothing[[]byte]())
+	}
+	return has, err
+}
+
+func (db *Database) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	if value, ok := db.values.Get(string(key)); ok {
+		db.lookups.With(getCacheLabel).Inc()
+		if value.IsNothing() {
+			return nil, database.ErrNotFound
+		}
+		return slices.Clone(value.Value()), nil
+	}
+	if !bloom.Contains(db.bloom, key, db.bloomSalt[:]) {
+		db.lookups.With(getBloomLabel).Inc()
+		return nil, database.ErrNotFound
+	}
+
+	db.lookups.With(getDBLabel).Inc()
+	value, err := db.db.Get(key)
+	switch err {
+	case nil:
+		db.values.Put(string(key), maybe.Some(slices.Clone(value)))
+	case database.ErrNotFound:
+		db.values.Put(string(key), maybe.Nothing[[]byte]())
+	}
+	return value, err
+}
+
+func (db *Database) Put(key, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	// The key is added to the bloom filter before it is written so that the
+	// bloom filter never reports that an existing key doesn't exist.
+	db.addToBloom(key)
+	if err := db.db.Put(key, value); err != nil {
+		db.values.Evict(string(key))
+		return err
+	}
+	db.values.Put(string(key), maybe.Some(slices.Clone(value)))
+	db.resetBloomIfNeeded()
+	return nil
+}
+
+func (db *Database) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	if err := db.db.Delete(key); err != nil {
+		db.values.Evict(string(key))
+		return err
+	}
+	db.values.Put(string(key), maybe.Nothing[[]byte]())
+	return nil
+}
+
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	// The cache can't be searched by range, so it is flushed.
+	err := db.db.DeleteRange(start, limit)
+	db.values.Flush()
+	return err
+}
+
+func (db *Database) NewBatch() database.Batch {
+	return &batch{
+		batch: db.db.NewBatch(),
+		db:    db,
+	}
+}
+
+func (db *Database) NewIterator() database.Iterator {
+	return db.db.NewIterator()
+}
+
+func (db *Database) NewIteratorWithStart(start []byte) database.Iterator {
+	return db.db.NewIteratorWithStart(start)
+}
+
+func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return db.db.NewIteratorWithPrefix(prefix)
+}
+
+func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return db.db.NewIteratorWithStartAndPrefix(start, prefix)
+}
+
+func (db *Database) Compact(start, limit []byte) error {
+	return db.db.Compact(start, limit)
+}
+
+// Checkpoint creates a checkpoint of the underlying database if it supports
+// them. Otherwise, errors.ErrUnsupported is returned.
+func (db *Database) Checkpoint(dir string) error {
+	checkpointer, ok := db.db.(database.Checkpointer)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return checkpointer.Checkpoint(dir)
+}
+
+// NewSnapshot creates a snapshot of the underlying database if it supports
+// them. Otherwise, errors.ErrUnsupported is returned.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	snapshotter, ok := db.db.(database.Snapshotter)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return snapshotter.NewSnapshot()
+}
+
+func (db *Database) Close() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+	db.closed = true
+	db.values.Flush()
+	return db.db.Close()
+}
+
+func (db *Database) HealthCheck(ctx context.Context) (interface{}, error) {
+	return db.db.HealthCheck(ctx)
+}
+
+// addToBloom adds [key] to the bloom filter.
+//
+// Assumes [db.lock] is held for writing.
+func (db *Database) addToBloom(key []byte) {
+	bloom.Add(db.bloom, key, db.bloomSalt[:])
+	db.bloomMetrics.Count.Inc()
+}
+
+// resetBloomIfNeeded rebuilds the bloom filter if too many keys have been
+// added to it.
+//
+// Assumes [db.lock] is held for writing.
+func (db *Database) resetBloomIfNeeded() {
+	if db.bloom.Count() <= db.bloomMaxCount {
+		return
+	}
+
+	// If the bloom filter can't be rebuilt, the current bloom filter is kept.
+	// Its false positive probability is higher than desired, but it still
+	// never reports that an existing key doesn't exist, so it is safe to keep
+	// using it.
+	_ = db.resetBloom()
+}
+
+// resetBloom replaces the bloom filter with a bloom filter of every key in the
+// database.
+//
+// The bloom filter is sized for the number of keys in the database rather
+// than the number of keys added to the previous bloom filter, which also
+// counts overwritten and deleted keys.
+//
+// Assumes [db.lock] is held for writing, or that [db] is being created.
+func (db *Database) resetBloom() error {
+	numKeys, err := countKeys(db.db)
+	if err != nil {
+		return err
+	}
+
+	numHashes, numEntries := bloom.OptimalParameters(
+		max(db.config.BloomMinTargetElements, numKeys),
+		db.config.BloomTargetFalsePositiveProbability,
+	)
+	newBloom, err := bloom.New(numHashes, numEntries)
+	if err != nil {
+		return err
+	}
+	var newSalt ids.ID
+	if _, err := rand.Read(newSalt[:]); err != nil {
+		return err
+	}
+	if err := addKeys(db.db, newBloom, newSalt[:]); err != nil {
+		return err
+	}
+
+	maxCount := bloom.EstimateCount(numHashes, numEntries, db.config.BloomResetFalsePositiveProbability)
+	db.bloom = newBloom
+	db.bloomSalt = newSalt
+	db.bloomMaxCount = maxCount
+	db.bloomMetrics.Reset(newBloom, maxCount)
+	return nil
+}
+
+// countKeys returns the number of keys in [db].
+func countKeys(db database.Iteratee) (int, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	numKeys := 0
+	for it.Next() {
+		numKeys++
+	}
+	return numKeys, it.Error()
+}
+
+// addKeys adds every key in [db] to [filter].
+func addKeys(db database.Iteratee, filter *bloom.Filter, salt []byte) error {
+	it := db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		bloom.Add(filter, it.Key(), salt)
+	}
+	return it.Error()
+}
+
+type batch struct {
+	database.BatchOps
+
+	batch database.Batch
+	db    *Database
+}
+
+func (b *batch) Put(key, value []byte) error {
+	if err := b.batch.Put(key, value); err != nil {
+		return err
+	}
+	return b.BatchOps.Put(key, value)
+}
+
+func (b *batch) Delete(key []byte) error {
+	if err := b.batch.Delete(key); err != nil {
+		return err
+	}
+	return b.BatchOps.Delete(key)
+}
+
+func (b *batch) DeleteRange(start, limit []byte) error {
+	if err := b.batch.DeleteRange(start, limit); err != nil {
+		return err
+	}
+	return b.BatchOps.DeleteRange(start, limit)
+}
+
+func (b *batch) Size() int {
+	return b.batch.Size()
+}
+
+func (b *batch) Write() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+
+	for _, op := range b.Ops {
+		if !op.Delete && !op.DeleteRange {
+			b.db.addToBloom(op.Key)
+		}
+	}
+	if err := b.batch.Write(); err != nil {
+		// The batch may have been partially written.
+		b.db.values.Flush()
+		return err
+	}
+
+	for _, op := range b.Ops {
+		switch {
+		case op.DeleteRange:
+			b.db.values.Flush()
+		case op.Delete:
+			b.db.values.Put(string(op.Key), maybe.Nothing[[]byte]())
+		default:
+			b.db.values.Put(string(op.Key), maybe.Some(op.Value))
+		}
+	}
+	b.db.resetBloomIfNeeded()
+	return nil
+}
+
+func (b *batch) Reset() {
+	b.batch.Reset()
+	b.BatchOps.Reset()
+}
+
+func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
+	return b.batch.Replay(w)
+}
+
+// Inner returns itself because writes must go through the cache for it to
+// remain coherent with the underlying database.
+func (b *batch) Inner() database.Batch {
+	return b
+}
@@ -0,0 +1,256 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cachedb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+)
+
+func TestInterface(t *testing.T) {
+	for name, test := range database.Tests {
+		t.Run(name, func(t *testing.T) {
+			baseDB := memdb.New()
+			db, err := New(prometheus.NewRegistry(), baseDB, DefaultConfig)
+			require.NoError(t, err)
+
+			test(t, db)
+		})
+	}
+}
+
+func newDB(t testing.TB) database.Database {
+	baseDB := memdb.New()
+	db, err := New(prometheus.NewRegistry(), baseDB, DefaultConfig)
+	require.NoError(t, err)
+	return db
+}
+
+func FuzzKeyValue(f *testing.F) {
+	database.FuzzKeyValue(f, newDB(f))
+}
+
+func FuzzNewIteratorWithPrefix(f *testing.F) {
+	database.FuzzNewIteratorWithPrefix(f, newDB(f))
+}
+
+func FuzzNewIteratorWithStartAndPrefix(f *testing.F) {
+	database.FuzzNewIteratorWithStartAndPrefix(f, newDB(f))
+}
+
+func BenchmarkInterface(b *testing.B) {
+	for _, size := range database.BenchmarkSizes {
+		keys, values := database.SetupBenchmark(b, size[0], size[1], size[2])
+		for name, bench := range database.Benchmarks {
+			b.Run(fmt.Sprintf("cachedb_%d_pairs_%d_keys_%d_values_%s", size[0], size[1], size[2], name), func(b *testing.B) {
+				bench(b, newDB(b), keys, values)
+			})
+		}
+	}
+}
+
+func TestLookups(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	require.NoError(baseDB.Put([]byte("existing"), []byte("value")))
+
+	db, err := New(prometheus.NewRegistry(), baseDB, DefaultConfig)
+	require.NoError(err)
+
+	// Keys that existed before the cache was created must be reported.
+	value, err := db.Get([]byte("existing"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	require.Equal(1.0, testutil.ToFloat64(db.lookups.With(getDBLabel)))
+
+	value, err = db.Get([]byte("existing"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	require.Equal(1.0, testutil.ToFloat64(db.lookups.With(getCacheLabel)))
+
+	has, err := db.Has([]byte("missing"))
+	require.NoError(err)
+	require.False(has)
+	require.Equal(1.0, testutil.ToFloat64(db.lookups.With(hasBloomLabel)))
+
+	_, err = db.Get([]byte("missing"))
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Equal(1.0, testutil.ToFloat64(db.lookups.With(getBloomLabel)))
+	require.Zero(testutil.ToFloat64(db.lookups.With(hasDBLabel)))
+}
+
+func TestBatchCoherence(t *testing.T) {
+	require := require.New(t)
+
+	db := newDB(t)
+	require.NoError(db.Put([]byte("a"), []byte("1")))
+	require.NoError(db.Put([]byte("b"), []byte("2")))
+	require.NoError(db.Put([]byte("c"), []byte("3")))
+
+	// Populate the cache.
+	for _, key := range []string{"a", "b", "c", "d"} {
+		_, _ = db.Get([]byte(key))
+	}
+
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("a"), []byte("updated")))
+	require.NoError(batch.Delete([]byte("b")))
+	require.NoError(batch.Put([]byte("d"), []byte("4")))
+
+	// Writes aren't visible until the batch is written.
+	value, err := db.Get([]byte("a"))
+	require.NoError(err)
+	require.Equal([]byte("1"), value)
+	_, err = db.Get([]byte("d"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(batch.Write())
+
+	expected := map[string][]byte{
+		"a": []byte("updated"),
+		"c": []byte("3"),
+		"d": []byte("4"),
+	}
+	for _, key := range []string{"a", "b", "c", "d"} {
+		expectedValue, expectedHas := expected[key]
+
+		has, err := db.Has([]byte(key))
+		require.NoError(err)
+		require.Equal(expectedHas, has)
+
+		value, err := db.Get([]byte(key))
+		if !expectedHas {
+			require.ErrorIs(err, database.ErrNotFound)
+			continue
+		}
+		require.NoError(err)
+		require.Equal(expectedValue, value)
+	}
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	iterated := map[string][]byte{}
+	for it.Next() {
+		iterated[string(it.Key())] = it.Value()
+	}
+	require.NoError(it.Error())
+	require.Equal(expected, iterated)
+
+	// Range deletions must not leave stale values in the cache.
+	require.NoError(db.DeleteRange([]byte("a"), []byte("d")))
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := db.Get([]byte(key))
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+	value, err = db.Get([]byte("d"))
+	require.NoError(err)
+	require.Equal([]byte("4"), value)
+}
+
+func TestBloomReset(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig
+	config.CacheSize = 1
+	config.BloomMinTargetElements = 1
+	db, err := New(prometheus.NewRegistry(), memdb.New(), config)
+	require.NoError(err)
+
+	const numKeys = 1024
+	for i := 0; i < numKeys; i++ {
+		require.NoError(db.Put(database.PackUInt64(uint64(i)), nil))
+	}
+	require.Greater(testutil.ToFloat64(db.bloomMetrics.ResetCount), 1.0)
+
+	for i := 0; i < numKeys; i++ {
+		has, err := db.Has(database.PackUInt64(uint64(i)))
+		require.NoError(err)
+		require.True(has)
+	}
+}
+
+func TestBloomResetOverwrites(t *testing.T) {
+	require := require.New(t)
+
+	config := DefaultConfig
+	config.BloomMinTargetElements = 1
+	db, err := New(prometheus.NewRegistry(), memdb.New(), config)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), nil))
+	require.NoError(db.resetBloom())
+	maxCount := db.bloomMaxCount
+
+	// Overwriting a key adds it to the bloom filter again, but the bloom
+	// filter is only sized for the keys in the database.
+	for i := 0; i < 1024; i++ {
+		require.NoError(db.Put([]byte("key"), nil))
+	}
+	require.Greater(testutil.ToFloat64(db.bloomMetrics.ResetCount), 1.0)
+	require.Equal(maxCount, db.bloomMaxCount)
+}
+
+func TestConfigVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr error
+	}{
+		{
+			name:   "default",
+			modify: func(*Config) {},
+		},
+		{
+			name: "zero cache size",
+			modify: func(c *Config) {
+				c.CacheSize = 0
+			},
+			expectedErr: errInvalidCacheSize,
+		},
+		{
+			name: "zero target probability",
+			modify: func(c *Config) {
+				c.BloomTargetFalsePositiveProbability = 0
+			},
+			expectedErr: errInvalidFalsePositiveProbability,
+		},
+		{
+			name: "zero reset probability",
+			modify: func(c *Config) {
+				c.BloomResetFalsePositiveProbability = 0
+			},
+			expectedErr: errInvalidFalsePositiveProbability,
+		},
+		{
+			name: "reset probability equal to target probability",
+			modify: func(c *Config) {
+				c.BloomResetFalsePositiveProbability = c.BloomTargetFalsePositiveProbability
+			},
+			expectedErr: errInvalidFalsePositiveProbability,
+		},
+		{
+			name: "reset probability of one",
+			modify: func(c *Config) {
+				c.BloomResetFalsePositiveProbability = 1
+			},
+			expectedErr: errInvalidFalsePositiveProbability,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := DefaultConfig
+			test.modify(&config)
+			require.ErrorIs(t, config.Verify(), test.expectedErr)
+		})
+	}
+}
@@ -5,6 +5,7 @@ package corruptabledb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
@@ -12,8 +13,10 @@ import (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
+	_ database.Database    = (*Database)(nil)
+	_ database.Snapshotter = (*Database)(nil)
+	_ database.Snapshot    = (*snapshot)(nil)
+	_ database.Batch       = (*batch)(nil)
 )
 
 // CorruptableDB is a wrapper around Database
@@ -67,6 +70,13 @@ func (db *Database) Delete(key []byte) error {
 	return db.handleError(db.Database.Delete(key))
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	if err := db.corrupted(); err != nil {
+		return err
+	}
+	return db.handleError(db.Database.DeleteRange(start, limit))
+}
+
 func (db *Database) Compact(start []byte, limit []byte) error {
 	return db.handleError(db.Database.Compact(start, limit))
 }
@@ -117,6 +127,30 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	}
 }
 
+// NewSnapshot returns a snapshot of the underlying database if it supports
+// them. Otherwise, errors.ErrUnsupported is returned.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	if err := db.corrupted(); err != nil {
+		return nil, err
+	}
+	snapshotter, ok := db.Database.(database.Snapshotter)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	s, err := snapshotter.NewSnapshot()
+	if errors.Is(err, errors.ErrUnsupported) {
+		// Lack of support doesn't indicate a corruption.
+		return nil, err
+	}
+	if err != nil {
+		return nil, db.handleError(err)
+	}
+	return &snapshot{
+		Snapshot: s,
+		db:       db,
+	}, nil
+}
+
 func (db *Database) corrupted() error {
 	db.errorLock.RLock()
 	defer db.errorLock.RUnlock()
@@ -156,6 +190,55 @@ func (b *batch) Write() error {
 	return b.db.handleError(b.Batch.Write())
 }
 
+type snapshot struct {
+	database.Snapshot
+	db *Database
+}
+
+func (s *snapshot) Has(key []byte) (bool, error) {
+	if err := s.db.corrupted(); err != nil {
+		return false, err
+	}
+	has, err := s.Snapshot.Has(key)
+	return has, s.db.handleError(err)
+}
+
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	if err := s.db.corrupted(); err != nil {
+		return nil, err
+	}
+	value, err := s.Snapshot.Get(key)
+	return value, s.db.handleError(err)
+}
+
+func (s *snapshot) NewIterator() database.Iterator {
+	return &iterator{
+		Iterator: s.Snapshot.NewIterator(),
+		db:       s.db,
+	}
+}
+
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return &iterator{
+		Iterator: s.Snapshot.NewIteratorWithStart(start),
+		db:       s.db,
+	}
+}
+
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return &iterator{
+		Iterator: s.Snapshot.NewIteratorWithPrefix(prefix),
+		db:       s.db,
+	}
+}
+
+func (s *snapshot) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return &iterator{
+		Iterator: s.Snapshot.NewIteratorWithStartAndPrefix(start, prefix),
+		db:       s.db,
+	}
+}
+
 type iterator struct {
 	database.Iterator
 	db *Database
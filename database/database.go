@@ -49,6 +49,19 @@ type KeyValueDeleter interface {
 	Delete(key []byte) error
 }
 
+// RangeDeleter wraps the DeleteRange method of a backing data store.
+type RangeDeleter interface {
+	// DeleteRange removes every key in the range [start, limit) from the
+	// key-value data store.
+	//
+	// An empty [limit] is treated as a key after all keys in the data store.
+	// If [start] >= [limit], no keys are removed.
+	//
+	// Note: [start] and [limit] are safe to modify and read after calling
+	// DeleteRange.
+	DeleteRange(start, limit []byte) error
+}
+
 // KeyValueReaderWriter allows read/write acccess to a backing data store.
 type KeyValueReaderWriter interface {
 	KeyValueReader
@@ -84,10 +97,47 @@ type Compacter interface {
 	Compact(start []byte, limit []byte) error
 }
 
+// Checkpointer is implemented by data stores that can natively create a
+// consistent, point-in-time copy of themselves while they are in use.
+type Checkpointer interface {
+	// Checkpoint writes a copy of the data store into [dir], which must not
+	// already exist. The copy can be opened as a data store of the same type.
+	//
+	// If the data store doesn't support checkpoints, errors.ErrUnsupported
+	// is returned.
+	Checkpoint(dir string) error
+}
+
+// Snapshot is a read-only view of a data store frozen at the point in time
+// that it was created. Writes to the data store after the snapshot was
+// created are not visible through the snapshot.
+type Snapshot interface {
+	KeyValueReader
+	Iteratee
+
+	// Release frees the resources held by the snapshot. Reads from the
+	// snapshot after Release is called return ErrClosed.
+	//
+	// Release may be called multiple times.
+	Release()
+}
+
+// Snapshotter is implemented by data stores that can provide a consistent
+// view of their contents across multiple reads.
+type Snapshotter interface {
+	// NewSnapshot returns a snapshot of the current contents of the data
+	// store. The snapshot must be released once it is no longer needed.
+	//
+	// If the data store doesn't support snapshots, errors.ErrUnsupported is
+	// returned.
+	NewSnapshot() (Snapshot, error)
+}
+
 // Database contains all the methods required to allow handling different
 // key-value data stores backing the database.
 type Database interface {
 	KeyValueReaderWriterDeleter
+	RangeDeleter
 	Batcher
 	Iteratee
 	Compacter
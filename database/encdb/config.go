@@ -0,0 +1,120 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// DefaultKDFParams follow the second recommended option of RFC 9106.
+	DefaultKDFParams = KDFParams{
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+
+	DefaultConfig = Config{
+		KeyMode: KeyModePlaintext,
+		KDF:     DefaultKDFParams,
+	}
+
+	errUnknownKeyMode      = errors.New("unknown key mode")
+	errInvalidKDFParams    = errors.New("invalid kdf params")
+	errInvalidPrefixLength = errors.New("invalid prefix length")
+)
+
+// KeyMode determines how keys are written to the underlying database.
+type KeyMode byte
+
+const (
+	// KeyModePlaintext stores keys as provided. Only values are encrypted.
+	// Iteration is served directly by the underlying database.
+	KeyModePlaintext KeyMode = iota
+
+	// KeyModeHMAC replaces every key with its HMAC and stores the original key
+	// encrypted alongside its value. Ordered and prefixed iteration is still
+	// supported, but requires decrypting every entry that may match the
+	// iterator and sorting them in memory.
+	//
+	// Keys that share the same first [Config.PrefixLength] bytes are stored
+	// next to each other. This allows iterators over a prefix that is at least
+	// [Config.PrefixLength] bytes long, and range deletions whose bounds share
+	// such a prefix, to only decrypt the entries sharing that prefix, at the
+	// cost of revealing which keys share it. Other iterators and range
+	// deletions decrypt every entry in the database.
+	KeyModeHMAC
+)
+
+func (m KeyMode) String() string {
+	switch m {
+	case KeyModePlaintext:
+		return "plaintext"
+	case KeyModeHMAC:
+		return "hmac"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(m))
+	}
+}
+
+// KDFParams are the argon2id parameters used to derive encryption keys from a
+// password.
+type KDFParams struct {
+	// Number of passes over the memory.
+	Time uint32 `serialize:"true" json:"time"`
+	// Memory used, in KiB.
+	Memory uint32 `serialize:"true" json:"memory"`
+	// Number of threads used.
+	Threads uint8 `serialize:"true" json:"threads"`
+}
+
+func (p KDFParams) Verify() error {
+	if p.Time == 0 || p.Threads == 0 || p.Memory < 8*uint32(p.Threads) {
+		return fmt.Errorf("%w: time=%d memory=%d threads=%d",
+			errInvalidKDFParams,
+			p.Time,
+			p.Memory,
+			p.Threads,
+		)
+	}
+	return nil
+}
+
+// Config is only used when initializing a new database, with the exception of
+// [AllowLegacy] and [KeyCache]. Existing databases keep the configuration they
+// were created with.
+type Config struct {
+	KeyMode KeyMode `json:"keyMode"`
+	// PrefixLength is the number of leading key bytes that are grouped
+	// together when [KeyMode] is KeyModeHMAC. It must be non-zero with
+	// KeyModeHMAC.
+	PrefixLength uint32    `json:"prefixLength"`
+	KDF          KDFParams `json:"kdf"`
+
+	// AllowLegacy opens a populated database that has no metadata in the
+	// legacy format. Otherwise, such a database is rejected, as removing the
+	// metadata of an encrypted database would downgrade its encryption.
+	AllowLegacy bool `json:"allowLegacy"`
+
+	// KeyCache, if provided, is used to avoid deriving the keys of a database
+	// that is opened repeatedly with the same password.
+	KeyCache *KeyCache `json:"-"`
+}
+
+func (c Config) Verify() error {
+	switch c.KeyMode {
+	case KeyModePlaintext:
+		if c.PrefixLength != 0 {
+			return fmt.Errorf("%w: %d with key mode %s", errInvalidPrefixLength, c.PrefixLength, c.KeyMode)
+		}
+	case KeyModeHMAC:
+		if c.PrefixLength == 0 {
+			return fmt.Errorf("%w: %d with key mode %s", errInvalidPrefixLength, c.PrefixLength, c.KeyMode)
+		}
+	default:
+		return fmt.Errorf("%w: %s", errUnknownKeyMode, c.KeyMode)
+	}
+	return c.KDF.Verify()
+}
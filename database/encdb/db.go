@@ -4,16 +4,14 @@
 package encdb
 
 import (
+	"bytes"
 	"context"
-	"crypto/cipher"
-	"crypto/rand"
 	"slices"
 	"sync"
 
-	"golang.org/x/crypto/chacha20poly1305"
-
 	"github.com/skychains/chain/database"
-	"github.com/skychains/chain/utils/hashing"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/utils/set"
 )
 
 var (
@@ -22,22 +20,88 @@ var (
 	_ database.Iterator = (*iterator)(nil)
 )
 
-// Database encrypts all values that are provided
+// Database encrypts all values that are provided. Depending on its
+// configuration, keys may also be protected.
 type Database struct {
 	lock   sync.RWMutex
-	cipher cipher.AEAD
+	format *format
 	db     database.Database
 	closed bool
 }
 
-// New returns a new encrypted database
+// New returns a new encrypted database. If [db] is empty, it is initialized
+// with DefaultConfig.
 func New(password []byte, db database.Database) (*Database, error) {
-	h := hashing.ComputeHash256(password)
-	aead, err := chacha20poly1305.NewX(h)
+	return NewWithConfig(password, db, DefaultConfig)
+}
+
+// NewWithConfig returns a new encrypted database. If [db] is empty, it is
+// initialized with [config]. Otherwise, [db] is opened with the configuration
+// it was initialized with.
+//
+// If [config.AllowLegacy] is set, legacy databases are opened in the legacy
+// format and can be converted to the current format with Upgrade.
+//
+// Returns ErrIncorrectPassword if [password] doesn't match the password of
+// [db], ErrPasswordChangeInProgress if a call to ChangePassword must be
+// completed before [db] can be used and ErrMissingMetadata if [db] is
+// populated without metadata and legacy databases aren't allowed.
+func NewWithConfig(password []byte, db database.Database, config Config) (*Database, error) {
+	f, err := openFormat(password, db, config)
+	if err != nil {
+		return nil, err
+	}
 	return &Database{
-		cipher: aead,
+		format: f,
 		db:     db,
-	}, err
+	}, nil
+}
+
+func openFormat(password []byte, db database.Database, config Config) (*format, error) {
+	md, err := getMetadata(db)
+	if err != nil {
+		return nil, err
+	}
+	if md != nil {
+		if md.HasPending {
+			return nil, ErrPasswordChangeInProgress
+		}
+		s, err := config.KeyCache.open(md.Current, password)
+		if err != nil {
+			return nil, err
+		}
+		return newFormat(md, s), nil
+	}
+
+	isEmpty, err := database.IsEmpty(db)
+	if err != nil {
+		return nil, err
+	}
+	if !isEmpty {
+		if !config.AllowLegacy {
+			return nil, ErrMissingMetadata
+		}
+		return newLegacyFormat(password)
+	}
+
+	if err := config.Verify(); err != nil {
+		return nil, err
+	}
+	params, s, err := newKeyParams(password, 0, config.KDF)
+	if err != nil {
+		return nil, err
+	}
+	config.KeyCache.put(params, password, s)
+	md = &metadata{
+		Version:      formatVersion,
+		KeyMode:      config.KeyMode,
+		PrefixLength: config.PrefixLength,
+		Current:      params,
+	}
+	if err := putMetadata(db, md); err != nil {
+		return nil, err
+	}
+	return newFormat(md, s), nil
 }
 
 func (db *Database) Has(key []byte) (bool, error) {
@@ -47,7 +111,7 @@ func (db *Database) Has(key []byte) (bool, error) {
 	if db.closed {
 		return false, database.ErrClosed
 	}
-	return db.db.Has(key)
+	return db.db.Has(db.format.storedKey(key))
 }
 
 func (db *Database) Get(key []byte) ([]byte, error) {
@@ -57,11 +121,13 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 	if db.closed {
 		return nil, database.ErrClosed
 	}
-	encVal, err := db.db.Get(key)
+	storedKey := db.format.storedKey(key)
+	encVal, err := db.db.Get(storedKey)
 	if err != nil {
 		return nil, err
 	}
-	return db.decrypt(encVal)
+	_, value, err := db.format.open(storedKey, encVal)
+	return value, err
 }
 
 func (db *Database) Put(key, value []byte) error {
@@ -72,11 +138,12 @@ func (db *Database) Put(key, value []byte) error {
 		return database.ErrClosed
 	}
 
-	encValue, err := db.encrypt(value)
+	storedKey := db.format.storedKey(key)
+	encValue, err := db.format.seal(storedKey, key, value)
 	if err != nil {
 		return err
 	}
-	return db.db.Put(key, encValue)
+	return db.db.Put(storedKey, encValue)
 }
 
 func (db *Database) Delete(key []byte) error {
@@ -86,7 +153,67 @@ func (db *Database) Delete(key []byte) error {
 	if db.closed {
 		return database.ErrClosed
 	}
-	return db.db.Delete(key)
+	return db.db.Delete(db.format.storedKey(key))
+}
+
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	if db.format.ordered() {
+		storedStart, storedLimit := db.storedRange(start, limit)
+		return db.db.DeleteRange(storedStart, storedLimit)
+	}
+
+	storedKeys, err := db.storedKeysInRange(start, limit)
+	if err != nil {
+		return err
+	}
+	batch := db.db.NewBatch()
+	for _, storedKey := range storedKeys {
+		if err := batch.Delete(storedKey); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// storedRange returns the range of stored keys that correspond to the range
+// of keys [start, limit).
+//
+// Assumes the entries are ordered.
+func (db *Database) storedRange(start, limit []byte) ([]byte, []byte) {
+	prefix := db.format.prefix()
+	if len(limit) == 0 {
+		return prefixed(prefix, start), db.format.limit()
+	}
+	return prefixed(prefix, start), prefixed(prefix, limit)
+}
+
+// storedKeysInRange returns the stored keys of every entry with a key in the
+// range [start, limit).
+//
+// Assumes [db.lock] is held.
+func (db *Database) storedKeysInRange(start, limit []byte) ([][]byte, error) {
+	it := db.db.NewIteratorWithPrefix(db.format.rangeBucket(start, limit))
+	defer it.Release()
+
+	var storedKeys [][]byte
+	for it.Next() {
+		storedKey := it.Key()
+		key, _, err := db.format.open(storedKey, it.Value())
+		if err != nil {
+			return nil, err
+		}
+		if database.InRange(key, start, limit) {
+			storedKeys = append(storedKeys, slices.Clone(storedKey))
+		}
+	}
+	return storedKeys, it.Error()
 }
 
 func (db *Database) NewBatch() database.Batch {
@@ -117,9 +244,53 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 			Err: database.ErrClosed,
 		}
 	}
+
+	if db.format.ordered() {
+		storedPrefix := db.format.prefix()
+		return &iterator{
+			Iterator: db.db.NewIteratorWithStartAndPrefix(
+				prefixed(storedPrefix, start),
+				prefixed(storedPrefix, prefix),
+			),
+			db: db,
+		}
+	}
+
+	// Entries aren't stored in key order, so every entry that may match is
+	// decrypted and sorted in memory.
+	bucket := dataPrefix
+	if db.format.prefixLength != 0 && len(prefix) >= db.format.prefixLength {
+		bucket = db.format.bucket(prefix)
+	}
+	it := db.db.NewIteratorWithPrefix(bucket)
+	defer it.Release()
+
+	entries := memdb.New()
+	for it.Next() {
+		key, value, err := db.format.open(it.Key(), it.Value())
+		if err != nil {
+			return &database.IteratorError{
+				Err: err,
+			}
+		}
+		if !bytes.HasPrefix(key, prefix) || bytes.Compare(key, start) < 0 {
+			continue
+		}
+		if err := entries.Put(key, value); err != nil {
+			return &database.IteratorError{
+				Err: err,
+			}
+		}
+	}
+	if err := it.Error(); err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
 	return &iterator{
-		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
-		db:       db,
+		Iterator:  entries.NewIteratorWithStartAndPrefix(start, prefix),
+		db:        db,
+		decrypted: true,
 	}
 }
 
@@ -130,7 +301,11 @@ func (db *Database) Compact(start, limit []byte) error {
 	if db.closed {
 		return database.ErrClosed
 	}
-	return db.db.Compact(start, limit)
+	if db.format.ordered() {
+		storedStart, storedLimit := db.storedRange(start, limit)
+		return db.db.Compact(storedStart, storedLimit)
+	}
+	return db.db.Compact(dataPrefix, dataLimit)
 }
 
 func (db *Database) Close() error {
@@ -166,6 +341,11 @@ type batch struct {
 
 	db  *Database
 	ops []database.BatchOp
+
+	// hasUnorderedRanges is true if a range deletion was queued while entries
+	// aren't stored in key order. The keys removed by such deletions are
+	// determined when the batch is written.
+	hasUnorderedRanges bool
 }
 
 func (b *batch) Put(key, value []byte) error {
@@ -173,11 +353,12 @@ func (b *batch) Put(key, value []byte) error {
 		Key:   slices.Clone(key),
 		Value: slices.Clone(value),
 	})
-	encValue, err := b.db.encrypt(value)
+	storedKey := b.db.format.storedKey(key)
+	encValue, err := b.db.format.seal(storedKey, key, value)
 	if err != nil {
 		return err
 	}
-	return b.Batch.Put(key, encValue)
+	return b.Batch.Put(storedKey, encValue)
 }
 
 func (b *batch) Delete(key []byte) error {
@@ -185,7 +366,22 @@ func (b *batch) Delete(key []byte) error {
 		Key:    slices.Clone(key),
 		Delete: true,
 	})
-	return b.Batch.Delete(key)
+	return b.Batch.Delete(b.db.format.storedKey(key))
+}
+
+// DeleteRange queues the removal of every key in the range [start, limit).
+func (b *batch) DeleteRange(start, limit []byte) error {
+	b.ops = append(b.ops, database.BatchOp{
+		Key:         slices.Clone(start),
+		Limit:       slices.Clone(limit),
+		DeleteRange: true,
+	})
+	if !b.db.format.ordered() {
+		b.hasUnorderedRanges = true
+		return nil
+	}
+	storedStart, storedLimit := b.db.storedRange(start, limit)
+	return b.Batch.DeleteRange(storedStart, storedLimit)
 }
 
 func (b *batch) Write() error {
@@ -196,9 +392,67 @@ func (b *batch) Write() error {
 		return database.ErrClosed
 	}
 
+	if b.hasUnorderedRanges {
+		if err := b.expandRanges(); err != nil {
+			return err
+		}
+	}
 	return b.Batch.Write()
 }
 
+// expandRanges rewrites the underlying batch from [b.ops], replacing range
+// deletions with deletions of the individual keys in the range. Keys are
+// removed if they are in the database when the batch is written or were put
+// into the batch before the range deletion.
+//
+// Assumes [b.db.lock] is held.
+func (b *batch) expandRanges() error {
+	b.Batch.Reset()
+
+	puts := set.Set[string]{}
+	for _, op := range b.ops {
+		switch {
+		case op.DeleteRange:
+			for key := range puts {
+				keyBytes := []byte(key)
+				if !database.InRange(keyBytes, op.Key, op.Limit) {
+					continue
+				}
+				if err := b.Batch.Delete(b.db.format.storedKey(keyBytes)); err != nil {
+					return err
+				}
+				puts.Remove(key)
+			}
+
+			storedKeys, err := b.db.storedKeysInRange(op.Key, op.Limit)
+			if err != nil {
+				return err
+			}
+			for _, storedKey := range storedKeys {
+				if err := b.Batch.Delete(storedKey); err != nil {
+					return err
+				}
+			}
+		case op.Delete:
+			if err := b.Batch.Delete(b.db.format.storedKey(op.Key)); err != nil {
+				return err
+			}
+			puts.Remove(string(op.Key))
+		default:
+			storedKey := b.db.format.storedKey(op.Key)
+			encValue, err := b.db.format.seal(storedKey, op.Key, op.Value)
+			if err != nil {
+				return err
+			}
+			if err := b.Batch.Put(storedKey, encValue); err != nil {
+				return err
+			}
+			puts.Add(string(op.Key))
+		}
+	}
+	return nil
+}
+
 // Reset resets the batch for reuse.
 func (b *batch) Reset() {
 	if cap(b.ops) > len(b.ops)*database.MaxExcessCapacityFactor {
@@ -206,18 +460,26 @@ func (b *batch) Reset() {
 	} else {
 		b.ops = b.ops[:0]
 	}
+	b.hasUnorderedRanges = false
 	b.Batch.Reset()
 }
 
 // Replay replays the batch contents.
 func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
 	for _, op := range b.ops {
-		if op.Delete {
+		switch {
+		case op.DeleteRange:
+			if err := database.ReplayDeleteRange(w, op.Key, op.Limit); err != nil {
+				return err
+			}
+		case op.Delete:
 			if err := w.Delete(op.Key); err != nil {
 				return err
 			}
-		} else if err := w.Put(op.Key, op.Value); err != nil {
-			return err
+		default:
+			if err := w.Put(op.Key, op.Value); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
@@ -227,6 +489,9 @@ type iterator struct {
 	database.Iterator
 	db *Database
 
+	// decrypted is true if [Iterator] returns decrypted entries.
+	decrypted bool
+
 	val, key []byte
 	err      error
 }
@@ -241,18 +506,21 @@ func (it *iterator) Next() bool {
 	}
 
 	next := it.Iterator.Next()
-	if next {
-		encVal := it.Iterator.Value()
-		val, err := it.db.decrypt(encVal)
+	switch {
+	case !next:
+		it.val = nil
+		it.key = nil
+	case it.decrypted:
+		it.val = it.Iterator.Value()
+		it.key = it.Iterator.Key()
+	default:
+		key, val, err := it.db.format.open(it.Iterator.Key(), it.Iterator.Value())
 		if err != nil {
 			it.err = err
 			return false
 		}
 		it.val = val
-		it.key = it.Iterator.Key()
-	} else {
-		it.val = nil
-		it.key = nil
+		it.key = key
 	}
 	return next
 }
@@ -271,28 +539,3 @@ func (it *iterator) Key() []byte {
 func (it *iterator) Value() []byte {
 	return it.val
 }
-
-type encryptedValue struct {
-	Ciphertext []byte `serialize:"true"`
-	Nonce      []byte `serialize:"true"`
-}
-
-func (db *Database) encrypt(plaintext []byte) ([]byte, error) {
-	nonce := make([]byte, chacha20poly1305.NonceSizeX)
-	if _, err := rand.Read(nonce); err != nil {
-		return nil, err
-	}
-	ciphertext := db.cipher.Seal(nil, nonce, plaintext, nil)
-	return Codec.Marshal(CodecVersion, &encryptedValue{
-		Ciphertext: ciphertext,
-		Nonce:      nonce,
-	})
-}
-
-func (db *Database) decrypt(ciphertext []byte) ([]byte, error) {
-	val := encryptedValue{}
-	if _, err := Codec.Unmarshal(ciphertext, &val); err != nil {
-		return nil, err
-	}
-	return db.cipher.Open(nil, val.Nonce, val.Ciphertext, nil)
-}
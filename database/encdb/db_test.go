@@ -15,25 +15,294 @@ import (
 
 const testPassword = "lol totally a secure password" //nolint:gosec
 
+var (
+	// testKDFParams are cheap to derive to keep the tests fast.
+	testKDFParams = KDFParams{
+		Time:    1,
+		Memory:  64,
+		Threads: 1,
+	}
+
+	testConfigs = map[string]Config{
+		"plaintext": {
+			KeyMode: KeyModePlaintext,
+			KDF:     testKDFParams,
+		},
+		"hmac": {
+			KeyMode:      KeyModeHMAC,
+			PrefixLength: 1,
+			KDF:          testKDFParams,
+		},
+		"hmac_long_prefix": {
+			KeyMode:      KeyModeHMAC,
+			PrefixLength: 4,
+			KDF:          testKDFParams,
+		},
+	}
+)
+
 func TestInterface(t *testing.T) {
-	for name, test := range database.Tests {
-		t.Run(name, func(t *testing.T) {
-			unencryptedDB := memdb.New()
-			db, err := New([]byte(testPassword), unencryptedDB)
-			require.NoError(t, err)
+	for configName, config := range testConfigs {
+		for name, test := range database.Tests {
+			t.Run(configName+"_"+name, func(t *testing.T) {
+				unencryptedDB := memdb.New()
+				db, err := NewWithConfig([]byte(testPassword), unencryptedDB, config)
+				require.NoError(t, err)
 
-			test(t, db)
-		})
+				test(t, db)
+			})
+		}
 	}
 }
 
 func newDB(t testing.TB) database.Database {
 	unencryptedDB := memdb.New()
-	db, err := New([]byte(testPassword), unencryptedDB)
+	db, err := NewWithConfig([]byte(testPassword), unencryptedDB, testConfigs["plaintext"])
 	require.NoError(t, err)
 	return db
 }
 
+func TestNewDefaultConfig(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	db, err := New([]byte(testPassword), unencryptedDB)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+
+	md, err := getMetadata(unencryptedDB)
+	require.NoError(err)
+	require.Equal(DefaultConfig.KeyMode, md.KeyMode)
+	require.Equal(DefaultConfig.KDF, md.Current.KDF)
+	require.Len(md.Current.Salt, saltLen)
+
+	db, err = New([]byte(testPassword), unencryptedDB)
+	require.NoError(err)
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestIncorrectPassword(t *testing.T) {
+	for name, config := range testConfigs {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			unencryptedDB := memdb.New()
+			_, err := NewWithConfig([]byte(testPassword), unencryptedDB, config)
+			require.NoError(err)
+
+			_, err = NewWithConfig([]byte("wrong password"), unencryptedDB, config)
+			require.ErrorIs(err, ErrIncorrectPassword)
+		})
+	}
+}
+
+func TestKeyCache(t *testing.T) {
+	require := require.New(t)
+
+	config := testConfigs["plaintext"]
+	config.KeyCache = NewKeyCache()
+
+	unencryptedDB := memdb.New()
+	db, err := NewWithConfig([]byte(testPassword), unencryptedDB, config)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.Len(config.KeyCache.secrets, 1)
+
+	_, err = NewWithConfig([]byte("wrong password"), unencryptedDB, config)
+	require.ErrorIs(err, ErrIncorrectPassword)
+	require.Len(config.KeyCache.secrets, 1)
+
+	// Reopening the database reuses the cached keys.
+	db, err = NewWithConfig([]byte(testPassword), unencryptedDB, config)
+	require.NoError(err)
+	require.Len(config.KeyCache.secrets, 1)
+	for _, cached := range config.KeyCache.secrets {
+		require.Same(cached, db.format.secrets)
+	}
+
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      Config
+		expectedErr error
+	}{
+		{
+			name: "unknown key mode",
+			config: Config{
+				KeyMode: KeyModeHMAC + 1,
+				KDF:     testKDFParams,
+			},
+			expectedErr: errUnknownKeyMode,
+		},
+		{
+			name: "prefix length with plaintext keys",
+			config: Config{
+				KeyMode:      KeyModePlaintext,
+				PrefixLength: 1,
+				KDF:          testKDFParams,
+			},
+			expectedErr: errInvalidPrefixLength,
+		},
+		{
+			name: "no prefix length with hmac keys",
+			config: Config{
+				KeyMode: KeyModeHMAC,
+				KDF:     testKDFParams,
+			},
+			expectedErr: errInvalidPrefixLength,
+		},
+		{
+			name: "zero kdf time",
+			config: Config{
+				KeyMode: KeyModePlaintext,
+				KDF: KDFParams{
+					Memory:  64,
+					Threads: 1,
+				},
+			},
+			expectedErr: errInvalidKDFParams,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewWithConfig([]byte(testPassword), memdb.New(), test.config)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
+
+// Ensure that neither keys nor values are written to the underlying database
+// in plaintext when keys are protected.
+func TestHMACKeysNotStored(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	db, err := NewWithConfig([]byte(testPassword), unencryptedDB, testConfigs["hmac"])
+	require.NoError(err)
+
+	key := []byte("secret key")
+	value := []byte("secret value")
+	require.NoError(db.Put(key, value))
+
+	it := unencryptedDB.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		require.NotContains(string(it.Key()), string(key))
+		require.NotContains(string(it.Value()), string(key))
+		require.NotContains(string(it.Value()), string(value))
+	}
+	require.NoError(it.Error())
+}
+
+// Ensure that an entry can't be moved to a different key in the underlying
+// database without being detected.
+func TestEntryBoundToKey(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	db, err := NewWithConfig([]byte(testPassword), unencryptedDB, testConfigs["plaintext"])
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("a"), []byte("value a")))
+	require.NoError(db.Put([]byte("b"), []byte("value b")))
+
+	value, err := unencryptedDB.Get(prefixed(dataPrefix, []byte("a")))
+	require.NoError(err)
+	require.NoError(unencryptedDB.Put(prefixed(dataPrefix, []byte("b")), value))
+
+	_, err = db.Get([]byte("b"))
+	require.Error(err) //nolint:forbidigo // the error is returned by the aead
+}
+
+func TestLegacy(t *testing.T) {
+	require := require.New(t)
+
+	legacyFormat, err := newLegacyFormat([]byte(testPassword))
+	require.NoError(err)
+
+	// Write an entry to the legacy metadata key to ensure it isn't mistaken
+	// for metadata.
+	legacyDB := memdb.New()
+	for _, key := range [][]byte{metadataKey, []byte("key")} {
+		value, err := legacyFormat.seal(key, key, key)
+		require.NoError(err)
+		require.NoError(legacyDB.Put(key, value))
+	}
+
+	// Legacy databases must be explicitly allowed, as removing the metadata
+	// of a database would otherwise downgrade it to the legacy format.
+	_, err = New([]byte(testPassword), legacyDB)
+	require.ErrorIs(err, ErrMissingMetadata)
+
+	config := DefaultConfig
+	config.AllowLegacy = true
+	db, err := NewWithConfig([]byte(testPassword), legacyDB, config)
+	require.NoError(err)
+	require.True(db.format.legacy)
+	require.NoError(db.Put([]byte("other key"), []byte("other key")))
+
+	upgradedDB := memdb.New()
+	require.NoError(Upgrade([]byte(testPassword), legacyDB, upgradedDB, testConfigs["hmac"]))
+	require.ErrorIs(Upgrade([]byte(testPassword), legacyDB, upgradedDB, testConfigs["hmac"]), errNotEmpty)
+	require.ErrorIs(Upgrade([]byte(testPassword), upgradedDB, memdb.New(), testConfigs["hmac"]), errNotLegacy)
+
+	db, err = New([]byte(testPassword), upgradedDB)
+	require.NoError(err)
+	require.False(db.format.legacy)
+	require.Equal(KeyModeHMAC, db.format.keyMode)
+
+	it := db.NewIterator()
+	defer it.Release()
+
+	for _, key := range [][]byte{metadataKey, []byte("key"), []byte("other key")} {
+		require.True(it.Next())
+		require.Equal(key, it.Key())
+		require.Equal(key, it.Value())
+	}
+	require.False(it.Next())
+	require.NoError(it.Error())
+}
+
+// Ensure that range deletions whose bounds share a group only decrypt the
+// entries of that group.
+func TestDeleteRangeWithinGroup(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	db, err := NewWithConfig([]byte(testPassword), unencryptedDB, testConfigs["hmac"])
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte{0x01, 0x01}, nil))
+	require.NoError(db.Put([]byte{0x01, 0x02}, nil))
+	require.NoError(db.Put([]byte{0x02, 0x01}, nil))
+
+	// Corrupt an entry outside of the deleted group. Decrypting it would fail
+	// the deletion.
+	otherStoredKey := db.format.storedKey([]byte{0x02, 0x01})
+	require.NoError(unencryptedDB.Put(otherStoredKey, []byte("corrupted")))
+
+	require.NoError(db.DeleteRange([]byte{0x01, 0x00}, []byte{0x01, 0x02}))
+
+	has, err := db.Has([]byte{0x01, 0x01})
+	require.NoError(err)
+	require.False(has)
+	has, err = db.Has([]byte{0x01, 0x02})
+	require.NoError(err)
+	require.True(has)
+
+	// Range deletions across groups decrypt every entry.
+	err = db.DeleteRange([]byte{0x01}, []byte{0x03})
+	require.ErrorIs(err, errInvalidEntry)
+}
+
 func FuzzKeyValue(f *testing.F) {
 	database.FuzzKeyValue(f, newDB(f))
 }
@@ -0,0 +1,446 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils/hashing"
+	"github.com/skychains/chain/utils/wrappers"
+)
+
+// formatVersion is the version of the on-disk format written by this package.
+//
+// Databases written before the format was versioned are referred to as
+// legacy databases. They store keys as provided, encrypt values with a key
+// derived by a single SHA-256 of the password, and have no metadata.
+//
+// Version 1 databases store their metadata under [metadataKey] and every entry
+// under [dataPrefix]. Values are prefixed with the format version and the
+// generation of the key that encrypted them, and are authenticated against
+// the key they are stored under.
+const formatVersion = 1
+
+const (
+	saltLen      = 16
+	masterKeyLen = 32
+	bucketLen    = 16
+
+	versionLen    = 1
+	generationLen = wrappers.IntLen
+	envelopeLen   = versionLen + generationLen + chacha20poly1305.NonceSizeX
+
+	encryptionKeyLabel = "encdb encryption key"
+	macKeyLabel        = "encdb mac key"
+	checkLabel         = "encdb password check"
+
+	bucketDomain = 0x00
+	keyDomain    = 0x01
+)
+
+var (
+	metadataKey   = []byte{0x00}
+	dataPrefix    = []byte{0x01}
+	dataLimit     = []byte{0x02}
+	metadataMagic = []byte("encdb")
+
+	ErrIncorrectPassword        = errors.New("incorrect password")
+	ErrPasswordChangeInProgress = errors.New("password change in progress")
+	ErrMissingMetadata          = errors.New("populated database is missing its metadata")
+
+	errUnknownFormatVersion = errors.New("unknown format version")
+	errUnexpectedGeneration = errors.New("unexpected key generation")
+	errInvalidEntry         = errors.New("invalid entry")
+)
+
+type metadata struct {
+	Version      uint16    `serialize:"true"`
+	KeyMode      KeyMode   `serialize:"true"`
+	PrefixLength uint32    `serialize:"true"`
+	Current      keyParams `serialize:"true"`
+
+	// Pending is only populated while a password change is in progress.
+	HasPending bool      `serialize:"true"`
+	Pending    keyParams `serialize:"true"`
+}
+
+// keyParams are the parameters used to derive a generation of keys from a
+// password.
+type keyParams struct {
+	Generation uint32    `serialize:"true"`
+	Salt       []byte    `serialize:"true"`
+	KDF        KDFParams `serialize:"true"`
+	// Check is used to verify that a password derives these keys.
+	Check []byte `serialize:"true"`
+}
+
+// getMetadata returns the metadata of [db], or nil if [db] doesn't have any.
+func getMetadata(db database.KeyValueReader) (*metadata, error) {
+	metadataBytes, err := db.Get(metadataKey)
+	if err == database.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(metadataBytes, metadataMagic) {
+		// This must be an entry in a legacy database.
+		return nil, nil
+	}
+
+	md := &metadata{}
+	if _, err := Codec.Unmarshal(metadataBytes[len(metadataMagic):], md); err != nil {
+		return nil, err
+	}
+	if md.Version != formatVersion {
+		return nil, fmt.Errorf("%w: %d", errUnknownFormatVersion, md.Version)
+	}
+	return md, nil
+}
+
+func putMetadata(db database.KeyValueWriter, md *metadata) error {
+	metadataBytes, err := Codec.Marshal(CodecVersion, md)
+	if err != nil {
+		return err
+	}
+	return db.Put(metadataKey, append(bytes.Clone(metadataMagic), metadataBytes...))
+}
+
+// secrets are the keys derived from a password.
+type secrets struct {
+	generation uint32
+	aead       cipher.AEAD
+	macKey     []byte
+	check      []byte
+}
+
+// newKeyParams generates a new salt and derives the secrets for [password].
+func newKeyParams(password []byte, generation uint32, kdf KDFParams) (keyParams, *secrets, error) {
+	params := keyParams{
+		Generation: generation,
+		Salt:       make([]byte, saltLen),
+		KDF:        kdf,
+	}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return keyParams{}, nil, err
+	}
+
+	s, err := params.derive(password)
+	if err != nil {
+		return keyParams{}, nil, err
+	}
+	params.Check = s.check
+	return params, s, nil
+}
+
+func (p keyParams) derive(password []byte) (*secrets, error) {
+	master := argon2.IDKey(
+		password,
+		p.Salt,
+		p.KDF.Time,
+		p.KDF.Memory,
+		p.KDF.Threads,
+		masterKeyLen,
+	)
+	aead, err := chacha20poly1305.NewX(mac(master, []byte(encryptionKeyLabel)))
+	if err != nil {
+		return nil, err
+	}
+	return &secrets{
+		generation: p.Generation,
+		aead:       aead,
+		macKey:     mac(master, []byte(macKeyLabel)),
+		check:      mac(master, []byte(checkLabel)),
+	}, nil
+}
+
+// open derives the secrets for [password] and verifies that [password] is the
+// password these params were created with.
+func (p keyParams) open(password []byte) (*secrets, error) {
+	s, err := p.derive(password)
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(s.check, p.Check) {
+		return nil, ErrIncorrectPassword
+	}
+	return s, nil
+}
+
+// KeyCache remembers the keys derived from passwords. Deriving keys with the
+// default KDF params uses 64 MiB of memory, so callers that open the same
+// database repeatedly should derive its keys once and reuse them.
+//
+// A nil KeyCache derives the keys every time.
+type KeyCache struct {
+	lock sync.Mutex
+	// salt + MAC of the password keyed by the salt -> derived secrets
+	secrets map[string]*secrets
+}
+
+func NewKeyCache() *KeyCache {
+	return &KeyCache{
+		secrets: make(map[string]*secrets),
+	}
+}
+
+// open returns the secrets previously derived from [password] for [params],
+// deriving them if they aren't cached.
+func (c *KeyCache) open(params keyParams, password []byte) (*secrets, error) {
+	if c == nil {
+		return params.open(password)
+	}
+
+	key := keyCacheKey(params, password)
+	c.lock.Lock()
+	s, ok := c.secrets[key]
+	c.lock.Unlock()
+	if ok && s.generation == params.Generation && hmac.Equal(s.check, params.Check) {
+		return s, nil
+	}
+
+	s, err := params.open(password)
+	if err != nil {
+		return nil, err
+	}
+	c.put(params, password, s)
+	return s, nil
+}
+
+func (c *KeyCache) put(params keyParams, password []byte, s *secrets) {
+	if c == nil {
+		return
+	}
+
+	key := keyCacheKey(params, password)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.secrets[key] = s
+}
+
+func keyCacheKey(params keyParams, password []byte) string {
+	return string(params.Salt) + string(mac(params.Salt, password))
+}
+
+func mac(key []byte, msgs ...[]byte) []byte {
+	h := hmac.New(sha256.New, key)
+	for _, msg := range msgs {
+		_, _ = h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// format describes how entries are written to the underlying database.
+type format struct {
+	legacy       bool
+	keyMode      KeyMode
+	prefixLength int
+	secrets      *secrets
+}
+
+func newLegacyFormat(password []byte) (*format, error) {
+	aead, err := chacha20poly1305.NewX(hashing.ComputeHash256(password))
+	if err != nil {
+		return nil, err
+	}
+	return &format{
+		legacy: true,
+		secrets: &secrets{
+			aead: aead,
+		},
+	}, nil
+}
+
+func newFormat(md *metadata, s *secrets) *format {
+	return &format{
+		keyMode:      md.KeyMode,
+		prefixLength: int(md.PrefixLength),
+		secrets:      s,
+	}
+}
+
+// ordered returns true if entries are stored in the same order as their
+// keys.
+func (f *format) ordered() bool {
+	return f.legacy || f.keyMode == KeyModePlaintext
+}
+
+// prefix returns the prefix of every entry in the underlying database.
+func (f *format) prefix() []byte {
+	if f.legacy {
+		return nil
+	}
+	return dataPrefix
+}
+
+// limit returns the key after every entry in the underlying database, or nil
+// if there isn't one.
+func (f *format) limit() []byte {
+	if f.legacy {
+		return nil
+	}
+	return dataLimit
+}
+
+// storedKey returns the key that [key] is stored under.
+func (f *format) storedKey(key []byte) []byte {
+	switch {
+	case f.legacy:
+		return key
+	case f.keyMode == KeyModePlaintext:
+		return prefixed(dataPrefix, key)
+	default:
+		storedKey := f.bucket(key)
+		return append(storedKey, mac(f.secrets.macKey, []byte{keyDomain}, key)...)
+	}
+}
+
+// bucket returns the prefix shared by the stored keys of every key that has
+// the same first [f.prefixLength] bytes as [key].
+//
+// Only used with KeyModeHMAC.
+func (f *format) bucket(key []byte) []byte {
+	if f.prefixLength == 0 {
+		return prefixed(dataPrefix, nil)
+	}
+	if len(key) > f.prefixLength {
+		key = key[:f.prefixLength]
+	}
+	digest := mac(f.secrets.macKey, []byte{bucketDomain}, key)
+	return prefixed(dataPrefix, digest[:bucketLen])
+}
+
+// rangeBucket returns the prefix shared by the stored keys of every key in the
+// range [start, limit).
+//
+// Only used with KeyModeHMAC.
+func (f *format) rangeBucket(start, limit []byte) []byte {
+	if f.prefixLength == 0 || len(start) < f.prefixLength || len(limit) == 0 {
+		return dataPrefix
+	}
+	// If [limit] shares the first [f.prefixLength] bytes of [start], so does
+	// every key between them.
+	if !bytes.HasPrefix(limit, start[:f.prefixLength]) {
+		return dataPrefix
+	}
+	return f.bucket(start)
+}
+
+// seal encrypts the entry [key, value] to be stored under [storedKey].
+func (f *format) seal(storedKey, key, value []byte) ([]byte, error) {
+	if f.legacy {
+		return f.sealLegacy(value)
+	}
+
+	plaintext := value
+	if f.keyMode == KeyModeHMAC {
+		plaintext = make([]byte, 0, binary.MaxVarintLen64+len(key)+len(value))
+		plaintext = binary.AppendUvarint(plaintext, uint64(len(key)))
+		plaintext = append(plaintext, key...)
+		plaintext = append(plaintext, value...)
+	}
+
+	sealed := make([]byte, envelopeLen, envelopeLen+len(plaintext)+f.secrets.aead.Overhead())
+	sealed[0] = formatVersion
+	binary.BigEndian.PutUint32(sealed[versionLen:], f.secrets.generation)
+	nonce := sealed[versionLen+generationLen : envelopeLen]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return f.secrets.aead.Seal(sealed, nonce, plaintext, storedKey), nil
+}
+
+// open decrypts the entry stored under [storedKey] and returns its key and
+// value.
+func (f *format) open(storedKey, sealed []byte) ([]byte, []byte, error) {
+	if f.legacy {
+		value, err := f.openLegacy(sealed)
+		return storedKey, value, err
+	}
+
+	generation, err := parseGeneration(sealed)
+	if err != nil {
+		return nil, nil, err
+	}
+	if generation != f.secrets.generation {
+		return nil, nil, fmt.Errorf("%w: expected %d but got %d",
+			errUnexpectedGeneration,
+			f.secrets.generation,
+			generation,
+		)
+	}
+
+	nonce := sealed[versionLen+generationLen : envelopeLen]
+	plaintext, err := f.secrets.aead.Open(nil, nonce, sealed[envelopeLen:], storedKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if f.keyMode == KeyModePlaintext {
+		return storedKey[len(dataPrefix):], plaintext, nil
+	}
+
+	keyLen, n := binary.Uvarint(plaintext)
+	if n <= 0 || uint64(len(plaintext)-n) < keyLen {
+		return nil, nil, errInvalidEntry
+	}
+	keyEnd := n + int(keyLen)
+	return plaintext[n:keyEnd], plaintext[keyEnd:], nil
+}
+
+// parseGeneration returns the generation of the key that encrypted [sealed].
+func parseGeneration(sealed []byte) (uint32, error) {
+	if len(sealed) < envelopeLen {
+		return 0, errInvalidEntry
+	}
+	if sealed[0] != formatVersion {
+		return 0, fmt.Errorf("%w: %d", errUnknownFormatVersion, sealed[0])
+	}
+	return binary.BigEndian.Uint32(sealed[versionLen:]), nil
+}
+
+type encryptedValue struct {
+	Ciphertext []byte `serialize:"true"`
+	Nonce      []byte `serialize:"true"`
+}
+
+func (f *format) sealLegacy(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	ciphertext := f.secrets.aead.Seal(nil, nonce, plaintext, nil)
+	return Codec.Marshal(CodecVersion, &encryptedValue{
+		Ciphertext: ciphertext,
+		Nonce:      nonce,
+	})
+}
+
+func (f *format) openLegacy(ciphertext []byte) ([]byte, error) {
+	val := encryptedValue{}
+	if _, err := Codec.Unmarshal(ciphertext, &val); err != nil {
+		return nil, err
+	}
+	return f.secrets.aead.Open(nil, val.Nonce, val.Ciphertext, nil)
+}
+
+func prefixed(prefix, key []byte) []byte {
+	prefixedKey := make([]byte, len(prefix)+len(key), len(prefix)+len(key)+sha256.Size)
+	copy(prefixedKey, prefix)
+	copy(prefixedKey[len(prefix):], key)
+	return prefixedKey
+}
@@ -0,0 +1,224 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils/units"
+)
+
+const (
+	// changePasswordBatchSize is the maximum number of entries re-encrypted in
+	// a single batch by ChangePassword.
+	changePasswordBatchSize = 1024
+
+	// upgradeBatchSize is the size, in bytes, at which Upgrade writes its
+	// pending batch.
+	upgradeBatchSize = units.MiB
+)
+
+var (
+	errNoMetadata = errors.New("database has no metadata")
+	errNotLegacy  = errors.New("database isn't in the legacy format")
+	errNotEmpty   = errors.New("database isn't empty")
+)
+
+// ChangePassword re-encrypts every entry in [db] with keys derived from
+// [newPassword] with [kdf].
+//
+// Entries are re-encrypted in batches, so the change can be resumed if it is
+// interrupted, for example by cancelling [ctx]. Until ChangePassword is called
+// again with the same passwords and returns successfully, [db] can't be
+// opened. When resuming, [kdf] is ignored in favor of the parameters of the
+// interrupted call.
+//
+// [db] must not be used by a Database while its password is being changed.
+// Legacy databases must be upgraded before their password can be changed.
+func ChangePassword(
+	ctx context.Context,
+	db database.Database,
+	oldPassword []byte,
+	newPassword []byte,
+	kdf KDFParams,
+) error {
+	md, err := getMetadata(db)
+	if err != nil {
+		return err
+	}
+	if md == nil {
+		return errNoMetadata
+	}
+
+	oldSecrets, err := md.Current.open(oldPassword)
+	if err != nil {
+		return err
+	}
+
+	var newSecrets *secrets
+	if md.HasPending {
+		newSecrets, err = md.Pending.open(newPassword)
+		if errors.Is(err, ErrIncorrectPassword) {
+			return fmt.Errorf("%w to a different password", ErrPasswordChangeInProgress)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := kdf.Verify(); err != nil {
+			return err
+		}
+		md.Pending, newSecrets, err = newKeyParams(newPassword, md.Current.Generation+1, kdf)
+		if err != nil {
+			return err
+		}
+		md.HasPending = true
+		if err := putMetadata(db, md); err != nil {
+			return err
+		}
+	}
+
+	var (
+		currentFormat = newFormat(md, oldSecrets)
+		pendingFormat = newFormat(md, newSecrets)
+		start         = dataPrefix
+	)
+	for start != nil {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		start, err = reencryptBatch(db, currentFormat, pendingFormat, start)
+		if err != nil {
+			return err
+		}
+	}
+
+	md.Current = md.Pending
+	md.HasPending = false
+	md.Pending = keyParams{}
+	return putMetadata(db, md)
+}
+
+// reencryptBatch re-encrypts up to changePasswordBatchSize entries, with stored
+// keys of at least [start], from [current] to [pending]. Returns the stored key
+// to continue from, or nil if there are no more entries to re-encrypt.
+func reencryptBatch(db database.Database, current, pending *format, start []byte) ([]byte, error) {
+	var (
+		storedKeys [][]byte
+		values     [][]byte
+		next       []byte
+	)
+	it := db.NewIteratorWithStartAndPrefix(start, dataPrefix)
+	for it.Next() {
+		if len(storedKeys) == changePasswordBatchSize {
+			next = slices.Clone(it.Key())
+			break
+		}
+
+		value := it.Value()
+		generation, err := parseGeneration(value)
+		if err != nil {
+			it.Release()
+			return nil, err
+		}
+		if generation == pending.secrets.generation {
+			// This entry was re-encrypted by a previous batch.
+			continue
+		}
+
+		storedKeys = append(storedKeys, slices.Clone(it.Key()))
+		values = append(values, slices.Clone(value))
+	}
+	err := it.Error()
+	it.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	batch := db.NewBatch()
+	for i, storedKey := range storedKeys {
+		key, value, err := current.open(storedKey, values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		newStoredKey := pending.storedKey(key)
+		if !bytes.Equal(newStoredKey, storedKey) {
+			if err := batch.Delete(storedKey); err != nil {
+				return nil, err
+			}
+		}
+
+		newValue, err := pending.seal(newStoredKey, key, value)
+		if err != nil {
+			return nil, err
+		}
+		if err := batch.Put(newStoredKey, newValue); err != nil {
+			return nil, err
+		}
+	}
+	return next, batch.Write()
+}
+
+// Upgrade copies every entry of the legacy database [src] into the empty
+// database [dst], which is initialized with [config]. [src] isn't modified, so
+// if Upgrade fails, [dst] can be cleared and the upgrade retried.
+func Upgrade(password []byte, src, dst database.Database, config Config) error {
+	md, err := getMetadata(src)
+	if err != nil {
+		return err
+	}
+	if md != nil {
+		return errNotLegacy
+	}
+
+	isEmpty, err := database.IsEmpty(dst)
+	if err != nil {
+		return err
+	}
+	if !isEmpty {
+		return errNotEmpty
+	}
+
+	legacyFormat, err := newLegacyFormat(password)
+	if err != nil {
+		return err
+	}
+	dstDB, err := NewWithConfig(password, dst, config)
+	if err != nil {
+		return err
+	}
+
+	it := src.NewIterator()
+	defer it.Release()
+
+	batch := dstDB.NewBatch()
+	for it.Next() {
+		key, value, err := legacyFormat.open(it.Key(), it.Value())
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(key, value); err != nil {
+			return err
+		}
+
+		if batch.Size() < upgradeBatchSize {
+			continue
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
@@ -0,0 +1,181 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package encdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/utils"
+)
+
+const newTestPassword = "an even more secure password" //nolint:gosec
+
+func TestChangePassword(t *testing.T) {
+	for name, config := range testConfigs {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			unencryptedDB := memdb.New()
+			db, err := NewWithConfig([]byte(testPassword), unencryptedDB, config)
+			require.NoError(err)
+			for i := 0; i < 10; i++ {
+				require.NoError(db.Put([]byte{byte(i)}, []byte{byte(i)}))
+			}
+
+			require.NoError(ChangePassword(
+				context.Background(),
+				unencryptedDB,
+				[]byte(testPassword),
+				[]byte(newTestPassword),
+				testKDFParams,
+			))
+
+			_, err = New([]byte(testPassword), unencryptedDB)
+			require.ErrorIs(err, ErrIncorrectPassword)
+
+			db, err = New([]byte(newTestPassword), unencryptedDB)
+			require.NoError(err)
+			require.Equal(uint32(1), db.format.secrets.generation)
+
+			it := db.NewIterator()
+			defer it.Release()
+
+			for i := 0; i < 10; i++ {
+				require.True(it.Next())
+				require.Equal([]byte{byte(i)}, it.Key())
+				require.Equal([]byte{byte(i)}, it.Value())
+			}
+			require.False(it.Next())
+			require.NoError(it.Error())
+		})
+	}
+}
+
+func TestChangePasswordIncorrectPassword(t *testing.T) {
+	require := require.New(t)
+
+	unencryptedDB := memdb.New()
+	_, err := NewWithConfig([]byte(testPassword), unencryptedDB, testConfigs["plaintext"])
+	require.NoError(err)
+
+	err = ChangePassword(
+		context.Background(),
+		unencryptedDB,
+		[]byte("wrong password"),
+		[]byte(newTestPassword),
+		testKDFParams,
+	)
+	require.ErrorIs(err, ErrIncorrectPassword)
+}
+
+func TestChangePasswordResume(t *testing.T) {
+	for name, config := range testConfigs {
+		t.Run(name, func(t *testing.T) {
+			require := require.New(t)
+
+			unencryptedDB := memdb.New()
+			db, err := NewWithConfig([]byte(testPassword), unencryptedDB, config)
+			require.NoError(err)
+
+			const numEntries = changePasswordBatchSize + changePasswordBatchSize/2
+			for i := 0; i < numEntries; i++ {
+				key := utils.RandomBytes(8)
+				require.NoError(db.Put(key, key))
+			}
+
+			// Interrupt the password change before any entries are
+			// re-encrypted.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err = ChangePassword(
+				ctx,
+				unencryptedDB,
+				[]byte(testPassword),
+				[]byte(newTestPassword),
+				testKDFParams,
+			)
+			require.ErrorIs(err, context.Canceled)
+
+			_, err = New([]byte(testPassword), unencryptedDB)
+			require.ErrorIs(err, ErrPasswordChangeInProgress)
+
+			// Re-encrypt a single batch to simulate an interruption part way
+			// through the password change.
+			md, err := getMetadata(unencryptedDB)
+			require.NoError(err)
+			current, err := md.Current.open([]byte(testPassword))
+			require.NoError(err)
+			pending, err := md.Pending.open([]byte(newTestPassword))
+			require.NoError(err)
+			next, err := reencryptBatch(
+				unencryptedDB,
+				newFormat(md, current),
+				newFormat(md, pending),
+				dataPrefix,
+			)
+			require.NoError(err)
+			require.NotNil(next)
+
+			// The password change can't be resumed with a different password.
+			err = ChangePassword(
+				context.Background(),
+				unencryptedDB,
+				[]byte(testPassword),
+				[]byte("a different password"),
+				testKDFParams,
+			)
+			require.ErrorIs(err, ErrPasswordChangeInProgress)
+
+			require.NoError(ChangePassword(
+				context.Background(),
+				unencryptedDB,
+				[]byte(testPassword),
+				[]byte(newTestPassword),
+				testKDFParams,
+			))
+
+			db, err = New([]byte(newTestPassword), unencryptedDB)
+			require.NoError(err)
+
+			count, err := database.Count(db)
+			require.NoError(err)
+			require.Equal(numEntries, count)
+
+			it := db.NewIterator()
+			defer it.Release()
+
+			for it.Next() {
+				require.Equal(it.Key(), it.Value())
+			}
+			require.NoError(it.Error())
+		})
+	}
+}
+
+func TestChangePasswordLegacy(t *testing.T) {
+	require := require.New(t)
+
+	legacyFormat, err := newLegacyFormat([]byte(testPassword))
+	require.NoError(err)
+
+	legacyDB := memdb.New()
+	key := []byte("key")
+	value, err := legacyFormat.seal(key, key, key)
+	require.NoError(err)
+	require.NoError(legacyDB.Put(key, value))
+
+	err = ChangePassword(
+		context.Background(),
+		legacyDB,
+		[]byte(testPassword),
+		[]byte(newTestPassword),
+		testKDFParams,
+	)
+	require.ErrorIs(err, errNoMetadata)
+}
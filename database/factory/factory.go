@@ -0,0 +1,102 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package factory
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/database/pebbledb"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/version"
+)
+
+var (
+	ErrNotFound = errors.New("database not found")
+
+	// readOnlyConfig opens either leveldb or pebbledb without allowing any
+	// writes.
+	readOnlyConfig = []byte(`{"readOnly":true}`)
+)
+
+// Path returns the directory that a database of type [name] stores its files
+// in when the database directory is [dir].
+func Path(name string, dir string) string {
+	switch name {
+	case leveldb.Name:
+		// Prior to v1.10.15, the only on-disk database was leveldb, and its
+		// files went to [dbPath]/[networkID]/v1.4.5.
+		return filepath.Join(dir, version.CurrentDatabase.String())
+	case pebbledb.Name:
+		return filepath.Join(dir, "pebble")
+	default:
+		return dir
+	}
+}
+
+// New opens the database of type [name] stored in [dir].
+func New(
+	name string,
+	dir string,
+	config []byte,
+	log logging.Logger,
+	reg prometheus.Registerer,
+) (database.Database, error) {
+	dbPath := Path(name, dir)
+	switch name {
+	case leveldb.Name:
+		db, err := leveldb.New(dbPath, config, log, reg)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create %s at %s: %w", leveldb.Name, dbPath, err)
+		}
+		return db, nil
+	case memdb.Name:
+		return memdb.New(), nil
+	case pebbledb.Name:
+		db, err := pebbledb.New(dbPath, config, log, reg)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't create %s at %s: %w", pebbledb.Name, dbPath, err)
+		}
+		return db, nil
+	default:
+		return nil, fmt.Errorf(
+			"db-type was %q but should have been one of {%s, %s, %s}",
+			name,
+			leveldb.Name,
+			memdb.Name,
+			pebbledb.Name,
+		)
+	}
+}
+
+// NewReadOnly opens the existing database of type [name] stored in [dir]
+// without allowing any writes. Unlike New, the database is never created. If
+// it doesn't exist, ErrNotFound is returned.
+func NewReadOnly(
+	name string,
+	dir string,
+	log logging.Logger,
+	reg prometheus.Registerer,
+) (database.Database, error) {
+	if name == memdb.Name {
+		return nil, fmt.Errorf("%w: %s isn't persisted", ErrNotFound, name)
+	}
+
+	dbPath := Path(name, dir)
+	switch _, err := os.Stat(dbPath); {
+	case errors.Is(err, fs.ErrNotExist):
+		return nil, fmt.Errorf("%w: %s at %s", ErrNotFound, name, dbPath)
+	case err != nil:
+		return nil, err
+	}
+	return New(name, dir, readOnlyConfig, log, reg)
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package factory
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/utils/logging"
+)
+
+func TestNewReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	dir := t.TempDir()
+	_, err := NewReadOnly(leveldb.Name, dir, logging.NoLog{}, prometheus.NewRegistry())
+	require.ErrorIs(err, ErrNotFound)
+
+	_, err = NewReadOnly(memdb.Name, dir, logging.NoLog{}, prometheus.NewRegistry())
+	require.ErrorIs(err, ErrNotFound)
+
+	db, err := New(leveldb.Name, dir, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Close())
+
+	db, err = NewReadOnly(leveldb.Name, dir, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+
+	value, err := db.Get([]byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+	require.Error(db.Put([]byte("key"), nil)) //nolint:forbidigo // the error isn't exported
+	require.NoError(db.Close())
+}
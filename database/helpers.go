@@ -4,6 +4,7 @@
 package database
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -188,6 +189,32 @@ func AtomicClearPrefix(readerDB Iteratee, deleterDB KeyValueDeleter, prefix []by
 	return iterator.Error()
 }
 
+// AtomicDeleteRange deletes from [deleterDB] all keys in [readerDB] that are
+// in the range [start, limit). An empty [limit] is treated as a key after all
+// keys.
+func AtomicDeleteRange(readerDB Iteratee, deleterDB KeyValueDeleter, start, limit []byte) error {
+	iterator := readerDB.NewIteratorWithStart(start)
+	defer iterator.Release()
+
+	for iterator.Next() {
+		key := iterator.Key()
+		if len(limit) != 0 && bytes.Compare(key, limit) >= 0 {
+			break
+		}
+		if err := deleterDB.Delete(key); err != nil {
+			return err
+		}
+	}
+	return iterator.Error()
+}
+
+// InRange returns true if [key] is in the range [start, limit). An empty
+// [limit] is treated as a key after all keys.
+func InRange(key, start, limit []byte) bool {
+	return bytes.Compare(key, start) >= 0 &&
+		(len(limit) == 0 || bytes.Compare(key, limit) < 0)
+}
+
 // Remove all key-value pairs from [db].
 // Writes each batch when it reaches [writeSize].
 func Clear(db Database, writeSize int) error {
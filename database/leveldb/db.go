@@ -25,6 +25,7 @@ import (
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/utils"
 	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/set"
 )
 
 const (
@@ -63,9 +64,11 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iter)(nil)
+	_ database.Database    = (*Database)(nil)
+	_ database.Snapshotter = (*Database)(nil)
+	_ database.Snapshot    = (*snapshot)(nil)
+	_ database.Batch       = (*batch)(nil)
+	_ database.Iterator    = (*iter)(nil)
 
 	ErrInvalidConfig = errors.New("invalid config")
 	ErrCouldNotOpen  = errors.New("could not open")
@@ -183,6 +186,12 @@ type config struct {
 	// MetricUpdateFrequency is the frequency to poll LevelDB metrics.
 	// If <= 0, LevelDB metrics aren't polled.
 	MetricUpdateFrequency time.Duration `json:"metricUpdateFrequency"`
+
+	// ReadOnly opens the database without allowing any writes. A corrupted
+	// database isn't recovered when opened read-only.
+	//
+	// The default is false.
+	ReadOnly bool `json:"readOnly"`
 }
 
 // New returns a wrapped LevelDB object.
@@ -223,8 +232,9 @@ func New(file string, configBytes []byte, log logging.Logger, reg prometheus.Reg
 		WriteBuffer:                   parsedConfig.WriteBuffer,
 		Filter:                        filter.NewBloomFilter(parsedConfig.FilterBitsPerKey),
 		MaxManifestFileSize:           parsedConfig.MaxManifestFileSize,
+		ReadOnly:                      parsedConfig.ReadOnly,
 	})
-	if _, corrupted := err.(*errors.ErrCorrupted); corrupted {
+	if _, corrupted := err.(*errors.ErrCorrupted); corrupted && !parsedConfig.ReadOnly {
 		db, err = leveldb.RecoverFile(file, nil)
 	}
 	if err != nil {
@@ -291,6 +301,17 @@ func (db *Database) Delete(key []byte) error {
 	return updateError(db.DB.Delete(key, nil))
 }
 
+// DeleteRange removes every key in the range [start, limit) from the database.
+// leveldb doesn't support range deletions natively, so the keys in the range
+// are deleted individually in a single batch.
+func (db *Database) DeleteRange(start, limit []byte) error {
+	b := db.NewBatch()
+	if err := b.DeleteRange(start, limit); err != nil {
+		return err
+	}
+	return b.Write()
+}
+
 // NewBatch creates a write/delete-only buffer that is atomically committed to
 // the database when write is called
 func (db *Database) NewBatch() database.Batch {
@@ -327,16 +348,25 @@ func (db *Database) NewIteratorWithPrefix(prefix []byte) database.Iterator {
 // over the database starting at start and ignoring keys that do not start with
 // the provided prefix
 func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
-	iterRange := util.BytesPrefix(prefix)
-	if bytes.Compare(start, prefix) == 1 {
-		iterRange.Start = start
-	}
 	return &iter{
 		db:       db,
-		Iterator: db.DB.NewIterator(iterRange, nil),
+		Iterator: db.DB.NewIterator(iterRange(start, prefix), nil),
 	}
 }
 
+// NewSnapshot returns a read-only view of the current state of the database
+// using leveldb's native snapshots.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	s, err := db.DB.GetSnapshot()
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return &snapshot{
+		db:       db,
+		Snapshot: s,
+	}, nil
+}
+
 // This comment is basically copy pasted from the underlying levelDB library:
 
 // Compact the underlying DB for the given key range.
@@ -373,6 +403,19 @@ type batch struct {
 	leveldb.Batch
 	db   *Database
 	size int
+
+	// ranges are the range deletions that were added to the batch, in order.
+	// Because levelDB doesn't support range deletions, they are expanded into
+	// individual deletions when the batch is written.
+	ranges []rangeDeletion
+}
+
+type rangeDeletion struct {
+	// index is the number of levelDB operations in the batch that precede
+	// this deletion.
+	index int
+	start []byte
+	limit []byte
 }
 
 // Put the value into the batch for later writing
@@ -389,6 +432,17 @@ func (b *batch) Delete(key []byte) error {
 	return nil
 }
 
+// DeleteRange queues the removal of every key in the range [start, limit)
+func (b *batch) DeleteRange(start, limit []byte) error {
+	b.ranges = append(b.ranges, rangeDeletion{
+		index: b.Batch.Len(),
+		start: slices.Clone(start),
+		limit: slices.Clone(limit),
+	})
+	b.size += len(start) + len(limit) + levelDBByteOverhead
+	return nil
+}
+
 // Size retrieves the amount of data queued up for writing.
 func (b *batch) Size() int {
 	return b.size
@@ -396,22 +450,41 @@ func (b *batch) Size() int {
 
 // Write flushes any accumulated data to disk.
 func (b *batch) Write() error {
-	return updateError(b.db.DB.Write(&b.Batch, nil))
+	if len(b.ranges) == 0 {
+		return updateError(b.db.DB.Write(&b.Batch, nil))
+	}
+
+	expander := &rangeExpander{
+		db:    b.db,
+		batch: new(leveldb.Batch),
+		puts:  set.Set[string]{},
+	}
+	if err := b.Replay(expander); err != nil {
+		return err
+	}
+	return updateError(b.db.DB.Write(expander.batch, nil))
 }
 
 // Reset resets the batch for reuse.
 func (b *batch) Reset() {
 	b.Batch.Reset()
+	b.ranges = nil
 	b.size = 0
 }
 
 // Replay the batch contents.
 func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
-	replay := &replayer{writerDeleter: w}
+	replay := &replayer{
+		writerDeleter: w,
+		ranges:        b.ranges,
+	}
 	if err := b.Batch.Replay(replay); err != nil {
 		// Never actually returns an error, because Replay just returns nil
 		return err
 	}
+	// Replay any range deletions that were added after the last levelDB
+	// operation.
+	replay.replayRanges()
 	return replay.err
 }
 
@@ -423,9 +496,15 @@ func (b *batch) Inner() database.Batch {
 type replayer struct {
 	writerDeleter database.KeyValueWriterDeleter
 	err           error
+
+	// index is the number of levelDB operations that have been replayed.
+	index  int
+	ranges []rangeDeletion
 }
 
 func (r *replayer) Put(key, value []byte) {
+	r.replayRanges()
+	r.index++
 	if r.err != nil {
 		return
 	}
@@ -433,12 +512,114 @@ func (r *replayer) Put(key, value []byte) {
 }
 
 func (r *replayer) Delete(key []byte) {
+	r.replayRanges()
+	r.index++
 	if r.err != nil {
 		return
 	}
 	r.err = r.writerDeleter.Delete(key)
 }
 
+// replayRanges replays the range deletions that precede the next levelDB
+// operation.
+func (r *replayer) replayRanges() {
+	for r.err == nil && len(r.ranges) > 0 && r.ranges[0].index <= r.index {
+		rangeDeletion := r.ranges[0]
+		r.ranges = r.ranges[1:]
+		r.err = database.ReplayDeleteRange(r.writerDeleter, rangeDeletion.start, rangeDeletion.limit)
+	}
+}
+
+// rangeExpander writes operations into a levelDB batch, replacing range
+// deletions with deletions of the individual keys in the range.
+type rangeExpander struct {
+	db    *Database
+	batch *leveldb.Batch
+	// puts contains the keys that have been put into [batch] and not deleted
+	// since.
+	puts set.Set[string]
+}
+
+func (e *rangeExpander) Put(key, value []byte) error {
+	e.batch.Put(key, value)
+	e.puts.Add(string(key))
+	return nil
+}
+
+func (e *rangeExpander) Delete(key []byte) error {
+	e.batch.Delete(key)
+	e.puts.Remove(string(key))
+	return nil
+}
+
+func (e *rangeExpander) DeleteRange(start, limit []byte) error {
+	for key := range e.puts {
+		keyBytes := []byte(key)
+		if database.InRange(keyBytes, start, limit) {
+			e.batch.Delete(keyBytes)
+			e.puts.Remove(key)
+		}
+	}
+
+	keyRange := &util.Range{Start: start}
+	if len(limit) != 0 {
+		keyRange.Limit = limit
+	}
+	it := e.db.DB.NewIterator(keyRange, nil)
+	defer it.Release()
+
+	for it.Next() {
+		e.batch.Delete(it.Key())
+	}
+	return updateError(it.Error())
+}
+
+// snapshot is a wrapper around a levelDB snapshot to convert errors and
+// iterators.
+type snapshot struct {
+	db *Database
+	*leveldb.Snapshot
+}
+
+// Has returns if the key is set in the snapshot
+func (s *snapshot) Has(key []byte) (bool, error) {
+	has, err := s.Snapshot.Has(key, nil)
+	return has, updateError(err)
+}
+
+// Get returns the value the key maps to in the snapshot
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	value, err := s.Snapshot.Get(key, nil)
+	return value, updateError(err)
+}
+
+// NewIterator creates a lexicographically ordered iterator over the snapshot
+func (s *snapshot) NewIterator() database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+// NewIteratorWithStart creates a lexicographically ordered iterator over the
+// snapshot starting at the provided key
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+// NewIteratorWithPrefix creates a lexicographically ordered iterator over the
+// snapshot ignoring keys that do not start with the provided prefix
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix creates a lexicographically ordered iterator
+// over the snapshot starting at start and ignoring keys that do not start with
+// the provided prefix
+func (s *snapshot) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	return &iter{
+		db:       s.db,
+		Iterator: s.Snapshot.NewIterator(iterRange(start, prefix), nil),
+	}
+}
+
 type iter struct {
 	db *Database
 	iterator.Iterator
@@ -482,9 +663,18 @@ func (it *iter) Value() []byte {
 	return it.val
 }
 
+// iterRange returns the range of keys after [start] that have [prefix].
+func iterRange(start, prefix []byte) *util.Range {
+	r := util.BytesPrefix(prefix)
+	if bytes.Compare(start, prefix) == 1 {
+		r.Start = start
+	}
+	return r
+}
+
 func updateError(err error) error {
 	switch err {
-	case leveldb.ErrClosed:
+	case leveldb.ErrClosed, leveldb.ErrSnapshotReleased:
 		return database.ErrClosed
 	case leveldb.ErrNotFound:
 		return database.ErrNotFound
@@ -9,6 +9,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
+	"github.com/syndtr/goleveldb/leveldb"
 
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/utils/logging"
@@ -35,6 +36,30 @@ func newDB(t testing.TB) database.Database {
 	return db
 }
 
+func TestReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	folder := t.TempDir()
+	db, err := New(folder, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+
+	key := []byte("key")
+	value := []byte("value")
+	require.NoError(db.Put(key, value))
+	require.NoError(db.Close())
+
+	db, err = New(folder, []byte(`{"readOnly":true}`), logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+	defer db.Close()
+
+	got, err := db.Get(key)
+	require.NoError(err)
+	require.Equal(value, got)
+
+	err = db.Put(key, value)
+	require.ErrorIs(err, leveldb.ErrReadOnly)
+}
+
 func FuzzKeyValue(f *testing.F) {
 	db := newDB(f)
 	defer db.Close()
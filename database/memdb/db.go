@@ -5,6 +5,7 @@ package memdb
 
 import (
 	"context"
+	"maps"
 	"slices"
 	"strings"
 	"sync"
@@ -21,9 +22,10 @@ const (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database    = (*Database)(nil)
+	_ database.Snapshotter = (*Database)(nil)
+	_ database.Batch       = (*batch)(nil)
+	_ database.Iterator    = (*iterator)(nil)
 )
 
 // Database is an ephemeral key-value store that implements the Database
@@ -108,6 +110,26 @@ func (db *Database) Delete(key []byte) error {
 	return nil
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.db == nil {
+		return database.ErrClosed
+	}
+	db.deleteRange(start, limit)
+	return nil
+}
+
+// Assumes [db.lock] is held.
+func (db *Database) deleteRange(start, limit []byte) {
+	for key := range db.db {
+		if database.InRange([]byte(key), start, limit) {
+			delete(db.db, key)
+		}
+	}
+}
+
 func (db *Database) NewBatch() database.Batch {
 	return &batch{db: db}
 }
@@ -134,10 +156,19 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 		}
 	}
 
+	return newIterator(db, db.db, start, prefix)
+}
+
+// newIterator returns an iterator over the entries of [kvs] that are after
+// [start] and have [prefix]. The returned iterator is invalidated when [db] is
+// closed.
+//
+// Assumes [kvs] is not modified while this function is running.
+func newIterator(db *Database, kvs map[string][]byte, start, prefix []byte) *iterator {
 	startString := string(start)
 	prefixString := string(prefix)
-	keys := make([]string, 0, len(db.db))
-	for key := range db.db {
+	keys := make([]string, 0, len(kvs))
+	for key := range kvs {
 		if strings.HasPrefix(key, prefixString) && key >= startString {
 			keys = append(keys, key)
 		}
@@ -145,7 +176,7 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	slices.Sort(keys) // Keys need to be in sorted order
 	values := make([][]byte, 0, len(keys))
 	for _, key := range keys {
-		values = append(values, db.db[key])
+		values = append(values, kvs[key])
 	}
 	return &iterator{
 		db:     db,
@@ -154,6 +185,21 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	}
 }
 
+// NewSnapshot copies the current contents of the database. Values are never
+// modified in place, so only the map of keys is copied.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.db == nil {
+		return nil, database.ErrClosed
+	}
+	return &snapshot{
+		db:  db,
+		kvs: maps.Clone(db.db),
+	}, nil
+}
+
 func (db *Database) Compact(_, _ []byte) error {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -186,9 +232,12 @@ func (b *batch) Write() error {
 	}
 
 	for _, op := range b.Ops {
-		if op.Delete {
+		switch {
+		case op.DeleteRange:
+			b.db.deleteRange(op.Key, op.Limit)
+		case op.Delete:
 			delete(b.db.db, string(op.Key))
-		} else {
+		default:
 			b.db.db[string(op.Key)] = op.Value
 		}
 	}
@@ -253,3 +302,80 @@ func (it *iterator) Release() {
 	it.keys = nil
 	it.values = nil
 }
+
+type snapshot struct {
+	db *Database
+
+	lock sync.RWMutex
+	// kvs is nil once the snapshot has been released.
+	kvs map[string][]byte
+}
+
+func (s *snapshot) Has(key []byte) (bool, error) {
+	if s.db.isClosed() {
+		return false, database.ErrClosed
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.kvs == nil {
+		return false, database.ErrClosed
+	}
+	_, ok := s.kvs[string(key)]
+	return ok, nil
+}
+
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	if s.db.isClosed() {
+		return nil, database.ErrClosed
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.kvs == nil {
+		return nil, database.ErrClosed
+	}
+	if entry, ok := s.kvs[string(key)]; ok {
+		return slices.Clone(entry), nil
+	}
+	return nil, database.ErrNotFound
+}
+
+func (s *snapshot) NewIterator() database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (s *snapshot) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	if s.db.isClosed() {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.kvs == nil {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return newIterator(s.db, s.kvs, start, prefix)
+}
+
+func (s *snapshot) Release() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.kvs = nil
+}
@@ -16,9 +16,12 @@ import (
 const methodLabel = "method"
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.Checkpointer = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
+	_ database.Snapshot     = (*snapshot)(nil)
+	_ database.Batch        = (*batch)(nil)
+	_ database.Iterator     = (*iterator)(nil)
 
 	methodLabels = []string{methodLabel}
 	hasLabel     = prometheus.Labels{
@@ -33,6 +36,9 @@ var (
 	deleteLabel = prometheus.Labels{
 		methodLabel: "delete",
 	}
+	deleteRangeLabel = prometheus.Labels{
+		methodLabel: "delete_range",
+	}
 	newBatchLabel = prometheus.Labels{
 		methodLabel: "new_batch",
 	}
@@ -45,6 +51,12 @@ var (
 	closeLabel = prometheus.Labels{
 		methodLabel: "close",
 	}
+	checkpointLabel = prometheus.Labels{
+		methodLabel: "checkpoint",
+	}
+	newSnapshotLabel = prometheus.Labels{
+		methodLabel: "new_snapshot",
+	}
 	healthCheckLabel = prometheus.Labels{
 		methodLabel: "health_check",
 	}
@@ -54,6 +66,9 @@ var (
 	batchDeleteLabel = prometheus.Labels{
 		methodLabel: "batch_delete",
 	}
+	batchDeleteRangeLabel = prometheus.Labels{
+		methodLabel: "batch_delete_range",
+	}
 	batchSizeLabel = prometheus.Labels{
 		methodLabel: "batch_size",
 	}
@@ -69,6 +84,18 @@ var (
 	batchInnerLabel = prometheus.Labels{
 		methodLabel: "batch_inner",
 	}
+	snapshotHasLabel = prometheus.Labels{
+		methodLabel: "snapshot_has",
+	}
+	snapshotGetLabel = prometheus.Labels{
+		methodLabel: "snapshot_get",
+	}
+	snapshotNewIteratorLabel = prometheus.Labels{
+		methodLabel: "snapshot_new_iterator",
+	}
+	snapshotReleaseLabel = prometheus.Labels{
+		methodLabel: "snapshot_release",
+	}
 	iteratorNextLabel = prometheus.Labels{
 		methodLabel: "iterator_next",
 	}
@@ -176,6 +203,17 @@ func (db *Database) Delete(key []byte) error {
 	return err
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	startTime := time.Now()
+	err := db.db.DeleteRange(start, limit)
+	duration := time.Since(startTime)
+
+	db.calls.With(deleteRangeLabel).Inc()
+	db.duration.With(deleteRangeLabel).Add(float64(duration))
+	db.size.With(deleteRangeLabel).Add(float64(len(start) + len(limit)))
+	return err
+}
+
 func (db *Database) NewBatch() database.Batch {
 	start := time.Now()
 	b := &batch{
@@ -227,6 +265,46 @@ func (db *Database) Compact(start, limit []byte) error {
 	return err
 }
 
+// Checkpoint creates a checkpoint of the underlying database if it supports
+// them. Otherwise, errors.ErrUnsupported is returned.
+func (db *Database) Checkpoint(dir string) error {
+	checkpointer, ok := db.db.(database.Checkpointer)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	start := time.Now()
+	err := checkpointer.Checkpoint(dir)
+	duration := time.Since(start)
+
+	db.calls.With(checkpointLabel).Inc()
+	db.duration.With(checkpointLabel).Add(float64(duration))
+	return err
+}
+
+// NewSnapshot creates a snapshot of the underlying database if it supports
+// them. Otherwise, errors.ErrUnsupported is returned.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	snapshotter, ok := db.db.(database.Snapshotter)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	start := time.Now()
+	s, err := snapshotter.NewSnapshot()
+	duration := time.Since(start)
+
+	db.calls.With(newSnapshotLabel).Inc()
+	db.duration.With(newSnapshotLabel).Add(float64(duration))
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{
+		snapshot: s,
+		db:       db,
+	}, nil
+}
+
 func (db *Database) Close() error {
 	start := time.Now()
 	err := db.db.Close()
@@ -274,6 +352,17 @@ func (b *batch) Delete(key []byte) error {
 	return err
 }
 
+func (b *batch) DeleteRange(start, limit []byte) error {
+	startTime := time.Now()
+	err := b.batch.DeleteRange(start, limit)
+	duration := time.Since(startTime)
+
+	b.db.calls.With(batchDeleteRangeLabel).Inc()
+	b.db.duration.With(batchDeleteRangeLabel).Add(float64(duration))
+	b.db.size.With(batchDeleteRangeLabel).Add(float64(len(start) + len(limit)))
+	return err
+}
+
 func (b *batch) Size() int {
 	start := time.Now()
 	size := b.batch.Size()
@@ -325,6 +414,70 @@ func (b *batch) Inner() database.Batch {
 	return inner
 }
 
+type snapshot struct {
+	snapshot database.Snapshot
+	db       *Database
+}
+
+func (s *snapshot) Has(key []byte) (bool, error) {
+	start := time.Now()
+	has, err := s.snapshot.Has(key)
+	duration := time.Since(start)
+
+	s.db.calls.With(snapshotHasLabel).Inc()
+	s.db.duration.With(snapshotHasLabel).Add(float64(duration))
+	s.db.size.With(snapshotHasLabel).Add(float64(len(key)))
+	return has, err
+}
+
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	start := time.Now()
+	value, err := s.snapshot.Get(key)
+	duration := time.Since(start)
+
+	s.db.calls.With(snapshotGetLabel).Inc()
+	s.db.duration.With(snapshotGetLabel).Add(float64(duration))
+	s.db.size.With(snapshotGetLabel).Add(float64(len(key) + len(value)))
+	return value, err
+}
+
+func (s *snapshot) NewIterator() database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (s *snapshot) NewIteratorWithStartAndPrefix(
+	start,
+	prefix []byte,
+) database.Iterator {
+	startTime := time.Now()
+	it := &iterator{
+		iterator: s.snapshot.NewIteratorWithStartAndPrefix(start, prefix),
+		db:       s.db,
+	}
+	duration := time.Since(startTime)
+
+	s.db.calls.With(snapshotNewIteratorLabel).Inc()
+	s.db.duration.With(snapshotNewIteratorLabel).Add(float64(duration))
+	return it
+}
+
+func (s *snapshot) Release() {
+	start := time.Now()
+	s.snapshot.Release()
+	duration := time.Since(start)
+
+	s.db.calls.With(snapshotReleaseLabel).Inc()
+	s.db.duration.With(snapshotReleaseLabel).Add(float64(duration))
+}
+
 type iterator struct {
 	iterator database.Iterator
 	db       *Database
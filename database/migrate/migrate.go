@@ -0,0 +1,450 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils/hashing"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/units"
+)
+
+const (
+	// DefaultBatchSize is the default number of bytes to buffer before
+	// writing a batch into the destination database.
+	DefaultBatchSize = 32 * units.MiB
+
+	// DefaultPrefixLen is the default number of leading key bytes used to
+	// group keys when computing checksums. Top-level prefixdb namespaces are
+	// hashes, so this groups keys by namespace.
+	DefaultPrefixLen = hashing.HashLen
+
+	progressLogFrequency = 30 * time.Second
+
+	// checkContextFrequency is the number of keys hashed between checks for
+	// context cancellation.
+	checkContextFrequency = 4096
+)
+
+var (
+	// progressKey is written into the destination database, atomically with
+	// every batch, to record the last key that was copied. It is removed once
+	// the migration completes.
+	progressKey = []byte("dbMigrationProgress")
+	// copiedKey is written into the destination database, atomically with the
+	// removal of [progressKey], once every key has been copied. It is removed
+	// once the copy has been verified.
+	copiedKey = []byte("dbMigrationCopied")
+
+	reservedKeys = [][]byte{progressKey, copiedKey}
+
+	ErrDestinationNotEmpty = errors.New("destination database is not empty")
+	ErrReservedKey         = errors.New("source database contains reserved migration key")
+	ErrChecksumMismatch    = errors.New("checksum mismatch")
+)
+
+type Config struct {
+	// BatchSize is the number of bytes to buffer before writing a batch into
+	// the destination database.
+	BatchSize int
+	// PrefixLen is the number of leading key bytes used to group keys when
+	// verifying the copy. Keys shorter than PrefixLen are grouped by the full
+	// key.
+	PrefixLen int
+}
+
+var DefaultConfig = Config{
+	BatchSize: DefaultBatchSize,
+	PrefixLen: DefaultPrefixLen,
+}
+
+// Migrate copies every key/value pair from [src] into [dst].
+//
+// [dst] must either be empty or contain a partial migration from a previous
+// call to Migrate with the same [src]. In the latter case, the copy resumes
+// after the last key that was written. Once every key has been copied, the
+// contents of both databases are compared with per-prefix checksums. The
+// migration is only marked as complete once the comparison succeeds, so an
+// interrupted verification is re-run by the next call.
+//
+// [src] must not be modified while the migration is running.
+func Migrate(
+	ctx context.Context,
+	log logging.Logger,
+	config Config,
+	src database.Database,
+	dst database.Database,
+) error {
+	for _, key := range reservedKeys {
+		hasReserved, err := src.Has(key)
+		if err != nil {
+			return err
+		}
+		if hasReserved {
+			return fmt.Errorf("%w: %q", ErrReservedKey, key)
+		}
+	}
+
+	start, copied, err := resumeFrom(dst)
+	if err != nil {
+		return err
+	}
+	switch {
+	case copied:
+		log.Info("resuming database migration verification")
+	case start == nil:
+		log.Info("starting database migration")
+	default:
+		log.Info("resuming database migration",
+			zap.Binary("start", start),
+		)
+	}
+
+	if !copied {
+		if err := copyAll(ctx, log, config.BatchSize, src, dst, start, true); err != nil {
+			return err
+		}
+	}
+
+	log.Info("verifying database migration")
+	if err := Verify(ctx, config.PrefixLen, src, dst); err != nil {
+		return err
+	}
+	if err := dst.Delete(copiedKey); err != nil {
+		return err
+	}
+	log.Info("finished database migration")
+	return nil
+}
+
+// Copy copies every key/value pair from [src] into [dst].
+//
+// Unlike Migrate, the copy is neither resumable nor verified. This allows [src]
+// to be concurrently modified, as its iterators provide a consistent view.
+func Copy(
+	ctx context.Context,
+	log logging.Logger,
+	batchSize int,
+	src database.Iteratee,
+	dst database.Batcher,
+) error {
+	return copyAll(ctx, log, batchSize, src, dst, nil, false)
+}
+
+// IsComplete returns true if [dst] is populated and doesn't contain an
+// unfinished migration.
+func IsComplete(dst database.Database) (bool, error) {
+	_, _, err := resumeFrom(dst)
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.Is(err, ErrDestinationNotEmpty):
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// resumeFrom returns the first key that still needs to be copied into [dst],
+// or nil if the migration should start from the beginning. If every key has
+// already been copied, true is returned.
+func resumeFrom(dst database.Database) ([]byte, bool, error) {
+	copied, err := dst.Has(copiedKey)
+	if err != nil || copied {
+		return nil, copied, err
+	}
+
+	lastKey, err := dst.Get(progressKey)
+	switch {
+	case err == nil:
+		// The successor of [lastKey] is [lastKey] with a 0x00 byte appended.
+		start := make([]byte, len(lastKey)+1)
+		copy(start, lastKey)
+		return start, false, nil
+	case err != database.ErrNotFound:
+		return nil, false, err
+	}
+
+	empty, err := database.IsEmpty(dst)
+	if err != nil {
+		return nil, false, err
+	}
+	if !empty {
+		return nil, false, ErrDestinationNotEmpty
+	}
+	return nil, false, nil
+}
+
+func copyAll(
+	ctx context.Context,
+	log logging.Logger,
+	batchSize int,
+	src database.Iteratee,
+	dst database.Batcher,
+	start []byte,
+	trackProgress bool,
+) error {
+	it := src.NewIteratorWithStart(start)
+	defer it.Release()
+
+	var (
+		batch       = dst.NewBatch()
+		numKeys     uint64
+		numBytes    uint64
+		lastLogTime = time.Now()
+	)
+	for it.Next() {
+		key := it.Key()
+		value := it.Value()
+		if err := batch.Put(key, value); err != nil {
+			return err
+		}
+		numKeys++
+		numBytes += uint64(len(key) + len(value))
+
+		if batch.Size() < batchSize {
+			continue
+		}
+
+		// The progress is persisted atomically with the copied values.
+		if trackProgress {
+			if err := batch.Put(progressKey, key); err != nil {
+				return err
+			}
+		}
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if now := time.Now(); now.Sub(lastLogTime) >= progressLogFrequency {
+			lastLogTime = now
+			log.Info("migrating database",
+				zap.Uint64("numKeys", numKeys),
+				zap.Uint64("numBytes", numBytes),
+				zap.Binary("lastKey", key),
+			)
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	log.Info("copied database",
+		zap.Uint64("numKeys", numKeys),
+		zap.Uint64("numBytes", numBytes),
+	)
+	if trackProgress {
+		if err := batch.Delete(progressKey); err != nil {
+			return err
+		}
+		if err := batch.Put(copiedKey, nil); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// MovePrefixes moves every key/value pair whose key starts with one of
+// [prefixes] from [src] into [dst]. Keys are written into [dst] unmodified.
+//
+// The pairs under each prefix are copied into [dst] before they are removed
+// from [src], so if MovePrefixes is interrupted it can be called again with the
+// same arguments to resume. Neither [src] nor [dst] may be modified under
+// [prefixes] by anything else until MovePrefixes returns successfully.
+func MovePrefixes(
+	ctx context.Context,
+	log logging.Logger,
+	config Config,
+	src database.Database,
+	dst database.Database,
+	prefixes [][]byte,
+) error {
+	for _, prefix := range prefixes {
+		if err := copyPrefix(ctx, config.BatchSize, src, dst, prefix); err != nil {
+			return err
+		}
+		if err := database.ClearPrefix(src, prefix, config.BatchSize); err != nil {
+			return err
+		}
+		log.Debug("moved database prefix",
+			zap.Binary("prefix", prefix),
+		)
+	}
+	return nil
+}
+
+func copyPrefix(
+	ctx context.Context,
+	batchSize int,
+	src database.Iteratee,
+	dst database.Batcher,
+	prefix []byte,
+) error {
+	it := src.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	batch := dst.NewBatch()
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+
+		if batch.Size() < batchSize {
+			continue
+		}
+
+		if err := batch.Write(); err != nil {
+			return err
+		}
+		batch.Reset()
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// Verify returns an error if [a] and [b] do not contain the same key/value
+// pairs. Keys are grouped by their first [prefixLen] bytes and the error
+// reports every group whose contents differ.
+func Verify(ctx context.Context, prefixLen int, a, b database.Iteratee) error {
+	aChecksums, err := Checksums(ctx, prefixLen, a)
+	if err != nil {
+		return err
+	}
+	bChecksums, err := Checksums(ctx, prefixLen, b)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for prefix, aChecksum := range aChecksums {
+		bChecksum, ok := bChecksums[prefix]
+		if !ok || aChecksum != bChecksum {
+			errs = append(errs, fmt.Errorf("%w for prefix 0x%x: %s != %s",
+				ErrChecksumMismatch,
+				prefix,
+				aChecksum,
+				bChecksum,
+			))
+		}
+	}
+	for prefix, bChecksum := range bChecksums {
+		if _, ok := aChecksums[prefix]; !ok {
+			errs = append(errs, fmt.Errorf("%w for prefix 0x%x: missing != %s",
+				ErrChecksumMismatch,
+				prefix,
+				bChecksum,
+			))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Checksum summarizes the key/value pairs under a prefix.
+type Checksum struct {
+	NumKeys  uint64
+	NumBytes uint64
+	Hash     [hashing.HashLen]byte
+}
+
+func (c Checksum) String() string {
+	return fmt.Sprintf("{keys=%d, bytes=%d, hash=%x}", c.NumKeys, c.NumBytes, c.Hash)
+}
+
+// Checksums returns a checksum of every prefix in [db]. Prefixes are the first
+// [prefixLen] bytes of each key. Keys used to track the progress of a migration
+// are ignored.
+func Checksums(ctx context.Context, prefixLen int, db database.Iteratee) (map[string]Checksum, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var (
+		checksums     = make(map[string]Checksum)
+		started       bool
+		currentPrefix []byte
+		current       Checksum
+		hasher        = sha256.New()
+		numKeys       uint64
+	)
+	finish := func() {
+		if !started {
+			return
+		}
+		copy(current.Hash[:], hasher.Sum(nil))
+		checksums[string(currentPrefix)] = current
+	}
+	for it.Next() {
+		key := it.Key()
+		if isReserved(key) {
+			continue
+		}
+		value := it.Value()
+
+		prefix := key
+		if len(prefix) > prefixLen {
+			prefix = prefix[:prefixLen]
+		}
+		if !started || !bytes.Equal(prefix, currentPrefix) {
+			finish()
+			started = true
+			currentPrefix = slices.Clone(prefix)
+			current = Checksum{}
+			hasher.Reset()
+		}
+
+		current.NumKeys++
+		current.NumBytes += uint64(len(key) + len(value))
+		writeLengthPrefixed(hasher, key)
+		writeLengthPrefixed(hasher, value)
+
+		numKeys++
+		if numKeys%checkContextFrequency == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	finish()
+	return checksums, it.Error()
+}
+
+func isReserved(key []byte) bool {
+	for _, reservedKey := range reservedKeys {
+		if bytes.Equal(key, reservedKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeLengthPrefixed writes [b] to [h] prefixed with its length so that
+// adjacent keys and values can't be confused with each other.
+func writeLengthPrefixed(h hash.Hash, b []byte) {
+	var length [database.Uint64Size]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(b)))
+	_, _ = h.Write(length[:])
+	_, _ = h.Write(b)
+}
@@ -0,0 +1,211 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package migrate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/utils/logging"
+)
+
+func newPopulatedDB(t *testing.T) database.Database {
+	require := require.New(t)
+
+	db := memdb.New()
+	require.NoError(db.Put([]byte("genesisID"), []byte{1, 2, 3}))
+	require.NoError(db.Put([]byte("ungracefulShutdown"), nil))
+	for _, prefix := range []string{"a", "b", "c"} {
+		prefixDB := prefixdb.New([]byte(prefix), db)
+		for i := 0; i < 100; i++ {
+			require.NoError(prefixDB.Put([]byte{byte(i)}, []byte{byte(i), byte(i)}))
+		}
+	}
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	require := require.New(t)
+
+	src := newPopulatedDB(t)
+	dst := memdb.New()
+	config := Config{
+		BatchSize: 64,
+		PrefixLen: DefaultPrefixLen,
+	}
+	complete, err := IsComplete(dst)
+	require.NoError(err)
+	require.False(complete)
+
+	require.NoError(Migrate(context.Background(), logging.NoLog{}, config, src, dst))
+
+	complete, err = IsComplete(dst)
+	require.NoError(err)
+	require.True(complete)
+
+	for _, key := range reservedKeys {
+		has, err := dst.Has(key)
+		require.NoError(err)
+		require.False(has)
+	}
+
+	has, err := dst.Has([]byte("ungracefulShutdown"))
+	require.NoError(err)
+	require.True(has)
+
+	require.NoError(Verify(context.Background(), DefaultPrefixLen, src, dst))
+
+	err = Migrate(context.Background(), logging.NoLog{}, config, src, dst)
+	require.ErrorIs(err, ErrDestinationNotEmpty)
+}
+
+func TestMigrateResume(t *testing.T) {
+	require := require.New(t)
+
+	src := newPopulatedDB(t)
+	dst := memdb.New()
+	config := Config{
+		BatchSize: 64,
+		PrefixLen: DefaultPrefixLen,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := Migrate(ctx, logging.NoLog{}, config, src, dst)
+	require.ErrorIs(err, context.Canceled)
+
+	has, err := dst.Has(progressKey)
+	require.NoError(err)
+	require.True(has)
+
+	require.NoError(Migrate(context.Background(), logging.NoLog{}, config, src, dst))
+	require.NoError(Verify(context.Background(), DefaultPrefixLen, src, dst))
+}
+
+func TestMigrateResumeVerification(t *testing.T) {
+	require := require.New(t)
+
+	src := newPopulatedDB(t)
+	dst := memdb.New()
+
+	// Simulate a migration that was interrupted after the copy finished but
+	// before it was verified.
+	require.NoError(copyAll(context.Background(), logging.NoLog{}, DefaultBatchSize, src, dst, nil, true))
+	has, err := dst.Has(copiedKey)
+	require.NoError(err)
+	require.True(has)
+
+	// The copy is verified when the migration is resumed.
+	require.NoError(dst.Put([]byte("genesisID"), []byte{3, 2, 1}))
+	err = Migrate(context.Background(), logging.NoLog{}, DefaultConfig, src, dst)
+	require.ErrorIs(err, ErrChecksumMismatch)
+
+	has, err = dst.Has(copiedKey)
+	require.NoError(err)
+	require.True(has)
+
+	require.NoError(dst.Put([]byte("genesisID"), []byte{1, 2, 3}))
+	require.NoError(Migrate(context.Background(), logging.NoLog{}, DefaultConfig, src, dst))
+
+	has, err = dst.Has(copiedKey)
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestCopy(t *testing.T) {
+	require := require.New(t)
+
+	src := newPopulatedDB(t)
+	dst := memdb.New()
+	require.NoError(Copy(context.Background(), logging.NoLog{}, 64, src, dst))
+	require.NoError(Verify(context.Background(), DefaultPrefixLen, src, dst))
+
+	for _, key := range reservedKeys {
+		has, err := dst.Has(key)
+		require.NoError(err)
+		require.False(has)
+	}
+}
+
+func TestVerifyMismatch(t *testing.T) {
+	require := require.New(t)
+
+	src := newPopulatedDB(t)
+	dst := memdb.New()
+	require.NoError(Migrate(context.Background(), logging.NoLog{}, DefaultConfig, src, dst))
+
+	require.NoError(dst.Put([]byte("genesisID"), []byte{3, 2, 1}))
+	err := Verify(context.Background(), DefaultPrefixLen, src, dst)
+	require.ErrorIs(err, ErrChecksumMismatch)
+
+	require.NoError(dst.Put([]byte("genesisID"), []byte{1, 2, 3}))
+	require.NoError(dst.Put([]byte("extra"), nil))
+	err = Verify(context.Background(), DefaultPrefixLen, src, dst)
+	require.ErrorIs(err, ErrChecksumMismatch)
+}
+
+func TestMigrateReservedKey(t *testing.T) {
+	require := require.New(t)
+
+	for _, key := range reservedKeys {
+		src := memdb.New()
+		require.NoError(src.Put(key, nil))
+
+		err := Migrate(context.Background(), logging.NoLog{}, DefaultConfig, src, memdb.New())
+		require.ErrorIs(err, ErrReservedKey)
+	}
+}
+
+func TestMovePrefixes(t *testing.T) {
+	require := require.New(t)
+
+	src := newPopulatedDB(t)
+	dst := memdb.New()
+	config := Config{
+		BatchSize: 64,
+		PrefixLen: DefaultPrefixLen,
+	}
+	prefixes := [][]byte{
+		prefixdb.MakePrefix([]byte("a")),
+		prefixdb.MakePrefix([]byte("c")),
+	}
+
+	// Interrupt the move after the first batch is copied.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := MovePrefixes(ctx, logging.NoLog{}, config, src, dst, prefixes)
+	require.ErrorIs(err, context.Canceled)
+
+	require.NoError(MovePrefixes(context.Background(), logging.NoLog{}, config, src, dst, prefixes))
+
+	for _, prefix := range []string{"a", "c"} {
+		srcPrefixDB := prefixdb.New([]byte(prefix), src)
+		isEmpty, err := database.IsEmpty(srcPrefixDB)
+		require.NoError(err)
+		require.True(isEmpty)
+
+		dstPrefixDB := prefixdb.New([]byte(prefix), dst)
+		count, err := database.Count(dstPrefixDB)
+		require.NoError(err)
+		require.Equal(100, count)
+
+		value, err := dstPrefixDB.Get([]byte{5})
+		require.NoError(err)
+		require.Equal([]byte{5, 5}, value)
+	}
+
+	// Keys outside of the moved prefixes are untouched.
+	count, err := database.Count(prefixdb.New([]byte("b"), src))
+	require.NoError(err)
+	require.Equal(100, count)
+
+	count, err = database.Count(dst)
+	require.NoError(err)
+	require.Equal(200, count)
+}
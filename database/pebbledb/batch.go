@@ -5,6 +5,7 @@ package pebbledb
 
 import (
 	"fmt"
+	"slices"
 
 	"github.com/cockroachdb/pebble"
 
@@ -19,11 +20,28 @@ type batch struct {
 	db    *Database
 	size  int
 
+	// The greatest key put into [batch] since the last time [Reset] was
+	// called. Used to bound range deletions without a limit.
+	maxKey []byte
+
+	// Range deletions without a limit. pebble doesn't support unbounded range
+	// deletions, so they are bounded by the greatest key in the database when
+	// the batch is written.
+	unboundedRanges []unboundedRange
+
 	// True iff [batch] has been written to the database
 	// since the last time [Reset] was called.
 	written bool
 }
 
+type unboundedRange struct {
+	// index is the number of operations in the batch before this deletion.
+	index uint32
+	start []byte
+	// maxKey is the greatest key put into the batch before this deletion.
+	maxKey []byte
+}
+
 func (db *Database) NewBatch() database.Batch {
 	return &batch{
 		db:    db,
@@ -33,6 +51,9 @@ func (db *Database) NewBatch() database.Batch {
 
 func (b *batch) Put(key, value []byte) error {
 	b.size += len(key) + len(value) + pebbleByteOverHead
+	if pebble.DefaultComparer.Compare(key, b.maxKey) > 0 {
+		b.maxKey = slices.Clone(key)
+	}
 	return b.batch.Set(key, value, pebble.Sync)
 }
 
@@ -41,12 +62,42 @@ func (b *batch) Delete(key []byte) error {
 	return b.batch.Delete(key, pebble.Sync)
 }
 
+// Assumes [b.db.lock] is not held.
+func (b *batch) DeleteRange(start, limit []byte) error {
+	b.db.lock.RLock()
+	defer b.db.lock.RUnlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+
+	if len(limit) == 0 {
+		b.unboundedRanges = append(b.unboundedRanges, unboundedRange{
+			index:  b.batch.Count(),
+			start:  slices.Clone(start),
+			maxKey: b.maxKey,
+		})
+		b.size += len(start) + pebbleByteOverHead
+		return nil
+	}
+	if pebble.DefaultComparer.Compare(start, limit) >= 0 {
+		// pebble requires [start] < [limit]
+		return nil
+	}
+	b.size += len(start) + len(limit) + pebbleByteOverHead
+	return b.batch.DeleteRange(start, limit, pebble.Sync)
+}
+
 func (b *batch) Size() int {
 	return b.size
 }
 
 // Assumes [b.db.lock] is not held.
 func (b *batch) Write() error {
+	if len(b.unboundedRanges) != 0 {
+		return b.writeUnboundedRanges()
+	}
+
 	b.db.lock.RLock()
 	defer b.db.lock.RUnlock()
 
@@ -70,19 +121,92 @@ func (b *batch) Write() error {
 	return updateError(b.batch.Commit(pebble.Sync))
 }
 
+// writeUnboundedRanges writes the batch after bounding its unbounded range
+// deletions by the greatest key in the database. The database is locked
+// exclusively so that no keys can be written between computing the bounds and
+// committing the batch.
+//
+// Assumes [b.db.lock] is not held.
+func (b *batch) writeUnboundedRanges() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	if b.db.closed {
+		return database.ErrClosed
+	}
+
+	var (
+		bounded = b.db.pebbleDB.NewBatch()
+		ranges  = b.unboundedRanges
+		reader  = b.batch.Reader()
+		index   uint32
+	)
+	for {
+		for len(ranges) > 0 && ranges[0].index == index {
+			end, err := b.db.rangeEnd(nil, ranges[0].maxKey)
+			if err != nil {
+				return err
+			}
+			if end != nil && pebble.DefaultComparer.Compare(ranges[0].start, end) < 0 {
+				if err := bounded.DeleteRange(ranges[0].start, end, pebble.Sync); err != nil {
+					return err
+				}
+			}
+			ranges = ranges[1:]
+		}
+
+		kind, k, v, ok := reader.Next()
+		if !ok {
+			break
+		}
+		var err error
+		switch kind {
+		case pebble.InternalKeyKindSet:
+			err = bounded.Set(k, v, pebble.Sync)
+		case pebble.InternalKeyKindDelete:
+			err = bounded.Delete(k, pebble.Sync)
+		case pebble.InternalKeyKindRangeDelete:
+			err = bounded.DeleteRange(k, v, pebble.Sync)
+		default:
+			err = fmt.Errorf("%w: %v", errInvalidOperation, kind)
+		}
+		if err != nil {
+			return err
+		}
+		index++
+	}
+
+	b.written = true
+	return updateError(bounded.Commit(pebble.Sync))
+}
+
 func (b *batch) Reset() {
 	b.batch.Reset()
 	b.written = false
 	b.size = 0
+	b.maxKey = nil
+	b.unboundedRanges = nil
 }
 
 func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
-	reader := b.batch.Reader()
+	var (
+		ranges = b.unboundedRanges
+		reader = b.batch.Reader()
+		index  uint32
+	)
 	for {
+		for len(ranges) > 0 && ranges[0].index == index {
+			if err := database.ReplayDeleteRange(w, ranges[0].start, nil); err != nil {
+				return err
+			}
+			ranges = ranges[1:]
+		}
+
 		kind, k, v, ok := reader.Next()
 		if !ok {
 			return nil
 		}
+		index++
 		switch kind {
 		case pebble.InternalKeyKindSet:
 			if err := w.Put(k, v); err != nil {
@@ -92,6 +216,10 @@ func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
 			if err := w.Delete(k); err != nil {
 				return err
 			}
+		case pebble.InternalKeyKindRangeDelete:
+			if err := database.ReplayDeleteRange(w, k, v); err != nil {
+				return err
+			}
 		default:
 			return fmt.Errorf("%w: %v", errInvalidOperation, kind)
 		}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"slices"
 	"sync"
+	"time"
 
 	"github.com/cockroachdb/pebble"
 	"github.com/prometheus/client_golang/prometheus"
@@ -28,10 +29,16 @@ const (
 	pebbleByteOverHead = 8
 
 	defaultCacheSize = 512 * units.MiB
+
+	// DefaultMetricUpdateFrequency is the frequency to poll the pebble
+	// metrics.
+	DefaultMetricUpdateFrequency = 10 * time.Second
 )
 
 var (
-	_ database.Database = (*Database)(nil)
+	_ database.Database     = (*Database)(nil)
+	_ database.Checkpointer = (*Database)(nil)
+	_ database.Snapshotter  = (*Database)(nil)
 
 	errInvalidOperation = errors.New("invalid operation")
 
@@ -43,6 +50,7 @@ var (
 		MemTableSize:                defaultCacheSize / 4,
 		MaxOpenFiles:                4096,
 		MaxConcurrentCompactions:    1,
+		MetricUpdateFrequency:       DefaultMetricUpdateFrequency,
 	}
 )
 
@@ -51,6 +59,16 @@ type Database struct {
 	pebbleDB      *pebble.DB
 	closed        bool
 	openIterators set.Set[*iter]
+	openSnapshots set.Set[*snapshot]
+
+	// metrics is only initialized and used when [MetricUpdateFrequency] is > 0
+	// in the config
+	metrics   *metrics
+	closeOnce sync.Once
+	// closeCh is closed when Close() is called.
+	closeCh chan struct{}
+	// closeWg is used to wait for all goroutines created by New() to exit.
+	closeWg sync.WaitGroup
 }
 
 type Config struct {
@@ -61,10 +79,16 @@ type Config struct {
 	MemTableSize                uint64 `json:"memTableSize"`
 	MaxOpenFiles                int    `json:"maxOpenFiles"`
 	MaxConcurrentCompactions    int    `json:"maxConcurrentCompactions"`
+
+	// MetricUpdateFrequency is the frequency to poll pebble metrics.
+	// If <= 0, pebble metrics aren't polled.
+	MetricUpdateFrequency time.Duration `json:"metricUpdateFrequency"`
+
+	// ReadOnly opens the database without allowing any writes.
+	ReadOnly bool `json:"readOnly"`
 }
 
-// TODO: Add metrics
-func New(file string, configBytes []byte, log logging.Logger, _ prometheus.Registerer) (database.Database, error) {
+func New(file string, configBytes []byte, log logging.Logger, reg prometheus.Registerer) (database.Database, error) {
 	cfg := DefaultConfig
 	if len(configBytes) > 0 {
 		if err := json.Unmarshal(configBytes, &cfg); err != nil {
@@ -81,22 +105,73 @@ func New(file string, configBytes []byte, log logging.Logger, _ prometheus.Regis
 		MemTableSize:                cfg.MemTableSize,
 		MaxOpenFiles:                cfg.MaxOpenFiles,
 		MaxConcurrentCompactions:    func() int { return cfg.MaxConcurrentCompactions },
+		ReadOnly:                    cfg.ReadOnly,
 	}
 	opts.Experimental.ReadSamplingMultiplier = -1 // Disable seek compaction
 
+	var (
+		metrics *metrics
+		err     error
+	)
+	if cfg.MetricUpdateFrequency > 0 {
+		metrics, err = newMetrics(reg)
+		if err != nil {
+			return nil, err
+		}
+		opts.AddEventListener(metrics.eventListener())
+	}
+
 	log.Info(
 		"opening pebble",
 		zap.Reflect("config", cfg),
 	)
 
 	db, err := pebble.Open(file, opts)
-	return &Database{
+	if err != nil && metrics != nil {
+		// Allow the metrics to be registered again when the database is
+		// reopened.
+		unregister(reg, metrics.collectors())
+	}
+	wrappedDB := &Database{
 		pebbleDB:      db,
 		openIterators: set.Set[*iter]{},
-	}, err
+		openSnapshots: set.Set[*snapshot]{},
+		metrics:       metrics,
+		closeCh:       make(chan struct{}),
+	}
+	if err != nil || metrics == nil {
+		return wrappedDB, err
+	}
+
+	wrappedDB.closeWg.Add(1)
+	go func() {
+		t := time.NewTicker(cfg.MetricUpdateFrequency)
+		defer func() {
+			t.Stop()
+			wrappedDB.closeWg.Done()
+		}()
+
+		for {
+			wrappedDB.updateMetrics()
+
+			select {
+			case <-t.C:
+			case <-wrappedDB.closeCh:
+				return
+			}
+		}
+	}()
+	return wrappedDB, nil
 }
 
 func (db *Database) Close() error {
+	// Stop polling metrics before acquiring the lock so that the pebble
+	// database is never read from after it has been closed.
+	db.closeOnce.Do(func() {
+		close(db.closeCh)
+	})
+	db.closeWg.Wait()
+
 	db.lock.Lock()
 	defer db.lock.Unlock()
 
@@ -113,6 +188,12 @@ func (db *Database) Close() error {
 	}
 	db.openIterators.Clear()
 
+	// Pebble requires every snapshot to be closed before the database is
+	// closed.
+	for snapshot := range db.openSnapshots {
+		snapshot.release()
+	}
+
 	return updateError(db.pebbleDB.Close())
 }
 
@@ -194,20 +275,15 @@ func (db *Database) Compact(start []byte, end []byte) error {
 		// keys but pebble treats a nil [limit] as a key before all keys in
 		// Compact. Use the greatest key in the database as the [limit] to get
 		// the desired behavior.
-		it, err := db.pebbleDB.NewIter(&pebble.IterOptions{})
+		lastKey, err := db.lastKey()
 		if err != nil {
-			return updateError(err)
+			return err
 		}
-
-		if !it.Last() {
+		if lastKey == nil {
 			// The database is empty.
-			return it.Close()
-		}
-
-		end = slices.Clone(it.Key())
-		if err := it.Close(); err != nil {
-			return err
+			return nil
 		}
+		end = lastKey
 	}
 
 	if pebble.DefaultComparer.Compare(start, end) >= 1 {
@@ -218,6 +294,100 @@ func (db *Database) Compact(start []byte, end []byte) error {
 	return updateError(db.pebbleDB.Compact(start, end, true /* parallelize */))
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	end, err := db.rangeEnd(limit, nil)
+	if err != nil {
+		return err
+	}
+	if end == nil || pebble.DefaultComparer.Compare(start, end) >= 0 {
+		// pebble requires [start] < [end]
+		return nil
+	}
+	return updateError(db.pebbleDB.DeleteRange(start, end, pebble.Sync))
+}
+
+// rangeEnd returns the exclusive upper bound to pass to pebble when deleting
+// keys up to [limit]. pebble doesn't support unbounded range deletions, so if
+// [limit] is empty, the successor of the greatest key in the database or
+// [maxKey] is returned. If there is no such key, nil is returned.
+//
+// Assumes [db.lock] is held.
+func (db *Database) rangeEnd(limit []byte, maxKey []byte) ([]byte, error) {
+	if len(limit) != 0 {
+		return limit, nil
+	}
+
+	lastKey, err := db.lastKey()
+	if err != nil {
+		return nil, err
+	}
+	if pebble.DefaultComparer.Compare(maxKey, lastKey) > 0 {
+		lastKey = maxKey
+	}
+	if lastKey == nil {
+		return nil, nil
+	}
+	// The successor of [lastKey] is [lastKey] with a 0x00 byte appended.
+	return append(slices.Clone(lastKey), 0x00), nil
+}
+
+// lastKey returns the greatest key in the database, or nil if the database is
+// empty.
+//
+// Assumes [db.lock] is held.
+func (db *Database) lastKey() ([]byte, error) {
+	it, err := db.pebbleDB.NewIter(&pebble.IterOptions{})
+	if err != nil {
+		return nil, updateError(err)
+	}
+
+	if !it.Last() {
+		return nil, it.Close()
+	}
+
+	lastKey := slices.Clone(it.Key())
+	return lastKey, it.Close()
+}
+
+// Checkpoint uses pebble's native checkpoints to write a consistent copy of
+// the database into [dir]. Files are hard-linked when possible, so the
+// checkpoint should be created on the same filesystem as the database.
+func (db *Database) Checkpoint(dir string) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	return updateError(db.pebbleDB.Checkpoint(dir, pebble.WithFlushedWAL()))
+}
+
+// NewSnapshot returns a read-only view of the current state of the database
+// using pebble's native snapshots.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	snapshot := &snapshot{
+		db:       db,
+		snapshot: db.pebbleDB.NewSnapshot(),
+	}
+	db.openSnapshots.Add(snapshot)
+	return snapshot, nil
+}
+
 func (db *Database) NewIterator() database.Iterator {
 	return db.NewIteratorWithStartAndPrefix(nil, nil)
 }
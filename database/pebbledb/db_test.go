@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/cockroachdb/pebble"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 
@@ -31,6 +32,30 @@ func TestInterface(t *testing.T) {
 	}
 }
 
+func TestReadOnly(t *testing.T) {
+	require := require.New(t)
+
+	folder := t.TempDir()
+	db, err := New(folder, nil, logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+
+	key := []byte("key")
+	value := []byte("value")
+	require.NoError(db.Put(key, value))
+	require.NoError(db.Close())
+
+	db, err = New(folder, []byte(`{"readOnly":true}`), logging.NoLog{}, prometheus.NewRegistry())
+	require.NoError(err)
+	defer db.Close()
+
+	got, err := db.Get(key)
+	require.NoError(err)
+	require.Equal(value, got)
+
+	err = db.Put(key, value)
+	require.ErrorIs(err, pebble.ErrReadOnly)
+}
+
 func FuzzKeyValue(f *testing.F) {
 	db := newDB(f)
 	database.FuzzKeyValue(f, db)
@@ -0,0 +1,382 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pebbledb
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/pebble"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var levelLabels = []string{"level"}
+
+type metrics struct {
+	// estimated number of bytes that need to be compacted for the LSM to reach
+	// a stable state
+	compactionDebt prometheus.Gauge
+	// number of compactions currently in progress
+	compactionsInProgress prometheus.Gauge
+	// total number of compactions performed
+	compactions prometheus.Counter
+	// total amount of time (in ns) spent compacting
+	compactionDuration prometheus.Counter
+	// total number of flushes performed
+	flushes prometheus.Counter
+
+	// read amplification of the LSM, which is the number of L0 sublevels plus
+	// the number of non-empty levels below L0
+	readAmplification prometheus.Gauge
+
+	// number of bytes allocated by memtables and large batches
+	memTableSize prometheus.Gauge
+	// number of memtables
+	memTableCount prometheus.Gauge
+	// number of bytes held by memtables that are no longer referenced by the
+	// current DB state
+	memTableZombieSize prometheus.Gauge
+
+	// number of bytes of cached blocks
+	blockCacheSize prometheus.Gauge
+	// total number of block cache hits
+	blockCacheHits prometheus.Counter
+	// total number of block cache misses
+	blockCacheMisses prometheus.Counter
+
+	// number of live WAL files
+	walFiles prometheus.Gauge
+	// physical size of the WAL files on disk
+	walSize prometheus.Gauge
+	// total number of logical bytes written to the WAL
+	walBytesIn prometheus.Counter
+	// total number of physical bytes written to the WAL
+	walBytesWritten prometheus.Counter
+
+	// number of currently open snapshots
+	aliveSnapshots prometheus.Gauge
+	// number of currently open sstable iterators
+	tableIterators prometheus.Gauge
+	// approximate number of tombstones in the database
+	tombstones prometheus.Gauge
+	// total number of bytes used on disk
+	diskUsage prometheus.Gauge
+
+	// number of files per level
+	levelFileCount *prometheus.GaugeVec
+	// number of sublevels per level. Only L0 may have more than one sublevel.
+	levelSublevels *prometheus.GaugeVec
+	// size of each level
+	levelSize *prometheus.GaugeVec
+	// compaction score of each level
+	levelScore *prometheus.GaugeVec
+	// amount of bytes read while compacting each level
+	levelReads *prometheus.CounterVec
+	// amount of bytes written while compacting or flushing into each level
+	levelWrites *prometheus.CounterVec
+
+	// total number of writes that have been stalled
+	writeStalls prometheus.Counter
+	// total amount of time (in ns) that writes have been stalled
+	writeStallDuration prometheus.Counter
+	// set to 1 if writes are currently stalled
+	writeIsStalled prometheus.Gauge
+
+	// stallLock protects [stallStart]
+	stallLock sync.Mutex
+	// stallStart is the time the current write stall began. It is the zero
+	// value if writes are not currently stalled.
+	stallStart time.Time
+
+	// updateLock serializes calls to [updateMetrics] and protects
+	// [priorMetrics]
+	updateLock   sync.Mutex
+	priorMetrics *pebble.Metrics
+}
+
+func newMetrics(reg prometheus.Registerer) (*metrics, error) {
+	m := &metrics{
+		compactionDebt: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "compaction_debt",
+			Help: "estimated number of bytes that need to be compacted to reach a stable state",
+		}),
+		compactionsInProgress: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "compactions_in_progress",
+			Help: "number of compactions currently in progress",
+		}),
+		compactions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "compactions",
+			Help: "total number of compactions performed",
+		}),
+		compactionDuration: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "compaction_duration",
+			Help: "cumulative amount of time (in ns) spent compacting",
+		}),
+		flushes: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "flushes",
+			Help: "total number of memtable flushes performed",
+		}),
+
+		readAmplification: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "read_amplification",
+			Help: "number of sublevels that may need to be read for a point lookup",
+		}),
+
+		memTableSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "memtable_size",
+			Help: "number of bytes allocated by memtables and large batches",
+		}),
+		memTableCount: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "memtable_count",
+			Help: "number of memtables",
+		}),
+		memTableZombieSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "memtable_zombie_size",
+			Help: "number of bytes held by memtables that are no longer referenced",
+		}),
+
+		blockCacheSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "block_cache_size",
+			Help: "total size of cached blocks",
+		}),
+		blockCacheHits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "block_cache_hits",
+			Help: "cumulative number of block cache hits",
+		}),
+		blockCacheMisses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "block_cache_misses",
+			Help: "cumulative number of block cache misses",
+		}),
+
+		walFiles: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "wal_files",
+			Help: "number of live WAL files",
+		}),
+		walSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "wal_size",
+			Help: "physical size of the live WAL files",
+		}),
+		walBytesIn: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wal_bytes_in",
+			Help: "cumulative number of logical bytes written to the WAL",
+		}),
+		walBytesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "wal_bytes_written",
+			Help: "cumulative number of physical bytes written to the WAL",
+		}),
+
+		aliveSnapshots: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "alive_snapshots",
+			Help: "number of currently alive snapshots",
+		}),
+		tableIterators: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "table_iterators",
+			Help: "number of currently open sstable iterators",
+		}),
+		tombstones: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "tombstones",
+			Help: "approximate number of tombstones in the database",
+		}),
+		diskUsage: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "disk_usage",
+			Help: "number of bytes used on disk",
+		}),
+
+		levelFileCount: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "file_count",
+				Help: "number of files by level",
+			},
+			levelLabels,
+		),
+		levelSublevels: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "sublevels",
+				Help: "number of sublevels by level",
+			},
+			levelLabels,
+		),
+		levelSize: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "size",
+				Help: "amount of bytes allocated by level",
+			},
+			levelLabels,
+		),
+		levelScore: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "score",
+				Help: "compaction score by level",
+			},
+			levelLabels,
+		),
+		levelReads: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "reads",
+				Help: "amount of bytes read during compaction by level",
+			},
+			levelLabels,
+		),
+		levelWrites: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "writes",
+				Help: "amount of bytes written during compaction and flushes by level",
+			},
+			levelLabels,
+		),
+
+		writeStalls: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "write_stalls",
+			Help: "number of cumulative writes that have been stalled",
+		}),
+		writeStallDuration: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "write_stall_duration",
+			Help: "amount of time (in ns) that writes have been stalled",
+		}),
+		writeIsStalled: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "write_stalled",
+			Help: "1 if writes are currently stalled",
+		}),
+
+		priorMetrics: &pebble.Metrics{},
+	}
+
+	collectors := m.collectors()
+	for i, collector := range collectors {
+		if err := reg.Register(collector); err != nil {
+			unregister(reg, collectors[:i])
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// collectors returns every metric that is registered by newMetrics.
+func (m *metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.compactionDebt,
+		m.compactionsInProgress,
+		m.compactions,
+		m.compactionDuration,
+		m.flushes,
+
+		m.readAmplification,
+
+		m.memTableSize,
+		m.memTableCount,
+		m.memTableZombieSize,
+
+		m.blockCacheSize,
+		m.blockCacheHits,
+		m.blockCacheMisses,
+
+		m.walFiles,
+		m.walSize,
+		m.walBytesIn,
+		m.walBytesWritten,
+
+		m.aliveSnapshots,
+		m.tableIterators,
+		m.tombstones,
+		m.diskUsage,
+
+		m.levelFileCount,
+		m.levelSublevels,
+		m.levelSize,
+		m.levelScore,
+		m.levelReads,
+		m.levelWrites,
+
+		m.writeStalls,
+		m.writeStallDuration,
+		m.writeIsStalled,
+	}
+}
+
+// unregister removes [collectors] from [reg].
+func unregister(reg prometheus.Registerer, collectors []prometheus.Collector) {
+	for _, collector := range collectors {
+		reg.Unregister(collector)
+	}
+}
+
+// eventListener returns the pebble event listener that records write stalls.
+func (m *metrics) eventListener() pebble.EventListener {
+	return pebble.EventListener{
+		WriteStallBegin: func(pebble.WriteStallBeginInfo) {
+			m.stallLock.Lock()
+			defer m.stallLock.Unlock()
+
+			m.stallStart = time.Now()
+			m.writeStalls.Inc()
+			m.writeIsStalled.Set(1)
+		},
+		WriteStallEnd: func() {
+			m.stallLock.Lock()
+			defer m.stallLock.Unlock()
+
+			if !m.stallStart.IsZero() {
+				m.writeStallDuration.Add(float64(time.Since(m.stallStart)))
+				m.stallStart = time.Time{}
+			}
+			m.writeIsStalled.Set(0)
+		},
+	}
+}
+
+func (db *Database) updateMetrics() {
+	metrics := db.metrics
+	metrics.updateLock.Lock()
+	defer metrics.updateLock.Unlock()
+
+	prior := metrics.priorMetrics
+	current := db.pebbleDB.Metrics()
+
+	metrics.compactionDebt.Set(float64(current.Compact.EstimatedDebt))
+	metrics.compactionsInProgress.Set(float64(current.Compact.NumInProgress))
+	metrics.compactions.Add(float64(current.Compact.Count - prior.Compact.Count))
+	metrics.compactionDuration.Add(float64(current.Compact.Duration - prior.Compact.Duration))
+	metrics.flushes.Add(float64(current.Flush.Count - prior.Flush.Count))
+
+	metrics.readAmplification.Set(float64(current.ReadAmp()))
+
+	metrics.memTableSize.Set(float64(current.MemTable.Size))
+	metrics.memTableCount.Set(float64(current.MemTable.Count))
+	metrics.memTableZombieSize.Set(float64(current.MemTable.ZombieSize))
+
+	metrics.blockCacheSize.Set(float64(current.BlockCache.Size))
+	metrics.blockCacheHits.Add(float64(current.BlockCache.Hits - prior.BlockCache.Hits))
+	metrics.blockCacheMisses.Add(float64(current.BlockCache.Misses - prior.BlockCache.Misses))
+
+	metrics.walFiles.Set(float64(current.WAL.Files))
+	metrics.walSize.Set(float64(current.WAL.PhysicalSize))
+	metrics.walBytesIn.Add(float64(current.WAL.BytesIn - prior.WAL.BytesIn))
+	metrics.walBytesWritten.Add(float64(current.WAL.BytesWritten - prior.WAL.BytesWritten))
+
+	metrics.aliveSnapshots.Set(float64(current.Snapshots.Count))
+	metrics.tableIterators.Set(float64(current.TableIters))
+	metrics.tombstones.Set(float64(current.Keys.TombstoneCount))
+	metrics.diskUsage.Set(float64(current.DiskSpaceUsage()))
+
+	for level := range current.Levels {
+		levelStr := strconv.Itoa(level)
+		currentLevel := &current.Levels[level]
+		priorLevel := &prior.Levels[level]
+
+		metrics.levelFileCount.WithLabelValues(levelStr).Set(float64(currentLevel.NumFiles))
+		metrics.levelSublevels.WithLabelValues(levelStr).Set(float64(currentLevel.Sublevels))
+		metrics.levelSize.WithLabelValues(levelStr).Set(float64(currentLevel.Size))
+		metrics.levelScore.WithLabelValues(levelStr).Set(currentLevel.Score)
+		metrics.levelReads.WithLabelValues(levelStr).Add(float64(currentLevel.BytesRead - priorLevel.BytesRead))
+		metrics.levelWrites.WithLabelValues(levelStr).Add(float64(
+			currentLevel.BytesCompacted + currentLevel.BytesFlushed -
+				priorLevel.BytesCompacted - priorLevel.BytesFlushed,
+		))
+	}
+
+	// update the priorMetrics to update the counters correctly next time this
+	// method is called
+	metrics.priorMetrics = current
+}
@@ -0,0 +1,76 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pebbledb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/perms"
+)
+
+func TestMetrics(t *testing.T) {
+	require := require.New(t)
+
+	reg := prometheus.NewRegistry()
+	dbIntf, err := New(t.TempDir(), nil, logging.NoLog{}, reg)
+	require.NoError(err)
+	db := dbIntf.(*Database)
+
+	for i := byte(0); i < 10; i++ {
+		require.NoError(db.Put([]byte{i}, []byte{i}))
+	}
+	db.updateMetrics()
+
+	require.Positive(testutil.ToFloat64(db.metrics.walBytesIn))
+	require.Positive(testutil.ToFloat64(db.metrics.memTableSize))
+
+	count, err := testutil.GatherAndCount(reg, "compaction_debt", "read_amplification", "write_stalls")
+	require.NoError(err)
+	require.Equal(3, count)
+
+	require.NoError(db.Close())
+}
+
+func TestMetricsDisabled(t *testing.T) {
+	require := require.New(t)
+
+	reg := prometheus.NewRegistry()
+	dbIntf, err := New(t.TempDir(), []byte(`{"metricUpdateFrequency":0}`), logging.NoLog{}, reg)
+	require.NoError(err)
+	db := dbIntf.(*Database)
+	require.Nil(db.metrics)
+
+	families, err := reg.Gather()
+	require.NoError(err)
+	require.Empty(families)
+
+	require.NoError(db.Close())
+}
+
+func TestMetricsUnregisteredOnOpenFailure(t *testing.T) {
+	require := require.New(t)
+
+	// A file can't be opened as a database directory.
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(os.WriteFile(file, nil, perms.ReadWrite))
+
+	reg := prometheus.NewRegistry()
+	_, err := New(file, nil, logging.NoLog{}, reg)
+	require.Error(err) //nolint:forbidigo // the error is returned by the filesystem
+
+	families, err := reg.Gather()
+	require.NoError(err)
+	require.Empty(families)
+
+	db, err := New(t.TempDir(), nil, logging.NoLog{}, reg)
+	require.NoError(err)
+	require.NoError(db.Close())
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package pebbledb
+
+import (
+	"slices"
+
+	"github.com/cockroachdb/pebble"
+
+	"github.com/skychains/chain/database"
+)
+
+var _ database.Snapshot = (*snapshot)(nil)
+
+type snapshot struct {
+	db       *Database
+	snapshot *pebble.Snapshot
+
+	// released is protected by [db.lock].
+	released bool
+}
+
+func (s *snapshot) Has(key []byte) (bool, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	if s.db.closed || s.released {
+		return false, database.ErrClosed
+	}
+
+	_, closer, err := s.snapshot.Get(key)
+	if err == pebble.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, updateError(err)
+	}
+	return true, closer.Close()
+}
+
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	if s.db.closed || s.released {
+		return nil, database.ErrClosed
+	}
+
+	data, closer, err := s.snapshot.Get(key)
+	if err != nil {
+		return nil, updateError(err)
+	}
+	return slices.Clone(data), closer.Close()
+}
+
+func (s *snapshot) NewIterator() database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (s *snapshot) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	if s.db.closed || s.released {
+		return &iter{
+			db:     s.db,
+			closed: true,
+			err:    database.ErrClosed,
+		}
+	}
+
+	it, err := s.snapshot.NewIter(keyRange(start, prefix))
+	if err != nil {
+		return &iter{
+			db:     s.db,
+			closed: true,
+			err:    updateError(err),
+		}
+	}
+
+	// Iterators hold their own reference to the snapshotted state, so they
+	// remain valid after the snapshot is released. They are tracked by the
+	// database so that they are closed when the database is closed.
+	iter := &iter{
+		db:   s.db,
+		iter: it,
+	}
+	s.db.openIterators.Add(iter)
+	return iter
+}
+
+func (s *snapshot) Release() {
+	s.db.lock.Lock()
+	defer s.db.lock.Unlock()
+
+	s.release()
+}
+
+// Assumes [s.db.lock] is held.
+func (s *snapshot) release() {
+	if s.released {
+		return
+	}
+
+	s.db.openSnapshots.Remove(s)
+
+	s.released = true
+	_ = s.snapshot.Close()
+}
@@ -5,6 +5,7 @@ package prefixdb
 
 import (
 	"context"
+	"errors"
 	"slices"
 	"sync"
 
@@ -14,9 +15,11 @@ import (
 )
 
 var (
-	_ database.Database = (*Database)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database    = (*Database)(nil)
+	_ database.Snapshotter = (*Database)(nil)
+	_ database.Snapshot    = (*snapshot)(nil)
+	_ database.Batch       = (*batch)(nil)
+	_ database.Iterator    = (*iterator)(nil)
 )
 
 // Database partitions a database into a sub-database by prefixing all keys with
@@ -151,6 +154,26 @@ func (db *Database) Delete(key []byte) error {
 	return db.db.Delete(*prefixedKey)
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return database.ErrClosed
+	}
+
+	prefixedStart := db.prefix(start)
+	defer db.bufferPool.Put(prefixedStart)
+
+	if len(limit) == 0 {
+		return db.db.DeleteRange(*prefixedStart, db.dbLimit)
+	}
+	prefixedLimit := db.prefix(limit)
+	defer db.bufferPool.Put(prefixedLimit)
+
+	return db.db.DeleteRange(*prefixedStart, *prefixedLimit)
+}
+
 func (db *Database) NewBatch() database.Batch {
 	return &batch{
 		Batch: db.db.NewBatch(),
@@ -181,7 +204,11 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 			Err: database.ErrClosed,
 		}
 	}
+	return db.newIterator(db.db, start, prefix)
+}
 
+// Assumes [db.lock] is held.
+func (db *Database) newIterator(iteratee database.Iteratee, start, prefix []byte) database.Iterator {
 	prefixedStart := db.prefix(start)
 	defer db.bufferPool.Put(prefixedStart)
 
@@ -189,11 +216,36 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	defer db.bufferPool.Put(prefixedPrefix)
 
 	return &iterator{
-		Iterator: db.db.NewIteratorWithStartAndPrefix(*prefixedStart, *prefixedPrefix),
+		Iterator: iteratee.NewIteratorWithStartAndPrefix(*prefixedStart, *prefixedPrefix),
 		db:       db,
 	}
 }
 
+// NewSnapshot returns a snapshot of the keys in this database. If the
+// underlying database doesn't support snapshots, errors.ErrUnsupported is
+// returned.
+func (db *Database) NewSnapshot() (database.Snapshot, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	snapshotter, ok := db.db.(database.Snapshotter)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	s, err := snapshotter.NewSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot{
+		Snapshot: s,
+		db:       db,
+	}, nil
+}
+
 func (db *Database) Compact(start, limit []byte) error {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
@@ -267,6 +319,11 @@ type batchOp struct {
 	Key    *[]byte
 	Value  []byte
 	Delete bool
+
+	// If true, this op deletes the range [Key, Limit). A nil Limit means the
+	// range is unbounded.
+	DeleteRange bool
+	Limit       *[]byte
 }
 
 func (b *batch) Put(key, value []byte) error {
@@ -288,6 +345,22 @@ func (b *batch) Delete(key []byte) error {
 	return b.Batch.Delete(*prefixedKey)
 }
 
+func (b *batch) DeleteRange(start, limit []byte) error {
+	prefixedStart := b.db.prefix(start)
+	op := batchOp{
+		Key:         prefixedStart,
+		DeleteRange: true,
+	}
+	if len(limit) == 0 {
+		b.ops = append(b.ops, op)
+		return b.Batch.DeleteRange(*prefixedStart, b.db.dbLimit)
+	}
+
+	op.Limit = b.db.prefix(limit)
+	b.ops = append(b.ops, op)
+	return b.Batch.DeleteRange(*prefixedStart, *op.Limit)
+}
+
 // Write flushes any accumulated data to the memory database.
 func (b *batch) Write() error {
 	b.db.lock.RLock()
@@ -307,6 +380,9 @@ func (b *batch) Reset() {
 	// value argument to w.Put.
 	for _, op := range b.ops {
 		b.db.bufferPool.Put(op.Key)
+		if op.Limit != nil {
+			b.db.bufferPool.Put(op.Limit)
+		}
 	}
 
 	// Clear b.writes
@@ -322,11 +398,20 @@ func (b *batch) Reset() {
 func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
 	for _, op := range b.ops {
 		keyWithoutPrefix := (*op.Key)[len(b.db.dbPrefix):]
-		if op.Delete {
+		switch {
+		case op.DeleteRange:
+			var limitWithoutPrefix []byte
+			if op.Limit != nil {
+				limitWithoutPrefix = (*op.Limit)[len(b.db.dbPrefix):]
+			}
+			if err := database.ReplayDeleteRange(w, keyWithoutPrefix, limitWithoutPrefix); err != nil {
+				return err
+			}
+		case op.Delete:
 			if err := w.Delete(keyWithoutPrefix); err != nil {
 				return err
 			}
-		} else {
+		default:
 			if err := w.Put(keyWithoutPrefix, op.Value); err != nil {
 				return err
 			}
@@ -335,6 +420,63 @@ func (b *batch) Replay(w database.KeyValueWriterDeleter) error {
 	return nil
 }
 
+// snapshot prefixes all keys read from the underlying snapshot with the
+// database's prefix.
+type snapshot struct {
+	database.Snapshot
+	db *Database
+}
+
+func (s *snapshot) Has(key []byte) (bool, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	if s.db.closed {
+		return false, database.ErrClosed
+	}
+	prefixedKey := s.db.prefix(key)
+	defer s.db.bufferPool.Put(prefixedKey)
+
+	return s.Snapshot.Has(*prefixedKey)
+}
+
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	if s.db.closed {
+		return nil, database.ErrClosed
+	}
+	prefixedKey := s.db.prefix(key)
+	defer s.db.bufferPool.Put(prefixedKey)
+
+	return s.Snapshot.Get(*prefixedKey)
+}
+
+func (s *snapshot) NewIterator() database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+func (s *snapshot) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	s.db.lock.RLock()
+	defer s.db.lock.RUnlock()
+
+	if s.db.closed {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	return s.db.newIterator(s.Snapshot, start, prefix)
+}
+
 type iterator struct {
 	database.Iterator
 	db *Database
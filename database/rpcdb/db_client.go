@@ -18,9 +18,11 @@ import (
 )
 
 var (
-	_ database.Database = (*DatabaseClient)(nil)
-	_ database.Batch    = (*batch)(nil)
-	_ database.Iterator = (*iterator)(nil)
+	_ database.Database    = (*DatabaseClient)(nil)
+	_ database.Snapshotter = (*DatabaseClient)(nil)
+	_ database.Snapshot    = (*snapshot)(nil)
+	_ database.Batch       = (*batch)(nil)
+	_ database.Iterator    = (*iterator)(nil)
 )
 
 // DatabaseClient is an implementation of database that talks over RPC.
@@ -80,6 +82,18 @@ func (db *DatabaseClient) Delete(key []byte) error {
 	return ErrEnumToError[resp.Err]
 }
 
+// DeleteRange removes every key in the range [start, limit) from the database
+func (db *DatabaseClient) DeleteRange(start, limit []byte) error {
+	resp, err := db.client.DeleteRange(context.Background(), &rpcdbpb.DeleteRangeRequest{
+		Start: start,
+		Limit: limit,
+	})
+	if err != nil {
+		return err
+	}
+	return ErrEnumToError[resp.Err]
+}
+
 // NewBatch returns a new batch
 func (db *DatabaseClient) NewBatch() database.Batch {
 	return &batch{db: db}
@@ -111,6 +125,21 @@ func (db *DatabaseClient) NewIteratorWithStartAndPrefix(start, prefix []byte) da
 	return newIterator(db, resp.Id)
 }
 
+// NewSnapshot attempts to create a snapshot of the remote database
+func (db *DatabaseClient) NewSnapshot() (database.Snapshot, error) {
+	resp, err := db.client.NewSnapshot(context.Background(), &rpcdbpb.NewSnapshotRequest{})
+	if err != nil {
+		return nil, err
+	}
+	if err := ErrEnumToError[resp.Err]; err != nil {
+		return nil, err
+	}
+	return &snapshot{
+		db: db,
+		id: resp.Id,
+	}, nil
+}
+
 // Compact attempts to optimize the space utilization in the provided range
 func (db *DatabaseClient) Compact(start, limit []byte) error {
 	resp, err := db.client.Compact(context.Background(), &rpcdbpb.CompactRequest{
@@ -149,12 +178,23 @@ type batch struct {
 }
 
 func (b *batch) Write() error {
+	// The server applies range deletions before puts and deletes, so only the
+	// puts and deletes that happened after every range deletion covering their
+	// key are sent.
 	request := &rpcdbpb.WriteBatchRequest{}
 	keySet := set.NewSet[string](len(b.Ops))
 	for i := len(b.Ops) - 1; i >= 0; i-- {
 		op := b.Ops[i]
+		if op.DeleteRange {
+			request.DeleteRanges = append(request.DeleteRanges, &rpcdbpb.DeleteRangeRequest{
+				Start: op.Key,
+				Limit: op.Limit,
+			})
+			continue
+		}
+
 		key := string(op.Key)
-		if keySet.Contains(key) {
+		if keySet.Contains(key) || isDeleted(request.DeleteRanges, op.Key) {
 			continue
 		}
 		keySet.Add(key)
@@ -182,6 +222,97 @@ func (b *batch) Inner() database.Batch {
 	return b
 }
 
+// isDeleted returns true if [key] is in any of [ranges].
+func isDeleted(ranges []*rpcdbpb.DeleteRangeRequest, key []byte) bool {
+	for _, r := range ranges {
+		if database.InRange(key, r.Start, r.Limit) {
+			return true
+		}
+	}
+	return false
+}
+
+type snapshot struct {
+	db *DatabaseClient
+	id uint64
+
+	released    utils.Atomic[bool]
+	releaseOnce sync.Once
+}
+
+// Has attempts to return if the snapshot has a key with the provided value.
+func (s *snapshot) Has(key []byte) (bool, error) {
+	if s.released.Get() {
+		return false, database.ErrClosed
+	}
+	resp, err := s.db.client.SnapshotHas(context.Background(), &rpcdbpb.SnapshotHasRequest{
+		Id:  s.id,
+		Key: key,
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.Has, ErrEnumToError[resp.Err]
+}
+
+// Get attempts to return the value that was mapped to the key that was
+// provided
+func (s *snapshot) Get(key []byte) ([]byte, error) {
+	if s.released.Get() {
+		return nil, database.ErrClosed
+	}
+	resp, err := s.db.client.SnapshotGet(context.Background(), &rpcdbpb.SnapshotGetRequest{
+		Id:  s.id,
+		Key: key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Value, ErrEnumToError[resp.Err]
+}
+
+func (s *snapshot) NewIterator() database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (s *snapshot) NewIteratorWithStart(start []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (s *snapshot) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return s.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix returns a new iterator over the snapshot
+func (s *snapshot) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	if s.released.Get() {
+		return &database.IteratorError{
+			Err: database.ErrClosed,
+		}
+	}
+	resp, err := s.db.client.SnapshotNewIteratorWithStartAndPrefix(context.Background(), &rpcdbpb.SnapshotNewIteratorWithStartAndPrefixRequest{
+		Id:     s.id,
+		Start:  start,
+		Prefix: prefix,
+	})
+	if err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+	return newIterator(s.db, resp.Id)
+}
+
+// Release frees the resources held by the snapshot on the remote database
+func (s *snapshot) Release() {
+	s.releaseOnce.Do(func() {
+		s.released.Set(true)
+		_, _ = s.db.client.SnapshotRelease(context.Background(), &rpcdbpb.SnapshotReleaseRequest{
+			Id: s.id,
+		})
+	})
+}
+
 type iterator struct {
 	db *DatabaseClient
 	id uint64
@@ -19,7 +19,10 @@ import (
 
 const iterationBatchSize = 128 * units.KiB
 
-var errUnknownIterator = errors.New("unknown iterator")
+var (
+	errUnknownIterator = errors.New("unknown iterator")
+	errUnknownSnapshot = errors.New("unknown snapshot")
+)
 
 // DatabaseServer is a database that is managed over RPC.
 type DatabaseServer struct {
@@ -35,6 +38,12 @@ type DatabaseServer struct {
 	iteratorLock   sync.RWMutex
 	nextIteratorID uint64
 	iterators      map[uint64]database.Iterator
+
+	// snapshotLock protects [nextSnapshotID] and [snapshots] from concurrent
+	// modifications.
+	snapshotLock   sync.RWMutex
+	nextSnapshotID uint64
+	snapshots      map[uint64]database.Snapshot
 }
 
 // NewServer returns a database instance that is managed remotely
@@ -42,6 +51,7 @@ func NewServer(db database.Database) *DatabaseServer {
 	return &DatabaseServer{
 		db:        db,
 		iterators: make(map[uint64]database.Iterator),
+		snapshots: make(map[uint64]database.Snapshot),
 	}
 }
 
@@ -76,6 +86,13 @@ func (db *DatabaseServer) Delete(_ context.Context, req *rpcdbpb.DeleteRequest)
 	return &rpcdbpb.DeleteResponse{Err: ErrorToErrEnum[err]}, ErrorToRPCError(err)
 }
 
+// DeleteRange delegates the DeleteRange call to the managed database and
+// returns the result
+func (db *DatabaseServer) DeleteRange(_ context.Context, req *rpcdbpb.DeleteRangeRequest) (*rpcdbpb.DeleteRangeResponse, error) {
+	err := db.db.DeleteRange(req.Start, req.Limit)
+	return &rpcdbpb.DeleteRangeResponse{Err: ErrorToErrEnum[err]}, ErrorToRPCError(err)
+}
+
 // Compact delegates the Compact call to the managed database and returns the
 // result
 func (db *DatabaseServer) Compact(_ context.Context, req *rpcdbpb.CompactRequest) (*rpcdbpb.CompactResponse, error) {
@@ -106,6 +123,13 @@ func (db *DatabaseServer) HealthCheck(ctx context.Context, _ *emptypb.Empty) (*r
 // the internal database
 func (db *DatabaseServer) WriteBatch(_ context.Context, req *rpcdbpb.WriteBatchRequest) (*rpcdbpb.WriteBatchResponse, error) {
 	batch := db.db.NewBatch()
+	for _, deleteRange := range req.DeleteRanges {
+		if err := batch.DeleteRange(deleteRange.Start, deleteRange.Limit); err != nil {
+			return &rpcdbpb.WriteBatchResponse{
+				Err: ErrorToErrEnum[err],
+			}, ErrorToRPCError(err)
+		}
+	}
 	for _, put := range req.Puts {
 		if err := batch.Put(put.Key, put.Value); err != nil {
 			return &rpcdbpb.WriteBatchResponse{
@@ -131,14 +155,19 @@ func (db *DatabaseServer) WriteBatch(_ context.Context, req *rpcdbpb.WriteBatchR
 // ID
 func (db *DatabaseServer) NewIteratorWithStartAndPrefix(_ context.Context, req *rpcdbpb.NewIteratorWithStartAndPrefixRequest) (*rpcdbpb.NewIteratorWithStartAndPrefixResponse, error) {
 	it := db.db.NewIteratorWithStartAndPrefix(req.Start, req.Prefix)
+	return &rpcdbpb.NewIteratorWithStartAndPrefixResponse{
+		Id: db.addIterator(it),
+	}, nil
+}
 
+func (db *DatabaseServer) addIterator(it database.Iterator) uint64 {
 	db.iteratorLock.Lock()
 	defer db.iteratorLock.Unlock()
 
 	id := db.nextIteratorID
 	db.iterators[id] = it
 	db.nextIteratorID++
-	return &rpcdbpb.NewIteratorWithStartAndPrefixResponse{Id: id}, nil
+	return id
 }
 
 // IteratorNext attempts to call next on the requested iterator
@@ -195,3 +224,93 @@ func (db *DatabaseServer) IteratorRelease(_ context.Context, req *rpcdbpb.Iterat
 	it.Release()
 	return &rpcdbpb.IteratorReleaseResponse{Err: ErrorToErrEnum[err]}, ErrorToRPCError(err)
 }
+
+// NewSnapshot creates a snapshot of the managed database and returns the
+// snapshot ID
+func (db *DatabaseServer) NewSnapshot(context.Context, *rpcdbpb.NewSnapshotRequest) (*rpcdbpb.NewSnapshotResponse, error) {
+	snapshotter, ok := db.db.(database.Snapshotter)
+	if !ok {
+		return &rpcdbpb.NewSnapshotResponse{
+			Err: rpcdbpb.Error_ERROR_UNSUPPORTED,
+		}, nil
+	}
+	snapshot, err := snapshotter.NewSnapshot()
+	if err != nil {
+		return &rpcdbpb.NewSnapshotResponse{
+			Err: ErrorToErrEnum[err],
+		}, ErrorToRPCError(err)
+	}
+
+	db.snapshotLock.Lock()
+	defer db.snapshotLock.Unlock()
+
+	id := db.nextSnapshotID
+	db.snapshots[id] = snapshot
+	db.nextSnapshotID++
+	return &rpcdbpb.NewSnapshotResponse{Id: id}, nil
+}
+
+// SnapshotHas delegates the Has call to the requested snapshot and returns the
+// result
+func (db *DatabaseServer) SnapshotHas(_ context.Context, req *rpcdbpb.SnapshotHasRequest) (*rpcdbpb.HasResponse, error) {
+	snapshot, err := db.getSnapshot(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	has, err := snapshot.Has(req.Key)
+	return &rpcdbpb.HasResponse{
+		Has: has,
+		Err: ErrorToErrEnum[err],
+	}, ErrorToRPCError(err)
+}
+
+// SnapshotGet delegates the Get call to the requested snapshot and returns the
+// result
+func (db *DatabaseServer) SnapshotGet(_ context.Context, req *rpcdbpb.SnapshotGetRequest) (*rpcdbpb.GetResponse, error) {
+	snapshot, err := db.getSnapshot(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	value, err := snapshot.Get(req.Key)
+	return &rpcdbpb.GetResponse{
+		Value: value,
+		Err:   ErrorToErrEnum[err],
+	}, ErrorToRPCError(err)
+}
+
+// SnapshotNewIteratorWithStartAndPrefix allocates an iterator over the
+// requested snapshot and returns the iterator ID
+func (db *DatabaseServer) SnapshotNewIteratorWithStartAndPrefix(_ context.Context, req *rpcdbpb.SnapshotNewIteratorWithStartAndPrefixRequest) (*rpcdbpb.NewIteratorWithStartAndPrefixResponse, error) {
+	snapshot, err := db.getSnapshot(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	it := snapshot.NewIteratorWithStartAndPrefix(req.Start, req.Prefix)
+	return &rpcdbpb.NewIteratorWithStartAndPrefixResponse{
+		Id: db.addIterator(it),
+	}, nil
+}
+
+// SnapshotRelease releases the resources allocated to a snapshot
+func (db *DatabaseServer) SnapshotRelease(_ context.Context, req *rpcdbpb.SnapshotReleaseRequest) (*rpcdbpb.SnapshotReleaseResponse, error) {
+	db.snapshotLock.Lock()
+	snapshot, exists := db.snapshots[req.Id]
+	delete(db.snapshots, req.Id)
+	db.snapshotLock.Unlock()
+
+	if exists {
+		snapshot.Release()
+	}
+	return &rpcdbpb.SnapshotReleaseResponse{}, nil
+}
+
+func (db *DatabaseServer) getSnapshot(id uint64) (database.Snapshot, error) {
+	db.snapshotLock.RLock()
+	defer db.snapshotLock.RUnlock()
+
+	snapshot, exists := db.snapshots[id]
+	if !exists {
+		return nil, errUnknownSnapshot
+	}
+	return snapshot, nil
+}
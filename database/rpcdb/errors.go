@@ -4,6 +4,8 @@
 package rpcdb
 
 import (
+	"errors"
+
 	"github.com/skychains/chain/database"
 
 	rpcdbpb "github.com/skychains/chain/proto/pb/rpcdb"
@@ -11,12 +13,14 @@ import (
 
 var (
 	ErrEnumToError = map[rpcdbpb.Error]error{
-		rpcdbpb.Error_ERROR_CLOSED:    database.ErrClosed,
-		rpcdbpb.Error_ERROR_NOT_FOUND: database.ErrNotFound,
+		rpcdbpb.Error_ERROR_CLOSED:      database.ErrClosed,
+		rpcdbpb.Error_ERROR_NOT_FOUND:   database.ErrNotFound,
+		rpcdbpb.Error_ERROR_UNSUPPORTED: errors.ErrUnsupported,
 	}
 	ErrorToErrEnum = map[error]rpcdbpb.Error{
-		database.ErrClosed:   rpcdbpb.Error_ERROR_CLOSED,
-		database.ErrNotFound: rpcdbpb.Error_ERROR_NOT_FOUND,
+		database.ErrClosed:    rpcdbpb.Error_ERROR_CLOSED,
+		database.ErrNotFound:  rpcdbpb.Error_ERROR_NOT_FOUND,
+		errors.ErrUnsupported: rpcdbpb.Error_ERROR_UNSUPPORTED,
 	}
 )
 
@@ -7,6 +7,7 @@ package database
 
 import (
 	"bytes"
+	"errors"
 	"io"
 	"math"
 	"math/rand"
@@ -61,6 +62,16 @@ var Tests = map[string]func(t *testing.T, db Database){
 	"ConcurrentBatches":                TestConcurrentBatches,
 	"ManySmallConcurrentKVPairBatches": TestManySmallConcurrentKVPairBatches,
 	"PutGetEmpty":                      TestPutGetEmpty,
+	"DeleteRange":                      TestDeleteRange,
+	"DeleteRangeUnbounded":             TestDeleteRangeUnbounded,
+	"DeleteRangeClosed":                TestDeleteRangeClosed,
+	"BatchDeleteRange":                 TestBatchDeleteRange,
+	"BatchDeleteRangeReplay":           TestBatchDeleteRangeReplay,
+	"BatchDeleteRangeUnboundedPuts":    TestBatchDeleteRangeUnboundedPuts,
+	"Snapshot":                         TestSnapshot,
+	"SnapshotIterator":                 TestSnapshotIterator,
+	"SnapshotRelease":                  TestSnapshotRelease,
+	"SnapshotClosed":                   TestSnapshotClosed,
 }
 
 // TestSimpleKeyValue tests to make sure that simple Put + Get + Delete + Has
@@ -1338,3 +1349,401 @@ func FuzzNewIteratorWithStartAndPrefix(f *testing.F, db Database) {
 		require.NoError(AtomicClear(db, db))
 	})
 }
+
+// requireKeys requires that [db] contains exactly the provided [keys], each
+// mapped to a value equal to its key.
+func requireKeys(t *testing.T, db Iteratee, keys ...[]byte) {
+	require := require.New(t)
+
+	iterator := db.NewIterator()
+	defer iterator.Release()
+
+	// Compare strings as nil and empty slices are treated equivalently.
+	expected := make([]string, len(keys))
+	for i, key := range keys {
+		expected[i] = string(key)
+	}
+
+	got := []string{}
+	for iterator.Next() {
+		require.Equal(string(iterator.Key()), string(iterator.Value()))
+		got = append(got, string(iterator.Key()))
+	}
+	require.NoError(iterator.Error())
+	require.Equal(expected, got)
+}
+
+// TestDeleteRange tests to make sure that DeleteRange removes exactly the keys
+// in the provided range.
+func TestDeleteRange(t *testing.T, db Database) {
+	require := require.New(t)
+
+	keys := [][]byte{
+		{0x00},
+		{0x01},
+		{0x01, 0x00},
+		{0x01, 0xff},
+		{0x02},
+		{0x02, 0x00},
+		{0x03},
+	}
+	for _, key := range keys {
+		require.NoError(db.Put(key, key))
+	}
+
+	// An empty range doesn't remove anything.
+	require.NoError(db.DeleteRange([]byte{0x02}, []byte{0x01}))
+	require.NoError(db.DeleteRange([]byte{0x02}, []byte{0x02}))
+	requireKeys(t, db, keys...)
+
+	require.NoError(db.DeleteRange([]byte{0x01}, []byte{0x02}))
+	requireKeys(t, db,
+		[]byte{0x00},
+		[]byte{0x02},
+		[]byte{0x02, 0x00},
+		[]byte{0x03},
+	)
+
+	has, err := db.Has([]byte{0x01, 0x00})
+	require.NoError(err)
+	require.False(has)
+
+	_, err = db.Get([]byte{0x01})
+	require.Equal(ErrNotFound, err)
+
+	// The range may start and end at keys that aren't in the database.
+	require.NoError(db.DeleteRange([]byte{0x01, 0x00}, []byte{0x02, 0x00, 0x00}))
+	requireKeys(t, db,
+		[]byte{0x00},
+		[]byte{0x03},
+	)
+}
+
+// TestDeleteRangeUnbounded tests to make sure that DeleteRange treats an empty
+// limit as a key after all keys.
+func TestDeleteRangeUnbounded(t *testing.T, db Database) {
+	require := require.New(t)
+
+	keys := [][]byte{
+		{},
+		{0x00},
+		{0x01},
+		{0xff, 0xff},
+	}
+	for _, key := range keys {
+		require.NoError(db.Put(key, key))
+	}
+
+	require.NoError(db.DeleteRange([]byte{0x01}, nil))
+	requireKeys(t, db,
+		[]byte{},
+		[]byte{0x00},
+	)
+
+	require.NoError(db.DeleteRange(nil, nil))
+	requireKeys(t, db)
+}
+
+// TestDeleteRangeClosed tests to make sure that DeleteRange reports a closed
+// error once the database is closed.
+func TestDeleteRangeClosed(t *testing.T, db Database) {
+	require := require.New(t)
+
+	require.NoError(db.Put([]byte{0x01}, []byte{0x01}))
+	require.NoError(db.Close())
+
+	require.Equal(ErrClosed, db.DeleteRange(nil, nil))
+}
+
+// TestBatchDeleteRange tests to make sure that range deletions in a batch are
+// ordered with respect to the other operations in the batch.
+func TestBatchDeleteRange(t *testing.T, db Database) {
+	require := require.New(t)
+
+	require.NoError(db.Put([]byte{0x00}, []byte{0x00}))
+	require.NoError(db.Put([]byte{0x01}, []byte{0x01}))
+	require.NoError(db.Put([]byte{0x02}, []byte{0x02}))
+	require.NoError(db.Put([]byte{0x03}, []byte{0x03}))
+
+	batch := db.NewBatch()
+	require.NotNil(batch)
+
+	require.NoError(batch.Put([]byte{0x01, 0x00}, []byte{0x01, 0x00}))
+	require.NoError(batch.DeleteRange([]byte{0x01}, []byte{0x03}))
+	require.NoError(batch.Put([]byte{0x02}, []byte{0x02}))
+	require.NoError(batch.Put([]byte{0x02, 0x00}, []byte{0x02, 0x00}))
+	require.NoError(batch.DeleteRange([]byte{0x02, 0x00}, nil))
+	require.NoError(batch.Put([]byte{0x04}, []byte{0x04}))
+	require.Positive(batch.Size())
+
+	// The batch shouldn't modify the database until it is written.
+	requireKeys(t, db,
+		[]byte{0x00},
+		[]byte{0x01},
+		[]byte{0x02},
+		[]byte{0x03},
+	)
+
+	require.NoError(batch.Write())
+	requireKeys(t, db,
+		[]byte{0x00},
+		[]byte{0x02},
+		[]byte{0x04},
+	)
+
+	batch.Reset()
+	require.Zero(batch.Size())
+	require.NoError(batch.Write())
+	requireKeys(t, db,
+		[]byte{0x00},
+		[]byte{0x02},
+		[]byte{0x04},
+	)
+}
+
+// TestBatchDeleteRangeUnboundedPuts tests to make sure that an unbounded range
+// deletion in a batch removes keys that were written to the database after the
+// deletion was queued, but before the batch was written.
+func TestBatchDeleteRangeUnboundedPuts(t *testing.T, db Database) {
+	require := require.New(t)
+
+	require.NoError(db.Put([]byte{0x01}, []byte{0x01}))
+
+	batch := db.NewBatch()
+	require.NotNil(batch)
+
+	require.NoError(batch.DeleteRange([]byte{0x01}, nil))
+	require.NoError(batch.Put([]byte{0x03}, []byte{0x03}))
+
+	require.NoError(db.Put([]byte{0x00}, []byte{0x00}))
+	require.NoError(db.Put([]byte{0x02}, []byte{0x02}))
+	require.NoError(db.Put([]byte{0x04}, []byte{0x04}))
+
+	require.NoError(batch.Write())
+	requireKeys(t, db,
+		[]byte{0x00},
+		[]byte{0x03},
+	)
+}
+
+// TestBatchDeleteRangeReplay tests to make sure that range deletions are
+// replayed in order.
+func TestBatchDeleteRangeReplay(t *testing.T, db Database) {
+	ctrl := gomock.NewController(t)
+	require := require.New(t)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+
+	key2 := []byte("hello2")
+	value2 := []byte("world2")
+
+	batch := db.NewBatch()
+	require.NotNil(batch)
+
+	require.NoError(batch.Put(key1, value1))
+	require.NoError(batch.DeleteRange(key1, key2))
+	require.NoError(batch.Put(key2, value2))
+
+	mockBatch := NewMockBatch(ctrl)
+	gomock.InOrder(
+		mockBatch.EXPECT().Put(key1, value1).Times(1),
+		mockBatch.EXPECT().DeleteRange(key1, key2).Times(1),
+		mockBatch.EXPECT().Put(key2, value2).Times(1),
+	)
+	require.NoError(batch.Replay(mockBatch))
+}
+
+// newSnapshot returns a snapshot of [db], skipping the test if [db] doesn't
+// support snapshots.
+func newSnapshot(t *testing.T, db Database) Snapshot {
+	snapshotter, ok := db.(Snapshotter)
+	if !ok {
+		t.Skip("database doesn't implement Snapshotter")
+	}
+	snapshot, err := snapshotter.NewSnapshot()
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("database doesn't support snapshots")
+	}
+	require.NoError(t, err)
+	return snapshot
+}
+
+// TestSnapshot tests to make sure that writes made after a snapshot is created
+// are not visible through the snapshot.
+func TestSnapshot(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+	value1Updated := []byte("world1 updated")
+
+	key2 := []byte("hello2")
+	value2 := []byte("world2")
+
+	key3 := []byte("hello3")
+	value3 := []byte("world3")
+
+	require.NoError(db.Put(key1, value1))
+	require.NoError(db.Put(key2, value2))
+
+	snapshot := newSnapshot(t, db)
+	defer snapshot.Release()
+
+	require.NoError(db.Put(key1, value1Updated))
+	require.NoError(db.Delete(key2))
+	require.NoError(db.Put(key3, value3))
+
+	has, err := snapshot.Has(key1)
+	require.NoError(err)
+	require.True(has)
+
+	value, err := snapshot.Get(key1)
+	require.NoError(err)
+	require.Equal(value1, value)
+
+	has, err = snapshot.Has(key2)
+	require.NoError(err)
+	require.True(has)
+
+	value, err = snapshot.Get(key2)
+	require.NoError(err)
+	require.Equal(value2, value)
+
+	has, err = snapshot.Has(key3)
+	require.NoError(err)
+	require.False(has)
+
+	_, err = snapshot.Get(key3)
+	require.Equal(ErrNotFound, err)
+
+	// The database itself should reflect the latest writes.
+	value, err = db.Get(key1)
+	require.NoError(err)
+	require.Equal(value1Updated, value)
+
+	has, err = db.Has(key2)
+	require.NoError(err)
+	require.False(has)
+}
+
+// TestSnapshotIterator tests to make sure that iterators created from a
+// snapshot iterate over the contents of the database at the time the snapshot
+// was created.
+func TestSnapshotIterator(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key1 := []byte("hello1")
+	value1 := []byte("world1")
+
+	key2 := []byte("hello2")
+	value2 := []byte("world2")
+
+	key3 := []byte("goodbye3")
+	value3 := []byte("world3")
+
+	key4 := []byte("hello4")
+	value4 := []byte("world4")
+
+	require.NoError(db.Put(key1, value1))
+	require.NoError(db.Put(key2, value2))
+	require.NoError(db.Put(key3, value3))
+
+	snapshot := newSnapshot(t, db)
+	defer snapshot.Release()
+
+	require.NoError(db.Delete(key1))
+	require.NoError(db.Put(key2, value1))
+	require.NoError(db.Put(key4, value4))
+
+	{
+		iterator := snapshot.NewIterator()
+		defer iterator.Release()
+
+		require.True(iterator.Next())
+		require.Equal(key3, iterator.Key())
+		require.Equal(value3, iterator.Value())
+
+		require.True(iterator.Next())
+		require.Equal(key1, iterator.Key())
+		require.Equal(value1, iterator.Value())
+
+		require.True(iterator.Next())
+		require.Equal(key2, iterator.Key())
+		require.Equal(value2, iterator.Value())
+
+		require.False(iterator.Next())
+		require.NoError(iterator.Error())
+	}
+
+	{
+		iterator := snapshot.NewIteratorWithStartAndPrefix(key2, []byte("h"))
+		defer iterator.Release()
+
+		require.True(iterator.Next())
+		require.Equal(key2, iterator.Key())
+		require.Equal(value2, iterator.Value())
+
+		require.False(iterator.Next())
+		require.NoError(iterator.Error())
+	}
+}
+
+// TestSnapshotRelease tests to make sure that a released snapshot reports a
+// closed error and that releasing a snapshot doesn't affect the database.
+func TestSnapshotRelease(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key := []byte("hello")
+	value := []byte("world")
+
+	require.NoError(db.Put(key, value))
+
+	snapshot := newSnapshot(t, db)
+	snapshot.Release()
+	snapshot.Release()
+
+	_, err := snapshot.Has(key)
+	require.Equal(ErrClosed, err)
+
+	_, err = snapshot.Get(key)
+	require.Equal(ErrClosed, err)
+
+	iterator := snapshot.NewIterator()
+	defer iterator.Release()
+
+	require.False(iterator.Next())
+	require.Equal(ErrClosed, iterator.Error())
+
+	got, err := db.Get(key)
+	require.NoError(err)
+	require.Equal(value, got)
+}
+
+// TestSnapshotClosed tests to make sure that a snapshot reports a closed error
+// once the database it was created from is closed.
+func TestSnapshotClosed(t *testing.T, db Database) {
+	require := require.New(t)
+
+	key := []byte("hello")
+	value := []byte("world")
+
+	require.NoError(db.Put(key, value))
+
+	snapshot := newSnapshot(t, db)
+	defer snapshot.Release()
+
+	require.NoError(db.Close())
+
+	_, err := snapshot.Has(key)
+	require.Equal(ErrClosed, err)
+
+	_, err = snapshot.Get(key)
+	require.Equal(ErrClosed, err)
+
+	iterator := snapshot.NewIterator()
+	defer iterator.Release()
+
+	require.False(iterator.Next())
+	require.Equal(ErrClosed, iterator.Error())
+}
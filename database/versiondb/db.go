@@ -35,6 +35,10 @@ type Database struct {
 	mem   map[string]valueDelete
 	db    database.Database
 	batch database.Batch
+
+	// Ranges of keys that have been deleted from the underlying database.
+	// Entries in [mem] take precedence over these ranges.
+	deletedRanges []keyRange
 }
 
 type valueDelete struct {
@@ -42,6 +46,10 @@ type valueDelete struct {
 	delete bool
 }
 
+type keyRange struct {
+	start, limit []byte
+}
+
 // New returns a new versioned database
 func New(db database.Database) *Database {
 	return &Database{
@@ -61,6 +69,9 @@ func (db *Database) Has(key []byte) (bool, error) {
 	if val, has := db.mem[string(key)]; has {
 		return !val.delete, nil
 	}
+	if isDeleted(db.deletedRanges, key) {
+		return false, nil
+	}
 	return db.db.Has(key)
 }
 
@@ -77,6 +88,9 @@ func (db *Database) Get(key []byte) ([]byte, error) {
 		}
 		return slices.Clone(val.value), nil
 	}
+	if isDeleted(db.deletedRanges, key) {
+		return nil, database.ErrNotFound
+	}
 	return db.db.Get(key)
 }
 
@@ -102,6 +116,40 @@ func (db *Database) Delete(key []byte) error {
 	return nil
 }
 
+func (db *Database) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if db.mem == nil {
+		return database.ErrClosed
+	}
+	db.deleteRange(start, limit)
+	return nil
+}
+
+// Assumes [db.lock] is held.
+func (db *Database) deleteRange(start, limit []byte) {
+	for key := range db.mem {
+		if database.InRange([]byte(key), start, limit) {
+			delete(db.mem, key)
+		}
+	}
+	db.deletedRanges = append(db.deletedRanges, keyRange{
+		start: slices.Clone(start),
+		limit: slices.Clone(limit),
+	})
+}
+
+// isDeleted returns true if [key] is in any of [ranges].
+func isDeleted(ranges []keyRange, key []byte) bool {
+	for _, r := range ranges {
+		if database.InRange(key, r.start, r.limit) {
+			return true
+		}
+	}
+	return false
+}
+
 func (db *Database) NewBatch() database.Batch {
 	return &batch{db: db}
 }
@@ -143,10 +191,11 @@ func (db *Database) NewIteratorWithStartAndPrefix(start, prefix []byte) database
 	}
 
 	return &iterator{
-		db:       db,
-		Iterator: db.db.NewIteratorWithStartAndPrefix(start, prefix),
-		keys:     keys,
-		values:   values,
+		db:            db,
+		Iterator:      db.db.NewIteratorWithStartAndPrefix(start, prefix),
+		keys:          keys,
+		values:        values,
+		deletedRanges: slices.Clone(db.deletedRanges),
 	}
 }
 
@@ -209,10 +258,11 @@ func (db *Database) Abort() {
 
 func (db *Database) abort() {
 	clear(db.mem)
+	db.deletedRanges = nil
 }
 
-// CommitBatch returns a batch that contains all uncommitted puts/deletes.
-// Calling Write() on the returned batch causes the puts/deletes to be
+// CommitBatch returns a batch that contains all uncommitted puts/deletes and
+// range deletions. Calling Write() on the returned batch causes them to be
 // written to the underlying database. The returned batch should be written before
 // future calls to this DB unless the batch will never be written.
 func (db *Database) CommitBatch() (database.Batch, error) {
@@ -230,6 +280,13 @@ func (db *Database) commitBatch() (database.Batch, error) {
 	}
 
 	db.batch.Reset()
+	// The range deletions must be written before the puts/deletes in memory,
+	// as [mem] holds the writes that happened after the ranges were deleted.
+	for _, r := range db.deletedRanges {
+		if err := db.batch.DeleteRange(r.start, r.limit); err != nil {
+			return nil, err
+		}
+	}
 	for key, value := range db.mem {
 		if value.delete {
 			if err := db.batch.Delete([]byte(key)); err != nil {
@@ -253,6 +310,7 @@ func (db *Database) Close() error {
 	db.batch = nil
 	db.mem = nil
 	db.db = nil
+	db.deletedRanges = nil
 	return nil
 }
 
@@ -288,6 +346,10 @@ func (b *batch) Write() error {
 	}
 
 	for _, op := range b.Ops {
+		if op.DeleteRange {
+			b.db.deleteRange(op.Key, op.Limit)
+			continue
+		}
 		b.db.mem[string(op.Key)] = valueDelete{
 			value:  op.Value,
 			delete: op.Delete,
@@ -312,6 +374,9 @@ type iterator struct {
 	keys   []string
 	values []valueDelete
 
+	// Keys of the underlying database in these ranges are skipped.
+	deletedRanges []keyRange
+
 	initialized, exhausted bool
 }
 
@@ -333,6 +398,11 @@ func (it *iterator) Next() bool {
 	}
 
 	for {
+		if !it.exhausted && isDeleted(it.deletedRanges, it.Iterator.Key()) {
+			it.exhausted = !it.Iterator.Next()
+			continue
+		}
+
 		switch {
 		case it.exhausted && len(it.keys) == 0:
 			it.key = nil
@@ -418,5 +488,6 @@ func (it *iterator) Release() {
 	it.value = nil
 	it.keys = nil
 	it.values = nil
+	it.deletedRanges = nil
 	it.Iterator.Release()
 }
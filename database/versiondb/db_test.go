@@ -231,6 +231,65 @@ func TestAbort(t *testing.T) {
 	require.False(has)
 }
 
+func TestDeleteRangeCommit(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	key1 := []byte("hello1")
+	key2 := []byte("hello2")
+	key3 := []byte("world")
+	value := []byte("value")
+
+	require.NoError(baseDB.Put(key1, value))
+	require.NoError(baseDB.Put(key2, value))
+	require.NoError(baseDB.Put(key3, value))
+
+	require.NoError(db.DeleteRange(key1, key3))
+	require.NoError(db.Put(key2, value))
+
+	has, err := db.Has(key1)
+	require.NoError(err)
+	require.False(has)
+	has, err = baseDB.Has(key1)
+	require.NoError(err)
+	require.True(has)
+
+	require.NoError(db.Commit())
+
+	has, err = baseDB.Has(key1)
+	require.NoError(err)
+	require.False(has)
+	has, err = baseDB.Has(key2)
+	require.NoError(err)
+	require.True(has)
+	has, err = baseDB.Has(key3)
+	require.NoError(err)
+	require.True(has)
+}
+
+func TestDeleteRangeAbort(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	key := []byte("hello")
+	require.NoError(baseDB.Put(key, []byte("world")))
+
+	require.NoError(db.DeleteRange(nil, nil))
+	has, err := db.Has(key)
+	require.NoError(err)
+	require.False(has)
+
+	db.Abort()
+
+	has, err = db.Has(key)
+	require.NoError(err)
+	require.True(has)
+}
+
 func TestCommitBatch(t *testing.T) {
 	require := require.New(t)
 
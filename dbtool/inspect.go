@@ -0,0 +1,450 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/chains"
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/factory"
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/indexer"
+	"github.com/skychains/chain/node"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/hashing"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/vms/avm/block"
+	"github.com/skychains/chain/vms/avm/fxs"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/nftfx"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/propertyfx"
+	"github.com/skychains/chain/vms/proposervm"
+	"github.com/skychains/chain/vms/secp256k1fx"
+
+	avmstate "github.com/skychains/chain/vms/avm/state"
+	platformstate "github.com/skychains/chain/vms/platformvm/state"
+)
+
+const platformChainName = "P-Chain"
+
+var (
+	// readOnlyConfig opens leveldb and pebbledb databases without allowing any
+	// writes.
+	readOnlyConfig = []byte(`{"readOnly":true,"metricUpdateFrequency":0}`)
+
+	errUnknownFx = errors.New("unknown fx")
+
+	// avmFxs are the fxs that an AVM chain can be created with.
+	avmFxs = map[ids.ID]func() fxs.Fx{
+		secp256k1fx.ID: func() fxs.Fx { return &secp256k1fx.Fx{} },
+		nftfx.ID:       func() fxs.Fx { return &nftfx.Fx{} },
+		propertyfx.ID:  func() fxs.Fx { return &propertyfx.Fx{} },
+	}
+)
+
+func newInspectCmd() *cobra.Command {
+	var (
+		dir    string
+		dbType string
+		deep   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "inspect",
+		Short: "Report the number of keys and bytes stored under each prefix of a database",
+		Long:  "Report the number of keys and bytes stored under each known prefix of a node's database, and of the databases of its isolated chains. The databases of isolated chains must be of the same type as the node's database. The databases are opened read-only, so the node must be stopped. With --deep, the state of the P-chain and of every X-chain is re-verified against the stored blocks, and the checksums of the stored txs and UTXOs are recomputed and compared to the checksums recorded by a node with checksums-enabled set. Exits with a non-zero status if any state is invalid or any checksum doesn't match.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if dir == "" {
+				return errDBDirRequired
+			}
+
+			log := newLogger()
+			i := &inspector{
+				log:    log,
+				out:    cmd.OutOrStdout(),
+				dir:    dir,
+				dbType: dbType,
+				dbs:    make(map[ids.ID]database.Database),
+			}
+			err := i.run(cmd.Context(), deep)
+			return errors.Join(err, i.close())
+		},
+	}
+	cmd.Flags().StringVar(&dir, "db-dir", "", "Path to the network's database directory, for example ~/.node/db/mainnet")
+	cmd.Flags().StringVar(&dbType, "db-type", leveldb.Name, "Database type of the database")
+	cmd.Flags().BoolVar(&deep, "deep", false, "Re-verify the state of the P-chain and of every X-chain")
+	return cmd
+}
+
+type chain struct {
+	id   ids.ID
+	name string
+	tx   *txs.CreateChainTx
+}
+
+type inspector struct {
+	log    logging.Logger
+	out    io.Writer
+	dir    string
+	dbType string
+
+	nodeDB database.Database
+	// dbs are the databases of isolated chains.
+	dbs    map[ids.ID]database.Database
+	chains []chain
+}
+
+func (i *inspector) run(ctx context.Context, deep bool) error {
+	var err error
+	i.nodeDB, err = factory.New(i.dbType, i.dir, readOnlyConfig, i.log, prometheus.NewRegistry())
+	if err != nil {
+		return err
+	}
+	if err := i.openIsolatedChainDBs(); err != nil {
+		return err
+	}
+	if err := i.loadChains(); err != nil {
+		return err
+	}
+
+	names := i.prefixNames()
+	if err := i.report(ctx, "node database", i.nodeDB, names); err != nil {
+		return err
+	}
+	for _, c := range i.chains {
+		if db, ok := i.dbs[c.id]; ok {
+			if err := i.report(ctx, c.name+" database", db, names); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !deep {
+		return nil
+	}
+	return i.verify(ctx)
+}
+
+// openIsolatedChainDBs opens the database of every chain whose state was moved
+// out of the node's database.
+func (i *inspector) openIsolatedChainDBs() error {
+	isolatedChainsDB := prefixdb.New(chains.IsolatedChainsDBPrefix, i.nodeDB)
+	it := isolatedChainsDB.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		chainID, err := ids.ToID(it.Key())
+		if err != nil {
+			return err
+		}
+
+		dbPath := filepath.Join(i.dir, node.ChainDBDirName, chainID.String())
+		db, err := factory.New(i.dbType, dbPath, readOnlyConfig, i.log, prometheus.NewRegistry())
+		if err != nil {
+			return fmt.Errorf("couldn't open database of chain %s: %w", chainID, err)
+		}
+		i.dbs[chainID] = db
+	}
+	return it.Error()
+}
+
+// loadChains loads the chains that were created on the P-chain and assigns
+// each of them a unique name.
+func (i *inspector) loadChains() error {
+	chainTxs, err := platformstate.GetChains(i.vmDB(constants.PlatformChainID))
+	if err != nil {
+		return fmt.Errorf("couldn't get chains: %w", err)
+	}
+
+	i.chains = []chain{{
+		id:   constants.PlatformChainID,
+		name: platformChainName,
+	}}
+	usedNames := map[string]int{
+		platformChainName: 1,
+	}
+	for _, tx := range chainTxs {
+		createChainTx, ok := tx.Unsigned.(*txs.CreateChainTx)
+		if !ok {
+			continue
+		}
+		usedNames[createChainTx.ChainName]++
+		i.chains = append(i.chains, chain{
+			id: tx.ID(),
+			tx: createChainTx,
+		})
+	}
+	for j := range i.chains[1:] {
+		c := &i.chains[j+1]
+		c.name = c.tx.ChainName
+		if usedNames[c.name] > 1 {
+			c.name = fmt.Sprintf("%s (%s)", c.name, c.id)
+		}
+	}
+	return nil
+}
+
+// chainDB returns the database that [chainID] stores its state in.
+func (i *inspector) chainDB(chainID ids.ID) database.Database {
+	if db, ok := i.dbs[chainID]; ok {
+		return db
+	}
+	return i.nodeDB
+}
+
+// vmDB returns the database that is provided to the VM of [chainID].
+func (i *inspector) vmDB(chainID ids.ID) database.Database {
+	chainDB := prefixdb.New(chainID[:], i.chainDB(chainID))
+	return prefixdb.New(chains.VMDBPrefix, chainDB)
+}
+
+// prefixNames maps the prefixes of the node's database, and the prefixes of the
+// P-chain's state, to human-readable names.
+type prefixNames map[string]string
+
+func (i *inspector) prefixNames() prefixNames {
+	names := prefixNames{}
+	indexerPrefix := prefixdb.MakePrefix(node.IndexerDBPrefix)
+	names.add(indexerPrefix, "indexer")
+	names.add(prefixdb.MakePrefix(node.KeystoreDBPrefix), "keystore")
+	names.add(prefixdb.MakePrefix(node.SharedMemoryDBPrefix), "shared memory")
+	names.add(prefixdb.MakePrefix(chains.IsolatedChainsDBPrefix), "isolated chains")
+
+	for _, c := range i.chains {
+		chainPrefix := prefixdb.MakePrefix(c.id[:])
+		vmPrefix := prefixdb.JoinPrefixes(chainPrefix, chains.VMDBPrefix)
+		names.add(chainPrefix, c.name)
+		names.add(vmPrefix, c.name+"/vm")
+		names.add(prefixdb.JoinPrefixes(vmPrefix, proposervm.DBPrefix), c.name+"/proposervm")
+		for _, prefix := range [][]byte{
+			chains.VertexDBPrefix,
+			chains.VertexBootstrappingDBPrefix,
+			chains.TxBootstrappingDBPrefix,
+			chains.BlockBootstrappingDBPrefix,
+			chains.ChainBootstrappingDBPrefix,
+		} {
+			names.add(prefixdb.JoinPrefixes(chainPrefix, prefix), c.name+"/"+string(prefix))
+		}
+		for index, prefix := range indexer.IndexPrefixes(c.id) {
+			names.add(prefixdb.JoinPrefixes(indexerPrefix, prefix), "indexer/"+c.name+"/"+index)
+		}
+	}
+
+	// The P-chain's state is stored under prefixes of its VM's database.
+	var (
+		pChainPrefix  = prefixdb.MakePrefix(constants.PlatformChainID[:])
+		pVMPrefix     = prefixdb.JoinPrefixes(pChainPrefix, chains.VMDBPrefix)
+		validators    = prefixdb.MakePrefix(platformstate.ValidatorsPrefix)
+		current       = prefixdb.JoinPrefixes(validators, platformstate.CurrentPrefix)
+		pending       = prefixdb.JoinPrefixes(validators, platformstate.PendingPrefix)
+		utxos         = prefixdb.MakePrefix(platformstate.UTXOPrefix)
+		statePrefixes = map[string][]byte{
+			"validators/weightDiffs":    prefixdb.JoinPrefixes(validators, platformstate.ValidatorWeightDiffsPrefix),
+			"validators/publicKeyDiffs": prefixdb.JoinPrefixes(validators, platformstate.ValidatorPublicKeyDiffsPrefix),
+			"utxos":                     prefixdb.JoinPrefixes(utxos, lux.UTXOPrefix),
+			"utxos/index":               prefixdb.JoinPrefixes(utxos, lux.IndexPrefix),
+		}
+	)
+	for name, validatorsPrefix := range map[string][]byte{
+		"current": current,
+		"pending": pending,
+	} {
+		for _, prefix := range [][]byte{
+			platformstate.ValidatorPrefix,
+			platformstate.DelegatorPrefix,
+			platformstate.SubnetValidatorPrefix,
+			platformstate.SubnetDelegatorPrefix,
+		} {
+			statePrefixes["validators/"+name+"/"+string(prefix)] = prefixdb.JoinPrefixes(validatorsPrefix, prefix)
+		}
+	}
+	for _, prefix := range [][]byte{
+		platformstate.BlockIDPrefix,
+		platformstate.BlockPrefix,
+		platformstate.TxPrefix,
+		platformstate.RewardUTXOsPrefix,
+		platformstate.SubnetPrefix,
+		platformstate.SubnetOwnerPrefix,
+		platformstate.TransformedSubnetPrefix,
+		platformstate.SupplyPrefix,
+		platformstate.ChainPrefix,
+		platformstate.SingletonPrefix,
+	} {
+		statePrefixes[string(prefix)] = prefixdb.MakePrefix(prefix)
+	}
+	for name, prefix := range statePrefixes {
+		names.add(prefixdb.PrefixKey(pVMPrefix, prefix), platformChainName+"/vm/"+name)
+	}
+	return names
+}
+
+func (n prefixNames) add(prefix []byte, name string) {
+	n[string(prefix)] = name
+}
+
+// name returns the name of the longest known prefix of [key].
+func (n prefixNames) name(key []byte) string {
+	for _, prefixLen := range []int{2 * hashing.HashLen, hashing.HashLen} {
+		if len(key) < prefixLen {
+			continue
+		}
+		if name, ok := n[string(key[:prefixLen])]; ok {
+			return name
+		}
+	}
+	if len(key) < hashing.HashLen {
+		// Keys that aren't prefixed are written directly by the node.
+		return fmt.Sprintf("key %q", key)
+	}
+	return fmt.Sprintf("unknown %x", key[:hashing.HashLen])
+}
+
+type prefixStats struct {
+	name  string
+	keys  uint64
+	bytes uint64
+}
+
+// report writes the number of keys, and the number of bytes, stored under each
+// prefix of [db].
+func (i *inspector) report(ctx context.Context, title string, db database.Iteratee, names prefixNames) error {
+	var (
+		stats = make(map[string]*prefixStats)
+		total = prefixStats{name: "total"}
+	)
+	it := db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key := it.Key()
+		name := names.name(key)
+		s, ok := stats[name]
+		if !ok {
+			s = &prefixStats{name: name}
+			stats[name] = s
+		}
+		size := uint64(len(key) + len(it.Value()))
+		s.keys++
+		s.bytes += size
+		total.keys++
+		total.bytes += size
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	sorted := make([]*prefixStats, 0, len(stats))
+	for _, s := range stats {
+		sorted = append(sorted, s)
+	}
+	slices.SortFunc(sorted, func(a, b *prefixStats) int {
+		switch {
+		case a.bytes > b.bytes:
+			return -1
+		case a.bytes < b.bytes:
+			return 1
+		default:
+			return 0
+		}
+	})
+	sorted = append(sorted, &total)
+
+	w := tabwriter.NewWriter(i.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\t\t\t\t\n", title)
+	fmt.Fprintf(w, "prefix\tkeys\tbytes\t%%\t\n")
+	for _, s := range sorted {
+		percent := 0.0
+		if total.bytes > 0 {
+			percent = 100 * float64(s.bytes) / float64(total.bytes)
+		}
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.2f\t\n", s.name, s.keys, s.bytes, percent)
+	}
+	fmt.Fprintln(w)
+	return w.Flush()
+}
+
+// verify re-verifies the state of the P-chain and of every X-chain.
+func (i *inspector) verify(ctx context.Context) error {
+	pSummary, err := platformstate.Verify(ctx, i.vmDB(constants.PlatformChainID))
+	if err != nil {
+		return fmt.Errorf("%s state is invalid: %w", platformChainName, err)
+	}
+	i.log.Info("verified state",
+		zap.String("chain", platformChainName),
+		zap.Stringer("lastAccepted", pSummary.LastAccepted),
+		zap.Uint64("height", pSummary.Height),
+		zap.Stringer("utxoChecksum", pSummary.UTXOChecksum),
+		zap.Bool("checksumRecorded", pSummary.ChecksumRecorded),
+	)
+
+	var errs []error
+	for _, c := range i.chains {
+		if c.tx == nil || c.tx.VMID != constants.AVMID {
+			continue
+		}
+
+		parser, err := newAVMParser(c.tx.FxIDs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("couldn't create parser for %s: %w", c.name, err))
+			continue
+		}
+		summary, err := avmstate.Verify(ctx, i.vmDB(c.id), parser)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s state is invalid: %w", c.name, err))
+			continue
+		}
+		i.log.Info("verified state",
+			zap.String("chain", c.name),
+			zap.Bool("linearized", summary.Linearized),
+			zap.Stringer("lastAccepted", summary.LastAccepted),
+			zap.Uint64("height", summary.Height),
+			zap.Stringer("txChecksum", summary.TxChecksum),
+			zap.Stringer("utxoChecksum", summary.UTXOChecksum),
+			zap.Bool("checksumsRecorded", summary.ChecksumsRecorded),
+		)
+	}
+	return errors.Join(errs...)
+}
+
+// newAVMParser returns a parser for an AVM chain that was created with
+// [fxIDs].
+func newAVMParser(fxIDs []ids.ID) (block.Parser, error) {
+	chainFxs := make([]fxs.Fx, len(fxIDs))
+	for j, fxID := range fxIDs {
+		newFx, ok := avmFxs[fxID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", errUnknownFx, fxID)
+		}
+		chainFxs[j] = newFx()
+	}
+	return block.NewParser(chainFxs)
+}
+
+func (i *inspector) close() error {
+	errs := make([]error, 0, len(i.dbs)+1)
+	for _, db := range i.dbs {
+		errs = append(errs, db.Close())
+	}
+	if i.nodeDB != nil {
+		errs = append(errs, i.nodeDB.Close())
+	}
+	return errors.Join(errs...)
+}
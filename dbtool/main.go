@@ -0,0 +1,49 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skychains/chain/utils/logging"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "dbtool",
+		Short: "Offline tools for managing node databases",
+	}
+	rootCmd.AddCommand(
+		newMigrateCmd(),
+		newVerifyCmd(),
+		newInspectCmd(),
+	)
+
+	// Interrupting a command cancels its context so that partially completed
+	// work can be persisted and later resumed.
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "dbtool failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newLogger() logging.Logger {
+	return logging.NewLogger(
+		"dbtool",
+		logging.NewWrappedCore(
+			logging.Info,
+			os.Stdout,
+			logging.Colors.ConsoleEncoder(),
+		),
+	)
+}
@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/factory"
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/migrate"
+	"github.com/skychains/chain/database/pebbledb"
+	"github.com/skychains/chain/node"
+	"github.com/skychains/chain/utils/logging"
+)
+
+var (
+	errDBDirRequired = errors.New("--db-dir is required")
+	errSameDBType    = errors.New("--from and --to must differ")
+)
+
+type dbFlags struct {
+	dir          string
+	from         string
+	to           string
+	toConfigFile string
+}
+
+func (f *dbFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&f.dir, "db-dir", "", "Path to the network's database directory, for example ~/.node/db/mainnet")
+	cmd.Flags().StringVar(&f.from, "from", leveldb.Name, "Database type to read from")
+	cmd.Flags().StringVar(&f.to, "to", pebbledb.Name, "Database type to write to")
+	cmd.Flags().StringVar(&f.toConfigFile, "to-config-file", "", "[optional] Path to the config file of the database being written to")
+}
+
+// toConfig returns the config of the database being written to.
+func (f *dbFlags) toConfig() ([]byte, error) {
+	if f.toConfigFile == "" {
+		return nil, nil
+	}
+	return os.ReadFile(f.toConfigFile)
+}
+
+// open opens the source and destination databases. The source database must
+// already exist and is opened read-only. The caller is responsible for closing
+// both databases.
+func (f *dbFlags) open(log logging.Logger) (database.Database, database.Database, error) {
+	if f.dir == "" {
+		return nil, nil, errDBDirRequired
+	}
+	if f.from == f.to {
+		return nil, nil, errSameDBType
+	}
+
+	toConfig, err := f.toConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src, err := factory.NewReadOnly(f.from, f.dir, log, prometheus.NewRegistry())
+	if err != nil {
+		return nil, nil, err
+	}
+	dst, err := factory.New(f.to, f.dir, toConfig, log, prometheus.NewRegistry())
+	if err != nil {
+		return nil, nil, errors.Join(err, src.Close())
+	}
+	return src, dst, nil
+}
+
+func newMigrateCmd() *cobra.Command {
+	var (
+		flags     dbFlags
+		batchSize int
+	)
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Copy every key in a database into a database of another type",
+		Long: fmt.Sprintf(
+			"Copy every key in a database into a database of another type and verify the copy with per-prefix checksums. The databases of isolated chains, in the %s directory, are migrated as well. An interrupted migration is resumed when the command is re-run. The node must be stopped, and must be restarted with --db-type=%s once the migration completes.",
+			node.ChainDBDirName,
+			pebbledb.Name,
+		),
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			log := newLogger()
+			src, dst, err := flags.open(log)
+			if err != nil {
+				return err
+			}
+
+			config := migrate.Config{
+				BatchSize: batchSize,
+				PrefixLen: migrate.DefaultPrefixLen,
+			}
+			err = migrate.Migrate(cmd.Context(), log, config, src, dst)
+			if err := errors.Join(err, src.Close(), dst.Close()); err != nil {
+				return err
+			}
+
+			toConfig, err := flags.toConfig()
+			if err != nil {
+				return err
+			}
+			return node.MigrateChainDatabases(
+				cmd.Context(),
+				log,
+				config,
+				flags.dir,
+				flags.from,
+				flags.to,
+				toConfig,
+			)
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&batchSize, "batch-size", migrate.DefaultBatchSize, "Number of bytes to buffer before each write")
+	return cmd
+}
+
+func newVerifyCmd() *cobra.Command {
+	var (
+		flags     dbFlags
+		prefixLen int
+	)
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Compare the contents of two databases with per-prefix checksums",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			log := newLogger()
+			src, dst, err := flags.open(log)
+			if err != nil {
+				return err
+			}
+
+			err = migrate.Verify(cmd.Context(), prefixLen, src, dst)
+			if err == nil {
+				log.Info("databases match")
+			}
+			return errors.Join(err, src.Close(), dst.Close())
+		},
+	}
+	flags.register(cmd)
+	cmd.Flags().IntVar(&prefixLen, "prefix-len", migrate.DefaultPrefixLen, "Number of leading key bytes used to group keys")
+	return cmd
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/skychains/chain/utils/crypto/secp256k1"
 	"github.com/skychains/chain/utils/units"
 	"github.com/skychains/chain/utils/wrappers"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/txs/fee"
 )
@@ -48,6 +49,24 @@ var (
 			AddSubnetValidatorFee:         units.MilliLux,
 			AddSubnetDelegatorFee:         units.MilliLux,
 		},
+		DynamicFeeConfig: gas.Config{
+			Weights: gas.Dimensions{
+				gas.Bandwidth: 1,     // Max block size ~1MB
+				gas.DBRead:    1_000, // Max reads per block 1,000
+				gas.DBWrite:   1_000, // Max writes per block 1,000
+				gas.Compute:   4,     // Max compute time per block ~250ms
+			},
+			MaxCapacity:     1_000_000,
+			MaxPerSecond:    100_000, // Refill time 10s
+			TargetPerSecond: 50_000,  // Target is half of max
+			MinPrice:        1,
+			// ExcessConversionConstant = (MaxPerSecond - TargetPerSecond) * NumberOfSecondsPerDoubling / ln(2)
+			//
+			// ln(2) is a float and the result is consensus critical, so we
+			// hardcode the result.
+			ExcessConversionConstant: 2_164_043, // Double every 30s
+		},
+		SubnetOnlyValidatorFee: 512, // ~0.044 LUX per day
 		StakingConfig: StakingConfig{
 			UptimeRequirement: .8, // 80%
 			MinValidatorStake: 2 * units.KiloLux,
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/txs/fee"
 )
@@ -37,6 +38,11 @@ type StakingConfig struct {
 type Params struct {
 	StakingConfig
 	fee.StaticConfig
+	// DynamicFeeConfig is the config for the P-chain fees after the F upgrade.
+	DynamicFeeConfig gas.Config
+	// SubnetOnlyValidatorFee is the fee, in nLUX, charged per second to each
+	// active subnet only validator after the F upgrade.
+	SubnetOnlyValidatorFee uint64
 }
 
 func GetTxFeeConfig(networkID uint32) fee.StaticConfig {
@@ -52,6 +58,32 @@ func GetTxFeeConfig(networkID uint32) fee.StaticConfig {
 	}
 }
 
+func GetDynamicFeeConfig(networkID uint32) gas.Config {
+	switch networkID {
+	case constants.MainnetID:
+		return MainnetParams.DynamicFeeConfig
+	case constants.FujiID:
+		return FujiParams.DynamicFeeConfig
+	case constants.LocalID:
+		return LocalParams.DynamicFeeConfig
+	default:
+		return LocalParams.DynamicFeeConfig
+	}
+}
+
+func GetSubnetOnlyValidatorFee(networkID uint32) uint64 {
+	switch networkID {
+	case constants.MainnetID:
+		return MainnetParams.SubnetOnlyValidatorFee
+	case constants.FujiID:
+		return FujiParams.SubnetOnlyValidatorFee
+	case constants.LocalID:
+		return LocalParams.SubnetOnlyValidatorFee
+	default:
+		return LocalParams.SubnetOnlyValidatorFee
+	}
+}
+
 func GetStakingConfig(networkID uint32) StakingConfig {
 	switch networkID {
 	case constants.MainnetID:
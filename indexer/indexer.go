@@ -42,6 +42,23 @@ var (
 	hasRunKey = []byte{0x07}
 )
 
+// IndexPrefixes returns the prefixes, of the indexer's database, that the
+// indices of [chainID] are stored under, keyed by the name of the index.
+func IndexPrefixes(chainID ids.ID) map[string][]byte {
+	return map[string][]byte{
+		"tx":    indexPrefix(chainID, txPrefix),
+		"vtx":   indexPrefix(chainID, vtxPrefix),
+		"block": indexPrefix(chainID, blockPrefix),
+	}
+}
+
+func indexPrefix(chainID ids.ID, prefixEnd byte) []byte {
+	prefix := make([]byte, ids.IDLen+wrappers.ByteLen)
+	copy(prefix, chainID[:])
+	prefix[ids.IDLen] = prefixEnd
+	return prefix
+}
+
 // Config for an indexer
 type Config struct {
 	DB                   database.Database
@@ -312,10 +329,7 @@ func (i *indexer) registerChainHelper(
 	name, endpoint string,
 	acceptorGroup snow.AcceptorGroup,
 ) (*index, error) {
-	prefix := make([]byte, ids.IDLen+wrappers.ByteLen)
-	copy(prefix, chainID[:])
-	prefix[ids.IDLen] = prefixEnd
-	indexDB := prefixdb.New(prefix, i.db)
+	indexDB := prefixdb.New(indexPrefix(chainID, prefixEnd), i.db)
 	index, err := newIndex(indexDB, i.log, i.clock)
 	if err != nil {
 		_ = indexDB.Close()
@@ -23,6 +23,7 @@ import (
 	"github.com/skychains/chain/utils/profiler"
 	"github.com/skychains/chain/utils/set"
 	"github.com/skychains/chain/utils/timer"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/txs/fee"
 )
 
@@ -119,6 +120,14 @@ type DatabaseConfig struct {
 
 	// Path to config file
 	Config []byte `json:"-"`
+
+	// Name of the database type to migrate into this database during startup.
+	// If empty, or equal to Name, no migration is performed.
+	MigrateFrom string `json:"migrateFrom"`
+
+	// IDs or aliases of chains that store their state in their own database,
+	// under [Path], rather than in this database.
+	IsolatedChains []string `json:"isolatedChains"`
 }
 
 // Config contains all of the configurations of an Lux node.
@@ -131,9 +140,16 @@ type Config struct {
 	BootstrapConfig  `json:"bootstrapConfig"`
 	DatabaseConfig   `json:"databaseConfig"`
 
+	// Dynamic fees config of the P-chain, active after the F upgrade
+	DynamicFeeConfig gas.Config `json:"dynamicFeeConfig"`
+
+	// Fee charged per second to each active subnet only validator of the
+	// P-chain, active after the F upgrade
+	SubnetOnlyValidatorFee uint64 `json:"subnetOnlyValidatorFee"`
+
 	// Genesis information
 	GenesisBytes []byte `json:"-"`
-	LuxAssetID  ids.ID `json:"luxAssetID"`
+	LuxAssetID   ids.ID `json:"luxAssetID"`
 
 	// ID of the network this node should connect to
 	NetworkID uint32 `json:"networkID"`
@@ -15,9 +15,11 @@ import (
 	"net"
 	"net/netip"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strconv"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -34,10 +36,10 @@ import (
 	"github.com/skychains/chain/chains"
 	"github.com/skychains/chain/chains/atomic"
 	"github.com/skychains/chain/database"
-	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/factory"
 	"github.com/skychains/chain/database/memdb"
 	"github.com/skychains/chain/database/meterdb"
-	"github.com/skychains/chain/database/pebbledb"
+	"github.com/skychains/chain/database/migrate"
 	"github.com/skychains/chain/database/prefixdb"
 	"github.com/skychains/chain/database/versiondb"
 	"github.com/skychains/chain/genesis"
@@ -92,6 +94,10 @@ const (
 
 	ipResolutionTimeout = 30 * time.Second
 
+	// ChainDBDirName is the directory, under the database directory, that
+	// isolated chains store their databases in.
+	ChainDBDirName = chains.DBDirName
+
 	apiNamespace             = constants.PlatformName + metric.NamespaceSeparator + "api"
 	benchlistNamespace       = constants.PlatformName + metric.NamespaceSeparator + "benchlist"
 	dbNamespace              = constants.PlatformName + metric.NamespaceSeparator + "db"
@@ -110,8 +116,11 @@ var (
 	genesisHashKey     = []byte("genesisID")
 	ungracefulShutdown = []byte("ungracefulShutdown")
 
-	indexerDBPrefix  = []byte{0x00}
-	keystoreDBPrefix = []byte("keystore")
+	// Prefixes, of the node's database, that node-level state is stored
+	// under.
+	IndexerDBPrefix      = []byte{0x00}
+	KeystoreDBPrefix     = []byte("keystore")
+	SharedMemoryDBPrefix = []byte("shared memory")
 
 	errInvalidTLSKey = errors.New("invalid TLS key")
 	errShuttingDown  = errors.New("server shutting down")
@@ -779,31 +788,19 @@ func (n *Node) initDatabase() error {
 	}
 
 	// start the db
-	switch n.Config.DatabaseConfig.Name {
-	case leveldb.Name:
-		// Prior to v1.10.15, the only on-disk database was leveldb, and its
-		// files went to [dbPath]/[networkID]/v1.4.5.
-		dbPath := filepath.Join(n.Config.DatabaseConfig.Path, version.CurrentDatabase.String())
-		n.DB, err = leveldb.New(dbPath, n.Config.DatabaseConfig.Config, n.Log, dbRegisterer)
-		if err != nil {
-			return fmt.Errorf("couldn't create %s at %s: %w", leveldb.Name, dbPath, err)
-		}
-	case memdb.Name:
-		n.DB = memdb.New()
-	case pebbledb.Name:
-		dbPath := filepath.Join(n.Config.DatabaseConfig.Path, "pebble")
-		n.DB, err = pebbledb.New(dbPath, n.Config.DatabaseConfig.Config, n.Log, dbRegisterer)
-		if err != nil {
-			return fmt.Errorf("couldn't create %s at %s: %w", pebbledb.Name, dbPath, err)
-		}
-	default:
-		return fmt.Errorf(
-			"db-type was %q but should have been one of {%s, %s, %s}",
-			n.Config.DatabaseConfig.Name,
-			leveldb.Name,
-			memdb.Name,
-			pebbledb.Name,
-		)
+	n.DB, err = factory.New(
+		n.Config.DatabaseConfig.Name,
+		n.Config.DatabaseConfig.Path,
+		n.Config.DatabaseConfig.Config,
+		n.Log,
+		dbRegisterer,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := n.migrateDatabase(); err != nil {
+		return fmt.Errorf("couldn't migrate database: %w", err)
 	}
 
 	if n.Config.ReadOnly && n.Config.DatabaseConfig.Name != memdb.Name {
@@ -870,6 +867,162 @@ func (n *Node) initDatabase() error {
 	return nil
 }
 
+// migrateDatabase copies the database of type [MigrateFrom] into [n.DB]. If the
+// migration was previously interrupted, it is resumed. If the migration was
+// previously completed, this is a noop.
+//
+// Must be called before any writes are made to [n.DB].
+func (n *Node) migrateDatabase() error {
+	srcName := n.Config.DatabaseConfig.MigrateFrom
+	dstName := n.Config.DatabaseConfig.Name
+	if srcName == "" || srcName == dstName {
+		return nil
+	}
+	if srcName == memdb.Name || dstName == memdb.Name || n.Config.ReadOnly {
+		return fmt.Errorf("can't migrate from %s to %s with read-only=%t",
+			srcName,
+			dstName,
+			n.Config.ReadOnly,
+		)
+	}
+
+	n.Log.Info("migrating database",
+		zap.String("from", srcName),
+		zap.String("to", dstName),
+	)
+
+	// The source database's metrics are discarded to avoid colliding with the
+	// metrics of [n.DB].
+	src, err := factory.NewReadOnly(
+		srcName,
+		n.Config.DatabaseConfig.Path,
+		n.Log,
+		prometheus.NewRegistry(),
+	)
+	if errors.Is(err, factory.ErrNotFound) {
+		// The source database may have been removed after the migration
+		// completed.
+		complete, completeErr := migrate.IsComplete(n.DB)
+		if completeErr != nil {
+			return completeErr
+		}
+		if complete {
+			n.Log.Info("skipping database migration",
+				zap.String("reason", "source database doesn't exist and the migration is complete"),
+			)
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	// The node's signal handlers aren't registered until it has been created,
+	// so the migration is interrupted directly by the shutdown signals. An
+	// interrupted migration is resumed when the node is restarted.
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	err = migrate.Migrate(ctx, n.Log, migrate.DefaultConfig, src, n.DB)
+	if errors.Is(err, migrate.ErrDestinationNotEmpty) {
+		n.Log.Info("skipping database migration",
+			zap.String("reason", "destination database is already populated"),
+		)
+		err = nil
+	}
+	if err := errors.Join(err, src.Close()); err != nil {
+		return err
+	}
+
+	return MigrateChainDatabases(
+		ctx,
+		n.Log,
+		migrate.DefaultConfig,
+		n.Config.DatabaseConfig.Path,
+		srcName,
+		dstName,
+		n.Config.DatabaseConfig.Config,
+	)
+}
+
+// MigrateChainDatabases migrates the databases of type [from], that isolated
+// chains store under the database directory [dir], into databases of type
+// [to]. Like the node's database, an interrupted migration is resumed by the
+// next call.
+//
+// Chains without a database of type [from] are skipped. Chains that are
+// configured with their own database type keep using it, so migrating their
+// databases has no effect.
+func MigrateChainDatabases(
+	ctx context.Context,
+	log logging.Logger,
+	config migrate.Config,
+	dir string,
+	from string,
+	to string,
+	toConfig []byte,
+) error {
+	chainsDir := filepath.Join(dir, ChainDBDirName)
+	entries, err := os.ReadDir(chainsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		chainDir := filepath.Join(chainsDir, entry.Name())
+		if err := migrateChainDatabase(ctx, log, config, chainDir, from, to, toConfig); err != nil {
+			return fmt.Errorf("couldn't migrate the database of chain %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+func migrateChainDatabase(
+	ctx context.Context,
+	log logging.Logger,
+	config migrate.Config,
+	dir string,
+	from string,
+	to string,
+	toConfig []byte,
+) error {
+	src, err := factory.NewReadOnly(from, dir, log, prometheus.NewRegistry())
+	if errors.Is(err, factory.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	dst, err := factory.New(to, dir, toConfig, log, prometheus.NewRegistry())
+	if err != nil {
+		return errors.Join(err, src.Close())
+	}
+
+	log.Info("migrating chain database",
+		zap.String("path", dir),
+		zap.String("from", from),
+		zap.String("to", to),
+	)
+
+	err = migrate.Migrate(ctx, log, config, src, dst)
+	if errors.Is(err, migrate.ErrDestinationNotEmpty) {
+		log.Info("skipping chain database migration",
+			zap.String("path", dir),
+			zap.String("reason", "destination database is already populated"),
+		)
+		err = nil
+	}
+	return errors.Join(err, src.Close(), dst.Close())
+}
+
 // Set the node IDs of the peers this node should first connect to
 func (n *Node) initBootstrappers() error {
 	n.bootstrappers = validators.NewManager()
@@ -897,7 +1050,7 @@ func (n *Node) initEventDispatchers() {
 // [n.ConsensusAcceptorGroup], [n.Log], [n.APIServer], [n.chainManager] are
 // initialized
 func (n *Node) initIndexer() error {
-	txIndexerDB := prefixdb.New(indexerDBPrefix, n.DB)
+	txIndexerDB := prefixdb.New(IndexerDBPrefix, n.DB)
 	var err error
 	n.indexer, err = indexer.NewIndexer(indexer.Config{
 		IndexingEnabled:      n.Config.IndexAPIEnabled,
@@ -1157,6 +1310,11 @@ func (n *Node) initChainManager(luxAssetID ids.ID) error {
 			TxAcceptorGroup:                         n.TxAcceptorGroup,
 			VertexAcceptorGroup:                     n.VertexAcceptorGroup,
 			DB:                                      n.DB,
+			DBName:                                  n.Config.DatabaseConfig.Name,
+			DBConfig:                                n.Config.DatabaseConfig.Config,
+			DBReadOnly:                              n.Config.ReadOnly,
+			ChainDBDir:                              filepath.Join(n.Config.DatabaseConfig.Path, ChainDBDirName),
+			IsolatedChains:                          set.Of(n.Config.DatabaseConfig.IsolatedChains...),
 			MsgCreator:                              n.msgCreator,
 			Router:                                  n.chainRouter,
 			Net:                                     n.Net,
@@ -1228,6 +1386,8 @@ func (n *Node) initVMs() error {
 				PartialSyncPrimaryNetwork: n.Config.PartialSyncPrimaryNetwork,
 				TrackedSubnets:            n.Config.TrackedSubnets,
 				StaticFeeConfig:           n.Config.StaticConfig,
+				DynamicFeeConfig:          n.Config.DynamicFeeConfig,
+				SubnetOnlyValidatorFee:    n.Config.SubnetOnlyValidatorFee,
 				UptimePercentage:          n.Config.UptimeRequirement,
 				MinValidatorStake:         n.Config.MinValidatorStake,
 				MaxValidatorStake:         n.Config.MaxValidatorStake,
@@ -1243,6 +1403,7 @@ func (n *Node) initVMs() error {
 					CortinaTime:       version.GetCortinaTime(n.Config.NetworkID),
 					DurangoTime:       version.GetDurangoTime(n.Config.NetworkID),
 					EUpgradeTime:      eUpgradeTime,
+					FUpgradeTime:      version.GetFUpgradeTime(n.Config.NetworkID),
 				},
 				UseCurrentHeight: n.Config.UseCurrentHeight,
 			},
@@ -1295,7 +1456,7 @@ func (n *Node) initVMs() error {
 // initSharedMemory initializes the shared memory for cross chain interation
 func (n *Node) initSharedMemory() {
 	n.Log.Info("initializing SharedMemory")
-	sharedMemoryDB := prefixdb.New([]byte("shared memory"), n.DB)
+	sharedMemoryDB := prefixdb.New(SharedMemoryDBPrefix, n.DB)
 	n.sharedMemory = atomic.NewMemory(sharedMemoryDB)
 }
 
@@ -1303,7 +1464,7 @@ func (n *Node) initSharedMemory() {
 // Assumes n.APIServer is already set
 func (n *Node) initKeystoreAPI() error {
 	n.Log.Info("initializing keystore")
-	n.keystore = keystore.New(n.Log, prefixdb.New(keystoreDBPrefix, n.DB))
+	n.keystore = keystore.New(n.Log, prefixdb.New(KeystoreDBPrefix, n.DB))
 	handler, err := n.keystore.CreateHandler()
 	if err != nil {
 		return err
@@ -1368,6 +1529,7 @@ func (n *Node) initAdminAPI() error {
 		admin.Config{
 			Log:          n.Log,
 			DB:           n.DB,
+			DBName:       n.Config.DatabaseConfig.Name,
 			ChainManager: n.chainManager,
 			HTTPServer:   n.APIServer,
 			ProfileDir:   n.Config.ProfilerConfig.Dir,
@@ -18,7 +18,7 @@ const (
 	// RPCChainVMProtocol should be bumped anytime changes are made which
 	// require the plugin vm to upgrade to latest node release to be
 	// compatible.
-	RPCChainVMProtocol uint = 35
+	RPCChainVMProtocol uint = 36
 )
 
 // These are globals that describe network upgrades and node versions
@@ -26,7 +26,7 @@ var (
 	Current = &Semantic{
 		Major: 1,
 		Minor: 11,
-		Patch: 9,
+		Patch: 10,
 	}
 	CurrentApp = &Application{
 		Name:  Client,
@@ -145,6 +145,11 @@ var (
 		constants.MainnetID: time.Date(10000, time.December, 1, 0, 0, 0, 0, time.UTC),
 		constants.FujiID:    time.Date(10000, time.December, 1, 0, 0, 0, 0, time.UTC),
 	}
+
+	FUpgradeTimes = map[uint32]time.Time{
+		constants.MainnetID: time.Date(10000, time.December, 1, 0, 0, 0, 0, time.UTC),
+		constants.FujiID:    time.Date(10000, time.December, 1, 0, 0, 0, 0, time.UTC),
+	}
 )
 
 func init() {
@@ -252,6 +257,13 @@ func GetEUpgradeTime(networkID uint32) time.Time {
 	return DefaultUpgradeTime
 }
 
+func GetFUpgradeTime(networkID uint32) time.Time {
+	if upgradeTime, exists := FUpgradeTimes[networkID]; exists {
+		return upgradeTime
+	}
+	return DefaultUpgradeTime
+}
+
 func GetCompatibility(networkID uint32) Compatibility {
 	return NewCompatibility(
 		CurrentApp,
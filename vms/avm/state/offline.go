@@ -0,0 +1,214 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/database/versiondb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/vms/avm/block"
+	"github.com/skychains/chain/vms/components/lux"
+)
+
+var (
+	errUnexpectedBlockID     = errors.New("unexpected block ID")
+	errUnexpectedBlockHeight = errors.New("unexpected block height")
+	errUnexpectedTxID        = errors.New("unexpected tx ID")
+)
+
+// Summary describes the state persisted by the X-chain.
+type Summary struct {
+	// Linearized is false if the chain hasn't accepted any blocks.
+	Linearized   bool
+	LastAccepted ids.ID
+	Height       uint64
+	// TxChecksum and UTXOChecksum are the checksums of the stored txs and
+	// UTXOs.
+	TxChecksum   ids.ID
+	UTXOChecksum ids.ID
+	// ChecksumsRecorded is true if [TxChecksum] and [UTXOChecksum] were
+	// compared to the checksums recorded by a node, that has checksums-enabled
+	// set, when it accepted the last accepted block.
+	ChecksumsRecorded bool
+}
+
+// Verify re-verifies the state persisted in [db], the database provided to the
+// X-chain VM, without modifying it.
+//
+// Every block, from the last accepted block back to the linearization, must be
+// stored, hash to the ID it is stored under and follow its parent. Every tx
+// must be stored under its ID, including the txs in those blocks. Every UTXO
+// must be stored under its ID. If checksums were recorded for the last accepted
+// block, the stored txs and UTXOs must match them.
+func Verify(ctx context.Context, db database.Database, parser block.Parser) (*Summary, error) {
+	var (
+		baseDB      = versiondb.New(db)
+		txDB        = prefixdb.New(txPrefix, baseDB)
+		blockIDDB   = prefixdb.New(blockIDPrefix, baseDB)
+		blockDB     = prefixdb.New(blockPrefix, baseDB)
+		singletonDB = prefixdb.New(singletonPrefix, baseDB)
+		summary     = &Summary{}
+		err         error
+	)
+	summary.LastAccepted, err = database.GetID(singletonDB, lastAcceptedKey)
+	switch {
+	case err == database.ErrNotFound:
+	case err != nil:
+		return nil, fmt.Errorf("couldn't get last accepted block: %w", err)
+	default:
+		summary.Linearized = true
+		summary.Height, err = verifyBlocks(ctx, blockIDDB, blockDB, txDB, parser, summary.LastAccepted)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	summary.TxChecksum, err = verifyTxChecksum(ctx, txDB, parser)
+	if err != nil {
+		return nil, err
+	}
+	summary.UTXOChecksum, err = lux.VerifyChecksum(ctx, prefixdb.New(utxoPrefix, baseDB), parser.Codec())
+	if err != nil {
+		return nil, err
+	}
+
+	txChecksumRecorded, err := lux.VerifyRecordedChecksum(
+		singletonDB,
+		txChecksumKey,
+		summary.LastAccepted,
+		summary.TxChecksum,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify tx checksum: %w", err)
+	}
+	utxoChecksumRecorded, err := lux.VerifyRecordedChecksum(
+		singletonDB,
+		utxoChecksumKey,
+		summary.LastAccepted,
+		summary.UTXOChecksum,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify UTXO checksum: %w", err)
+	}
+	summary.ChecksumsRecorded = txChecksumRecorded && utxoChecksumRecorded
+	return summary, nil
+}
+
+// verifyBlocks verifies every block from [lastAccepted] back to the
+// linearization and returns the height of [lastAccepted].
+func verifyBlocks(
+	ctx context.Context,
+	blockIDDB database.KeyValueReader,
+	blockDB database.KeyValueReader,
+	txDB database.KeyValueReader,
+	parser block.Parser,
+	lastAccepted ids.ID,
+) (uint64, error) {
+	var (
+		blkID              = lastAccepted
+		lastAcceptedHeight uint64
+		childHeight        uint64
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		blkBytes, err := blockDB.Get(blkID[:])
+		if err != nil {
+			return 0, fmt.Errorf("couldn't get block %s: %w", blkID, err)
+		}
+		blk, err := parser.ParseBlock(blkBytes)
+		if err != nil {
+			return 0, fmt.Errorf("couldn't parse block %s: %w", blkID, err)
+		}
+		if blk.ID() != blkID {
+			return 0, fmt.Errorf("%w: block %s is stored under %s",
+				errUnexpectedBlockID,
+				blk.ID(),
+				blkID,
+			)
+		}
+
+		height := blk.Height()
+		if blkID == lastAccepted {
+			lastAcceptedHeight = height
+		} else if height+1 != childHeight {
+			return 0, fmt.Errorf("%w: block %s has height %d but its child has height %d",
+				errUnexpectedBlockHeight,
+				blkID,
+				height,
+				childHeight,
+			)
+		}
+
+		indexedID, err := database.GetID(blockIDDB, database.PackUInt64(height))
+		if err != nil {
+			return 0, fmt.Errorf("couldn't get block ID at height %d: %w", height, err)
+		}
+		if indexedID != blkID {
+			return 0, fmt.Errorf("%w: height %d is indexed as %s rather than %s",
+				errUnexpectedBlockID,
+				height,
+				indexedID,
+				blkID,
+			)
+		}
+
+		for _, tx := range blk.Txs() {
+			txID := tx.ID()
+			has, err := txDB.Has(txID[:])
+			if err != nil {
+				return 0, err
+			}
+			if !has {
+				return 0, fmt.Errorf("%w: tx %s of block %s", database.ErrNotFound, txID, blkID)
+			}
+		}
+
+		if height == 0 {
+			return lastAcceptedHeight, nil
+		}
+		childHeight = height
+		blkID = blk.Parent()
+	}
+}
+
+// verifyTxChecksum verifies that every tx in [txDB] can be parsed and is
+// stored under its ID. Returns the checksum of the stored txs.
+func verifyTxChecksum(ctx context.Context, txDB database.Iteratee, parser block.Parser) (ids.ID, error) {
+	it := txDB.NewIterator()
+	defer it.Release()
+
+	var checksum ids.ID
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return ids.Empty, err
+		}
+
+		txID, err := ids.ToID(it.Key())
+		if err != nil {
+			return ids.Empty, err
+		}
+
+		tx, err := parser.ParseGenesisTx(it.Value())
+		if err != nil {
+			return ids.Empty, fmt.Errorf("couldn't parse tx %s: %w", txID, err)
+		}
+		if tx.ID() != txID {
+			return ids.Empty, fmt.Errorf("%w: tx %s is stored under %s",
+				errUnexpectedTxID,
+				tx.ID(),
+				txID,
+			)
+		}
+		checksum = checksum.XOR(txID)
+	}
+	return checksum, it.Error()
+}
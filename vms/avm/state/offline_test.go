@@ -0,0 +1,96 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/database/versiondb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/version"
+	"github.com/skychains/chain/vms/avm/block"
+	"github.com/skychains/chain/vms/avm/txs"
+	"github.com/skychains/chain/vms/components/lux"
+)
+
+func TestVerify(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	vdb := versiondb.New(db)
+	s, err := New(vdb, parser, prometheus.NewRegistry(), true)
+	require.NoError(err)
+
+	summary, err := Verify(context.Background(), db, parser)
+	require.NoError(err)
+	require.False(summary.Linearized)
+
+	genesisTimestamp := version.DefaultUpgradeTime
+	require.NoError(s.InitializeChainState(ids.GenerateTestID(), genesisTimestamp))
+
+	childBlock, err := block.NewStandardBlock(
+		s.GetLastAccepted(),
+		1,
+		genesisTimestamp,
+		[]*txs.Tx{
+			{
+				Unsigned: &txs.BaseTx{BaseTx: lux.BaseTx{
+					BlockchainID: ids.GenerateTestID(),
+				}},
+			},
+		},
+		parser.Codec(),
+	)
+	require.NoError(err)
+
+	s.AddUTXO(populatedUTXO)
+	s.AddTx(childBlock.Txs()[0])
+	s.AddBlock(childBlock)
+	s.SetLastAccepted(childBlock.ID())
+	require.NoError(s.Commit())
+
+	summary, err = Verify(context.Background(), db, parser)
+	require.NoError(err)
+
+	txChecksum, utxoChecksum := s.Checksums()
+	require.Equal(&Summary{
+		Linearized:   true,
+		LastAccepted: childBlock.ID(),
+		Height:       1,
+		TxChecksum:   txChecksum,
+		UTXOChecksum: utxoChecksum,
+
+		ChecksumsRecorded: true,
+	}, summary)
+
+	// Store a UTXO without updating the recorded checksum.
+	utxos, err := lux.NewUTXOState(prefixdb.New(utxoPrefix, vdb), parser.Codec(), false)
+	require.NoError(err)
+	require.NoError(utxos.PutUTXO(&lux.UTXO{
+		UTXOID: lux.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: populatedUTXO.Asset,
+		Out:   populatedUTXO.Out,
+	}))
+	require.NoError(vdb.Commit())
+
+	_, err = Verify(context.Background(), db, parser)
+	require.ErrorIs(err, lux.ErrChecksumMismatch)
+
+	// Remove the tx that was accepted in the last accepted block.
+	txID := childBlock.Txs()[0].ID()
+	require.NoError(prefixdb.New(txPrefix, vdb).Delete(txID[:]))
+	require.NoError(vdb.Commit())
+
+	_, err = Verify(context.Background(), db, parser)
+	require.ErrorIs(err, database.ErrNotFound)
+}
@@ -37,6 +37,8 @@ var (
 	isInitializedKey = []byte{0x00}
 	timestampKey     = []byte{0x01}
 	lastAcceptedKey  = []byte{0x02}
+	txChecksumKey    = []byte{0x03}
+	utxoChecksumKey  = []byte{0x04}
 
 	_ State = (*state)(nil)
 )
@@ -108,7 +110,9 @@ type State interface {
  * '-. singletons
  *   |-- initializedKey -> nil
  *   |-- timestampKey -> timestamp
- *   '-- lastAcceptedKey -> lastAccepted
+ *   |-- lastAcceptedKey -> lastAccepted
+ *   |-- txChecksumKey -> lastAccepted + txChecksum
+ *   '-- utxoChecksumKey -> lastAccepted + utxoChecksum
  */
 type state struct {
 	parser block.Parser
@@ -499,6 +503,15 @@ func (s *state) writeMetadata() error {
 		}
 		s.persistedLastAccepted = s.lastAccepted
 	}
+	if s.trackChecksum {
+		txChecksum, utxoChecksum := s.Checksums()
+		if err := lux.PutChecksum(s.singletonDB, txChecksumKey, s.lastAccepted, txChecksum); err != nil {
+			return fmt.Errorf("failed to write tx checksum: %w", err)
+		}
+		if err := lux.PutChecksum(s.singletonDB, utxoChecksumKey, s.lastAccepted, utxoChecksum); err != nil {
+			return fmt.Errorf("failed to write utxo checksum: %w", err)
+		}
+	}
 	return nil
 }
 
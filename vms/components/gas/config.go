@@ -0,0 +1,48 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gas
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	errZeroMaxCapacity              = errors.New("max capacity must be non-zero")
+	errTargetAboveMaxPerSecond      = errors.New("target gas per second must not exceed the max gas per second")
+	errZeroExcessConversionConstant = errors.New("excess conversion constant must be non-zero")
+)
+
+// Config is the configuration of a chain's dynamic fees.
+type Config struct {
+	// Weights to merge the consumed dimensions into a single amount of gas.
+	Weights Dimensions `json:"weights"`
+	// Maximum amount of gas the chain is allowed to store for future use.
+	MaxCapacity Gas `json:"maxCapacity"`
+	// Maximum amount of gas the chain is allowed to consume per second.
+	MaxPerSecond Gas `json:"maxPerSecond"`
+	// Target amount of gas the chain should consume per second to keep the
+	// gas price stable.
+	TargetPerSecond Gas `json:"targetPerSecond"`
+	// Minimum price per unit of gas.
+	MinPrice Price `json:"minPrice"`
+	// Constant used to convert the excess gas into a gas price. The price
+	// increases by a factor of e every [ExcessConversionConstant] units of
+	// excess gas.
+	ExcessConversionConstant Gas `json:"excessConversionConstant"`
+}
+
+// Verify returns an error if the config can't be used to calculate fees.
+func (c *Config) Verify() error {
+	switch {
+	case c.MaxCapacity == 0:
+		return errZeroMaxCapacity
+	case c.TargetPerSecond > c.MaxPerSecond:
+		return fmt.Errorf("%w: %d > %d", errTargetAboveMaxPerSecond, c.TargetPerSecond, c.MaxPerSecond)
+	case c.ExcessConversionConstant == 0:
+		return errZeroExcessConversionConstant
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+func TestDimensionsAdd(t *testing.T) {
+	tests := []struct {
+		name        string
+		lhs         Dimensions
+		rhs         []*Dimensions
+		expected    Dimensions
+		expectedErr error
+	}{
+		{
+			name:     "no args",
+			lhs:      Dimensions{1, 2, 3, 4},
+			expected: Dimensions{1, 2, 3, 4},
+		},
+		{
+			name: "multiple args",
+			lhs:  Dimensions{1, 2, 3, 4},
+			rhs: []*Dimensions{
+				{10, 20, 30, 40},
+				{100, 200, 300, 400},
+			},
+			expected: Dimensions{111, 222, 333, 444},
+		},
+		{
+			name: "overflow",
+			lhs:  Dimensions{math.MaxUint64, 0, 0, 0},
+			rhs: []*Dimensions{
+				{1, 0, 0, 0},
+			},
+			expectedErr: safemath.ErrOverflow,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := test.lhs.Add(test.rhs...)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr == nil {
+				require.Equal(test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDimensionsSub(t *testing.T) {
+	tests := []struct {
+		name        string
+		lhs         Dimensions
+		rhs         []*Dimensions
+		expected    Dimensions
+		expectedErr error
+	}{
+		{
+			name:     "no args",
+			lhs:      Dimensions{1, 2, 3, 4},
+			expected: Dimensions{1, 2, 3, 4},
+		},
+		{
+			name: "multiple args",
+			lhs:  Dimensions{111, 222, 333, 444},
+			rhs: []*Dimensions{
+				{10, 20, 30, 40},
+				{100, 200, 300, 400},
+			},
+			expected: Dimensions{1, 2, 3, 4},
+		},
+		{
+			name: "underflow",
+			lhs:  Dimensions{0, 0, 0, 0},
+			rhs: []*Dimensions{
+				{0, 0, 0, 1},
+			},
+			expectedErr: safemath.ErrUnderflow,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := test.lhs.Sub(test.rhs...)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr == nil {
+				require.Equal(test.expected, actual)
+			}
+		})
+	}
+}
+
+func TestDimensionsToGas(t *testing.T) {
+	tests := []struct {
+		name        string
+		dimensions  Dimensions
+		weights     Dimensions
+		expected    Gas
+		expectedErr error
+	}{
+		{
+			name:       "zero weights",
+			dimensions: Dimensions{1, 2, 3, 4},
+			weights:    Dimensions{},
+			expected:   0,
+		},
+		{
+			name:       "weighted sum",
+			dimensions: Dimensions{1, 2, 3, 4},
+			weights:    Dimensions{1000, 100, 10, 1},
+			expected:   1_000 + 200 + 30 + 4,
+		},
+		{
+			name:        "multiplication overflow",
+			dimensions:  Dimensions{math.MaxUint64, 0, 0, 0},
+			weights:     Dimensions{2, 0, 0, 0},
+			expectedErr: safemath.ErrOverflow,
+		},
+		{
+			name:        "addition overflow",
+			dimensions:  Dimensions{math.MaxUint64, 1, 0, 0},
+			weights:     Dimensions{1, 1, 0, 0},
+			expectedErr: safemath.ErrOverflow,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := test.dimensions.ToGas(test.weights)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
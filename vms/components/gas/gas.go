@@ -0,0 +1,105 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gas
+
+import (
+	"math"
+
+	"github.com/holiman/uint256"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+var maxUint64 = new(uint256.Int).SetUint64(math.MaxUint64)
+
+type (
+	Gas   uint64
+	Price uint64
+)
+
+// Cost converts the gas to nLUX based on the price.
+//
+// If overflow occurs, an error is returned.
+func (g Gas) Cost(price Price) (uint64, error) {
+	return safemath.Mul64(uint64(g), uint64(price))
+}
+
+// AddPerSecond returns g + gasPerSecond * seconds.
+//
+// If overflow occurs, MaxUint64 is returned.
+func (g Gas) AddPerSecond(gasPerSecond Gas, seconds uint64) Gas {
+	newGas, err := safemath.Mul64(uint64(gasPerSecond), seconds)
+	if err != nil {
+		return math.MaxUint64
+	}
+	totalGas, err := safemath.Add64(uint64(g), newGas)
+	if err != nil {
+		return math.MaxUint64
+	}
+	return Gas(totalGas)
+}
+
+// SubPerSecond returns g - gasPerSecond * seconds.
+//
+// If underflow occurs, 0 is returned.
+func (g Gas) SubPerSecond(gasPerSecond Gas, seconds uint64) Gas {
+	gasToRemove, err := safemath.Mul64(uint64(gasPerSecond), seconds)
+	if err != nil {
+		return 0
+	}
+	totalGas, err := safemath.Sub(uint64(g), gasToRemove)
+	if err != nil {
+		return 0
+	}
+	return Gas(totalGas)
+}
+
+// CalculatePrice returns the gas price given the minimum gas price, the
+// excess gas, and the excess conversion constant.
+//
+// It is defined as an approximation of:
+//
+//	minPrice * e^(excess / excessConversionConstant)
+//
+// This implements the EIP-4844 fake exponential formula:
+//
+//	def fake_exponential(factor: int, numerator: int, denominator: int) -> int:
+//		i = 1
+//		output = 0
+//		numerator_accum = factor * denominator
+//		while numerator_accum > 0:
+//			output += numerator_accum
+//			numerator_accum = (numerator_accum * numerator) // (denominator * i)
+//			i += 1
+//		return output // denominator
+//
+// This implementation returns MaxUint64 once the price would exceed it, so
+// every intermediate value fits in a uint256.
+func CalculatePrice(
+	minPrice Price,
+	excess Gas,
+	excessConversionConstant Gas,
+) Price {
+	var (
+		numerator   = uint256.NewInt(uint64(excess))
+		denominator = uint256.NewInt(uint64(excessConversionConstant))
+
+		i              uint256.Int
+		output         uint256.Int
+		numeratorAccum = new(uint256.Int).Mul(uint256.NewInt(uint64(minPrice)), denominator)
+		maxOutput      = new(uint256.Int).Mul(denominator, maxUint64)
+		divisor        uint256.Int
+	)
+	for i.SetOne(); numeratorAccum.Sign() > 0; i.AddUint64(&i, 1) {
+		output.Add(&output, numeratorAccum)
+		if output.Cmp(maxOutput) >= 0 {
+			return math.MaxUint64
+		}
+
+		numeratorAccum.Mul(numeratorAccum, numerator)
+		divisor.Mul(denominator, &i)
+		numeratorAccum.Div(numeratorAccum, &divisor)
+	}
+	return Price(output.Div(&output, denominator).Uint64())
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gas
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+const (
+	// The constant used in the tests. The price doubles every ~30 seconds of
+	// consuming gas at the max rate, with a target of half of the max rate.
+	testExcessConversionConstant = 2_164_043
+)
+
+func TestGasCost(t *testing.T) {
+	tests := []struct {
+		gas          Gas
+		price        Price
+		expectedCost uint64
+		expectedErr  error
+	}{
+		{
+			gas:          0,
+			price:        100,
+			expectedCost: 0,
+		},
+		{
+			gas:          10,
+			price:        100,
+			expectedCost: 1_000,
+		},
+		{
+			gas:          math.MaxUint64,
+			price:        1,
+			expectedCost: math.MaxUint64,
+		},
+		{
+			gas:         math.MaxUint64,
+			price:       2,
+			expectedErr: safemath.ErrOverflow,
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d*%d", test.gas, test.price), func(t *testing.T) {
+			require := require.New(t)
+
+			cost, err := test.gas.Cost(test.price)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expectedCost, cost)
+		})
+	}
+}
+
+func TestGasAddPerSecond(t *testing.T) {
+	tests := []struct {
+		name         string
+		gas          Gas
+		gasPerSecond Gas
+		seconds      uint64
+		expected     Gas
+	}{
+		{
+			name:         "no time",
+			gas:          10,
+			gasPerSecond: 5,
+			seconds:      0,
+			expected:     10,
+		},
+		{
+			name:         "normal",
+			gas:          10,
+			gasPerSecond: 5,
+			seconds:      2,
+			expected:     20,
+		},
+		{
+			name:         "overflow multiplication",
+			gas:          10,
+			gasPerSecond: math.MaxUint64,
+			seconds:      2,
+			expected:     math.MaxUint64,
+		},
+		{
+			name:         "overflow addition",
+			gas:          math.MaxUint64,
+			gasPerSecond: 1,
+			seconds:      1,
+			expected:     math.MaxUint64,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.gas.AddPerSecond(test.gasPerSecond, test.seconds))
+		})
+	}
+}
+
+func TestGasSubPerSecond(t *testing.T) {
+	tests := []struct {
+		name         string
+		gas          Gas
+		gasPerSecond Gas
+		seconds      uint64
+		expected     Gas
+	}{
+		{
+			name:         "no time",
+			gas:          10,
+			gasPerSecond: 5,
+			seconds:      0,
+			expected:     10,
+		},
+		{
+			name:         "normal",
+			gas:          20,
+			gasPerSecond: 5,
+			seconds:      2,
+			expected:     10,
+		},
+		{
+			name:         "overflow multiplication",
+			gas:          10,
+			gasPerSecond: math.MaxUint64,
+			seconds:      2,
+			expected:     0,
+		},
+		{
+			name:         "underflow subtraction",
+			gas:          10,
+			gasPerSecond: 6,
+			seconds:      2,
+			expected:     0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, test.gas.SubPerSecond(test.gasPerSecond, test.seconds))
+		})
+	}
+}
+
+func TestCalculatePrice(t *testing.T) {
+	tests := []struct {
+		minPrice                 Price
+		excess                   Gas
+		excessConversionConstant Gas
+		expected                 Price
+	}{
+		{
+			minPrice:                 1,
+			excess:                   0,
+			excessConversionConstant: 1,
+			expected:                 1,
+		},
+		{
+			minPrice:                 1,
+			excess:                   1,
+			excessConversionConstant: 1,
+			expected:                 2, // e^1 ~= 2.718
+		},
+		{
+			minPrice:                 100,
+			excess:                   0,
+			excessConversionConstant: testExcessConversionConstant,
+			expected:                 100,
+		},
+		{
+			minPrice:                 100,
+			excess:                   testExcessConversionConstant,
+			excessConversionConstant: testExcessConversionConstant,
+			expected:                 271, // 100 * e^1 ~= 271.8
+		},
+		{
+			minPrice:                 1_000_000,
+			excess:                   2 * testExcessConversionConstant,
+			excessConversionConstant: testExcessConversionConstant,
+			expected:                 7_389_056, // 1_000_000 * e^2 ~= 7_389_056.1
+		},
+		{
+			minPrice:                 1,
+			excess:                   math.MaxUint64,
+			excessConversionConstant: 1,
+			expected:                 math.MaxUint64,
+		},
+		{
+			minPrice:                 math.MaxUint64,
+			excess:                   0,
+			excessConversionConstant: math.MaxUint64,
+			expected:                 math.MaxUint64,
+		},
+		{
+			minPrice:                 math.MaxUint64,
+			excess:                   1,
+			excessConversionConstant: math.MaxUint64,
+			expected:                 math.MaxUint64,
+		},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d*e^(%d/%d)", test.minPrice, test.excess, test.excessConversionConstant), func(t *testing.T) {
+			require.Equal(
+				t,
+				test.expected,
+				CalculatePrice(test.minPrice, test.excess, test.excessConversionConstant),
+			)
+		})
+	}
+}
+
+func BenchmarkCalculatePrice(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		CalculatePrice(1, 10*testExcessConversionConstant, testExcessConversionConstant)
+	}
+}
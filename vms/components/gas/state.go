@@ -0,0 +1,68 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gas
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+var ErrInsufficientCapacity = errors.New("insufficient capacity")
+
+// State is the gas state of a chain, which determines how much gas can be
+// consumed and how much it costs.
+type State struct {
+	// Capacity is the amount of gas that can currently be consumed.
+	Capacity Gas `serialize:"true" json:"capacity"`
+	// Excess is the amount of gas consumed above the target rate, which
+	// determines the gas price.
+	Excess Gas `serialize:"true" json:"excess"`
+}
+
+// AdvanceTime adds maxPerSecond to the capacity and removes targetPerSecond
+// from the excess for each of the [seconds] that passed.
+//
+// The capacity is capped at maxCapacity and the excess never goes below 0.
+func (s State) AdvanceTime(
+	maxCapacity Gas,
+	maxPerSecond Gas,
+	targetPerSecond Gas,
+	seconds uint64,
+) State {
+	return State{
+		Capacity: min(
+			s.Capacity.AddPerSecond(maxPerSecond, seconds),
+			maxCapacity,
+		),
+		Excess: s.Excess.SubPerSecond(targetPerSecond, seconds),
+	}
+}
+
+// ConsumeGas removes [gas] from the capacity and adds it to the excess.
+//
+// If the capacity is insufficient, an error is returned. If the excess would
+// overflow, it is capped at MaxUint64.
+func (s State) ConsumeGas(gas Gas) (State, error) {
+	newCapacity, err := safemath.Sub(uint64(s.Capacity), uint64(gas))
+	if err != nil {
+		return State{}, fmt.Errorf("%w: capacity (%d) < gas (%d)",
+			ErrInsufficientCapacity,
+			s.Capacity,
+			gas,
+		)
+	}
+
+	newExcess, err := safemath.Add64(uint64(s.Excess), uint64(gas))
+	if err != nil {
+		newExcess = math.MaxUint64
+	}
+
+	return State{
+		Capacity: Gas(newCapacity),
+		Excess:   Gas(newExcess),
+	}, nil
+}
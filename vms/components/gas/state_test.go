@@ -0,0 +1,190 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gas
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateAdvanceTime(t *testing.T) {
+	tests := []struct {
+		name            string
+		initial         State
+		maxCapacity     Gas
+		maxPerSecond    Gas
+		targetPerSecond Gas
+		seconds         uint64
+		expected        State
+	}{
+		{
+			name: "no time",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			maxCapacity:     100,
+			maxPerSecond:    10,
+			targetPerSecond: 5,
+			seconds:         0,
+			expected: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+		},
+		{
+			name: "refill and decay",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			maxCapacity:     100,
+			maxPerSecond:    10,
+			targetPerSecond: 5,
+			seconds:         2,
+			expected: State{
+				Capacity: 30,
+				Excess:   10,
+			},
+		},
+		{
+			name: "capacity capped",
+			initial: State{
+				Capacity: 95,
+				Excess:   20,
+			},
+			maxCapacity:     100,
+			maxPerSecond:    10,
+			targetPerSecond: 5,
+			seconds:         1,
+			expected: State{
+				Capacity: 100,
+				Excess:   15,
+			},
+		},
+		{
+			name: "excess floored",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			maxCapacity:     100,
+			maxPerSecond:    10,
+			targetPerSecond: 5,
+			seconds:         5,
+			expected: State{
+				Capacity: 60,
+				Excess:   0,
+			},
+		},
+		{
+			name: "overflow",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			maxCapacity:     math.MaxUint64,
+			maxPerSecond:    math.MaxUint64,
+			targetPerSecond: math.MaxUint64,
+			seconds:         math.MaxUint64,
+			expected: State{
+				Capacity: math.MaxUint64,
+				Excess:   0,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(
+				t,
+				test.expected,
+				test.initial.AdvanceTime(
+					test.maxCapacity,
+					test.maxPerSecond,
+					test.targetPerSecond,
+					test.seconds,
+				),
+			)
+		})
+	}
+}
+
+func TestStateConsumeGas(t *testing.T) {
+	tests := []struct {
+		name        string
+		initial     State
+		gas         Gas
+		expected    State
+		expectedErr error
+	}{
+		{
+			name: "consume nothing",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			gas: 0,
+			expected: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+		},
+		{
+			name: "consume some",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			gas: 5,
+			expected: State{
+				Capacity: 5,
+				Excess:   25,
+			},
+		},
+		{
+			name: "consume all",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			gas: 10,
+			expected: State{
+				Capacity: 0,
+				Excess:   30,
+			},
+		},
+		{
+			name: "insufficient capacity",
+			initial: State{
+				Capacity: 10,
+				Excess:   20,
+			},
+			gas:         11,
+			expectedErr: ErrInsufficientCapacity,
+		},
+		{
+			name: "excess overflow",
+			initial: State{
+				Capacity: 10,
+				Excess:   math.MaxUint64,
+			},
+			gas: 10,
+			expected: State{
+				Capacity: 0,
+				Excess:   math.MaxUint64,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := test.initial.ConsumeGas(test.gas)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
@@ -4,6 +4,10 @@
 package lux
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/skychains/chain/cache"
@@ -21,8 +25,15 @@ const (
 )
 
 var (
-	utxoPrefix  = []byte("utxo")
-	indexPrefix = []byte("index")
+	// Prefixes, of the database provided to a UTXOState, that UTXOs and the
+	// address index are stored under.
+	UTXOPrefix  = []byte("utxo")
+	IndexPrefix = []byte("index")
+
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	errUnexpectedUTXOID = errors.New("unexpected UTXO ID")
+	errInvalidChecksum  = errors.New("invalid recorded checksum")
 )
 
 // UTXOState is a thin wrapper around a database to provide, caching,
@@ -93,9 +104,9 @@ func NewUTXOState(
 		codec: codec,
 
 		utxoCache: &cache.LRU[ids.ID, *UTXO]{Size: utxoCacheSize},
-		utxoDB:    prefixdb.New(utxoPrefix, db),
+		utxoDB:    prefixdb.New(UTXOPrefix, db),
 
-		indexDB:    prefixdb.New(indexPrefix, db),
+		indexDB:    prefixdb.New(IndexPrefix, db),
 		indexCache: &cache.LRU[string, linkeddb.LinkedDB]{Size: indexCacheSize},
 
 		trackChecksum: trackChecksum,
@@ -133,9 +144,9 @@ func NewMeteredUTXOState(
 		codec: codec,
 
 		utxoCache: utxoCache,
-		utxoDB:    prefixdb.New(utxoPrefix, db),
+		utxoDB:    prefixdb.New(UTXOPrefix, db),
 
-		indexDB:    prefixdb.New(indexPrefix, db),
+		indexDB:    prefixdb.New(IndexPrefix, db),
 		indexCache: indexCache,
 
 		trackChecksum: trackChecksum,
@@ -292,3 +303,88 @@ func (s *utxoState) updateChecksum(modifiedID ids.ID) {
 
 	s.checksum = s.checksum.XOR(modifiedID)
 }
+
+// VerifyChecksum verifies that every UTXO stored in [db], the database provided
+// to a UTXOState, can be parsed and is stored under its ID. Returns the
+// checksum of the stored UTXOs.
+func VerifyChecksum(ctx context.Context, db database.Database, codec codec.Manager) (ids.ID, error) {
+	utxoDB := prefixdb.New(UTXOPrefix, db)
+	it := utxoDB.NewIterator()
+	defer it.Release()
+
+	var checksum ids.ID
+	for it.Next() {
+		if err := ctx.Err(); err != nil {
+			return ids.Empty, err
+		}
+
+		utxoID, err := ids.ToID(it.Key())
+		if err != nil {
+			return ids.Empty, err
+		}
+
+		utxo := &UTXO{}
+		if _, err := codec.Unmarshal(it.Value(), utxo); err != nil {
+			return ids.Empty, fmt.Errorf("couldn't parse UTXO %s: %w", utxoID, err)
+		}
+		if inputID := utxo.InputID(); inputID != utxoID {
+			return ids.Empty, fmt.Errorf("%w: UTXO %s is stored under %s",
+				errUnexpectedUTXOID,
+				inputID,
+				utxoID,
+			)
+		}
+		checksum = checksum.XOR(utxoID)
+	}
+	return checksum, it.Error()
+}
+
+// PutChecksum records, under [key], that [checksum] was the checksum of the
+// state when [blkID] was the last accepted block.
+func PutChecksum(db database.KeyValueWriter, key []byte, blkID ids.ID, checksum ids.ID) error {
+	value := make([]byte, 0, 2*ids.IDLen)
+	value = append(value, blkID[:]...)
+	value = append(value, checksum[:]...)
+	return db.Put(key, value)
+}
+
+// VerifyRecordedChecksum compares [checksum], the checksum of the state when
+// [lastAccepted] is the last accepted block, to the checksum recorded under
+// [key]. ErrChecksumMismatch is returned if they differ. Returns false if no
+// checksum was recorded when [lastAccepted] was the last accepted block.
+func VerifyRecordedChecksum(
+	db database.KeyValueReader,
+	key []byte,
+	lastAccepted ids.ID,
+	checksum ids.ID,
+) (bool, error) {
+	value, err := db.Get(key)
+	if err == database.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if len(value) != 2*ids.IDLen {
+		return false, fmt.Errorf("%w: expected %d bytes but got %d",
+			errInvalidChecksum,
+			2*ids.IDLen,
+			len(value),
+		)
+	}
+
+	blkID := ids.ID(value[:ids.IDLen])
+	if blkID != lastAccepted {
+		return false, nil
+	}
+	recordedChecksum := ids.ID(value[ids.IDLen:])
+	if recordedChecksum != checksum {
+		return true, fmt.Errorf("%w: recorded %s at block %s but computed %s",
+			ErrChecksumMismatch,
+			recordedChecksum,
+			blkID,
+			checksum,
+		)
+	}
+	return true, nil
+}
@@ -4,6 +4,7 @@
 package lux
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -12,6 +13,7 @@ import (
 	"github.com/skychains/chain/codec/linearcodec"
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/database/prefixdb"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/vms/secp256k1fx"
 )
@@ -92,3 +94,44 @@ func TestUTXOState(t *testing.T) {
 	require.NoError(err)
 	require.Equal([]ids.ID{utxoID}, utxoIDs)
 }
+
+func TestVerifyChecksum(t *testing.T) {
+	require := require.New(t)
+
+	c := linearcodec.NewDefault()
+	manager := codec.NewDefaultManager()
+	require.NoError(c.RegisterType(&secp256k1fx.TransferOutput{}))
+	require.NoError(manager.RegisterCodec(codecVersion, c))
+
+	db := memdb.New()
+	s, err := NewUTXOState(db, manager, true)
+	require.NoError(err)
+
+	utxos := make([]*UTXO, 3)
+	for i := range utxos {
+		utxos[i] = &UTXO{
+			UTXOID: UTXOID{
+				TxID:        ids.GenerateTestID(),
+				OutputIndex: uint32(i),
+			},
+			Asset: Asset{ID: ids.GenerateTestID()},
+			Out: &secp256k1fx.TransferOutput{
+				Amt: uint64(i + 1),
+			},
+		}
+		require.NoError(s.PutUTXO(utxos[i]))
+	}
+
+	checksum, err := VerifyChecksum(context.Background(), db, manager)
+	require.NoError(err)
+	require.Equal(s.Checksum(), checksum)
+
+	// Store a UTXO under the wrong ID.
+	utxoBytes, err := manager.Marshal(codecVersion, utxos[0])
+	require.NoError(err)
+	wrongID := utxos[1].InputID()
+	require.NoError(prefixdb.New(UTXOPrefix, db).Put(wrongID[:], utxoBytes))
+
+	_, err = VerifyChecksum(context.Background(), db, manager)
+	require.ErrorIs(err, errUnexpectedUTXOID)
+}
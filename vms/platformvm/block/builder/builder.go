@@ -18,10 +18,12 @@ import (
 	"github.com/skychains/chain/utils/set"
 	"github.com/skychains/chain/utils/timer/mockable"
 	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/block"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/status"
 	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/platformvm/txs/fee"
 	"github.com/skychains/chain/vms/platformvm/txs/mempool"
 
 	blockexecutor "github.com/skychains/chain/vms/platformvm/block/executor"
@@ -332,6 +334,10 @@ func packBlockTxs(
 	}
 
 	var (
+		isFActive    = backend.Config.UpgradeConfig.IsFActivated(timestamp)
+		feeConfig    = backend.Config.DynamicFeeConfig
+		remainingGas = stateDiff.GetFeeState().Capacity
+
 		blockTxs []*txs.Tx
 		inputs   set.Set[ids.ID]
 	)
@@ -345,6 +351,26 @@ func packBlockTxs(
 		if txSize > remainingSize {
 			break
 		}
+
+		var txGas gas.Gas
+		if isFActive {
+			complexity, err := fee.TxComplexity(tx.Unsigned)
+			if err == nil {
+				txGas, err = complexity.ToGas(feeConfig.Weights)
+			}
+			if err == nil && txGas > feeConfig.MaxCapacity {
+				err = blockexecutor.ErrTxGasExceedsMaxCapacity
+			}
+			if err != nil {
+				txID := tx.ID()
+				mempool.Remove(tx)
+				mempool.MarkDropped(txID, err)
+				continue
+			}
+			if txGas > remainingGas {
+				break
+			}
+		}
 		mempool.Remove(tx)
 
 		// Invariant: [tx] has already been syntactically verified.
@@ -387,6 +413,7 @@ func packBlockTxs(
 		}
 
 		remainingSize -= txSize
+		remainingGas -= txGas
 		blockTxs = append(blockTxs, tx)
 	}
 
@@ -5,15 +5,18 @@ package executor
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/snow/consensus/snowman"
 	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/block"
 	"github.com/skychains/chain/vms/platformvm/metrics"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/txs"
 	"github.com/skychains/chain/vms/platformvm/txs/executor"
+	"github.com/skychains/chain/vms/platformvm/txs/fee"
 	"github.com/skychains/chain/vms/platformvm/txs/mempool"
 	"github.com/skychains/chain/vms/platformvm/validators"
 )
@@ -21,7 +24,8 @@ import (
 var (
 	_ Manager = (*manager)(nil)
 
-	ErrChainNotSynced = errors.New("chain not synced")
+	ErrChainNotSynced          = errors.New("chain not synced")
+	ErrTxGasExceedsMaxCapacity = errors.New("tx gas exceeds max capacity")
 )
 
 type Manager interface {
@@ -30,6 +34,13 @@ type Manager interface {
 	// Returns the ID of the most recently accepted block.
 	LastAccepted() ids.ID
 
+	// SetLastAccepted marks [blkID] as the most recently accepted block after
+	// the state was replaced by state sync. [blkID] also becomes the preferred
+	// block.
+	//
+	// Invariant: [blkID] must be the last accepted block of the state.
+	SetLastAccepted(blkID ids.ID)
+
 	SetPreference(blkID ids.ID) (updated bool)
 	Preferred() ids.ID
 
@@ -122,6 +133,11 @@ func (m *manager) Preferred() ids.ID {
 	return m.preferred
 }
 
+func (m *manager) SetLastAccepted(blkID ids.ID) {
+	m.backend.lastAccepted = blkID
+	m.preferred = blkID
+}
+
 func (m *manager) VerifyTx(tx *txs.Tx) error {
 	if !m.txExecutorBackend.Bootstrapped.Get() {
 		return ErrChainNotSynced
@@ -142,6 +158,14 @@ func (m *manager) VerifyTx(tx *txs.Tx) error {
 		return err
 	}
 
+	// After the F upgrade, a tx that consumes more gas than the chain can
+	// ever have available can never be included in a block.
+	if m.txExecutorBackend.Config.UpgradeConfig.IsFActivated(nextBlkTime) {
+		if err := verifyTxGas(&m.txExecutorBackend.Config.DynamicFeeConfig, tx.Unsigned); err != nil {
+			return err
+		}
+	}
+
 	return tx.Unsigned.Visit(&executor.StandardTxExecutor{
 		Backend: m.txExecutorBackend,
 		State:   stateDiff,
@@ -152,3 +176,20 @@ func (m *manager) VerifyTx(tx *txs.Tx) error {
 func (m *manager) VerifyUniqueInputs(blkID ids.ID, inputs set.Set[ids.ID]) error {
 	return m.backend.verifyUniqueInputs(blkID, inputs)
 }
+
+// verifyTxGas returns an error if [tx] consumes more gas than the max capacity
+// allowed by [config].
+func verifyTxGas(config *gas.Config, tx txs.UnsignedTx) error {
+	complexity, err := fee.TxComplexity(tx)
+	if err != nil {
+		return err
+	}
+	txGas, err := complexity.ToGas(config.Weights)
+	if err != nil {
+		return err
+	}
+	if txGas > config.MaxCapacity {
+		return fmt.Errorf("%w: %d > %d", ErrTxGasExceedsMaxCapacity, txGas, config.MaxCapacity)
+	}
+	return nil
+}
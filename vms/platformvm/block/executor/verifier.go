@@ -10,11 +10,13 @@ import (
 	"github.com/skychains/chain/chains/atomic"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/block"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/status"
 	"github.com/skychains/chain/vms/platformvm/txs"
 	"github.com/skychains/chain/vms/platformvm/txs/executor"
+	"github.com/skychains/chain/vms/platformvm/txs/fee"
 )
 
 var (
@@ -478,6 +480,33 @@ func (v *verifier) processStandardTxs(txs []*txs.Tx, state state.Diff, parentID
 		return nil, nil, nil, err
 	}
 
+	// After the F upgrade, the gas consumed by the txs is removed from the
+	// capacity of the chain. The consumed gas only impacts the gas price of
+	// future blocks.
+	if v.txExecutorBackend.Config.UpgradeConfig.IsFActivated(state.GetTimestamp()) {
+		var complexity gas.Dimensions
+		for _, tx := range txs {
+			txComplexity, err := fee.TxComplexity(tx.Unsigned)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to calculate tx complexity: %w", err)
+			}
+			complexity, err = complexity.Add(&txComplexity)
+			if err != nil {
+				return nil, nil, nil, fmt.Errorf("failed to calculate block complexity: %w", err)
+			}
+		}
+		blockGas, err := complexity.ToGas(v.txExecutorBackend.Config.DynamicFeeConfig.Weights)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to calculate block gas: %w", err)
+		}
+
+		feeState, err := state.GetFeeState().ConsumeGas(blockGas)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		state.SetFeeState(feeState)
+	}
+
 	if numFuncs := len(funcs); numFuncs == 1 {
 		onAcceptFunc = funcs[0]
 	} else if numFuncs > 1 {
@@ -16,6 +16,8 @@ import (
 	"github.com/skychains/chain/utils/formatting/address"
 	"github.com/skychains/chain/utils/json"
 	"github.com/skychains/chain/utils/rpc"
+	"github.com/skychains/chain/vms/components/gas"
+	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/status"
 )
 
@@ -109,6 +111,11 @@ type Client interface {
 	GetRewardUTXOs(context.Context, *api.GetTxArgs, ...rpc.Option) ([][]byte, error)
 	// GetTimestamp returns the current chain timestamp
 	GetTimestamp(ctx context.Context, options ...rpc.Option) (time.Time, error)
+	// GetFeeConfig returns the dynamic fee config of the chain.
+	GetFeeConfig(ctx context.Context, options ...rpc.Option) (*gas.Config, error)
+	// GetFeeState returns the current fee state of the chain, the current gas
+	// price, and the timestamp of the last accepted block.
+	GetFeeState(ctx context.Context, options ...rpc.Option) (gas.State, gas.Price, time.Time, error)
 	// GetValidatorsAt returns the weights of the validator set of a provided
 	// subnet at the specified height.
 	GetValidatorsAt(
@@ -117,6 +124,32 @@ type Client interface {
 		height uint64,
 		options ...rpc.Option,
 	) (map[ids.NodeID]*validators.GetValidatorOutput, error)
+	// GetValidatorSetDiff returns the changes to the validator set of a
+	// provided subnet between [fromHeight] and [toHeight].
+	GetValidatorSetDiff(
+		ctx context.Context,
+		subnetID ids.ID,
+		fromHeight uint64,
+		toHeight uint64,
+		options ...rpc.Option,
+	) (*GetValidatorSetDiffReply, error)
+	// GetRewardConfig returns the parameters used to calculate staking rewards
+	// on the subnet with ID [subnetID].
+	GetRewardConfig(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (*reward.Config, error)
+	// EstimateReward returns the reward that would be paid for staking
+	// [stakeAmount] on the subnet with ID [subnetID] for [duration], given the
+	// current supply. If [nodeID] is provided, the reward is split using the
+	// delegation fee of that current validator. Otherwise, if [delegationFee]
+	// is provided, the reward is split using it.
+	EstimateReward(
+		ctx context.Context,
+		subnetID ids.ID,
+		stakeAmount uint64,
+		duration time.Duration,
+		nodeID *ids.NodeID,
+		delegationFee *uint32,
+		options ...rpc.Option,
+	) (*EstimateRewardReply, error)
 	// GetBlock returns the block with the given id.
 	GetBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]byte, error)
 	// GetBlockByHeight returns the block at the given [height].
@@ -478,6 +511,18 @@ func (c *client) GetTimestamp(ctx context.Context, options ...rpc.Option) (time.
 	return res.Timestamp, err
 }
 
+func (c *client) GetFeeConfig(ctx context.Context, options ...rpc.Option) (*gas.Config, error) {
+	res := &gas.Config{}
+	err := c.requester.SendRequest(ctx, "platform.getFeeConfig", struct{}{}, res, options...)
+	return res, err
+}
+
+func (c *client) GetFeeState(ctx context.Context, options ...rpc.Option) (gas.State, gas.Price, time.Time, error) {
+	res := &GetFeeStateReply{}
+	err := c.requester.SendRequest(ctx, "platform.getFeeState", struct{}{}, res, options...)
+	return res.State, res.Price, res.Time, err
+}
+
 func (c *client) GetValidatorsAt(
 	ctx context.Context,
 	subnetID ids.ID,
@@ -492,6 +537,61 @@ func (c *client) GetValidatorsAt(
 	return res.Validators, err
 }
 
+func (c *client) GetValidatorSetDiff(
+	ctx context.Context,
+	subnetID ids.ID,
+	fromHeight uint64,
+	toHeight uint64,
+	options ...rpc.Option,
+) (*GetValidatorSetDiffReply, error) {
+	res := &GetValidatorSetDiffReply{}
+	err := c.requester.SendRequest(ctx, "platform.getValidatorSetDiff", &GetValidatorSetDiffArgs{
+		SubnetID:   subnetID,
+		FromHeight: json.Uint64(fromHeight),
+		ToHeight:   json.Uint64(toHeight),
+	}, res, options...)
+	return res, err
+}
+
+func (c *client) GetRewardConfig(ctx context.Context, subnetID ids.ID, options ...rpc.Option) (*reward.Config, error) {
+	res := &GetRewardConfigReply{}
+	if err := c.requester.SendRequest(ctx, "platform.getRewardConfig", &GetRewardConfigArgs{
+		SubnetID: subnetID,
+	}, res, options...); err != nil {
+		return nil, err
+	}
+	return &reward.Config{
+		MaxConsumptionRate: uint64(res.MaxConsumptionRate),
+		MinConsumptionRate: uint64(res.MinConsumptionRate),
+		MintingPeriod:      time.Duration(res.MintingPeriod) * time.Second,
+		SupplyCap:          uint64(res.SupplyCap),
+	}, nil
+}
+
+func (c *client) EstimateReward(
+	ctx context.Context,
+	subnetID ids.ID,
+	stakeAmount uint64,
+	duration time.Duration,
+	nodeID *ids.NodeID,
+	delegationFee *uint32,
+	options ...rpc.Option,
+) (*EstimateRewardReply, error) {
+	args := &EstimateRewardArgs{
+		SubnetID:    subnetID,
+		StakeAmount: json.Uint64(stakeAmount),
+		Duration:    json.Uint64(duration / time.Second),
+		NodeID:      nodeID,
+	}
+	if delegationFee != nil {
+		fee := json.Uint32(*delegationFee)
+		args.DelegationFee = &fee
+	}
+	res := &EstimateRewardReply{}
+	err := c.requester.SendRequest(ctx, "platform.estimateReward", args, res, options...)
+	return res, err
+}
+
 func (c *client) GetBlock(ctx context.Context, blockID ids.ID, options ...rpc.Option) ([]byte, error) {
 	res := &api.FormattedBlock{}
 	if err := c.requester.SendRequest(ctx, "platform.getBlock", &api.GetBlockArgs{
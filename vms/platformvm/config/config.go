@@ -12,6 +12,7 @@ import (
 	"github.com/skychains/chain/snow/validators"
 	"github.com/skychains/chain/utils/constants"
 	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/txs"
 	"github.com/skychains/chain/vms/platformvm/txs/fee"
@@ -31,9 +32,16 @@ type Config struct {
 	//            calling VM.Initialize.
 	Validators validators.Manager
 
-	// All static fees config active before E-upgrade
+	// All static fees config active before the F upgrade
 	StaticFeeConfig fee.StaticConfig
 
+	// Dynamic fees config active after the F upgrade
+	DynamicFeeConfig gas.Config
+
+	// Fee, in nLUX, charged per second to each active subnet only validator
+	// after the F upgrade
+	SubnetOnlyValidatorFee uint64
+
 	// Provides access to the uptime manager as a thread safe data structure
 	UptimeLockedCalculator uptime.LockedCalculator
 
@@ -23,6 +23,8 @@ var DefaultExecutionConfig = ExecutionConfig{
 	FxOwnerCacheSize:             4 * units.MiB,
 	ChecksumsEnabled:             false,
 	MempoolPruneFrequency:        30 * time.Minute,
+	StateSyncEnabled:             false,
+	StateSyncSummaryFrequency:    16384,
 }
 
 // ExecutionConfig provides execution parameters of PlatformVM
@@ -38,6 +40,13 @@ type ExecutionConfig struct {
 	FxOwnerCacheSize             int            `json:"fx-owner-cache-size"`
 	ChecksumsEnabled             bool           `json:"checksums-enabled"`
 	MempoolPruneFrequency        time.Duration  `json:"mempool-prune-frequency"`
+	// StateSyncEnabled allows the node to sync the P-chain state from a
+	// recent summary rather than executing every block since genesis.
+	StateSyncEnabled bool `json:"state-sync-enabled"`
+	// StateSyncSummaryFrequency is the number of blocks between the state
+	// summaries that are produced and served to syncing peers. If 0, no
+	// summaries are produced.
+	StateSyncSummaryFrequency uint64 `json:"state-sync-summary-frequency"`
 }
 
 // GetExecutionConfig returns an ExecutionConfig
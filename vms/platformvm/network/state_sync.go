@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/network/p2p"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/wrappers"
+)
+
+// stateSyncRequestLen is the size of a serialized [StateSyncRequest].
+const stateSyncRequestLen = 2 * wrappers.LongLen
+
+var (
+	_ p2p.Handler = (*StateSyncHandler)(nil)
+
+	errInvalidStateSyncRequest = errors.New("invalid state sync request")
+)
+
+// StateSyncChunkGetter provides the chunks of the stored state snapshot.
+type StateSyncChunkGetter interface {
+	// GetSyncChunk returns the chunk at [index] of the snapshot at [height].
+	GetSyncChunk(height uint64, index uint64) ([]byte, error)
+}
+
+// StateSyncRequest requests the chunk at [Index] of the snapshot at [Height].
+type StateSyncRequest struct {
+	Height uint64
+	Index  uint64
+}
+
+func (r StateSyncRequest) Bytes() []byte {
+	bytes := make([]byte, stateSyncRequestLen)
+	binary.BigEndian.PutUint64(bytes, r.Height)
+	binary.BigEndian.PutUint64(bytes[wrappers.LongLen:], r.Index)
+	return bytes
+}
+
+func ParseStateSyncRequest(bytes []byte) (StateSyncRequest, error) {
+	if len(bytes) != stateSyncRequestLen {
+		return StateSyncRequest{}, fmt.Errorf("%w: expected %d bytes but got %d",
+			errInvalidStateSyncRequest,
+			stateSyncRequestLen,
+			len(bytes),
+		)
+	}
+	return StateSyncRequest{
+		Height: binary.BigEndian.Uint64(bytes),
+		Index:  binary.BigEndian.Uint64(bytes[wrappers.LongLen:]),
+	}, nil
+}
+
+// StateSyncHandler serves the chunks of the stored state snapshot to syncing
+// peers.
+type StateSyncHandler struct {
+	p2p.NoOpHandler
+
+	log    logging.Logger
+	lock   sync.Locker
+	chunks StateSyncChunkGetter
+}
+
+// NewStateSyncHandler returns a handler that serves chunks from [chunks].
+// [lock] is held while reading from [chunks].
+func NewStateSyncHandler(
+	log logging.Logger,
+	lock sync.Locker,
+	chunks StateSyncChunkGetter,
+) *StateSyncHandler {
+	return &StateSyncHandler{
+		log:    log,
+		lock:   lock,
+		chunks: chunks,
+	}
+}
+
+func (h *StateSyncHandler) AppRequest(
+	_ context.Context,
+	nodeID ids.NodeID,
+	_ time.Time,
+	requestBytes []byte,
+) ([]byte, *common.AppError) {
+	request, err := ParseStateSyncRequest(requestBytes)
+	if err != nil {
+		h.log.Debug("dropping state sync request",
+			zap.Stringer("nodeID", nodeID),
+			zap.Error(err),
+		)
+		return nil, p2p.ErrUnexpected
+	}
+
+	h.lock.Lock()
+	chunk, err := h.chunks.GetSyncChunk(request.Height, request.Index)
+	h.lock.Unlock()
+	if err != nil {
+		h.log.Debug("failed to serve state sync request",
+			zap.Stringer("nodeID", nodeID),
+			zap.Uint64("height", request.Height),
+			zap.Uint64("index", request.Index),
+			zap.Error(err),
+		)
+		return nil, p2p.ErrUnexpected
+	}
+	return chunk, nil
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package network
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/network/p2p"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/utils/logging"
+)
+
+type testChunkGetter map[StateSyncRequest][]byte
+
+func (g testChunkGetter) GetSyncChunk(height uint64, index uint64) ([]byte, error) {
+	chunk, ok := g[StateSyncRequest{
+		Height: height,
+		Index:  index,
+	}]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return chunk, nil
+}
+
+func TestStateSyncRequestSerialization(t *testing.T) {
+	require := require.New(t)
+
+	request := StateSyncRequest{
+		Height: 16384,
+		Index:  3,
+	}
+	bytes := request.Bytes()
+	require.Len(bytes, stateSyncRequestLen)
+
+	parsed, err := ParseStateSyncRequest(bytes)
+	require.NoError(err)
+	require.Equal(request, parsed)
+
+	_, err = ParseStateSyncRequest(bytes[1:])
+	require.ErrorIs(err, errInvalidStateSyncRequest)
+}
+
+func TestStateSyncHandlerAppRequest(t *testing.T) {
+	chunks := testChunkGetter{
+		{Height: 1, Index: 0}: {1, 2, 3},
+	}
+
+	tests := []struct {
+		name             string
+		requestBytes     []byte
+		expectedResponse []byte
+		expectedErr      *common.AppError
+	}{
+		{
+			name: "served chunk",
+			requestBytes: StateSyncRequest{
+				Height: 1,
+				Index:  0,
+			}.Bytes(),
+			expectedResponse: []byte{1, 2, 3},
+		},
+		{
+			name: "unknown chunk",
+			requestBytes: StateSyncRequest{
+				Height: 1,
+				Index:  1,
+			}.Bytes(),
+			expectedErr: p2p.ErrUnexpected,
+		},
+		{
+			name:         "invalid request",
+			requestBytes: []byte{1},
+			expectedErr:  p2p.ErrUnexpected,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			handler := NewStateSyncHandler(logging.NoLog{}, &sync.Mutex{}, chunks)
+			response, err := handler.AppRequest(
+				context.Background(),
+				ids.GenerateTestNodeID(),
+				time.Time{},
+				test.requestBytes,
+			)
+			require.Equal(test.expectedErr, err)
+			require.Equal(test.expectedResponse, response)
+		})
+	}
+}
@@ -4,6 +4,7 @@
 package platformvm
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -27,6 +28,7 @@ import (
 	"github.com/skychains/chain/utils/formatting"
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/components/keystore"
 	"github.com/skychains/chain/vms/platformvm/fx"
@@ -41,6 +43,7 @@ import (
 	avajson "github.com/skychains/chain/utils/json"
 	safemath "github.com/skychains/chain/utils/math"
 	platformapi "github.com/skychains/chain/vms/platformvm/api"
+	txexecutor "github.com/skychains/chain/vms/platformvm/txs/executor"
 )
 
 const (
@@ -53,6 +56,10 @@ const (
 	// Max number of items allowed in a page
 	maxPageSize = 1024
 
+	// Max number of heights that can be spanned by a single
+	// GetValidatorSetDiff call
+	maxGetValidatorSetDiffHeights = 1024
+
 	// Note: Staker attributes cache should be large enough so that no evictions
 	// happen when the API loops through all stakers.
 	stakerAttributesCacheSize = 100_000
@@ -63,6 +70,18 @@ var (
 	errPrimaryNetworkIsNotASubnet = errors.New("the primary network isn't a subnet")
 	errNoAddresses                = errors.New("no addresses provided")
 	errMissingBlockchainID        = errors.New("argument 'blockchainID' not given")
+	errInvalidHeightRange         = errors.New("invalid height range")
+	errHeightRangeTooLarge        = errors.New("height range too large")
+	errHeightBeforeValidatorDiffs = errors.New("height is before the stored validator diffs")
+	errNoStakeAmount              = errors.New("argument 'stakeAmount' must be > 0")
+	errNoStakeDuration            = errors.New("argument 'duration' must be > 0")
+	errStakeDurationTooLong       = errors.New("argument 'duration' exceeds the minting period")
+	errDelegationFeeTooLarge      = errors.New("argument 'delegationFee' exceeds the percent denominator")
+	errStakeTooSmall              = errors.New("stake is less than the minimum stake")
+	errStakeTooLarge              = errors.New("stake is greater than the maximum stake")
+	errDelegationPeriodMismatch   = errors.New("delegation must end before the validator")
+	errOverDelegated              = errors.New("validator would be over delegated")
+	errNodeIDAndDelegationFee     = errors.New("only one of 'nodeID' and 'delegationFee' can be given")
 )
 
 // Service defines the API calls that can be made to the platform chain
@@ -980,6 +999,288 @@ func (s *Service) GetCurrentSupply(r *http.Request, args *GetCurrentSupplyArgs,
 	return nil
 }
 
+// GetRewardConfigArgs are the arguments for calling GetRewardConfig
+type GetRewardConfigArgs struct {
+	// If omitted, defaults to the primary network
+	SubnetID ids.ID `json:"subnetID"`
+}
+
+// GetRewardConfigReply are the results from calling GetRewardConfig
+type GetRewardConfigReply struct {
+	// Consumption rates are given in units of [PercentDenominator]
+	MaxConsumptionRate avajson.Uint64 `json:"maxConsumptionRate"`
+	MinConsumptionRate avajson.Uint64 `json:"minConsumptionRate"`
+	// Minting period in seconds
+	MintingPeriod      avajson.Uint64 `json:"mintingPeriod"`
+	SupplyCap          avajson.Uint64 `json:"supplyCap"`
+	PercentDenominator avajson.Uint64 `json:"percentDenominator"`
+}
+
+// GetRewardConfig returns the parameters used to calculate staking rewards on
+// a subnet
+func (s *Service) GetRewardConfig(_ *http.Request, args *GetRewardConfigArgs, reply *GetRewardConfigReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getRewardConfig"),
+	)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	config, err := s.getRewardConfig(args.SubnetID)
+	if err != nil {
+		return err
+	}
+
+	reply.MaxConsumptionRate = avajson.Uint64(config.MaxConsumptionRate)
+	reply.MinConsumptionRate = avajson.Uint64(config.MinConsumptionRate)
+	reply.MintingPeriod = avajson.Uint64(config.MintingPeriod / time.Second)
+	reply.SupplyCap = avajson.Uint64(config.SupplyCap)
+	reply.PercentDenominator = reward.PercentDenominator
+	return nil
+}
+
+// EstimateRewardArgs are the arguments for calling EstimateReward
+type EstimateRewardArgs struct {
+	// If omitted, defaults to the primary network
+	SubnetID ids.ID `json:"subnetID"`
+	// Amount of stake, in nLUX
+	StakeAmount avajson.Uint64 `json:"stakeAmount"`
+	// Staking duration in seconds
+	Duration avajson.Uint64 `json:"duration"`
+	// If provided, the stake is delegated to this current validator and the
+	// reward is split using its delegation fee
+	NodeID *ids.NodeID `json:"nodeID,omitempty"`
+	// If provided, the stake is delegated to a validator charging this
+	// delegation fee, in units of [PercentDenominator]
+	DelegationFee *avajson.Uint32 `json:"delegationFee,omitempty"`
+}
+
+// EstimateRewardReply are the results from calling EstimateReward
+type EstimateRewardReply struct {
+	// Total reward minted for the stake
+	Reward avajson.Uint64 `json:"reward"`
+	// Portion of the reward paid to the validator
+	ValidatorReward avajson.Uint64 `json:"validatorReward"`
+	// Portion of the reward paid to the delegator
+	DelegatorReward avajson.Uint64 `json:"delegatorReward"`
+	// Supply the reward was calculated against
+	CurrentSupply avajson.Uint64 `json:"currentSupply"`
+}
+
+// EstimateReward returns the reward that would be paid for staking the
+// provided amount for the provided duration, given the current supply
+func (s *Service) EstimateReward(_ *http.Request, args *EstimateRewardArgs, reply *EstimateRewardReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "estimateReward"),
+	)
+
+	switch {
+	case args.StakeAmount == 0:
+		return errNoStakeAmount
+	case args.Duration == 0:
+		return errNoStakeDuration
+	case args.NodeID != nil && args.DelegationFee != nil:
+		return errNodeIDAndDelegationFee
+	case args.DelegationFee != nil && uint64(*args.DelegationFee) > reward.PercentDenominator:
+		return errDelegationFeeTooLarge
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	config, err := s.getRewardConfig(args.SubnetID)
+	if err != nil {
+		return err
+	}
+	limits, err := s.getStakeLimits(args.SubnetID)
+	if err != nil {
+		return err
+	}
+
+	// Compare in seconds to avoid overflowing [time.Duration].
+	mintingPeriod := uint64(config.MintingPeriod / time.Second)
+	if uint64(args.Duration) > mintingPeriod {
+		return fmt.Errorf("%w: %d > %d",
+			errStakeDurationTooLong,
+			args.Duration,
+			mintingPeriod,
+		)
+	}
+	duration := time.Duration(args.Duration) * time.Second
+
+	var (
+		stakeAmount = uint64(args.StakeAmount)
+		minStake    = limits.minValidatorStake
+	)
+	if args.NodeID != nil || args.DelegationFee != nil {
+		minStake = limits.minDelegatorStake
+	}
+	switch {
+	case stakeAmount < minStake:
+		return fmt.Errorf("%w: %d < %d", errStakeTooSmall, stakeAmount, minStake)
+	case stakeAmount > limits.maxValidatorStake:
+		return fmt.Errorf("%w: %d > %d", errStakeTooLarge, stakeAmount, limits.maxValidatorStake)
+	}
+
+	var shares uint32
+	if args.NodeID != nil {
+		validator, err := s.vm.state.GetCurrentValidator(args.SubnetID, *args.NodeID)
+		if err != nil {
+			return fmt.Errorf("failed fetching validator %s: %w", *args.NodeID, err)
+		}
+		if err := s.verifyDelegation(validator, limits, stakeAmount, duration); err != nil {
+			return err
+		}
+		attr, err := s.loadStakerTxAttributes(validator.TxID)
+		if err != nil {
+			return err
+		}
+		shares = attr.shares
+	}
+
+	currentSupply, err := s.vm.state.GetCurrentSupply(args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("fetching current supply failed: %w", err)
+	}
+
+	calculator := reward.NewCalculator(config)
+	potentialReward := calculator.Calculate(duration, stakeAmount, currentSupply)
+
+	reply.Reward = avajson.Uint64(potentialReward)
+	reply.CurrentSupply = avajson.Uint64(currentSupply)
+
+	switch {
+	case args.NodeID != nil:
+		validatorReward, delegatorReward := reward.Split(potentialReward, shares)
+		reply.ValidatorReward = avajson.Uint64(validatorReward)
+		reply.DelegatorReward = avajson.Uint64(delegatorReward)
+	case args.DelegationFee != nil:
+		validatorReward, delegatorReward := reward.Split(potentialReward, uint32(*args.DelegationFee))
+		reply.ValidatorReward = avajson.Uint64(validatorReward)
+		reply.DelegatorReward = avajson.Uint64(delegatorReward)
+	default:
+		reply.ValidatorReward = avajson.Uint64(potentialReward)
+	}
+	return nil
+}
+
+// verifyDelegation verifies that a delegation of [stakeAmount] to [validator],
+// starting now and lasting [duration], could be issued: the delegation must
+// end before the validator does and must not exceed the delegation cap.
+//
+// Invariant: the context lock must be held.
+func (s *Service) verifyDelegation(
+	validator *state.Staker,
+	limits stakeLimits,
+	stakeAmount uint64,
+	duration time.Duration,
+) error {
+	startTime := s.vm.state.GetTimestamp()
+	endTime := startTime.Add(duration)
+	if endTime.After(validator.EndTime) {
+		return fmt.Errorf("%w: delegation would end at %s but validator %s ends at %s",
+			errDelegationPeriodMismatch,
+			endTime,
+			validator.NodeID,
+			validator.EndTime,
+		)
+	}
+
+	maxWeight, err := safemath.Mul64(uint64(limits.maxValidatorWeightFactor), validator.Weight)
+	if err != nil {
+		maxWeight = math.MaxUint64
+	}
+	maxWeight = min(maxWeight, limits.maxValidatorStake)
+
+	currentWeight, err := txexecutor.GetMaxWeight(s.vm.state, validator, startTime, endTime)
+	if err != nil {
+		return fmt.Errorf("failed calculating the weight of validator %s: %w", validator.NodeID, err)
+	}
+	newWeight, err := safemath.Add64(currentWeight, stakeAmount)
+	if err != nil || newWeight > maxWeight {
+		return fmt.Errorf("%w: validator %s can accept at most %d more stake",
+			errOverDelegated,
+			validator.NodeID,
+			maxWeight-min(currentWeight, maxWeight),
+		)
+	}
+	return nil
+}
+
+// stakeLimits are the bounds on the stake of the validators and delegators of
+// a subnet.
+type stakeLimits struct {
+	minValidatorStake        uint64
+	maxValidatorStake        uint64
+	minDelegatorStake        uint64
+	maxValidatorWeightFactor byte
+}
+
+// getStakeLimits returns the bounds on the stake of the validators and
+// delegators of [subnetID].
+//
+// Invariant: the context lock must be held.
+func (s *Service) getStakeLimits(subnetID ids.ID) (stakeLimits, error) {
+	if subnetID == constants.PrimaryNetworkID {
+		return stakeLimits{
+			minValidatorStake:        s.vm.MinValidatorStake,
+			maxValidatorStake:        s.vm.MaxValidatorStake,
+			minDelegatorStake:        s.vm.MinDelegatorStake,
+			maxValidatorWeightFactor: txexecutor.MaxValidatorWeightFactor,
+		}, nil
+	}
+
+	transformSubnet, err := txexecutor.GetTransformSubnetTx(s.vm.state, subnetID)
+	if err != nil {
+		return stakeLimits{}, fmt.Errorf(
+			"failed fetching subnet transformation for %s: %w",
+			subnetID,
+			err,
+		)
+	}
+	return stakeLimits{
+		minValidatorStake:        transformSubnet.MinValidatorStake,
+		maxValidatorStake:        transformSubnet.MaxValidatorStake,
+		minDelegatorStake:        transformSubnet.MinDelegatorStake,
+		maxValidatorWeightFactor: transformSubnet.MaxValidatorWeightFactor,
+	}, nil
+}
+
+// getRewardConfig returns the config used to calculate staking rewards on
+// [subnetID].
+//
+// Invariant: the context lock must be held.
+func (s *Service) getRewardConfig(subnetID ids.ID) (reward.Config, error) {
+	if subnetID == constants.PrimaryNetworkID {
+		return s.vm.RewardConfig, nil
+	}
+
+	transformSubnetIntf, err := s.vm.state.GetSubnetTransformation(subnetID)
+	if err != nil {
+		return reward.Config{}, fmt.Errorf(
+			"failed fetching subnet transformation for %s: %w",
+			subnetID,
+			err,
+		)
+	}
+	transformSubnet, ok := transformSubnetIntf.Unsigned.(*txs.TransformSubnetTx)
+	if !ok {
+		return reward.Config{}, fmt.Errorf(
+			"unexpected subnet transformation tx type fetched %T",
+			transformSubnetIntf.Unsigned,
+		)
+	}
+
+	return reward.Config{
+		MaxConsumptionRate: transformSubnet.MaxConsumptionRate,
+		MinConsumptionRate: transformSubnet.MinConsumptionRate,
+		MintingPeriod:      s.vm.RewardConfig.MintingPeriod,
+		SupplyCap:          transformSubnet.MaximumSupply,
+	}, nil
+}
+
 // SampleValidatorsArgs are the arguments for calling SampleValidators
 type SampleValidatorsArgs struct {
 	// Number of validators in the sample
@@ -1661,6 +1962,49 @@ func (s *Service) GetTimestamp(_ *http.Request, _ *struct{}, reply *GetTimestamp
 	return nil
 }
 
+// GetFeeConfig returns the dynamic fee config of the chain.
+func (s *Service) GetFeeConfig(_ *http.Request, _ *struct{}, reply *gas.Config) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getFeeConfig"),
+	)
+
+	*reply = s.vm.DynamicFeeConfig
+	return nil
+}
+
+// GetFeeStateReply is the response from GetFeeState
+type GetFeeStateReply struct {
+	gas.State
+	// Price is the current price per unit of gas. It is 0 before the F
+	// upgrade is activated.
+	Price gas.Price `json:"price"`
+	// Time of the last accepted block
+	Time time.Time `json:"timestamp"`
+}
+
+// GetFeeState returns the current fee state of the chain.
+func (s *Service) GetFeeState(_ *http.Request, _ *struct{}, reply *GetFeeStateReply) error {
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getFeeState"),
+	)
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	reply.State = s.vm.state.GetFeeState()
+	reply.Time = s.vm.state.GetTimestamp()
+	if s.vm.UpgradeConfig.IsFActivated(reply.Time) {
+		reply.Price = gas.CalculatePrice(
+			s.vm.DynamicFeeConfig.MinPrice,
+			reply.State.Excess,
+			s.vm.DynamicFeeConfig.ExcessConversionConstant,
+		)
+	}
+	return nil
+}
+
 // GetValidatorsAtArgs is the response from GetValidatorsAt
 type GetValidatorsAtArgs struct {
 	Height   avajson.Uint64 `json:"height"`
@@ -1673,8 +2017,27 @@ type jsonGetValidatorOutput struct {
 }
 
 func (v *GetValidatorsAtReply) MarshalJSON() ([]byte, error) {
-	m := make(map[ids.NodeID]*jsonGetValidatorOutput, len(v.Validators))
-	for _, vdr := range v.Validators {
+	m, err := validatorsToJSON(v.Validators)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
+
+func (v *GetValidatorsAtReply) UnmarshalJSON(b []byte) error {
+	var m map[ids.NodeID]*jsonGetValidatorOutput
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	var err error
+	v.Validators, err = validatorsFromJSON(m)
+	return err
+}
+
+func validatorsToJSON(vdrs map[ids.NodeID]*validators.GetValidatorOutput) (map[ids.NodeID]*jsonGetValidatorOutput, error) {
+	m := make(map[ids.NodeID]*jsonGetValidatorOutput, len(vdrs))
+	for _, vdr := range vdrs {
 		vdrJSON := &jsonGetValidatorOutput{
 			Weight: avajson.Uint64(vdr.Weight),
 		}
@@ -1689,21 +2052,15 @@ func (v *GetValidatorsAtReply) MarshalJSON() ([]byte, error) {
 
 		m[vdr.NodeID] = vdrJSON
 	}
-	return json.Marshal(m)
+	return m, nil
 }
 
-func (v *GetValidatorsAtReply) UnmarshalJSON(b []byte) error {
-	var m map[ids.NodeID]*jsonGetValidatorOutput
-	if err := json.Unmarshal(b, &m); err != nil {
-		return err
-	}
-
+func validatorsFromJSON(m map[ids.NodeID]*jsonGetValidatorOutput) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
 	if m == nil {
-		v.Validators = nil
-		return nil
+		return nil, nil
 	}
 
-	v.Validators = make(map[ids.NodeID]*validators.GetValidatorOutput, len(m))
+	vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput, len(m))
 	for nodeID, vdrJSON := range m {
 		vdr := &validators.GetValidatorOutput{
 			NodeID: nodeID,
@@ -1713,17 +2070,17 @@ func (v *GetValidatorsAtReply) UnmarshalJSON(b []byte) error {
 		if vdrJSON.PublicKey != nil {
 			pkBytes, err := formatting.Decode(formatting.HexNC, *vdrJSON.PublicKey)
 			if err != nil {
-				return err
+				return nil, err
 			}
 			vdr.PublicKey, err = bls.PublicKeyFromCompressedBytes(pkBytes)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 
-		v.Validators[nodeID] = vdr
+		vdrs[nodeID] = vdr
 	}
-	return nil
+	return vdrs, nil
 }
 
 // GetValidatorsAtReply is the response from GetValidatorsAt
@@ -1754,6 +2111,224 @@ func (s *Service) GetValidatorsAt(r *http.Request, args *GetValidatorsAtArgs, re
 	return nil
 }
 
+// GetValidatorSetDiffArgs are the arguments for calling GetValidatorSetDiff
+type GetValidatorSetDiffArgs struct {
+	SubnetID   ids.ID         `json:"subnetID"`
+	FromHeight avajson.Uint64 `json:"fromHeight"`
+	ToHeight   avajson.Uint64 `json:"toHeight"`
+}
+
+// GetValidatorSetDiffReply is the response from GetValidatorSetDiff
+type GetValidatorSetDiffReply struct {
+	// Added contains the validators that are in the set at ToHeight but were
+	// not in the set at FromHeight.
+	Added map[ids.NodeID]*validators.GetValidatorOutput
+	// Removed contains the validators that were in the set at FromHeight but
+	// are not in the set at ToHeight.
+	Removed []ids.NodeID
+	// Modified contains the validators that are in both sets but whose weight
+	// or public key changed. The values are as of ToHeight.
+	Modified map[ids.NodeID]*validators.GetValidatorOutput
+}
+
+type jsonGetValidatorSetDiffReply struct {
+	Added    map[ids.NodeID]*jsonGetValidatorOutput `json:"added"`
+	Removed  []ids.NodeID                           `json:"removed"`
+	Modified map[ids.NodeID]*jsonGetValidatorOutput `json:"modified"`
+}
+
+func (v *GetValidatorSetDiffReply) MarshalJSON() ([]byte, error) {
+	added, err := validatorsToJSON(v.Added)
+	if err != nil {
+		return nil, err
+	}
+	modified, err := validatorsToJSON(v.Modified)
+	if err != nil {
+		return nil, err
+	}
+	removed := v.Removed
+	if removed == nil {
+		removed = []ids.NodeID{}
+	}
+	return json.Marshal(&jsonGetValidatorSetDiffReply{
+		Added:    added,
+		Removed:  removed,
+		Modified: modified,
+	})
+}
+
+func (v *GetValidatorSetDiffReply) UnmarshalJSON(b []byte) error {
+	var m jsonGetValidatorSetDiffReply
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	var err error
+	v.Added, err = validatorsFromJSON(m.Added)
+	if err != nil {
+		return err
+	}
+	v.Removed = m.Removed
+	v.Modified, err = validatorsFromJSON(m.Modified)
+	return err
+}
+
+// GetValidatorSetDiff returns the changes to the validator set of a provided
+// subnet between two heights.
+func (s *Service) GetValidatorSetDiff(r *http.Request, args *GetValidatorSetDiffArgs, reply *GetValidatorSetDiffReply) error {
+	var (
+		fromHeight = uint64(args.FromHeight)
+		toHeight   = uint64(args.ToHeight)
+	)
+	s.vm.ctx.Log.Debug("API called",
+		zap.String("service", "platform"),
+		zap.String("method", "getValidatorSetDiff"),
+		zap.Uint64("fromHeight", fromHeight),
+		zap.Uint64("toHeight", toHeight),
+		zap.Stringer("subnetID", args.SubnetID),
+	)
+
+	if fromHeight > toHeight {
+		return fmt.Errorf("%w: fromHeight (%d) > toHeight (%d)",
+			errInvalidHeightRange,
+			fromHeight,
+			toHeight,
+		)
+	}
+
+	if toHeight-fromHeight > maxGetValidatorSetDiffHeights {
+		return fmt.Errorf("%w: %d heights requested but at most %d are allowed",
+			errHeightRangeTooLarge,
+			toHeight-fromHeight,
+			maxGetValidatorSetDiffHeights,
+		)
+	}
+
+	s.vm.ctx.Lock.Lock()
+	defer s.vm.ctx.Lock.Unlock()
+
+	ctx := r.Context()
+	toValidators, err := s.vm.GetValidatorSet(ctx, toHeight, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get validator set at height %d: %w", toHeight, err)
+	}
+
+	// The changes happened in the heights (fromHeight, toHeight]. Because the
+	// state interface is implemented to be inclusive, we read the diffs in
+	// [fromHeight + 1, toHeight].
+	lastDiffHeight := fromHeight + 1
+	if startHeight := s.vm.state.GetValidatorDiffsStartHeight(); lastDiffHeight < startHeight {
+		return fmt.Errorf("%w: fromHeight (%d) < lowest available height (%d)",
+			errHeightBeforeValidatorDiffs,
+			fromHeight,
+			startHeight-1,
+		)
+	}
+	weightDiffs, err := s.vm.state.GetValidatorWeightDiffs(ctx, toHeight, lastDiffHeight, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get validator weight diffs: %w", err)
+	}
+	fromPublicKeys, err := s.vm.state.GetValidatorPublicKeyDiffs(ctx, toHeight, lastDiffHeight, args.SubnetID)
+	if err != nil {
+		return fmt.Errorf("failed to get validator public key diffs: %w", err)
+	}
+
+	if args.SubnetID != constants.PrimaryNetworkID {
+		// Subnet validators that did not register their own public key use the
+		// key they registered on the Primary Network.
+		primaryPublicKeys, err := s.vm.state.GetValidatorPublicKeyDiffs(ctx, toHeight, lastDiffHeight, constants.PrimaryNetworkID)
+		if err != nil {
+			return fmt.Errorf("failed to get primary network public key diffs: %w", err)
+		}
+		for nodeID, publicKey := range primaryPublicKeys {
+			if _, ok := fromPublicKeys[nodeID]; ok {
+				continue
+			}
+			if vdr, ok := s.vm.Validators.GetValidator(args.SubnetID, nodeID); ok && vdr.PublicKey != nil {
+				continue
+			}
+			fromPublicKeys[nodeID] = publicKey
+		}
+	}
+
+	*reply, err = diffValidatorSet(toValidators, weightDiffs, fromPublicKeys)
+	return err
+}
+
+// diffValidatorSet returns the changes that transformed the validator set at
+// the start of a height range into [to], the validator set at its end.
+//
+// [weightDiffs] contains the net weight changes of the validators over the
+// range. [fromPublicKeys] contains the uncompressed public keys, at the start
+// of the range, of the validators whose public keys may have changed.
+func diffValidatorSet(
+	to map[ids.NodeID]*validators.GetValidatorOutput,
+	weightDiffs map[ids.NodeID]*state.ValidatorWeightDiff,
+	fromPublicKeys map[ids.NodeID][]byte,
+) (GetValidatorSetDiffReply, error) {
+	diff := GetValidatorSetDiffReply{
+		Added:    make(map[ids.NodeID]*validators.GetValidatorOutput),
+		Removed:  []ids.NodeID{},
+		Modified: make(map[ids.NodeID]*validators.GetValidatorOutput),
+	}
+
+	changed := set.NewSet[ids.NodeID](len(weightDiffs) + len(fromPublicKeys))
+	for nodeID := range weightDiffs {
+		changed.Add(nodeID)
+	}
+	for nodeID := range fromPublicKeys {
+		changed.Add(nodeID)
+	}
+
+	for nodeID := range changed {
+		var toWeight uint64
+		toVdr, ok := to[nodeID]
+		if ok {
+			toWeight = toVdr.Weight
+		}
+
+		fromWeight := toWeight
+		if weightDiff, ok := weightDiffs[nodeID]; ok {
+			// Undo the net change to find the weight at the start of the
+			// range.
+			var err error
+			if weightDiff.Decrease {
+				fromWeight, err = safemath.Add64(toWeight, weightDiff.Amount)
+			} else {
+				fromWeight, err = safemath.Sub(toWeight, weightDiff.Amount)
+			}
+			if err != nil {
+				return GetValidatorSetDiffReply{}, fmt.Errorf("invalid weight diff for %s: %w", nodeID, err)
+			}
+		}
+
+		switch {
+		case fromWeight == 0 && toWeight == 0:
+			// The validator was added and removed within the range.
+		case fromWeight == 0:
+			diff.Added[nodeID] = toVdr
+		case toWeight == 0:
+			diff.Removed = append(diff.Removed, nodeID)
+		case fromWeight != toWeight:
+			diff.Modified[nodeID] = toVdr
+		default:
+			fromPublicKey, ok := fromPublicKeys[nodeID]
+			if ok && !bytes.Equal(fromPublicKey, publicKeyToBytes(toVdr.PublicKey)) {
+				diff.Modified[nodeID] = toVdr
+			}
+		}
+	}
+	utils.Sort(diff.Removed)
+	return diff, nil
+}
+
+func publicKeyToBytes(pk *bls.PublicKey) []byte {
+	if pk == nil {
+		return nil
+	}
+	return bls.PublicKeyToUncompressedBytes(pk)
+}
+
 func (s *Service) GetBlock(_ *http.Request, args *api.GetBlockArgs, response *api.GetBlockResponse) error {
 	s.vm.ctx.Log.Debug("API called",
 		zap.String("service", "platform"),
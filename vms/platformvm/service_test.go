@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"net/http"
 	"testing"
 	"time"
 
@@ -32,8 +33,10 @@ import (
 	"github.com/skychains/chain/utils/crypto/secp256k1"
 	"github.com/skychains/chain/utils/formatting"
 	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/block"
+	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/signer"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/status"
@@ -44,6 +47,7 @@ import (
 	"github.com/skychains/chain/wallet/subnet/primary/common"
 
 	avajson "github.com/skychains/chain/utils/json"
+	safemath "github.com/skychains/chain/utils/math"
 	vmkeystore "github.com/skychains/chain/vms/components/keystore"
 	pchainapi "github.com/skychains/chain/vms/platformvm/api"
 	blockbuilder "github.com/skychains/chain/vms/platformvm/block/builder"
@@ -376,10 +380,9 @@ func TestGetBalance(t *testing.T) {
 	require := require.New(t)
 	service, _, _ := defaultService(t)
 
-	var (
-		feeCalc         = fee.NewStaticCalculator(service.vm.Config.StaticFeeConfig, service.vm.Config.UpgradeConfig)
-		createSubnetFee = feeCalc.CalculateFee(&txs.CreateSubnetTx{}, service.vm.clock.Time())
-	)
+	feeCalc := fee.NewStaticCalculator(service.vm.Config.StaticFeeConfig, service.vm.Config.UpgradeConfig, service.vm.clock.Time())
+	createSubnetFee, err := feeCalc.CalculateFee(&txs.CreateSubnetTx{})
+	require.NoError(err)
 
 	// Ensure GetStake is correct for each of the genesis validators
 	genesis, _ := defaultGenesis(t, service.vm.ctx.LUXAssetID)
@@ -749,6 +752,293 @@ func TestGetTimestamp(t *testing.T) {
 	require.Equal(newTimestamp, reply.Timestamp)
 }
 
+func TestGetFeeConfig(t *testing.T) {
+	require := require.New(t)
+	service, _, _ := defaultService(t)
+
+	var reply gas.Config
+	require.NoError(service.GetFeeConfig(nil, nil, &reply))
+	require.Equal(defaultDynamicFeeConfig, reply)
+}
+
+func TestGetFeeState(t *testing.T) {
+	require := require.New(t)
+	service, _, _ := defaultService(t)
+
+	// The price is zero before the F upgrade is activated.
+	var reply GetFeeStateReply
+	require.NoError(service.GetFeeState(nil, nil, &reply))
+
+	service.vm.ctx.Lock.Lock()
+	require.Equal(service.vm.state.GetFeeState(), reply.State)
+	require.Equal(service.vm.state.GetTimestamp(), reply.Time)
+	require.Zero(reply.Price)
+
+	feeState := gas.State{
+		Capacity: 1_000,
+		Excess:   2 * defaultDynamicFeeConfig.ExcessConversionConstant,
+	}
+	service.vm.state.SetFeeState(feeState)
+	service.vm.UpgradeConfig.FUpgradeTime = service.vm.state.GetTimestamp()
+	service.vm.ctx.Lock.Unlock()
+
+	require.NoError(service.GetFeeState(nil, nil, &reply))
+	require.Equal(feeState, reply.State)
+	require.Equal(gas.Price(7), reply.Price) // e^2 ~= 7.39
+}
+
+func TestGetRewardConfig(t *testing.T) {
+	require := require.New(t)
+	service, _, _ := defaultService(t)
+
+	args := GetRewardConfigArgs{
+		SubnetID: constants.PrimaryNetworkID,
+	}
+	reply := GetRewardConfigReply{}
+	require.NoError(service.GetRewardConfig(nil, &args, &reply))
+	require.Equal(GetRewardConfigReply{
+		MaxConsumptionRate: avajson.Uint64(defaultRewardConfig.MaxConsumptionRate),
+		MinConsumptionRate: avajson.Uint64(defaultRewardConfig.MinConsumptionRate),
+		MintingPeriod:      avajson.Uint64(defaultRewardConfig.MintingPeriod / time.Second),
+		SupplyCap:          avajson.Uint64(defaultRewardConfig.SupplyCap),
+		PercentDenominator: reward.PercentDenominator,
+	}, reply)
+
+	args.SubnetID = ids.GenerateTestID()
+	err := service.GetRewardConfig(nil, &args, &reply)
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func TestEstimateReward(t *testing.T) {
+	service, _, factory := defaultService(t)
+
+	var (
+		delegationFee        = avajson.Uint32(reward.PercentDenominator / 50)
+		invalidDelegationFee = avajson.Uint32(reward.PercentDenominator + 1)
+
+		validatorNodeID   = ids.GenerateTestNodeID()
+		validatorDuration = 2 * defaultMinStakingDuration
+		genesisNodeID     = genesisNodeIDs[0]
+		unknownNodeID     = ids.GenerateTestNodeID()
+	)
+
+	service.vm.ctx.Lock.Lock()
+	currentSupply, err := service.vm.state.GetCurrentSupply(constants.PrimaryNetworkID)
+	require.NoError(t, err)
+
+	validatorStartTime := service.vm.state.GetTimestamp()
+	builder, signer := factory.NewWallet(keys[0])
+	utx, err := builder.NewAddValidatorTx(
+		&txs.Validator{
+			NodeID: validatorNodeID,
+			Start:  uint64(validatorStartTime.Unix()),
+			End:    uint64(validatorStartTime.Add(validatorDuration).Unix()),
+			Wght:   defaultMinValidatorStake,
+		},
+		&secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+		},
+		uint32(delegationFee),
+	)
+	require.NoError(t, err)
+	tx, err := walletsigner.SignUnsigned(context.Background(), signer, utx)
+	require.NoError(t, err)
+	staker, err := state.NewCurrentStaker(tx.ID(), utx, validatorStartTime, 0)
+	require.NoError(t, err)
+	service.vm.state.PutCurrentValidator(staker)
+	service.vm.state.AddTx(tx, status.Committed)
+	require.NoError(t, service.vm.state.Commit())
+	service.vm.ctx.Lock.Unlock()
+
+	var (
+		stakeAmount     = avajson.Uint64(defaultMinValidatorStake)
+		duration        = avajson.Uint64(defaultMaxStakingDuration / time.Second)
+		calculator      = reward.NewCalculator(defaultRewardConfig)
+		potentialReward = calculator.Calculate(defaultMaxStakingDuration, defaultMinValidatorStake, currentSupply)
+
+		delegationDuration           = avajson.Uint64(defaultMinStakingDuration / time.Second)
+		delegationReward             = calculator.Calculate(defaultMinStakingDuration, defaultMinValidatorStake, currentSupply)
+		delegationValidatorReward, _ = reward.Split(delegationReward, uint32(delegationFee))
+
+		feeValidatorReward, _ = reward.Split(potentialReward, uint32(delegationFee))
+	)
+
+	tests := []struct {
+		name          string
+		args          EstimateRewardArgs
+		expectedReply EstimateRewardReply
+		expectedErr   error
+	}{
+		{
+			name: "validator",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+				Duration:    duration,
+			},
+			expectedReply: EstimateRewardReply{
+				Reward:          avajson.Uint64(potentialReward),
+				ValidatorReward: avajson.Uint64(potentialReward),
+				CurrentSupply:   avajson.Uint64(currentSupply),
+			},
+		},
+		{
+			name: "delegation fee",
+			args: EstimateRewardArgs{
+				StakeAmount:   stakeAmount,
+				Duration:      duration,
+				DelegationFee: &delegationFee,
+			},
+			expectedReply: EstimateRewardReply{
+				Reward:          avajson.Uint64(potentialReward),
+				ValidatorReward: avajson.Uint64(feeValidatorReward),
+				DelegatorReward: avajson.Uint64(potentialReward - feeValidatorReward),
+				CurrentSupply:   avajson.Uint64(currentSupply),
+			},
+		},
+		{
+			name: "delegation to current validator",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+				Duration:    delegationDuration,
+				NodeID:      &validatorNodeID,
+			},
+			expectedReply: EstimateRewardReply{
+				Reward:          avajson.Uint64(delegationReward),
+				ValidatorReward: avajson.Uint64(delegationValidatorReward),
+				DelegatorReward: avajson.Uint64(delegationReward - delegationValidatorReward),
+				CurrentSupply:   avajson.Uint64(currentSupply),
+			},
+		},
+		{
+			name: "delegation ends after validator",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+				Duration:    avajson.Uint64((validatorDuration + time.Second) / time.Second),
+				NodeID:      &validatorNodeID,
+			},
+			expectedErr: errDelegationPeriodMismatch,
+		},
+		{
+			// The validator accepts at most [MaxValidatorWeightFactor] times
+			// its own stake, including its own stake.
+			name: "delegation exceeds delegation cap",
+			args: EstimateRewardArgs{
+				StakeAmount: avajson.Uint64((txexecutor.MaxValidatorWeightFactor-1)*defaultMinValidatorStake + 1),
+				Duration:    delegationDuration,
+				NodeID:      &validatorNodeID,
+			},
+			expectedErr: errOverDelegated,
+		},
+		{
+			name: "delegation to genesis validator exceeds delegation cap",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+				Duration:    delegationDuration,
+				NodeID:      &genesisNodeID,
+			},
+			expectedErr: errOverDelegated,
+		},
+		{
+			name: "delegation to unknown validator",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+				Duration:    duration,
+				NodeID:      &unknownNodeID,
+			},
+			expectedErr: database.ErrNotFound,
+		},
+		{
+			name: "validator stake too small",
+			args: EstimateRewardArgs{
+				StakeAmount: avajson.Uint64(defaultMinValidatorStake - 1),
+				Duration:    duration,
+			},
+			expectedErr: errStakeTooSmall,
+		},
+		{
+			name: "validator stake too large",
+			args: EstimateRewardArgs{
+				StakeAmount: avajson.Uint64(defaultMaxValidatorStake + 1),
+				Duration:    duration,
+			},
+			expectedErr: errStakeTooLarge,
+		},
+		{
+			name: "delegator stake too small",
+			args: EstimateRewardArgs{
+				StakeAmount:   avajson.Uint64(defaultMinDelegatorStake - 1),
+				Duration:      duration,
+				DelegationFee: &delegationFee,
+			},
+			expectedErr: errStakeTooSmall,
+		},
+		{
+			name: "no stake amount",
+			args: EstimateRewardArgs{
+				Duration: duration,
+			},
+			expectedErr: errNoStakeAmount,
+		},
+		{
+			name: "no duration",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+			},
+			expectedErr: errNoStakeDuration,
+		},
+		{
+			name: "duration longer than minting period",
+			args: EstimateRewardArgs{
+				StakeAmount: stakeAmount,
+				Duration:    avajson.Uint64(defaultRewardConfig.MintingPeriod/time.Second) + 1,
+			},
+			expectedErr: errStakeDurationTooLong,
+		},
+		{
+			name: "delegation fee too large",
+			args: EstimateRewardArgs{
+				StakeAmount:   stakeAmount,
+				Duration:      duration,
+				DelegationFee: &invalidDelegationFee,
+			},
+			expectedErr: errDelegationFeeTooLarge,
+		},
+		{
+			name: "node ID and delegation fee",
+			args: EstimateRewardArgs{
+				StakeAmount:   stakeAmount,
+				Duration:      duration,
+				NodeID:        &genesisNodeID,
+				DelegationFee: &delegationFee,
+			},
+			expectedErr: errNodeIDAndDelegationFee,
+		},
+		{
+			name: "subnet without rewards",
+			args: EstimateRewardArgs{
+				SubnetID:    ids.GenerateTestID(),
+				StakeAmount: stakeAmount,
+				Duration:    duration,
+			},
+			expectedErr: database.ErrNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			reply := EstimateRewardReply{}
+			err := service.EstimateReward(nil, &test.args, &reply)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+			require.Equal(test.expectedReply, reply)
+		})
+	}
+}
+
 func TestGetBlock(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -866,6 +1156,201 @@ func TestGetValidatorsAtReplyMarshalling(t *testing.T) {
 	require.Equal(reply, &parsedReply)
 }
 
+func TestGetValidatorSetDiffReplyMarshalling(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		addedNodeID    = ids.GenerateTestNodeID()
+		modifiedNodeID = ids.GenerateTestNodeID()
+		reply          = &GetValidatorSetDiffReply{
+			Added: map[ids.NodeID]*validators.GetValidatorOutput{
+				addedNodeID: {
+					NodeID:    addedNodeID,
+					PublicKey: bls.PublicFromSecretKey(sk),
+					Weight:    math.MaxUint64,
+				},
+			},
+			Removed: []ids.NodeID{
+				ids.GenerateTestNodeID(),
+			},
+			Modified: map[ids.NodeID]*validators.GetValidatorOutput{
+				modifiedNodeID: {
+					NodeID: modifiedNodeID,
+					Weight: 1,
+				},
+			},
+		}
+	)
+
+	replyJSON, err := reply.MarshalJSON()
+	require.NoError(err)
+
+	var parsedReply GetValidatorSetDiffReply
+	require.NoError(parsedReply.UnmarshalJSON(replyJSON))
+	require.Equal(reply, &parsedReply)
+}
+
+func TestGetValidatorSetDiff(t *testing.T) {
+	require := require.New(t)
+	service, _, _ := defaultService(t)
+
+	args := GetValidatorSetDiffArgs{
+		SubnetID:   constants.PrimaryNetworkID,
+		FromHeight: 1,
+		ToHeight:   0,
+	}
+	reply := GetValidatorSetDiffReply{}
+	err := service.GetValidatorSetDiff(&http.Request{}, &args, &reply)
+	require.ErrorIs(err, errInvalidHeightRange)
+
+	args.ToHeight = maxGetValidatorSetDiffHeights + 2
+	err = service.GetValidatorSetDiff(&http.Request{}, &args, &reply)
+	require.ErrorIs(err, errHeightRangeTooLarge)
+
+	args.FromHeight = 0
+	args.ToHeight = 0
+	require.NoError(service.GetValidatorSetDiff(&http.Request{}, &args, &reply))
+	require.Empty(reply.Added)
+	require.Empty(reply.Removed)
+	require.Empty(reply.Modified)
+}
+
+func TestDiffValidatorSet(t *testing.T) {
+	sk0, err := bls.NewSecretKey()
+	require.NoError(t, err)
+	sk1, err := bls.NewSecretKey()
+	require.NoError(t, err)
+
+	var (
+		pk0 = bls.PublicFromSecretKey(sk0)
+		pk1 = bls.PublicFromSecretKey(sk1)
+
+		pk0Bytes = bls.PublicKeyToUncompressedBytes(pk0)
+
+		nodeID0 = ids.BuildTestNodeID([]byte{0})
+		nodeID1 = ids.BuildTestNodeID([]byte{1})
+		nodeID2 = ids.BuildTestNodeID([]byte{2})
+	)
+
+	tests := []struct {
+		name           string
+		to             map[ids.NodeID]*validators.GetValidatorOutput
+		weightDiffs    map[ids.NodeID]*state.ValidatorWeightDiff
+		fromPublicKeys map[ids.NodeID][]byte
+		expected       GetValidatorSetDiffReply
+		expectedErr    error
+	}{
+		{
+			name: "unchanged",
+			to: map[ids.NodeID]*validators.GetValidatorOutput{
+				nodeID0: {NodeID: nodeID0, PublicKey: pk0, Weight: 1},
+			},
+			weightDiffs: map[ids.NodeID]*state.ValidatorWeightDiff{
+				nodeID0: {Decrease: false, Amount: 0},
+			},
+			fromPublicKeys: map[ids.NodeID][]byte{
+				nodeID0: pk0Bytes,
+			},
+			expected: GetValidatorSetDiffReply{
+				Added:    map[ids.NodeID]*validators.GetValidatorOutput{},
+				Removed:  []ids.NodeID{},
+				Modified: map[ids.NodeID]*validators.GetValidatorOutput{},
+			},
+		},
+		{
+			name: "added and removed",
+			to: map[ids.NodeID]*validators.GetValidatorOutput{
+				nodeID1: {NodeID: nodeID1, PublicKey: pk1, Weight: 2},
+			},
+			weightDiffs: map[ids.NodeID]*state.ValidatorWeightDiff{
+				nodeID0: {Decrease: true, Amount: 1},
+				nodeID1: {Decrease: false, Amount: 2},
+				nodeID2: {Decrease: true, Amount: 1},
+			},
+			expected: GetValidatorSetDiffReply{
+				Added: map[ids.NodeID]*validators.GetValidatorOutput{
+					nodeID1: {NodeID: nodeID1, PublicKey: pk1, Weight: 2},
+				},
+				Removed:  []ids.NodeID{nodeID0, nodeID2},
+				Modified: map[ids.NodeID]*validators.GetValidatorOutput{},
+			},
+		},
+		{
+			name: "added and removed within the range",
+			to:   map[ids.NodeID]*validators.GetValidatorOutput{},
+			weightDiffs: map[ids.NodeID]*state.ValidatorWeightDiff{
+				nodeID0: {Decrease: false, Amount: 0},
+			},
+			fromPublicKeys: map[ids.NodeID][]byte{
+				nodeID0: nil,
+			},
+			expected: GetValidatorSetDiffReply{
+				Added:    map[ids.NodeID]*validators.GetValidatorOutput{},
+				Removed:  []ids.NodeID{},
+				Modified: map[ids.NodeID]*validators.GetValidatorOutput{},
+			},
+		},
+		{
+			name: "reweighted",
+			to: map[ids.NodeID]*validators.GetValidatorOutput{
+				nodeID0: {NodeID: nodeID0, PublicKey: pk0, Weight: 2},
+			},
+			weightDiffs: map[ids.NodeID]*state.ValidatorWeightDiff{
+				nodeID0: {Decrease: false, Amount: 1},
+			},
+			expected: GetValidatorSetDiffReply{
+				Added:   map[ids.NodeID]*validators.GetValidatorOutput{},
+				Removed: []ids.NodeID{},
+				Modified: map[ids.NodeID]*validators.GetValidatorOutput{
+					nodeID0: {NodeID: nodeID0, PublicKey: pk0, Weight: 2},
+				},
+			},
+		},
+		{
+			name: "public key changed",
+			to: map[ids.NodeID]*validators.GetValidatorOutput{
+				nodeID0: {NodeID: nodeID0, PublicKey: pk1, Weight: 1},
+				nodeID1: {NodeID: nodeID1, PublicKey: pk1, Weight: 1},
+			},
+			fromPublicKeys: map[ids.NodeID][]byte{
+				nodeID0: pk0Bytes,
+				nodeID1: nil,
+			},
+			expected: GetValidatorSetDiffReply{
+				Added:   map[ids.NodeID]*validators.GetValidatorOutput{},
+				Removed: []ids.NodeID{},
+				Modified: map[ids.NodeID]*validators.GetValidatorOutput{
+					nodeID0: {NodeID: nodeID0, PublicKey: pk1, Weight: 1},
+					nodeID1: {NodeID: nodeID1, PublicKey: pk1, Weight: 1},
+				},
+			},
+		},
+		{
+			name: "invalid weight diff",
+			to:   map[ids.NodeID]*validators.GetValidatorOutput{},
+			weightDiffs: map[ids.NodeID]*state.ValidatorWeightDiff{
+				nodeID0: {Decrease: false, Amount: 1},
+			},
+			expectedErr: safemath.ErrUnderflow,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			diff, err := diffValidatorSet(test.to, test.weightDiffs, test.fromPublicKeys)
+			require.ErrorIs(err, test.expectedErr)
+			if test.expectedErr != nil {
+				return
+			}
+			require.Equal(test.expected, diff)
+		})
+	}
+}
+
 func TestServiceGetBlockByHeight(t *testing.T) {
 	ctrl := gomock.NewController(t)
 
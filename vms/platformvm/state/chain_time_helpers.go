@@ -9,6 +9,9 @@ import (
 
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/utils/timer/mockable"
+	"github.com/skychains/chain/vms/components/gas"
+	"github.com/skychains/chain/vms/platformvm/config"
+	"github.com/skychains/chain/vms/platformvm/txs/fee"
 )
 
 func NextBlockTime(state Chain, clk *mockable.Clock) (time.Time, bool, error) {
@@ -68,3 +71,27 @@ func GetNextStakerChangeTime(state Chain) (time.Time, error) {
 		return time.Time{}, database.ErrNotFound
 	}
 }
+
+// PickFeeCalculator returns the fee calculator that applies to txs included
+// on top of [state].
+func PickFeeCalculator(cfg *config.Config, state Chain) fee.Calculator {
+	timestamp := state.GetTimestamp()
+	if !cfg.UpgradeConfig.IsFActivated(timestamp) {
+		return fee.NewStaticCalculator(
+			cfg.StaticFeeConfig,
+			cfg.UpgradeConfig,
+			timestamp,
+		)
+	}
+
+	feeState := state.GetFeeState()
+	gasPrice := gas.CalculatePrice(
+		cfg.DynamicFeeConfig.MinPrice,
+		feeState.Excess,
+		cfg.DynamicFeeConfig.ExcessConversionConstant,
+	)
+	return fee.NewDynamicCalculator(
+		cfg.DynamicFeeConfig.Weights,
+		gasPrice,
+	)
+}
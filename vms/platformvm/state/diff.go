@@ -10,6 +10,7 @@ import (
 
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/fx"
 	"github.com/skychains/chain/vms/platformvm/status"
@@ -33,7 +34,9 @@ type diff struct {
 	parentID      ids.ID
 	stateVersions Versions
 
-	timestamp time.Time
+	timestamp   time.Time
+	feeState    gas.State
+	accruedFees uint64
 
 	// Subnet ID --> supply of native asset of the subnet
 	currentSupply map[ids.ID]uint64
@@ -46,6 +49,11 @@ type diff struct {
 	addedSubnetIDs []ids.ID
 	// Subnet ID --> Owner of the subnet
 	subnetOwners map[ids.ID]fx.Owner
+	// Subnet ID --> Manager of the subnet
+	subnetManagers map[ids.ID]subnetManager
+	sovDiff        *subnetOnlyValidatorsDiff
+	// Expiry entry --> whether the entry was added or removed
+	modifiedExpiries map[ExpiryEntry]bool
 	// Subnet ID --> Tx that transforms the subnet
 	transformedSubnets map[ids.ID]*txs.Tx
 
@@ -68,10 +76,16 @@ func NewDiff(
 		return nil, fmt.Errorf("%w: %s", ErrMissingParentState, parentID)
 	}
 	return &diff{
-		parentID:      parentID,
-		stateVersions: stateVersions,
-		timestamp:     parentState.GetTimestamp(),
-		subnetOwners:  make(map[ids.ID]fx.Owner),
+		parentID:       parentID,
+		stateVersions:  stateVersions,
+		timestamp:      parentState.GetTimestamp(),
+		feeState:       parentState.GetFeeState(),
+		accruedFees:    parentState.GetAccruedFees(),
+		subnetOwners:   make(map[ids.ID]fx.Owner),
+		subnetManagers: make(map[ids.ID]subnetManager),
+		sovDiff:        newSubnetOnlyValidatorsDiff(),
+
+		modifiedExpiries: make(map[ExpiryEntry]bool),
 	}, nil
 }
 
@@ -97,6 +111,121 @@ func (d *diff) SetTimestamp(timestamp time.Time) {
 	d.timestamp = timestamp
 }
 
+func (d *diff) GetFeeState() gas.State {
+	return d.feeState
+}
+
+func (d *diff) SetFeeState(feeState gas.State) {
+	d.feeState = feeState
+}
+
+func (d *diff) GetAccruedFees() uint64 {
+	return d.accruedFees
+}
+
+func (d *diff) SetAccruedFees(accruedFees uint64) {
+	d.accruedFees = accruedFees
+}
+
+func (d *diff) GetActiveSubnetOnlyValidators() ([]SubnetOnlyValidator, error) {
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+
+	parentActive, err := parentState.GetActiveSubnetOnlyValidators()
+	if err != nil {
+		return nil, err
+	}
+	return d.sovDiff.getActiveSubnetOnlyValidators(parentActive), nil
+}
+
+func (d *diff) NumActiveSubnetOnlyValidators() int {
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return 0
+	}
+	return parentState.NumActiveSubnetOnlyValidators() + d.sovDiff.numAddedActive
+}
+
+func (d *diff) GetSubnetOnlyValidator(validationID ids.ID) (SubnetOnlyValidator, error) {
+	if sov, modified, err := d.sovDiff.getSubnetOnlyValidator(validationID); modified {
+		return sov, err
+	}
+
+	// If the validator was not modified in this diff, ask the parent state.
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return SubnetOnlyValidator{}, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+	return parentState.GetSubnetOnlyValidator(validationID)
+}
+
+func (d *diff) GetSubnetOnlyValidators(subnetID ids.ID) ([]SubnetOnlyValidator, error) {
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+
+	parentValidators, err := parentState.GetSubnetOnlyValidators(subnetID)
+	if err != nil {
+		return nil, err
+	}
+	return d.sovDiff.getSubnetOnlyValidators(parentValidators, subnetID), nil
+}
+
+func (d *diff) HasSubnetOnlyValidator(subnetID ids.ID, nodeID ids.NodeID) (bool, error) {
+	if has, modified := d.sovDiff.hasSubnetOnlyValidator(subnetID, nodeID); modified {
+		return has, nil
+	}
+
+	// If the subnetID + nodeID pair was not modified in this diff, ask the
+	// parent state.
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+	return parentState.HasSubnetOnlyValidator(subnetID, nodeID)
+}
+
+func (d *diff) PutSubnetOnlyValidator(sov SubnetOnlyValidator) error {
+	return d.sovDiff.putSubnetOnlyValidator(d, sov)
+}
+
+func (d *diff) GetExpiries() ([]ExpiryEntry, error) {
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+
+	parentExpiries, err := parentState.GetExpiries()
+	if err != nil {
+		return nil, err
+	}
+	return mergeExpiries(parentExpiries, d.modifiedExpiries), nil
+}
+
+func (d *diff) HasExpiry(entry ExpiryEntry) (bool, error) {
+	if added, modified := d.modifiedExpiries[entry]; modified {
+		return added, nil
+	}
+
+	// If the entry was not modified in this diff, ask the parent state.
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return false, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+	return parentState.HasExpiry(entry)
+}
+
+func (d *diff) PutExpiry(entry ExpiryEntry) {
+	d.modifiedExpiries[entry] = true
+}
+
+func (d *diff) DeleteExpiry(entry ExpiryEntry) {
+	d.modifiedExpiries[entry] = false
+}
+
 func (d *diff) GetCurrentSupply(subnetID ids.ID) (uint64, error) {
 	supply, ok := d.currentSupply[subnetID]
 	if ok {
@@ -209,6 +338,19 @@ func (d *diff) GetCurrentStakerIterator() (StakerIterator, error) {
 	return d.currentStakerDiffs.GetStakerIterator(parentIterator), nil
 }
 
+func (d *diff) GetCurrentValidators(subnetID ids.ID) ([]*Staker, error) {
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParentState, d.parentID)
+	}
+
+	parentValidators, err := parentState.GetCurrentValidators(subnetID)
+	if err != nil {
+		return nil, err
+	}
+	return d.currentStakerDiffs.GetValidators(parentValidators, subnetID), nil
+}
+
 func (d *diff) GetPendingValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker, error) {
 	// If the validator was modified in this diff, return the modified
 	// validator.
@@ -294,6 +436,27 @@ func (d *diff) SetSubnetOwner(subnetID ids.ID, owner fx.Owner) {
 	d.subnetOwners[subnetID] = owner
 }
 
+func (d *diff) GetSubnetManager(subnetID ids.ID) (ids.ID, []byte, error) {
+	if manager, exists := d.subnetManagers[subnetID]; exists {
+		return manager.ChainID, manager.Addr, nil
+	}
+
+	// If the subnet manager was not assigned in this diff, ask the parent
+	// state.
+	parentState, ok := d.stateVersions.GetState(d.parentID)
+	if !ok {
+		return ids.Empty, nil, ErrMissingParentState
+	}
+	return parentState.GetSubnetManager(subnetID)
+}
+
+func (d *diff) SetSubnetManager(subnetID ids.ID, chainID ids.ID, addr []byte) {
+	d.subnetManagers[subnetID] = subnetManager{
+		ChainID: chainID,
+		Addr:    addr,
+	}
+}
+
 func (d *diff) GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error) {
 	tx, exists := d.transformedSubnets[subnetID]
 	if exists {
@@ -401,6 +564,8 @@ func (d *diff) DeleteUTXO(utxoID ids.ID) {
 
 func (d *diff) Apply(baseState Chain) error {
 	baseState.SetTimestamp(d.timestamp)
+	baseState.SetFeeState(d.feeState)
+	baseState.SetAccruedFees(d.accruedFees)
 	for subnetID, supply := range d.currentSupply {
 		baseState.SetCurrentSupply(subnetID, supply)
 	}
@@ -480,5 +645,33 @@ func (d *diff) Apply(baseState Chain) error {
 	for subnetID, owner := range d.subnetOwners {
 		baseState.SetSubnetOwner(subnetID, owner)
 	}
+	for subnetID, manager := range d.subnetManagers {
+		baseState.SetSubnetManager(subnetID, manager.ChainID, manager.Addr)
+	}
+	for entry, added := range d.modifiedExpiries {
+		if added {
+			baseState.PutExpiry(entry)
+		} else {
+			baseState.DeleteExpiry(entry)
+		}
+	}
+	// Validators that are being removed must be applied first so that their
+	// subnetID + nodeID pairs can be re-used.
+	for _, sov := range d.sovDiff.modified {
+		if sov.Weight != 0 {
+			continue
+		}
+		if err := baseState.PutSubnetOnlyValidator(sov); err != nil {
+			return err
+		}
+	}
+	for _, sov := range d.sovDiff.modified {
+		if sov.Weight == 0 {
+			continue
+		}
+		if err := baseState.PutSubnetOnlyValidator(sov); err != nil {
+			return err
+		}
+	}
 	return nil
 }
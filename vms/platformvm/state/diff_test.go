@@ -14,6 +14,7 @@ import (
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils"
 	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/fx"
 	"github.com/skychains/chain/vms/platformvm/status"
@@ -73,6 +74,31 @@ func TestDiffCurrentSupply(t *testing.T) {
 	require.Equal(initialCurrentSupply, returnedBaseCurrentSupply)
 }
 
+func TestDiffFeeState(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	lastAcceptedID := ids.GenerateTestID()
+	state := newInitializedState(require)
+	versions := NewMockVersions(ctrl)
+	versions.EXPECT().GetState(lastAcceptedID).AnyTimes().Return(state, true)
+
+	d, err := NewDiff(lastAcceptedID, versions)
+	require.NoError(err)
+
+	initialFeeState := state.GetFeeState()
+	newFeeState := gas.State{
+		Capacity: initialFeeState.Capacity + 1,
+		Excess:   initialFeeState.Excess + 1,
+	}
+	d.SetFeeState(newFeeState)
+	require.Equal(newFeeState, d.GetFeeState())
+	require.Equal(initialFeeState, state.GetFeeState())
+
+	require.NoError(d.Apply(state))
+	require.Equal(newFeeState, state.GetFeeState())
+}
+
 func TestDiffCurrentValidator(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -81,6 +107,8 @@ func TestDiffCurrentValidator(t *testing.T) {
 	state := NewMockState(ctrl)
 	// Called in NewDiff
 	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
 
 	states := NewMockVersions(ctrl)
 	states.EXPECT().GetState(lastAcceptedID).Return(state, true).AnyTimes()
@@ -110,6 +138,52 @@ func TestDiffCurrentValidator(t *testing.T) {
 	require.ErrorIs(err, database.ErrNotFound)
 }
 
+func TestDiffCurrentValidators(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	lastAcceptedID := ids.GenerateTestID()
+	state := NewMockState(ctrl)
+	// Called in NewDiff
+	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
+
+	states := NewMockVersions(ctrl)
+	states.EXPECT().GetState(lastAcceptedID).Return(state, true).AnyTimes()
+
+	d, err := NewDiff(lastAcceptedID, states)
+	require.NoError(err)
+
+	subnetID := ids.GenerateTestID()
+	newValidator := func() *Staker {
+		return &Staker{
+			TxID:     ids.GenerateTestID(),
+			SubnetID: subnetID,
+			NodeID:   ids.GenerateTestNodeID(),
+		}
+	}
+	var (
+		unmodifiedValidator = newValidator()
+		deletedValidator    = newValidator()
+		addedValidator      = newValidator()
+	)
+	d.DeleteCurrentValidator(deletedValidator)
+	d.PutCurrentValidator(addedValidator)
+
+	// Validators of other subnets are not included.
+	d.PutCurrentValidator(&Staker{
+		TxID:     ids.GenerateTestID(),
+		SubnetID: ids.GenerateTestID(),
+		NodeID:   ids.GenerateTestNodeID(),
+	})
+
+	state.EXPECT().GetCurrentValidators(subnetID).Return([]*Staker{unmodifiedValidator, deletedValidator}, nil).Times(1)
+	currentValidators, err := d.GetCurrentValidators(subnetID)
+	require.NoError(err)
+	require.ElementsMatch([]*Staker{unmodifiedValidator, addedValidator}, currentValidators)
+}
+
 func TestDiffPendingValidator(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
@@ -118,6 +192,8 @@ func TestDiffPendingValidator(t *testing.T) {
 	state := NewMockState(ctrl)
 	// Called in NewDiff
 	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
 
 	states := NewMockVersions(ctrl)
 	states.EXPECT().GetState(lastAcceptedID).Return(state, true).AnyTimes()
@@ -160,6 +236,8 @@ func TestDiffCurrentDelegator(t *testing.T) {
 	state := NewMockState(ctrl)
 	// Called in NewDiff
 	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
 
 	states := NewMockVersions(ctrl)
 	lastAcceptedID := ids.GenerateTestID()
@@ -209,6 +287,8 @@ func TestDiffPendingDelegator(t *testing.T) {
 	state := NewMockState(ctrl)
 	// Called in NewDiff
 	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
 
 	states := NewMockVersions(ctrl)
 	lastAcceptedID := ids.GenerateTestID()
@@ -349,6 +429,8 @@ func TestDiffTx(t *testing.T) {
 	state := NewMockState(ctrl)
 	// Called in NewDiff
 	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
 
 	states := NewMockVersions(ctrl)
 	lastAcceptedID := ids.GenerateTestID()
@@ -445,6 +527,8 @@ func TestDiffUTXO(t *testing.T) {
 	state := NewMockState(ctrl)
 	// Called in NewDiff
 	state.EXPECT().GetTimestamp().Return(time.Now()).Times(1)
+	state.EXPECT().GetFeeState().Return(gas.State{}).Times(1)
+	state.EXPECT().GetAccruedFees().Return(uint64(0)).Times(1)
 
 	states := NewMockVersions(ctrl)
 	lastAcceptedID := ids.GenerateTestID()
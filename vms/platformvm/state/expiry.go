@@ -0,0 +1,80 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"github.com/google/btree"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/wrappers"
+)
+
+const expiryEntryLen = wrappers.LongLen + ids.IDLen
+
+var (
+	_ btree.LessFunc[ExpiryEntry] = ExpiryEntry.Less
+
+	errUnexpectedExpiryEntryLength = fmt.Errorf("expected expiry entry length %d", expiryEntryLen)
+)
+
+// ExpiryEntry records that the warp message registering [ValidationID] has
+// been consumed. The entry must be kept until [Timestamp], after which the
+// message has expired and can no longer be replayed.
+type ExpiryEntry struct {
+	Timestamp    uint64
+	ValidationID ids.ID
+}
+
+// Marshal returns the database key of the entry. Keys sort by timestamp.
+func (e ExpiryEntry) Marshal() []byte {
+	data := make([]byte, expiryEntryLen)
+	binary.BigEndian.PutUint64(data, e.Timestamp)
+	copy(data[wrappers.LongLen:], e.ValidationID[:])
+	return data
+}
+
+func (e *ExpiryEntry) Unmarshal(data []byte) error {
+	if len(data) != expiryEntryLen {
+		return fmt.Errorf("%w: %d", errUnexpectedExpiryEntryLength, len(data))
+	}
+	e.Timestamp = binary.BigEndian.Uint64(data)
+	copy(e.ValidationID[:], data[wrappers.LongLen:])
+	return nil
+}
+
+// Less sorts entries by the order in which they expire.
+func (e ExpiryEntry) Less(o ExpiryEntry) bool {
+	return e.Compare(o) == -1
+}
+
+func (e ExpiryEntry) Compare(o ExpiryEntry) int {
+	if c := cmp.Compare(e.Timestamp, o.Timestamp); c != 0 {
+		return c
+	}
+	return e.ValidationID.Compare(o.ValidationID)
+}
+
+// mergeExpiries applies [modified] on top of the sorted [parent] entries.
+// [modified] maps an entry to true if it was added and false if it was
+// removed.
+func mergeExpiries(parent []ExpiryEntry, modified map[ExpiryEntry]bool) []ExpiryEntry {
+	expiries := make([]ExpiryEntry, 0, len(parent)+len(modified))
+	for _, entry := range parent {
+		if _, ok := modified[entry]; !ok {
+			expiries = append(expiries, entry)
+		}
+	}
+	for entry, added := range modified {
+		if added {
+			expiries = append(expiries, entry)
+		}
+	}
+	slices.SortFunc(expiries, ExpiryEntry.Compare)
+	return expiries
+}
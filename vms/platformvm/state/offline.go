@@ -0,0 +1,249 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/linkeddb"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/database/versiondb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/platformvm/block"
+	"github.com/skychains/chain/vms/platformvm/status"
+	"github.com/skychains/chain/vms/platformvm/txs"
+)
+
+var (
+	errUnexpectedBlockID     = errors.New("unexpected block ID")
+	errUnexpectedBlockHeight = errors.New("unexpected block height")
+	errUnexpectedTxID        = errors.New("unexpected tx ID")
+	errUnexpectedTxStatus    = errors.New("unexpected tx status")
+)
+
+// The functions in this file read the state persisted in [db], the database
+// provided to the P-chain VM, without loading it. They are intended to be used
+// by offline tools and never modify [db].
+
+// Summary describes the state persisted by the P-chain.
+type Summary struct {
+	LastAccepted ids.ID
+	Height       uint64
+	// UTXOChecksum is the checksum of the stored UTXOs.
+	UTXOChecksum ids.ID
+	// ChecksumRecorded is true if [UTXOChecksum] was compared to the checksum
+	// recorded by a node, that has checksums-enabled set, when it accepted the
+	// last accepted block.
+	ChecksumRecorded bool
+}
+
+// GetChains returns the CreateChainTx of every chain, including the chains of
+// the primary network, persisted in [db].
+func GetChains(db database.Database) ([]*txs.Tx, error) {
+	baseDB := versiondb.New(db)
+	subnetIDs := []ids.ID{constants.PrimaryNetworkID}
+	subnetIt := linkeddb.NewDefault(prefixdb.New(SubnetPrefix, baseDB)).NewIterator()
+	for subnetIt.Next() {
+		subnetID, err := ids.ToID(subnetIt.Key())
+		if err != nil {
+			subnetIt.Release()
+			return nil, err
+		}
+		subnetIDs = append(subnetIDs, subnetID)
+	}
+	err := subnetIt.Error()
+	subnetIt.Release()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		chainDB = prefixdb.New(ChainPrefix, baseDB)
+		txDB    = prefixdb.New(TxPrefix, baseDB)
+		chains  []*txs.Tx
+	)
+	for _, subnetID := range subnetIDs {
+		chainIt := linkeddb.NewDefault(prefixdb.New(subnetID[:], chainDB)).NewIterator()
+		for chainIt.Next() {
+			chainID, err := ids.ToID(chainIt.Key())
+			if err != nil {
+				chainIt.Release()
+				return nil, err
+			}
+			tx, _, err := getStoredTx(txDB, chainID)
+			if err != nil {
+				chainIt.Release()
+				return nil, fmt.Errorf("couldn't get chain %s: %w", chainID, err)
+			}
+			chains = append(chains, tx)
+		}
+		err := chainIt.Error()
+		chainIt.Release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return chains, nil
+}
+
+// Verify re-verifies the state persisted in [db].
+//
+// Every block, from the last accepted block back to genesis, must be stored,
+// hash to the ID it is stored under and follow its parent. Every tx in those
+// blocks must be stored as decided. Every UTXO must be stored under its ID and,
+// if a checksum was recorded for the last accepted block, the stored UTXOs must
+// match it.
+func Verify(ctx context.Context, db database.Database) (*Summary, error) {
+	var (
+		baseDB      = versiondb.New(db)
+		blockIDDB   = prefixdb.New(BlockIDPrefix, baseDB)
+		blockDB     = prefixdb.New(BlockPrefix, baseDB)
+		txDB        = prefixdb.New(TxPrefix, baseDB)
+		utxoDB      = prefixdb.New(UTXOPrefix, baseDB)
+		singletonDB = prefixdb.New(SingletonPrefix, baseDB)
+	)
+	lastAccepted, err := database.GetID(singletonDB, LastAcceptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get last accepted block: %w", err)
+	}
+
+	summary := &Summary{
+		LastAccepted: lastAccepted,
+	}
+	var (
+		blkID       = lastAccepted
+		childHeight uint64
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		blk, err := verifyStoredBlock(blockIDDB, blockDB, txDB, blkID)
+		if err != nil {
+			return nil, err
+		}
+
+		height := blk.Height()
+		if blkID == lastAccepted {
+			summary.Height = height
+		} else if height+1 != childHeight {
+			return nil, fmt.Errorf("%w: block %s has height %d but its child has height %d",
+				errUnexpectedBlockHeight,
+				blkID,
+				height,
+				childHeight,
+			)
+		}
+		if height == 0 {
+			break
+		}
+		childHeight = height
+		blkID = blk.Parent()
+	}
+
+	summary.UTXOChecksum, err = lux.VerifyChecksum(ctx, utxoDB, txs.GenesisCodec)
+	if err != nil {
+		return nil, err
+	}
+	summary.ChecksumRecorded, err = lux.VerifyRecordedChecksum(
+		singletonDB,
+		UTXOChecksumKey,
+		lastAccepted,
+		summary.UTXOChecksum,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't verify UTXO checksum: %w", err)
+	}
+	return summary, nil
+}
+
+// verifyStoredBlock verifies the block stored under [blkID] and the txs it
+// contains.
+func verifyStoredBlock(
+	blockIDDB database.KeyValueReader,
+	blockDB database.KeyValueReader,
+	txDB database.KeyValueReader,
+	blkID ids.ID,
+) (block.Block, error) {
+	blkBytes, err := blockDB.Get(blkID[:])
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get block %s: %w", blkID, err)
+	}
+	blk, _, err := parseStoredBlock(blkBytes)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse block %s: %w", blkID, err)
+	}
+	if blk.ID() != blkID {
+		return nil, fmt.Errorf("%w: block %s is stored under %s",
+			errUnexpectedBlockID,
+			blk.ID(),
+			blkID,
+		)
+	}
+
+	// Blocks accepted by old versions of the node may not be indexed by
+	// height.
+	height := blk.Height()
+	indexedID, err := database.GetID(blockIDDB, database.PackUInt64(height))
+	switch {
+	case err == database.ErrNotFound:
+	case err != nil:
+		return nil, err
+	case indexedID != blkID:
+		return nil, fmt.Errorf("%w: height %d is indexed as %s rather than %s",
+			errUnexpectedBlockID,
+			height,
+			indexedID,
+			blkID,
+		)
+	}
+
+	for _, blkTx := range blk.Txs() {
+		txID := blkTx.ID()
+		tx, txStatus, err := getStoredTx(txDB, txID)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get tx %s of block %s: %w", txID, blkID, err)
+		}
+		if tx.ID() != txID {
+			return nil, fmt.Errorf("%w: tx %s is stored under %s",
+				errUnexpectedTxID,
+				tx.ID(),
+				txID,
+			)
+		}
+		if txStatus != status.Committed && txStatus != status.Aborted {
+			return nil, fmt.Errorf("%w: tx %s of block %s is %s",
+				errUnexpectedTxStatus,
+				txID,
+				blkID,
+				txStatus,
+			)
+		}
+	}
+	return blk, nil
+}
+
+func getStoredTx(txDB database.KeyValueReader, txID ids.ID) (*txs.Tx, status.Status, error) {
+	txBytes, err := txDB.Get(txID[:])
+	if err != nil {
+		return nil, status.Unknown, err
+	}
+
+	stx := txBytesAndStatus{}
+	if _, err := txs.GenesisCodec.Unmarshal(txBytes, &stx); err != nil {
+		return nil, status.Unknown, err
+	}
+
+	tx, err := txs.Parse(txs.GenesisCodec, stx.Tx)
+	if err != nil {
+		return nil, status.Unknown, err
+	}
+	return tx, stx.Status, nil
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/database/versiondb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/platformvm/block"
+	"github.com/skychains/chain/vms/platformvm/genesis"
+	"github.com/skychains/chain/vms/platformvm/status"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/secp256k1fx"
+)
+
+func TestOffline(t *testing.T) {
+	require := require.New(t)
+	s, db := newUninitializedState(require)
+
+	chainTx := &txs.Tx{Unsigned: &txs.CreateChainTx{
+		SubnetID:   constants.PrimaryNetworkID,
+		ChainName:  "x",
+		VMID:       constants.AVMID,
+		SubnetAuth: &secp256k1fx.Input{},
+	}}
+	require.NoError(chainTx.Initialize(txs.Codec))
+
+	utxo := lux.UTXO{
+		UTXOID: lux.UTXOID{
+			TxID: initialTxID,
+		},
+		Asset: lux.Asset{ID: initialTxID},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Schmeckle,
+		},
+	}
+
+	genesisBlk, err := block.NewApricotCommitBlock(ids.GenerateTestID(), 0)
+	require.NoError(err)
+	require.NoError(s.syncGenesis(genesisBlk, &genesis.Genesis{
+		UTXOs: []*genesis.UTXO{
+			{
+				UTXO: utxo,
+			},
+		},
+		Chains: []*txs.Tx{
+			chainTx,
+		},
+		Timestamp:     uint64(initialTime.Unix()),
+		InitialSupply: units.Schmeckle,
+	}))
+
+	rewardTx := &txs.Tx{Unsigned: &txs.RewardValidatorTx{
+		TxID: ids.GenerateTestID(),
+	}}
+	require.NoError(rewardTx.Initialize(txs.Codec))
+	blk, err := block.NewBanffStandardBlock(initialTime, genesisBlk.ID(), 1, []*txs.Tx{rewardTx})
+	require.NoError(err)
+
+	s.AddTx(rewardTx, status.Committed)
+	s.AddStatelessBlock(blk)
+	s.SetLastAccepted(blk.ID())
+	require.NoError(s.Commit())
+
+	chains, err := GetChains(db)
+	require.NoError(err)
+	require.Len(chains, 1)
+	require.Equal(chainTx.ID(), chains[0].ID())
+
+	summary, err := Verify(context.Background(), db)
+	require.NoError(err)
+	require.Equal(&Summary{
+		LastAccepted: blk.ID(),
+		Height:       1,
+		UTXOChecksum: utxo.InputID(),
+	}, summary)
+
+	// Record a checksum that doesn't match the stored UTXOs.
+	vdb := versiondb.New(db)
+	singletonDB := prefixdb.New(SingletonPrefix, vdb)
+	require.NoError(lux.PutChecksum(singletonDB, UTXOChecksumKey, blk.ID(), ids.GenerateTestID()))
+	require.NoError(vdb.Commit())
+
+	_, err = Verify(context.Background(), db)
+	require.ErrorIs(err, lux.ErrChecksumMismatch)
+
+	// Record the checksum of the stored UTXOs.
+	require.NoError(lux.PutChecksum(singletonDB, UTXOChecksumKey, blk.ID(), utxo.InputID()))
+	require.NoError(vdb.Commit())
+
+	summary, err = Verify(context.Background(), db)
+	require.NoError(err)
+	require.True(summary.ChecksumRecorded)
+
+	// Mark the tx in the last accepted block as processing.
+	txBytes, err := txs.GenesisCodec.Marshal(txs.CodecVersion, &txBytesAndStatus{
+		Tx:     rewardTx.Bytes(),
+		Status: status.Processing,
+	})
+	require.NoError(err)
+	rewardTxID := rewardTx.ID()
+	require.NoError(prefixdb.New(TxPrefix, vdb).Put(rewardTxID[:], txBytes))
+	require.NoError(vdb.Commit())
+
+	_, err = Verify(context.Background(), db)
+	require.ErrorIs(err, errUnexpectedTxStatus)
+}
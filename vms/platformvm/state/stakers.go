@@ -61,6 +61,10 @@ type CurrentStakers interface {
 	// GetCurrentStakerIterator returns stakers in order of their removal from
 	// the current staker set.
 	GetCurrentStakerIterator() (StakerIterator, error)
+
+	// GetCurrentValidators returns the current validators of [subnetID] in no
+	// particular order.
+	GetCurrentValidators(subnetID ids.ID) ([]*Staker, error)
 }
 
 type PendingStakers interface {
@@ -131,6 +135,17 @@ func (v *baseStakers) GetValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker,
 	return validator.validator, nil
 }
 
+func (v *baseStakers) GetValidators(subnetID ids.ID) []*Staker {
+	subnetValidators := v.validators[subnetID]
+	validators := make([]*Staker, 0, len(subnetValidators))
+	for _, validator := range subnetValidators {
+		if validator.validator != nil {
+			validators = append(validators, validator.validator)
+		}
+	}
+	return validators
+}
+
 func (v *baseStakers) PutValidator(staker *Staker) {
 	validator := v.getOrCreateValidator(staker.SubnetID, staker.NodeID)
 	validator.validator = staker
@@ -288,6 +303,25 @@ func (s *diffStakers) GetValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker,
 	return nil, validatorDiff.validatorStatus
 }
 
+// GetValidators applies the validators of [subnetID] that were added or
+// removed in this diff to [parentValidators].
+// Invariant: Assumes that the validator will never be removed and then added.
+func (s *diffStakers) GetValidators(parentValidators []*Staker, subnetID ids.ID) []*Staker {
+	subnetValidatorDiffs := s.validatorDiffs[subnetID]
+	validators := make([]*Staker, 0, len(parentValidators)+len(subnetValidatorDiffs))
+	for _, validator := range parentValidators {
+		if validatorDiff, ok := subnetValidatorDiffs[validator.NodeID]; !ok || validatorDiff.validatorStatus != deleted {
+			validators = append(validators, validator)
+		}
+	}
+	for _, validatorDiff := range subnetValidatorDiffs {
+		if validatorDiff.validatorStatus == added {
+			validators = append(validators, validatorDiff.validator)
+		}
+	}
+	return validators
+}
+
 func (s *diffStakers) PutValidator(staker *Staker) {
 	validatorDiff := s.getOrCreateDiff(staker.SubnetID, staker.NodeID)
 	validatorDiff.validatorStatus = added
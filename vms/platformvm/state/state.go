@@ -8,7 +8,9 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/btree"
@@ -32,6 +34,7 @@ import (
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/timer"
 	"github.com/skychains/chain/utils/wrappers"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/block"
 	"github.com/skychains/chain/vms/platformvm/config"
@@ -74,17 +77,27 @@ var (
 	UTXOPrefix                    = []byte("utxo")
 	SubnetPrefix                  = []byte("subnet")
 	SubnetOwnerPrefix             = []byte("subnetOwner")
+	SubnetManagerPrefix           = []byte("subnetManager")
+	SubnetOnlyValidatorsPrefix    = []byte("subnetOnlyValidators")
+	ExpiryPrefix                  = []byte("expiry")
 	TransformedSubnetPrefix       = []byte("transformedSubnet")
 	SupplyPrefix                  = []byte("supply")
 	ChainPrefix                   = []byte("chain")
 	SingletonPrefix               = []byte("singleton")
+	StateSyncSnapshotPrefix       = []byte("stateSyncSnapshot")
+	StateSyncProgressPrefix       = []byte("stateSyncProgress")
 
 	TimestampKey       = []byte("timestamp")
+	FeeStateKey        = []byte("fee state")
+	AccruedFeesKey     = []byte("accrued fees")
 	CurrentSupplyKey   = []byte("current supply")
 	LastAcceptedKey    = []byte("last accepted")
 	HeightsIndexedKey  = []byte("heights indexed")
 	InitializedKey     = []byte("initialized")
 	BlocksReindexedKey = []byte("blocks reindexed")
+	UTXOChecksumKey    = []byte("utxo checksum")
+
+	ValidatorDiffsStartHeightKey = []byte("validator diffs start height")
 )
 
 // Chain collects all methods to manage the state of the chain for block
@@ -98,6 +111,14 @@ type Chain interface {
 	GetTimestamp() time.Time
 	SetTimestamp(tm time.Time)
 
+	GetFeeState() gas.State
+	SetFeeState(f gas.State)
+
+	// GetAccruedFees returns the total fees that an always active subnet only
+	// validator would have paid since the F upgrade.
+	GetAccruedFees() uint64
+	SetAccruedFees(f uint64)
+
 	GetCurrentSupply(subnetID ids.ID) (uint64, error)
 	SetCurrentSupply(subnetID ids.ID, cs uint64)
 
@@ -108,6 +129,35 @@ type Chain interface {
 	GetSubnetOwner(subnetID ids.ID) (fx.Owner, error)
 	SetSubnetOwner(subnetID ids.ID, owner fx.Owner)
 
+	// GetSubnetManager returns the chainID and address of the manager of a
+	// converted subnet. If the subnet was never converted,
+	// [database.ErrNotFound] is returned.
+	GetSubnetManager(subnetID ids.ID) (ids.ID, []byte, error)
+	SetSubnetManager(subnetID ids.ID, chainID ids.ID, addr []byte)
+
+	// GetActiveSubnetOnlyValidators returns the active subnet only validators
+	// sorted by the order in which they will exhaust their balances.
+	GetActiveSubnetOnlyValidators() ([]SubnetOnlyValidator, error)
+	NumActiveSubnetOnlyValidators() int
+	GetSubnetOnlyValidator(validationID ids.ID) (SubnetOnlyValidator, error)
+	// GetSubnetOnlyValidators returns the subnet only validators of
+	// [subnetID], including inactive validators, in no particular order.
+	GetSubnetOnlyValidators(subnetID ids.ID) ([]SubnetOnlyValidator, error)
+	HasSubnetOnlyValidator(subnetID ids.ID, nodeID ids.NodeID) (bool, error)
+
+	// PutSubnetOnlyValidator inserts or updates [sov]. If [sov.Weight] is 0,
+	// the validator is removed. An error is returned if a field that must
+	// never change is modified, or if the subnetID + nodeID pair is already in
+	// use by a different validator.
+	PutSubnetOnlyValidator(sov SubnetOnlyValidator) error
+
+	// GetExpiries returns the entries of consumed warp messages that have not
+	// yet been pruned, sorted by the order in which they expire.
+	GetExpiries() ([]ExpiryEntry, error)
+	HasExpiry(entry ExpiryEntry) (bool, error)
+	PutExpiry(entry ExpiryEntry)
+	DeleteExpiry(entry ExpiryEntry)
+
 	GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error)
 	AddSubnetTransformation(transformSubnetTx *txs.Tx)
 
@@ -171,8 +221,41 @@ type State interface {
 		validators map[ids.NodeID]*validators.GetValidatorOutput,
 		startHeight uint64,
 		endHeight uint64,
+		subnetID ids.ID,
 	) error
 
+	// GetValidatorWeightDiffs returns the net change of the weight of every
+	// validator of [subnetID] whose weight changed in the heights
+	// [endHeight, startHeight].
+	//
+	// Note: Like [ApplyValidatorWeightDiffs], this function iterates from
+	// [startHeight] towards the genesis block.
+	GetValidatorWeightDiffs(
+		ctx context.Context,
+		startHeight uint64,
+		endHeight uint64,
+		subnetID ids.ID,
+	) (map[ids.NodeID]*ValidatorWeightDiff, error)
+
+	// GetValidatorPublicKeyDiffs returns, for every validator of [subnetID]
+	// whose public key diff was recorded in the heights
+	// [endHeight, startHeight], the uncompressed public key it had at height
+	// [endHeight - 1]. An empty key means that the validator had no public key.
+	//
+	// Note: Like [ApplyValidatorPublicKeyDiffs], this function iterates from
+	// [startHeight] towards the genesis block.
+	GetValidatorPublicKeyDiffs(
+		ctx context.Context,
+		startHeight uint64,
+		endHeight uint64,
+		subnetID ids.ID,
+	) (map[ids.NodeID][]byte, error)
+
+	// GetValidatorDiffsStartHeight returns the lowest height whose validator
+	// diffs are stored. If the state was never synced, every diff is stored
+	// and 0 is returned.
+	GetValidatorDiffsStartHeight() uint64
+
 	SetHeight(height uint64)
 
 	// Discard uncommitted changes to the database.
@@ -195,6 +278,36 @@ type State interface {
 
 	Checksum() ids.ID
 
+	// GetSyncSummary returns the summary of the most recently produced
+	// snapshot. If no snapshot was produced, [database.ErrNotFound] is
+	// returned.
+	GetSyncSummary() (*SyncSummary, error)
+
+	// GetSyncChunk returns the chunk at [index] of the snapshot at [height].
+	// If the snapshot at [height] isn't stored, [database.ErrNotFound] is
+	// returned.
+	GetSyncChunk(height uint64, index uint64) ([]byte, error)
+
+	// GetOngoingSyncSummary returns the summary that is currently being
+	// synced. If no sync is ongoing, [database.ErrNotFound] is returned.
+	GetOngoingSyncSummary() (*SyncSummary, error)
+
+	// SetOngoingSyncSummary marks [summary] as the summary being synced. If a
+	// different summary was being synced, its fetched chunks are dropped.
+	SetOngoingSyncSummary(summary *SyncSummary) error
+
+	// GetMissingSyncChunks returns the indices of the chunks of the ongoing
+	// sync that haven't been fetched yet.
+	GetMissingSyncChunks() ([]uint64, error)
+
+	// PutSyncChunk stores the chunk at [index] of the ongoing sync. If the
+	// chunk doesn't match the summary, [ErrInvalidSyncChunk] is returned.
+	PutSyncChunk(index uint64, chunk []byte) error
+
+	// FinishSync replaces the state with the fetched snapshot of the ongoing
+	// sync and reloads the in-memory state, including the validator sets.
+	FinishSync() error
+
 	Close() error
 }
 
@@ -258,16 +371,31 @@ type stateBlk struct {
  * |   '-- txID -> nil
  * |-. subnetOwners
  * | '-. subnetID -> owner
+ * |-. subnetManagers
+ * | '-. subnetID -> manager chainID + address
+ * |-. subnetOnlyValidators
+ * | '-. validationID -> subnet only validator
+ * |-. expiry
+ * | '-- timestamp + validationID -> nil
  * |-. chains
  * | '-. subnetID
  * |   '-. list
  * |     '-- txID -> nil
+ * |-. stateSyncSnapshot
+ * | |-- summaryKey -> summary of the most recent snapshot
+ * | '-- index -> chunk
+ * |-. stateSyncProgress
+ * | |-- summaryKey -> summary being synced
+ * | '-- index -> fetched chunk
  * '-. singletons
  *   |-- initializedKey -> nil
  *   |-- blocksReindexedKey -> nil
  *   |-- timestampKey -> timestamp
+ *   |-- feeStateKey -> feeState
+ *   |-- accruedFeesKey -> accruedFees
  *   |-- currentSupplyKey -> currentSupply
  *   |-- lastAcceptedKey -> lastAccepted
+ *   |-- validatorDiffsStartHeightKey -> validatorDiffsStartHeight
  *   '-- heightsIndexKey -> startIndexHeight + endIndexHeight
  */
 type state struct {
@@ -338,6 +466,20 @@ type state struct {
 	subnetOwnerCache cache.Cacher[ids.ID, fxOwnerAndSize] // cache of subnetID -> owner; if the entry is nil, it is not in the database
 	subnetOwnerDB    database.Database
 
+	subnetManagers     map[ids.ID]subnetManager             // map of subnetID -> manager of the subnet
+	subnetManagerCache cache.Cacher[ids.ID, *subnetManager] // cache of subnetID -> manager; if the entry is nil, it is not in the database
+	subnetManagerDB    database.Database
+
+	sovDiff                *subnetOnlyValidatorsDiff          // modifications that have not been written yet
+	subnetOnlyValidators   map[ids.ID]SubnetOnlyValidator     // validationID -> persisted validator
+	subnetOnlyValidatorIDs map[ids.ID]map[ids.NodeID]ids.ID   // subnetID -> nodeID -> validationID of the persisted validator
+	activeSOVs             *btree.BTreeG[SubnetOnlyValidator] // persisted active validators
+	subnetOnlyValidatorsDB database.Database
+
+	modifiedExpiries map[ExpiryEntry]bool       // entry -> whether the entry was added or removed
+	expiries         *btree.BTreeG[ExpiryEntry] // persisted entries
+	expiryDB         database.Database
+
 	transformedSubnets     map[ids.ID]*txs.Tx            // map of subnetID -> transformSubnetTx
 	transformedSubnetCache cache.Cacher[ids.ID, *txs.Tx] // cache of subnetID -> transformSubnetTx; if the entry is nil, it is not in the database
 	transformedSubnetDB    database.Database
@@ -353,12 +495,31 @@ type state struct {
 
 	// The persisted fields represent the current database value
 	timestamp, persistedTimestamp         time.Time
+	feeState, persistedFeeState           gas.State
+	accruedFees, persistedAccruedFees     uint64
 	currentSupply, persistedCurrentSupply uint64
 	// [lastAccepted] is the most recently accepted block.
 	lastAccepted, persistedLastAccepted ids.ID
 	// TODO: Remove indexedHeights once v1.11.3 has been released.
 	indexedHeights *heightRange
-	singletonDB    database.Database
+	// Validator diffs below [validatorDiffsStartHeight] were not synced.
+	validatorDiffsStartHeight uint64
+	singletonDB               database.Database
+	// If [checksumsEnabled], the UTXO checksum is recorded, with the last
+	// accepted block, whenever the state is written.
+	checksumsEnabled bool
+
+	syncSummaryFrequency uint64            // number of blocks between snapshots; 0 if disabled
+	syncSnapshotDB       database.Database // most recently produced snapshot
+	syncProgressDB       database.Database // snapshot that is being synced
+	// The snapshot of the state at [pendingSyncSnapshot] is built from a
+	// snapshot of [db] once the state has been committed.
+	db                   database.Database
+	pendingSyncSnapshot  block.Block
+	buildingSyncSnapshot atomic.Bool
+	syncSnapshotCtx      context.Context
+	syncSnapshotCancel   context.CancelFunc
+	syncSnapshotWG       sync.WaitGroup
 }
 
 // heightRange is used to track which heights are safe to use the native DB
@@ -402,6 +563,11 @@ type txAndStatus struct {
 	status status.Status
 }
 
+type subnetManager struct {
+	ChainID ids.ID `serialize:"true"`
+	Addr    []byte `serialize:"true"`
+}
+
 type fxOwnerAndSize struct {
 	owner fx.Owner
 	size  int
@@ -546,6 +712,16 @@ func newState(
 		return nil, err
 	}
 
+	subnetManagerDB := prefixdb.New(SubnetManagerPrefix, baseDB)
+	subnetManagerCache, err := metercacher.New[ids.ID, *subnetManager](
+		"subnet_manager_cache",
+		metricsReg,
+		&cache.LRU[ids.ID, *subnetManager]{Size: execCfg.FxOwnerCacheSize},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	transformedSubnetCache, err := metercacher.New(
 		"transformed_subnet_cache",
 		metricsReg,
@@ -582,6 +758,7 @@ func newState(
 		return nil, err
 	}
 
+	syncSnapshotCtx, syncSnapshotCancel := context.WithCancel(context.Background())
 	return &state{
 		validatorState: newValidatorState(),
 
@@ -644,6 +821,20 @@ func newState(
 		subnetOwnerDB:    subnetOwnerDB,
 		subnetOwnerCache: subnetOwnerCache,
 
+		subnetManagers:     make(map[ids.ID]subnetManager),
+		subnetManagerDB:    subnetManagerDB,
+		subnetManagerCache: subnetManagerCache,
+
+		sovDiff:                newSubnetOnlyValidatorsDiff(),
+		subnetOnlyValidators:   make(map[ids.ID]SubnetOnlyValidator),
+		subnetOnlyValidatorIDs: make(map[ids.ID]map[ids.NodeID]ids.ID),
+		activeSOVs:             btree.NewG(defaultTreeDegree, SubnetOnlyValidator.Less),
+		subnetOnlyValidatorsDB: prefixdb.New(SubnetOnlyValidatorsPrefix, baseDB),
+
+		modifiedExpiries: make(map[ExpiryEntry]bool),
+		expiries:         btree.NewG(defaultTreeDegree, ExpiryEntry.Less),
+		expiryDB:         prefixdb.New(ExpiryPrefix, baseDB),
+
 		transformedSubnets:     make(map[ids.ID]*txs.Tx),
 		transformedSubnetCache: transformedSubnetCache,
 		transformedSubnetDB:    prefixdb.New(TransformedSubnetPrefix, baseDB),
@@ -657,7 +848,16 @@ func newState(
 		chainCache:   chainCache,
 		chainDBCache: chainDBCache,
 
-		singletonDB: prefixdb.New(SingletonPrefix, baseDB),
+		singletonDB:      prefixdb.New(SingletonPrefix, baseDB),
+		checksumsEnabled: execCfg.ChecksumsEnabled,
+
+		syncSummaryFrequency: execCfg.StateSyncSummaryFrequency,
+		// Snapshots are written in the background, so they bypass [baseDB].
+		syncSnapshotDB:     prefixdb.New(StateSyncSnapshotPrefix, db),
+		syncProgressDB:     prefixdb.New(StateSyncProgressPrefix, baseDB),
+		db:                 db,
+		syncSnapshotCtx:    syncSnapshotCtx,
+		syncSnapshotCancel: syncSnapshotCancel,
 	}, nil
 }
 
@@ -689,6 +889,10 @@ func (s *state) GetCurrentStakerIterator() (StakerIterator, error) {
 	return s.currentStakers.GetStakerIterator(), nil
 }
 
+func (s *state) GetCurrentValidators(subnetID ids.ID) ([]*Staker, error) {
+	return s.currentStakers.GetValidators(subnetID), nil
+}
+
 func (s *state) GetPendingValidator(subnetID ids.ID, nodeID ids.NodeID) (*Staker, error) {
 	return s.pendingStakers.GetValidator(subnetID, nodeID)
 }
@@ -807,6 +1011,113 @@ func (s *state) SetSubnetOwner(subnetID ids.ID, owner fx.Owner) {
 	s.subnetOwners[subnetID] = owner
 }
 
+func (s *state) GetSubnetManager(subnetID ids.ID) (ids.ID, []byte, error) {
+	if manager, exists := s.subnetManagers[subnetID]; exists {
+		return manager.ChainID, manager.Addr, nil
+	}
+
+	if manager, cached := s.subnetManagerCache.Get(subnetID); cached {
+		if manager == nil {
+			return ids.Empty, nil, database.ErrNotFound
+		}
+		return manager.ChainID, manager.Addr, nil
+	}
+
+	managerBytes, err := s.subnetManagerDB.Get(subnetID[:])
+	if err == database.ErrNotFound {
+		s.subnetManagerCache.Put(subnetID, nil)
+		return ids.Empty, nil, database.ErrNotFound
+	}
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+
+	var manager subnetManager
+	if _, err := block.GenesisCodec.Unmarshal(managerBytes, &manager); err != nil {
+		return ids.Empty, nil, fmt.Errorf("failed to parse subnet manager: %w", err)
+	}
+	s.subnetManagerCache.Put(subnetID, &manager)
+	return manager.ChainID, manager.Addr, nil
+}
+
+func (s *state) SetSubnetManager(subnetID ids.ID, chainID ids.ID, addr []byte) {
+	s.subnetManagers[subnetID] = subnetManager{
+		ChainID: chainID,
+		Addr:    addr,
+	}
+}
+
+func (s *state) GetActiveSubnetOnlyValidators() ([]SubnetOnlyValidator, error) {
+	persistedActive := make([]SubnetOnlyValidator, 0, s.activeSOVs.Len())
+	s.activeSOVs.Ascend(func(sov SubnetOnlyValidator) bool {
+		persistedActive = append(persistedActive, sov)
+		return true
+	})
+	return s.sovDiff.getActiveSubnetOnlyValidators(persistedActive), nil
+}
+
+func (s *state) NumActiveSubnetOnlyValidators() int {
+	return s.activeSOVs.Len() + s.sovDiff.numAddedActive
+}
+
+func (s *state) GetSubnetOnlyValidator(validationID ids.ID) (SubnetOnlyValidator, error) {
+	if sov, modified, err := s.sovDiff.getSubnetOnlyValidator(validationID); modified {
+		return sov, err
+	}
+
+	sov, ok := s.subnetOnlyValidators[validationID]
+	if !ok {
+		return SubnetOnlyValidator{}, database.ErrNotFound
+	}
+	return sov, nil
+}
+
+func (s *state) HasSubnetOnlyValidator(subnetID ids.ID, nodeID ids.NodeID) (bool, error) {
+	if has, modified := s.sovDiff.hasSubnetOnlyValidator(subnetID, nodeID); modified {
+		return has, nil
+	}
+
+	_, has := s.subnetOnlyValidatorIDs[subnetID][nodeID]
+	return has, nil
+}
+
+func (s *state) GetSubnetOnlyValidators(subnetID ids.ID) ([]SubnetOnlyValidator, error) {
+	validationIDs := s.subnetOnlyValidatorIDs[subnetID]
+	persisted := make([]SubnetOnlyValidator, 0, len(validationIDs))
+	for _, validationID := range validationIDs {
+		persisted = append(persisted, s.subnetOnlyValidators[validationID])
+	}
+	return s.sovDiff.getSubnetOnlyValidators(persisted, subnetID), nil
+}
+
+func (s *state) PutSubnetOnlyValidator(sov SubnetOnlyValidator) error {
+	return s.sovDiff.putSubnetOnlyValidator(s, sov)
+}
+
+func (s *state) GetExpiries() ([]ExpiryEntry, error) {
+	persisted := make([]ExpiryEntry, 0, s.expiries.Len())
+	s.expiries.Ascend(func(entry ExpiryEntry) bool {
+		persisted = append(persisted, entry)
+		return true
+	})
+	return mergeExpiries(persisted, s.modifiedExpiries), nil
+}
+
+func (s *state) HasExpiry(entry ExpiryEntry) (bool, error) {
+	if added, modified := s.modifiedExpiries[entry]; modified {
+		return added, nil
+	}
+	return s.expiries.Has(entry), nil
+}
+
+func (s *state) PutExpiry(entry ExpiryEntry) {
+	s.modifiedExpiries[entry] = true
+}
+
+func (s *state) DeleteExpiry(entry ExpiryEntry) {
+	s.modifiedExpiries[entry] = false
+}
+
 func (s *state) GetSubnetTransformation(subnetID ids.ID) (*txs.Tx, error) {
 	if tx, exists := s.transformedSubnets[subnetID]; exists {
 		return tx, nil
@@ -1005,6 +1316,22 @@ func (s *state) SetTimestamp(tm time.Time) {
 	s.timestamp = tm
 }
 
+func (s *state) GetFeeState() gas.State {
+	return s.feeState
+}
+
+func (s *state) SetFeeState(feeState gas.State) {
+	s.feeState = feeState
+}
+
+func (s *state) GetAccruedFees() uint64 {
+	return s.accruedFees
+}
+
+func (s *state) SetAccruedFees(accruedFees uint64) {
+	s.accruedFees = accruedFees
+}
+
 func (s *state) GetLastAccepted() ids.ID {
 	return s.lastAccepted
 }
@@ -1149,10 +1476,11 @@ func (s *state) ApplyValidatorPublicKeyDiffs(
 	validators map[ids.NodeID]*validators.GetValidatorOutput,
 	startHeight uint64,
 	endHeight uint64,
+	subnetID ids.ID,
 ) error {
 	diffIter := s.validatorPublicKeyDiffsDB.NewIteratorWithStartAndPrefix(
-		marshalStartDiffKey(constants.PrimaryNetworkID, startHeight),
-		constants.PrimaryNetworkID[:],
+		marshalStartDiffKey(subnetID, startHeight),
+		subnetID[:],
 	)
 	defer diffIter.Release()
 
@@ -1193,6 +1521,90 @@ func (s *state) ApplyValidatorPublicKeyDiffs(
 	return diffIter.Error()
 }
 
+func (s *state) GetValidatorWeightDiffs(
+	ctx context.Context,
+	startHeight uint64,
+	endHeight uint64,
+	subnetID ids.ID,
+) (map[ids.NodeID]*ValidatorWeightDiff, error) {
+	diffIter := s.validatorWeightDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, startHeight),
+		subnetID[:],
+	)
+	defer diffIter.Release()
+
+	weightDiffs := make(map[ids.NodeID]*ValidatorWeightDiff)
+	for diffIter.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		_, parsedHeight, nodeID, err := unmarshalDiffKey(diffIter.Key())
+		if err != nil {
+			return nil, err
+		}
+		// If the parsedHeight is less than our target endHeight, then we have
+		// fully processed the diffs from startHeight through endHeight.
+		if parsedHeight < endHeight {
+			break
+		}
+
+		weightDiff, err := unmarshalWeightDiff(diffIter.Value())
+		if err != nil {
+			return nil, err
+		}
+
+		netDiff, ok := weightDiffs[nodeID]
+		if !ok {
+			netDiff = &ValidatorWeightDiff{}
+			weightDiffs[nodeID] = netDiff
+		}
+		if err := netDiff.Add(weightDiff.Decrease, weightDiff.Amount); err != nil {
+			return nil, err
+		}
+	}
+	return weightDiffs, diffIter.Error()
+}
+
+func (s *state) GetValidatorPublicKeyDiffs(
+	ctx context.Context,
+	startHeight uint64,
+	endHeight uint64,
+	subnetID ids.ID,
+) (map[ids.NodeID][]byte, error) {
+	diffIter := s.validatorPublicKeyDiffsDB.NewIteratorWithStartAndPrefix(
+		marshalStartDiffKey(subnetID, startHeight),
+		subnetID[:],
+	)
+	defer diffIter.Release()
+
+	publicKeys := make(map[ids.NodeID][]byte)
+	for diffIter.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		_, parsedHeight, nodeID, err := unmarshalDiffKey(diffIter.Key())
+		if err != nil {
+			return nil, err
+		}
+		// If the parsedHeight is less than our target endHeight, then we have
+		// fully processed the diffs from startHeight through endHeight.
+		if parsedHeight < endHeight {
+			break
+		}
+
+		// Diffs are iterated in decreasing heights, so the last diff of a
+		// validator holds its public key prior to [endHeight].
+		publicKeys[nodeID] = slices.Clone(diffIter.Value())
+	}
+	return publicKeys, diffIter.Error()
+}
+
+func (s *state) GetValidatorDiffsStartHeight() uint64 {
+	return s.validatorDiffsStartHeight
+}
+
 func (s *state) syncGenesis(genesisBlk block.Block, genesis *genesis.Genesis) error {
 	genesisBlkID := genesisBlk.ID()
 	s.SetLastAccepted(genesisBlkID)
@@ -1275,6 +1687,8 @@ func (s *state) load() error {
 		s.loadMetadata(),
 		s.loadCurrentValidators(),
 		s.loadPendingValidators(),
+		s.loadSubnetOnlyValidators(),
+		s.loadExpiries(),
 		s.initValidatorSets(),
 	)
 }
@@ -1287,6 +1701,20 @@ func (s *state) loadMetadata() error {
 	s.persistedTimestamp = timestamp
 	s.SetTimestamp(timestamp)
 
+	feeState, err := getFeeState(s.singletonDB)
+	if err != nil {
+		return err
+	}
+	s.persistedFeeState = feeState
+	s.SetFeeState(feeState)
+
+	accruedFees, err := getAccruedFees(s.singletonDB)
+	if err != nil {
+		return err
+	}
+	s.persistedAccruedFees = accruedFees
+	s.SetAccruedFees(accruedFees)
+
 	currentSupply, err := database.GetUInt64(s.singletonDB, CurrentSupplyKey)
 	if err != nil {
 		return err
@@ -1301,6 +1729,17 @@ func (s *state) loadMetadata() error {
 	s.persistedLastAccepted = lastAccepted
 	s.lastAccepted = lastAccepted
 
+	// If the state was never synced, every validator diff is stored.
+	validatorDiffsStartHeight, err := database.GetUInt64(s.singletonDB, ValidatorDiffsStartHeightKey)
+	switch {
+	case err == nil:
+		s.validatorDiffsStartHeight = validatorDiffsStartHeight
+	case err == database.ErrNotFound:
+		s.validatorDiffsStartHeight = 0
+	default:
+		return err
+	}
+
 	// Lookup the most recently indexed range on disk. If we haven't started
 	// indexing the weights, then we keep the indexed heights as nil.
 	indexedHeightsBytes, err := s.singletonDB.Get(HeightsIndexedKey)
@@ -1586,6 +2025,44 @@ func (s *state) loadPendingValidators() error {
 
 // Invariant: initValidatorSets requires loadCurrentValidators to have already
 // been called.
+func (s *state) loadSubnetOnlyValidators() error {
+	it := s.subnetOnlyValidatorsDB.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		validationID, err := ids.ToID(it.Key())
+		if err != nil {
+			return err
+		}
+
+		sov, err := getSubnetOnlyValidator(s.subnetOnlyValidatorsDB, validationID)
+		if err != nil {
+			return err
+		}
+
+		s.subnetOnlyValidators[validationID] = sov
+		s.putSubnetOnlyValidatorID(sov)
+		if sov.IsActive() {
+			s.activeSOVs.ReplaceOrInsert(sov)
+		}
+	}
+	return it.Error()
+}
+
+func (s *state) loadExpiries() error {
+	it := s.expiryDB.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		var entry ExpiryEntry
+		if err := entry.Unmarshal(it.Key()); err != nil {
+			return err
+		}
+		s.expiries.ReplaceOrInsert(entry)
+	}
+	return it.Error()
+}
+
 func (s *state) initValidatorSets() error {
 	for subnetID, validators := range s.currentStakers.validators {
 		if s.validators.Count(subnetID) != 0 {
@@ -1611,6 +2088,18 @@ func (s *state) initValidatorSets() error {
 		}
 	}
 
+	// Subnet only validators are only included in the validator set while they
+	// are active.
+	var err error
+	s.activeSOVs.Ascend(func(sov SubnetOnlyValidator) bool {
+		pk := bls.PublicKeyFromValidUncompressedBytes(sov.PublicKey)
+		err = s.validators.AddStaker(sov.SubnetID, sov.NodeID, pk, sov.ValidationID, sov.Weight)
+		return err == nil
+	})
+	if err != nil {
+		return err
+	}
+
 	s.metrics.SetLocalStake(s.validators.GetWeight(constants.PrimaryNetworkID, s.ctx.NodeID))
 	totalWeight, err := s.validators.TotalWeight(constants.PrimaryNetworkID)
 	if err != nil {
@@ -1630,20 +2119,28 @@ func (s *state) write(updateValidators bool, height uint64) error {
 		s.writeBlocks(),
 		s.writeCurrentStakers(updateValidators, height, codecVersion),
 		s.writePendingStakers(),
+		s.writeSubnetOnlyValidators(updateValidators, height), // Must be called after writeCurrentStakers
+		s.writeExpiries(),
 		s.WriteValidatorMetadata(s.currentValidatorList, s.currentSubnetValidatorList, codecVersion), // Must be called after writeCurrentStakers
 		s.writeTXs(),
 		s.writeRewardUTXOs(),
 		s.writeUTXOs(),
 		s.writeSubnets(),
 		s.writeSubnetOwners(),
+		s.writeSubnetManagers(),
 		s.writeTransformedSubnets(),
 		s.writeSubnetSupplies(),
 		s.writeChains(),
 		s.writeMetadata(),
+		s.prepareSyncSnapshot(height), // Must be called last
 	)
 }
 
 func (s *state) Close() error {
+	// The snapshot being built must not outlive the databases it writes into.
+	s.syncSnapshotCancel()
+	s.syncSnapshotWG.Wait()
+
 	return errors.Join(
 		s.pendingSubnetValidatorBaseDB.Close(),
 		s.pendingSubnetDelegatorBaseDB.Close(),
@@ -1660,12 +2157,17 @@ func (s *state) Close() error {
 		s.rewardUTXODB.Close(),
 		s.utxoDB.Close(),
 		s.subnetBaseDB.Close(),
+		s.subnetManagerDB.Close(),
+		s.subnetOnlyValidatorsDB.Close(),
+		s.expiryDB.Close(),
 		s.transformedSubnetDB.Close(),
 		s.supplyDB.Close(),
 		s.chainDB.Close(),
 		s.singletonDB.Close(),
 		s.blockDB.Close(),
 		s.blockIDDB.Close(),
+		s.syncSnapshotDB.Close(),
+		s.syncProgressDB.Close(),
 	)
 }
 
@@ -1754,6 +2256,8 @@ func (s *state) Commit() error {
 
 func (s *state) Abort() {
 	s.baseDB.Abort()
+	// Abort is called after the committed batch has been written.
+	s.startSyncSnapshot()
 }
 
 func (s *state) Checksum() ids.ID {
@@ -2051,6 +2555,167 @@ func writeCurrentDelegatorDiff(
 	return nil
 }
 
+func (s *state) writeSubnetOnlyValidators(updateValidators bool, height uint64) error {
+	// Weight must be removed from the validator set before weight is added so
+	// that a subnetID + nodeID pair can be re-used by a new validator in the
+	// same block.
+	var (
+		decreases = make([]SubnetOnlyValidator, 0, len(s.sovDiff.modified))
+		increases = make([]SubnetOnlyValidator, 0, len(s.sovDiff.modified))
+	)
+	for validationID, sov := range s.sovDiff.modified {
+		prior := s.subnetOnlyValidators[validationID]
+		if sov.effectiveWeight() < prior.effectiveWeight() {
+			decreases = append(decreases, sov)
+		} else {
+			increases = append(increases, sov)
+		}
+	}
+	s.sovDiff = newSubnetOnlyValidatorsDiff()
+
+	for _, sov := range append(decreases, increases...) {
+		if err := s.writeSubnetOnlyValidator(updateValidators, height, sov); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *state) writeSubnetOnlyValidator(updateValidators bool, height uint64, sov SubnetOnlyValidator) error {
+	var (
+		validationID   = sov.ValidationID
+		prior, existed = s.subnetOnlyValidators[validationID]
+	)
+	if existed && prior.IsActive() {
+		s.activeSOVs.Delete(prior)
+	}
+
+	if sov.Weight == 0 {
+		if err := deleteSubnetOnlyValidator(s.subnetOnlyValidatorsDB, validationID); err != nil {
+			return fmt.Errorf("failed to delete subnet only validator: %w", err)
+		}
+		delete(s.subnetOnlyValidators, validationID)
+		s.deleteSubnetOnlyValidatorID(sov)
+	} else {
+		if err := putSubnetOnlyValidator(s.subnetOnlyValidatorsDB, sov); err != nil {
+			return fmt.Errorf("failed to write subnet only validator: %w", err)
+		}
+		s.subnetOnlyValidators[validationID] = sov
+		s.putSubnetOnlyValidatorID(sov)
+		if sov.IsActive() {
+			s.activeSOVs.ReplaceOrInsert(sov)
+		}
+	}
+
+	var (
+		priorWeight = prior.effectiveWeight()
+		newWeight   = sov.effectiveWeight()
+	)
+	if priorWeight == newWeight {
+		return nil
+	}
+
+	diffKey := marshalDiffKey(sov.SubnetID, height, sov.NodeID)
+
+	// A subnet validator may have been removed at this height as well, so any
+	// existing weight diff must be merged with this change.
+	weightDiff := &ValidatorWeightDiff{}
+	weightDiffBytes, err := s.validatorWeightDiffsDB.Get(diffKey)
+	switch err {
+	case nil:
+		weightDiff, err = unmarshalWeightDiff(weightDiffBytes)
+		if err != nil {
+			return err
+		}
+	case database.ErrNotFound:
+	default:
+		return err
+	}
+
+	if newWeight > priorWeight {
+		err = weightDiff.Add(false, newWeight-priorWeight)
+	} else {
+		err = weightDiff.Add(true, priorWeight-newWeight)
+	}
+	if err != nil {
+		return err
+	}
+
+	if weightDiff.Amount == 0 {
+		err = s.validatorWeightDiffsDB.Delete(diffKey)
+	} else {
+		err = s.validatorWeightDiffsDB.Put(diffKey, marshalWeightDiff(weightDiff))
+	}
+	if err != nil {
+		return err
+	}
+
+	// Record the public key prior to this height if the validator is being
+	// added to or removed from the validator set. If a value was already
+	// recorded at this height, it already represents the prior public key.
+	if priorWeight == 0 || newWeight == 0 {
+		hasPublicKeyDiff, err := s.validatorPublicKeyDiffsDB.Has(diffKey)
+		if err != nil {
+			return err
+		}
+		if !hasPublicKeyDiff {
+			var priorPublicKey []byte
+			if priorWeight != 0 {
+				priorPublicKey = sov.PublicKey
+			}
+			if err := s.validatorPublicKeyDiffsDB.Put(diffKey, priorPublicKey); err != nil {
+				return err
+			}
+		}
+	}
+
+	// TODO: Move the validator set management out of the state package
+	if !updateValidators {
+		return nil
+	}
+
+	switch {
+	case priorWeight == 0:
+		err = s.validators.AddStaker(
+			sov.SubnetID,
+			sov.NodeID,
+			bls.PublicKeyFromValidUncompressedBytes(sov.PublicKey),
+			validationID,
+			newWeight,
+		)
+	case newWeight > priorWeight:
+		err = s.validators.AddWeight(sov.SubnetID, sov.NodeID, newWeight-priorWeight)
+	default:
+		err = s.validators.RemoveWeight(sov.SubnetID, sov.NodeID, priorWeight-newWeight)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update subnet only validator weight: %w", err)
+	}
+	return nil
+}
+
+func (s *state) putSubnetOnlyValidatorID(sov SubnetOnlyValidator) {
+	subnetValidatorIDs, ok := s.subnetOnlyValidatorIDs[sov.SubnetID]
+	if !ok {
+		subnetValidatorIDs = make(map[ids.NodeID]ids.ID)
+		s.subnetOnlyValidatorIDs[sov.SubnetID] = subnetValidatorIDs
+	}
+	subnetValidatorIDs[sov.NodeID] = sov.ValidationID
+}
+
+// deleteSubnetOnlyValidatorID removes the subnetID+nodeID pair of [sov] unless
+// it has already been reused by a different validator.
+func (s *state) deleteSubnetOnlyValidatorID(sov SubnetOnlyValidator) {
+	subnetValidatorIDs := s.subnetOnlyValidatorIDs[sov.SubnetID]
+	if subnetValidatorIDs[sov.NodeID] != sov.ValidationID {
+		return
+	}
+	delete(subnetValidatorIDs, sov.NodeID)
+	if len(subnetValidatorIDs) == 0 {
+		delete(s.subnetOnlyValidatorIDs, sov.SubnetID)
+	}
+}
+
 func (s *state) writePendingStakers() error {
 	for subnetID, subnetValidatorDiffs := range s.pendingStakers.validatorDiffs {
 		delete(s.pendingStakers.validatorDiffs, subnetID)
@@ -2211,6 +2876,47 @@ func (s *state) writeSubnetOwners() error {
 	return nil
 }
 
+func (s *state) writeExpiries() error {
+	for entry, added := range s.modifiedExpiries {
+		delete(s.modifiedExpiries, entry)
+
+		key := entry.Marshal()
+		if !added {
+			s.expiries.Delete(entry)
+			if err := s.expiryDB.Delete(key); err != nil {
+				return fmt.Errorf("failed to delete expiry: %w", err)
+			}
+			continue
+		}
+
+		s.expiries.ReplaceOrInsert(entry)
+		if err := s.expiryDB.Put(key, nil); err != nil {
+			return fmt.Errorf("failed to write expiry: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *state) writeSubnetManagers() error {
+	for subnetID, manager := range s.subnetManagers {
+		subnetID := subnetID
+		manager := manager
+		delete(s.subnetManagers, subnetID)
+
+		managerBytes, err := block.GenesisCodec.Marshal(block.CodecVersion, &manager)
+		if err != nil {
+			return fmt.Errorf("failed to marshal subnet manager: %w", err)
+		}
+
+		s.subnetManagerCache.Put(subnetID, &manager)
+
+		if err := s.subnetManagerDB.Put(subnetID[:], managerBytes); err != nil {
+			return fmt.Errorf("failed to write subnet manager: %w", err)
+		}
+	}
+	return nil
+}
+
 func (s *state) writeTransformedSubnets() error {
 	for subnetID, tx := range s.transformedSubnets {
 		txID := tx.ID()
@@ -2261,6 +2967,18 @@ func (s *state) writeMetadata() error {
 		}
 		s.persistedTimestamp = s.timestamp
 	}
+	if s.persistedFeeState != s.feeState {
+		if err := putFeeState(s.singletonDB, s.feeState); err != nil {
+			return fmt.Errorf("failed to write fee state: %w", err)
+		}
+		s.persistedFeeState = s.feeState
+	}
+	if s.persistedAccruedFees != s.accruedFees {
+		if err := database.PutUInt64(s.singletonDB, AccruedFeesKey, s.accruedFees); err != nil {
+			return fmt.Errorf("failed to write accrued fees: %w", err)
+		}
+		s.persistedAccruedFees = s.accruedFees
+	}
 	if s.persistedCurrentSupply != s.currentSupply {
 		if err := database.PutUInt64(s.singletonDB, CurrentSupplyKey, s.currentSupply); err != nil {
 			return fmt.Errorf("failed to write current supply: %w", err)
@@ -2273,6 +2991,11 @@ func (s *state) writeMetadata() error {
 		}
 		s.persistedLastAccepted = s.lastAccepted
 	}
+	if s.checksumsEnabled {
+		if err := lux.PutChecksum(s.singletonDB, UTXOChecksumKey, s.lastAccepted, s.utxoState.Checksum()); err != nil {
+			return fmt.Errorf("failed to write utxo checksum: %w", err)
+		}
+	}
 	if s.indexedHeights != nil {
 		indexedHeightsBytes, err := block.GenesisCodec.Marshal(block.CodecVersion, s.indexedHeights)
 		if err != nil {
@@ -2285,6 +3008,42 @@ func (s *state) writeMetadata() error {
 	return nil
 }
 
+// getFeeState returns the persisted fee state. If the fee state was never
+// written, the zero value is returned.
+func getFeeState(db database.KeyValueReader) (gas.State, error) {
+	feeStateBytes, err := db.Get(FeeStateKey)
+	if err == database.ErrNotFound {
+		return gas.State{}, nil
+	}
+	if err != nil {
+		return gas.State{}, err
+	}
+
+	var feeState gas.State
+	if _, err := block.GenesisCodec.Unmarshal(feeStateBytes, &feeState); err != nil {
+		return gas.State{}, fmt.Errorf("failed to parse fee state: %w", err)
+	}
+	return feeState, nil
+}
+
+// getAccruedFees returns the persisted accrued fees. If the accrued fees were
+// never written, 0 is returned.
+func getAccruedFees(db database.KeyValueReader) (uint64, error) {
+	accruedFees, err := database.GetUInt64(db, AccruedFeesKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+	return accruedFees, err
+}
+
+func putFeeState(db database.KeyValueWriter, feeState gas.State) error {
+	feeStateBytes, err := block.GenesisCodec.Marshal(block.CodecVersion, &feeState)
+	if err != nil {
+		return fmt.Errorf("failed to serialize fee state: %w", err)
+	}
+	return db.Put(FeeStateKey, feeStateBytes)
+}
+
 // Returns the block and whether it is a [stateBlk].
 // Invariant: blkBytes is safe to parse with blocks.GenesisCodec
 //
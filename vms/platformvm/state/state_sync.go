@@ -0,0 +1,1090 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/btree"
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/linkeddb"
+	"github.com/skychains/chain/database/prefixdb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/hashing"
+	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/utils/wrappers"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/platformvm/block"
+	"github.com/skychains/chain/vms/platformvm/txs"
+)
+
+const (
+	// targetSyncChunkSize is the approximate number of bytes of entries that
+	// are included in each chunk of a snapshot.
+	targetSyncChunkSize = 512 * units.KiB
+
+	// syncEntryOverhead is the number of bytes, in addition to the key and
+	// value, used to serialize a [syncEntry].
+	syncEntryOverhead = wrappers.ByteLen + 2*wrappers.IntLen
+
+	// syncValidatorDiffsLookback is the number of heights, ending at the
+	// summary height, whose validator diffs are included in a snapshot. This
+	// bounds how far in the past a synced node is able to calculate
+	// validator sets.
+	syncValidatorDiffsLookback = 1 << 16
+)
+
+// The namespaces of the entries included in a snapshot. Each namespace maps
+// to the database the entry is written into when the snapshot is applied.
+const (
+	syncMetadataNamespace byte = iota
+	syncTxNamespace
+	syncUTXONamespace
+	syncCurrentValidatorNamespace
+	syncCurrentDelegatorNamespace
+	syncCurrentSubnetValidatorNamespace
+	syncCurrentSubnetDelegatorNamespace
+	syncPendingValidatorNamespace
+	syncPendingDelegatorNamespace
+	syncPendingSubnetValidatorNamespace
+	syncPendingSubnetDelegatorNamespace
+	syncValidatorWeightDiffNamespace
+	syncValidatorPublicKeyDiffNamespace
+	syncSubnetNamespace
+	syncSubnetOwnerNamespace
+	syncSubnetManagerNamespace
+	syncSubnetOnlyValidatorNamespace
+	syncTransformedSubnetNamespace
+	syncSupplyNamespace
+	syncChainNamespace
+	syncExpiryNamespace
+)
+
+var (
+	ErrInvalidSyncChunk = errors.New("invalid sync chunk")
+
+	errMissingSyncChunks         = errors.New("missing sync chunks")
+	errUnexpectedSyncNamespace   = errors.New("unexpected sync namespace")
+	errUnexpectedSyncMetadataKey = errors.New("unexpected sync metadata key")
+	errUnexpectedSyncChainKey    = errors.New("unexpected sync chain key")
+	errSyncBlockHeightMismatch   = errors.New("sync summary block height doesn't match the summary height")
+	errSyncBlockMismatch         = errors.New("sync summary block doesn't match the synced last accepted block")
+	errSyncSnapshotReadOnly      = errors.New("sync snapshot is read-only")
+
+	syncSummaryKey = []byte("summary")
+
+	// syncedMetadataKeys are the singletons that are included in a snapshot.
+	// The remaining singletons describe the local database rather than the
+	// chain state.
+	syncedMetadataKeys = [][]byte{
+		TimestampKey,
+		FeeStateKey,
+		AccruedFeesKey,
+		CurrentSupplyKey,
+		LastAcceptedKey,
+		InitializedKey,
+	}
+)
+
+// SyncSummary describes a snapshot of the state at an accepted height. The
+// snapshot is split into chunks whose hashes are committed to by the summary,
+// so that every chunk can be verified as soon as it is received.
+type SyncSummary struct {
+	Height uint64 `serialize:"true"`
+	// Block is the accepted block at [Height].
+	Block       []byte   `serialize:"true"`
+	ChunkHashes []ids.ID `serialize:"true"`
+
+	id    ids.ID
+	bytes []byte
+}
+
+func NewSyncSummary(height uint64, blk []byte, chunkHashes []ids.ID) (*SyncSummary, error) {
+	summary := &SyncSummary{
+		Height:      height,
+		Block:       blk,
+		ChunkHashes: chunkHashes,
+	}
+	bytes, err := block.GenesisCodec.Marshal(block.CodecVersion, summary)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal sync summary: %w", err)
+	}
+	summary.initialize(bytes)
+	return summary, nil
+}
+
+// ParseSyncSummary parses a summary. The block included in the summary is not
+// parsed.
+//
+// Note: The size of the summary is bounded by the message it was received in,
+// so the genesis codec is used to allow summaries of large snapshots.
+func ParseSyncSummary(bytes []byte) (*SyncSummary, error) {
+	summary := &SyncSummary{}
+	if _, err := block.GenesisCodec.Unmarshal(bytes, summary); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sync summary: %w", err)
+	}
+	summary.initialize(bytes)
+	return summary, nil
+}
+
+func (s *SyncSummary) initialize(bytes []byte) {
+	s.id = hashing.ComputeHash256Array(bytes)
+	s.bytes = bytes
+}
+
+func (s *SyncSummary) ID() ids.ID {
+	return s.id
+}
+
+func (s *SyncSummary) Bytes() []byte {
+	return s.bytes
+}
+
+// syncEntry is a key-value pair of a snapshot.
+type syncEntry struct {
+	Namespace byte   `serialize:"true"`
+	Key       []byte `serialize:"true"`
+	Value     []byte `serialize:"true"`
+}
+
+func compareSyncEntryKeys(a, b syncEntry) int {
+	return bytes.Compare(a.Key, b.Key)
+}
+
+// syncChunkWriter groups the entries of a snapshot into chunks and writes the
+// chunks into [db].
+type syncChunkWriter struct {
+	ctx         context.Context
+	db          database.KeyValueWriter
+	entries     []syncEntry
+	size        int
+	chunkHashes []ids.ID
+}
+
+func (w *syncChunkWriter) add(entry syncEntry) error {
+	w.entries = append(w.entries, entry)
+	w.size += syncEntryOverhead + len(entry.Key) + len(entry.Value)
+	if w.size < targetSyncChunkSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *syncChunkWriter) addAll(entries []syncEntry) error {
+	for _, entry := range entries {
+		if err := w.add(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDB adds every entry of [db] whose key is accepted by [include]. If
+// [include] is nil, every entry is added.
+func (w *syncChunkWriter) addDB(namespace byte, db database.Iteratee, include func(key []byte) bool) error {
+	it := db.NewIterator()
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if include != nil && !include(key) {
+			continue
+		}
+		err := w.add(syncEntry{
+			Namespace: namespace,
+			Key:       slices.Clone(key),
+			Value:     slices.Clone(it.Value()),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return it.Error()
+}
+
+func (w *syncChunkWriter) flush() error {
+	if len(w.entries) == 0 {
+		return nil
+	}
+	if err := w.ctx.Err(); err != nil {
+		return err
+	}
+
+	chunk, err := block.GenesisCodec.Marshal(block.CodecVersion, &w.entries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal sync chunk: %w", err)
+	}
+
+	index := uint64(len(w.chunkHashes))
+	w.chunkHashes = append(w.chunkHashes, hashing.ComputeHash256Array(chunk))
+	w.entries = nil
+	w.size = 0
+	return w.db.Put(database.PackUInt64(index), chunk)
+}
+
+func parseSyncChunk(chunk []byte) ([]syncEntry, error) {
+	var entries []syncEntry
+	if _, err := block.GenesisCodec.Unmarshal(chunk, &entries); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sync chunk: %w", err)
+	}
+	return entries, nil
+}
+
+// readLinkedDB returns the entries of [db] ordered from the least to the most
+// recently inserted. Because entries are inserted at the head of a linkeddb,
+// re-inserting the entries in this order reproduces the iteration order of
+// [db].
+func readLinkedDB(namespace byte, db linkeddb.LinkedDB) ([]syncEntry, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var entries []syncEntry
+	for it.Next() {
+		entries = append(entries, syncEntry{
+			Namespace: namespace,
+			Key:       slices.Clone(it.Key()),
+			Value:     slices.Clone(it.Value()),
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+func (s *state) GetSyncSummary() (*SyncSummary, error) {
+	return getSyncSummary(s.syncSnapshotDB)
+}
+
+func (s *state) GetSyncChunk(height uint64, index uint64) ([]byte, error) {
+	summary, err := s.GetSyncSummary()
+	if err != nil {
+		return nil, err
+	}
+	if summary.Height != height {
+		return nil, database.ErrNotFound
+	}
+	chunk, err := s.syncSnapshotDB.Get(database.PackUInt64(index))
+	if err != nil {
+		return nil, err
+	}
+
+	// The snapshot may have been replaced while the chunk was being read.
+	summary, err = s.GetSyncSummary()
+	if err != nil {
+		return nil, err
+	}
+	if summary.Height != height {
+		return nil, database.ErrNotFound
+	}
+	return chunk, nil
+}
+
+func (s *state) GetOngoingSyncSummary() (*SyncSummary, error) {
+	return getSyncSummary(s.syncProgressDB)
+}
+
+func (s *state) SetOngoingSyncSummary(summary *SyncSummary) error {
+	ongoing, err := s.GetOngoingSyncSummary()
+	switch {
+	case err == nil && ongoing.ID() == summary.ID():
+		// The chunks that were already fetched are still valid.
+		return nil
+	case err != nil && err != database.ErrNotFound:
+		return err
+	}
+
+	if err := database.AtomicClear(s.syncProgressDB, s.syncProgressDB); err != nil {
+		return err
+	}
+	if err := s.syncProgressDB.Put(syncSummaryKey, summary.Bytes()); err != nil {
+		return err
+	}
+	return s.baseDB.Commit()
+}
+
+func (s *state) GetMissingSyncChunks() ([]uint64, error) {
+	summary, err := s.GetOngoingSyncSummary()
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []uint64
+	for i := range summary.ChunkHashes {
+		index := uint64(i)
+		has, err := s.syncProgressDB.Has(database.PackUInt64(index))
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			missing = append(missing, index)
+		}
+	}
+	return missing, nil
+}
+
+func (s *state) PutSyncChunk(index uint64, chunk []byte) error {
+	summary, err := s.GetOngoingSyncSummary()
+	if err != nil {
+		return err
+	}
+	if index >= uint64(len(summary.ChunkHashes)) {
+		return fmt.Errorf("%w: index %d >= %d", ErrInvalidSyncChunk, index, len(summary.ChunkHashes))
+	}
+	if hash := hashing.ComputeHash256Array(chunk); hash != summary.ChunkHashes[index] {
+		return fmt.Errorf("%w: hash %s != %s", ErrInvalidSyncChunk, hash, summary.ChunkHashes[index])
+	}
+
+	if err := s.syncProgressDB.Put(database.PackUInt64(index), chunk); err != nil {
+		return err
+	}
+	return s.baseDB.Commit()
+}
+
+func (s *state) FinishSync() error {
+	summary, err := s.GetOngoingSyncSummary()
+	if err != nil {
+		return err
+	}
+	missing, err := s.GetMissingSyncChunks()
+	if err != nil {
+		return err
+	}
+	if len(missing) != 0 {
+		return fmt.Errorf("%w: %d of %d", errMissingSyncChunks, len(missing), len(summary.ChunkHashes))
+	}
+
+	blk, err := block.Parse(block.GenesisCodec, summary.Block)
+	if err != nil {
+		return err
+	}
+	if blk.Height() != summary.Height {
+		return fmt.Errorf("%w: %d != %d", errSyncBlockHeightMismatch, blk.Height(), summary.Height)
+	}
+
+	if err := s.applySyncSnapshot(summary, blk); err != nil {
+		s.Abort()
+		return err
+	}
+
+	// Remove the validators of the replaced state from the validator manager
+	// so that the synced validators can be loaded.
+	var subnetIDs set.Set[ids.ID]
+	for subnetID := range s.currentStakers.validators {
+		subnetIDs.Add(subnetID)
+	}
+	s.activeSOVs.Ascend(func(sov SubnetOnlyValidator) bool {
+		subnetIDs.Add(sov.SubnetID)
+		return true
+	})
+	for subnetID := range subnetIDs {
+		for nodeID, vdr := range s.validators.GetMap(subnetID) {
+			if err := s.validators.RemoveWeight(subnetID, nodeID, vdr.Weight); err != nil {
+				return err
+			}
+		}
+	}
+
+	s.validatorState = newValidatorState()
+	s.sovDiff = newSubnetOnlyValidatorsDiff()
+	s.subnetOnlyValidators = make(map[ids.ID]SubnetOnlyValidator)
+	s.subnetOnlyValidatorIDs = make(map[ids.ID]map[ids.NodeID]ids.ID)
+	s.activeSOVs = btree.NewG(defaultTreeDegree, SubnetOnlyValidator.Less)
+	s.modifiedExpiries = make(map[ExpiryEntry]bool)
+	s.expiries = btree.NewG(defaultTreeDegree, ExpiryEntry.Less)
+	s.cachedSubnetIDs = nil
+	s.indexedHeights = nil
+	for _, c := range []interface{ Flush() }{
+		s.blockIDCache,
+		s.blockCache,
+		s.txCache,
+		s.rewardUTXOsCache,
+		s.subnetOwnerCache,
+		s.subnetManagerCache,
+		s.transformedSubnetCache,
+		s.supplyCache,
+		s.chainCache,
+		s.chainDBCache,
+	} {
+		c.Flush()
+	}
+	return s.load()
+}
+
+// applySyncSnapshot replaces the chain state in the database with the staged
+// snapshot and commits the result.
+func (s *state) applySyncSnapshot(summary *SyncSummary, blk block.Block) error {
+	if err := s.clearSyncedState(); err != nil {
+		return err
+	}
+
+	for i := range summary.ChunkHashes {
+		chunk, err := s.syncProgressDB.Get(database.PackUInt64(uint64(i)))
+		if err != nil {
+			return err
+		}
+		entries, err := parseSyncChunk(chunk)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := s.applySyncEntry(entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	blkID := blk.ID()
+	lastAccepted, err := database.GetID(s.singletonDB, LastAcceptedKey)
+	if err != nil {
+		return err
+	}
+	if lastAccepted != blkID {
+		return fmt.Errorf("%w: %s != %s", errSyncBlockMismatch, blkID, lastAccepted)
+	}
+	if err := database.PutID(s.blockIDDB, database.PackUInt64(blk.Height()), blkID); err != nil {
+		return err
+	}
+	if err := s.blockDB.Put(blkID[:], blk.Bytes()); err != nil {
+		return err
+	}
+	// The synced validator diffs don't cover the heights before the snapshot's
+	// lookback.
+	err = database.PutUInt64(s.singletonDB, ValidatorDiffsStartHeightKey, syncValidatorDiffsStartHeight(summary.Height))
+	if err != nil {
+		return err
+	}
+
+	if err := database.AtomicClear(s.syncProgressDB, s.syncProgressDB); err != nil {
+		return err
+	}
+	return s.baseDB.Commit()
+}
+
+// clearSyncedState removes all the data that is replaced by a snapshot.
+// Historical data, such as blocks, transactions, and reward UTXOs, is kept.
+func (s *state) clearSyncedState() error {
+	utxoIDs, err := readKeys(prefixdb.New(lux.UTXOPrefix, s.utxoDB))
+	if err != nil {
+		return err
+	}
+	for _, utxoIDBytes := range utxoIDs {
+		utxoID, err := ids.ToID(utxoIDBytes)
+		if err != nil {
+			return err
+		}
+		if err := s.utxoState.DeleteUTXO(utxoID); err != nil {
+			return err
+		}
+	}
+
+	for _, db := range []database.Database{
+		s.validatorsDB,
+		s.subnetBaseDB,
+		s.subnetOwnerDB,
+		s.subnetManagerDB,
+		s.subnetOnlyValidatorsDB,
+		s.expiryDB,
+		s.transformedSubnetDB,
+		s.supplyDB,
+		s.chainDB,
+	} {
+		if err := database.AtomicClear(db, db); err != nil {
+			return err
+		}
+	}
+	for _, key := range syncedMetadataKeys {
+		if err := s.singletonDB.Delete(key); err != nil {
+			return err
+		}
+	}
+	// The synced validator diffs don't cover the previously indexed heights.
+	if err := s.singletonDB.Delete(HeightsIndexedKey); err != nil {
+		return err
+	}
+
+	// The linkeddbs cache their contents, so they must be recreated after
+	// their databases were cleared.
+	s.currentValidatorList = linkeddb.NewDefault(s.currentValidatorBaseDB)
+	s.currentDelegatorList = linkeddb.NewDefault(s.currentDelegatorBaseDB)
+	s.currentSubnetValidatorList = linkeddb.NewDefault(s.currentSubnetValidatorBaseDB)
+	s.currentSubnetDelegatorList = linkeddb.NewDefault(s.currentSubnetDelegatorBaseDB)
+	s.pendingValidatorList = linkeddb.NewDefault(s.pendingValidatorBaseDB)
+	s.pendingDelegatorList = linkeddb.NewDefault(s.pendingDelegatorBaseDB)
+	s.pendingSubnetValidatorList = linkeddb.NewDefault(s.pendingSubnetValidatorBaseDB)
+	s.pendingSubnetDelegatorList = linkeddb.NewDefault(s.pendingSubnetDelegatorBaseDB)
+	s.subnetDB = linkeddb.NewDefault(s.subnetBaseDB)
+	s.chainDBCache.Flush()
+	return nil
+}
+
+func (s *state) applySyncEntry(entry syncEntry) error {
+	switch entry.Namespace {
+	case syncMetadataNamespace:
+		if !slices.ContainsFunc(syncedMetadataKeys, func(key []byte) bool {
+			return bytes.Equal(key, entry.Key)
+		}) {
+			return fmt.Errorf("%w: %q", errUnexpectedSyncMetadataKey, entry.Key)
+		}
+		return s.singletonDB.Put(entry.Key, entry.Value)
+	case syncUTXONamespace:
+		utxo := &lux.UTXO{}
+		if _, err := txs.GenesisCodec.Unmarshal(entry.Value, utxo); err != nil {
+			return fmt.Errorf("failed to unmarshal UTXO: %w", err)
+		}
+		return s.utxoState.PutUTXO(utxo)
+	case syncChainNamespace:
+		if len(entry.Key) != 2*ids.IDLen {
+			return fmt.Errorf("%w: length %d", errUnexpectedSyncChainKey, len(entry.Key))
+		}
+		subnetID, err := ids.ToID(entry.Key[:ids.IDLen])
+		if err != nil {
+			return err
+		}
+		return s.getChainDB(subnetID).Put(entry.Key[ids.IDLen:], entry.Value)
+	}
+
+	db, ok := s.syncWriters()[entry.Namespace]
+	if !ok {
+		return fmt.Errorf("%w: %d", errUnexpectedSyncNamespace, entry.Namespace)
+	}
+	return db.Put(entry.Key, entry.Value)
+}
+
+// syncWriters returns the databases that the entries of each namespace are
+// written into, excluding the namespaces that require special handling.
+func (s *state) syncWriters() map[byte]database.KeyValueWriter {
+	return map[byte]database.KeyValueWriter{
+		syncTxNamespace:                     s.txDB,
+		syncCurrentValidatorNamespace:       s.currentValidatorList,
+		syncCurrentDelegatorNamespace:       s.currentDelegatorList,
+		syncCurrentSubnetValidatorNamespace: s.currentSubnetValidatorList,
+		syncCurrentSubnetDelegatorNamespace: s.currentSubnetDelegatorList,
+		syncPendingValidatorNamespace:       s.pendingValidatorList,
+		syncPendingDelegatorNamespace:       s.pendingDelegatorList,
+		syncPendingSubnetValidatorNamespace: s.pendingSubnetValidatorList,
+		syncPendingSubnetDelegatorNamespace: s.pendingSubnetDelegatorList,
+		syncValidatorWeightDiffNamespace:    s.validatorWeightDiffsDB,
+		syncValidatorPublicKeyDiffNamespace: s.validatorPublicKeyDiffsDB,
+		syncSubnetNamespace:                 s.subnetDB,
+		syncSubnetOwnerNamespace:            s.subnetOwnerDB,
+		syncSubnetManagerNamespace:          s.subnetManagerDB,
+		syncSubnetOnlyValidatorNamespace:    s.subnetOnlyValidatorsDB,
+		syncExpiryNamespace:                 s.expiryDB,
+		syncTransformedSubnetNamespace:      s.transformedSubnetDB,
+		syncSupplyNamespace:                 s.supplyDB,
+	}
+}
+
+// prepareSyncSnapshot records that a snapshot of the state at [height] should
+// be built, if a summary should be produced at [height]. The snapshot is built
+// once the state has been committed.
+func (s *state) prepareSyncSnapshot(height uint64) error {
+	if s.syncSummaryFrequency == 0 || height == 0 || height%s.syncSummaryFrequency != 0 {
+		return nil
+	}
+
+	switch summary, err := s.GetSyncSummary(); {
+	case err == nil && summary.Height == height:
+		// The snapshot was already produced.
+		return nil
+	case err != nil && err != database.ErrNotFound:
+		return err
+	}
+
+	blk, err := s.GetStatelessBlock(s.lastAccepted)
+	if err != nil {
+		return err
+	}
+	if blk.Height() != height {
+		return fmt.Errorf("%w: %d != %d", errSyncBlockHeightMismatch, blk.Height(), height)
+	}
+	s.pendingSyncSnapshot = blk
+	return nil
+}
+
+// startSyncSnapshot starts building the snapshot recorded by
+// [prepareSyncSnapshot]. The snapshot is read from a snapshot of the
+// underlying database, so that it can be built without blocking the
+// acceptance of blocks. If the underlying database doesn't support snapshots,
+// the snapshot is built synchronously.
+//
+// Invariant: The state must have been committed to the underlying database
+// before calling this function.
+func (s *state) startSyncSnapshot() {
+	blk := s.pendingSyncSnapshot
+	if blk == nil {
+		return
+	}
+	s.pendingSyncSnapshot = nil
+
+	if !s.buildingSyncSnapshot.CompareAndSwap(false, true) {
+		s.ctx.Log.Info("skipping state sync snapshot",
+			zap.String("reason", "previous snapshot is still being built"),
+			zap.Uint64("height", blk.Height()),
+		)
+		return
+	}
+
+	snapshot, err := newDBSnapshot(s.db)
+	if errors.Is(err, errors.ErrUnsupported) {
+		defer s.buildingSyncSnapshot.Store(false)
+
+		if err := s.buildSyncSnapshot(s.syncSnapshotCtx, s.db, blk); err != nil {
+			s.ctx.Log.Warn("failed to build state sync snapshot",
+				zap.Uint64("height", blk.Height()),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+	if err != nil {
+		s.buildingSyncSnapshot.Store(false)
+		s.ctx.Log.Warn("failed to snapshot database for state sync",
+			zap.Uint64("height", blk.Height()),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.syncSnapshotWG.Add(1)
+	go func() {
+		defer func() {
+			snapshot.Release()
+			s.buildingSyncSnapshot.Store(false)
+			s.syncSnapshotWG.Done()
+		}()
+
+		if err := s.buildSyncSnapshot(s.syncSnapshotCtx, &snapshotDB{Snapshot: snapshot}, blk); err != nil {
+			s.ctx.Log.Warn("failed to build state sync snapshot",
+				zap.Uint64("height", blk.Height()),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// buildSyncSnapshot replaces the stored snapshot with a snapshot of the state
+// in [db], which must have [blk] as its last accepted block.
+//
+// The summary is removed before, and written after, the chunks are replaced so
+// that chunks are never served for a summary they don't belong to.
+func (s *state) buildSyncSnapshot(ctx context.Context, db database.Database, blk block.Block) error {
+	source := newSyncSource(db)
+	lastAccepted, err := database.GetID(source.singletonDB, LastAcceptedKey)
+	if err != nil {
+		return err
+	}
+	blkID := blk.ID()
+	if lastAccepted != blkID {
+		return fmt.Errorf("%w: %s != %s", errSyncBlockMismatch, blkID, lastAccepted)
+	}
+
+	if err := database.AtomicClear(s.syncSnapshotDB, s.syncSnapshotDB); err != nil {
+		return err
+	}
+
+	w := &syncChunkWriter{
+		ctx: ctx,
+		db:  s.syncSnapshotDB,
+	}
+	height := blk.Height()
+	if err := source.writeSyncEntries(w, height); err != nil {
+		return err
+	}
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	summary, err := NewSyncSummary(height, blk.Bytes(), w.chunkHashes)
+	if err != nil {
+		return err
+	}
+	return s.syncSnapshotDB.Put(syncSummaryKey, summary.Bytes())
+}
+
+// syncSource contains the databases that a snapshot is read from. It mirrors
+// the layout of the state's databases on top of [newSyncSource]'s database.
+type syncSource struct {
+	singletonDB database.Database
+	txDB        database.Database
+	utxoDB      database.Database
+
+	currentValidatorList       linkeddb.LinkedDB
+	currentDelegatorList       linkeddb.LinkedDB
+	currentSubnetValidatorList linkeddb.LinkedDB
+	currentSubnetDelegatorList linkeddb.LinkedDB
+	pendingValidatorList       linkeddb.LinkedDB
+	pendingDelegatorList       linkeddb.LinkedDB
+	pendingSubnetValidatorList linkeddb.LinkedDB
+	pendingSubnetDelegatorList linkeddb.LinkedDB
+	validatorWeightDiffsDB     database.Database
+	validatorPublicKeyDiffsDB  database.Database
+
+	subnetDB               linkeddb.LinkedDB
+	subnetOwnerDB          database.Database
+	subnetManagerDB        database.Database
+	subnetOnlyValidatorsDB database.Database
+	expiryDB               database.Database
+	transformedSubnetDB    database.Database
+	supplyDB               database.Database
+	chainDB                database.Database
+}
+
+func newSyncSource(db database.Database) *syncSource {
+	validatorsDB := prefixdb.New(ValidatorsPrefix, db)
+	currentValidatorsDB := prefixdb.New(CurrentPrefix, validatorsDB)
+	pendingValidatorsDB := prefixdb.New(PendingPrefix, validatorsDB)
+	return &syncSource{
+		singletonDB: prefixdb.New(SingletonPrefix, db),
+		txDB:        prefixdb.New(TxPrefix, db),
+		utxoDB:      prefixdb.New(UTXOPrefix, db),
+
+		currentValidatorList:       linkeddb.NewDefault(prefixdb.New(ValidatorPrefix, currentValidatorsDB)),
+		currentDelegatorList:       linkeddb.NewDefault(prefixdb.New(DelegatorPrefix, currentValidatorsDB)),
+		currentSubnetValidatorList: linkeddb.NewDefault(prefixdb.New(SubnetValidatorPrefix, currentValidatorsDB)),
+		currentSubnetDelegatorList: linkeddb.NewDefault(prefixdb.New(SubnetDelegatorPrefix, currentValidatorsDB)),
+		pendingValidatorList:       linkeddb.NewDefault(prefixdb.New(ValidatorPrefix, pendingValidatorsDB)),
+		pendingDelegatorList:       linkeddb.NewDefault(prefixdb.New(DelegatorPrefix, pendingValidatorsDB)),
+		pendingSubnetValidatorList: linkeddb.NewDefault(prefixdb.New(SubnetValidatorPrefix, pendingValidatorsDB)),
+		pendingSubnetDelegatorList: linkeddb.NewDefault(prefixdb.New(SubnetDelegatorPrefix, pendingValidatorsDB)),
+		validatorWeightDiffsDB:     prefixdb.New(ValidatorWeightDiffsPrefix, validatorsDB),
+		validatorPublicKeyDiffsDB:  prefixdb.New(ValidatorPublicKeyDiffsPrefix, validatorsDB),
+
+		subnetDB:               linkeddb.NewDefault(prefixdb.New(SubnetPrefix, db)),
+		subnetOwnerDB:          prefixdb.New(SubnetOwnerPrefix, db),
+		subnetManagerDB:        prefixdb.New(SubnetManagerPrefix, db),
+		subnetOnlyValidatorsDB: prefixdb.New(SubnetOnlyValidatorsPrefix, db),
+		expiryDB:               prefixdb.New(ExpiryPrefix, db),
+		transformedSubnetDB:    prefixdb.New(TransformedSubnetPrefix, db),
+		supplyDB:               prefixdb.New(SupplyPrefix, db),
+		chainDB:                prefixdb.New(ChainPrefix, db),
+	}
+}
+
+func (s *syncSource) getChainDB(subnetID ids.ID) linkeddb.LinkedDB {
+	return linkeddb.NewDefault(prefixdb.New(subnetID[:], s.chainDB))
+}
+
+func (s *syncSource) getTx(txID ids.ID) (*txs.Tx, error) {
+	txBytes, err := s.txDB.Get(txID[:])
+	if err != nil {
+		return nil, err
+	}
+
+	stx := txBytesAndStatus{}
+	if _, err := txs.GenesisCodec.Unmarshal(txBytes, &stx); err != nil {
+		return nil, err
+	}
+	return txs.Parse(txs.GenesisCodec, stx.Tx)
+}
+
+// writeSyncEntries adds all the entries of the snapshot at [height] to [w].
+// The entries must be produced deterministically so that every node produces
+// the same summary at [height].
+func (s *syncSource) writeSyncEntries(w *syncChunkWriter, height uint64) error {
+	for _, key := range syncedMetadataKeys {
+		value, err := s.singletonDB.Get(key)
+		if err == database.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = w.add(syncEntry{
+			Namespace: syncMetadataNamespace,
+			Key:       key,
+			Value:     value,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Stakers are inserted into the lists in a non-deterministic order, so
+	// they are sorted.
+	stakerLists := []struct {
+		namespace byte
+		list      linkeddb.LinkedDB
+	}{
+		{syncCurrentValidatorNamespace, s.currentValidatorList},
+		{syncCurrentDelegatorNamespace, s.currentDelegatorList},
+		{syncCurrentSubnetValidatorNamespace, s.currentSubnetValidatorList},
+		{syncCurrentSubnetDelegatorNamespace, s.currentSubnetDelegatorList},
+		{syncPendingValidatorNamespace, s.pendingValidatorList},
+		{syncPendingDelegatorNamespace, s.pendingDelegatorList},
+		{syncPendingSubnetValidatorNamespace, s.pendingSubnetValidatorList},
+		{syncPendingSubnetDelegatorNamespace, s.pendingSubnetDelegatorList},
+	}
+	var (
+		stakerEntries []syncEntry
+		txIDs         set.Set[ids.ID]
+	)
+	for _, stakers := range stakerLists {
+		entries, err := readLinkedDB(stakers.namespace, stakers.list)
+		if err != nil {
+			return err
+		}
+		slices.SortFunc(entries, compareSyncEntryKeys)
+
+		if stakers.namespace == syncCurrentValidatorNamespace || stakers.namespace == syncCurrentSubnetValidatorNamespace {
+			if err := s.normalizeSyncedValidatorMetadata(entries); err != nil {
+				return err
+			}
+		}
+		for _, entry := range entries {
+			txID, err := ids.ToID(entry.Key)
+			if err != nil {
+				return err
+			}
+			txIDs.Add(txID)
+		}
+		stakerEntries = append(stakerEntries, entries...)
+	}
+
+	subnetEntries, err := readLinkedDB(syncSubnetNamespace, s.subnetDB)
+	if err != nil {
+		return err
+	}
+	subnetIDs := []ids.ID{constants.PrimaryNetworkID}
+	for _, entry := range subnetEntries {
+		subnetID, err := ids.ToID(entry.Key)
+		if err != nil {
+			return err
+		}
+		txIDs.Add(subnetID)
+		subnetIDs = append(subnetIDs, subnetID)
+	}
+
+	var chainEntries []syncEntry
+	for _, subnetID := range subnetIDs {
+		entries, err := readLinkedDB(syncChainNamespace, s.getChainDB(subnetID))
+		if err != nil {
+			return err
+		}
+		for i, entry := range entries {
+			chainID, err := ids.ToID(entry.Key)
+			if err != nil {
+				return err
+			}
+			txIDs.Add(chainID)
+			key := make([]byte, 2*ids.IDLen)
+			copy(key, subnetID[:])
+			copy(key[ids.IDLen:], chainID[:])
+			entries[i].Key = key
+		}
+		chainEntries = append(chainEntries, entries...)
+	}
+
+	transformedSubnetTxIDs, err := readValues(s.transformedSubnetDB)
+	if err != nil {
+		return err
+	}
+	for _, txIDBytes := range transformedSubnetTxIDs {
+		txID, err := ids.ToID(txIDBytes)
+		if err != nil {
+			return err
+		}
+		txIDs.Add(txID)
+	}
+
+	sortedTxIDs := txIDs.List()
+	utils.Sort(sortedTxIDs)
+	for _, txID := range sortedTxIDs {
+		txBytes, err := s.txDB.Get(txID[:])
+		if err != nil {
+			return fmt.Errorf("failed to get tx %s: %w", txID, err)
+		}
+		err = w.add(syncEntry{
+			Namespace: syncTxNamespace,
+			Key:       txID[:],
+			Value:     txBytes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := w.addDB(syncUTXONamespace, prefixdb.New(lux.UTXOPrefix, s.utxoDB), nil); err != nil {
+		return err
+	}
+	if err := w.addAll(stakerEntries); err != nil {
+		return err
+	}
+
+	minDiffHeight := syncValidatorDiffsStartHeight(height)
+	includeDiff := func(key []byte) bool {
+		_, diffHeight, _, err := unmarshalDiffKey(key)
+		return err == nil && diffHeight >= minDiffHeight
+	}
+	if err := w.addDB(syncValidatorWeightDiffNamespace, s.validatorWeightDiffsDB, includeDiff); err != nil {
+		return err
+	}
+	if err := w.addDB(syncValidatorPublicKeyDiffNamespace, s.validatorPublicKeyDiffsDB, includeDiff); err != nil {
+		return err
+	}
+
+	if err := w.addAll(subnetEntries); err != nil {
+		return err
+	}
+	rawDBs := []struct {
+		namespace byte
+		db        database.Iteratee
+	}{
+		{syncSubnetOwnerNamespace, s.subnetOwnerDB},
+		{syncSubnetManagerNamespace, s.subnetManagerDB},
+		{syncSubnetOnlyValidatorNamespace, s.subnetOnlyValidatorsDB},
+		{syncExpiryNamespace, s.expiryDB},
+		{syncTransformedSubnetNamespace, s.transformedSubnetDB},
+		{syncSupplyNamespace, s.supplyDB},
+	}
+	for _, raw := range rawDBs {
+		if err := w.addDB(raw.namespace, raw.db, nil); err != nil {
+			return err
+		}
+	}
+	return w.addAll(chainEntries)
+}
+
+// normalizeSyncedValidatorMetadata removes the locally measured uptimes from
+// the validator metadata [entries]. Uptimes differ between nodes, so including
+// them would prevent nodes from agreeing on the summary. Synced nodes instead
+// assume that every validator has been online since it started validating.
+func (s *syncSource) normalizeSyncedValidatorMetadata(entries []syncEntry) error {
+	for i, entry := range entries {
+		txID, err := ids.ToID(entry.Key)
+		if err != nil {
+			return err
+		}
+		tx, err := s.getTx(txID)
+		if err != nil {
+			return fmt.Errorf("failed to get validator tx %s: %w", txID, err)
+		}
+
+		metadata := &validatorMetadata{
+			txID: txID,
+		}
+		if scheduledStakerTx, ok := tx.Unsigned.(txs.ScheduledStaker); ok {
+			metadata.StakerStartTime = uint64(scheduledStakerTx.StartTime().Unix())
+		}
+		if err := parseValidatorMetadata(entry.Value, metadata); err != nil {
+			return err
+		}
+
+		metadata.UpDuration = 0
+		metadata.LastUpdated = metadata.StakerStartTime
+		entries[i].Value, err = MetadataCodec.Marshal(CodecVersion1, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to marshal validator metadata: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncValidatorDiffsStartHeight returns the lowest height whose validator
+// diffs are included in the snapshot at [height].
+func syncValidatorDiffsStartHeight(height uint64) uint64 {
+	if height < syncValidatorDiffsLookback {
+		return 0
+	}
+	return height - syncValidatorDiffsLookback + 1
+}
+
+// snapshotDB exposes a database snapshot as a read-only database, so that the
+// state's database layout can be reconstructed on top of the snapshot.
+type snapshotDB struct {
+	database.Snapshot
+}
+
+func (*snapshotDB) Put([]byte, []byte) error {
+	return errSyncSnapshotReadOnly
+}
+
+func (*snapshotDB) Delete([]byte) error {
+	return errSyncSnapshotReadOnly
+}
+
+func (*snapshotDB) DeleteRange([]byte, []byte) error {
+	return errSyncSnapshotReadOnly
+}
+
+func (*snapshotDB) NewBatch() database.Batch {
+	return &snapshotBatch{}
+}
+
+func (*snapshotDB) Compact([]byte, []byte) error {
+	return nil
+}
+
+func (db *snapshotDB) Close() error {
+	db.Release()
+	return nil
+}
+
+func (*snapshotDB) HealthCheck(context.Context) (interface{}, error) {
+	return nil, nil
+}
+
+// snapshotBatch is a batch of a [snapshotDB], which can never be written.
+type snapshotBatch struct {
+	database.BatchOps
+}
+
+func (*snapshotBatch) Write() error {
+	return errSyncSnapshotReadOnly
+}
+
+func (b *snapshotBatch) Inner() database.Batch {
+	return b
+}
+
+// newDBSnapshot returns a snapshot of [db]. If [db] doesn't support snapshots,
+// errors.ErrUnsupported is returned.
+func newDBSnapshot(db database.Database) (database.Snapshot, error) {
+	snapshotter, ok := db.(database.Snapshotter)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	return snapshotter.NewSnapshot()
+}
+
+func getSyncSummary(db database.KeyValueReader) (*SyncSummary, error) {
+	bytes, err := db.Get(syncSummaryKey)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSyncSummary(bytes)
+}
+
+func readKeys(db database.Iteratee) ([][]byte, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var keys [][]byte
+	for it.Next() {
+		keys = append(keys, slices.Clone(it.Key()))
+	}
+	return keys, it.Error()
+}
+
+func readValues(db database.Iteratee) ([][]byte, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	var values [][]byte
+	for it.Next() {
+		values = append(values, slices.Clone(it.Value()))
+	}
+	return values, it.Error()
+}
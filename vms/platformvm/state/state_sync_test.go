@@ -0,0 +1,276 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/platformvm/block"
+	"github.com/skychains/chain/vms/platformvm/status"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/secp256k1fx"
+)
+
+func TestSyncSummarySerialization(t *testing.T) {
+	require := require.New(t)
+
+	summary, err := NewSyncSummary(
+		16384,
+		[]byte{1, 2, 3},
+		[]ids.ID{ids.GenerateTestID(), ids.GenerateTestID()},
+	)
+	require.NoError(err)
+
+	parsed, err := ParseSyncSummary(summary.Bytes())
+	require.NoError(err)
+	require.Equal(summary, parsed)
+	require.Equal(summary.ID(), parsed.ID())
+}
+
+func TestStateSync(t *testing.T) {
+	require := require.New(t)
+
+	source := newInitializedState(require).(*state)
+	source.syncSummaryFrequency = 2
+
+	createSubnetTx := &txs.Tx{
+		Unsigned: &txs.CreateSubnetTx{
+			Owner: &secp256k1fx.OutputOwners{},
+		},
+	}
+	require.NoError(createSubnetTx.Initialize(txs.Codec))
+	subnetID := createSubnetTx.ID()
+	source.AddTx(createSubnetTx, status.Committed)
+	source.AddSubnet(subnetID)
+
+	utxo := &lux.UTXO{
+		UTXOID: lux.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: lux.Asset{ID: ids.GenerateTestID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Lux,
+		},
+	}
+	source.AddUTXO(utxo)
+
+	// A snapshot is only produced at multiples of the summary frequency.
+	for height := uint64(1); height <= 2; height++ {
+		_, err := source.GetSyncSummary()
+		require.ErrorIs(err, database.ErrNotFound)
+
+		blk, err := block.NewApricotCommitBlock(source.GetLastAccepted(), height)
+		require.NoError(err)
+		source.AddStatelessBlock(blk)
+		source.SetLastAccepted(blk.ID())
+		source.SetHeight(height)
+		require.NoError(source.Commit())
+	}
+
+	// The snapshot is built in the background.
+	source.syncSnapshotWG.Wait()
+	summary, err := source.GetSyncSummary()
+	require.NoError(err)
+	require.Equal(uint64(2), summary.Height)
+	require.NotEmpty(summary.ChunkHashes)
+
+	_, err = source.GetSyncChunk(summary.Height+1, 0)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	destination := newInitializedState(require).(*state)
+	destinationUTXO := &lux.UTXO{
+		UTXOID: lux.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: lux.Asset{ID: ids.GenerateTestID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Lux,
+		},
+	}
+	destination.AddUTXO(destinationUTXO)
+	destination.SetHeight(1)
+	require.NoError(destination.Commit())
+
+	_, err = destination.GetOngoingSyncSummary()
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(destination.SetOngoingSyncSummary(summary))
+	ongoing, err := destination.GetOngoingSyncSummary()
+	require.NoError(err)
+	require.Equal(summary.ID(), ongoing.ID())
+
+	missing, err := destination.GetMissingSyncChunks()
+	require.NoError(err)
+	require.Len(missing, len(summary.ChunkHashes))
+
+	require.ErrorIs(destination.PutSyncChunk(0, []byte{0}), ErrInvalidSyncChunk)
+	require.ErrorIs(destination.PutSyncChunk(uint64(len(summary.ChunkHashes)), nil), ErrInvalidSyncChunk)
+	require.ErrorIs(destination.FinishSync(), errMissingSyncChunks)
+
+	for _, index := range missing {
+		chunk, err := source.GetSyncChunk(summary.Height, index)
+		require.NoError(err)
+		require.NoError(destination.PutSyncChunk(index, chunk))
+	}
+
+	missing, err = destination.GetMissingSyncChunks()
+	require.NoError(err)
+	require.Empty(missing)
+
+	require.NoError(destination.FinishSync())
+
+	_, err = destination.GetOngoingSyncSummary()
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.Equal(source.GetLastAccepted(), destination.GetLastAccepted())
+	require.Equal(source.GetTimestamp(), destination.GetTimestamp())
+	require.Equal(syncValidatorDiffsStartHeight(summary.Height), destination.GetValidatorDiffsStartHeight())
+
+	blkID, err := destination.GetBlockIDAtHeight(summary.Height)
+	require.NoError(err)
+	require.Equal(source.GetLastAccepted(), blkID)
+
+	_, err = destination.GetUTXO(utxo.InputID())
+	require.NoError(err)
+	_, err = destination.GetUTXO(destinationUTXO.InputID())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	subnetIDs, err := destination.GetSubnetIDs()
+	require.NoError(err)
+	require.Equal([]ids.ID{subnetID}, subnetIDs)
+
+	expectedChains, err := source.GetChains(constants.PrimaryNetworkID)
+	require.NoError(err)
+	chains, err := destination.GetChains(constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Len(chains, len(expectedChains))
+	for i, chain := range chains {
+		require.Equal(expectedChains[i].ID(), chain.ID())
+	}
+
+	// The synced validators are loaded into the validator set, and are
+	// assumed to have been online since they started validating.
+	_, err = destination.GetCurrentValidator(constants.PrimaryNetworkID, initialNodeID)
+	require.NoError(err)
+	require.Equal(uint64(units.Lux), destination.validators.GetWeight(constants.PrimaryNetworkID, initialNodeID))
+
+	upDuration, lastUpdated, err := destination.GetUptime(initialNodeID, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Zero(upDuration)
+	require.Equal(initialTime.Unix(), lastUpdated.Unix())
+}
+
+func TestBuildSyncSnapshot(t *testing.T) {
+	require := require.New(t)
+
+	s := newInitializedState(require).(*state)
+
+	utxo := &lux.UTXO{
+		UTXOID: lux.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: lux.Asset{ID: ids.GenerateTestID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Lux,
+		},
+	}
+	s.AddUTXO(utxo)
+
+	blk, err := block.NewApricotCommitBlock(s.GetLastAccepted(), 1)
+	require.NoError(err)
+	s.AddStatelessBlock(blk)
+	s.SetLastAccepted(blk.ID())
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	snapshot, err := newDBSnapshot(s.db)
+	require.NoError(err)
+	defer snapshot.Release()
+
+	// Changes committed after the database snapshot was taken must not be
+	// included in the sync snapshot.
+	laterUTXO := &lux.UTXO{
+		UTXOID: lux.UTXOID{
+			TxID: ids.GenerateTestID(),
+		},
+		Asset: lux.Asset{ID: ids.GenerateTestID()},
+		Out: &secp256k1fx.TransferOutput{
+			Amt: units.Lux,
+		},
+	}
+	s.AddUTXO(laterUTXO)
+	laterBlk, err := block.NewApricotCommitBlock(blk.ID(), 2)
+	require.NoError(err)
+	s.AddStatelessBlock(laterBlk)
+	s.SetLastAccepted(laterBlk.ID())
+	s.SetHeight(2)
+	require.NoError(s.Commit())
+
+	db := &snapshotDB{Snapshot: snapshot}
+	err = s.buildSyncSnapshot(context.Background(), db, laterBlk)
+	require.ErrorIs(err, errSyncBlockMismatch)
+	require.NoError(s.buildSyncSnapshot(context.Background(), db, blk))
+
+	summary, err := s.GetSyncSummary()
+	require.NoError(err)
+	require.Equal(blk.Height(), summary.Height)
+
+	utxoIDs := set.Set[ids.ID]{}
+	for i := range summary.ChunkHashes {
+		chunk, err := s.GetSyncChunk(summary.Height, uint64(i))
+		require.NoError(err)
+		entries, err := parseSyncChunk(chunk)
+		require.NoError(err)
+		for _, entry := range entries {
+			if entry.Namespace != syncUTXONamespace {
+				continue
+			}
+			utxoID, err := ids.ToID(entry.Key)
+			require.NoError(err)
+			utxoIDs.Add(utxoID)
+		}
+	}
+	require.Contains(utxoIDs, utxo.InputID())
+	require.NotContains(utxoIDs, laterUTXO.InputID())
+
+	// The snapshot can't be modified.
+	require.ErrorIs(db.Put([]byte{0}, nil), errSyncSnapshotReadOnly)
+	require.ErrorIs(db.NewBatch().Write(), errSyncSnapshotReadOnly)
+}
+
+func TestSyncValidatorDiffsStartHeight(t *testing.T) {
+	tests := []struct {
+		height   uint64
+		expected uint64
+	}{
+		{
+			height:   0,
+			expected: 0,
+		},
+		{
+			height:   syncValidatorDiffsLookback - 1,
+			expected: 0,
+		},
+		{
+			height:   syncValidatorDiffsLookback,
+			expected: 1,
+		},
+		{
+			height:   3 * syncValidatorDiffsLookback,
+			expected: 2*syncValidatorDiffsLookback + 1,
+		},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.expected, syncValidatorDiffsStartHeight(test.height))
+	}
+}
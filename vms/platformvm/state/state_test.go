@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
+	"github.com/skychains/chain/codec"
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/database/memdb"
 	"github.com/skychains/chain/ids"
@@ -26,6 +27,7 @@ import (
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/units"
 	"github.com/skychains/chain/utils/wrappers"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/block"
 	"github.com/skychains/chain/vms/platformvm/config"
@@ -1239,6 +1241,7 @@ func TestStateAddRemoveValidator(t *testing.T) {
 				primaryValidatorSet,
 				currentHeight,
 				prevHeight+1,
+				constants.PrimaryNetworkID,
 			))
 			requireEqualPublicKeysValidatorSet(require, prevDiff.expectedPrimaryValidatorSet, primaryValidatorSet)
 
@@ -1255,6 +1258,85 @@ func TestStateAddRemoveValidator(t *testing.T) {
 	}
 }
 
+// Tests GetValidatorWeightDiffs, GetValidatorPublicKeyDiffs
+func TestStateGetValidatorDiffs(t *testing.T) {
+	require := require.New(t)
+
+	state := newInitializedState(require)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	var (
+		startTime = time.Now()
+		endTime   = startTime.Add(24 * time.Hour)
+		staker0   = Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			PublicKey: bls.PublicFromSecretKey(sk),
+			SubnetID:  constants.PrimaryNetworkID,
+			Weight:    1,
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+		staker1 = Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    ids.GenerateTestNodeID(),
+			SubnetID:  constants.PrimaryNetworkID,
+			Weight:    2,
+			StartTime: startTime,
+			EndTime:   endTime,
+		}
+	)
+
+	// Height 1: add staker0
+	state.PutCurrentValidator(&staker0)
+	state.SetHeight(1)
+	require.NoError(state.Commit())
+
+	// Height 2: remove staker0
+	state.DeleteCurrentValidator(&staker0)
+	state.SetHeight(2)
+	require.NoError(state.Commit())
+
+	// Height 3: add staker1
+	state.PutCurrentValidator(&staker1)
+	state.SetHeight(3)
+	require.NoError(state.Commit())
+
+	weightDiffs, err := state.GetValidatorWeightDiffs(context.Background(), 3, 2, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(
+		map[ids.NodeID]*ValidatorWeightDiff{
+			staker0.NodeID: {Decrease: true, Amount: staker0.Weight},
+			staker1.NodeID: {Decrease: false, Amount: staker1.Weight},
+		},
+		weightDiffs,
+	)
+
+	// staker0 was added and removed within the range.
+	weightDiffs, err = state.GetValidatorWeightDiffs(context.Background(), 2, 1, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Len(weightDiffs, 1)
+	require.Contains(weightDiffs, staker0.NodeID)
+	require.Zero(weightDiffs[staker0.NodeID].Amount)
+
+	publicKeys, err := state.GetValidatorPublicKeyDiffs(context.Background(), 3, 2, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Equal(
+		map[ids.NodeID][]byte{
+			staker0.NodeID: bls.PublicKeyToUncompressedBytes(staker0.PublicKey),
+		},
+		publicKeys,
+	)
+
+	// staker0 had no public key prior to height 1.
+	publicKeys, err = state.GetValidatorPublicKeyDiffs(context.Background(), 3, 1, constants.PrimaryNetworkID)
+	require.NoError(err)
+	require.Len(publicKeys, 1)
+	require.Empty(publicKeys[staker0.NodeID])
+}
+
 func copyValidatorSet(
 	input map[ids.NodeID]*validators.GetValidatorOutput,
 ) map[ids.NodeID]*validators.GetValidatorOutput {
@@ -1401,6 +1483,30 @@ func TestStateSubnetOwner(t *testing.T) {
 	require.Equal(owner2, owner)
 }
 
+func TestGetFeeState(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+
+	feeState, err := getFeeState(db)
+	require.NoError(err)
+	require.Equal(gas.State{}, feeState)
+
+	expectedFeeState := gas.State{
+		Capacity: 1,
+		Excess:   2,
+	}
+	require.NoError(putFeeState(db, expectedFeeState))
+
+	feeState, err = getFeeState(db)
+	require.NoError(err)
+	require.Equal(expectedFeeState, feeState)
+
+	require.NoError(db.Put(FeeStateKey, []byte{}))
+	_, err = getFeeState(db)
+	require.ErrorIs(err, codec.ErrCantUnpackVersion)
+}
+
 func makeBlocks(require *require.Assertions) []block.Block {
 	var blks []block.Block
 	{
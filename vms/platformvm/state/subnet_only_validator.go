@@ -0,0 +1,255 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/btree"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/vms/platformvm/block"
+)
+
+var (
+	_ btree.LessFunc[SubnetOnlyValidator] = SubnetOnlyValidator.Less
+
+	ErrMutatedSubnetOnlyValidator     = errors.New("subnet only validator contains mutated constant fields")
+	ErrConflictingSubnetOnlyValidator = errors.New("subnet only validator contains conflicting subnetID + nodeID pair")
+)
+
+// SubnetOnlyValidator is a validator of a converted subnet that is not
+// required to validate the Primary Network. Rather than staking, the validator
+// continuously pays a fee out of its balance while it is active.
+type SubnetOnlyValidator struct {
+	// ValidationID is not serialized because it is used as the key in the
+	// database, so it doesn't need to be stored in the value.
+	ValidationID ids.ID
+
+	SubnetID ids.ID     `serialize:"true"`
+	NodeID   ids.NodeID `serialize:"true"`
+
+	// PublicKey is the uncompressed BLS public key of the validator.
+	PublicKey []byte `serialize:"true"`
+
+	// RemainingBalanceOwner is the serialized fx.Owner that is able to claim
+	// the remaining balance of the validator once it is removed.
+	RemainingBalanceOwner []byte `serialize:"true"`
+
+	// StartTime is the unix timestamp, in seconds, when this validator was
+	// added to the set.
+	StartTime uint64 `serialize:"true"`
+
+	// Weight of this validator. A weight of 0 denotes that the validator has
+	// been removed.
+	Weight uint64 `serialize:"true"`
+
+	// EndAccumulatedFee is the value of the globally accrued fees at which
+	// this validator will have exhausted its balance. If EndAccumulatedFee is
+	// 0, the validator is inactive and is not included in the validator set.
+	EndAccumulatedFee uint64 `serialize:"true"`
+
+	// MinNonce is the smallest nonce that a weight update of this validator
+	// must use. It prevents weight updates from being replayed.
+	MinNonce uint64 `serialize:"true"`
+}
+
+// Less sorts validators by the order in which they will exhaust their
+// balances.
+func (v SubnetOnlyValidator) Less(o SubnetOnlyValidator) bool {
+	return v.Compare(o) == -1
+}
+
+func (v SubnetOnlyValidator) Compare(o SubnetOnlyValidator) int {
+	if c := cmp.Compare(v.EndAccumulatedFee, o.EndAccumulatedFee); c != 0 {
+		return c
+	}
+	return v.ValidationID.Compare(o.ValidationID)
+}
+
+// IsActive returns true if the validator is currently paying for its
+// inclusion in the validator set.
+func (v SubnetOnlyValidator) IsActive() bool {
+	return v.Weight != 0 && v.EndAccumulatedFee != 0
+}
+
+// constantsAreUnmodified returns true if the fields of [v] that must never
+// change are equal to those of [o].
+func (v SubnetOnlyValidator) constantsAreUnmodified(o SubnetOnlyValidator) bool {
+	return v.ValidationID == o.ValidationID &&
+		v.SubnetID == o.SubnetID &&
+		v.NodeID == o.NodeID &&
+		bytes.Equal(v.PublicKey, o.PublicKey) &&
+		bytes.Equal(v.RemainingBalanceOwner, o.RemainingBalanceOwner) &&
+		v.StartTime == o.StartTime
+}
+
+// effectiveWeight returns the weight this validator contributes to the
+// validator set of its subnet.
+func (v SubnetOnlyValidator) effectiveWeight() uint64 {
+	if !v.IsActive() {
+		return 0
+	}
+	return v.Weight
+}
+
+func getSubnetOnlyValidator(db database.KeyValueReader, validationID ids.ID) (SubnetOnlyValidator, error) {
+	bytes, err := db.Get(validationID[:])
+	if err != nil {
+		return SubnetOnlyValidator{}, err
+	}
+
+	vdr := SubnetOnlyValidator{
+		ValidationID: validationID,
+	}
+	if _, err := block.GenesisCodec.Unmarshal(bytes, &vdr); err != nil {
+		return SubnetOnlyValidator{}, fmt.Errorf("failed to unmarshal subnet only validator: %w", err)
+	}
+	return vdr, nil
+}
+
+func putSubnetOnlyValidator(db database.KeyValueWriter, vdr SubnetOnlyValidator) error {
+	bytes, err := block.GenesisCodec.Marshal(block.CodecVersion, &vdr)
+	if err != nil {
+		return fmt.Errorf("failed to marshal subnet only validator: %w", err)
+	}
+	return db.Put(vdr.ValidationID[:], bytes)
+}
+
+func deleteSubnetOnlyValidator(db database.KeyValueDeleter, validationID ids.ID) error {
+	return db.Delete(validationID[:])
+}
+
+type subnetIDNodeID struct {
+	subnetID ids.ID
+	nodeID   ids.NodeID
+}
+
+// subnetOnlyValidatorsDiff tracks modifications to the set of subnet only
+// validators on top of a parent view.
+type subnetOnlyValidatorsDiff struct {
+	numAddedActive     int                                // May be negative
+	modified           map[ids.ID]SubnetOnlyValidator     // validationID -> modified validator
+	modifiedHasNodeIDs map[subnetIDNodeID]bool            // subnetID+nodeID -> whether the pair is in use
+	active             *btree.BTreeG[SubnetOnlyValidator] // active validators that were modified
+}
+
+func newSubnetOnlyValidatorsDiff() *subnetOnlyValidatorsDiff {
+	return &subnetOnlyValidatorsDiff{
+		modified:           make(map[ids.ID]SubnetOnlyValidator),
+		modifiedHasNodeIDs: make(map[subnetIDNodeID]bool),
+		active:             btree.NewG(defaultTreeDegree, SubnetOnlyValidator.Less),
+	}
+}
+
+// getActiveSubnetOnlyValidators merges the active validators of the parent
+// view with the active validators modified in this diff.
+//
+// Invariant: [parentActive] must be sorted.
+func (d *subnetOnlyValidatorsDiff) getActiveSubnetOnlyValidators(parentActive []SubnetOnlyValidator) []SubnetOnlyValidator {
+	active := make([]SubnetOnlyValidator, 0, len(parentActive)+d.active.Len())
+	for _, vdr := range parentActive {
+		if _, modified := d.modified[vdr.ValidationID]; !modified {
+			active = append(active, vdr)
+		}
+	}
+	d.active.Ascend(func(vdr SubnetOnlyValidator) bool {
+		active = append(active, vdr)
+		return true
+	})
+	slices.SortFunc(active, SubnetOnlyValidator.Compare)
+	return active
+}
+
+// getSubnetOnlyValidators merges the validators of [subnetID] in the parent
+// view with the validators of [subnetID] modified in this diff.
+func (d *subnetOnlyValidatorsDiff) getSubnetOnlyValidators(parentValidators []SubnetOnlyValidator, subnetID ids.ID) []SubnetOnlyValidator {
+	validators := make([]SubnetOnlyValidator, 0, len(parentValidators))
+	for _, vdr := range parentValidators {
+		if _, modified := d.modified[vdr.ValidationID]; !modified {
+			validators = append(validators, vdr)
+		}
+	}
+	for _, vdr := range d.modified {
+		if vdr.SubnetID == subnetID && vdr.Weight != 0 {
+			validators = append(validators, vdr)
+		}
+	}
+	return validators
+}
+
+func (d *subnetOnlyValidatorsDiff) getSubnetOnlyValidator(validationID ids.ID) (SubnetOnlyValidator, bool, error) {
+	vdr, modified := d.modified[validationID]
+	if !modified {
+		return SubnetOnlyValidator{}, false, nil
+	}
+	if vdr.Weight == 0 {
+		return SubnetOnlyValidator{}, true, database.ErrNotFound
+	}
+	return vdr, true, nil
+}
+
+func (d *subnetOnlyValidatorsDiff) hasSubnetOnlyValidator(subnetID ids.ID, nodeID ids.NodeID) (bool, bool) {
+	has, modified := d.modifiedHasNodeIDs[subnetIDNodeID{
+		subnetID: subnetID,
+		nodeID:   nodeID,
+	}]
+	return has, modified
+}
+
+// putSubnetOnlyValidator records [vdr] in the diff. [view] must be the state
+// that this diff is being applied to, including any prior modifications made
+// by this diff.
+func (d *subnetOnlyValidatorsDiff) putSubnetOnlyValidator(view Chain, vdr SubnetOnlyValidator) error {
+	var wasActive bool
+	switch prior, err := view.GetSubnetOnlyValidator(vdr.ValidationID); err {
+	case nil:
+		if !prior.constantsAreUnmodified(vdr) {
+			return ErrMutatedSubnetOnlyValidator
+		}
+		wasActive = prior.IsActive()
+	case database.ErrNotFound:
+		if vdr.Weight == 0 {
+			// Removing a validator that doesn't exist is a noop.
+			return nil
+		}
+
+		has, err := view.HasSubnetOnlyValidator(vdr.SubnetID, vdr.NodeID)
+		if err != nil {
+			return err
+		}
+		if has {
+			return ErrConflictingSubnetOnlyValidator
+		}
+	default:
+		return err
+	}
+
+	if prior, ok := d.modified[vdr.ValidationID]; ok {
+		d.active.Delete(prior)
+	}
+
+	isActive := vdr.IsActive()
+	switch {
+	case !wasActive && isActive:
+		d.numAddedActive++
+	case wasActive && !isActive:
+		d.numAddedActive--
+	}
+
+	d.modified[vdr.ValidationID] = vdr
+	d.modifiedHasNodeIDs[subnetIDNodeID{
+		subnetID: vdr.SubnetID,
+		nodeID:   vdr.NodeID,
+	}] = vdr.Weight != 0
+	if isActive {
+		d.active.ReplaceOrInsert(vdr)
+	}
+	return nil
+}
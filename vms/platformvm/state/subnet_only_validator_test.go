@@ -0,0 +1,190 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package state
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow/validators"
+	"github.com/skychains/chain/utils/crypto/bls"
+)
+
+func TestSubnetOnlyValidator_Compare(t *testing.T) {
+	tests := []struct {
+		name     string
+		v        SubnetOnlyValidator
+		o        SubnetOnlyValidator
+		expected int
+	}{
+		{
+			name: "v.EndAccumulatedFee < o.EndAccumulatedFee",
+			v: SubnetOnlyValidator{
+				ValidationID:      ids.ID{1},
+				EndAccumulatedFee: 1,
+			},
+			o: SubnetOnlyValidator{
+				ValidationID:      ids.ID{0},
+				EndAccumulatedFee: 2,
+			},
+			expected: -1,
+		},
+		{
+			name: "v.EndAccumulatedFee = o.EndAccumulatedFee, v.ValidationID < o.ValidationID",
+			v: SubnetOnlyValidator{
+				ValidationID:      ids.ID{0},
+				EndAccumulatedFee: 1,
+			},
+			o: SubnetOnlyValidator{
+				ValidationID:      ids.ID{1},
+				EndAccumulatedFee: 1,
+			},
+			expected: -1,
+		},
+		{
+			name: "v.EndAccumulatedFee = o.EndAccumulatedFee, v.ValidationID = o.ValidationID",
+			v: SubnetOnlyValidator{
+				ValidationID:      ids.ID{0},
+				EndAccumulatedFee: 1,
+			},
+			o: SubnetOnlyValidator{
+				ValidationID:      ids.ID{0},
+				EndAccumulatedFee: 1,
+			},
+			expected: 0,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			require.Equal(test.expected, test.v.Compare(test.o))
+			require.Equal(-test.expected, test.o.Compare(test.v))
+			require.Equal(test.expected == -1, test.v.Less(test.o))
+		})
+	}
+}
+
+func TestSubnetOnlyValidators(t *testing.T) {
+	require := require.New(t)
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(err)
+	pk := bls.PublicFromSecretKey(sk)
+	pkBytes := bls.PublicKeyToUncompressedBytes(pk)
+
+	var (
+		subnetID = ids.GenerateTestID()
+		active   = SubnetOnlyValidator{
+			ValidationID:      ids.GenerateTestID(),
+			SubnetID:          subnetID,
+			NodeID:            ids.GenerateTestNodeID(),
+			PublicKey:         pkBytes,
+			Weight:            2,
+			EndAccumulatedFee: 10,
+		}
+		inactive = SubnetOnlyValidator{
+			ValidationID: ids.GenerateTestID(),
+			SubnetID:     subnetID,
+			NodeID:       ids.GenerateTestNodeID(),
+			PublicKey:    pkBytes,
+			Weight:       3,
+		}
+	)
+
+	s := newInitializedState(require).(*state)
+	d, err := NewDiffOn(s)
+	require.NoError(err)
+
+	require.NoError(d.PutSubnetOnlyValidator(active))
+	require.NoError(d.PutSubnetOnlyValidator(inactive))
+
+	// The same subnetID + nodeID pair can not be used by a second validator.
+	conflicting := active
+	conflicting.ValidationID = ids.GenerateTestID()
+	require.ErrorIs(d.PutSubnetOnlyValidator(conflicting), ErrConflictingSubnetOnlyValidator)
+
+	// Constant fields can not be modified.
+	mutated := active
+	mutated.StartTime++
+	require.ErrorIs(d.PutSubnetOnlyValidator(mutated), ErrMutatedSubnetOnlyValidator)
+
+	activeSOVs, err := d.GetActiveSubnetOnlyValidators()
+	require.NoError(err)
+	require.Equal([]SubnetOnlyValidator{active}, activeSOVs)
+	require.Equal(1, d.NumActiveSubnetOnlyValidators())
+	require.Zero(s.NumActiveSubnetOnlyValidators())
+
+	has, err := d.HasSubnetOnlyValidator(subnetID, inactive.NodeID)
+	require.NoError(err)
+	require.True(has)
+
+	subnetSOVs, err := d.GetSubnetOnlyValidators(subnetID)
+	require.NoError(err)
+	require.ElementsMatch([]SubnetOnlyValidator{active, inactive}, subnetSOVs)
+
+	subnetSOVs, err = d.GetSubnetOnlyValidators(ids.GenerateTestID())
+	require.NoError(err)
+	require.Empty(subnetSOVs)
+
+	_, err = s.GetSubnetOnlyValidator(active.ValidationID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	require.NoError(d.Apply(s))
+	s.SetHeight(1)
+	require.NoError(s.Commit())
+
+	for _, expected := range []SubnetOnlyValidator{active, inactive} {
+		sov, err := s.GetSubnetOnlyValidator(expected.ValidationID)
+		require.NoError(err)
+		require.Equal(expected, sov)
+
+		sov, err = getSubnetOnlyValidator(s.subnetOnlyValidatorsDB, expected.ValidationID)
+		require.NoError(err)
+		require.Equal(expected, sov)
+	}
+	require.Equal(1, s.NumActiveSubnetOnlyValidators())
+
+	subnetSOVs, err = s.GetSubnetOnlyValidators(subnetID)
+	require.NoError(err)
+	require.ElementsMatch([]SubnetOnlyValidator{active, inactive}, subnetSOVs)
+
+	// Only active validators are included in the validator set.
+	require.Equal(active.Weight, s.validators.GetWeight(subnetID, active.NodeID))
+	require.Zero(s.validators.GetWeight(subnetID, inactive.NodeID))
+
+	// Removing the active validator should be reverted by the diffs.
+	removed := active
+	removed.Weight = 0
+	require.NoError(s.PutSubnetOnlyValidator(removed))
+	s.SetHeight(2)
+	require.NoError(s.Commit())
+
+	_, err = s.GetSubnetOnlyValidator(active.ValidationID)
+	require.ErrorIs(err, database.ErrNotFound)
+	require.Zero(s.validators.GetWeight(subnetID, active.NodeID))
+	require.Zero(s.NumActiveSubnetOnlyValidators())
+
+	subnetSOVs, err = s.GetSubnetOnlyValidators(subnetID)
+	require.NoError(err)
+	require.Equal([]SubnetOnlyValidator{inactive}, subnetSOVs)
+
+	vdrs := map[ids.NodeID]*validators.GetValidatorOutput{}
+	require.NoError(s.ApplyValidatorWeightDiffs(context.Background(), vdrs, 2, 2, subnetID))
+	require.NoError(s.ApplyValidatorPublicKeyDiffs(context.Background(), vdrs, 2, 2, subnetID))
+	require.Equal(
+		map[ids.NodeID]*validators.GetValidatorOutput{
+			active.NodeID: {
+				NodeID:    active.NodeID,
+				PublicKey: pk,
+				Weight:    active.Weight,
+			},
+		},
+		vdrs,
+	)
+}
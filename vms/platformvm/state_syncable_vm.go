@@ -0,0 +1,261 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/vms/platformvm/block"
+	"github.com/skychains/chain/vms/platformvm/network"
+	"github.com/skychains/chain/vms/platformvm/state"
+
+	snowmanblock "github.com/skychains/chain/snow/engine/snowman/block"
+)
+
+// stateSyncRetryDelay is the time to wait before requesting a chunk again
+// after a request failed.
+const stateSyncRetryDelay = time.Second
+
+var (
+	_ snowmanblock.StateSyncableVM = (*VM)(nil)
+	_ snowmanblock.StateSummary    = (*stateSummary)(nil)
+
+	errInvalidStateSummary = errors.New("invalid state summary")
+	errStateSyncFailed     = errors.New("state sync failed")
+)
+
+func (vm *VM) StateSyncEnabled(context.Context) (bool, error) {
+	return vm.stateSyncEnabled, nil
+}
+
+func (vm *VM) GetOngoingSyncStateSummary(context.Context) (snowmanblock.StateSummary, error) {
+	summary, err := vm.state.GetOngoingSyncSummary()
+	if err != nil {
+		return nil, err
+	}
+	return vm.newStateSummary(summary), nil
+}
+
+func (vm *VM) GetLastStateSummary(context.Context) (snowmanblock.StateSummary, error) {
+	summary, err := vm.state.GetSyncSummary()
+	if err != nil {
+		return nil, err
+	}
+	return vm.newStateSummary(summary), nil
+}
+
+func (vm *VM) ParseStateSummary(_ context.Context, summaryBytes []byte) (snowmanblock.StateSummary, error) {
+	summary, err := state.ParseSyncSummary(summaryBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Note: summaries to be parsed are not verified, so we must use
+	// block.Codec rather than block.GenesisCodec
+	blk, err := block.Parse(block.Codec, summary.Block)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errInvalidStateSummary, err)
+	}
+	if blk.Height() != summary.Height {
+		return nil, fmt.Errorf("%w: block height %d != summary height %d",
+			errInvalidStateSummary,
+			blk.Height(),
+			summary.Height,
+		)
+	}
+	return vm.newStateSummary(summary), nil
+}
+
+// GetStateSummary only returns the summary of the most recent snapshot, as
+// older snapshots aren't kept.
+func (vm *VM) GetStateSummary(_ context.Context, height uint64) (snowmanblock.StateSummary, error) {
+	summary, err := vm.state.GetSyncSummary()
+	if err != nil {
+		return nil, err
+	}
+	if summary.Height != height {
+		return nil, database.ErrNotFound
+	}
+	return vm.newStateSummary(summary), nil
+}
+
+func (vm *VM) newStateSummary(summary *state.SyncSummary) *stateSummary {
+	return &stateSummary{
+		summary: summary,
+		vm:      vm,
+	}
+}
+
+type stateSummary struct {
+	summary *state.SyncSummary
+	vm      *VM
+}
+
+func (s *stateSummary) ID() ids.ID {
+	return s.summary.ID()
+}
+
+func (s *stateSummary) Height() uint64 {
+	return s.summary.Height
+}
+
+func (s *stateSummary) Bytes() []byte {
+	return s.summary.Bytes()
+}
+
+func (s *stateSummary) Accept(context.Context) (snowmanblock.StateSyncMode, error) {
+	lastAcceptedID := s.vm.manager.LastAccepted()
+	lastAccepted, err := s.vm.manager.GetStatelessBlock(lastAcceptedID)
+	if err != nil {
+		return snowmanblock.StateSyncSkipped, err
+	}
+	if s.summary.Height <= lastAccepted.Height() {
+		s.vm.ctx.Log.Info("skipping state sync",
+			zap.String("reason", "summary is not ahead of the last accepted block"),
+			zap.Uint64("summaryHeight", s.summary.Height),
+			zap.Uint64("lastAcceptedHeight", lastAccepted.Height()),
+		)
+		return snowmanblock.StateSyncSkipped, nil
+	}
+
+	if err := s.vm.state.SetOngoingSyncSummary(s.summary); err != nil {
+		return snowmanblock.StateSyncSkipped, err
+	}
+
+	go s.vm.syncState(s.vm.onShutdownCtx, s.summary)
+	return snowmanblock.StateSyncStatic, nil
+}
+
+// syncState fetches the missing chunks of [summary] from peers and then
+// replaces the state with the synced snapshot. The engine is notified once
+// syncing has finished. If syncing failed, the error is returned to the engine
+// when it starts bootstrapping. The fetched chunks are kept, so syncing
+// resumes from the ongoing summary after a restart.
+func (vm *VM) syncState(ctx context.Context, summary *state.SyncSummary) {
+	vm.ctx.Log.Info("starting state sync",
+		zap.Stringer("summaryID", summary.ID()),
+		zap.Uint64("height", summary.Height),
+		zap.Int("numChunks", len(summary.ChunkHashes)),
+	)
+
+	if err := vm.syncStateChunks(ctx, summary); err != nil {
+		vm.ctx.Log.Error("state sync failed",
+			zap.Stringer("summaryID", summary.ID()),
+			zap.Error(err),
+		)
+
+		vm.ctx.Lock.Lock()
+		vm.stateSyncErr = fmt.Errorf("%w of summary %s: %w", errStateSyncFailed, summary.ID(), err)
+		vm.ctx.Lock.Unlock()
+	} else {
+		vm.ctx.Log.Info("finished state sync",
+			zap.Stringer("summaryID", summary.ID()),
+			zap.Uint64("height", summary.Height),
+		)
+	}
+
+	select {
+	case vm.toEngine <- common.StateSyncDone:
+	case <-ctx.Done():
+	}
+}
+
+func (vm *VM) syncStateChunks(ctx context.Context, summary *state.SyncSummary) error {
+	vm.ctx.Lock.Lock()
+	missing, err := vm.state.GetMissingSyncChunks()
+	vm.ctx.Lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, index := range missing {
+		if err := vm.syncStateChunk(ctx, summary.Height, index); err != nil {
+			return err
+		}
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	if err := vm.state.FinishSync(); err != nil {
+		return err
+	}
+
+	lastAcceptedID := vm.state.GetLastAccepted()
+	vm.manager.SetLastAccepted(lastAcceptedID)
+	return vm.initBlockchains()
+}
+
+// syncStateChunk fetches the chunk at [index] of the snapshot at [height] and
+// stores it. Failed requests and invalid chunks are retried until [ctx] is
+// cancelled.
+func (vm *VM) syncStateChunk(ctx context.Context, height uint64, index uint64) error {
+	for {
+		chunk, err := vm.fetchStateChunk(ctx, height, index)
+		if err == nil {
+			vm.ctx.Lock.Lock()
+			err = vm.state.PutSyncChunk(index, chunk)
+			vm.ctx.Lock.Unlock()
+			if err == nil {
+				return nil
+			}
+			if !errors.Is(err, state.ErrInvalidSyncChunk) {
+				return err
+			}
+		}
+
+		vm.ctx.Log.Debug("failed to fetch state sync chunk",
+			zap.Uint64("height", height),
+			zap.Uint64("index", index),
+			zap.Error(err),
+		)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(stateSyncRetryDelay):
+		}
+	}
+}
+
+func (vm *VM) fetchStateChunk(ctx context.Context, height uint64, index uint64) ([]byte, error) {
+	type response struct {
+		chunk []byte
+		err   error
+	}
+
+	responses := make(chan response, 1)
+	request := network.StateSyncRequest{
+		Height: height,
+		Index:  index,
+	}
+	err := vm.stateSyncClient.AppRequestAny(
+		ctx,
+		request.Bytes(),
+		func(_ context.Context, _ ids.NodeID, chunk []byte, err error) {
+			responses <- response{
+				chunk: chunk,
+				err:   err,
+			}
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case r := <-responses:
+		return r.chunk, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package platformvm
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/network/p2p"
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/version"
+	"github.com/skychains/chain/vms/platformvm/network"
+	"github.com/skychains/chain/vms/platformvm/state"
+	"github.com/skychains/chain/vms/secp256k1fx"
+
+	snowmanblock "github.com/skychains/chain/snow/engine/snowman/block"
+	walletsigner "github.com/skychains/chain/wallet/chain/p/signer"
+)
+
+var stateSyncConfigBytes = []byte(`{"network":{"max-validator-set-staleness":0},"state-sync-enabled":true,"state-sync-summary-frequency":2}`)
+
+func TestStateSync(t *testing.T) {
+	require := require.New(t)
+
+	source, summary := newStateSyncSource(t)
+	syncSummary, err := state.ParseSyncSummary(summary.Bytes())
+	require.NoError(err)
+
+	var (
+		sender       = &common.SenderTest{}
+		sourceNodeID = ids.GenerateTestNodeID()
+		numRequests  int
+	)
+	vm, _, _, _ := defaultVMWithConfig(t, latestFork, stateSyncConfigBytes, sender)
+	sender.SendAppRequestF = func(_ context.Context, _ set.Set[ids.NodeID], requestID uint32, requestBytes []byte) error {
+		_, requestBytes, _ = p2p.ParseMessage(requestBytes)
+		request, err := network.ParseStateSyncRequest(requestBytes)
+		if err != nil {
+			return err
+		}
+
+		source.ctx.Lock.Lock()
+		chunk, err := source.state.GetSyncChunk(request.Height, request.Index)
+		source.ctx.Lock.Unlock()
+		if err != nil {
+			return err
+		}
+
+		// The first response is corrupted to ensure that invalid chunks are
+		// requested again.
+		numRequests++
+		if numRequests == 1 {
+			chunk = append(slices.Clone(chunk), 0)
+		}
+
+		// The response must be delivered after the request is registered.
+		go func() {
+			_ = vm.AppResponse(context.Background(), sourceNodeID, requestID, chunk)
+		}()
+		return nil
+	}
+
+	toEngine := make(chan common.Message, 1)
+	vm.ctx.Lock.Lock()
+	vm.toEngine = toEngine
+	require.NoError(vm.Connected(context.Background(), sourceNodeID, version.CurrentApp))
+	mode := acceptStateSummary(require, vm, summary)
+	require.Equal(snowmanblock.StateSyncStatic, mode)
+	vm.ctx.Lock.Unlock()
+
+	select {
+	case msg := <-toEngine:
+		require.Equal(common.StateSyncDone, msg)
+	case <-time.After(time.Minute):
+		require.FailNow("state sync didn't finish")
+	}
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	require.NoError(vm.stateSyncErr)
+	require.Equal(len(syncSummary.ChunkHashes)+1, numRequests)
+
+	_, err = vm.GetOngoingSyncStateSummary(context.Background())
+	require.ErrorIs(err, database.ErrNotFound)
+
+	source.ctx.Lock.Lock()
+	lastAcceptedID := source.manager.LastAccepted()
+	source.ctx.Lock.Unlock()
+	require.Equal(lastAcceptedID, vm.state.GetLastAccepted())
+	require.Equal(lastAcceptedID, vm.manager.LastAccepted())
+
+	lastAccepted, err := vm.manager.GetStatelessBlock(lastAcceptedID)
+	require.NoError(err)
+	require.Equal(summary.Height(), lastAccepted.Height())
+}
+
+func TestStateSyncFailure(t *testing.T) {
+	require := require.New(t)
+
+	_, summary := newStateSyncSource(t)
+
+	// Requests are never answered, so syncing only stops once the VM is shut
+	// down.
+	sender := &common.SenderTest{}
+	sender.SendAppRequestF = func(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+		return nil
+	}
+	vm, _, _, _ := defaultVMWithConfig(t, latestFork, stateSyncConfigBytes, sender)
+
+	vm.ctx.Lock.Lock()
+	require.NoError(vm.Connected(context.Background(), ids.GenerateTestNodeID(), version.CurrentApp))
+	mode := acceptStateSummary(require, vm, summary)
+	require.Equal(snowmanblock.StateSyncStatic, mode)
+	vm.ctx.Lock.Unlock()
+
+	vm.onShutdownCtxCancel()
+	require.Eventually(func() bool {
+		vm.ctx.Lock.Lock()
+		defer vm.ctx.Lock.Unlock()
+
+		return vm.stateSyncErr != nil
+	}, time.Minute, 10*time.Millisecond)
+
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	// The failure is reported to the engine, and syncing can be resumed.
+	err := vm.SetState(context.Background(), snow.Bootstrapping)
+	require.ErrorIs(err, errStateSyncFailed)
+
+	ongoing, err := vm.GetOngoingSyncStateSummary(context.Background())
+	require.NoError(err)
+	require.Equal(summary.ID(), ongoing.ID())
+}
+
+// newStateSyncSource returns a VM that has produced a state summary at height
+// 2, along with the summary.
+func newStateSyncSource(t *testing.T) (*VM, snowmanblock.StateSummary) {
+	require := require.New(t)
+
+	vm, factory, _, _ := defaultVMWithConfig(t, latestFork, stateSyncConfigBytes, &common.SenderTest{})
+	vm.ctx.Lock.Lock()
+	defer vm.ctx.Lock.Unlock()
+
+	builder, signer := factory.NewWallet(keys[0])
+	utx, err := builder.NewCreateSubnetTx(&secp256k1fx.OutputOwners{})
+	require.NoError(err)
+	tx, err := walletsigner.SignUnsigned(context.Background(), signer, utx)
+	require.NoError(err)
+
+	vm.ctx.Lock.Unlock()
+	require.NoError(vm.issueTxFromRPC(tx))
+	vm.ctx.Lock.Lock()
+
+	blk, err := vm.Builder.BuildBlock(context.Background())
+	require.NoError(err)
+	require.NoError(blk.Verify(context.Background()))
+	require.NoError(blk.Accept(context.Background()))
+	require.Equal(uint64(2), blk.Height())
+
+	// The snapshot is built in the background.
+	var summary snowmanblock.StateSummary
+	vm.ctx.Lock.Unlock()
+	require.Eventually(func() bool {
+		vm.ctx.Lock.Lock()
+		defer vm.ctx.Lock.Unlock()
+
+		summary, err = vm.GetLastStateSummary(context.Background())
+		return err == nil
+	}, time.Minute, 10*time.Millisecond)
+	vm.ctx.Lock.Lock()
+
+	require.Equal(blk.Height(), summary.Height())
+	return vm, summary
+}
+
+func acceptStateSummary(require *require.Assertions, vm *VM, summary snowmanblock.StateSummary) snowmanblock.StateSyncMode {
+	parsed, err := vm.ParseStateSummary(context.Background(), summary.Bytes())
+	require.NoError(err)
+	mode, err := parsed.Accept(context.Background())
+	require.NoError(err)
+	return mode
+}
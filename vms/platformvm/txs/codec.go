@@ -42,7 +42,10 @@ func init() {
 
 		c.SkipRegistrations(4)
 
-		errs.Add(RegisterDUnsignedTxsTypes(c))
+		errs.Add(
+			RegisterDUnsignedTxsTypes(c),
+			RegisterFUnsignedTxsTypes(c),
+		)
 	}
 
 	Codec = codec.NewDefaultManager()
@@ -109,3 +112,12 @@ func RegisterDUnsignedTxsTypes(targetCodec linearcodec.Codec) error {
 		targetCodec.RegisterType(&BaseTx{}),
 	)
 }
+
+func RegisterFUnsignedTxsTypes(targetCodec linearcodec.Codec) error {
+	return errors.Join(
+		targetCodec.RegisterType(&ConvertSubnetTx{}),
+		targetCodec.RegisterType(&IncreaseBalanceTx{}),
+		targetCodec.RegisterType(&RegisterSubnetValidatorTx{}),
+		targetCodec.RegisterType(&SetSubnetValidatorWeightTx{}),
+	)
+}
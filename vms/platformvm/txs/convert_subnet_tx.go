@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"errors"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/vms/components/verify"
+	"github.com/skychains/chain/vms/platformvm/fx"
+	"github.com/skychains/chain/vms/platformvm/signer"
+	"github.com/skychains/chain/vms/types"
+)
+
+const MaxSubnetAddressLength = 4096
+
+var (
+	_ UnsignedTx                              = (*ConvertSubnetTx)(nil)
+	_ utils.Sortable[*ConvertSubnetValidator] = (*ConvertSubnetValidator)(nil)
+
+	ErrConvertPermissionlessSubnet         = errors.New("cannot convert a permissionless subnet")
+	ErrAddressTooLong                      = errors.New("address is too long")
+	ErrConvertMustIncludeValidators        = errors.New("conversion must include at least one validator")
+	ErrConvertValidatorsNotSortedAndUnique = errors.New("conversion validators must be sorted and unique")
+	ErrZeroWeight                          = errors.New("validator weight must be non-zero")
+	ErrEmptyNodeID                         = errors.New("validator nodeID must be non-empty")
+)
+
+// ConvertSubnetTx converts a permissioned subnet into a subnet whose validator
+// set is managed by [Address] on [ChainID]. The initial validators of the
+// subnet pay a continuous fee out of their balance rather than staking on the
+// primary network.
+type ConvertSubnetTx struct {
+	// Metadata, inputs and outputs
+	BaseTx `serialize:"true"`
+	// ID of the Subnet to convert
+	Subnet ids.ID `serialize:"true" json:"subnetID"`
+	// Chain where the Subnet manager lives
+	ChainID ids.ID `serialize:"true" json:"chainID"`
+	// Address of the Subnet manager
+	Address types.JSONByteSlice `serialize:"true" json:"address"`
+	// Initial pay-as-you-go validators of the Subnet
+	Validators []*ConvertSubnetValidator `serialize:"true" json:"validators"`
+	// Authorizes this conversion
+	SubnetAuth verify.Verifiable `serialize:"true" json:"subnetAuthorization"`
+}
+
+// InitCtx sets the FxID fields in the inputs and outputs of this
+// [ConvertSubnetTx]. Also sets the [ctx] to the given [vm.ctx] so that the
+// addresses can be json marshalled into human readable format
+func (tx *ConvertSubnetTx) InitCtx(ctx *snow.Context) {
+	tx.BaseTx.InitCtx(ctx)
+	for _, vdr := range tx.Validators {
+		vdr.RemainingBalanceOwner.InitCtx(ctx)
+	}
+}
+
+func (tx *ConvertSubnetTx) SyntacticVerify(ctx *snow.Context) error {
+	switch {
+	case tx == nil:
+		return ErrNilTx
+	case tx.SyntacticallyVerified:
+		// already passed syntactic verification
+		return nil
+	case tx.Subnet == constants.PrimaryNetworkID:
+		return ErrConvertPermissionlessSubnet
+	case len(tx.Address) > MaxSubnetAddressLength:
+		return ErrAddressTooLong
+	case len(tx.Validators) == 0:
+		return ErrConvertMustIncludeValidators
+	case !utils.IsSortedAndUnique(tx.Validators):
+		return ErrConvertValidatorsNotSortedAndUnique
+	}
+
+	if err := tx.BaseTx.SyntacticVerify(ctx); err != nil {
+		return err
+	}
+	for _, vdr := range tx.Validators {
+		if err := vdr.Verify(); err != nil {
+			return err
+		}
+	}
+	if err := tx.SubnetAuth.Verify(); err != nil {
+		return err
+	}
+
+	tx.SyntacticallyVerified = true
+	return nil
+}
+
+func (tx *ConvertSubnetTx) Visit(visitor Visitor) error {
+	return visitor.ConvertSubnetTx(tx)
+}
+
+// ConvertSubnetValidator is an initial validator of a converted subnet.
+type ConvertSubnetValidator struct {
+	// NodeID of this validator
+	NodeID ids.NodeID `serialize:"true" json:"nodeID"`
+	// Weight of this validator used when sampling
+	Weight uint64 `serialize:"true" json:"weight"`
+	// Initial balance, in nLUX, used to pay the continuous fee of this
+	// validator
+	Balance uint64 `serialize:"true" json:"balance"`
+	// [Signer] is the BLS key for this validator.
+	Signer signer.ProofOfPossession `serialize:"true" json:"signer"`
+	// Leftover balance is returned to this owner once the validator is
+	// removed
+	RemainingBalanceOwner fx.Owner `serialize:"true" json:"remainingBalanceOwner"`
+}
+
+func (v *ConvertSubnetValidator) Compare(o *ConvertSubnetValidator) int {
+	return v.NodeID.Compare(o.NodeID)
+}
+
+func (v *ConvertSubnetValidator) Verify() error {
+	switch {
+	case v.Weight == 0:
+		return ErrZeroWeight
+	case v.NodeID == ids.EmptyNodeID:
+		return ErrEmptyNodeID
+	}
+	return verify.All(&v.Signer, v.RemainingBalanceOwner)
+}
@@ -0,0 +1,241 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/crypto/bls"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/components/verify"
+	"github.com/skychains/chain/vms/platformvm/signer"
+	"github.com/skychains/chain/vms/secp256k1fx"
+)
+
+func TestConvertSubnetTxSyntacticVerify(t *testing.T) {
+	type test struct {
+		name        string
+		txFunc      func(*gomock.Controller) *ConvertSubnetTx
+		expectedErr error
+	}
+
+	var (
+		networkID = uint32(1337)
+		chainID   = ids.GenerateTestID()
+	)
+
+	ctx := &snow.Context{
+		ChainID:   chainID,
+		NetworkID: networkID,
+	}
+
+	// A BaseTx that already passed syntactic verification.
+	verifiedBaseTx := BaseTx{
+		SyntacticallyVerified: true,
+	}
+	// Sanity check.
+	require.NoError(t, verifiedBaseTx.SyntacticVerify(ctx))
+
+	// A BaseTx that passes syntactic verification.
+	validBaseTx := BaseTx{
+		BaseTx: lux.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: chainID,
+		},
+	}
+	// Sanity check.
+	require.NoError(t, validBaseTx.SyntacticVerify(ctx))
+	// Make sure we're not caching the verification result.
+	require.False(t, validBaseTx.SyntacticallyVerified)
+
+	// A BaseTx that fails syntactic verification.
+	invalidBaseTx := BaseTx{}
+
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+
+	newValidator := func(nodeID ids.NodeID) *ConvertSubnetValidator {
+		return &ConvertSubnetValidator{
+			NodeID:  nodeID,
+			Weight:  1,
+			Balance: 1,
+			Signer:  *signer.NewProofOfPossession(sk),
+			RemainingBalanceOwner: &secp256k1fx.OutputOwners{
+				Threshold: 1,
+				Addrs:     []ids.ShortID{ids.GenerateTestShortID()},
+			},
+		}
+	}
+	validValidators := []*ConvertSubnetValidator{
+		newValidator(ids.GenerateTestNodeID()),
+	}
+
+	tests := []test{
+		{
+			name: "nil tx",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return nil
+			},
+			expectedErr: ErrNilTx,
+		},
+		{
+			name: "already verified",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{BaseTx: verifiedBaseTx}
+			},
+			expectedErr: nil,
+		},
+		{
+			name: "invalid subnetID",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{
+					BaseTx:     validBaseTx,
+					Subnet:     constants.PrimaryNetworkID,
+					Validators: validValidators,
+				}
+			},
+			expectedErr: ErrConvertPermissionlessSubnet,
+		},
+		{
+			name: "address too long",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{
+					BaseTx:     validBaseTx,
+					Subnet:     ids.GenerateTestID(),
+					Address:    make([]byte, MaxSubnetAddressLength+1),
+					Validators: validValidators,
+				}
+			},
+			expectedErr: ErrAddressTooLong,
+		},
+		{
+			name: "no validators",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{
+					BaseTx: validBaseTx,
+					Subnet: ids.GenerateTestID(),
+				}
+			},
+			expectedErr: ErrConvertMustIncludeValidators,
+		},
+		{
+			name: "duplicate validators",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				nodeID := ids.GenerateTestNodeID()
+				return &ConvertSubnetTx{
+					BaseTx: validBaseTx,
+					Subnet: ids.GenerateTestID(),
+					Validators: []*ConvertSubnetValidator{
+						newValidator(nodeID),
+						newValidator(nodeID),
+					},
+				}
+			},
+			expectedErr: ErrConvertValidatorsNotSortedAndUnique,
+		},
+		{
+			name: "unsorted validators",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{
+					BaseTx: validBaseTx,
+					Subnet: ids.GenerateTestID(),
+					Validators: []*ConvertSubnetValidator{
+						newValidator(ids.BuildTestNodeID([]byte{2})),
+						newValidator(ids.BuildTestNodeID([]byte{1})),
+					},
+				}
+			},
+			expectedErr: ErrConvertValidatorsNotSortedAndUnique,
+		},
+		{
+			name: "invalid BaseTx",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{
+					BaseTx:     invalidBaseTx,
+					Subnet:     ids.GenerateTestID(),
+					Validators: validValidators,
+				}
+			},
+			expectedErr: lux.ErrWrongNetworkID,
+		},
+		{
+			name: "zero weight validator",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				vdr := newValidator(ids.GenerateTestNodeID())
+				vdr.Weight = 0
+				return &ConvertSubnetTx{
+					BaseTx:     validBaseTx,
+					Subnet:     ids.GenerateTestID(),
+					Validators: []*ConvertSubnetValidator{vdr},
+				}
+			},
+			expectedErr: ErrZeroWeight,
+		},
+		{
+			name: "empty nodeID validator",
+			txFunc: func(*gomock.Controller) *ConvertSubnetTx {
+				return &ConvertSubnetTx{
+					BaseTx: validBaseTx,
+					Subnet: ids.GenerateTestID(),
+					Validators: []*ConvertSubnetValidator{
+						newValidator(ids.EmptyNodeID),
+					},
+				}
+			},
+			expectedErr: ErrEmptyNodeID,
+		},
+		{
+			name: "invalid subnetAuth",
+			txFunc: func(ctrl *gomock.Controller) *ConvertSubnetTx {
+				// This SubnetAuth fails verification.
+				invalidSubnetAuth := verify.NewMockVerifiable(ctrl)
+				invalidSubnetAuth.EXPECT().Verify().Return(errInvalidSubnetAuth)
+				return &ConvertSubnetTx{
+					BaseTx:     validBaseTx,
+					Subnet:     ids.GenerateTestID(),
+					Validators: validValidators,
+					SubnetAuth: invalidSubnetAuth,
+				}
+			},
+			expectedErr: errInvalidSubnetAuth,
+		},
+		{
+			name: "passes verification",
+			txFunc: func(ctrl *gomock.Controller) *ConvertSubnetTx {
+				// This SubnetAuth passes verification.
+				validSubnetAuth := verify.NewMockVerifiable(ctrl)
+				validSubnetAuth.EXPECT().Verify().Return(nil)
+				return &ConvertSubnetTx{
+					BaseTx:     validBaseTx,
+					Subnet:     ids.GenerateTestID(),
+					Address:    make([]byte, MaxSubnetAddressLength),
+					Validators: validValidators,
+					SubnetAuth: validSubnetAuth,
+				}
+			},
+			expectedErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+			ctrl := gomock.NewController(t)
+
+			tx := tt.txFunc(ctrl)
+			err := tx.SyntacticVerify(ctx)
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr != nil {
+				return
+			}
+			require.True(tx.SyntacticallyVerified)
+		})
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/skychains/chain/snow/snowtest"
 	"github.com/skychains/chain/utils/constants"
 	"github.com/skychains/chain/utils/crypto/secp256k1"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/status"
@@ -887,6 +888,73 @@ func TestAdvanceTimeTxAfterBanff(t *testing.T) {
 	require.ErrorIs(err, ErrAdvanceTimeTxIssuedAfterBanff)
 }
 
+func TestAdvanceTimeToUpdatesFeeState(t *testing.T) {
+	tests := []struct {
+		name     string
+		fork     fork
+		seconds  time.Duration
+		expected gas.State
+	}{
+		{
+			name:    "before F upgrade",
+			fork:    eUpgrade,
+			seconds: 2,
+			expected: gas.State{
+				Capacity: 0,
+				Excess:   100,
+			},
+		},
+		{
+			name:    "no time passed",
+			fork:    fUpgrade,
+			seconds: 0,
+			expected: gas.State{
+				Capacity: 0,
+				Excess:   100,
+			},
+		},
+		{
+			name:    "time passed",
+			fork:    fUpgrade,
+			seconds: 2,
+			expected: gas.State{
+				Capacity: 200, // 2 * MaxPerSecond
+				Excess:   0,   // 100 - 2 * TargetPerSecond
+			},
+		},
+		{
+			name:    "capacity capped",
+			fork:    fUpgrade,
+			seconds: 20,
+			expected: gas.State{
+				Capacity: 1_000, // MaxCapacity
+				Excess:   0,
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+			env := newEnvironment(t, test.fork)
+			env.ctx.Lock.Lock()
+			defer env.ctx.Lock.Unlock()
+
+			onAcceptState, err := state.NewDiff(lastAcceptedID, env)
+			require.NoError(err)
+			onAcceptState.SetFeeState(gas.State{
+				Capacity: 0,
+				Excess:   100,
+			})
+
+			newChainTime := onAcceptState.GetTimestamp().Add(test.seconds * time.Second)
+			_, err = AdvanceTimeTo(&env.backend, onAcceptState, newChainTime)
+			require.NoError(err)
+			require.Equal(test.expected, onAcceptState.GetFeeState())
+			require.Equal(newChainTime, onAcceptState.GetTimestamp())
+		})
+	}
+}
+
 // Ensure marshaling/unmarshaling works
 func TestAdvanceTimeTxUnmarshal(t *testing.T) {
 	require := require.New(t)
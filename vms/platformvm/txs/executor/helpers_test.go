@@ -34,6 +34,7 @@ import (
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/timer/mockable"
 	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/api"
 	"github.com/skychains/chain/vms/platformvm/config"
 	"github.com/skychains/chain/vms/platformvm/fx"
@@ -62,6 +63,7 @@ const (
 	cortina
 	durango
 	eUpgrade
+	fUpgrade
 )
 
 var (
@@ -283,6 +285,14 @@ func defaultConfig(t *testing.T, f fork) *config.Config {
 			CreateSubnetTxFee:     100 * defaultTxFee,
 			CreateBlockchainTxFee: 100 * defaultTxFee,
 		},
+		DynamicFeeConfig: gas.Config{
+			Weights:                  gas.Dimensions{1, 1, 1, 1},
+			MaxCapacity:              1_000,
+			MaxPerSecond:             100,
+			TargetPerSecond:          50,
+			MinPrice:                 1,
+			ExcessConversionConstant: 1_000,
+		},
 		MinValidatorStake: 5 * units.MilliLux,
 		MaxValidatorStake: 500 * units.MilliLux,
 		MinDelegatorStake: 1 * units.MilliLux,
@@ -301,10 +311,14 @@ func defaultConfig(t *testing.T, f fork) *config.Config {
 			CortinaTime:       mockable.MaxTime,
 			DurangoTime:       mockable.MaxTime,
 			EUpgradeTime:      mockable.MaxTime,
+			FUpgradeTime:      mockable.MaxTime,
 		},
 	}
 
 	switch f {
+	case fUpgrade:
+		c.UpgradeConfig.FUpgradeTime = defaultValidateStartTime.Add(-2 * time.Second)
+		fallthrough
 	case eUpgrade:
 		c.UpgradeConfig.EUpgradeTime = defaultValidateStartTime.Add(-2 * time.Second)
 		fallthrough
@@ -15,7 +15,6 @@ import (
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/txs"
-	"github.com/skychains/chain/vms/platformvm/txs/fee"
 
 	safemath "github.com/skychains/chain/utils/math"
 )
@@ -164,8 +163,11 @@ func verifyAddValidatorTx(
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
@@ -255,8 +257,11 @@ func verifyAddSubnetValidatorTx(
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
@@ -333,8 +338,11 @@ func verifyRemoveSubnetValidatorTx(
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return nil, false, err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
@@ -451,8 +459,11 @@ func verifyAddDelegatorTx(
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
@@ -571,8 +582,11 @@ func verifyAddPermissionlessValidatorTx(
 	copy(outs[len(tx.Outs):], tx.StakeOuts)
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
@@ -716,8 +730,11 @@ func verifyAddPermissionlessDelegatorTx(
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
@@ -770,9 +787,11 @@ func verifyTransferSubnetOwnershipTx(
 	}
 
 	// Verify the flowcheck
-	currentTimestamp := chainState.GetTimestamp()
-	feeCalculator := fee.NewStaticCalculator(backend.Config.StaticFeeConfig, backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(backend.Config, chainState)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := backend.FlowChecker.VerifySpend(
 		tx,
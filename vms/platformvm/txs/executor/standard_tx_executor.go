@@ -12,22 +12,41 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/skychains/chain/chains/atomic"
+	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/crypto/bls"
 	"github.com/skychains/chain/utils/set"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/components/verify"
+	"github.com/skychains/chain/vms/platformvm/fx"
+	"github.com/skychains/chain/vms/platformvm/signer"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/txs"
-	"github.com/skychains/chain/vms/platformvm/txs/fee"
+	"github.com/skychains/chain/vms/platformvm/warp/message"
+	"github.com/skychains/chain/vms/secp256k1fx"
+
+	safemath "github.com/skychains/chain/utils/math"
 )
 
+// RegisterSubnetValidatorTxExpiryWindow is the maximum duration into the
+// future that a RegisterSubnetValidator message may expire at. Consumed
+// messages are remembered until they expire, so this bounds the number of
+// messages that must be remembered.
+const RegisterSubnetValidatorTxExpiryWindow = 24 * time.Hour
+
 var (
 	_ txs.Visitor = (*StandardTxExecutor)(nil)
 
 	errEmptyNodeID                = errors.New("validator nodeID cannot be empty")
 	errMaxStakeDurationTooLarge   = errors.New("max stake duration must be less than or equal to the global max stake duration")
 	errMissingStartTimePreDurango = errors.New("staker transactions must have a StartTime pre-Durango")
+	errFUpgradeNotActive          = errors.New("attempting to use an F-upgrade feature prior to activation")
+	errWarpMessageExpired         = errors.New("warp message expired")
+	errWarpMessageNotYetAllowed   = errors.New("warp message expires too far in the future")
+	errWarpMessageAlreadyIssued   = errors.New("warp message already issued")
+	errWarpMessageNonceTooLow     = errors.New("warp message nonce is too low")
+	errInvalidRemainingOwner      = errors.New("remaining balance owner is not a secp256k1fx owner")
 )
 
 type StandardTxExecutor struct {
@@ -69,8 +88,11 @@ func (e *StandardTxExecutor) CreateChainTx(tx *txs.CreateChainTx) error {
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(e.Backend.Config.StaticFeeConfig, e.Backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := e.FlowChecker.VerifySpend(
 		tx,
@@ -117,8 +139,11 @@ func (e *StandardTxExecutor) CreateSubnetTx(tx *txs.CreateSubnetTx) error {
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(e.Backend.Config.StaticFeeConfig, e.Backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := e.FlowChecker.VerifySpend(
 		tx,
@@ -200,8 +225,11 @@ func (e *StandardTxExecutor) ImportTx(tx *txs.ImportTx) error {
 		copy(ins[len(tx.Ins):], tx.ImportedInputs)
 
 		// Verify the flowcheck
-		feeCalculator := fee.NewStaticCalculator(e.Backend.Config.StaticFeeConfig, e.Backend.Config.UpgradeConfig)
-		fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+		feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+		fee, err := feeCalculator.CalculateFee(tx)
+		if err != nil {
+			return err
+		}
 
 		if err := e.FlowChecker.VerifySpendUTXOs(
 			tx,
@@ -259,8 +287,11 @@ func (e *StandardTxExecutor) ExportTx(tx *txs.ExportTx) error {
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(e.Backend.Config.StaticFeeConfig, e.Backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := e.FlowChecker.VerifySpend(
 		tx,
@@ -448,8 +479,11 @@ func (e *StandardTxExecutor) TransformSubnetTx(tx *txs.TransformSubnetTx) error
 	}
 
 	// Verify the flowcheck
-	feeCalculator := fee.NewStaticCalculator(e.Backend.Config.StaticFeeConfig, e.Backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	totalRewardAmount := tx.MaximumSupply - tx.InitialSupply
 	if err := e.Backend.FlowChecker.VerifySpend(
@@ -556,6 +590,433 @@ func (e *StandardTxExecutor) TransferSubnetOwnershipTx(tx *txs.TransferSubnetOwn
 	return nil
 }
 
+func (e *StandardTxExecutor) ConvertSubnetTx(tx *txs.ConvertSubnetTx) error {
+	currentTimestamp := e.State.GetTimestamp()
+	if !e.Backend.Config.UpgradeConfig.IsFActivated(currentTimestamp) {
+		return errFUpgradeNotActive
+	}
+
+	// Verify the tx is well-formed
+	if err := e.Tx.SyntacticVerify(e.Ctx); err != nil {
+		return err
+	}
+
+	if err := lux.VerifyMemoFieldLength(tx.Memo, true /*=isDurangoActive*/); err != nil {
+		return err
+	}
+
+	baseTxCreds, err := verifyPoASubnetAuthorization(e.Backend, e.State, e.Tx, tx.Subnet, tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+
+	// Verify the flowcheck
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
+
+	var (
+		txID        = e.Tx.ID()
+		startTime   = uint64(currentTimestamp.Unix())
+		accruedFees = e.State.GetAccruedFees()
+	)
+	for i, vdr := range tx.Validators {
+		// The initial validators must not already be validating the subnet.
+		_, err := GetValidator(e.State, tx.Subnet, vdr.NodeID)
+		if err == nil {
+			return fmt.Errorf(
+				"%s %w of %s",
+				vdr.NodeID,
+				ErrDuplicateValidator,
+				tx.Subnet,
+			)
+		}
+		if err != database.ErrNotFound {
+			return err
+		}
+
+		remainingBalanceOwner, err := txs.Codec.Marshal(txs.CodecVersion, &vdr.RemainingBalanceOwner)
+		if err != nil {
+			return err
+		}
+
+		sov := state.SubnetOnlyValidator{
+			ValidationID:          txID.Prefix(uint64(i)),
+			SubnetID:              tx.Subnet,
+			NodeID:                vdr.NodeID,
+			PublicKey:             bls.PublicKeyToUncompressedBytes(vdr.Signer.Key()),
+			RemainingBalanceOwner: remainingBalanceOwner,
+			StartTime:             startTime,
+			Weight:                vdr.Weight,
+		}
+		if vdr.Balance != 0 {
+			// The validator is active until it has paid [vdr.Balance] worth
+			// of fees.
+			sov.EndAccumulatedFee, err = safemath.Add64(accruedFees, vdr.Balance)
+			if err != nil {
+				return err
+			}
+		}
+		if err := e.State.PutSubnetOnlyValidator(sov); err != nil {
+			return err
+		}
+
+		// The initial balance of the validator is burned along with the fee.
+		fee, err = safemath.Add64(fee, vdr.Balance)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := e.FlowChecker.VerifySpend(
+		tx,
+		e.State,
+		tx.Ins,
+		tx.Outs,
+		baseTxCreds,
+		map[ids.ID]uint64{
+			e.Ctx.LUXAssetID: fee,
+		},
+	); err != nil {
+		return err
+	}
+
+	// Consume the UTXOS
+	lux.Consume(e.State, tx.Ins)
+	// Produce the UTXOS
+	lux.Produce(e.State, txID, tx.Outs)
+	// Convert the subnet in the database
+	e.State.SetSubnetManager(tx.Subnet, tx.ChainID, tx.Address)
+	return nil
+}
+
+func (e *StandardTxExecutor) IncreaseBalanceTx(tx *txs.IncreaseBalanceTx) error {
+	if !e.Backend.Config.UpgradeConfig.IsFActivated(e.State.GetTimestamp()) {
+		return errFUpgradeNotActive
+	}
+
+	// Verify the tx is well-formed
+	if err := e.Tx.SyntacticVerify(e.Ctx); err != nil {
+		return err
+	}
+
+	if err := lux.VerifyMemoFieldLength(tx.Memo, true /*=isDurangoActive*/); err != nil {
+		return err
+	}
+
+	sov, err := e.State.GetSubnetOnlyValidator(tx.ValidationID)
+	if err != nil {
+		return fmt.Errorf("failed to get subnet only validator %s: %w", tx.ValidationID, err)
+	}
+
+	// Verify the flowcheck
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
+	fee, err = safemath.Add64(fee, tx.Balance)
+	if err != nil {
+		return err
+	}
+
+	if err := e.FlowChecker.VerifySpend(
+		tx,
+		e.State,
+		tx.Ins,
+		tx.Outs,
+		e.Tx.Creds,
+		map[ids.ID]uint64{
+			e.Ctx.LUXAssetID: fee,
+		},
+	); err != nil {
+		return err
+	}
+
+	// If the validator is currently inactive, it is re-activated starting from
+	// the current accrued fees.
+	if !sov.IsActive() {
+		sov.EndAccumulatedFee = e.State.GetAccruedFees()
+	}
+	sov.EndAccumulatedFee, err = safemath.Add64(sov.EndAccumulatedFee, tx.Balance)
+	if err != nil {
+		return err
+	}
+	if err := e.State.PutSubnetOnlyValidator(sov); err != nil {
+		return err
+	}
+
+	txID := e.Tx.ID()
+	// Consume the UTXOS
+	lux.Consume(e.State, tx.Ins)
+	// Produce the UTXOS
+	lux.Produce(e.State, txID, tx.Outs)
+	return nil
+}
+
+func (e *StandardTxExecutor) RegisterSubnetValidatorTx(tx *txs.RegisterSubnetValidatorTx) error {
+	currentTimestamp := e.State.GetTimestamp()
+	if !e.Backend.Config.UpgradeConfig.IsFActivated(currentTimestamp) {
+		return errFUpgradeNotActive
+	}
+
+	// Verify the tx is well-formed
+	if err := e.Tx.SyntacticVerify(e.Ctx); err != nil {
+		return err
+	}
+
+	if err := lux.VerifyMemoFieldLength(tx.Memo, true /*=isDurangoActive*/); err != nil {
+		return err
+	}
+
+	warpMessage, addressedCall, err := parseWarpMessage(tx.Message)
+	if err != nil {
+		return err
+	}
+	msg, err := message.ParseRegisterSubnetValidator(addressedCall.Payload)
+	if err != nil {
+		return err
+	}
+
+	var (
+		currentUnix = uint64(currentTimestamp.Unix())
+		maxExpiry   = uint64(currentTimestamp.Add(RegisterSubnetValidatorTxExpiryWindow).Unix())
+	)
+	switch {
+	case msg.Expiry <= currentUnix:
+		return fmt.Errorf("%w: %d <= %d", errWarpMessageExpired, msg.Expiry, currentUnix)
+	case msg.Expiry > maxExpiry:
+		return fmt.Errorf("%w: %d > %d", errWarpMessageNotYetAllowed, msg.Expiry, maxExpiry)
+	case msg.Weight == 0:
+		return txs.ErrZeroWeight
+	case msg.NodeID == ids.EmptyNodeID:
+		return txs.ErrEmptyNodeID
+	}
+
+	// The message may only be used once before it expires.
+	validationID := msg.ValidationID()
+	expiry := state.ExpiryEntry{
+		Timestamp:    msg.Expiry,
+		ValidationID: validationID,
+	}
+	isDuplicate, err := e.State.HasExpiry(expiry)
+	if err != nil {
+		return err
+	}
+	if isDuplicate {
+		return fmt.Errorf("%w: %s", errWarpMessageAlreadyIssued, validationID)
+	}
+
+	if err := verifyWarpMessage(e.Backend, e.State, msg.SubnetID, warpMessage, addressedCall); err != nil {
+		return err
+	}
+
+	pop := signer.ProofOfPossession{
+		PublicKey:         msg.BLSPublicKey,
+		ProofOfPossession: tx.ProofOfPossession,
+	}
+	if err := pop.Verify(); err != nil {
+		return err
+	}
+
+	var remainingBalanceOwner fx.Owner = &secp256k1fx.OutputOwners{
+		Threshold: msg.RemainingBalanceOwner.Threshold,
+		Addrs:     msg.RemainingBalanceOwner.Addresses,
+	}
+	if err := remainingBalanceOwner.Verify(); err != nil {
+		return err
+	}
+	remainingBalanceOwnerBytes, err := txs.Codec.Marshal(txs.CodecVersion, &remainingBalanceOwner)
+	if err != nil {
+		return err
+	}
+
+	// The validator must not already be validating the subnet.
+	_, err = GetValidator(e.State, msg.SubnetID, msg.NodeID)
+	if err == nil {
+		return fmt.Errorf(
+			"%s %w of %s",
+			msg.NodeID,
+			ErrDuplicateValidator,
+			msg.SubnetID,
+		)
+	}
+	if err != database.ErrNotFound {
+		return err
+	}
+	hasSOV, err := e.State.HasSubnetOnlyValidator(msg.SubnetID, msg.NodeID)
+	if err != nil {
+		return err
+	}
+	if hasSOV {
+		return fmt.Errorf(
+			"%s %w of %s",
+			msg.NodeID,
+			ErrDuplicateValidator,
+			msg.SubnetID,
+		)
+	}
+
+	// Verify the flowcheck
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
+	// The initial balance of the validator is burned along with the fee.
+	fee, err = safemath.Add64(fee, tx.Balance)
+	if err != nil {
+		return err
+	}
+
+	if err := e.FlowChecker.VerifySpend(
+		tx,
+		e.State,
+		tx.Ins,
+		tx.Outs,
+		e.Tx.Creds,
+		map[ids.ID]uint64{
+			e.Ctx.LUXAssetID: fee,
+		},
+	); err != nil {
+		return err
+	}
+
+	sov := state.SubnetOnlyValidator{
+		ValidationID:          validationID,
+		SubnetID:              msg.SubnetID,
+		NodeID:                msg.NodeID,
+		PublicKey:             bls.PublicKeyToUncompressedBytes(pop.Key()),
+		RemainingBalanceOwner: remainingBalanceOwnerBytes,
+		StartTime:             currentUnix,
+		Weight:                msg.Weight,
+	}
+	if tx.Balance != 0 {
+		// The validator is active until it has paid [tx.Balance] worth of
+		// fees.
+		sov.EndAccumulatedFee, err = safemath.Add64(e.State.GetAccruedFees(), tx.Balance)
+		if err != nil {
+			return err
+		}
+	}
+	if err := e.State.PutSubnetOnlyValidator(sov); err != nil {
+		return err
+	}
+	e.State.PutExpiry(expiry)
+
+	txID := e.Tx.ID()
+	// Consume the UTXOS
+	lux.Consume(e.State, tx.Ins)
+	// Produce the UTXOS
+	lux.Produce(e.State, txID, tx.Outs)
+	return nil
+}
+
+func (e *StandardTxExecutor) SetSubnetValidatorWeightTx(tx *txs.SetSubnetValidatorWeightTx) error {
+	if !e.Backend.Config.UpgradeConfig.IsFActivated(e.State.GetTimestamp()) {
+		return errFUpgradeNotActive
+	}
+
+	// Verify the tx is well-formed
+	if err := e.Tx.SyntacticVerify(e.Ctx); err != nil {
+		return err
+	}
+
+	if err := lux.VerifyMemoFieldLength(tx.Memo, true /*=isDurangoActive*/); err != nil {
+		return err
+	}
+
+	warpMessage, addressedCall, err := parseWarpMessage(tx.Message)
+	if err != nil {
+		return err
+	}
+	msg, err := message.ParseSubnetValidatorWeight(addressedCall.Payload)
+	if err != nil {
+		return err
+	}
+
+	sov, err := e.State.GetSubnetOnlyValidator(msg.ValidationID)
+	if err != nil {
+		return fmt.Errorf("failed to get subnet only validator %s: %w", msg.ValidationID, err)
+	}
+
+	if err := verifyWarpMessage(e.Backend, e.State, sov.SubnetID, warpMessage, addressedCall); err != nil {
+		return err
+	}
+
+	if msg.Nonce < sov.MinNonce {
+		return fmt.Errorf("%w: %d < %d", errWarpMessageNonceTooLow, msg.Nonce, sov.MinNonce)
+	}
+
+	// Verify the flowcheck
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
+
+	if err := e.FlowChecker.VerifySpend(
+		tx,
+		e.State,
+		tx.Ins,
+		tx.Outs,
+		e.Tx.Creds,
+		map[ids.ID]uint64{
+			e.Ctx.LUXAssetID: fee,
+		},
+	); err != nil {
+		return err
+	}
+
+	txID := e.Tx.ID()
+	// Consume the UTXOS
+	lux.Consume(e.State, tx.Ins)
+	// Produce the UTXOS
+	lux.Produce(e.State, txID, tx.Outs)
+
+	if msg.Weight != 0 {
+		sov.Weight = msg.Weight
+		sov.MinNonce, err = safemath.Add64(msg.Nonce, 1)
+		if err != nil {
+			return err
+		}
+		return e.State.PutSubnetOnlyValidator(sov)
+	}
+
+	// The validator is being removed, so its remaining balance is returned to
+	// its owner.
+	accruedFees := e.State.GetAccruedFees()
+	if sov.IsActive() && sov.EndAccumulatedFee > accruedFees {
+		var owner fx.Owner
+		if _, err := txs.Codec.Unmarshal(sov.RemainingBalanceOwner, &owner); err != nil {
+			return err
+		}
+		outputOwners, ok := owner.(*secp256k1fx.OutputOwners)
+		if !ok {
+			return fmt.Errorf("%w: %T", errInvalidRemainingOwner, owner)
+		}
+
+		e.State.AddUTXO(&lux.UTXO{
+			UTXOID: lux.UTXOID{
+				TxID:        txID,
+				OutputIndex: uint32(len(tx.Outs)),
+			},
+			Asset: lux.Asset{
+				ID: e.Ctx.LUXAssetID,
+			},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:          sov.EndAccumulatedFee - accruedFees,
+				OutputOwners: *outputOwners,
+			},
+		})
+	}
+
+	sov.Weight = 0
+	return e.State.PutSubnetOnlyValidator(sov)
+}
+
 func (e *StandardTxExecutor) BaseTx(tx *txs.BaseTx) error {
 	if !e.Backend.Config.UpgradeConfig.IsDurangoActivated(e.State.GetTimestamp()) {
 		return ErrDurangoUpgradeNotActive
@@ -571,9 +1032,11 @@ func (e *StandardTxExecutor) BaseTx(tx *txs.BaseTx) error {
 	}
 
 	// Verify the flowcheck
-	currentTimestamp := e.State.GetTimestamp()
-	feeCalculator := fee.NewStaticCalculator(e.Backend.Config.StaticFeeConfig, e.Backend.Config.UpgradeConfig)
-	fee := feeCalculator.CalculateFee(tx, currentTimestamp)
+	feeCalculator := state.PickFeeCalculator(e.Backend.Config, e.State)
+	fee, err := feeCalculator.CalculateFee(tx)
+	if err != nil {
+		return err
+	}
 
 	if err := e.FlowChecker.VerifySpend(
 		tx,
@@ -22,6 +22,7 @@ import (
 	"github.com/skychains/chain/utils/crypto/bls"
 	"github.com/skychains/chain/utils/crypto/secp256k1"
 	"github.com/skychains/chain/utils/hashing"
+	"github.com/skychains/chain/utils/set"
 	"github.com/skychains/chain/utils/timer/mockable"
 	"github.com/skychains/chain/utils/units"
 	"github.com/skychains/chain/vms/components/lux"
@@ -35,9 +36,13 @@ import (
 	"github.com/skychains/chain/vms/platformvm/txs"
 	"github.com/skychains/chain/vms/platformvm/upgrade"
 	"github.com/skychains/chain/vms/platformvm/utxo"
+	"github.com/skychains/chain/vms/platformvm/warp"
+	"github.com/skychains/chain/vms/platformvm/warp/message"
+	"github.com/skychains/chain/vms/platformvm/warp/payload"
 	"github.com/skychains/chain/vms/secp256k1fx"
 	"github.com/skychains/chain/wallet/subnet/primary/common"
 
+	safemath "github.com/skychains/chain/utils/math"
 	walletsigner "github.com/skychains/chain/wallet/chain/p/signer"
 )
 
@@ -2081,6 +2086,7 @@ func TestStandardExecutorTransformSubnetTx(t *testing.T) {
 				env.state.EXPECT().GetTimestamp().Return(env.latestForkTime)
 				env.state.EXPECT().GetSubnetOwner(env.unsignedTx.Subnet).Return(subnetOwner, nil)
 				env.state.EXPECT().GetSubnetTransformation(env.unsignedTx.Subnet).Return(nil, database.ErrNotFound).Times(1)
+				env.state.EXPECT().GetSubnetManager(env.unsignedTx.Subnet).Return(ids.Empty, nil, database.ErrNotFound).Times(1)
 				env.fx.EXPECT().VerifyPermission(gomock.Any(), env.unsignedTx.SubnetAuth, env.tx.Creds[len(env.tx.Creds)-1], subnetOwner).Return(nil)
 				env.flowChecker.EXPECT().VerifySpend(
 					gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
@@ -2115,6 +2121,7 @@ func TestStandardExecutorTransformSubnetTx(t *testing.T) {
 				env.state.EXPECT().GetTimestamp().Return(env.latestForkTime)
 				env.state.EXPECT().GetSubnetOwner(env.unsignedTx.Subnet).Return(subnetOwner, nil).Times(1)
 				env.state.EXPECT().GetSubnetTransformation(env.unsignedTx.Subnet).Return(nil, database.ErrNotFound).Times(1)
+				env.state.EXPECT().GetSubnetManager(env.unsignedTx.Subnet).Return(ids.Empty, nil, database.ErrNotFound).Times(1)
 				env.fx.EXPECT().VerifyPermission(env.unsignedTx, env.unsignedTx.SubnetAuth, env.tx.Creds[len(env.tx.Creds)-1], subnetOwner).Return(nil).Times(1)
 				env.flowChecker.EXPECT().VerifySpend(
 					env.unsignedTx, env.state, env.unsignedTx.Ins, env.unsignedTx.Outs, env.tx.Creds[:len(env.tx.Creds)-1], gomock.Any(),
@@ -2165,10 +2172,14 @@ func defaultTestConfig(t *testing.T, f fork, tm time.Time) *config.Config {
 			CortinaTime:       mockable.MaxTime,
 			DurangoTime:       mockable.MaxTime,
 			EUpgradeTime:      mockable.MaxTime,
+			FUpgradeTime:      mockable.MaxTime,
 		},
 	}
 
 	switch f {
+	case fUpgrade:
+		c.UpgradeConfig.FUpgradeTime = tm
+		fallthrough
 	case eUpgrade:
 		c.UpgradeConfig.EUpgradeTime = tm
 		fallthrough
@@ -2192,3 +2203,567 @@ func defaultTestConfig(t *testing.T, f fork, tm time.Time) *config.Config {
 
 	return c
 }
+
+// newConvertSubnetValidator returns a pay-as-you-go validator, along with its
+// BLS secret key, that can be used to convert testSubnet1.
+func newConvertSubnetValidator(t *testing.T, balance uint64) (*txs.ConvertSubnetValidator, *bls.SecretKey) {
+	sk, err := bls.NewSecretKey()
+	require.NoError(t, err)
+
+	return &txs.ConvertSubnetValidator{
+		NodeID:  ids.GenerateTestNodeID(),
+		Weight:  defaultWeight,
+		Balance: balance,
+		Signer:  *signer.NewProofOfPossession(sk),
+		RemainingBalanceOwner: &secp256k1fx.OutputOwners{
+			Threshold: 1,
+			Addrs: []ids.ShortID{
+				preFundedKeys[0].PublicKey().Address(),
+			},
+		},
+	}, sk
+}
+
+// executeStandardTx executes [tx] on top of the last accepted state of [env]
+// and, if it succeeds, commits the resulting state.
+func executeStandardTx(t *testing.T, env *environment, tx *txs.Tx) error {
+	diff, err := state.NewDiff(lastAcceptedID, env)
+	require.NoError(t, err)
+
+	executor := StandardTxExecutor{
+		Backend: &env.backend,
+		State:   diff,
+		Tx:      tx,
+	}
+	if err := tx.Unsigned.Visit(&executor); err != nil {
+		return err
+	}
+
+	diff.AddTx(tx, status.Committed)
+	require.NoError(t, diff.Apply(env.state))
+	require.NoError(t, env.state.Commit())
+	return nil
+}
+
+// addManagerChain creates a blockchain on testSubnet1 that can be used as the
+// subnet manager.
+func addManagerChain(t *testing.T, env *environment) ids.ID {
+	require := require.New(t)
+
+	builder, signer := env.factory.NewWallet(testSubnet1ControlKeys[0], testSubnet1ControlKeys[1])
+	utx, err := builder.NewCreateChainTx(
+		testSubnet1.ID(),
+		nil,
+		constants.AVMID,
+		nil,
+		"manager",
+	)
+	require.NoError(err)
+	tx, err := walletsigner.SignUnsigned(context.Background(), signer, utx)
+	require.NoError(err)
+
+	require.NoError(executeStandardTx(t, env, tx))
+	return tx.ID()
+}
+
+// newConvertSubnetTx returns a signed tx converting testSubnet1 into a subnet
+// managed by [address] on [chainID].
+func newConvertSubnetTx(
+	t *testing.T,
+	env *environment,
+	chainID ids.ID,
+	address []byte,
+	validators ...*txs.ConvertSubnetValidator,
+) *txs.Tx {
+	require := require.New(t)
+
+	utils.Sort(validators)
+	builder, signer := env.factory.NewWallet(testSubnet1ControlKeys[0], testSubnet1ControlKeys[1])
+	utx, err := builder.NewConvertSubnetTx(
+		testSubnet1.ID(),
+		chainID,
+		address,
+		validators,
+	)
+	require.NoError(err)
+	tx, err := walletsigner.SignUnsigned(context.Background(), signer, utx)
+	require.NoError(err)
+	return tx
+}
+
+// newSignedWarpMessage returns a warp message sent by [address] on [chainID]
+// that is signed by every validator in [signers]. [signers] must contain the
+// entire validator set of the chain.
+func newSignedWarpMessage(
+	t *testing.T,
+	env *environment,
+	chainID ids.ID,
+	address []byte,
+	msgPayload []byte,
+	signers ...*bls.SecretKey,
+) []byte {
+	require := require.New(t)
+
+	addressedCall, err := payload.NewAddressedCall(address, msgPayload)
+	require.NoError(err)
+	unsignedMessage, err := warp.NewUnsignedMessage(
+		env.ctx.NetworkID,
+		chainID,
+		addressedCall.Bytes(),
+	)
+	require.NoError(err)
+
+	var (
+		signerIndices = set.NewBits()
+		signatures    = make([]*bls.Signature, len(signers))
+	)
+	for i, sk := range signers {
+		signerIndices.Add(i)
+		signatures[i] = bls.Sign(sk, unsignedMessage.Bytes())
+	}
+	aggregateSignature, err := bls.AggregateSignatures(signatures)
+	require.NoError(err)
+
+	signature := &warp.BitSetSignature{
+		Signers: signerIndices.Bytes(),
+	}
+	copy(signature.Signature[:], bls.SignatureToBytes(aggregateSignature))
+
+	msg, err := warp.NewMessage(unsignedMessage, signature)
+	require.NoError(err)
+	return msg.Bytes()
+}
+
+// burnedAmount returns the amount of LUX consumed by [utx] that was not
+// returned in its outputs.
+func burnedAmount(t *testing.T, utx *txs.BaseTx) uint64 {
+	var consumed, produced uint64
+	for _, in := range utx.Ins {
+		var err error
+		consumed, err = safemath.Add64(consumed, in.In.Amount())
+		require.NoError(t, err)
+	}
+	for _, out := range utx.Outs {
+		var err error
+		produced, err = safemath.Add64(produced, out.Out.Amount())
+		require.NoError(t, err)
+	}
+	return consumed - produced
+}
+
+func TestStandardExecutorConvertSubnetTx(t *testing.T) {
+	var (
+		chainID = ids.GenerateTestID()
+		address = []byte{'a', 'd', 'd', 'r'}
+	)
+
+	t.Run("F upgrade not active", func(t *testing.T) {
+		require := require.New(t)
+		env := newEnvironment(t, fUpgrade)
+		env.ctx.Lock.Lock()
+		defer env.ctx.Lock.Unlock()
+
+		vdr, _ := newConvertSubnetValidator(t, units.Lux)
+		tx := newConvertSubnetTx(t, env, chainID, address, vdr)
+
+		env.config.UpgradeConfig.FUpgradeTime = mockable.MaxTime
+		err := executeStandardTx(t, env, tx)
+		require.ErrorIs(err, errFUpgradeNotActive)
+	})
+
+	t.Run("duplicate node ID", func(t *testing.T) {
+		require := require.New(t)
+		env := newEnvironment(t, fUpgrade)
+		env.ctx.Lock.Lock()
+		defer env.ctx.Lock.Unlock()
+
+		vdr, _ := newConvertSubnetValidator(t, units.Lux)
+		tx := newConvertSubnetTx(t, env, chainID, address, vdr)
+
+		diff, err := state.NewDiff(lastAcceptedID, env)
+		require.NoError(err)
+		diff.PutCurrentValidator(&state.Staker{
+			TxID:      ids.GenerateTestID(),
+			NodeID:    vdr.NodeID,
+			SubnetID:  testSubnet1.ID(),
+			Weight:    defaultWeight,
+			StartTime: diff.GetTimestamp(),
+			EndTime:   defaultValidateEndTime,
+			Priority:  txs.SubnetPermissionedValidatorCurrentPriority,
+		})
+
+		executor := StandardTxExecutor{
+			Backend: &env.backend,
+			State:   diff,
+			Tx:      tx,
+		}
+		err = tx.Unsigned.Visit(&executor)
+		require.ErrorIs(err, ErrDuplicateValidator)
+	})
+
+	t.Run("converts subnet and burns balances", func(t *testing.T) {
+		require := require.New(t)
+		env := newEnvironment(t, fUpgrade)
+		env.ctx.Lock.Lock()
+		defer env.ctx.Lock.Unlock()
+
+		var (
+			activeVdr, activeSK = newConvertSubnetValidator(t, units.Lux)
+			inactiveVdr, _      = newConvertSubnetValidator(t, 0)
+			tx                  = newConvertSubnetTx(t, env, chainID, address, activeVdr, inactiveVdr)
+			utx                 = tx.Unsigned.(*txs.ConvertSubnetTx)
+		)
+
+		feeCalculator := state.PickFeeCalculator(env.config, env.state)
+		fee, err := feeCalculator.CalculateFee(utx)
+		require.NoError(err)
+
+		accruedFees := env.state.GetAccruedFees()
+		require.NoError(executeStandardTx(t, env, tx))
+
+		// The fee and the initial balances of the validators are burned.
+		require.Equal(fee+units.Lux, burnedAmount(t, &utx.BaseTx))
+
+		managerChainID, managerAddress, err := env.state.GetSubnetManager(testSubnet1.ID())
+		require.NoError(err)
+		require.Equal(chainID, managerChainID)
+		require.Equal(address, managerAddress)
+
+		for i, vdr := range utx.Validators {
+			sov, err := env.state.GetSubnetOnlyValidator(tx.ID().Prefix(uint64(i)))
+			require.NoError(err)
+			require.Equal(testSubnet1.ID(), sov.SubnetID)
+			require.Equal(vdr.NodeID, sov.NodeID)
+			require.Equal(vdr.Weight, sov.Weight)
+
+			if vdr.NodeID == activeVdr.NodeID {
+				require.Equal(accruedFees+units.Lux, sov.EndAccumulatedFee)
+				require.Equal(
+					bls.PublicKeyToUncompressedBytes(bls.PublicFromSecretKey(activeSK)),
+					sov.PublicKey,
+				)
+			} else {
+				require.False(sov.IsActive())
+			}
+		}
+
+		// Converted subnets can no longer be managed by the subnet owner.
+		builder, signer := env.factory.NewWallet(testSubnet1ControlKeys[0], testSubnet1ControlKeys[1])
+		utxCreateChain, err := builder.NewCreateChainTx(
+			testSubnet1.ID(),
+			nil,
+			constants.AVMID,
+			nil,
+			"chain",
+		)
+		require.NoError(err)
+		createChainTx, err := walletsigner.SignUnsigned(context.Background(), signer, utxCreateChain)
+		require.NoError(err)
+		err = executeStandardTx(t, env, createChainTx)
+		require.ErrorIs(err, errIsImmutable)
+	})
+}
+
+func TestStandardExecutorIncreaseBalanceTx(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, fUpgrade)
+	env.ctx.Lock.Lock()
+	defer env.ctx.Lock.Unlock()
+
+	vdr, _ := newConvertSubnetValidator(t, 0)
+	convertTx := newConvertSubnetTx(t, env, ids.GenerateTestID(), nil, vdr)
+	require.NoError(executeStandardTx(t, env, convertTx))
+
+	validationID := convertTx.ID().Prefix(0)
+	sov, err := env.state.GetSubnetOnlyValidator(validationID)
+	require.NoError(err)
+	require.False(sov.IsActive())
+
+	builder, signer := env.factory.NewWallet(preFundedKeys[0])
+	utx, err := builder.NewIncreaseBalanceTx(validationID, units.Lux)
+	require.NoError(err)
+	tx, err := walletsigner.SignUnsigned(context.Background(), signer, utx)
+	require.NoError(err)
+
+	feeCalculator := state.PickFeeCalculator(env.config, env.state)
+	fee, err := feeCalculator.CalculateFee(utx)
+	require.NoError(err)
+
+	accruedFees := env.state.GetAccruedFees()
+	require.NoError(executeStandardTx(t, env, tx))
+
+	// The fee and the added balance are burned.
+	require.Equal(fee+units.Lux, burnedAmount(t, &utx.BaseTx))
+
+	// The validator is re-activated starting from the current accrued fees.
+	sov, err = env.state.GetSubnetOnlyValidator(validationID)
+	require.NoError(err)
+	require.True(sov.IsActive())
+	require.Equal(accruedFees+units.Lux, sov.EndAccumulatedFee)
+}
+
+func TestSubnetOnlyValidatorDeactivatedAtZeroBalance(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, fUpgrade)
+	env.ctx.Lock.Lock()
+	defer env.ctx.Lock.Unlock()
+
+	const (
+		feePerSecond = 10
+		balance      = 5 * feePerSecond
+	)
+	env.config.SubnetOnlyValidatorFee = feePerSecond
+
+	vdr, _ := newConvertSubnetValidator(t, balance)
+	convertTx := newConvertSubnetTx(t, env, ids.GenerateTestID(), nil, vdr)
+	require.NoError(executeStandardTx(t, env, convertTx))
+
+	validationID := convertTx.ID().Prefix(0)
+	advanceTime := func(duration time.Duration) {
+		diff, err := state.NewDiff(lastAcceptedID, env)
+		require.NoError(err)
+
+		_, err = AdvanceTimeTo(&env.backend, diff, diff.GetTimestamp().Add(duration))
+		require.NoError(err)
+		require.NoError(diff.Apply(env.state))
+		require.NoError(env.state.Commit())
+	}
+
+	// The validator can still pay for another second.
+	advanceTime(4 * time.Second)
+	sov, err := env.state.GetSubnetOnlyValidator(validationID)
+	require.NoError(err)
+	require.True(sov.IsActive())
+	require.Equal(1, env.state.NumActiveSubnetOnlyValidators())
+
+	// The validator's balance is exhausted.
+	advanceTime(time.Second)
+	sov, err = env.state.GetSubnetOnlyValidator(validationID)
+	require.NoError(err)
+	require.False(sov.IsActive())
+	require.Equal(vdr.Weight, sov.Weight)
+	require.Zero(env.state.NumActiveSubnetOnlyValidators())
+}
+
+func TestStandardExecutorRegisterSubnetValidatorTx(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, fUpgrade)
+	env.ctx.Lock.Lock()
+	defer env.ctx.Lock.Unlock()
+
+	var (
+		managerChainID        = addManagerChain(t, env)
+		managerAddress        = []byte{'m', 'a', 'n', 'a', 'g', 'e', 'r'}
+		managerVdr, managerSK = newConvertSubnetValidator(t, units.Lux)
+	)
+	require.NoError(executeStandardTx(t, env, newConvertSubnetTx(t, env, managerChainID, managerAddress, managerVdr)))
+
+	var (
+		currentTime = env.state.GetTimestamp()
+		expiry      = uint64(currentTime.Add(time.Hour).Unix())
+		owner       = message.PChainOwner{
+			Threshold: 1,
+			Addresses: []ids.ShortID{
+				preFundedKeys[0].PublicKey().Address(),
+			},
+		}
+	)
+
+	otherSK, err := bls.NewSecretKey()
+	require.NoError(err)
+
+	newRegisterTx := func(
+		nodeID ids.NodeID,
+		expiry uint64,
+		balance uint64,
+		source []byte,
+		warpSigner *bls.SecretKey,
+	) (*txs.Tx, *message.RegisterSubnetValidator, *bls.SecretKey) {
+		sk, err := bls.NewSecretKey()
+		require.NoError(err)
+		pop := signer.NewProofOfPossession(sk)
+
+		msg, err := message.NewRegisterSubnetValidator(
+			testSubnet1.ID(),
+			nodeID,
+			pop.PublicKey,
+			expiry,
+			owner,
+			defaultWeight,
+		)
+		require.NoError(err)
+
+		builder, txSigner := env.factory.NewWallet(preFundedKeys[0])
+		utx, err := builder.NewRegisterSubnetValidatorTx(
+			balance,
+			pop.ProofOfPossession,
+			newSignedWarpMessage(t, env, managerChainID, source, msg.Bytes(), warpSigner),
+		)
+		require.NoError(err)
+		tx, err := walletsigner.SignUnsigned(context.Background(), txSigner, utx)
+		require.NoError(err)
+		return tx, msg, sk
+	}
+
+	tests := []struct {
+		name        string
+		nodeID      ids.NodeID
+		expiry      uint64
+		source      []byte
+		signer      *bls.SecretKey
+		expectedErr error
+	}{
+		{
+			name:        "expired message",
+			nodeID:      ids.GenerateTestNodeID(),
+			expiry:      uint64(currentTime.Unix()),
+			source:      managerAddress,
+			signer:      managerSK,
+			expectedErr: errWarpMessageExpired,
+		},
+		{
+			name:        "expiry too far in the future",
+			nodeID:      ids.GenerateTestNodeID(),
+			expiry:      uint64(currentTime.Add(RegisterSubnetValidatorTxExpiryWindow + time.Second).Unix()),
+			source:      managerAddress,
+			signer:      managerSK,
+			expectedErr: errWarpMessageNotYetAllowed,
+		},
+		{
+			name:        "not sent by the subnet manager",
+			nodeID:      ids.GenerateTestNodeID(),
+			expiry:      expiry,
+			source:      []byte{'o', 't', 'h', 'e', 'r'},
+			signer:      managerSK,
+			expectedErr: errWrongWarpMessageSource,
+		},
+		{
+			name:        "not signed by the manager chain validators",
+			nodeID:      ids.GenerateTestNodeID(),
+			expiry:      expiry,
+			source:      managerAddress,
+			signer:      otherSK,
+			expectedErr: warp.ErrInvalidSignature,
+		},
+		{
+			name:        "duplicate node ID",
+			nodeID:      managerVdr.NodeID,
+			expiry:      expiry,
+			source:      managerAddress,
+			signer:      managerSK,
+			expectedErr: ErrDuplicateValidator,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tx, _, _ := newRegisterTx(test.nodeID, test.expiry, units.Lux, test.source, test.signer)
+			err := executeStandardTx(t, env, tx)
+			require.ErrorIs(err, test.expectedErr)
+		})
+	}
+
+	nodeID := ids.GenerateTestNodeID()
+	tx, msg, sk := newRegisterTx(nodeID, expiry, units.Lux, managerAddress, managerSK)
+	utx := tx.Unsigned.(*txs.RegisterSubnetValidatorTx)
+
+	feeCalculator := state.PickFeeCalculator(env.config, env.state)
+	fee, err := feeCalculator.CalculateFee(utx)
+	require.NoError(err)
+
+	accruedFees := env.state.GetAccruedFees()
+	require.NoError(executeStandardTx(t, env, tx))
+
+	// The fee and the initial balance are burned.
+	require.Equal(fee+units.Lux, burnedAmount(t, &utx.BaseTx))
+
+	sov, err := env.state.GetSubnetOnlyValidator(msg.ValidationID())
+	require.NoError(err)
+	require.Equal(testSubnet1.ID(), sov.SubnetID)
+	require.Equal(nodeID, sov.NodeID)
+	require.Equal(defaultWeight, sov.Weight)
+	require.Equal(accruedFees+units.Lux, sov.EndAccumulatedFee)
+	require.Equal(bls.PublicKeyToUncompressedBytes(bls.PublicFromSecretKey(sk)), sov.PublicKey)
+
+	hasExpiry, err := env.state.HasExpiry(state.ExpiryEntry{
+		Timestamp:    expiry,
+		ValidationID: msg.ValidationID(),
+	})
+	require.NoError(err)
+	require.True(hasExpiry)
+
+	// The same message can not be issued twice.
+	builder, txSigner := env.factory.NewWallet(preFundedKeys[0])
+	replayUTX, err := builder.NewRegisterSubnetValidatorTx(
+		utx.Balance,
+		utx.ProofOfPossession,
+		utx.Message,
+	)
+	require.NoError(err)
+	replayTx, err := walletsigner.SignUnsigned(context.Background(), txSigner, replayUTX)
+	require.NoError(err)
+	err = executeStandardTx(t, env, replayTx)
+	require.ErrorIs(err, errWarpMessageAlreadyIssued)
+}
+
+func TestStandardExecutorSetSubnetValidatorWeightTx(t *testing.T) {
+	require := require.New(t)
+	env := newEnvironment(t, fUpgrade)
+	env.ctx.Lock.Lock()
+	defer env.ctx.Lock.Unlock()
+
+	var (
+		managerChainID = addManagerChain(t, env)
+		managerAddress = []byte{'m', 'a', 'n', 'a', 'g', 'e', 'r'}
+		vdr0, sk0      = newConvertSubnetValidator(t, units.Lux)
+		vdr1, sk1      = newConvertSubnetValidator(t, units.Lux)
+		convertTx      = newConvertSubnetTx(t, env, managerChainID, managerAddress, vdr0, vdr1)
+	)
+	require.NoError(executeStandardTx(t, env, convertTx))
+
+	// Both validators are required to reach quorum.
+	signers := []*bls.SecretKey{sk0, sk1}
+	validationID := convertTx.ID().Prefix(0)
+	if convertTx.Unsigned.(*txs.ConvertSubnetTx).Validators[1].NodeID == vdr1.NodeID {
+		validationID = convertTx.ID().Prefix(1)
+	}
+
+	newWeightTx := func(nonce uint64, weight uint64) *txs.Tx {
+		msg, err := message.NewSubnetValidatorWeight(validationID, nonce, weight)
+		require.NoError(err)
+
+		builder, signer := env.factory.NewWallet(preFundedKeys[0])
+		utx, err := builder.NewSetSubnetValidatorWeightTx(
+			newSignedWarpMessage(t, env, managerChainID, managerAddress, msg.Bytes(), signers...),
+		)
+		require.NoError(err)
+		tx, err := walletsigner.SignUnsigned(context.Background(), signer, utx)
+		require.NoError(err)
+		return tx
+	}
+
+	// Update the weight of the validator.
+	require.NoError(executeStandardTx(t, env, newWeightTx(0, 2*defaultWeight)))
+
+	sov, err := env.state.GetSubnetOnlyValidator(validationID)
+	require.NoError(err)
+	require.Equal(2*defaultWeight, sov.Weight)
+	require.Equal(uint64(1), sov.MinNonce)
+
+	// Messages can not be replayed.
+	err = executeStandardTx(t, env, newWeightTx(0, 3*defaultWeight))
+	require.ErrorIs(err, errWarpMessageNonceTooLow)
+
+	// Removing the validator refunds its remaining balance.
+	removeTx := newWeightTx(1, 0)
+	require.NoError(executeStandardTx(t, env, removeTx))
+
+	_, err = env.state.GetSubnetOnlyValidator(validationID)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	removeUTX := removeTx.Unsigned.(*txs.SetSubnetValidatorWeightTx)
+	refundUTXO, err := env.state.GetUTXO(removeTx.ID().Prefix(uint64(len(removeUTX.Outs))))
+	require.NoError(err)
+	require.IsType(&secp256k1fx.TransferOutput{}, refundUTXO.Out)
+	refund := refundUTXO.Out.(*secp256k1fx.TransferOutput)
+	require.Equal(sov.EndAccumulatedFee-env.state.GetAccruedFees(), refund.Amt)
+}
@@ -13,6 +13,8 @@ import (
 	"github.com/skychains/chain/vms/platformvm/reward"
 	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/txs"
+
+	safemath "github.com/skychains/chain/utils/math"
 )
 
 var (
@@ -165,6 +167,71 @@ func AdvanceTimeTo(
 		changed = true
 	}
 
+	// Refill the gas capacity and decay the excess gas for the time that has
+	// passed since the parent block.
+	if backend.Config.UpgradeConfig.IsFActivated(newChainTime) {
+		var (
+			feeConfig  = backend.Config.DynamicFeeConfig
+			parentTime = parentState.GetTimestamp()
+			seconds    uint64
+		)
+		if newChainTime.After(parentTime) {
+			seconds = uint64(newChainTime.Sub(parentTime) / time.Second)
+		}
+
+		feeState := changes.GetFeeState()
+		feeState = feeState.AdvanceTime(
+			feeConfig.MaxCapacity,
+			feeConfig.MaxPerSecond,
+			feeConfig.TargetPerSecond,
+			seconds,
+		)
+		changes.SetFeeState(feeState)
+
+		// Charge every active subnet only validator for the time that has
+		// passed and deactivate any validators that can no longer pay.
+		fees, err := safemath.Mul64(seconds, backend.Config.SubnetOnlyValidatorFee)
+		if err != nil {
+			return false, err
+		}
+		accruedFees, err := safemath.Add64(changes.GetAccruedFees(), fees)
+		if err != nil {
+			return false, err
+		}
+		changes.SetAccruedFees(accruedFees)
+
+		activeSOVs, err := changes.GetActiveSubnetOnlyValidators()
+		if err != nil {
+			return false, err
+		}
+		for _, sov := range activeSOVs {
+			if sov.EndAccumulatedFee > accruedFees {
+				break
+			}
+
+			sov.EndAccumulatedFee = 0 // Deactivate the validator
+			if err := changes.PutSubnetOnlyValidator(sov); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+
+		// Expired warp messages can no longer be replayed, so they no longer
+		// need to be remembered.
+		expiries, err := changes.GetExpiries()
+		if err != nil {
+			return false, err
+		}
+		newTimestamp := uint64(newChainTime.Unix())
+		for _, entry := range expiries {
+			if entry.Timestamp > newTimestamp {
+				break
+			}
+
+			changes.DeleteExpiry(entry)
+		}
+	}
+
 	if err := changes.Apply(parentState); err != nil {
 		return false, err
 	}
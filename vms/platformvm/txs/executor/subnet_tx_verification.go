@@ -22,7 +22,9 @@ var (
 
 // verifyPoASubnetAuthorization carries out the validation for modifying a PoA
 // subnet. This is an extension of [verifySubnetAuthorization] that additionally
-// verifies that the subnet being modified is currently a PoA subnet.
+// verifies that the subnet being modified is currently a PoA subnet, meaning
+// that it was neither transformed nor converted. The validators of a converted
+// subnet are instead managed by warp messages from its subnet manager.
 func verifyPoASubnetAuthorization(
 	backend *Backend,
 	chainState state.Chain,
@@ -43,6 +45,14 @@ func verifyPoASubnetAuthorization(
 		return nil, err
 	}
 
+	_, _, err = chainState.GetSubnetManager(subnetID)
+	if err == nil {
+		return nil, fmt.Errorf("%q %w", subnetID, errIsImmutable)
+	}
+	if err != database.ErrNotFound {
+		return nil, err
+	}
+
 	return creds, nil
 }
 
@@ -82,6 +82,22 @@ func (v *MempoolTxVerifier) BaseTx(tx *txs.BaseTx) error {
 	return v.standardTx(tx)
 }
 
+func (v *MempoolTxVerifier) ConvertSubnetTx(tx *txs.ConvertSubnetTx) error {
+	return v.standardTx(tx)
+}
+
+func (v *MempoolTxVerifier) IncreaseBalanceTx(tx *txs.IncreaseBalanceTx) error {
+	return v.standardTx(tx)
+}
+
+func (v *MempoolTxVerifier) RegisterSubnetValidatorTx(tx *txs.RegisterSubnetValidatorTx) error {
+	return v.standardTx(tx)
+}
+
+func (v *MempoolTxVerifier) SetSubnetValidatorWeightTx(tx *txs.SetSubnetValidatorWeightTx) error {
+	return v.standardTx(tx)
+}
+
 func (v *MempoolTxVerifier) standardTx(tx txs.UnsignedTx) error {
 	baseState, err := v.standardBaseState()
 	if err != nil {
@@ -0,0 +1,181 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow/validators"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/crypto/bls"
+	"github.com/skychains/chain/vms/platformvm/state"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/platformvm/warp"
+	"github.com/skychains/chain/vms/platformvm/warp/payload"
+)
+
+const (
+	// WarpQuorumNumerator and WarpQuorumDenominator define the fraction of the
+	// weight of the validators of a subnet manager's chain that must sign a
+	// warp message for it to be accepted by the P-chain.
+	WarpQuorumNumerator   = 67
+	WarpQuorumDenominator = 100
+)
+
+var (
+	_ validators.State = (*chainValidatorState)(nil)
+
+	errSubnetNotConverted     = errors.New("subnet is not converted")
+	errWrongWarpMessageSource = errors.New("warp message was not sent by the subnet manager")
+	errNotBlockchain          = errors.New("is not a blockchain")
+)
+
+// parseWarpMessage parses a signed warp message whose payload is an addressed
+// call.
+func parseWarpMessage(msgBytes []byte) (*warp.Message, *payload.AddressedCall, error) {
+	msg, err := warp.ParseMessage(msgBytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse warp message: %w", err)
+	}
+	addressedCall, err := payload.ParseAddressedCall(msg.Payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse warp message payload: %w", err)
+	}
+	return msg, addressedCall, nil
+}
+
+// verifyWarpMessage verifies that [msg] was sent by the manager of [subnetID]
+// and that it was signed by a quorum of the validators of the manager's chain.
+//
+// The validator set is taken from [chainState], rather than from a P-chain
+// height, so that every node executing the transaction on the same state
+// reaches the same result.
+func verifyWarpMessage(
+	backend *Backend,
+	chainState state.Chain,
+	subnetID ids.ID,
+	msg *warp.Message,
+	addressedCall *payload.AddressedCall,
+) error {
+	chainID, address, err := chainState.GetSubnetManager(subnetID)
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%w: %s", errSubnetNotConverted, subnetID)
+	}
+	if err != nil {
+		return err
+	}
+
+	if msg.SourceChainID != chainID || !bytes.Equal(addressedCall.SourceAddress, address) {
+		return fmt.Errorf(
+			"%w: expected %s:%x but got %s:%x",
+			errWrongWarpMessageSource,
+			chainID,
+			address,
+			msg.SourceChainID,
+			addressedCall.SourceAddress,
+		)
+	}
+
+	return msg.Signature.Verify(
+		context.Background(),
+		&msg.UnsignedMessage,
+		backend.Ctx.NetworkID,
+		&chainValidatorState{
+			chainState: chainState,
+		},
+		0, // The height is ignored by chainValidatorState
+		WarpQuorumNumerator,
+		WarpQuorumDenominator,
+	)
+}
+
+// chainValidatorState exposes the current validator sets of [chainState] as a
+// [validators.State]. Heights are ignored.
+type chainValidatorState struct {
+	chainState state.Chain
+}
+
+func (*chainValidatorState) GetMinimumHeight(context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (*chainValidatorState) GetCurrentHeight(context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (s *chainValidatorState) GetSubnetID(_ context.Context, chainID ids.ID) (ids.ID, error) {
+	if chainID == constants.PlatformChainID {
+		return constants.PrimaryNetworkID, nil
+	}
+
+	chainTx, _, err := s.chainState.GetTx(chainID)
+	if err != nil {
+		return ids.Empty, fmt.Errorf(
+			"problem retrieving blockchain %q: %w",
+			chainID,
+			err,
+		)
+	}
+	chain, ok := chainTx.Unsigned.(*txs.CreateChainTx)
+	if !ok {
+		return ids.Empty, fmt.Errorf("%q %w", chainID, errNotBlockchain)
+	}
+	return chain.SubnetID, nil
+}
+
+func (s *chainValidatorState) GetValidatorSet(
+	_ context.Context,
+	_ uint64,
+	subnetID ids.ID,
+) (map[ids.NodeID]*validators.GetValidatorOutput, error) {
+	currentValidators, err := s.chainState.GetCurrentValidators(subnetID)
+	if err != nil {
+		return nil, err
+	}
+	sovs, err := s.chainState.GetSubnetOnlyValidators(subnetID)
+	if err != nil {
+		return nil, err
+	}
+
+	vdrs := make(map[ids.NodeID]*validators.GetValidatorOutput, len(currentValidators)+len(sovs))
+	for _, staker := range currentValidators {
+		publicKey := staker.PublicKey
+		if publicKey == nil && subnetID != constants.PrimaryNetworkID {
+			// Subnet validators use the key they registered on the Primary
+			// Network.
+			primaryValidator, err := s.chainState.GetCurrentValidator(constants.PrimaryNetworkID, staker.NodeID)
+			switch err {
+			case nil:
+				publicKey = primaryValidator.PublicKey
+			case database.ErrNotFound:
+			default:
+				return nil, err
+			}
+		}
+
+		vdrs[staker.NodeID] = &validators.GetValidatorOutput{
+			NodeID:    staker.NodeID,
+			PublicKey: publicKey,
+			Weight:    staker.Weight,
+		}
+	}
+
+	for _, sov := range sovs {
+		if !sov.IsActive() {
+			continue
+		}
+
+		vdrs[sov.NodeID] = &validators.GetValidatorOutput{
+			NodeID:    sov.NodeID,
+			PublicKey: bls.PublicKeyFromValidUncompressedBytes(sov.PublicKey),
+			Weight:    sov.Weight,
+		}
+	}
+	return vdrs, nil
+}
@@ -0,0 +1,776 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fee
+
+import (
+	"errors"
+
+	"github.com/skychains/chain/codec"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/crypto/bls"
+	"github.com/skychains/chain/utils/crypto/secp256k1"
+	"github.com/skychains/chain/utils/wrappers"
+	"github.com/skychains/chain/vms/components/gas"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/components/verify"
+	"github.com/skychains/chain/vms/platformvm/fx"
+	"github.com/skychains/chain/vms/platformvm/signer"
+	"github.com/skychains/chain/vms/platformvm/stakeable"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/secp256k1fx"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+// Signature verification costs were conservatively based on benchmarks run on
+// an AWS c5.xlarge instance.
+const (
+	intrinsicValidatorBandwidth = ids.NodeIDLen + // nodeID
+		wrappers.LongLen + // start
+		wrappers.LongLen + // end
+		wrappers.LongLen // weight
+
+	intrinsicSubnetValidatorBandwidth = intrinsicValidatorBandwidth + // validator
+		ids.IDLen // subnetID
+
+	intrinsicOutputBandwidth = ids.IDLen + // assetID
+		wrappers.IntLen // output typeID
+
+	intrinsicStakeableLockedOutputBandwidth = wrappers.LongLen + // locktime
+		wrappers.IntLen // output typeID
+
+	intrinsicSECP256k1FxOutputOwnersBandwidth = wrappers.LongLen + // locktime
+		wrappers.IntLen + // threshold
+		wrappers.IntLen // num addresses
+
+	intrinsicSECP256k1FxOutputBandwidth = wrappers.LongLen + // amount
+		intrinsicSECP256k1FxOutputOwnersBandwidth
+
+	intrinsicInputBandwidth = ids.IDLen + // txID
+		wrappers.IntLen + // output index
+		ids.IDLen + // assetID
+		wrappers.IntLen + // input typeID
+		wrappers.IntLen // credential typeID
+
+	intrinsicStakeableLockedInputBandwidth = wrappers.LongLen + // locktime
+		wrappers.IntLen // input typeID
+
+	intrinsicSECP256k1FxInputBandwidth = wrappers.IntLen + // num indices
+		wrappers.IntLen // num signatures
+
+	intrinsicSECP256k1FxTransferableInputBandwidth = wrappers.LongLen + // amount
+		intrinsicSECP256k1FxInputBandwidth
+
+	intrinsicSECP256k1FxSignatureBandwidth = wrappers.IntLen + // signature index
+		secp256k1.SignatureLen // signature length
+
+	intrinsicSECP256k1FxSignatureCompute = 200 // secp256k1 signature verification time is around 200us
+
+	intrinsicPoPBandwidth = bls.PublicKeyLen + // public key
+		bls.SignatureLen // signature
+
+	intrinsicPoPCompute = 1_050 // BLS PoP verification time is around 1.05ms
+
+	intrinsicWarpSignatureCompute = 1_050 // BLS aggregate signature verification time is around 1.05ms
+
+	intrinsicConvertSubnetValidatorBandwidth = ids.NodeIDLen + // nodeID
+		wrappers.LongLen + // weight
+		wrappers.LongLen + // balance
+		intrinsicPoPBandwidth + // signer
+		wrappers.IntLen // remaining balance owner typeID
+
+	intrinsicInputDBRead = 1
+
+	intrinsicInputDBWrite  = 1
+	intrinsicOutputDBWrite = 1
+)
+
+var (
+	_ txs.Visitor = (*complexityVisitor)(nil)
+
+	IntrinsicAddPermissionlessValidatorTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			intrinsicValidatorBandwidth + // validator
+			ids.IDLen + // subnetID
+			wrappers.IntLen + // signer typeID
+			wrappers.IntLen + // num stake outs
+			wrappers.IntLen + // validator rewards typeID
+			wrappers.IntLen + // delegator rewards typeID
+			wrappers.IntLen, // delegation shares
+		gas.DBRead:  1, // subnet transformation
+		gas.DBWrite: 1, // validator
+	}
+	IntrinsicAddPermissionlessDelegatorTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			intrinsicValidatorBandwidth + // validator
+			ids.IDLen + // subnetID
+			wrappers.IntLen + // num stake outs
+			wrappers.IntLen, // delegator rewards typeID
+		gas.DBRead:  1, // validator
+		gas.DBWrite: 1, // delegator
+	}
+	IntrinsicAddSubnetValidatorTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			intrinsicSubnetValidatorBandwidth + // subnetValidator
+			wrappers.IntLen + // subnetAuth typeID
+			wrappers.IntLen, // subnetAuthCredential typeID
+		gas.DBRead:  2, // primary network validator + subnet owner
+		gas.DBWrite: 1, // subnet validator
+	}
+	IntrinsicBaseTxComplexities = gas.Dimensions{
+		gas.Bandwidth: codec.VersionSize + // codecVersion
+			wrappers.IntLen + // typeID
+			wrappers.IntLen + // networkID
+			ids.IDLen + // blockchainID
+			wrappers.IntLen + // number of outputs
+			wrappers.IntLen + // number of inputs
+			wrappers.IntLen + // length of memo
+			wrappers.IntLen, // number of credentials
+	}
+	IntrinsicConvertSubnetTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // subnetID
+			ids.IDLen + // chainID
+			wrappers.IntLen + // address length
+			wrappers.IntLen + // num validators
+			wrappers.IntLen + // subnetAuth typeID
+			wrappers.IntLen, // subnetAuthCredential typeID
+		gas.DBRead:  3, // subnet owner + subnet transformation + subnet manager
+		gas.DBWrite: 1, // subnet manager
+	}
+	IntrinsicCreateChainTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // subnetID
+			wrappers.ShortLen + // chainName length
+			ids.IDLen + // vmID
+			wrappers.IntLen + // num fxIDs
+			wrappers.IntLen + // genesis length
+			wrappers.IntLen + // subnetAuth typeID
+			wrappers.IntLen, // subnetAuthCredential typeID
+		gas.DBRead:  1, // subnet owner
+		gas.DBWrite: 1, // chain
+	}
+	IntrinsicCreateSubnetTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			wrappers.IntLen, // owner typeID
+		gas.DBWrite: 1, // subnet owner
+	}
+	IntrinsicExportTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // destination chainID
+			wrappers.IntLen, // num exported outputs
+	}
+	IntrinsicImportTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // source chainID
+			wrappers.IntLen, // num importing inputs
+	}
+	IntrinsicIncreaseBalanceTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // validationID
+			wrappers.LongLen, // balance
+		gas.DBRead:  1, // validator
+		gas.DBWrite: 1, // validator
+	}
+	IntrinsicRegisterSubnetValidatorTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			wrappers.LongLen + // balance
+			bls.SignatureLen + // proof of possession
+			wrappers.IntLen, // message length
+		gas.DBRead:  3, // subnet manager + expiry + existing validator
+		gas.DBWrite: 2, // validator + expiry
+		gas.Compute: intrinsicPoPCompute + intrinsicWarpSignatureCompute,
+	}
+	IntrinsicRemoveSubnetValidatorTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.NodeIDLen + // nodeID
+			ids.IDLen + // subnetID
+			wrappers.IntLen + // subnetAuth typeID
+			wrappers.IntLen, // subnetAuthCredential typeID
+		gas.DBRead:  2, // subnet validator + subnet owner
+		gas.DBWrite: 1, // subnet validator
+	}
+	IntrinsicSetSubnetValidatorWeightTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			wrappers.IntLen, // message length
+		gas.DBRead:  2, // validator + subnet manager
+		gas.DBWrite: 2, // validator + remaining balance utxo
+		gas.Compute: intrinsicWarpSignatureCompute,
+	}
+	IntrinsicTransferSubnetOwnershipTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // subnetID
+			wrappers.IntLen + // subnetAuth typeID
+			wrappers.IntLen + // owner typeID
+			wrappers.IntLen, // subnetAuthCredential typeID
+		gas.DBRead:  1, // subnet owner
+		gas.DBWrite: 1, // subnet owner
+	}
+	IntrinsicTransformSubnetTxComplexities = gas.Dimensions{
+		gas.Bandwidth: IntrinsicBaseTxComplexities[gas.Bandwidth] +
+			ids.IDLen + // subnetID
+			ids.IDLen + // assetID
+			wrappers.LongLen + // initial supply
+			wrappers.LongLen + // maximum supply
+			wrappers.LongLen + // min consumption rate
+			wrappers.LongLen + // max consumption rate
+			wrappers.LongLen + // min validator stake
+			wrappers.LongLen + // max validator stake
+			wrappers.IntLen + // min stake duration
+			wrappers.IntLen + // max stake duration
+			wrappers.IntLen + // min delegation fee
+			wrappers.LongLen + // min delegator stake
+			wrappers.ByteLen + // max validator weight factor
+			wrappers.IntLen + // uptime requirement
+			wrappers.IntLen + // subnetAuth typeID
+			wrappers.IntLen, // subnetAuthCredential typeID
+		gas.DBRead:  2, // subnet owner + subnet transformation
+		gas.DBWrite: 1, // subnet transformation
+	}
+
+	ErrUnsupportedTx     = errors.New("unsupported transaction type")
+	errUnsupportedOutput = errors.New("unsupported output type")
+	errUnsupportedInput  = errors.New("unsupported input type")
+	errUnsupportedOwner  = errors.New("unsupported owner type")
+	errUnsupportedAuth   = errors.New("unsupported auth type")
+	errUnsupportedSigner = errors.New("unsupported signer type")
+)
+
+// TxComplexity returns the complexity of the provided txs.
+func TxComplexity(txs ...txs.UnsignedTx) (gas.Dimensions, error) {
+	var complexity gas.Dimensions
+	for _, tx := range txs {
+		c := complexityVisitor{}
+		if err := tx.Visit(&c); err != nil {
+			return gas.Dimensions{}, err
+		}
+
+		var err error
+		complexity, err = complexity.Add(&c.output)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+	}
+	return complexity, nil
+}
+
+// OutputComplexity returns the complexity outputs add to a transaction.
+func OutputComplexity(outs ...*lux.TransferableOutput) (gas.Dimensions, error) {
+	var complexity gas.Dimensions
+	for _, out := range outs {
+		outputComplexity, err := outputComplexity(out)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+
+		complexity, err = complexity.Add(&outputComplexity)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+	}
+	return complexity, nil
+}
+
+func outputComplexity(out *lux.TransferableOutput) (gas.Dimensions, error) {
+	complexity := gas.Dimensions{
+		gas.Bandwidth: intrinsicOutputBandwidth + intrinsicSECP256k1FxOutputBandwidth,
+		gas.DBWrite:   intrinsicOutputDBWrite,
+	}
+
+	outIntf := out.Out
+	if stakeableOut, ok := outIntf.(*stakeable.LockOut); ok {
+		complexity[gas.Bandwidth] += intrinsicStakeableLockedOutputBandwidth
+		outIntf = stakeableOut.TransferableOut
+	}
+
+	secp256k1Out, ok := outIntf.(*secp256k1fx.TransferOutput)
+	if !ok {
+		return gas.Dimensions{}, errUnsupportedOutput
+	}
+
+	numAddresses := uint64(len(secp256k1Out.Addrs))
+	addressBandwidth, err := safemath.Mul64(numAddresses, ids.ShortIDLen)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+	complexity[gas.Bandwidth], err = safemath.Add64(complexity[gas.Bandwidth], addressBandwidth)
+	return complexity, err
+}
+
+// InputComplexity returns the complexity inputs add to a transaction. It
+// includes the complexity that the corresponding credentials will add.
+func InputComplexity(ins ...*lux.TransferableInput) (gas.Dimensions, error) {
+	var complexity gas.Dimensions
+	for _, in := range ins {
+		inputComplexity, err := inputComplexity(in)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+
+		complexity, err = complexity.Add(&inputComplexity)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+	}
+	return complexity, nil
+}
+
+func inputComplexity(in *lux.TransferableInput) (gas.Dimensions, error) {
+	complexity := gas.Dimensions{
+		gas.Bandwidth: intrinsicInputBandwidth + intrinsicSECP256k1FxTransferableInputBandwidth,
+		gas.DBRead:    intrinsicInputDBRead,
+		gas.DBWrite:   intrinsicInputDBWrite,
+	}
+
+	inIntf := in.In
+	if stakeableIn, ok := inIntf.(*stakeable.LockIn); ok {
+		complexity[gas.Bandwidth] += intrinsicStakeableLockedInputBandwidth
+		inIntf = stakeableIn.TransferableIn
+	}
+
+	secp256k1In, ok := inIntf.(*secp256k1fx.TransferInput)
+	if !ok {
+		return gas.Dimensions{}, errUnsupportedInput
+	}
+
+	numSignatures := uint64(len(secp256k1In.SigIndices))
+	// Add signature bandwidth
+	signatureBandwidth, err := safemath.Mul64(numSignatures, intrinsicSECP256k1FxSignatureBandwidth)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+	complexity[gas.Bandwidth], err = safemath.Add64(complexity[gas.Bandwidth], signatureBandwidth)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	// Add signature compute
+	complexity[gas.Compute], err = safemath.Mul64(numSignatures, intrinsicSECP256k1FxSignatureCompute)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+	return complexity, nil
+}
+
+// OwnerComplexity returns the complexity an owner adds to a transaction.
+// It does not include the typeID of the owner.
+func OwnerComplexity(ownerIntf fx.Owner) (gas.Dimensions, error) {
+	owner, ok := ownerIntf.(*secp256k1fx.OutputOwners)
+	if !ok {
+		return gas.Dimensions{}, errUnsupportedOwner
+	}
+
+	numAddresses := uint64(len(owner.Addrs))
+	addressBandwidth, err := safemath.Mul64(numAddresses, ids.ShortIDLen)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	bandwidth, err := safemath.Add64(addressBandwidth, intrinsicSECP256k1FxOutputOwnersBandwidth)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	return gas.Dimensions{
+		gas.Bandwidth: bandwidth,
+	}, nil
+}
+
+// AuthComplexity returns the complexity an authorization adds to a
+// transaction. It does not include the typeID of the authorization. It does
+// include the complexity that the corresponding credential will add. It does
+// not include the typeID of the credential.
+func AuthComplexity(authIntf verify.Verifiable) (gas.Dimensions, error) {
+	auth, ok := authIntf.(*secp256k1fx.Input)
+	if !ok {
+		return gas.Dimensions{}, errUnsupportedAuth
+	}
+
+	numSignatures := uint64(len(auth.SigIndices))
+	signatureBandwidth, err := safemath.Mul64(numSignatures, intrinsicSECP256k1FxSignatureBandwidth)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	bandwidth, err := safemath.Add64(signatureBandwidth, intrinsicSECP256k1FxInputBandwidth)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	signatureCompute, err := safemath.Mul64(numSignatures, intrinsicSECP256k1FxSignatureCompute)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	return gas.Dimensions{
+		gas.Bandwidth: bandwidth,
+		gas.Compute:   signatureCompute,
+	}, nil
+}
+
+// SignerComplexity returns the complexity a signer adds to a transaction.
+// It does not include the typeID of the signer.
+func SignerComplexity(s signer.Signer) (gas.Dimensions, error) {
+	switch s.(type) {
+	case *signer.Empty:
+		return gas.Dimensions{}, nil
+	case *signer.ProofOfPossession:
+		return gas.Dimensions{
+			gas.Bandwidth: intrinsicPoPBandwidth,
+			gas.Compute:   intrinsicPoPCompute,
+		}, nil
+	default:
+		return gas.Dimensions{}, errUnsupportedSigner
+	}
+}
+
+// ConvertSubnetValidatorComplexity returns the complexity the validators add
+// to a ConvertSubnetTx.
+func ConvertSubnetValidatorComplexity(vdrs ...*txs.ConvertSubnetValidator) (gas.Dimensions, error) {
+	var complexity gas.Dimensions
+	for _, vdr := range vdrs {
+		vdrComplexity, err := convertSubnetValidatorComplexity(vdr)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+
+		complexity, err = complexity.Add(&vdrComplexity)
+		if err != nil {
+			return gas.Dimensions{}, err
+		}
+	}
+	return complexity, nil
+}
+
+func convertSubnetValidatorComplexity(vdr *txs.ConvertSubnetValidator) (gas.Dimensions, error) {
+	ownerComplexity, err := OwnerComplexity(vdr.RemainingBalanceOwner)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+
+	complexity := gas.Dimensions{
+		gas.Bandwidth: intrinsicConvertSubnetValidatorBandwidth,
+		gas.DBRead:    1, // existing validator
+		gas.DBWrite:   1, // validator
+		gas.Compute:   intrinsicPoPCompute,
+	}
+	return complexity.Add(&ownerComplexity)
+}
+
+type complexityVisitor struct {
+	output gas.Dimensions
+}
+
+func (*complexityVisitor) AddValidatorTx(*txs.AddValidatorTx) error {
+	return ErrUnsupportedTx
+}
+
+func (*complexityVisitor) AddDelegatorTx(*txs.AddDelegatorTx) error {
+	return ErrUnsupportedTx
+}
+
+func (*complexityVisitor) AdvanceTimeTx(*txs.AdvanceTimeTx) error {
+	return ErrUnsupportedTx
+}
+
+func (*complexityVisitor) RewardValidatorTx(*txs.RewardValidatorTx) error {
+	return ErrUnsupportedTx
+}
+
+func (c *complexityVisitor) AddPermissionlessValidatorTx(tx *txs.AddPermissionlessValidatorTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	signerComplexity, err := SignerComplexity(tx.Signer)
+	if err != nil {
+		return err
+	}
+	outputsComplexity, err := OutputComplexity(tx.StakeOuts...)
+	if err != nil {
+		return err
+	}
+	validatorOwnerComplexity, err := OwnerComplexity(tx.ValidatorRewardsOwner)
+	if err != nil {
+		return err
+	}
+	delegatorOwnerComplexity, err := OwnerComplexity(tx.DelegatorRewardsOwner)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicAddPermissionlessValidatorTxComplexities.Add(
+		&baseTxComplexity,
+		&signerComplexity,
+		&outputsComplexity,
+		&validatorOwnerComplexity,
+		&delegatorOwnerComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) AddPermissionlessDelegatorTx(tx *txs.AddPermissionlessDelegatorTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	ownerComplexity, err := OwnerComplexity(tx.DelegationRewardsOwner)
+	if err != nil {
+		return err
+	}
+	outputsComplexity, err := OutputComplexity(tx.StakeOuts...)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicAddPermissionlessDelegatorTxComplexities.Add(
+		&baseTxComplexity,
+		&ownerComplexity,
+		&outputsComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) AddSubnetValidatorTx(tx *txs.AddSubnetValidatorTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	authComplexity, err := AuthComplexity(tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicAddSubnetValidatorTxComplexities.Add(
+		&baseTxComplexity,
+		&authComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) BaseTx(tx *txs.BaseTx) error {
+	baseTxComplexity, err := baseTxComplexity(tx)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicBaseTxComplexities.Add(&baseTxComplexity)
+	return err
+}
+
+func (c *complexityVisitor) ConvertSubnetTx(tx *txs.ConvertSubnetTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	validatorsComplexity, err := ConvertSubnetValidatorComplexity(tx.Validators...)
+	if err != nil {
+		return err
+	}
+	authComplexity, err := AuthComplexity(tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+	addressComplexity := gas.Dimensions{
+		gas.Bandwidth: uint64(len(tx.Address)),
+	}
+	c.output, err = IntrinsicConvertSubnetTxComplexities.Add(
+		&baseTxComplexity,
+		&validatorsComplexity,
+		&authComplexity,
+		&addressComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) CreateChainTx(tx *txs.CreateChainTx) error {
+	bandwidth, err := safemath.Mul64(uint64(len(tx.FxIDs)), ids.IDLen)
+	if err != nil {
+		return err
+	}
+	bandwidth, err = safemath.Add64(bandwidth, uint64(len(tx.ChainName)))
+	if err != nil {
+		return err
+	}
+	bandwidth, err = safemath.Add64(bandwidth, uint64(len(tx.GenesisData)))
+	if err != nil {
+		return err
+	}
+	dynamicComplexity := gas.Dimensions{
+		gas.Bandwidth: bandwidth,
+	}
+
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	authComplexity, err := AuthComplexity(tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicCreateChainTxComplexities.Add(
+		&dynamicComplexity,
+		&baseTxComplexity,
+		&authComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) CreateSubnetTx(tx *txs.CreateSubnetTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	ownerComplexity, err := OwnerComplexity(tx.Owner)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicCreateSubnetTxComplexities.Add(
+		&baseTxComplexity,
+		&ownerComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) ExportTx(tx *txs.ExportTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	outputsComplexity, err := OutputComplexity(tx.ExportedOutputs...)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicExportTxComplexities.Add(
+		&baseTxComplexity,
+		&outputsComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) ImportTx(tx *txs.ImportTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	inputsComplexity, err := InputComplexity(tx.ImportedInputs...)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicImportTxComplexities.Add(
+		&baseTxComplexity,
+		&inputsComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) IncreaseBalanceTx(tx *txs.IncreaseBalanceTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicIncreaseBalanceTxComplexities.Add(&baseTxComplexity)
+	return err
+}
+
+func (c *complexityVisitor) RegisterSubnetValidatorTx(tx *txs.RegisterSubnetValidatorTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	messageComplexity := gas.Dimensions{
+		gas.Bandwidth: uint64(len(tx.Message)),
+	}
+	c.output, err = IntrinsicRegisterSubnetValidatorTxComplexities.Add(
+		&baseTxComplexity,
+		&messageComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) RemoveSubnetValidatorTx(tx *txs.RemoveSubnetValidatorTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	authComplexity, err := AuthComplexity(tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicRemoveSubnetValidatorTxComplexities.Add(
+		&baseTxComplexity,
+		&authComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) TransformSubnetTx(tx *txs.TransformSubnetTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	authComplexity, err := AuthComplexity(tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicTransformSubnetTxComplexities.Add(
+		&baseTxComplexity,
+		&authComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) SetSubnetValidatorWeightTx(tx *txs.SetSubnetValidatorWeightTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	messageComplexity := gas.Dimensions{
+		gas.Bandwidth: uint64(len(tx.Message)),
+	}
+	c.output, err = IntrinsicSetSubnetValidatorWeightTxComplexities.Add(
+		&baseTxComplexity,
+		&messageComplexity,
+	)
+	return err
+}
+
+func (c *complexityVisitor) TransferSubnetOwnershipTx(tx *txs.TransferSubnetOwnershipTx) error {
+	baseTxComplexity, err := baseTxComplexity(&tx.BaseTx)
+	if err != nil {
+		return err
+	}
+	authComplexity, err := AuthComplexity(tx.SubnetAuth)
+	if err != nil {
+		return err
+	}
+	ownerComplexity, err := OwnerComplexity(tx.Owner)
+	if err != nil {
+		return err
+	}
+	c.output, err = IntrinsicTransferSubnetOwnershipTxComplexities.Add(
+		&baseTxComplexity,
+		&authComplexity,
+		&ownerComplexity,
+	)
+	return err
+}
+
+// baseTxComplexity returns the complexity of the outputs, inputs and memo of
+// [tx]. It does not include the intrinsic complexity of the tx.
+func baseTxComplexity(tx *txs.BaseTx) (gas.Dimensions, error) {
+	outputsComplexity, err := OutputComplexity(tx.Outs...)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+	inputsComplexity, err := InputComplexity(tx.Ins...)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+	complexity, err := outputsComplexity.Add(&inputsComplexity)
+	if err != nil {
+		return gas.Dimensions{}, err
+	}
+	complexity[gas.Bandwidth], err = safemath.Add64(
+		complexity[gas.Bandwidth],
+		uint64(len(tx.Memo)),
+	)
+	return complexity, err
+}
@@ -0,0 +1,449 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fee
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/vms/components/gas"
+	"github.com/skychains/chain/vms/components/lux"
+	"github.com/skychains/chain/vms/components/verify"
+	"github.com/skychains/chain/vms/platformvm/fx"
+	"github.com/skychains/chain/vms/platformvm/signer"
+	"github.com/skychains/chain/vms/platformvm/stakeable"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/secp256k1fx"
+)
+
+func TestTxComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		tx          txs.UnsignedTx
+		expected    gas.Dimensions
+		expectedErr error
+	}{
+		{
+			name: "empty BaseTx",
+			tx:   &txs.BaseTx{},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 58,
+			},
+		},
+		{
+			name: "BaseTx with memo",
+			tx: &txs.BaseTx{
+				BaseTx: lux.BaseTx{
+					Memo: []byte{1, 2, 3},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 61,
+			},
+		},
+		{
+			name: "BaseTx with input and output",
+			tx: &txs.BaseTx{
+				BaseTx: lux.BaseTx{
+					Ins: []*lux.TransferableInput{
+						{
+							In: &secp256k1fx.TransferInput{
+								Input: secp256k1fx.Input{
+									SigIndices: []uint32{0},
+								},
+							},
+						},
+					},
+					Outs: []*lux.TransferableOutput{
+						{
+							Out: &secp256k1fx.TransferOutput{
+								OutputOwners: secp256k1fx.OutputOwners{
+									Addrs: []ids.ShortID{ids.GenerateTestShortID()},
+								},
+							},
+						},
+					},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 58 + 161 + 80,
+				gas.DBRead:    1,
+				gas.DBWrite:   2,
+				gas.Compute:   200,
+			},
+		},
+		{
+			name: "ConvertSubnetTx",
+			tx: &txs.ConvertSubnetTx{
+				Address: make([]byte, 20),
+				Validators: []*txs.ConvertSubnetValidator{
+					{
+						RemainingBalanceOwner: &secp256k1fx.OutputOwners{},
+					},
+				},
+				SubnetAuth: &secp256k1fx.Input{},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 138 + 200 + 8 + 20,
+				gas.DBRead:    4,
+				gas.DBWrite:   2,
+				gas.Compute:   1_050,
+			},
+		},
+		{
+			name: "IncreaseBalanceTx",
+			tx:   &txs.IncreaseBalanceTx{},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 98,
+				gas.DBRead:    1,
+				gas.DBWrite:   1,
+			},
+		},
+		{
+			name: "RegisterSubnetValidatorTx",
+			tx: &txs.RegisterSubnetValidatorTx{
+				Message: make([]byte, 10),
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 166 + 10,
+				gas.DBRead:    3,
+				gas.DBWrite:   2,
+				gas.Compute:   2_100,
+			},
+		},
+		{
+			name: "SetSubnetValidatorWeightTx",
+			tx: &txs.SetSubnetValidatorWeightTx{
+				Message: make([]byte, 10),
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 62 + 10,
+				gas.DBRead:    2,
+				gas.DBWrite:   2,
+				gas.Compute:   1_050,
+			},
+		},
+		{
+			name:        "AdvanceTimeTx",
+			tx:          &txs.AdvanceTimeTx{},
+			expectedErr: ErrUnsupportedTx,
+		},
+		{
+			name:        "RewardValidatorTx",
+			tx:          &txs.RewardValidatorTx{},
+			expectedErr: ErrUnsupportedTx,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := TxComplexity(test.tx)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestOutputComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		outs        []*lux.TransferableOutput
+		expected    gas.Dimensions
+		expectedErr error
+	}{
+		{
+			name:     "no outputs",
+			outs:     []*lux.TransferableOutput{},
+			expected: gas.Dimensions{},
+		},
+		{
+			name: "any can spend",
+			outs: []*lux.TransferableOutput{
+				{
+					Out: &secp256k1fx.TransferOutput{},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 60,
+				gas.DBWrite:   1,
+			},
+		},
+		{
+			name: "one owner",
+			outs: []*lux.TransferableOutput{
+				{
+					Out: &secp256k1fx.TransferOutput{
+						OutputOwners: secp256k1fx.OutputOwners{
+							Addrs: make([]ids.ShortID, 1),
+						},
+					},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 80,
+				gas.DBWrite:   1,
+			},
+		},
+		{
+			name: "locked stakeable",
+			outs: []*lux.TransferableOutput{
+				{
+					Out: &stakeable.LockOut{
+						TransferableOut: &secp256k1fx.TransferOutput{
+							OutputOwners: secp256k1fx.OutputOwners{
+								Addrs: make([]ids.ShortID, 1),
+							},
+						},
+					},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 92,
+				gas.DBWrite:   1,
+			},
+		},
+		{
+			name: "multiple outputs",
+			outs: []*lux.TransferableOutput{
+				{
+					Out: &secp256k1fx.TransferOutput{},
+				},
+				{
+					Out: &secp256k1fx.TransferOutput{
+						OutputOwners: secp256k1fx.OutputOwners{
+							Addrs: make([]ids.ShortID, 1),
+						},
+					},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 60 + 80,
+				gas.DBWrite:   2,
+			},
+		},
+		{
+			name: "invalid output type",
+			outs: []*lux.TransferableOutput{
+				{
+					Out: nil,
+				},
+			},
+			expectedErr: errUnsupportedOutput,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := OutputComplexity(test.outs...)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestInputComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		ins         []*lux.TransferableInput
+		expected    gas.Dimensions
+		expectedErr error
+	}{
+		{
+			name:     "no inputs",
+			ins:      []*lux.TransferableInput{},
+			expected: gas.Dimensions{},
+		},
+		{
+			name: "any can spend",
+			ins: []*lux.TransferableInput{
+				{
+					In: &secp256k1fx.TransferInput{},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 92,
+				gas.DBRead:    1,
+				gas.DBWrite:   1,
+			},
+		},
+		{
+			name: "one signature",
+			ins: []*lux.TransferableInput{
+				{
+					In: &secp256k1fx.TransferInput{
+						Input: secp256k1fx.Input{
+							SigIndices: []uint32{0},
+						},
+					},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 161,
+				gas.DBRead:    1,
+				gas.DBWrite:   1,
+				gas.Compute:   200,
+			},
+		},
+		{
+			name: "locked stakeable",
+			ins: []*lux.TransferableInput{
+				{
+					In: &stakeable.LockIn{
+						TransferableIn: &secp256k1fx.TransferInput{
+							Input: secp256k1fx.Input{
+								SigIndices: []uint32{0},
+							},
+						},
+					},
+				},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 173,
+				gas.DBRead:    1,
+				gas.DBWrite:   1,
+				gas.Compute:   200,
+			},
+		},
+		{
+			name: "invalid input type",
+			ins: []*lux.TransferableInput{
+				{
+					In: nil,
+				},
+			},
+			expectedErr: errUnsupportedInput,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := InputComplexity(test.ins...)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestOwnerComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		owner       fx.Owner
+		expected    gas.Dimensions
+		expectedErr error
+	}{
+		{
+			name:  "any can spend",
+			owner: &secp256k1fx.OutputOwners{},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 16,
+			},
+		},
+		{
+			name: "one owner",
+			owner: &secp256k1fx.OutputOwners{
+				Addrs: make([]ids.ShortID, 1),
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 36,
+			},
+		},
+		{
+			name:        "invalid owner type",
+			owner:       nil,
+			expectedErr: errUnsupportedOwner,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := OwnerComplexity(test.owner)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestAuthComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		auth        verify.Verifiable
+		expected    gas.Dimensions
+		expectedErr error
+	}{
+		{
+			name: "any can spend",
+			auth: &secp256k1fx.Input{},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 8,
+			},
+		},
+		{
+			name: "one signature",
+			auth: &secp256k1fx.Input{
+				SigIndices: []uint32{0},
+			},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 77,
+				gas.Compute:   200,
+			},
+		},
+		{
+			name:        "invalid auth type",
+			auth:        nil,
+			expectedErr: errUnsupportedAuth,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := AuthComplexity(test.auth)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
+
+func TestSignerComplexity(t *testing.T) {
+	tests := []struct {
+		name        string
+		signer      signer.Signer
+		expected    gas.Dimensions
+		expectedErr error
+	}{
+		{
+			name:     "empty",
+			signer:   &signer.Empty{},
+			expected: gas.Dimensions{},
+		},
+		{
+			name:   "proof of possession",
+			signer: &signer.ProofOfPossession{},
+			expected: gas.Dimensions{
+				gas.Bandwidth: 144,
+				gas.Compute:   1_050,
+			},
+		},
+		{
+			name:        "invalid signer type",
+			signer:      nil,
+			expectedErr: errUnsupportedSigner,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			actual, err := SignerComplexity(test.signer)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, actual)
+		})
+	}
+}
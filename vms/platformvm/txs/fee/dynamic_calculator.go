@@ -0,0 +1,41 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fee
+
+import (
+	"github.com/skychains/chain/vms/components/gas"
+	"github.com/skychains/chain/vms/platformvm/txs"
+)
+
+var _ Calculator = (*dynamicCalculator)(nil)
+
+// NewDynamicCalculator returns a calculator that charges [price] for every
+// unit of gas a tx consumes, where the gas of a tx is its complexity merged
+// with [weights].
+func NewDynamicCalculator(
+	weights gas.Dimensions,
+	price gas.Price,
+) Calculator {
+	return &dynamicCalculator{
+		weights: weights,
+		price:   price,
+	}
+}
+
+type dynamicCalculator struct {
+	weights gas.Dimensions
+	price   gas.Price
+}
+
+func (c *dynamicCalculator) CalculateFee(tx txs.UnsignedTx) (uint64, error) {
+	complexity, err := TxComplexity(tx)
+	if err != nil {
+		return 0, err
+	}
+	gas, err := complexity.ToGas(c.weights)
+	if err != nil {
+		return 0, err
+	}
+	return gas.Cost(c.price)
+}
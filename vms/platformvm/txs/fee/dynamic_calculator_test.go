@@ -0,0 +1,66 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fee
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/vms/components/gas"
+	"github.com/skychains/chain/vms/platformvm/txs"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+func TestDynamicCalculator(t *testing.T) {
+	tests := []struct {
+		name        string
+		weights     gas.Dimensions
+		price       gas.Price
+		tx          txs.UnsignedTx
+		expected    uint64
+		expectedErr error
+	}{
+		{
+			name:     "zero price",
+			weights:  gas.Dimensions{1, 1, 1, 1},
+			price:    0,
+			tx:       &txs.BaseTx{},
+			expected: 0,
+		},
+		{
+			name:     "weighted bandwidth",
+			weights:  gas.Dimensions{2, 1, 1, 1},
+			price:    10,
+			tx:       &txs.BaseTx{},
+			expected: 58 * 2 * 10,
+		},
+		{
+			name:        "cost overflow",
+			weights:     gas.Dimensions{1, 1, 1, 1},
+			price:       math.MaxUint64,
+			tx:          &txs.BaseTx{},
+			expectedErr: safemath.ErrOverflow,
+		},
+		{
+			name:        "unsupported tx",
+			weights:     gas.Dimensions{1, 1, 1, 1},
+			price:       1,
+			tx:          &txs.AdvanceTimeTx{},
+			expectedErr: ErrUnsupportedTx,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			c := NewDynamicCalculator(test.weights, test.price)
+			fee, err := c.CalculateFee(test.tx)
+			require.ErrorIs(err, test.expectedErr)
+			require.Equal(test.expected, fee)
+		})
+	}
+}
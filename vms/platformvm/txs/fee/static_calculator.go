@@ -0,0 +1,166 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package fee
+
+import (
+	"time"
+
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/platformvm/upgrade"
+)
+
+var (
+	_ Calculator  = (*staticCalculator)(nil)
+	_ txs.Visitor = (*staticVisitor)(nil)
+)
+
+// NewStaticCalculator returns a calculator of the flat per-tx-type fees in
+// [config] that apply at chain time [chainTime].
+func NewStaticCalculator(
+	config StaticConfig,
+	upgradeTimes upgrade.Config,
+	chainTime time.Time,
+) Calculator {
+	return &staticCalculator{
+		config:       config,
+		upgradeTimes: upgradeTimes,
+		chainTime:    chainTime,
+	}
+}
+
+type staticCalculator struct {
+	config       StaticConfig
+	upgradeTimes upgrade.Config
+	chainTime    time.Time
+}
+
+func (c *staticCalculator) CalculateFee(tx txs.UnsignedTx) (uint64, error) {
+	v := staticVisitor{
+		upgrades:  c.upgradeTimes,
+		staticCfg: c.config,
+		time:      c.chainTime,
+	}
+	err := tx.Visit(&v)
+	return v.fee, err
+}
+
+// staticVisitor is intentionally unexported and used through
+// staticCalculator to provide a more convenient API
+type staticVisitor struct {
+	// inputs
+	upgrades  upgrade.Config
+	staticCfg StaticConfig
+	time      time.Time
+
+	// outputs of visitor execution
+	fee uint64
+}
+
+func (c *staticVisitor) AddValidatorTx(*txs.AddValidatorTx) error {
+	c.fee = c.staticCfg.AddPrimaryNetworkValidatorFee
+	return nil
+}
+
+func (c *staticVisitor) AddSubnetValidatorTx(*txs.AddSubnetValidatorTx) error {
+	c.fee = c.staticCfg.AddSubnetValidatorFee
+	return nil
+}
+
+func (c *staticVisitor) AddDelegatorTx(*txs.AddDelegatorTx) error {
+	c.fee = c.staticCfg.AddPrimaryNetworkDelegatorFee
+	return nil
+}
+
+func (c *staticVisitor) CreateChainTx(*txs.CreateChainTx) error {
+	if c.upgrades.IsApricotPhase3Activated(c.time) {
+		c.fee = c.staticCfg.CreateBlockchainTxFee
+	} else {
+		c.fee = c.staticCfg.CreateAssetTxFee
+	}
+	return nil
+}
+
+func (c *staticVisitor) CreateSubnetTx(*txs.CreateSubnetTx) error {
+	if c.upgrades.IsApricotPhase3Activated(c.time) {
+		c.fee = c.staticCfg.CreateSubnetTxFee
+	} else {
+		c.fee = c.staticCfg.CreateAssetTxFee
+	}
+	return nil
+}
+
+func (c *staticVisitor) AdvanceTimeTx(*txs.AdvanceTimeTx) error {
+	c.fee = 0 // no fees
+	return nil
+}
+
+func (c *staticVisitor) RewardValidatorTx(*txs.RewardValidatorTx) error {
+	c.fee = 0 // no fees
+	return nil
+}
+
+func (c *staticVisitor) RemoveSubnetValidatorTx(*txs.RemoveSubnetValidatorTx) error {
+	c.fee = c.staticCfg.TxFee
+	return nil
+}
+
+func (c *staticVisitor) TransformSubnetTx(*txs.TransformSubnetTx) error {
+	c.fee = c.staticCfg.TransformSubnetTxFee
+	return nil
+}
+
+func (c *staticVisitor) TransferSubnetOwnershipTx(*txs.TransferSubnetOwnershipTx) error {
+	c.fee = c.staticCfg.TxFee
+	return nil
+}
+
+func (c *staticVisitor) AddPermissionlessValidatorTx(tx *txs.AddPermissionlessValidatorTx) error {
+	if tx.Subnet != constants.PrimaryNetworkID {
+		c.fee = c.staticCfg.AddSubnetValidatorFee
+	} else {
+		c.fee = c.staticCfg.AddPrimaryNetworkValidatorFee
+	}
+	return nil
+}
+
+func (c *staticVisitor) AddPermissionlessDelegatorTx(tx *txs.AddPermissionlessDelegatorTx) error {
+	if tx.Subnet != constants.PrimaryNetworkID {
+		c.fee = c.staticCfg.AddSubnetDelegatorFee
+	} else {
+		c.fee = c.staticCfg.AddPrimaryNetworkDelegatorFee
+	}
+	return nil
+}
+
+func (c *staticVisitor) BaseTx(*txs.BaseTx) error {
+	c.fee = c.staticCfg.TxFee
+	return nil
+}
+
+func (c *staticVisitor) ImportTx(*txs.ImportTx) error {
+	c.fee = c.staticCfg.TxFee
+	return nil
+}
+
+func (c *staticVisitor) ExportTx(*txs.ExportTx) error {
+	c.fee = c.staticCfg.TxFee
+	return nil
+}
+
+func (*staticVisitor) ConvertSubnetTx(*txs.ConvertSubnetTx) error {
+	return ErrUnsupportedTx // only supported after the F upgrade
+}
+
+func (*staticVisitor) IncreaseBalanceTx(*txs.IncreaseBalanceTx) error {
+	return ErrUnsupportedTx // only supported after the F upgrade
+}
+
+func (*staticVisitor) RegisterSubnetValidatorTx(*txs.RegisterSubnetValidatorTx) error {
+	return ErrUnsupportedTx // only supported after the F upgrade
+}
+
+func (*staticVisitor) SetSubnetValidatorWeightTx(*txs.SetSubnetValidatorWeightTx) error {
+	return ErrUnsupportedTx // only supported after the F upgrade
+}
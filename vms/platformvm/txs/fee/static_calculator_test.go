@@ -16,7 +16,7 @@ import (
 	"github.com/skychains/chain/vms/platformvm/upgrade"
 )
 
-func TestTxFees(t *testing.T) {
+func TestStaticCalculator(t *testing.T) {
 	feeTestsDefaultCfg := StaticConfig{
 		TxFee:                         1 * units.Lux,
 		CreateAssetTxFee:              2 * units.Lux,
@@ -187,9 +187,13 @@ func TestTxFees(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
 			uTx := tt.unsignedTx()
-			fc := NewStaticCalculator(feeTestsDefaultCfg, upgrades)
-			require.Equal(t, tt.expected, fc.CalculateFee(uTx, tt.chainTime))
+			fc := NewStaticCalculator(feeTestsDefaultCfg, upgrades, tt.chainTime)
+			fee, err := fc.CalculateFee(uTx)
+			require.NoError(err)
+			require.Equal(tt.expected, fee)
 		})
 	}
 }
@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"errors"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow"
+)
+
+var (
+	_ UnsignedTx = (*IncreaseBalanceTx)(nil)
+
+	ErrZeroBalance = errors.New("balance must be non-zero")
+)
+
+// IncreaseBalanceTx adds to the balance that a pay-as-you-go subnet validator
+// uses to pay its continuous fee. If the validator was deactivated because it
+// ran out of balance, it is reactivated.
+type IncreaseBalanceTx struct {
+	// Metadata, inputs and outputs
+	BaseTx `serialize:"true"`
+	// ID of the validator to top up
+	ValidationID ids.ID `serialize:"true" json:"validationID"`
+	// Balance, in nLUX, to add to the validator
+	Balance uint64 `serialize:"true" json:"balance"`
+}
+
+func (tx *IncreaseBalanceTx) SyntacticVerify(ctx *snow.Context) error {
+	switch {
+	case tx == nil:
+		return ErrNilTx
+	case tx.SyntacticallyVerified:
+		// already passed syntactic verification
+		return nil
+	case tx.Balance == 0:
+		return ErrZeroBalance
+	}
+
+	if err := tx.BaseTx.SyntacticVerify(ctx); err != nil {
+		return err
+	}
+
+	tx.SyntacticallyVerified = true
+	return nil
+}
+
+func (tx *IncreaseBalanceTx) Visit(visitor Visitor) error {
+	return visitor.IncreaseBalanceTx(tx)
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/vms/components/lux"
+)
+
+func TestIncreaseBalanceTxSyntacticVerify(t *testing.T) {
+	var (
+		networkID = uint32(1337)
+		chainID   = ids.GenerateTestID()
+	)
+
+	ctx := &snow.Context{
+		ChainID:   chainID,
+		NetworkID: networkID,
+	}
+
+	// A BaseTx that already passed syntactic verification.
+	verifiedBaseTx := BaseTx{
+		SyntacticallyVerified: true,
+	}
+	// Sanity check.
+	require.NoError(t, verifiedBaseTx.SyntacticVerify(ctx))
+
+	// A BaseTx that passes syntactic verification.
+	validBaseTx := BaseTx{
+		BaseTx: lux.BaseTx{
+			NetworkID:    networkID,
+			BlockchainID: chainID,
+		},
+	}
+	// Sanity check.
+	require.NoError(t, validBaseTx.SyntacticVerify(ctx))
+	// Make sure we're not caching the verification result.
+	require.False(t, validBaseTx.SyntacticallyVerified)
+
+	// A BaseTx that fails syntactic verification.
+	invalidBaseTx := BaseTx{}
+
+	tests := []struct {
+		name        string
+		tx          *IncreaseBalanceTx
+		expectedErr error
+	}{
+		{
+			name:        "nil tx",
+			tx:          nil,
+			expectedErr: ErrNilTx,
+		},
+		{
+			name:        "already verified",
+			tx:          &IncreaseBalanceTx{BaseTx: verifiedBaseTx},
+			expectedErr: nil,
+		},
+		{
+			name:        "zero balance",
+			tx:          &IncreaseBalanceTx{BaseTx: validBaseTx},
+			expectedErr: ErrZeroBalance,
+		},
+		{
+			name:        "invalid BaseTx",
+			tx:          &IncreaseBalanceTx{BaseTx: invalidBaseTx, Balance: 1},
+			expectedErr: lux.ErrWrongNetworkID,
+		},
+		{
+			name:        "passes verification",
+			tx:          &IncreaseBalanceTx{BaseTx: validBaseTx, Balance: 1},
+			expectedErr: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			err := tt.tx.SyntacticVerify(ctx)
+			require.ErrorIs(err, tt.expectedErr)
+			if tt.expectedErr != nil {
+				return
+			}
+			require.True(tt.tx.SyntacticallyVerified)
+		})
+	}
+}
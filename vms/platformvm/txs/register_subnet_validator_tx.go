@@ -0,0 +1,56 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"errors"
+
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/utils/crypto/bls"
+	"github.com/skychains/chain/vms/types"
+)
+
+var (
+	_ UnsignedTx = (*RegisterSubnetValidatorTx)(nil)
+
+	ErrEmptyWarpMessage = errors.New("warp message must be non-empty")
+)
+
+// RegisterSubnetValidatorTx adds a pay-as-you-go validator to a converted
+// subnet. The validator is described by a warp message sent by the manager of
+// the subnet.
+type RegisterSubnetValidatorTx struct {
+	// Metadata, inputs and outputs
+	BaseTx `serialize:"true"`
+	// Balance, in nLUX, used to pay the continuous fee of the validator
+	Balance uint64 `serialize:"true" json:"balance"`
+	// [ProofOfPossession] proves ownership of the BLS key specified in the
+	// warp message
+	ProofOfPossession [bls.SignatureLen]byte `serialize:"true" json:"proofOfPossession"`
+	// Signed warp message containing a RegisterSubnetValidator payload
+	Message types.JSONByteSlice `serialize:"true" json:"message"`
+}
+
+func (tx *RegisterSubnetValidatorTx) SyntacticVerify(ctx *snow.Context) error {
+	switch {
+	case tx == nil:
+		return ErrNilTx
+	case tx.SyntacticallyVerified:
+		// already passed syntactic verification
+		return nil
+	case len(tx.Message) == 0:
+		return ErrEmptyWarpMessage
+	}
+
+	if err := tx.BaseTx.SyntacticVerify(ctx); err != nil {
+		return err
+	}
+
+	tx.SyntacticallyVerified = true
+	return nil
+}
+
+func (tx *RegisterSubnetValidatorTx) Visit(visitor Visitor) error {
+	return visitor.RegisterSubnetValidatorTx(tx)
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package txs
+
+import (
+	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/vms/types"
+)
+
+var _ UnsignedTx = (*SetSubnetValidatorWeightTx)(nil)
+
+// SetSubnetValidatorWeightTx updates the weight of a pay-as-you-go validator
+// of a converted subnet, as instructed by a warp message sent by the manager
+// of the subnet. Setting the weight to 0 removes the validator and returns its
+// remaining balance.
+type SetSubnetValidatorWeightTx struct {
+	// Metadata, inputs and outputs
+	BaseTx `serialize:"true"`
+	// Signed warp message containing a SubnetValidatorWeight payload
+	Message types.JSONByteSlice `serialize:"true" json:"message"`
+}
+
+func (tx *SetSubnetValidatorWeightTx) SyntacticVerify(ctx *snow.Context) error {
+	switch {
+	case tx == nil:
+		return ErrNilTx
+	case tx.SyntacticallyVerified:
+		// already passed syntactic verification
+		return nil
+	case len(tx.Message) == 0:
+		return ErrEmptyWarpMessage
+	}
+
+	if err := tx.BaseTx.SyntacticVerify(ctx); err != nil {
+		return err
+	}
+
+	tx.SyntacticallyVerified = true
+	return nil
+}
+
+func (tx *SetSubnetValidatorWeightTx) Visit(visitor Visitor) error {
+	return visitor.SetSubnetValidatorWeightTx(tx)
+}
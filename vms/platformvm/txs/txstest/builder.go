@@ -36,7 +36,7 @@ func (w *WalletFactory) NewWallet(keys ...*secp256k1.PrivateKey) (builder.Builde
 		kc      = secp256k1fx.NewKeychain(keys...)
 		addrs   = kc.Addresses()
 		backend = newBackend(addrs, w.state, w.ctx.SharedMemory)
-		context = newContext(w.ctx, w.cfg, w.state.GetTimestamp())
+		context = newContext(w.ctx, w.cfg, w.state)
 	)
 
 	return builder.New(addrs, context, backend), signer.New(kc, backend)
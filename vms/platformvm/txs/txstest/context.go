@@ -4,10 +4,10 @@
 package txstest
 
 import (
-	"time"
-
 	"github.com/skychains/chain/snow"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/platformvm/config"
+	"github.com/skychains/chain/vms/platformvm/state"
 	"github.com/skychains/chain/vms/platformvm/txs"
 	"github.com/skychains/chain/vms/platformvm/txs/fee"
 	"github.com/skychains/chain/wallet/chain/p/builder"
@@ -16,12 +16,28 @@ import (
 func newContext(
 	ctx *snow.Context,
 	cfg *config.Config,
-	timestamp time.Time,
+	state state.Chain,
 ) *builder.Context {
+	timestamp := state.GetTimestamp()
+	if cfg.UpgradeConfig.IsFActivated(timestamp) {
+		feeState := state.GetFeeState()
+		return &builder.Context{
+			NetworkID:         ctx.NetworkID,
+			LUXAssetID:        ctx.LUXAssetID,
+			ComplexityWeights: cfg.DynamicFeeConfig.Weights,
+			GasPrice: gas.CalculatePrice(
+				cfg.DynamicFeeConfig.MinPrice,
+				feeState.Excess,
+				cfg.DynamicFeeConfig.ExcessConversionConstant,
+			),
+		}
+	}
+
 	var (
-		feeCalc         = fee.NewStaticCalculator(cfg.StaticFeeConfig, cfg.UpgradeConfig)
-		createSubnetFee = feeCalc.CalculateFee(&txs.CreateSubnetTx{}, timestamp)
-		createChainFee  = feeCalc.CalculateFee(&txs.CreateChainTx{}, timestamp)
+		feeCalc = fee.NewStaticCalculator(cfg.StaticFeeConfig, cfg.UpgradeConfig, timestamp)
+		// The static calculator never errors on these txs.
+		createSubnetFee, _ = feeCalc.CalculateFee(&txs.CreateSubnetTx{})
+		createChainFee, _  = feeCalc.CalculateFee(&txs.CreateChainTx{})
 	)
 
 	return &builder.Context{
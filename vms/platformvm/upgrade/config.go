@@ -23,6 +23,9 @@ type Config struct {
 
 	// Time of the E network upgrade
 	EUpgradeTime time.Time
+
+	// Time of the F network upgrade
+	FUpgradeTime time.Time
 }
 
 func (c *Config) IsApricotPhase3Activated(timestamp time.Time) bool {
@@ -48,3 +51,7 @@ func (c *Config) IsDurangoActivated(timestamp time.Time) bool {
 func (c *Config) IsEActivated(timestamp time.Time) bool {
 	return !timestamp.Before(c.EUpgradeTime)
 }
+
+func (c *Config) IsFActivated(timestamp time.Time) bool {
+	return !timestamp.Before(c.FUpgradeTime)
+}
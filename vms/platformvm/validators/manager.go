@@ -34,6 +34,7 @@ var (
 	_ validators.State = (*manager)(nil)
 
 	errUnfinalizedHeight = errors.New("failed to fetch validator set at unfinalized height")
+	errUnsyncedHeight    = errors.New("failed to fetch validator set at height before the synced validator diffs")
 )
 
 // Manager adds the ability to introduce newly accepted blocks IDs to the State
@@ -85,7 +86,12 @@ type State interface {
 		validators map[ids.NodeID]*validators.GetValidatorOutput,
 		startHeight uint64,
 		endHeight uint64,
+		subnetID ids.ID,
 	) error
+
+	// GetValidatorDiffsStartHeight returns the lowest height whose validator
+	// diffs are stored.
+	GetValidatorDiffsStartHeight() uint64
 }
 
 func NewManager(
@@ -197,6 +203,17 @@ func (m *manager) GetValidatorSet(
 		return validatorSet, nil
 	}
 
+	// Calculating the validator set at [targetHeight] requires the diffs of
+	// every height after [targetHeight].
+	if startHeight := m.state.GetValidatorDiffsStartHeight(); targetHeight+1 < startHeight {
+		return nil, fmt.Errorf("%w with SubnetID = %s: requested P-Chain height (%d) < lowest available P-Chain height (%d)",
+			errUnsyncedHeight,
+			subnetID,
+			targetHeight,
+			startHeight-1,
+		)
+	}
+
 	// get the start time to track metrics
 	startTime := m.clk.Time()
 
@@ -282,6 +299,7 @@ func (m *manager) makePrimaryNetworkValidatorSet(
 		validatorSet,
 		currentHeight,
 		lastDiffHeight,
+		constants.PrimaryNetworkID,
 	)
 	return validatorSet, currentHeight, err
 }
@@ -335,19 +353,38 @@ func (m *manager) makeSubnetValidatorSet(
 	// these keys to represent the public keys at [targetHeight]. If the subnet
 	// validator is not currently a primary network validator, it doesn't have a
 	// key at [currentHeight].
+	//
+	// Subnet only validators register their own public keys, which are
+	// tracked by the public key diffs of the subnet.
+	legacyValidatorSet := make(map[ids.NodeID]*validators.GetValidatorOutput, len(subnetValidatorSet))
 	for nodeID, vdr := range subnetValidatorSet {
+		if vdr.PublicKey != nil {
+			continue
+		}
+
+		legacyValidatorSet[nodeID] = vdr
 		if primaryVdr, ok := primaryValidatorSet[nodeID]; ok {
 			vdr.PublicKey = primaryVdr.PublicKey
-		} else {
-			vdr.PublicKey = nil
 		}
 	}
 
+	err = m.state.ApplyValidatorPublicKeyDiffs(
+		ctx,
+		legacyValidatorSet,
+		currentHeight,
+		lastDiffHeight,
+		constants.PrimaryNetworkID,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	err = m.state.ApplyValidatorPublicKeyDiffs(
 		ctx,
 		subnetValidatorSet,
 		currentHeight,
 		lastDiffHeight,
+		subnetID,
 	)
 	return subnetValidatorSet, currentHeight, err
 }
@@ -0,0 +1,33 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package validators
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/timer/mockable"
+	"github.com/skychains/chain/vms/platformvm/config"
+	"github.com/skychains/chain/vms/platformvm/metrics"
+	"github.com/skychains/chain/vms/platformvm/state"
+)
+
+func TestGetValidatorSetBeforeSyncedDiffs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	s := state.NewMockState(ctrl)
+	s.EXPECT().GetValidatorDiffsStartHeight().Return(uint64(10)).AnyTimes()
+
+	m := NewManager(logging.NoLog{}, config.Config{}, s, metrics.Noop, &mockable.Clock{})
+	for _, subnetID := range []ids.ID{constants.PrimaryNetworkID, ids.GenerateTestID()} {
+		_, err := m.GetValidatorSet(context.Background(), 8, subnetID)
+		require.ErrorIs(t, err, errUnsyncedHeight)
+	}
+}
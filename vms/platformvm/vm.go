@@ -20,6 +20,7 @@ import (
 	"github.com/skychains/chain/codec/linearcodec"
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/network/p2p"
 	"github.com/skychains/chain/snow"
 	"github.com/skychains/chain/snow/consensus/snowman"
 	"github.com/skychains/chain/snow/engine/common"
@@ -86,6 +87,14 @@ type VM struct {
 
 	manager blockexecutor.Manager
 
+	// Used to notify the engine once state sync has finished
+	toEngine chan<- common.Message
+
+	stateSyncEnabled bool
+	stateSyncClient  *p2p.Client
+	// stateSyncErr is the error that caused state sync to fail, if any.
+	stateSyncErr error
+
 	// Cancelled on shutdown
 	onShutdownCtx context.Context
 	// Call [onShutdownCtxCancel] to cancel [onShutdownCtx] during Shutdown()
@@ -126,6 +135,8 @@ func (vm *VM) Initialize(
 
 	vm.ctx = chainCtx
 	vm.db = db
+	vm.toEngine = toEngine
+	vm.stateSyncEnabled = execConfig.StateSyncEnabled
 
 	// Note: this codec is never used to serialize anything
 	vm.codecRegistry = linearcodec.NewDefault()
@@ -200,6 +211,12 @@ func (vm *VM) Initialize(
 		return fmt.Errorf("failed to initialize network: %w", err)
 	}
 
+	stateSyncHandler := network.NewStateSyncHandler(chainCtx.Log, &chainCtx.Lock, vm.state)
+	if err := vm.Network.AddHandler(network.StateSyncHandlerID, stateSyncHandler); err != nil {
+		return fmt.Errorf("failed to register state sync handler: %w", err)
+	}
+	vm.stateSyncClient = vm.Network.NewClient(network.StateSyncHandlerID)
+
 	vm.onShutdownCtx, vm.onShutdownCtxCancel = context.WithCancel(context.Background())
 	// TODO: Wait for this goroutine to exit during Shutdown once the platformvm
 	// has better control of the context lock.
@@ -378,6 +395,11 @@ func (vm *VM) onNormalOperationsStarted() error {
 func (vm *VM) SetState(_ context.Context, state snow.State) error {
 	switch state {
 	case snow.Bootstrapping:
+		// The engine starts bootstrapping once state sync has finished, so a
+		// failed sync is reported here.
+		if vm.stateSyncErr != nil {
+			return vm.stateSyncErr
+		}
 		return vm.onBootstrapStarted()
 	case snow.NormalOp:
 		return vm.onNormalOperationsStarted()
@@ -49,6 +49,7 @@ import (
 	"github.com/skychains/chain/utils/timer/mockable"
 	"github.com/skychains/chain/utils/units"
 	"github.com/skychains/chain/version"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/api"
 	"github.com/skychains/chain/vms/platformvm/block"
@@ -82,6 +83,7 @@ const (
 	cortina
 	durango
 	eUpgrade
+	fUpgrade
 
 	latestFork = durango
 
@@ -101,6 +103,15 @@ var (
 
 	defaultTxFee = uint64(100)
 
+	defaultDynamicFeeConfig = gas.Config{
+		Weights:                  gas.Dimensions{1, 1, 1, 1},
+		MaxCapacity:              1_000_000,
+		MaxPerSecond:             100_000,
+		TargetPerSecond:          50_000,
+		MinPrice:                 1,
+		ExcessConversionConstant: 2_164_043,
+	}
+
 	// chain timestamp at genesis
 	defaultGenesisTime = time.Date(1997, 1, 1, 0, 0, 0, 0, time.UTC)
 
@@ -207,6 +218,10 @@ func defaultGenesis(t *testing.T, luxAssetID ids.ID) (*api.BuildGenesisArgs, []b
 }
 
 func defaultVM(t *testing.T, f fork) (*VM, *txstest.WalletFactory, database.Database, *mutableSharedMemory) {
+	return defaultVMWithConfig(t, f, []byte(`{"network":{"max-validator-set-staleness":0}}`), &common.SenderTest{})
+}
+
+func defaultVMWithConfig(t *testing.T, f fork, configBytes []byte, appSender *common.SenderTest) (*VM, *txstest.WalletFactory, database.Database, *mutableSharedMemory) {
 	require := require.New(t)
 	var (
 		apricotPhase3Time = mockable.MaxTime
@@ -215,12 +230,16 @@ func defaultVM(t *testing.T, f fork) (*VM, *txstest.WalletFactory, database.Data
 		cortinaTime       = mockable.MaxTime
 		durangoTime       = mockable.MaxTime
 		eUpgradeTime      = mockable.MaxTime
+		fUpgradeTime      = mockable.MaxTime
 	)
 
 	// always reset latestForkTime (a package level variable)
 	// to ensure test independence
 	latestForkTime = defaultGenesisTime.Add(time.Second)
 	switch f {
+	case fUpgrade:
+		fUpgradeTime = latestForkTime
+		fallthrough
 	case eUpgrade:
 		eUpgradeTime = latestForkTime
 		fallthrough
@@ -253,6 +272,7 @@ func defaultVM(t *testing.T, f fork) (*VM, *txstest.WalletFactory, database.Data
 			TransformSubnetTxFee:  100 * defaultTxFee,
 			CreateBlockchainTxFee: 100 * defaultTxFee,
 		},
+		DynamicFeeConfig:  defaultDynamicFeeConfig,
 		MinValidatorStake: defaultMinValidatorStake,
 		MaxValidatorStake: defaultMaxValidatorStake,
 		MinDelegatorStake: defaultMinDelegatorStake,
@@ -266,6 +286,7 @@ func defaultVM(t *testing.T, f fork) (*VM, *txstest.WalletFactory, database.Data
 			CortinaTime:       cortinaTime,
 			DurangoTime:       durangoTime,
 			EUpgradeTime:      eUpgradeTime,
+			FUpgradeTime:      fUpgradeTime,
 		},
 	}}
 
@@ -286,7 +307,6 @@ func defaultVM(t *testing.T, f fork) (*VM, *txstest.WalletFactory, database.Data
 	ctx.Lock.Lock()
 	defer ctx.Lock.Unlock()
 	_, genesisBytes := defaultGenesis(t, ctx.LUXAssetID)
-	appSender := &common.SenderTest{}
 	appSender.CantSendAppGossip = true
 	appSender.SendAppGossipF = func(context.Context, common.SendConfig, []byte) error {
 		return nil
@@ -295,14 +315,13 @@ func defaultVM(t *testing.T, f fork) (*VM, *txstest.WalletFactory, database.Data
 		return nil
 	}
 
-	dynamicConfigBytes := []byte(`{"network":{"max-validator-set-staleness":0}}`)
 	require.NoError(vm.Initialize(
 		context.Background(),
 		ctx,
 		chainDB,
 		genesisBytes,
 		nil,
-		dynamicConfigBytes,
+		configBytes,
 		msgChan,
 		nil,
 		appSender,
@@ -0,0 +1,30 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"errors"
+
+	"github.com/skychains/chain/codec"
+	"github.com/skychains/chain/codec/linearcodec"
+	"github.com/skychains/chain/vms/platformvm/warp/payload"
+)
+
+const CodecVersion = 0
+
+var Codec codec.Manager
+
+func init() {
+	Codec = codec.NewManager(payload.MaxMessageSize)
+	lc := linearcodec.NewDefault()
+
+	err := errors.Join(
+		lc.RegisterType(&RegisterSubnetValidator{}),
+		lc.RegisterType(&SubnetValidatorWeight{}),
+		Codec.RegisterCodec(CodecVersion, lc),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
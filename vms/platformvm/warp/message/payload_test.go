@@ -0,0 +1,78 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/codec"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/hashing"
+)
+
+var junkBytes = []byte{0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88}
+
+func TestParseJunk(t *testing.T) {
+	require := require.New(t)
+	_, err := Parse(junkBytes)
+	require.ErrorIs(err, codec.ErrUnknownVersion)
+}
+
+func TestParseWrongPayloadType(t *testing.T) {
+	require := require.New(t)
+	registerMsg, err := NewRegisterSubnetValidator(
+		ids.GenerateTestID(),
+		ids.GenerateTestNodeID(),
+		[48]byte{1, 2, 3},
+		1,
+		PChainOwner{
+			Threshold: 1,
+			Addresses: []ids.ShortID{ids.GenerateTestShortID()},
+		},
+		2,
+	)
+	require.NoError(err)
+
+	weightMsg, err := NewSubnetValidatorWeight(ids.GenerateTestID(), 1, 2)
+	require.NoError(err)
+
+	_, err = ParseRegisterSubnetValidator(weightMsg.Bytes())
+	require.ErrorIs(err, errWrongType)
+
+	_, err = ParseSubnetValidatorWeight(registerMsg.Bytes())
+	require.ErrorIs(err, errWrongType)
+}
+
+func TestRegisterSubnetValidator(t *testing.T) {
+	require := require.New(t)
+	msg, err := NewRegisterSubnetValidator(
+		ids.GenerateTestID(),
+		ids.GenerateTestNodeID(),
+		[48]byte{1, 2, 3},
+		1,
+		PChainOwner{
+			Threshold: 1,
+			Addresses: []ids.ShortID{ids.GenerateTestShortID()},
+		},
+		2,
+	)
+	require.NoError(err)
+
+	parsedMsg, err := ParseRegisterSubnetValidator(msg.Bytes())
+	require.NoError(err)
+	require.Equal(msg, parsedMsg)
+	require.Equal(ids.ID(hashing.ComputeHash256Array(msg.Bytes())), parsedMsg.ValidationID())
+}
+
+func TestSubnetValidatorWeight(t *testing.T) {
+	require := require.New(t)
+	msg, err := NewSubnetValidatorWeight(ids.GenerateTestID(), 1, 2)
+	require.NoError(err)
+
+	parsedMsg, err := Parse(msg.Bytes())
+	require.NoError(err)
+	require.Equal(msg, parsedMsg)
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"fmt"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/crypto/bls"
+	"github.com/skychains/chain/utils/hashing"
+)
+
+var _ Payload = (*RegisterSubnetValidator)(nil)
+
+// PChainOwner is the owner of the leftover balance of a subnet validator once
+// it is removed.
+type PChainOwner struct {
+	// The threshold number of [Addresses] that must provide a signature in
+	// order for the balance to be spent.
+	Threshold uint32 `serialize:"true" json:"threshold"`
+	// The addresses that are allowed to sign to spend the balance.
+	Addresses []ids.ShortID `serialize:"true" json:"addresses"`
+}
+
+// RegisterSubnetValidator is sent by the manager of a converted subnet to add
+// a validator to the subnet.
+type RegisterSubnetValidator struct {
+	SubnetID     ids.ID                 `serialize:"true" json:"subnetID"`
+	NodeID       ids.NodeID             `serialize:"true" json:"nodeID"`
+	BLSPublicKey [bls.PublicKeyLen]byte `serialize:"true" json:"blsPublicKey"`
+	// Unix timestamp, in seconds, after which this message can no longer be
+	// used to register the validator.
+	Expiry                uint64      `serialize:"true" json:"expiry"`
+	RemainingBalanceOwner PChainOwner `serialize:"true" json:"remainingBalanceOwner"`
+	Weight                uint64      `serialize:"true" json:"weight"`
+
+	bytes []byte
+}
+
+// NewRegisterSubnetValidator creates a new *RegisterSubnetValidator and
+// initializes it.
+func NewRegisterSubnetValidator(
+	subnetID ids.ID,
+	nodeID ids.NodeID,
+	blsPublicKey [bls.PublicKeyLen]byte,
+	expiry uint64,
+	remainingBalanceOwner PChainOwner,
+	weight uint64,
+) (*RegisterSubnetValidator, error) {
+	msg := &RegisterSubnetValidator{
+		SubnetID:              subnetID,
+		NodeID:                nodeID,
+		BLSPublicKey:          blsPublicKey,
+		Expiry:                expiry,
+		RemainingBalanceOwner: remainingBalanceOwner,
+		Weight:                weight,
+	}
+	return msg, initialize(msg)
+}
+
+// ParseRegisterSubnetValidator converts a slice of bytes into an initialized
+// RegisterSubnetValidator.
+func ParseRegisterSubnetValidator(b []byte) (*RegisterSubnetValidator, error) {
+	payloadIntf, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := payloadIntf.(*RegisterSubnetValidator)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", errWrongType, payloadIntf)
+	}
+	return payload, nil
+}
+
+// ValidationID returns the ID of the validation period that this message
+// registers. It assumes that the payload is initialized.
+func (r *RegisterSubnetValidator) ValidationID() ids.ID {
+	return hashing.ComputeHash256Array(r.bytes)
+}
+
+// Bytes returns the binary representation of this payload. It assumes that the
+// payload is initialized from either NewRegisterSubnetValidator or Parse.
+func (r *RegisterSubnetValidator) Bytes() []byte {
+	return r.bytes
+}
+
+func (r *RegisterSubnetValidator) initialize(bytes []byte) {
+	r.bytes = bytes
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package message
+
+import (
+	"fmt"
+
+	"github.com/skychains/chain/ids"
+)
+
+var _ Payload = (*SubnetValidatorWeight)(nil)
+
+// SubnetValidatorWeight is sent by the manager of a converted subnet to update
+// the weight of one of its validators. A weight of 0 removes the validator.
+type SubnetValidatorWeight struct {
+	ValidationID ids.ID `serialize:"true" json:"validationID"`
+	// Messages with a nonce lower than the most recently applied nonce of the
+	// validator are rejected, preventing replays.
+	Nonce  uint64 `serialize:"true" json:"nonce"`
+	Weight uint64 `serialize:"true" json:"weight"`
+
+	bytes []byte
+}
+
+// NewSubnetValidatorWeight creates a new *SubnetValidatorWeight and
+// initializes it.
+func NewSubnetValidatorWeight(
+	validationID ids.ID,
+	nonce uint64,
+	weight uint64,
+) (*SubnetValidatorWeight, error) {
+	msg := &SubnetValidatorWeight{
+		ValidationID: validationID,
+		Nonce:        nonce,
+		Weight:       weight,
+	}
+	return msg, initialize(msg)
+}
+
+// ParseSubnetValidatorWeight converts a slice of bytes into an initialized
+// SubnetValidatorWeight.
+func ParseSubnetValidatorWeight(b []byte) (*SubnetValidatorWeight, error) {
+	payloadIntf, err := Parse(b)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := payloadIntf.(*SubnetValidatorWeight)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T", errWrongType, payloadIntf)
+	}
+	return payload, nil
+}
+
+// Bytes returns the binary representation of this payload. It assumes that the
+// payload is initialized from either NewSubnetValidatorWeight or Parse.
+func (s *SubnetValidatorWeight) Bytes() []byte {
+	return s.bytes
+}
+
+func (s *SubnetValidatorWeight) initialize(bytes []byte) {
+	s.bytes = bytes
+}
@@ -52,7 +52,9 @@ var (
 	_ block.BatchedChainVM  = (*VM)(nil)
 	_ block.StateSyncableVM = (*VM)(nil)
 
-	dbPrefix = []byte("proposervm")
+	// DBPrefix is the prefix, of the database provided to the VM, that the
+	// proposervm stores its state under.
+	DBPrefix = []byte("proposervm")
 )
 
 func cachedBlockSize(_ ids.ID, blk snowman.Block) int {
@@ -138,7 +140,7 @@ func (vm *VM) Initialize(
 	appSender common.AppSender,
 ) error {
 	vm.ctx = chainCtx
-	vm.db = versiondb.New(prefixdb.New(dbPrefix, db))
+	vm.db = versiondb.New(prefixdb.New(DBPrefix, db))
 	baseState, err := state.NewMetered(vm.db, "state", vm.Config.Registerer)
 	if err != nil {
 		return err
@@ -7,18 +7,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"time"
 
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils"
 	"github.com/skychains/chain/utils/constants"
+	"github.com/skychains/chain/utils/crypto/bls"
 	"github.com/skychains/chain/utils/math"
 	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/vms/components/gas"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/fx"
 	"github.com/skychains/chain/vms/platformvm/signer"
 	"github.com/skychains/chain/vms/platformvm/stakeable"
 	"github.com/skychains/chain/vms/platformvm/txs"
+	"github.com/skychains/chain/vms/platformvm/txs/fee"
 	"github.com/skychains/chain/vms/secp256k1fx"
 	"github.com/skychains/chain/wallet/subnet/primary/common"
 )
@@ -147,6 +151,57 @@ type Builder interface {
 		options ...common.Option,
 	) (*txs.TransferSubnetOwnershipTx, error)
 
+	// NewConvertSubnetTx converts the named subnet into a subnet whose
+	// validator set is managed by [address] on [chainID].
+	//
+	// - [subnetID] specifies the subnet to be converted
+	// - [chainID] specifies the chain where the subnet manager lives
+	// - [address] specifies the address of the subnet manager
+	// - [validators] specifies the initial pay-as-you-go validators of the
+	//   subnet
+	NewConvertSubnetTx(
+		subnetID ids.ID,
+		chainID ids.ID,
+		address []byte,
+		validators []*txs.ConvertSubnetValidator,
+		options ...common.Option,
+	) (*txs.ConvertSubnetTx, error)
+
+	// NewIncreaseBalanceTx increases the balance of a subnet only validator.
+	//
+	// - [validationID] specifies the validator to be funded
+	// - [balance] specifies the amount of nLUX to add to its balance
+	NewIncreaseBalanceTx(
+		validationID ids.ID,
+		balance uint64,
+		options ...common.Option,
+	) (*txs.IncreaseBalanceTx, error)
+
+	// NewRegisterSubnetValidatorTx adds a pay-as-you-go validator to a
+	// converted subnet.
+	//
+	// - [balance] specifies the amount of nLUX used to pay the continuous fee
+	//   of the validator
+	// - [proofOfPossession] proves ownership of the BLS key in [message]
+	// - [message] is the signed warp message, sent by the subnet manager,
+	//   that describes the validator
+	NewRegisterSubnetValidatorTx(
+		balance uint64,
+		proofOfPossession [bls.SignatureLen]byte,
+		message []byte,
+		options ...common.Option,
+	) (*txs.RegisterSubnetValidatorTx, error)
+
+	// NewSetSubnetValidatorWeightTx updates the weight of a pay-as-you-go
+	// validator of a converted subnet.
+	//
+	// - [message] is the signed warp message, sent by the subnet manager,
+	//   that specifies the new weight of the validator
+	NewSetSubnetValidatorWeightTx(
+		message []byte,
+		options ...common.Option,
+	) (*txs.SetSubnetValidatorWeightTx, error)
+
 	// NewImportTx creates an import transaction that attempts to consume all
 	// the available UTXOs and import the funds to [to].
 	//
@@ -324,20 +379,26 @@ func (b *builder) NewBaseTx(
 	toStake := map[ids.ID]uint64{}
 
 	ops := common.NewOptions(options)
-	inputs, changeOutputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-	outputs = append(outputs, changeOutputs...)
-	lux.SortTransferableOutputs(outputs, txs.Codec) // sort the outputs
-
 	tx := &txs.BaseTx{BaseTx: lux.BaseTx{
 		NetworkID:    b.context.NetworkID,
 		BlockchainID: constants.PlatformChainID,
-		Ins:          inputs,
 		Outs:         outputs,
 		Memo:         ops.Memo(),
 	}}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, changeOutputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+	outputs = append(outputs, changeOutputs...)
+	lux.SortTransferableOutputs(outputs, txs.Codec) // sort the outputs
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -355,7 +416,10 @@ func (b *builder) NewAddValidatorTx(
 		luxAssetID: vdr.Wght,
 	}
 	ops := common.NewOptions(options)
-	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, ops)
+	if b.context.GasPrice != 0 {
+		return nil, fmt.Errorf("%w: %T", fee.ErrUnsupportedTx, (*txs.AddValidatorTx)(nil))
+	}
+	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, gas.Dimensions{}, ops)
 	if err != nil {
 		return nil, err
 	}
@@ -386,11 +450,6 @@ func (b *builder) NewAddSubnetValidatorTx(
 	}
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, outputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	subnetAuth, err := b.authorizeSubnet(vdr.Subnet, ops)
 	if err != nil {
 		return nil, err
@@ -400,13 +459,23 @@ func (b *builder) NewAddSubnetValidatorTx(
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
 			Memo:         ops.Memo(),
 		}},
 		SubnetValidator: *vdr,
 		SubnetAuth:      subnetAuth,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -420,11 +489,6 @@ func (b *builder) NewRemoveSubnetValidatorTx(
 	}
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, outputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	subnetAuth, err := b.authorizeSubnet(subnetID, ops)
 	if err != nil {
 		return nil, err
@@ -434,14 +498,24 @@ func (b *builder) NewRemoveSubnetValidatorTx(
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
 			Memo:         ops.Memo(),
 		}},
 		Subnet:     subnetID,
 		NodeID:     nodeID,
 		SubnetAuth: subnetAuth,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -458,7 +532,10 @@ func (b *builder) NewAddDelegatorTx(
 		luxAssetID: vdr.Wght,
 	}
 	ops := common.NewOptions(options)
-	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, ops)
+	if b.context.GasPrice != 0 {
+		return nil, fmt.Errorf("%w: %T", fee.ErrUnsupportedTx, (*txs.AddDelegatorTx)(nil))
+	}
+	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, gas.Dimensions{}, ops)
 	if err != nil {
 		return nil, err
 	}
@@ -492,11 +569,6 @@ func (b *builder) NewCreateChainTx(
 	}
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, outputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	subnetAuth, err := b.authorizeSubnet(subnetID, ops)
 	if err != nil {
 		return nil, err
@@ -507,8 +579,6 @@ func (b *builder) NewCreateChainTx(
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
 			Memo:         ops.Memo(),
 		}},
 		SubnetID:    subnetID,
@@ -518,6 +588,18 @@ func (b *builder) NewCreateChainTx(
 		GenesisData: genesis,
 		SubnetAuth:  subnetAuth,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -530,22 +612,27 @@ func (b *builder) NewCreateSubnetTx(
 	}
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, outputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	utils.Sort(owner.Addrs)
 	tx := &txs.CreateSubnetTx{
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
 			Memo:         ops.Memo(),
 		}},
 		Owner: owner,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -559,29 +646,206 @@ func (b *builder) NewTransferSubnetOwnershipTx(
 	}
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, outputs, _, err := b.spend(toBurn, toStake, ops)
+	subnetAuth, err := b.authorizeSubnet(subnetID, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	utils.Sort(owner.Addrs)
+	tx := &txs.TransferSubnetOwnershipTx{
+		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
+			NetworkID:    b.context.NetworkID,
+			BlockchainID: constants.PlatformChainID,
+			Memo:         ops.Memo(),
+		}},
+		Subnet:     subnetID,
+		Owner:      owner,
+		SubnetAuth: subnetAuth,
+	}
+	complexity, err := b.txComplexity(tx)
 	if err != nil {
 		return nil, err
 	}
 
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
+	return tx, b.initCtx(tx)
+}
+
+func (b *builder) NewConvertSubnetTx(
+	subnetID ids.ID,
+	chainID ids.ID,
+	address []byte,
+	validators []*txs.ConvertSubnetValidator,
+	options ...common.Option,
+) (*txs.ConvertSubnetTx, error) {
+	if b.context.GasPrice == 0 {
+		return nil, fmt.Errorf("%w: %T", fee.ErrUnsupportedTx, (*txs.ConvertSubnetTx)(nil))
+	}
+
+	var toBurnAmount uint64
+	for _, vdr := range validators {
+		var err error
+		toBurnAmount, err = math.Add64(toBurnAmount, vdr.Balance)
+		if err != nil {
+			return nil, err
+		}
+	}
+	toBurn := map[ids.ID]uint64{
+		b.context.LUXAssetID: toBurnAmount,
+	}
+	toStake := map[ids.ID]uint64{}
+	ops := common.NewOptions(options)
 	subnetAuth, err := b.authorizeSubnet(subnetID, ops)
 	if err != nil {
 		return nil, err
 	}
 
-	utils.Sort(owner.Addrs)
-	tx := &txs.TransferSubnetOwnershipTx{
+	utils.Sort(validators)
+	tx := &txs.ConvertSubnetTx{
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
 			Memo:         ops.Memo(),
 		}},
 		Subnet:     subnetID,
-		Owner:      owner,
+		ChainID:    chainID,
+		Address:    address,
+		Validators: validators,
 		SubnetAuth: subnetAuth,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
+	return tx, b.initCtx(tx)
+}
+
+func (b *builder) NewIncreaseBalanceTx(
+	validationID ids.ID,
+	balance uint64,
+	options ...common.Option,
+) (*txs.IncreaseBalanceTx, error) {
+	if b.context.GasPrice == 0 {
+		return nil, fmt.Errorf("%w: %T", fee.ErrUnsupportedTx, (*txs.IncreaseBalanceTx)(nil))
+	}
+
+	toBurn := map[ids.ID]uint64{
+		b.context.LUXAssetID: balance,
+	}
+	toStake := map[ids.ID]uint64{}
+	ops := common.NewOptions(options)
+
+	tx := &txs.IncreaseBalanceTx{
+		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
+			NetworkID:    b.context.NetworkID,
+			BlockchainID: constants.PlatformChainID,
+			Memo:         ops.Memo(),
+		}},
+		ValidationID: validationID,
+		Balance:      balance,
+	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
+	return tx, b.initCtx(tx)
+}
+
+func (b *builder) NewRegisterSubnetValidatorTx(
+	balance uint64,
+	proofOfPossession [bls.SignatureLen]byte,
+	message []byte,
+	options ...common.Option,
+) (*txs.RegisterSubnetValidatorTx, error) {
+	if b.context.GasPrice == 0 {
+		return nil, fmt.Errorf("%w: %T", fee.ErrUnsupportedTx, (*txs.RegisterSubnetValidatorTx)(nil))
+	}
+
+	toBurn := map[ids.ID]uint64{
+		b.context.LUXAssetID: balance,
+	}
+	toStake := map[ids.ID]uint64{}
+	ops := common.NewOptions(options)
+
+	tx := &txs.RegisterSubnetValidatorTx{
+		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
+			NetworkID:    b.context.NetworkID,
+			BlockchainID: constants.PlatformChainID,
+			Memo:         ops.Memo(),
+		}},
+		Balance:           balance,
+		ProofOfPossession: proofOfPossession,
+		Message:           message,
+	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
+	return tx, b.initCtx(tx)
+}
+
+func (b *builder) NewSetSubnetValidatorWeightTx(
+	message []byte,
+	options ...common.Option,
+) (*txs.SetSubnetValidatorWeightTx, error) {
+	if b.context.GasPrice == 0 {
+		return nil, fmt.Errorf("%w: %T", fee.ErrUnsupportedTx, (*txs.SetSubnetValidatorWeightTx)(nil))
+	}
+
+	toBurn := map[ids.ID]uint64{}
+	toStake := map[ids.ID]uint64{}
+	ops := common.NewOptions(options)
+
+	tx := &txs.SetSubnetValidatorWeightTx{
+		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
+			NetworkID:    b.context.NetworkID,
+			BlockchainID: constants.PlatformChainID,
+			Memo:         ops.Memo(),
+		}},
+		Message: message,
+	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -600,7 +864,6 @@ func (b *builder) NewImportTx(
 		addrs           = ops.Addresses(b.addrs)
 		minIssuanceTime = ops.MinIssuanceTime()
 		luxAssetID     = b.context.LUXAssetID
-		txFee           = b.context.BaseTxFee
 
 		importedInputs  = make([]*lux.TransferableInput, 0, len(utxos))
 		importedAmounts = make(map[ids.ID]uint64)
@@ -645,6 +908,20 @@ func (b *builder) NewImportTx(
 		)
 	}
 
+	tx := &txs.ImportTx{
+		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
+			NetworkID:    b.context.NetworkID,
+			BlockchainID: constants.PlatformChainID,
+			Memo:         ops.Memo(),
+		}},
+		SourceChain:    sourceChainID,
+		ImportedInputs: importedInputs,
+	}
+	txFee, err := b.importTxFee(tx, importedAmounts, to)
+	if err != nil {
+		return nil, err
+	}
+
 	var (
 		inputs       []*lux.TransferableInput
 		outputs      = make([]*lux.TransferableOutput, 0, len(importedAmounts))
@@ -659,7 +936,7 @@ func (b *builder) NewImportTx(
 			}
 			toStake := map[ids.ID]uint64{}
 			var err error
-			inputs, outputs, _, err = b.spend(toBurn, toStake, ops)
+			inputs, outputs, _, err = b.spend(toBurn, toStake, gas.Dimensions{}, ops)
 			if err != nil {
 				return nil, fmt.Errorf("couldn't generate tx inputs/outputs: %w", err)
 			}
@@ -678,17 +955,8 @@ func (b *builder) NewImportTx(
 	}
 
 	lux.SortTransferableOutputs(outputs, txs.Codec) // sort imported outputs
-	tx := &txs.ImportTx{
-		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
-			NetworkID:    b.context.NetworkID,
-			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
-			Memo:         ops.Memo(),
-		}},
-		SourceChain:    sourceChainID,
-		ImportedInputs: importedInputs,
-	}
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -711,23 +979,28 @@ func (b *builder) NewExportTx(
 
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, changeOutputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	lux.SortTransferableOutputs(outputs, txs.Codec) // sort exported outputs
 	tx := &txs.ExportTx{
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         changeOutputs,
 			Memo:         ops.Memo(),
 		}},
 		DestinationChain: chainID,
 		ExportedOutputs:  outputs,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, changeOutputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = changeOutputs
 	return tx, b.initCtx(tx)
 }
 
@@ -754,11 +1027,6 @@ func (b *builder) NewTransformSubnetTx(
 	}
 	toStake := map[ids.ID]uint64{}
 	ops := common.NewOptions(options)
-	inputs, outputs, _, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	subnetAuth, err := b.authorizeSubnet(subnetID, ops)
 	if err != nil {
 		return nil, err
@@ -768,8 +1036,6 @@ func (b *builder) NewTransformSubnetTx(
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         outputs,
 			Memo:         ops.Memo(),
 		}},
 		Subnet:                   subnetID,
@@ -788,6 +1054,18 @@ func (b *builder) NewTransformSubnetTx(
 		UptimeRequirement:        uptimeRequirement,
 		SubnetAuth:               subnetAuth,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, outputs, _, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = outputs
 	return tx, b.initCtx(tx)
 }
 
@@ -811,29 +1089,34 @@ func (b *builder) NewAddPermissionlessValidatorTx(
 		assetID: vdr.Wght,
 	}
 	ops := common.NewOptions(options)
-	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	utils.Sort(validationRewardsOwner.Addrs)
 	utils.Sort(delegationRewardsOwner.Addrs)
 	tx := &txs.AddPermissionlessValidatorTx{
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         baseOutputs,
 			Memo:         ops.Memo(),
 		}},
 		Validator:             vdr.Validator,
 		Subnet:                vdr.Subnet,
 		Signer:                signer,
-		StakeOuts:             stakeOutputs,
 		ValidatorRewardsOwner: validationRewardsOwner,
 		DelegatorRewardsOwner: delegationRewardsOwner,
 		DelegationShares:      shares,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = baseOutputs
+	tx.StakeOuts = stakeOutputs
 	return tx, b.initCtx(tx)
 }
 
@@ -854,25 +1137,30 @@ func (b *builder) NewAddPermissionlessDelegatorTx(
 		assetID: vdr.Wght,
 	}
 	ops := common.NewOptions(options)
-	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, ops)
-	if err != nil {
-		return nil, err
-	}
-
 	utils.Sort(rewardsOwner.Addrs)
 	tx := &txs.AddPermissionlessDelegatorTx{
 		BaseTx: txs.BaseTx{BaseTx: lux.BaseTx{
 			NetworkID:    b.context.NetworkID,
 			BlockchainID: constants.PlatformChainID,
-			Ins:          inputs,
-			Outs:         baseOutputs,
 			Memo:         ops.Memo(),
 		}},
 		Validator:              vdr.Validator,
 		Subnet:                 vdr.Subnet,
-		StakeOuts:              stakeOutputs,
 		DelegationRewardsOwner: rewardsOwner,
 	}
+	complexity, err := b.txComplexity(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs, baseOutputs, stakeOutputs, err := b.spend(toBurn, toStake, complexity, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	tx.Ins = inputs
+	tx.Outs = baseOutputs
+	tx.StakeOuts = stakeOutputs
 	return tx, b.initCtx(tx)
 }
 
@@ -925,6 +1213,8 @@ func (b *builder) getBalance(
 }
 
 // spend takes in the requested burn amounts and the requested stake amounts.
+// If dynamic fees are enabled, the fee of the transaction is burned in
+// addition to [amountsToBurn].
 //
 //   - [amountsToBurn] maps assetID to the amount of the asset to spend without
 //     producing an output. This is typically used for fees. However, it can
@@ -935,7 +1225,69 @@ func (b *builder) getBalance(
 //     place into the staked outputs. First locked UTXOs are attempted to be
 //     used for these funds, and then unlocked UTXOs will be attempted to be
 //     used. There is no preferential ordering on the unlock times.
+//   - [complexity] is the complexity of the transaction, excluding the
+//     returned inputs and outputs. It is ignored if dynamic fees are disabled.
 func (b *builder) spend(
+	amountsToBurn map[ids.ID]uint64,
+	amountsToStake map[ids.ID]uint64,
+	complexity gas.Dimensions,
+	options *common.Options,
+) (
+	inputs []*lux.TransferableInput,
+	changeOutputs []*lux.TransferableOutput,
+	stakeOutputs []*lux.TransferableOutput,
+	err error,
+) {
+	if b.context.GasPrice == 0 {
+		return b.spendUTXOs(amountsToBurn, amountsToStake, options)
+	}
+
+	// The fee depends on the inputs and outputs that are selected, which in
+	// turn depend on the fee. Since selecting more UTXOs can only increase the
+	// fee, iterate until the fee being paid covers the resulting complexity.
+	luxAssetID := b.context.LUXAssetID
+	txFee, err := b.calculateFee(complexity)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	for {
+		toBurn := maps.Clone(amountsToBurn)
+		toBurn[luxAssetID], err = math.Add64(toBurn[luxAssetID], txFee)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		inputs, changeOutputs, stakeOutputs, err = b.spendUTXOs(toBurn, maps.Clone(amountsToStake), options)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		inputsComplexity, err := fee.InputComplexity(inputs...)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		outputsComplexity, err := fee.OutputComplexity(append(changeOutputs, stakeOutputs...)...)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		totalComplexity, err := complexity.Add(&inputsComplexity, &outputsComplexity)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		requiredFee, err := b.calculateFee(totalComplexity)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if requiredFee <= txFee {
+			return inputs, changeOutputs, stakeOutputs, nil
+		}
+		txFee = requiredFee
+	}
+}
+
+// spendUTXOs consumes the UTXOs needed to burn [amountsToBurn] and to stake
+// [amountsToStake]. The provided maps are modified.
+func (b *builder) spendUTXOs(
 	amountsToBurn map[ids.ID]uint64,
 	amountsToStake map[ids.ID]uint64,
 	options *common.Options,
@@ -1185,6 +1537,57 @@ func (b *builder) authorizeSubnet(subnetID ids.ID, options *common.Options) (*se
 	}, nil
 }
 
+// txComplexity returns the complexity of [tx] if dynamic fees are enabled.
+func (b *builder) txComplexity(tx txs.UnsignedTx) (gas.Dimensions, error) {
+	if b.context.GasPrice == 0 {
+		return gas.Dimensions{}, nil
+	}
+	return fee.TxComplexity(tx)
+}
+
+// calculateFee returns the dynamic fee of a transaction with [complexity].
+func (b *builder) calculateFee(complexity gas.Dimensions) (uint64, error) {
+	txGas, err := complexity.ToGas(b.context.ComplexityWeights)
+	if err != nil {
+		return 0, err
+	}
+	return txGas.Cost(b.context.GasPrice)
+}
+
+// importTxFee returns the fee of [tx], assuming that an output is created for
+// each asset in [importedAmounts] and that no additional UTXOs are consumed.
+func (b *builder) importTxFee(
+	tx *txs.ImportTx,
+	importedAmounts map[ids.ID]uint64,
+	to *secp256k1fx.OutputOwners,
+) (uint64, error) {
+	if b.context.GasPrice == 0 {
+		return b.context.BaseTxFee, nil
+	}
+
+	complexity, err := fee.TxComplexity(tx)
+	if err != nil {
+		return 0, err
+	}
+	for assetID, amount := range importedAmounts {
+		outputComplexity, err := fee.OutputComplexity(&lux.TransferableOutput{
+			Asset: lux.Asset{ID: assetID},
+			Out: &secp256k1fx.TransferOutput{
+				Amt:          amount,
+				OutputOwners: *to,
+			},
+		})
+		if err != nil {
+			return 0, err
+		}
+		complexity, err = complexity.Add(&outputComplexity)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return b.calculateFee(complexity)
+}
+
 func (b *builder) initCtx(tx txs.UnsignedTx) error {
 	ctx, err := NewSnowContext(b.context.NetworkID, b.context.LUXAssetID)
 	if err != nil {
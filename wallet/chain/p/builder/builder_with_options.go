@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/crypto/bls"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm/signer"
 	"github.com/skychains/chain/vms/platformvm/txs"
@@ -155,6 +156,58 @@ func (b *builderWithOptions) NewTransferSubnetOwnershipTx(
 	)
 }
 
+func (b *builderWithOptions) NewConvertSubnetTx(
+	subnetID ids.ID,
+	chainID ids.ID,
+	address []byte,
+	validators []*txs.ConvertSubnetValidator,
+	options ...common.Option,
+) (*txs.ConvertSubnetTx, error) {
+	return b.builder.NewConvertSubnetTx(
+		subnetID,
+		chainID,
+		address,
+		validators,
+		common.UnionOptions(b.options, options)...,
+	)
+}
+
+func (b *builderWithOptions) NewIncreaseBalanceTx(
+	validationID ids.ID,
+	balance uint64,
+	options ...common.Option,
+) (*txs.IncreaseBalanceTx, error) {
+	return b.builder.NewIncreaseBalanceTx(
+		validationID,
+		balance,
+		common.UnionOptions(b.options, options)...,
+	)
+}
+
+func (b *builderWithOptions) NewRegisterSubnetValidatorTx(
+	balance uint64,
+	proofOfPossession [bls.SignatureLen]byte,
+	message []byte,
+	options ...common.Option,
+) (*txs.RegisterSubnetValidatorTx, error) {
+	return b.builder.NewRegisterSubnetValidatorTx(
+		balance,
+		proofOfPossession,
+		message,
+		common.UnionOptions(b.options, options)...,
+	)
+}
+
+func (b *builderWithOptions) NewSetSubnetValidatorWeightTx(
+	message []byte,
+	options ...common.Option,
+) (*txs.SetSubnetValidatorWeightTx, error) {
+	return b.builder.NewSetSubnetValidatorWeightTx(
+		message,
+		common.UnionOptions(b.options, options)...,
+	)
+}
+
 func (b *builderWithOptions) NewImportTx(
 	sourceChainID ids.ID,
 	to *secp256k1fx.OutputOwners,
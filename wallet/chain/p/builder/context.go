@@ -5,17 +5,30 @@ package builder
 
 import (
 	"context"
+	"time"
 
 	"github.com/skychains/chain/api/info"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/snow"
 	"github.com/skychains/chain/utils/constants"
 	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/rpc"
 	"github.com/skychains/chain/vms/avm"
+	"github.com/skychains/chain/vms/components/gas"
 )
 
 const Alias = "P"
 
+var _ FeeClient = (*feeClient)(nil)
+
+// FeeClient fetches the dynamic fee parameters of the P-chain.
+//
+// It is implemented by the P-chain API client.
+type FeeClient interface {
+	GetFeeConfig(ctx context.Context, options ...rpc.Option) (*gas.Config, error)
+	GetFeeState(ctx context.Context, options ...rpc.Option) (gas.State, gas.Price, time.Time, error)
+}
+
 type Context struct {
 	NetworkID                     uint32
 	LUXAssetID                   ids.ID
@@ -27,18 +40,29 @@ type Context struct {
 	AddPrimaryNetworkDelegatorFee uint64
 	AddSubnetValidatorFee         uint64
 	AddSubnetDelegatorFee         uint64
+
+	// ComplexityWeights and GasPrice are used to calculate the fee of a
+	// transaction once dynamic fees are activated. If GasPrice is 0, the
+	// static fees above are used. Otherwise, the static fees are expected to
+	// be 0.
+	ComplexityWeights gas.Dimensions
+	GasPrice          gas.Price
 }
 
 func NewContextFromURI(ctx context.Context, uri string) (*Context, error) {
 	infoClient := info.NewClient(uri)
 	xChainClient := avm.NewClient(uri, "X")
-	return NewContextFromClients(ctx, infoClient, xChainClient)
+	pChainClient := &feeClient{requester: rpc.NewEndpointRequester(
+		uri + "/ext/P",
+	)}
+	return NewContextFromClients(ctx, infoClient, xChainClient, pChainClient)
 }
 
 func NewContextFromClients(
 	ctx context.Context,
 	infoClient info.Client,
 	xChainClient avm.Client,
+	pChainClient FeeClient,
 ) (*Context, error) {
 	networkID, err := infoClient.GetNetworkID(ctx)
 	if err != nil {
@@ -50,6 +74,24 @@ func NewContextFromClients(
 		return nil, err
 	}
 
+	_, gasPrice, _, err := pChainClient.GetFeeState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if gasPrice != 0 {
+		feeConfig, err := pChainClient.GetFeeConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Context{
+			NetworkID:         networkID,
+			LUXAssetID:        asset.AssetID,
+			ComplexityWeights: feeConfig.Weights,
+			GasPrice:          gasPrice,
+		}, nil
+	}
+
 	txFees, err := infoClient.GetTxFee(ctx)
 	if err != nil {
 		return nil, err
@@ -80,3 +122,25 @@ func NewSnowContext(networkID uint32, luxAssetID ids.ID) (*snow.Context, error)
 		BCLookup:    lookup,
 	}, lookup.Alias(constants.PlatformChainID, Alias)
 }
+
+// feeClient is a minimal P-chain API client that only fetches the dynamic fee
+// parameters.
+type feeClient struct {
+	requester rpc.EndpointRequester
+}
+
+func (c *feeClient) GetFeeConfig(ctx context.Context, options ...rpc.Option) (*gas.Config, error) {
+	res := &gas.Config{}
+	err := c.requester.SendRequest(ctx, "platform.getFeeConfig", struct{}{}, res, options...)
+	return res, err
+}
+
+func (c *feeClient) GetFeeState(ctx context.Context, options ...rpc.Option) (gas.State, gas.Price, time.Time, error) {
+	res := &struct {
+		gas.State
+		Price gas.Price `json:"price"`
+		Time  time.Time `json:"timestamp"`
+	}{}
+	err := c.requester.SendRequest(ctx, "platform.getFeeState", struct{}{}, res, options...)
+	return res.State, res.Price, res.Time, err
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/crypto/bls"
 	"github.com/skychains/chain/vms/components/lux"
 	"github.com/skychains/chain/vms/platformvm"
 	"github.com/skychains/chain/vms/platformvm/txs"
@@ -131,6 +132,60 @@ type Wallet interface {
 		options ...common.Option,
 	) (*txs.Tx, error)
 
+	// IssueConvertSubnetTx creates, signs, and issues a transaction that
+	// converts the named subnet into a subnet whose validator set is managed
+	// by [address] on [chainID].
+	//
+	// - [subnetID] specifies the subnet to be converted
+	// - [chainID] specifies the chain where the subnet manager lives
+	// - [address] specifies the address of the subnet manager
+	// - [validators] specifies the initial pay-as-you-go validators of the
+	//   subnet
+	IssueConvertSubnetTx(
+		subnetID ids.ID,
+		chainID ids.ID,
+		address []byte,
+		validators []*txs.ConvertSubnetValidator,
+		options ...common.Option,
+	) (*txs.Tx, error)
+
+	// IssueIncreaseBalanceTx creates, signs, and issues a transaction that
+	// increases the balance of a subnet only validator.
+	//
+	// - [validationID] specifies the validator to be funded
+	// - [balance] specifies the amount of nLUX to add to its balance
+	IssueIncreaseBalanceTx(
+		validationID ids.ID,
+		balance uint64,
+		options ...common.Option,
+	) (*txs.Tx, error)
+
+	// IssueRegisterSubnetValidatorTx creates, signs, and issues a transaction
+	// that adds a pay-as-you-go validator to a converted subnet.
+	//
+	// - [balance] specifies the amount of nLUX used to pay the continuous fee
+	//   of the validator
+	// - [proofOfPossession] proves ownership of the BLS key in [message]
+	// - [message] is the signed warp message, sent by the subnet manager,
+	//   that describes the validator
+	IssueRegisterSubnetValidatorTx(
+		balance uint64,
+		proofOfPossession [bls.SignatureLen]byte,
+		message []byte,
+		options ...common.Option,
+	) (*txs.Tx, error)
+
+	// IssueSetSubnetValidatorWeightTx creates, signs, and issues a transaction
+	// that updates the weight of a pay-as-you-go validator of a converted
+	// subnet.
+	//
+	// - [message] is the signed warp message, sent by the subnet manager,
+	//   that specifies the new weight of the validator
+	IssueSetSubnetValidatorWeightTx(
+		message []byte,
+		options ...common.Option,
+	) (*txs.Tx, error)
+
 	// IssueImportTx creates, signs, and issues an import transaction that
 	// attempts to consume all the available UTXOs and import the funds to [to].
 	//
@@ -380,6 +435,56 @@ func (w *wallet) IssueTransferSubnetOwnershipTx(
 	return w.IssueUnsignedTx(utx, options...)
 }
 
+func (w *wallet) IssueConvertSubnetTx(
+	subnetID ids.ID,
+	chainID ids.ID,
+	address []byte,
+	validators []*txs.ConvertSubnetValidator,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	utx, err := w.builder.NewConvertSubnetTx(subnetID, chainID, address, validators, options...)
+	if err != nil {
+		return nil, err
+	}
+	return w.IssueUnsignedTx(utx, options...)
+}
+
+func (w *wallet) IssueIncreaseBalanceTx(
+	validationID ids.ID,
+	balance uint64,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	utx, err := w.builder.NewIncreaseBalanceTx(validationID, balance, options...)
+	if err != nil {
+		return nil, err
+	}
+	return w.IssueUnsignedTx(utx, options...)
+}
+
+func (w *wallet) IssueRegisterSubnetValidatorTx(
+	balance uint64,
+	proofOfPossession [bls.SignatureLen]byte,
+	message []byte,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	utx, err := w.builder.NewRegisterSubnetValidatorTx(balance, proofOfPossession, message, options...)
+	if err != nil {
+		return nil, err
+	}
+	return w.IssueUnsignedTx(utx, options...)
+}
+
+func (w *wallet) IssueSetSubnetValidatorWeightTx(
+	message []byte,
+	options ...common.Option,
+) (*txs.Tx, error) {
+	utx, err := w.builder.NewSetSubnetValidatorWeightTx(message, options...)
+	if err != nil {
+		return nil, err
+	}
+	return w.IssueUnsignedTx(utx, options...)
+}
+
 func (w *wallet) IssueImportTx(
 	sourceChainID ids.ID,
 	to *secp256k1fx.OutputOwners,
@@ -79,7 +79,7 @@ func FetchState(
 	xClient := avm.NewClient(uri, "X")
 	cClient := evm.NewCChainClient(uri)
 
-	pCTX, err := pbuilder.NewContextFromClients(ctx, infoClient, xClient)
+	pCTX, err := pbuilder.NewContextFromClients(ctx, infoClient, xClient, pClient)
 	if err != nil {
 		return nil, err
 	}
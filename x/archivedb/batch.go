@@ -3,7 +3,14 @@
 
 package archivedb
 
-import "github.com/skychains/chain/database"
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils/set"
+)
 
 var _ database.Batch = (*batch)(nil)
 
@@ -20,8 +27,18 @@ type batch struct {
 }
 
 func (c *batch) Write() error {
-	batch := c.db.db.NewBatch()
+	var (
+		batch   = c.db.db.NewBatch()
+		written set.Set[string]
+	)
 	for _, op := range c.Ops {
+		if op.DeleteRange {
+			if err := c.deleteRange(batch, &written, op.Key, op.Limit); err != nil {
+				return err
+			}
+			continue
+		}
+
 		key, _ := newDBKeyFromUser(op.Key, c.height)
 		var value []byte
 		if !op.Delete {
@@ -30,6 +47,7 @@ func (c *batch) Write() error {
 		if err := batch.Put(key, value); err != nil {
 			return err
 		}
+		written.Add(string(op.Key))
 	}
 
 	if err := database.PutUInt64(batch, heightKey, c.height); err != nil {
@@ -39,6 +57,108 @@ func (c *batch) Write() error {
 	return batch.Write()
 }
 
+// deleteRange registers the deletion, at this batch's height, of every key in
+// [start, limit) that either exists at this batch's height or was [written]
+// earlier in this batch.
+//
+// Because user keys are prefixed by their length on disk, keys in the range
+// aren't contiguous, so the range is scanned once per stored key length.
+func (c *batch) deleteRange(
+	batch database.Batch,
+	written *set.Set[string],
+	start []byte,
+	limit []byte,
+) error {
+	storedKeys, err := c.keysInRange(start, limit)
+	if err != nil {
+		return err
+	}
+
+	var keys set.Set[string]
+	for key := range *written {
+		if database.InRange([]byte(key), start, limit) {
+			keys.Add(key)
+		}
+	}
+
+	reader := c.db.Open(c.height)
+	for key := range storedKeys {
+		if keys.Contains(key) {
+			continue
+		}
+
+		has, err := reader.Has([]byte(key))
+		if err != nil {
+			return err
+		}
+		if has {
+			keys.Add(key)
+		}
+	}
+
+	for key := range keys {
+		dbKey, _ := newDBKeyFromUser([]byte(key), c.height)
+		if err := batch.Put(dbKey, nil); err != nil {
+			return err
+		}
+		written.Add(key)
+	}
+	return nil
+}
+
+// keysInRange returns every user key in [start, limit) that has been modified
+// at any height.
+//
+// Keys of the same length are stored contiguously and in order, so each key
+// length is scanned from [start] until [limit].
+func (c *batch) keysInRange(start, limit []byte) (set.Set[string], error) {
+	lengths, err := keyLengths(c.db.db)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys set.Set[string]
+	for _, length := range lengths {
+		if err := c.keysOfLengthInRange(&keys, length, start, limit); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+// keysOfLengthInRange adds to [keys] every user key of [length] in
+// [start, limit) that has been modified at any height.
+func (c *batch) keysOfLengthInRange(
+	keys *set.Set[string],
+	length uint64,
+	start []byte,
+	limit []byte,
+) error {
+	lengthPrefix := binary.AppendUvarint(nil, length)
+	dbStart := append(slices.Clone(lengthPrefix), start...)
+	it := c.db.db.NewIteratorWithStartAndPrefix(dbStart, lengthPrefix)
+	defer it.Release()
+
+	for it.Next() {
+		dbKey := it.Key()
+		if isMetadataKey(dbKey) {
+			continue
+		}
+
+		key, _, err := parseDBKeyFromUser(dbKey)
+		if err != nil {
+			return err
+		}
+		if len(limit) != 0 && bytes.Compare(key, limit) >= 0 {
+			break
+		}
+		if bytes.Compare(key, start) >= 0 {
+			keys.Add(string(key))
+		}
+	}
+	return it.Error()
+}
+
 func (c *batch) Inner() database.Batch {
 	return c
 }
@@ -4,17 +4,28 @@
 package archivedb
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
+	"slices"
+	"sync"
 
 	"github.com/skychains/chain/api/health"
 	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils/units"
 )
 
+// pruneBatchSize is the size, in bytes, at which Prune writes its pending
+// batch.
+const pruneBatchSize = units.MiB
+
 var (
 	ErrNotImplemented = errors.New("feature not implemented")
 	ErrInvalidValue   = errors.New("invalid data value")
+	ErrPruned         = errors.New("height has been pruned")
+	ErrFutureHeight   = errors.New("height hasn't been written")
 
 	_ database.Compacter = (*Database)(nil)
 	_ health.Checker     = (*Database)(nil)
@@ -57,8 +68,19 @@ var (
 // foo was deleted at height 1000. When calling `reader.GetHeight(foo)` at
 // height 99 it will return a tuple `("foo's value is bar", 10)` returning the
 // value of `foo` at height 99 (which was set at height 10).
+//
+// Old versions can be removed with Prune. Once the database has been pruned to
+// a height, reading at any lower height returns ErrPruned.
 type Database struct {
 	db database.Database
+
+	// pruneLock ensures that only one call to Prune runs at a time.
+	pruneLock sync.Mutex
+
+	// prunedHeightLock protects the cached pruned height.
+	prunedHeightLock   sync.RWMutex
+	prunedHeightLoaded bool
+	prunedHeight       uint64
 }
 
 func New(db database.Database) *Database {
@@ -72,7 +94,168 @@ func (db *Database) Height() (uint64, error) {
 	return database.GetUInt64(db.db, heightKey)
 }
 
+// PrunedHeight returns the lowest height that can still be read. If the
+// database has never been pruned, 0 is returned.
+func (db *Database) PrunedHeight() (uint64, error) {
+	db.prunedHeightLock.RLock()
+	if db.prunedHeightLoaded {
+		defer db.prunedHeightLock.RUnlock()
+		return db.prunedHeight, nil
+	}
+	db.prunedHeightLock.RUnlock()
+
+	db.prunedHeightLock.Lock()
+	defer db.prunedHeightLock.Unlock()
+
+	if db.prunedHeightLoaded {
+		return db.prunedHeight, nil
+	}
+
+	prunedHeight, err := database.GetUInt64(db.db, prunedHeightKey)
+	if err == database.ErrNotFound {
+		prunedHeight, err = 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	db.prunedHeightLoaded = true
+	db.prunedHeight = prunedHeight
+	return prunedHeight, nil
+}
+
+// verifyHeight returns ErrPruned if [height] can no longer be read.
+func (db *Database) verifyHeight(height uint64) error {
+	prunedHeight, err := db.PrunedHeight()
+	if err != nil {
+		return err
+	}
+	if height < prunedHeight {
+		return fmt.Errorf("%w: requested %d but pruned to %d", ErrPruned, height, prunedHeight)
+	}
+	return nil
+}
+
+// Prune removes every version of every key that isn't needed to read the
+// state at [height] or above. For each key, the newest version at or below
+// [height] is kept, unless it is a deletion, in which case it is removed
+// along with every older version. Versions above [height] are never removed.
+//
+// After Prune is called, reading at any height below [height] returns
+// ErrPruned, even if Prune returns an error. If Prune is interrupted, for
+// example by cancelling [ctx], it can be resumed by calling it again with the
+// same height. Pruning to a height lower than a previously pruned height is a
+// no-op. Pruning to a height above Height returns ErrFutureHeight.
+//
+// Note: Because deletions at or below [height] are removed, GetEntry will
+// return ErrNotFound, rather than the height of the deletion, for keys that
+// were deleted at or below [height].
+func (db *Database) Prune(ctx context.Context, height uint64) error {
+	db.pruneLock.Lock()
+	defer db.pruneLock.Unlock()
+
+	lastHeight, err := db.Height()
+	if err != nil {
+		return err
+	}
+	if height > lastHeight {
+		return fmt.Errorf("%w: requested %d but last wrote %d", ErrFutureHeight, height, lastHeight)
+	}
+
+	prunedHeight, err := db.PrunedHeight()
+	if err != nil {
+		return err
+	}
+	if height < prunedHeight {
+		return nil
+	}
+
+	db.prunedHeightLock.Lock()
+	err = database.PutUInt64(db.db, prunedHeightKey, height)
+	if err == nil {
+		db.prunedHeight = height
+	}
+	db.prunedHeightLock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	var (
+		batch = db.db.NewBatch()
+		it    = db.db.NewIterator()
+
+		// lastKey is the user key of the previous entry.
+		lastKey []byte
+		// foundVersion is true if a version of [lastKey] at or below [height]
+		// has been seen.
+		foundVersion bool
+	)
+	// Defer the release of the iterator inside a closure to guarantee that the
+	// latest, not the first, iterator is released on return.
+	defer func() {
+		it.Release()
+	}()
+
+	for it.Next() {
+		dbKey := it.Key()
+		if isMetadataKey(dbKey) {
+			continue
+		}
+
+		key, keyHeight, err := parseDBKeyFromUser(dbKey)
+		if err != nil {
+			return err
+		}
+
+		isNewKey := lastKey == nil || !bytes.Equal(key, lastKey)
+		if isNewKey && batch.Size() >= pruneBatchSize {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+
+			// Reset the iterator to release references to now deleted keys.
+			// Because only a full key has been processed, the iterator can
+			// be restarted from the current entry.
+			if err := it.Error(); err != nil {
+				return err
+			}
+			start := slices.Clone(dbKey)
+			it.Release()
+			it = db.db.NewIteratorWithStart(start)
+			lastKey = nil
+			continue
+		}
+		if isNewKey {
+			lastKey = slices.Clone(key)
+			foundVersion = false
+		}
+
+		if keyHeight > height {
+			continue
+		}
+		if !foundVersion {
+			foundVersion = true
+			if _, exists := parseDBValue(it.Value()); exists {
+				continue
+			}
+		}
+		if err := batch.Delete(dbKey); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
 // Open returns a reader for the state at the given height.
+//
+// If the database has been pruned above [height], the reader will return
+// ErrPruned.
 func (db *Database) Open(height uint64) *Reader {
 	return &Reader{
 		db:     db,
@@ -4,12 +4,17 @@
 package archivedb
 
 import (
+	"bytes"
+	"context"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/units"
 )
 
 func TestDBEntries(t *testing.T) {
@@ -225,3 +230,473 @@ func TestSkipHeight(t *testing.T) {
 	require.NoError(err)
 	require.Equal(uint64(10), height)
 }
+
+func TestDeleteRange(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+
+	batch := db.NewBatch(1)
+	require.NoError(batch.Put([]byte("a"), []byte("a@1")))
+	require.NoError(batch.Put([]byte("b"), []byte("b@1")))
+	require.NoError(batch.Put([]byte("bb"), []byte("bb@1")))
+	require.NoError(batch.Put([]byte("c"), []byte("c@1")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(2)
+	require.NoError(batch.Delete([]byte("bb")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(3)
+	require.NoError(batch.Put([]byte("ba"), []byte("ba@3")))
+	require.NoError(batch.DeleteRange([]byte("b"), []byte("c")))
+	require.NoError(batch.Put([]byte("bc"), []byte("bc@3")))
+	require.NoError(batch.Write())
+
+	reader := db.Open(3)
+	value, err := reader.Get([]byte("a"))
+	require.NoError(err)
+	require.Equal([]byte("a@1"), value)
+
+	value, err = reader.Get([]byte("c"))
+	require.NoError(err)
+	require.Equal([]byte("c@1"), value)
+
+	value, err = reader.Get([]byte("bc"))
+	require.NoError(err)
+	require.Equal([]byte("bc@3"), value)
+
+	for _, key := range []string{"b", "ba", "bb"} {
+		_, err = reader.Get([]byte(key))
+		require.ErrorIs(err, database.ErrNotFound)
+	}
+
+	// The deletion of "bb" at height 2 shouldn't have been rewritten.
+	_, height, exists, err := reader.GetEntry([]byte("bb"))
+	require.NoError(err)
+	require.False(exists)
+	require.Equal(uint64(2), height)
+
+	_, height, exists, err = reader.GetEntry([]byte("b"))
+	require.NoError(err)
+	require.False(exists)
+	require.Equal(uint64(3), height)
+
+	// History before the range deletion is preserved.
+	value, err = db.Open(2).Get([]byte("b"))
+	require.NoError(err)
+	require.Equal([]byte("b@1"), value)
+}
+
+func TestKeysInRange(t *testing.T) {
+	db := New(memdb.New())
+
+	batch := db.NewBatch(1)
+	for _, key := range []string{"a", "b", "c", "ab", "bb", "pruned", "abcdefg"} {
+		require.NoError(t, batch.Put([]byte(key), []byte(key)))
+	}
+	require.NoError(t, batch.Write())
+
+	batch = db.NewBatch(2)
+	require.NoError(t, batch.Delete([]byte("bb")))
+	require.NoError(t, batch.Write())
+
+	tests := []struct {
+		name     string
+		start    []byte
+		limit    []byte
+		expected []string
+	}{
+		{
+			name:     "everything",
+			expected: []string{"a", "b", "c", "ab", "bb", "pruned", "abcdefg"},
+		},
+		{
+			name:     "bounded",
+			start:    []byte("ab"),
+			limit:    []byte("c"),
+			expected: []string{"b", "ab", "bb", "abcdefg"},
+		},
+		{
+			name:     "unbounded limit",
+			start:    []byte("b"),
+			expected: []string{"b", "c", "bb", "pruned"},
+		},
+		{
+			name:     "limit excluded",
+			start:    []byte("a"),
+			limit:    []byte("b"),
+			expected: []string{"a", "ab", "abcdefg"},
+		},
+		{
+			name:  "empty",
+			start: []byte("d"),
+			limit: []byte("e"),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			batch := db.NewBatch(3)
+			keys, err := batch.keysInRange(test.start, test.limit)
+			require.NoError(err)
+			require.ElementsMatch(test.expected, keys.List())
+		})
+	}
+}
+
+// newIteratorTestDB returns a database with keys of various lengths, including
+// [longKey], written at heights 1, 2 and 4.
+func newIteratorTestDB(require *require.Assertions, longKey []byte) *Database {
+	db := New(memdb.New())
+
+	batch := db.NewBatch(1)
+	require.NoError(batch.Put([]byte("a"), []byte("a@1")))
+	require.NoError(batch.Put([]byte("ab"), []byte("ab@1")))
+	require.NoError(batch.Put([]byte("b"), []byte("b@1")))
+	require.NoError(batch.Put(longKey, []byte("long@1")))
+	require.NoError(batch.Put([]byte{}, []byte("empty@1")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(2)
+	require.NoError(batch.Put([]byte("a"), []byte("a@2")))
+	require.NoError(batch.Delete([]byte("ab")))
+	require.NoError(batch.Put([]byte("c"), []byte("c@2")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(4)
+	require.NoError(batch.Delete([]byte("b")))
+	require.NoError(batch.Put([]byte("abc"), []byte("abc@4")))
+	require.NoError(batch.Write())
+
+	return db
+}
+
+func TestIterator(t *testing.T) {
+	longKey := bytes.Repeat([]byte{'b'}, 200)
+
+	type entry struct {
+		key   string
+		value string
+	}
+	tests := []struct {
+		name     string
+		height   uint64
+		start    []byte
+		prefix   []byte
+		expected []entry
+	}{
+		{
+			name:     "before first height",
+			height:   0,
+			expected: nil,
+		},
+		{
+			name:   "height 1",
+			height: 1,
+			expected: []entry{
+				{"", "empty@1"},
+				{"a", "a@1"},
+				{"ab", "ab@1"},
+				{"b", "b@1"},
+				{string(longKey), "long@1"},
+			},
+		},
+		{
+			name:   "skipped height",
+			height: 3,
+			expected: []entry{
+				{"", "empty@1"},
+				{"a", "a@2"},
+				{"b", "b@1"},
+				{string(longKey), "long@1"},
+				{"c", "c@2"},
+			},
+		},
+		{
+			name:   "above last height",
+			height: 10,
+			expected: []entry{
+				{"", "empty@1"},
+				{"a", "a@2"},
+				{"abc", "abc@4"},
+				{string(longKey), "long@1"},
+				{"c", "c@2"},
+			},
+		},
+		{
+			name:   "prefix",
+			height: 1,
+			prefix: []byte("a"),
+			expected: []entry{
+				{"a", "a@1"},
+				{"ab", "ab@1"},
+			},
+		},
+		{
+			name:   "start",
+			height: 10,
+			start:  []byte("ab"),
+			expected: []entry{
+				{"abc", "abc@4"},
+				{string(longKey), "long@1"},
+				{"c", "c@2"},
+			},
+		},
+		{
+			name:   "start longer than keys",
+			height: 1,
+			start:  []byte("abc"),
+			expected: []entry{
+				{"b", "b@1"},
+				{string(longKey), "long@1"},
+			},
+		},
+		{
+			name:   "start and prefix",
+			height: 10,
+			start:  []byte("b"),
+			prefix: []byte("b"),
+			expected: []entry{
+				{string(longKey), "long@1"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db := newIteratorTestDB(require, longKey)
+			it := db.Open(test.height).NewIteratorWithStartAndPrefix(test.start, test.prefix)
+			defer it.Release()
+
+			var entries []entry
+			for it.Next() {
+				entries = append(entries, entry{
+					key:   string(it.Key()),
+					value: string(it.Value()),
+				})
+			}
+			require.NoError(it.Error())
+			require.Equal(test.expected, entries)
+		})
+	}
+}
+
+func TestIteratorMatchesGet(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+
+	const (
+		numHeights = 20
+		numOps     = 10
+	)
+	var keys [][]byte
+	for i := 0; i < 30; i++ {
+		keys = append(keys, utils.RandomBytes(rand.Intn(4))) // #nosec G404
+	}
+	for height := uint64(1); height <= numHeights; height++ {
+		batch := db.NewBatch(height)
+		for i := 0; i < numOps; i++ {
+			key := keys[rand.Intn(len(keys))] // #nosec G404
+			if rand.Intn(3) == 0 {            // #nosec G404
+				require.NoError(batch.Delete(key))
+			} else {
+				require.NoError(batch.Put(key, utils.RandomBytes(8)))
+			}
+		}
+		require.NoError(batch.Write())
+	}
+
+	for height := uint64(0); height <= numHeights; height++ {
+		reader := db.Open(height)
+
+		expected := make(map[string][]byte)
+		for _, key := range keys {
+			value, err := reader.Get(key)
+			if err == database.ErrNotFound {
+				continue
+			}
+			require.NoError(err)
+			expected[string(key)] = value
+		}
+
+		it := reader.NewIterator()
+		var (
+			lastKey []byte
+			found   = make(map[string][]byte)
+		)
+		for it.Next() {
+			key := it.Key()
+			if lastKey != nil {
+				require.Negative(bytes.Compare(lastKey, key))
+			}
+			lastKey = key
+			found[string(key)] = it.Value()
+		}
+		require.NoError(it.Error())
+		it.Release()
+
+		require.Equal(expected, found)
+	}
+}
+
+func TestPrune(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	db := New(baseDB)
+
+	batch := db.NewBatch(1)
+	require.NoError(batch.Put([]byte("a"), []byte("a@1")))
+	require.NoError(batch.Put([]byte("b"), []byte("b@1")))
+	require.NoError(batch.Put([]byte("c"), []byte("c@1")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(2)
+	require.NoError(batch.Put([]byte("a"), []byte("a@2")))
+	require.NoError(batch.Delete([]byte("b")))
+	require.NoError(batch.Write())
+
+	batch = db.NewBatch(4)
+	require.NoError(batch.Put([]byte("a"), []byte("a@4")))
+	require.NoError(batch.Put([]byte("b"), []byte("b@4")))
+	require.NoError(batch.Delete([]byte("c")))
+	require.NoError(batch.Write())
+
+	prunedHeight, err := db.PrunedHeight()
+	require.NoError(err)
+	require.Zero(prunedHeight)
+
+	// Heights that haven't been written can't be pruned.
+	err = db.Prune(context.Background(), 5)
+	require.ErrorIs(err, ErrFutureHeight)
+
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Zero(prunedHeight)
+
+	require.NoError(db.Prune(context.Background(), 3))
+
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Equal(uint64(3), prunedHeight)
+
+	// a@1, b@1 and the deletion of b@2 are no longer needed.
+	for _, entry := range []struct {
+		key    string
+		height uint64
+	}{
+		{"a", 1},
+		{"b", 1},
+		{"b", 2},
+	} {
+		dbKey, _ := newDBKeyFromUser([]byte(entry.key), entry.height)
+		has, err := baseDB.Has(dbKey)
+		require.NoError(err)
+		require.False(has, "%s@%d", entry.key, entry.height)
+	}
+
+	reader := db.Open(2)
+	_, err = reader.Get([]byte("a"))
+	require.ErrorIs(err, ErrPruned)
+
+	it := reader.NewIterator()
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrPruned)
+	it.Release()
+
+	reader = db.Open(3)
+	value, height, exists, err := reader.GetEntry([]byte("a"))
+	require.NoError(err)
+	require.True(exists)
+	require.Equal(uint64(2), height)
+	require.Equal([]byte("a@2"), value)
+
+	_, err = reader.Get([]byte("b"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	value, err = reader.Get([]byte("c"))
+	require.NoError(err)
+	require.Equal([]byte("c@1"), value)
+
+	reader = db.Open(4)
+	for key, expectedValue := range map[string]string{
+		"a": "a@4",
+		"b": "b@4",
+	} {
+		value, err := reader.Get([]byte(key))
+		require.NoError(err)
+		require.Equal([]byte(expectedValue), value)
+	}
+	_, height, exists, err = reader.GetEntry([]byte("c"))
+	require.NoError(err)
+	require.False(exists)
+	require.Equal(uint64(4), height)
+
+	// Pruning to a lower height is a no-op.
+	require.NoError(db.Prune(context.Background(), 1))
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Equal(uint64(3), prunedHeight)
+
+	// The pruned height is persisted.
+	db = New(baseDB)
+	prunedHeight, err = db.PrunedHeight()
+	require.NoError(err)
+	require.Equal(uint64(3), prunedHeight)
+
+	// Pruning removes the deletion of c, and everything before it.
+	require.NoError(db.Prune(context.Background(), 4))
+	_, _, _, err = db.Open(4).GetEntry([]byte("c"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	lastHeight, err := db.Height()
+	require.NoError(err)
+	require.Equal(uint64(4), lastHeight)
+}
+
+func TestPruneResume(t *testing.T) {
+	require := require.New(t)
+
+	db := New(memdb.New())
+
+	// Write enough data to require multiple prune batches.
+	const numKeys = 2 * pruneBatchSize / units.KiB
+	keys := make([][]byte, numKeys)
+	for height := uint64(1); height <= 2; height++ {
+		batch := db.NewBatch(height)
+		for i := range keys {
+			if height == 1 {
+				keys[i] = utils.RandomBytes(units.KiB)
+			}
+			require.NoError(batch.Put(keys[i], []byte{byte(height)}))
+		}
+		require.NoError(batch.Write())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := db.Prune(ctx, 2)
+	require.ErrorIs(err, context.Canceled)
+
+	_, err = db.Open(1).Get(keys[0])
+	require.ErrorIs(err, ErrPruned)
+
+	require.NoError(db.Prune(context.Background(), 2))
+
+	reader := db.Open(2)
+	for _, key := range keys {
+		_, height, exists, err := reader.GetEntry(key)
+		require.NoError(err)
+		require.True(exists)
+		require.Equal(uint64(2), height)
+
+		dbKey, _ := newDBKeyFromUser(key, 1)
+		has, err := db.db.Has(dbKey)
+		require.NoError(err)
+		require.False(has)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package archivedb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+
+	"github.com/skychains/chain/database"
+)
+
+var _ database.Iterator = (*iterator)(nil)
+
+// iterator iterates over the user keys that exist at a given height.
+//
+// Because user keys are prefixed by their length on disk, keys of different
+// lengths aren't stored in lexicographical order. However, keys of the same
+// length are. The iterator keeps one iterator per key length and merges them.
+type iterator struct {
+	segments    []*segmentIterator
+	current     *segmentIterator
+	initialized bool
+	err         error
+}
+
+func newIterator(db database.Iteratee, height uint64, start, prefix []byte) database.Iterator {
+	lengths, err := keyLengths(db)
+	if err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+
+	if bytes.Compare(start, prefix) < 0 {
+		start = prefix
+	}
+
+	it := &iterator{}
+	for _, length := range lengths {
+		if length < uint64(len(prefix)) {
+			continue
+		}
+
+		lengthPrefix := binary.AppendUvarint(nil, length)
+		dbStart := append(slices.Clone(lengthPrefix), start...)
+		dbPrefix := append(lengthPrefix, prefix...)
+		it.segments = append(it.segments, &segmentIterator{
+			it:     db.NewIteratorWithStartAndPrefix(dbStart, dbPrefix),
+			height: height,
+			start:  start,
+		})
+	}
+	return it
+}
+
+// keyLengths returns every key length prefix that is stored in [db].
+func keyLengths(db database.Iteratee) ([]uint64, error) {
+	var (
+		lengths []uint64
+		start   []byte
+	)
+	for {
+		it := db.NewIteratorWithStart(start)
+		if !it.Next() {
+			err := it.Error()
+			it.Release()
+			return lengths, err
+		}
+
+		dbKey := it.Key()
+		length, offset := binary.Uvarint(dbKey)
+		if offset <= 0 {
+			it.Release()
+			return nil, ErrParsingKeyLength
+		}
+		lengths = append(lengths, length)
+
+		// The last byte of a uvarint never has its high bit set, so it can
+		// be incremented to skip every key with this length prefix.
+		start = slices.Clone(dbKey[:offset])
+		start[offset-1]++
+		it.Release()
+	}
+}
+
+func (it *iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if !it.initialized {
+		it.initialized = true
+		for _, s := range it.segments {
+			s.valid = s.next()
+		}
+	} else if it.current != nil {
+		it.current.valid = it.current.next()
+	}
+
+	it.current = nil
+	for _, s := range it.segments {
+		if s.err != nil {
+			it.err = s.err
+			it.current = nil
+			return false
+		}
+		if !s.valid {
+			continue
+		}
+		if it.current == nil || bytes.Compare(s.key, it.current.key) < 0 {
+			it.current = s
+		}
+	}
+	return it.current != nil
+}
+
+func (it *iterator) Error() error {
+	return it.err
+}
+
+func (it *iterator) Key() []byte {
+	if it.current == nil {
+		return nil
+	}
+	return it.current.key
+}
+
+func (it *iterator) Value() []byte {
+	if it.current == nil {
+		return nil
+	}
+	return it.current.value
+}
+
+func (it *iterator) Release() {
+	for _, s := range it.segments {
+		s.it.Release()
+	}
+}
+
+// segmentIterator iterates over the user keys of a single length that exist
+// at a given height.
+type segmentIterator struct {
+	it     database.Iterator
+	height uint64
+	start  []byte
+
+	lastKey    []byte
+	hasLastKey bool
+
+	valid bool
+	key   []byte
+	value []byte
+	err   error
+}
+
+func (s *segmentIterator) next() bool {
+	for s.it.Next() {
+		dbKey := s.it.Key()
+		if isMetadataKey(dbKey) {
+			continue
+		}
+
+		key, height, err := parseDBKeyFromUser(dbKey)
+		if err != nil {
+			s.err = err
+			return false
+		}
+
+		// Versions of a key are sorted by decreasing height, so the first
+		// version at or below the requested height is the one to report.
+		if height > s.height {
+			continue
+		}
+		if s.hasLastKey && bytes.Equal(key, s.lastKey) {
+			continue
+		}
+		s.lastKey = slices.Clone(key)
+		s.hasLastKey = true
+
+		// The start of the underlying iterator may be longer than the keys in
+		// this segment, in which case keys before [s.start] can be returned.
+		if bytes.Compare(key, s.start) < 0 {
+			continue
+		}
+
+		value, exists := parseDBValue(s.it.Value())
+		if !exists {
+			continue
+		}
+		s.key = s.lastKey
+		s.value = slices.Clone(value)
+		return true
+	}
+	s.err = s.it.Error()
+	return false
+}
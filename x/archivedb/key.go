@@ -4,6 +4,7 @@
 package archivedb
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 
@@ -14,7 +15,8 @@ var (
 	ErrParsingKeyLength   = errors.New("failed reading key length")
 	ErrIncorrectKeyLength = errors.New("incorrect key length")
 
-	heightKey = newDBKeyFromMetadata([]byte{})
+	heightKey       = newDBKeyFromMetadata([]byte{})
+	prunedHeightKey = newDBKeyFromMetadata([]byte("pruned"))
 )
 
 // The requirements of a database key are:
@@ -93,3 +95,9 @@ func newDBKeyFromMetadata(key []byte) []byte {
 	offset += copy(dbKey[offset:], key)
 	return dbKey[:offset]
 }
+
+// isMetadataKey returns true if [dbKey] is one of the metadata keys written by
+// this package.
+func isMetadataKey(dbKey []byte) bool {
+	return bytes.Equal(dbKey, heightKey) || bytes.Equal(dbKey, prunedHeightKey)
+}
@@ -5,7 +5,10 @@ package archivedb
 
 import "github.com/skychains/chain/database"
 
-var _ database.KeyValueReader = (*Reader)(nil)
+var (
+	_ database.KeyValueReader = (*Reader)(nil)
+	_ database.Iteratee       = (*Reader)(nil)
+)
 
 type Reader struct {
 	db     *Database
@@ -35,6 +38,10 @@ func (r *Reader) Get(key []byte) ([]byte, error) {
 // modified at, and a boolean to indicate if the last modification was an
 // insertion. If the key has never been modified, ErrNotFound will be returned.
 func (r *Reader) GetEntry(key []byte) ([]byte, uint64, bool, error) {
+	if err := r.db.verifyHeight(r.height); err != nil {
+		return nil, 0, false, err
+	}
+
 	it := r.db.db.NewIteratorWithStartAndPrefix(newDBKeyFromUser(key, r.height))
 	defer it.Release()
 
@@ -59,3 +66,30 @@ func (r *Reader) GetEntry(key []byte) ([]byte, uint64, bool, error) {
 	}
 	return value, height, true, nil
 }
+
+func (r *Reader) NewIterator() database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(nil, nil)
+}
+
+func (r *Reader) NewIteratorWithStart(start []byte) database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(start, nil)
+}
+
+func (r *Reader) NewIteratorWithPrefix(prefix []byte) database.Iterator {
+	return r.NewIteratorWithStartAndPrefix(nil, prefix)
+}
+
+// NewIteratorWithStartAndPrefix returns an iterator over the keys that exist at
+// the height of this reader, along with their values at this height. Keys are
+// iterated in lexicographical order.
+//
+// Because heights are append only, the iterator isn't affected by batches
+// written above the height of this reader.
+func (r *Reader) NewIteratorWithStartAndPrefix(start, prefix []byte) database.Iterator {
+	if err := r.db.verifyHeight(r.height); err != nil {
+		return &database.IteratorError{
+			Err: err,
+		}
+	}
+	return newIterator(r.db.db, r.height, start, prefix)
+}
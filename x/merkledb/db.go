@@ -6,6 +6,7 @@ package merkledb
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"runtime"
@@ -29,12 +30,11 @@ import (
 
 const (
 	// TODO: name better
-	rebuildViewSizeFractionOfCacheSize   = 50
-	minRebuildViewSizePerCommit          = 1000
-	clearBatchSize                       = units.MiB
-	rebuildIntermediateDeletionWriteSize = units.MiB
-	valueNodePrefixLen                   = 1
-	cacheEntryOverHead                   = 8
+	rebuildViewSizeFractionOfCacheSize = 50
+	minRebuildViewSizePerCommit        = 1000
+	clearBatchSize                     = units.MiB
+	valueNodePrefixLen                 = 1
+	cacheEntryOverHead                 = 8
 )
 
 var (
@@ -43,6 +43,8 @@ var (
 	metadataPrefix         = []byte{0}
 	valueNodePrefix        = []byte{1}
 	intermediateNodePrefix = []byte{2}
+	historyPrefix          = []byte{3}
+	nodePagePrefix         = []byte{4}
 
 	// cleanShutdownKey is used to flag that the database did (or did not)
 	// previously shutdown correctly.
@@ -57,11 +59,19 @@ var (
 	//
 	// Regardless of the value of [cleanShutdownKey], the value nodes must
 	// always be persisted correctly.
-	cleanShutdownKey        = []byte(string(metadataPrefix) + "cleanShutdown")
-	rootDBKey               = []byte(string(metadataPrefix) + "root")
+	cleanShutdownKey = []byte(string(metadataPrefix) + "cleanShutdown")
+	rootDBKey        = []byte(string(metadataPrefix) + "root")
+	// nodeStoreKey records the layout of the intermediate nodes on disk. If
+	// it isn't present, the intermediate nodes are stored by [KeyNodeStore].
+	nodeStoreKey            = []byte(string(metadataPrefix) + "nodeStore")
 	hadCleanShutdown        = []byte{1}
 	didNotHaveCleanShutdown = []byte{0}
 
+	// ErrRootNotInHistory is returned when a root is requested that is neither
+	// the current root nor in the history. Either it was evicted from the
+	// history or the trie never had that root.
+	ErrRootNotInHistory = fmt.Errorf("%w: root not in history", ErrInsufficientHistory)
+
 	errSameRoot = errors.New("start and end root are the same")
 )
 
@@ -129,6 +139,35 @@ type RangeProofer interface {
 	CommitRangeProof(ctx context.Context, start, end maybe.Maybe[[]byte], proof *RangeProof) error
 }
 
+type HistoricalReader interface {
+	// GetValueAtRoot returns the value associated with [key] when the root of
+	// the trie was [rootID].
+	// Returns database.ErrNotFound if [key] wasn't present at [rootID].
+	// Returns [ErrRootNotInHistory] if [rootID] isn't the current root and
+	// isn't in the history.
+	GetValueAtRoot(ctx context.Context, rootID ids.ID, key []byte) ([]byte, error)
+
+	// GetProofAtRoot generates a proof of the value associated with [key], or
+	// of its absence, when the root of the trie was [rootID].
+	// Returns ErrEmptyProof if [rootID] is ids.Empty.
+	// Returns [ErrRootNotInHistory] if [rootID] isn't the current root and
+	// isn't in the history.
+	GetProofAtRoot(ctx context.Context, rootID ids.ID, key []byte) (*Proof, error)
+}
+
+type Differ interface {
+	// Diff returns an iterator over the keys whose values differ between when
+	// the root of the trie was [fromRootID] and when it was [toRootID], in
+	// increasing order of key.
+	// If [toRootID] follows [fromRootID] in the history, the diff is read from
+	// the history. Otherwise, the tries at both roots are walked in parallel,
+	// skipping the subtries they have in common. The iterator returns
+	// [ErrInvalid] if the database is committed to while it's walking them.
+	// Returns [ErrRootNotInHistory] if either root isn't the current root and
+	// isn't in the history.
+	Diff(ctx context.Context, fromRootID ids.ID, toRootID ids.ID) (DiffIterator, error)
+}
+
 type Clearer interface {
 	// Deletes all key/value pairs from the database
 	// and clears the change history.
@@ -156,6 +195,9 @@ type MerkleDB interface {
 	ProofGetter
 	ChangeProofer
 	RangeProofer
+	HistoricalReader
+	Differ
+	Snapshotter
 	Prefetcher
 }
 
@@ -177,6 +219,17 @@ type Config struct {
 	// The number of changes to the database that we store in memory in order to
 	// serve change proofs.
 	HistoryLength uint
+	// The number of changes to the database that we store on disk in order to
+	// serve change proofs and range proofs at roots that are no longer in the
+	// in-memory history. Unlike the in-memory history, the on-disk history is
+	// kept across restarts.
+	// If both [PersistentHistoryLength] and [PersistentHistorySize] are 0, no
+	// history is stored on disk.
+	PersistentHistoryLength uint
+	// The number of bytes of changes to the database that we store on disk.
+	// The oldest changes are removed once either limit is exceeded. A limit
+	// of 0 means the history isn't limited by that measure.
+	PersistentHistorySize uint
 	// The number of bytes used to cache nodes with values.
 	ValueNodeCacheSize uint
 	// The number of bytes used to cache nodes without values.
@@ -186,6 +239,19 @@ type Config struct {
 	// The number of bytes to write to disk when intermediate nodes are evicted
 	// from the write buffer and written to disk.
 	IntermediateWriteBatchSize uint
+	// NodeStore determines how intermediate nodes are laid out on disk.
+	// If the layout differs from the one the database was written with, the
+	// intermediate nodes are rebuilt when the database is opened.
+	//
+	// If not specified, [KeyNodeStore] will be used.
+	NodeStore NodeStoreType
+	// The number of bits of key spanned by each page of a [PagedNodeStore].
+	// Must be a multiple of the number of bits in a token.
+	//
+	// If 0 is specified, pages span two tokens, and at least 8 bits.
+	NodePageDepth uint
+	// The number of bytes used to cache the pages of a [PagedNodeStore].
+	NodePageCacheSize uint
 	// If [Reg] is nil, metrics are collected locally but not exported through
 	// Prometheus.
 	// This may be useful for testing.
@@ -216,6 +282,12 @@ type merkleDB struct {
 	// historical views of the trie.
 	history *trieHistory
 
+	// Stores value change lists on disk. Used to serve change proofs and
+	// construct historical views of the trie for roots that are no longer in
+	// [history].
+	// Nil if no history is stored on disk.
+	historyDB *historyDB
+
 	// True iff the db has been closed.
 	closed bool
 
@@ -261,6 +333,18 @@ func newDatabase(
 	if err := config.BranchFactor.Valid(); err != nil {
 		return nil, err
 	}
+	if err := config.NodeStore.Valid(); err != nil {
+		return nil, err
+	}
+
+	tokenSize := BranchFactorToTokenSize[config.BranchFactor]
+	pageDepth := defaultNodePageDepth(tokenSize)
+	if config.NodePageDepth != 0 {
+		pageDepth = int(config.NodePageDepth)
+	}
+	if pageDepth%tokenSize != 0 {
+		return nil, fmt.Errorf("%w: page depth %d isn't a multiple of the token size %d", ErrInvalidNodeStore, pageDepth, tokenSize)
+	}
 
 	hasher := config.Hasher
 	if hasher == nil {
@@ -276,17 +360,28 @@ func newDatabase(
 	// reduce memory allocations.
 	bufferPool := utils.NewBytesPool()
 
+	store, err := newNodeStore(
+		db,
+		bufferPool,
+		config.NodeStore,
+		pageDepth,
+		int(config.NodePageCacheSize),
+		tokenSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	trieDB := &merkleDB{
 		metrics: metrics,
 		baseDB:  db,
 		intermediateNodeDB: newIntermediateNodeDB(
 			db,
-			bufferPool,
+			store,
 			metrics,
 			int(config.IntermediateNodeCacheSize),
 			int(config.IntermediateWriteBufferSize),
 			int(config.IntermediateWriteBatchSize),
-			BranchFactorToTokenSize[config.BranchFactor],
 			hasher,
 		),
 		valueNodeDB: newValueNodeDB(
@@ -301,10 +396,15 @@ func newDatabase(
 		infoTracer:       getTracerIfEnabled(config.TraceLevel, InfoTrace, config.Tracer),
 		childViews:       make([]*view, 0, defaultPreallocationSize),
 		hashNodesKeyPool: newBytesPool(rootGenConcurrency),
-		tokenSize:        BranchFactorToTokenSize[config.BranchFactor],
+		tokenSize:        tokenSize,
 		hasher:           hasher,
 	}
 
+	storeChanged, err := trieDB.setNodeStore(config.NodeStore, pageDepth)
+	if err != nil {
+		return nil, err
+	}
+
 	shutdownType, err := trieDB.baseDB.Get(cleanShutdownKey)
 	switch err {
 	case nil:
@@ -315,7 +415,7 @@ func newDatabase(
 	default:
 		return nil, err
 	}
-	if bytes.Equal(shutdownType, didNotHaveCleanShutdown) {
+	if storeChanged || bytes.Equal(shutdownType, didNotHaveCleanShutdown) {
 		if err := trieDB.rebuild(ctx, int(config.ValueNodeCacheSize)); err != nil {
 			return nil, err
 		}
@@ -325,6 +425,20 @@ func newDatabase(
 		}
 	}
 
+	// The on-disk history is loaded after the trie has been rebuilt so that
+	// the rebuild isn't recorded as changes to the trie.
+	if config.PersistentHistoryLength != 0 || config.PersistentHistorySize != 0 {
+		trieDB.historyDB, err = newHistoryDB(
+			db,
+			uint64(config.PersistentHistoryLength),
+			uint64(config.PersistentHistorySize),
+			trieDB.rootID,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// add current root to history (has no changes)
 	trieDB.history.record(&changeSummary{
 		rootID: trieDB.rootID,
@@ -340,6 +454,52 @@ func newDatabase(
 	return trieDB, err
 }
 
+// setNodeStore records that the intermediate nodes are laid out by
+// [storeType], with pages spanning [pageDepth] bits if they're paged.
+// If they were previously laid out differently, the previous layout is
+// deleted and true is returned, in which case the intermediate nodes must be
+// rebuilt.
+func (db *merkleDB) setNodeStore(storeType NodeStoreType, pageDepth int) (bool, error) {
+	w := codecWriter{
+		b: make([]byte, 0, 1+binary.MaxVarintLen64),
+	}
+	w.b = append(w.b, byte(storeType))
+	if storeType == PagedNodeStore {
+		w.Uvarint(uint64(pageDepth))
+	}
+
+	previousStoreBytes, err := db.baseDB.Get(nodeStoreKey)
+	switch err {
+	case nil:
+	case database.ErrNotFound:
+		previousStoreBytes = []byte{byte(KeyNodeStore)}
+	default:
+		return false, err
+	}
+	if bytes.Equal(previousStoreBytes, w.b) {
+		return false, nil
+	}
+
+	// The intermediate nodes aren't valid until they are rebuilt, so the db is
+	// marked as not having been cleanly closed before anything is deleted.
+	// This ensures the nodes are rebuilt again if the rebuild is interrupted.
+	if err := db.baseDB.Put(cleanShutdownKey, didNotHaveCleanShutdown); err != nil {
+		return false, err
+	}
+
+	// Delete the nodes in the previous layout. The new layout is recorded
+	// afterwards so that the previous layout is deleted again if this is
+	// interrupted.
+	previousPrefix := intermediateNodePrefix
+	if len(previousStoreBytes) > 0 && NodeStoreType(previousStoreBytes[0]) == PagedNodeStore {
+		previousPrefix = nodePagePrefix
+	}
+	if err := database.ClearPrefix(db.baseDB, previousPrefix, clearBatchSize); err != nil {
+		return false, err
+	}
+	return true, db.baseDB.Put(nodeStoreKey, w.b)
+}
+
 // Deletes every intermediate node and rebuilds them by re-adding every key/value.
 // TODO: make this more efficient by only clearing out the stale portions of the trie.
 func (db *merkleDB) rebuild(ctx context.Context, cacheSize int) error {
@@ -347,7 +507,7 @@ func (db *merkleDB) rebuild(ctx context.Context, cacheSize int) error {
 	db.rootID = ids.Empty
 
 	// Delete intermediate nodes.
-	if err := database.ClearPrefix(db.baseDB, intermediateNodePrefix, rebuildIntermediateDeletionWriteSize); err != nil {
+	if err := db.intermediateNodeDB.Clear(); err != nil {
 		return err
 	}
 
@@ -656,6 +816,34 @@ func (db *merkleDB) GetProof(ctx context.Context, key []byte) (*Proof, error) {
 	return getProof(db, key)
 }
 
+func (db *merkleDB) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	_, span := db.infoTracer.Start(ctx, "MerkleDB.GetMultiProof")
+	defer span.End()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	return getMultiProof(db, keys)
+}
+
+func (db *merkleDB) GetPrefixProof(ctx context.Context, prefix []byte) (*PrefixProof, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	_, span := db.infoTracer.Start(ctx, "MerkleDB.GetPrefixProof")
+	defer span.End()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	return getPrefixProof(db, prefix)
+}
+
 func (db *merkleDB) GetRangeProof(
 	ctx context.Context,
 	start maybe.Maybe[[]byte],
@@ -697,13 +885,108 @@ func (db *merkleDB) GetRangeProofAtRoot(
 		return nil, ErrEmptyProof
 	}
 
-	historicalTrie, err := db.getTrieAtRootForRange(rootID, start, end)
+	historicalTrie, err := db.getTrieAtRootForRange(ctx, rootID, start, end)
 	if err != nil {
 		return nil, err
 	}
 	return getRangeProof(historicalTrie, start, end, maxLength)
 }
 
+func (db *merkleDB) GetValueAtRoot(ctx context.Context, rootID ids.ID, key []byte) ([]byte, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	_, span := db.debugTracer.Start(ctx, "MerkleDB.GetValueAtRoot")
+	defer span.End()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+
+	if rootID == db.getMerkleRoot() {
+		return db.GetValue(ctx, key)
+	}
+
+	value, changed, err := db.getValueChangeSinceRoot(rootID, ToKey(key))
+	if err != nil {
+		return nil, err
+	}
+	if !changed {
+		// The value wasn't changed since [rootID], so its current value is its
+		// value at [rootID].
+		return db.GetValue(ctx, key)
+	}
+	if value.IsNothing() {
+		return nil, database.ErrNotFound
+	}
+	return slices.Clone(value.Value()), nil
+}
+
+// getValueChangeSinceRoot returns the value of [key] at [rootID] and true if
+// it was changed after [rootID]. Otherwise, returns false.
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) getValueChangeSinceRoot(rootID ids.ID, key Key) (maybe.Maybe[[]byte], bool, error) {
+	keyBytes := maybe.Some(key.Bytes())
+	changes, err := db.history.getChangesToGetToRoot(rootID, keyBytes, keyBytes)
+	if err == nil {
+		valueChange, ok := changes.values[key]
+		if !ok {
+			return maybe.Nothing[[]byte](), false, nil
+		}
+		return valueChange.after, true, nil
+	}
+	if !errors.Is(err, ErrInsufficientHistory) {
+		return maybe.Nothing[[]byte](), false, err
+	}
+	if db.historyDB == nil {
+		return maybe.Nothing[[]byte](), false, fmt.Errorf("%w: %s", ErrRootNotInHistory, rootID)
+	}
+
+	value, changed, err := db.historyDB.getValueAtRoot(rootID, key)
+	if errors.Is(err, ErrInsufficientHistory) {
+		return maybe.Nothing[[]byte](), false, fmt.Errorf("%w: %s", ErrRootNotInHistory, rootID)
+	}
+	return value, changed, err
+}
+
+func (db *merkleDB) GetProofAtRoot(ctx context.Context, rootID ids.ID, key []byte) (*Proof, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	_, span := db.infoTracer.Start(ctx, "MerkleDB.GetProofAtRoot")
+	defer span.End()
+
+	switch {
+	case db.closed:
+		return nil, database.ErrClosed
+	case rootID == ids.Empty:
+		return nil, ErrEmptyProof
+	}
+
+	keyBytes := maybe.Some(key)
+	historicalTrie, err := db.getTrieAtRootForRange(ctx, rootID, keyBytes, keyBytes)
+	if errors.Is(err, ErrInsufficientHistory) {
+		return nil, fmt.Errorf("%w: %s", ErrRootNotInHistory, rootID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return getProof(historicalTrie, key)
+}
+
+func (db *merkleDB) Diff(ctx context.Context, fromRootID ids.ID, toRootID ids.ID) (DiffIterator, error) {
+	db.commitLock.RLock()
+	defer db.commitLock.RUnlock()
+
+	ctx, span := db.infoTracer.Start(ctx, "MerkleDB.Diff")
+	defer span.End()
+
+	if db.closed {
+		return nil, database.ErrClosed
+	}
+	return db.diff(ctx, fromRootID, toRootID)
+}
+
 func (db *merkleDB) GetChangeProof(
 	ctx context.Context,
 	startRootID ids.ID,
@@ -732,6 +1015,9 @@ func (db *merkleDB) GetChangeProof(
 	}
 
 	changes, err := db.history.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	if errors.Is(err, ErrInsufficientHistory) && db.historyDB != nil {
+		changes, err = db.historyDB.getValueChanges(startRootID, endRootID, start, end, maxLength)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -763,7 +1049,7 @@ func (db *merkleDB) GetChangeProof(
 
 	// Since we hold [db.commitlock] we must still have sufficient
 	// history to recreate the trie at [endRootID].
-	historicalTrie, err := db.getTrieAtRootForRange(endRootID, start, largestKey)
+	historicalTrie, err := db.getTrieAtRootForRange(ctx, endRootID, start, largestKey)
 	if err != nil {
 		return nil, err
 	}
@@ -929,6 +1215,14 @@ func (db *merkleDB) DeleteContext(ctx context.Context, key []byte) error {
 	return view.commitToDB(ctx)
 }
 
+func (db *merkleDB) DeleteRange(start, limit []byte) error {
+	return db.commitBatch([]database.BatchOp{{
+		Key:         start,
+		Limit:       limit,
+		DeleteRange: true,
+	}})
+}
+
 // Assumes values inside [ops] are safe to reference after the function
 // returns. Assumes [db.lock] isn't held.
 func (db *merkleDB) commitBatch(ops []database.BatchOp) error {
@@ -988,9 +1282,23 @@ func (db *merkleDB) commitView(ctx context.Context, trieToCommit *view) error {
 		return err
 	}
 
+	// The changes are recorded in the same batch as the value nodes so that
+	// the on-disk history always ends at the persisted values.
+	var historyMetadata historyMetadata
+	if db.historyDB != nil {
+		var err error
+		historyMetadata, err = db.historyDB.record(valueNodeBatch, changes)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := db.commitValueChanges(ctx, valueNodeBatch); err != nil {
 		return err
 	}
+	if db.historyDB != nil {
+		db.historyDB.historyMetadata = historyMetadata
+	}
 
 	db.history.record(changes)
 
@@ -1267,6 +1575,7 @@ func (db *merkleDB) initializeRoot() error {
 // If [end] is Nothing, there's no upper bound on the range.
 // Assumes [db.commitLock] is read locked.
 func (db *merkleDB) getTrieAtRootForRange(
+	ctx context.Context,
 	rootID ids.ID,
 	start maybe.Maybe[[]byte],
 	end maybe.Maybe[[]byte],
@@ -1277,6 +1586,16 @@ func (db *merkleDB) getTrieAtRootForRange(
 	}
 
 	changeHistory, err := db.history.getChangesToGetToRoot(rootID, start, end)
+	if errors.Is(err, ErrInsufficientHistory) && db.historyDB != nil {
+		// The trie is reverted using every change since [rootID], regardless
+		// of [start] and [end], so that the IDs of the nodes outside of the
+		// range are correct.
+		values, err := db.historyDB.getValuesAtRoot(rootID)
+		if err != nil {
+			return nil, err
+		}
+		return newHistoricalView(ctx, db, rootID, values)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -1371,6 +1690,21 @@ func (db *merkleDB) Clear() error {
 		values: map[Key]*change[maybe.Maybe[[]byte]]{},
 		nodes:  map[Key]*change[*node]{},
 	})
+	if db.historyDB == nil {
+		return nil
+	}
+	if err := db.historyDB.clear(); err != nil {
+		return err
+	}
+	batch := db.baseDB.NewBatch()
+	historyMetadata, err := db.historyDB.record(batch, &changeSummary{rootID: db.rootID})
+	if err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	db.historyDB.historyMetadata = historyMetadata
 	return nil
 }
 
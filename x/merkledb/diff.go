@@ -0,0 +1,397 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/maybe"
+)
+
+const (
+	// KeyAdded means the key wasn't in the trie being diffed from.
+	KeyAdded DiffType = iota
+	// KeyUpdated means the key is in both tries, with different values.
+	KeyUpdated
+	// KeyDeleted means the key isn't in the trie being diffed to.
+	KeyDeleted
+)
+
+var (
+	_ DiffIterator = (*changesDiffIterator)(nil)
+	_ DiffIterator = (*trieDiffIterator)(nil)
+)
+
+// DiffType is how the value of a key differs between two tries.
+type DiffType byte
+
+// KeyDiff is the difference between the values of a key in two tries.
+type KeyDiff struct {
+	Key []byte
+	// The value of [Key] in the trie being diffed from, or Nothing if it
+	// isn't in that trie.
+	OldValue maybe.Maybe[[]byte]
+	// The value of [Key] in the trie being diffed to, or Nothing if it isn't
+	// in that trie.
+	NewValue maybe.Maybe[[]byte]
+}
+
+func (d KeyDiff) Type() DiffType {
+	switch {
+	case d.OldValue.IsNothing():
+		return KeyAdded
+	case d.NewValue.IsNothing():
+		return KeyDeleted
+	default:
+		return KeyUpdated
+	}
+}
+
+// DiffIterator iterates over the keys whose values differ between two tries,
+// in increasing order of key.
+type DiffIterator interface {
+	// Next moves the iterator to the next key whose value differs.
+	// Returns false once the iterator is exhausted, or if an error occurred.
+	Next() bool
+
+	// Diff returns the difference at the current key.
+	Diff() KeyDiff
+
+	// Error returns the error that stopped the iteration, if any.
+	Error() error
+
+	// Release releases the resources held by the iterator.
+	Release()
+}
+
+// diff returns an iterator over the keys whose values differ between
+// [fromRootID] and [toRootID].
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) diff(ctx context.Context, fromRootID ids.ID, toRootID ids.ID) (DiffIterator, error) {
+	// If the changes from [fromRootID] to [toRootID] are in the history, the
+	// diff is the net change of each key changed by them.
+	changes, err := db.history.getValueChanges(fromRootID, toRootID, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), math.MaxInt)
+	if errors.Is(err, ErrInsufficientHistory) && db.historyDB != nil {
+		changes, err = db.historyDB.getValueChanges(fromRootID, toRootID, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), math.MaxInt)
+	}
+	if err == nil {
+		return newChangesDiffIterator(changes), nil
+	}
+	if !errors.Is(err, ErrInsufficientHistory) {
+		return nil, err
+	}
+
+	// Otherwise, [toRootID] is before [fromRootID], or they were never roots
+	// in that order. Walk the tries at both roots instead.
+	fromTrie, err := db.getTrieAtRoot(ctx, fromRootID)
+	if err != nil {
+		return nil, err
+	}
+	toTrie, err := db.getTrieAtRoot(ctx, toRootID)
+	if err != nil {
+		return nil, err
+	}
+	return newTrieDiffIterator(db, fromTrie, fromRootID, toTrie, toRootID), nil
+}
+
+// getTrieAtRoot returns the trie as it was when it had root [rootID].
+// Returns [ErrRootNotInHistory] if [rootID] isn't the current root and isn't
+// in the history.
+// Assumes [db.commitLock] is read locked.
+func (db *merkleDB) getTrieAtRoot(ctx context.Context, rootID ids.ID) (Trie, error) {
+	t, err := db.getTrieAtRootForRange(ctx, rootID, maybe.Nothing[[]byte](), maybe.Nothing[[]byte]())
+	if errors.Is(err, ErrInsufficientHistory) {
+		return nil, fmt.Errorf("%w: %s", ErrRootNotInHistory, rootID)
+	}
+	return t, err
+}
+
+// changesDiffIterator iterates over the net changes of a series of changes
+// from the history.
+type changesDiffIterator struct {
+	// Sorted by increasing key.
+	diffs []KeyDiff
+	diff  KeyDiff
+}
+
+func newChangesDiffIterator(changes *changeSummary) *changesDiffIterator {
+	keys := maps.Keys(changes.values)
+	utils.Sort(keys)
+
+	diffs := make([]KeyDiff, 0, len(keys))
+	for _, key := range keys {
+		change := changes.values[key]
+		if maybe.Equal(change.before, change.after, bytes.Equal) {
+			// The key was changed back to its value before the changes, or it
+			// was deleted when it wasn't in the trie.
+			continue
+		}
+		diffs = append(diffs, KeyDiff{
+			Key: key.Bytes(),
+			// Copy the values so that the history isn't modified.
+			OldValue: maybe.Bind(change.before, slices.Clone[[]byte]),
+			NewValue: maybe.Bind(change.after, slices.Clone[[]byte]),
+		})
+	}
+	return &changesDiffIterator{
+		diffs: diffs,
+	}
+}
+
+func (it *changesDiffIterator) Next() bool {
+	if len(it.diffs) == 0 {
+		it.diff = KeyDiff{}
+		return false
+	}
+	it.diff = it.diffs[0]
+	it.diffs = it.diffs[1:]
+	return true
+}
+
+func (it *changesDiffIterator) Diff() KeyDiff {
+	return it.diff
+}
+
+func (*changesDiffIterator) Error() error {
+	return nil
+}
+
+func (it *changesDiffIterator) Release() {
+	it.diffs = nil
+	it.diff = KeyDiff{}
+}
+
+// subtrie is a node of a trie, along with all of its descendants.
+type subtrie struct {
+	key      Key
+	id       ids.ID
+	hasValue bool
+	// Nil until it's loaded from the trie.
+	node *node
+}
+
+// subtriePair is a subtrie of the trie being diffed from, and a subtrie of
+// the trie being diffed to, that contain the same range of keys.
+// Either may be nil if its trie has no keys in the range, but not both.
+type subtriePair struct {
+	from *subtrie
+	to   *subtrie
+}
+
+// trieDiffIterator diffs two tries by walking them in parallel. Subtries
+// with the same ID are skipped, since they contain the same key-value pairs.
+type trieDiffIterator struct {
+	db *merkleDB
+	// The root of [db] when the iterator was created. The tries may read
+	// nodes from [db], so they're invalid once its root changes.
+	dbRootID  ids.ID
+	fromTrie  Trie
+	toTrie    Trie
+	tokenSize int
+
+	// The pairs of subtries that haven't been diffed yet. The last pair has
+	// the smallest keys, so it's diffed next.
+	pairs []subtriePair
+
+	diff KeyDiff
+	err  error
+}
+
+// Assumes [db.commitLock] is read locked.
+func newTrieDiffIterator(
+	db *merkleDB,
+	fromTrie Trie,
+	fromRootID ids.ID,
+	toTrie Trie,
+	toRootID ids.ID,
+) *trieDiffIterator {
+	it := &trieDiffIterator{
+		db:        db,
+		dbRootID:  db.getMerkleRoot(),
+		fromTrie:  fromTrie,
+		toTrie:    toTrie,
+		tokenSize: db.tokenSize,
+	}
+	pair := subtriePair{
+		from: rootSubtrie(fromTrie, fromRootID),
+		to:   rootSubtrie(toTrie, toRootID),
+	}
+	if pair.from != nil || pair.to != nil {
+		it.pairs = append(it.pairs, pair)
+	}
+	return it
+}
+
+// rootSubtrie returns the subtrie of all of [t], or nil if [t] is empty.
+func rootSubtrie(t Trie, rootID ids.ID) *subtrie {
+	root := t.getRoot()
+	if root.IsNothing() {
+		return nil
+	}
+	return &subtrie{
+		key:  root.Value().key,
+		id:   rootID,
+		node: root.Value(),
+	}
+}
+
+func (it *trieDiffIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	it.db.commitLock.RLock()
+	defer it.db.commitLock.RUnlock()
+
+	switch {
+	case it.db.closed:
+		it.err = database.ErrClosed
+	case it.db.getMerkleRoot() != it.dbRootID:
+		it.err = ErrInvalid
+	}
+
+	for it.err == nil && len(it.pairs) > 0 {
+		pair := it.pairs[len(it.pairs)-1]
+		it.pairs = it.pairs[:len(it.pairs)-1]
+
+		var found bool
+		found, it.err = it.diffPair(pair)
+		if found {
+			return true
+		}
+	}
+	it.diff = KeyDiff{}
+	return false
+}
+
+// diffPair compares the values of the keys at the roots of the subtries in
+// [pair], and adds the pairs of their child subtries to [it.pairs].
+// Returns true if the values differ, in which case [it.diff] is set.
+func (it *trieDiffIterator) diffPair(pair subtriePair) (bool, error) {
+	from, to := pair.from, pair.to
+	if from != nil && to != nil {
+		if from.key == to.key && from.id == to.id {
+			// The subtries are the same.
+			return false, nil
+		}
+		if !from.key.HasPrefix(to.key) && !to.key.HasPrefix(from.key) {
+			// The subtries don't have any keys in common, so each is
+			// compared to nothing, starting with the one with smaller keys.
+			if from.key.Less(to.key) {
+				it.pairs = append(it.pairs, subtriePair{to: to}, subtriePair{from: from})
+			} else {
+				it.pairs = append(it.pairs, subtriePair{from: from}, subtriePair{to: to})
+			}
+			return false, nil
+		}
+	}
+
+	// [key] is the shorter of the keys of the subtries. The other subtrie is
+	// compared to the child of [key] that it would be in.
+	var key Key
+	switch {
+	case to == nil || (from != nil && to.key.HasPrefix(from.key)):
+		key = from.key
+	default:
+		key = to.key
+	}
+
+	fromValue, fromChildren, err := it.expand(it.fromTrie, from, key)
+	if err != nil {
+		return false, err
+	}
+	toValue, toChildren, err := it.expand(it.toTrie, to, key)
+	if err != nil {
+		return false, err
+	}
+
+	indices := make([]byte, 0, len(fromChildren)+len(toChildren))
+	for index := range fromChildren {
+		indices = append(indices, index)
+	}
+	for index := range toChildren {
+		if _, ok := fromChildren[index]; !ok {
+			indices = append(indices, index)
+		}
+	}
+	// Add the pairs of children in decreasing order of index, so that they're
+	// diffed in increasing order of key.
+	slices.Sort(indices)
+	for i := len(indices) - 1; i >= 0; i-- {
+		index := indices[i]
+		it.pairs = append(it.pairs, subtriePair{
+			from: fromChildren[index],
+			to:   toChildren[index],
+		})
+	}
+
+	if fromValue.IsNothing() && toValue.IsNothing() ||
+		fromValue.HasValue() && toValue.HasValue() && bytes.Equal(fromValue.Value(), toValue.Value()) {
+		return false, nil
+	}
+	it.diff = KeyDiff{
+		Key:      key.Bytes(),
+		OldValue: maybe.Bind(fromValue, slices.Clone[[]byte]),
+		NewValue: maybe.Bind(toValue, slices.Clone[[]byte]),
+	}
+	return true, nil
+}
+
+// expand returns the value at [key] and the child subtries of [key] in [s],
+// which is a subtrie of [t] whose key has the prefix [key].
+// If [s] is nil, there's no value or children.
+func (it *trieDiffIterator) expand(t Trie, s *subtrie, key Key) (maybe.Maybe[[]byte], map[byte]*subtrie, error) {
+	switch {
+	case s == nil:
+		return maybe.Nothing[[]byte](), nil, nil
+	case s.key != key:
+		// [s] is the only child of [key].
+		return maybe.Nothing[[]byte](), map[byte]*subtrie{
+			s.key.Token(key.length, it.tokenSize): s,
+		}, nil
+	}
+
+	if s.node == nil {
+		n, err := t.getEditableNode(s.key, s.hasValue)
+		if err != nil {
+			return maybe.Nothing[[]byte](), nil, err
+		}
+		s.node = n
+	}
+
+	children := make(map[byte]*subtrie, len(s.node.children))
+	for index, child := range s.node.children {
+		children[index] = &subtrie{
+			key:      s.node.key.Extend(ToToken(index, it.tokenSize), child.compressedKey),
+			id:       child.id,
+			hasValue: child.hasValue,
+		}
+	}
+	return s.node.value, children, nil
+}
+
+func (it *trieDiffIterator) Diff() KeyDiff {
+	return it.diff
+}
+
+func (it *trieDiffIterator) Error() error {
+	return it.err
+}
+
+func (it *trieDiffIterator) Release() {
+	it.pairs = nil
+	it.diff = KeyDiff{}
+	it.fromTrie = nil
+	it.toTrie = nil
+}
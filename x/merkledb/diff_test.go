@@ -0,0 +1,365 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/maps"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+)
+
+// Returns all of the differences returned by [it].
+func collectDiffs(t *testing.T, it DiffIterator) []KeyDiff {
+	defer it.Release()
+
+	diffs := []KeyDiff{}
+	for it.Next() {
+		diffs = append(diffs, it.Diff())
+	}
+	require.NoError(t, it.Error())
+	return diffs
+}
+
+// Returns the differences between the key-value pairs [from] and [to].
+func expectedDiffs(from map[string][]byte, to map[string][]byte) []KeyDiff {
+	keys := maps.Keys(from)
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	diffs := []KeyDiff{}
+	for _, key := range keys {
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		if inFrom && inTo && bytes.Equal(fromValue, toValue) {
+			continue
+		}
+
+		diff := KeyDiff{
+			Key: []byte(key),
+		}
+		if inFrom {
+			diff.OldValue = maybe.Some(fromValue)
+		}
+		if inTo {
+			diff.NewValue = maybe.Some(toValue)
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// Returns the key-value pairs in [db].
+func getKeyValues(t *testing.T, db database.Iteratee) map[string][]byte {
+	it := db.NewIterator()
+	defer it.Release()
+
+	keyValues := make(map[string][]byte)
+	for it.Next() {
+		keyValues[string(it.Key())] = slices.Clone(it.Value())
+	}
+	require.NoError(t, it.Error())
+	return keyValues
+}
+
+// writeRandomChangesWithValues commits [numCommits] random changes to [db]
+// and returns the root and the key-value pairs after each commit, as well as
+// before the first commit.
+func writeRandomChangesWithValues(
+	t *testing.T,
+	r *rand.Rand,
+	db *merkleDB,
+	numCommits int,
+) ([]ids.ID, []map[string][]byte) {
+	require := require.New(t)
+
+	root, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	roots := []ids.ID{root}
+	keyValues := []map[string][]byte{getKeyValues(t, db)}
+	for i := 0; i < numCommits; i++ {
+		batch := db.NewBatch()
+		for j := 0; j < 10; j++ {
+			key := []byte(strconv.Itoa(r.Intn(50)))
+			if r.Intn(4) == 0 {
+				require.NoError(batch.Delete(key))
+				continue
+			}
+			value := make([]byte, r.Intn(32))
+			_, _ = r.Read(value)
+			require.NoError(batch.Put(key, value))
+		}
+		require.NoError(batch.Write())
+
+		root, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		roots = append(roots, root)
+		keyValues = append(keyValues, getKeyValues(t, db))
+	}
+	return roots, keyValues
+}
+
+func TestKeyDiffType(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     KeyDiff
+		expected DiffType
+	}{
+		{
+			name: "added",
+			diff: KeyDiff{
+				NewValue: maybe.Some([]byte{1}),
+			},
+			expected: KeyAdded,
+		},
+		{
+			name: "updated",
+			diff: KeyDiff{
+				OldValue: maybe.Some([]byte{1}),
+				NewValue: maybe.Some([]byte{2}),
+			},
+			expected: KeyUpdated,
+		},
+		{
+			name: "updated to empty value",
+			diff: KeyDiff{
+				OldValue: maybe.Some([]byte{1}),
+				NewValue: maybe.Some([]byte{}),
+			},
+			expected: KeyUpdated,
+		},
+		{
+			name: "deleted",
+			diff: KeyDiff{
+				OldValue: maybe.Some([]byte{1}),
+			},
+			expected: KeyDeleted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.diff.Type())
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		config func() Config
+	}{
+		{
+			name:   "in-memory history",
+			config: newDefaultConfig,
+		},
+		{
+			name: "persistent history",
+			config: func() Config {
+				return newPersistentHistoryConfig(100, 0)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			ctx := context.Background()
+			db, err := newDatabase(ctx, memdb.New(), tt.config(), &mockMetrics{})
+			require.NoError(err)
+
+			r := rand.New(rand.NewSource(0)) // #nosec G404
+			roots, keyValues := writeRandomChangesWithValues(t, r, db, 20)
+
+			// Diffs from earlier roots to later roots are read from the
+			// history. The others are calculated by walking the tries.
+			for i := range roots {
+				for j := range roots {
+					it, err := db.Diff(ctx, roots[i], roots[j])
+					require.NoError(err)
+					require.Equal(
+						expectedDiffs(keyValues[i], keyValues[j]),
+						collectDiffs(t, it),
+						"diff from %d to %d", i, j,
+					)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffRevertedChanges(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("k1"), []byte("v1")))
+	require.NoError(db.Put([]byte("k2"), []byte("v1")))
+	rootA, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("k1"), []byte("v2")))
+	require.NoError(db.Put([]byte("k3"), []byte("v2")))
+	rootB, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	// Revert the changes to k1 and k3, and change k2. Then put the value of k1
+	// again, which records a change whose before and after values are equal.
+	batch := db.NewBatch()
+	require.NoError(batch.Put([]byte("k1"), []byte("v1")))
+	require.NoError(batch.Put([]byte("k2"), []byte("v2")))
+	require.NoError(batch.Delete([]byte("k3")))
+	require.NoError(batch.Write())
+	require.NoError(db.Put([]byte("k1"), []byte("v1")))
+	rootC, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	// The reverted keys are unchanged from A to C.
+	it, err := db.Diff(ctx, rootA, rootC)
+	require.NoError(err)
+	require.Equal(
+		[]KeyDiff{
+			{
+				Key:      []byte("k2"),
+				OldValue: maybe.Some([]byte("v1")),
+				NewValue: maybe.Some([]byte("v2")),
+			},
+		},
+		collectDiffs(t, it),
+	)
+
+	// Revert the change to k2 as well, so that the root is A again.
+	require.NoError(db.Put([]byte("k2"), []byte("v1")))
+	rootD, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(rootA, rootD)
+
+	// k2 was changed and changed back after B, so only the keys that B
+	// changed differ from B to A.
+	it, err = db.Diff(ctx, rootB, rootD)
+	require.NoError(err)
+	require.Equal(
+		[]KeyDiff{
+			{
+				Key:      []byte("k1"),
+				OldValue: maybe.Some([]byte("v2")),
+				NewValue: maybe.Some([]byte("v1")),
+			},
+			{
+				Key:      []byte("k3"),
+				OldValue: maybe.Some([]byte("v2")),
+				NewValue: maybe.Nothing[[]byte](),
+			},
+		},
+		collectDiffs(t, it),
+	)
+}
+
+func TestDiffRootNotInHistory(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	roots, _ := writeRandomChangesWithValues(t, r, db, 5)
+
+	unknownRoot := ids.GenerateTestID()
+	_, err = db.Diff(ctx, unknownRoot, roots[len(roots)-1])
+	require.ErrorIs(err, ErrRootNotInHistory)
+
+	_, err = db.Diff(ctx, roots[len(roots)-1], unknownRoot)
+	require.ErrorIs(err, ErrRootNotInHistory)
+}
+
+func TestDiffInvalidatedByCommit(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	roots, _ := writeRandomChangesWithValues(t, r, db, 5)
+
+	// The diff from a later root to an earlier root walks the tries.
+	it, err := db.Diff(ctx, roots[len(roots)-1], roots[0])
+	require.NoError(err)
+	defer it.Release()
+
+	require.NoError(db.Put([]byte("new key"), []byte("new value")))
+
+	require.False(it.Next())
+	require.ErrorIs(it.Error(), ErrInvalid)
+}
+
+func TestTrieDiffIterator(t *testing.T) {
+	for _, bf := range validBranchFactors {
+		t.Run(fmt.Sprint(bf), func(t *testing.T) {
+			require := require.New(t)
+
+			ctx := context.Background()
+			db, err := getBasicDBWithBranchFactor(bf)
+			require.NoError(err)
+
+			r := rand.New(rand.NewSource(0)) // #nosec G404
+			randomBytes := func(maxLen int) []byte {
+				b := make([]byte, r.Intn(maxLen))
+				_, _ = r.Read(b)
+				return b
+			}
+
+			batch := db.NewBatch()
+			for i := 0; i < 500; i++ {
+				require.NoError(batch.Put(randomBytes(4), randomBytes(8)))
+			}
+			require.NoError(batch.Write())
+
+			for i := 0; i < 10; i++ {
+				ops := make([]database.BatchOp, 50)
+				for j := range ops {
+					ops[j] = database.BatchOp{
+						Key:    randomBytes(4),
+						Value:  randomBytes(8),
+						Delete: r.Intn(2) == 0,
+					}
+				}
+				view, err := db.NewView(ctx, ViewChanges{BatchOps: ops})
+				require.NoError(err)
+
+				dbRoot, err := db.GetMerkleRoot(ctx)
+				require.NoError(err)
+				viewRoot, err := view.GetMerkleRoot(ctx)
+				require.NoError(err)
+
+				dbKeyValues := getKeyValues(t, db)
+				viewKeyValues := getKeyValues(t, view)
+
+				it := newTrieDiffIterator(db, db, dbRoot, view, viewRoot)
+				require.Equal(expectedDiffs(dbKeyValues, viewKeyValues), collectDiffs(t, it))
+
+				it = newTrieDiffIterator(db, view, viewRoot, db, dbRoot)
+				require.Equal(expectedDiffs(viewKeyValues, dbKeyValues), collectDiffs(t, it))
+			}
+		})
+	}
+}
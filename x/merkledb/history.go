@@ -99,7 +99,7 @@ func (th *trieHistory) getValueChanges(
 	}
 
 	if startRoot == endRoot {
-		return newChangeSummary(maxLength), nil
+		return newChangeSummary(min(maxLength, defaultPreallocationSize)), nil
 	}
 
 	// [endRootChanges] is the last change in the history resulting in [endRoot].
@@ -137,6 +137,7 @@ func (th *trieHistory) getValueChanges(
 		//
 		// Translate the insert number to the index in [th.history] so we can iterate
 		// backward from [endRootChanges].
+		foundStartRoot := false
 		for i := endRootIndex - 1; i >= 0; i-- {
 			changes, _ := th.history.Index(i)
 
@@ -144,15 +145,15 @@ func (th *trieHistory) getValueChanges(
 				// [startRootChanges] is now the last change resulting in
 				// [startRoot] before [endRootChanges].
 				startRootChanges = changes
+				foundStartRoot = true
 				break
 			}
-
-			if i == 0 {
-				return nil, fmt.Errorf(
-					"%w: start root %s not found before end root %s",
-					ErrInsufficientHistory, startRoot, endRoot,
-				)
-			}
+		}
+		if !foundStartRoot {
+			return nil, fmt.Errorf(
+				"%w: start root %s not found before end root %s",
+				ErrInsufficientHistory, startRoot, endRoot,
+			)
 		}
 	}
 
@@ -168,7 +169,7 @@ func (th *trieHistory) getValueChanges(
 		// last appearance (exclusive) and [endRoot]'s last appearance (inclusive),
 		// add the changes to keys in [start, end] to [combinedChanges].
 		// Only the key-value pairs with the greatest [maxLength] keys will be kept.
-		combinedChanges = newChangeSummary(maxLength)
+		combinedChanges = newChangeSummary(min(maxLength, defaultPreallocationSize))
 
 		// The difference between the index of [startRootChanges] and [endRootChanges] in [th.history].
 		startToEndOffset = int(endRootChanges.insertNumber - startRootChanges.insertNumber)
@@ -184,36 +185,62 @@ func (th *trieHistory) getValueChanges(
 		changes, _ := th.history.Index(i)
 
 		// Add the changes from this commit to [combinedChanges].
-		for key, valueChange := range changes.values {
-			// The key is outside the range [start, end].
-			if (startKey.HasValue() && key.Less(startKey.Value())) ||
-				(end.HasValue() && key.Greater(endKey.Value())) {
-				continue
-			}
+		combineValueChanges(combinedChanges, changedKeys, changes.values, startKey, endKey)
+	}
 
-			// A change to this key already exists in [combinedChanges]
-			// so update its before value with the earlier before value
-			if existing, ok := combinedChanges.values[key]; ok {
-				existing.after = valueChange.after
-				if existing.before.HasValue() == existing.after.HasValue() &&
-					bytes.Equal(existing.before.Value(), existing.after.Value()) {
-					// The change to this key is a no-op, so remove it from [combinedChanges].
-					delete(combinedChanges.values, key)
-					changedKeys.Remove(key)
-				}
-			} else {
-				combinedChanges.values[key] = &change[maybe.Maybe[[]byte]]{
-					before: valueChange.before,
-					after:  valueChange.after,
-				}
-				changedKeys.Add(key)
+	truncateValueChanges(combinedChanges, changedKeys, maxLength)
+	return combinedChanges, nil
+}
+
+// combineValueChanges adds the changes in [values] to keys in
+// [startKey, endKey] to [combinedChanges], which contains the changes to
+// [changedKeys] that were made before [values].
+// If [startKey] is Nothing, there's no lower bound on the range.
+// If [endKey] is Nothing, there's no upper bound on the range.
+func combineValueChanges(
+	combinedChanges *changeSummary,
+	changedKeys set.Set[Key],
+	values map[Key]*change[maybe.Maybe[[]byte]],
+	startKey maybe.Maybe[Key],
+	endKey maybe.Maybe[Key],
+) {
+	for key, valueChange := range values {
+		// The key is outside the range [start, end].
+		if (startKey.HasValue() && key.Less(startKey.Value())) ||
+			(endKey.HasValue() && key.Greater(endKey.Value())) {
+			continue
+		}
+
+		// A change to this key already exists in [combinedChanges]
+		// so update its before value with the earlier before value
+		if existing, ok := combinedChanges.values[key]; ok {
+			existing.after = valueChange.after
+			if existing.before.HasValue() == existing.after.HasValue() &&
+				bytes.Equal(existing.before.Value(), existing.after.Value()) {
+				// The change to this key is a no-op, so remove it from [combinedChanges].
+				delete(combinedChanges.values, key)
+				changedKeys.Remove(key)
 			}
+		} else {
+			combinedChanges.values[key] = &change[maybe.Maybe[[]byte]]{
+				before: valueChange.before,
+				after:  valueChange.after,
+			}
+			changedKeys.Add(key)
 		}
 	}
+}
 
+// truncateValueChanges removes all but the changes to the smallest
+// [maxLength] keys from [combinedChanges].
+func truncateValueChanges(
+	combinedChanges *changeSummary,
+	changedKeys set.Set[Key],
+	maxLength int,
+) {
 	// If we have <= [maxLength] elements, we're done.
 	if changedKeys.Len() <= maxLength {
-		return combinedChanges, nil
+		return
 	}
 
 	// Keep only the smallest [maxLength] items in [combinedChanges.values].
@@ -224,8 +251,6 @@ func (th *trieHistory) getValueChanges(
 		sortedChangedKeys = sortedChangedKeys[:len(sortedChangedKeys)-1]
 		delete(combinedChanges.values, greatestKey)
 	}
-
-	return combinedChanges, nil
 }
 
 // Returns the changes to go from the current trie state back to the requested [rootID]
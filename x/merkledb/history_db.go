@@ -0,0 +1,512 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/maybe"
+	"github.com/skychains/chain/utils/set"
+)
+
+const (
+	historyChangePrefixByte = 0
+	historyRootPrefixByte   = 1
+	historyMetadataByte     = 2
+
+	insertNumberLen = 8
+)
+
+var (
+	historyMetadataKey = []byte{historyPrefix[0], historyMetadataByte}
+
+	errUnexpectedHistoricalRoot = errors.New("unexpected historical root")
+)
+
+// historyDB stores the value changes made by each commit on disk so that
+// change proofs and historical views can be created for roots that are no
+// longer in the in-memory [trieHistory], including after a restart.
+//
+// Unlike [trieHistory], node changes aren't stored. A historical view is
+// created by reverting the value changes made since the requested root and
+// recalculating the affected nodes.
+//
+// Each change is tagged with a monotonically increasing insert number, which
+// is used as its key on disk. Changes are stored as:
+//
+//	historyPrefix + historyChangePrefixByte + insertNumber -> encoded change
+//	historyPrefix + historyRootPrefixByte + rootID -> insertNumber
+//
+// where the second mapping records the most recent change resulting in
+// rootID.
+type historyDB struct {
+	baseDB database.Database
+
+	// Maximum number of changes to store. If 0, the number of changes isn't
+	// limited.
+	maxLength uint64
+	// Maximum number of bytes of encoded changes to store. If 0, the size of
+	// the changes isn't limited.
+	maxSize uint64
+
+	// Only updated once the changes it describes have been written.
+	historyMetadata
+}
+
+// historyMetadata describes the stored changes.
+type historyMetadata struct {
+	// The changes in [oldestInsertNumber, nextInsertNumber) are stored.
+	oldestInsertNumber uint64
+	nextInsertNumber   uint64
+	// The number of bytes of the stored encoded changes.
+	size uint64
+}
+
+// newHistoryDB loads the history stored in [baseDB]. If the most recent stored
+// change doesn't result in [rootID], which is the current root of the trie,
+// the stored history is discarded because it can't be used to revert the
+// trie.
+func newHistoryDB(
+	baseDB database.Database,
+	maxLength uint64,
+	maxSize uint64,
+	rootID ids.ID,
+) (*historyDB, error) {
+	h := &historyDB{
+		baseDB:    baseDB,
+		maxLength: maxLength,
+		maxSize:   maxSize,
+	}
+	metadataBytes, err := baseDB.Get(historyMetadataKey)
+	switch err {
+	case nil:
+		if err := h.historyMetadata.parse(metadataBytes); err != nil {
+			return nil, err
+		}
+	case database.ErrNotFound:
+	default:
+		return nil, err
+	}
+
+	if h.len() != 0 {
+		lastRootID, err := h.getRootID(h.nextInsertNumber - 1)
+		if err != nil {
+			return nil, err
+		}
+		if lastRootID == rootID {
+			return h, nil
+		}
+		if err := h.clear(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record the current root so that changes can be generated from it.
+	batch := baseDB.NewBatch()
+	metadata, err := h.record(batch, &changeSummary{rootID: rootID})
+	if err != nil {
+		return nil, err
+	}
+	if err := batch.Write(); err != nil {
+		return nil, err
+	}
+	h.historyMetadata = metadata
+	return h, nil
+}
+
+// len returns the number of stored changes.
+func (m *historyMetadata) len() uint64 {
+	return m.nextInsertNumber - m.oldestInsertNumber
+}
+
+// record writes [changes] into [batch] and removes the oldest changes that
+// exceed the configured limits. The most recent change is always kept.
+//
+// The returned metadata describes the stored changes once [batch] is written.
+// It must only be assigned to [h.historyMetadata] after [batch] is written, so
+// that [h] is unchanged if [batch] isn't written.
+func (h *historyDB) record(batch database.KeyValueWriterDeleter, changes *changeSummary) (historyMetadata, error) {
+	metadata := h.historyMetadata
+	insertNumber := metadata.nextInsertNumber
+	changeBytes := encodeHistoricalChange(changes)
+	if err := batch.Put(historyChangeKey(insertNumber), changeBytes); err != nil {
+		return historyMetadata{}, err
+	}
+	if err := batch.Put(historyRootKey(changes.rootID), database.PackUInt64(insertNumber)); err != nil {
+		return historyMetadata{}, err
+	}
+	metadata.nextInsertNumber++
+	metadata.size += uint64(len(changeBytes))
+
+	for metadata.len() > 1 &&
+		((h.maxLength != 0 && metadata.len() > h.maxLength) ||
+			(h.maxSize != 0 && metadata.size > h.maxSize)) {
+		if err := h.removeOldest(batch, &metadata, changes.rootID); err != nil {
+			return historyMetadata{}, err
+		}
+	}
+	return metadata, batch.Put(historyMetadataKey, metadata.bytes())
+}
+
+// removeOldest writes the removal of the oldest stored change in [metadata]
+// into [batch] and updates [metadata] accordingly.
+// [newRootID] is the root resulting from the change being recorded in
+// [batch], whose root mapping must not be removed.
+func (h *historyDB) removeOldest(batch database.KeyValueWriterDeleter, metadata *historyMetadata, newRootID ids.ID) error {
+	insertNumber := metadata.oldestInsertNumber
+	changeKey := historyChangeKey(insertNumber)
+	changeBytes, err := h.baseDB.Get(changeKey)
+	if err != nil {
+		return err
+	}
+	if err := batch.Delete(changeKey); err != nil {
+		return err
+	}
+	metadata.oldestInsertNumber++
+	metadata.size -= uint64(len(changeBytes))
+
+	rootID, err := parseHistoricalRootID(changeBytes)
+	if err != nil {
+		return err
+	}
+	if rootID == newRootID {
+		return nil
+	}
+	lastInsertNumber, err := h.getInsertNumber(rootID)
+	if err != nil {
+		return err
+	}
+	if lastInsertNumber != insertNumber {
+		// A more recent change also resulted in [rootID].
+		return nil
+	}
+	return batch.Delete(historyRootKey(rootID))
+}
+
+// getValueChanges returns up to [maxLength] key-value pair changes with keys
+// in [start, end] that occurred between [startRoot] and [endRoot].
+// See [trieHistory.getValueChanges].
+func (h *historyDB) getValueChanges(
+	startRoot ids.ID,
+	endRoot ids.ID,
+	start maybe.Maybe[[]byte],
+	end maybe.Maybe[[]byte],
+	maxLength int,
+) (*changeSummary, error) {
+	if maxLength <= 0 {
+		return nil, fmt.Errorf("%w but was %d", ErrInvalidMaxLength, maxLength)
+	}
+
+	if startRoot == endRoot {
+		return newChangeSummary(min(maxLength, defaultPreallocationSize)), nil
+	}
+
+	endInsertNumber, err := h.getInsertNumber(endRoot)
+	if err == database.ErrNotFound {
+		return nil, fmt.Errorf("%w: %s", ErrNoEndRoot, endRoot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	startInsertNumber, err := h.getInsertNumber(startRoot)
+	if err == database.ErrNotFound {
+		return nil, fmt.Errorf("%w: start root %s not found", ErrInsufficientHistory, startRoot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if startInsertNumber > endInsertNumber {
+		// The most recent change resulting in [startRoot] happened after the
+		// most recent change resulting in [endRoot]. Attempt to find a change
+		// resulting in [startRoot] before it.
+		startInsertNumber, err = h.findRootBefore(startRoot, endInsertNumber)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		changedKeys     = set.Set[Key]{}
+		startKey        = maybe.Bind(start, ToKey)
+		endKey          = maybe.Bind(end, ToKey)
+		combinedChanges = newChangeSummary(min(maxLength, defaultPreallocationSize))
+	)
+	for insertNumber := startInsertNumber + 1; insertNumber <= endInsertNumber; insertNumber++ {
+		changes, err := h.getChange(insertNumber)
+		if err != nil {
+			return nil, err
+		}
+		combineValueChanges(combinedChanges, changedKeys, changes.values, startKey, endKey)
+	}
+
+	truncateValueChanges(combinedChanges, changedKeys, maxLength)
+	return combinedChanges, nil
+}
+
+// findRootBefore returns the insert number of the most recent change resulting
+// in [rootID] before the change with [insertNumber].
+func (h *historyDB) findRootBefore(rootID ids.ID, insertNumber uint64) (uint64, error) {
+	for i := insertNumber; i > h.oldestInsertNumber; i-- {
+		changeRootID, err := h.getRootID(i - 1)
+		if err != nil {
+			return 0, err
+		}
+		if changeRootID == rootID {
+			return i - 1, nil
+		}
+	}
+	return 0, fmt.Errorf(
+		"%w: start root %s not found before insert number %d",
+		ErrInsufficientHistory, rootID, insertNumber,
+	)
+}
+
+// getValuesAtRoot returns the values, at [rootID], of every key that was
+// changed after the most recent change resulting in [rootID].
+// Returns [ErrInsufficientHistory] if [rootID] isn't in the history.
+func (h *historyDB) getValuesAtRoot(rootID ids.ID) (map[Key]maybe.Maybe[[]byte], error) {
+	rootInsertNumber, err := h.getInsertNumber(rootID)
+	if err == database.ErrNotFound {
+		return nil, fmt.Errorf("%w: root %s not found", ErrInsufficientHistory, rootID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Iterate over the changes from the most recent to the oldest, so that
+	// the last before value of each key is its value at [rootID].
+	values := make(map[Key]maybe.Maybe[[]byte])
+	for insertNumber := h.nextInsertNumber - 1; insertNumber > rootInsertNumber; insertNumber-- {
+		changes, err := h.getChange(insertNumber)
+		if err != nil {
+			return nil, err
+		}
+		for key, valueChange := range changes.values {
+			values[key] = valueChange.before
+		}
+	}
+	return values, nil
+}
+
+// getValueAtRoot returns the value of [key] at [rootID] and true if it was
+// changed after the most recent change resulting in [rootID]. Otherwise,
+// returns false.
+// Returns [ErrInsufficientHistory] if [rootID] isn't in the history.
+func (h *historyDB) getValueAtRoot(rootID ids.ID, key Key) (maybe.Maybe[[]byte], bool, error) {
+	rootInsertNumber, err := h.getInsertNumber(rootID)
+	if err == database.ErrNotFound {
+		return maybe.Nothing[[]byte](), false, fmt.Errorf("%w: root %s not found", ErrInsufficientHistory, rootID)
+	}
+	if err != nil {
+		return maybe.Nothing[[]byte](), false, err
+	}
+
+	// The earliest change to [key] after [rootID] has its value at [rootID] as
+	// its before value.
+	for insertNumber := rootInsertNumber + 1; insertNumber < h.nextInsertNumber; insertNumber++ {
+		changes, err := h.getChange(insertNumber)
+		if err != nil {
+			return maybe.Nothing[[]byte](), false, err
+		}
+		if valueChange, ok := changes.values[key]; ok {
+			return valueChange.before, true, nil
+		}
+	}
+	return maybe.Nothing[[]byte](), false, nil
+}
+
+// getChange returns the change with [insertNumber].
+func (h *historyDB) getChange(insertNumber uint64) (*changeSummary, error) {
+	changeBytes, err := h.baseDB.Get(historyChangeKey(insertNumber))
+	if err != nil {
+		return nil, err
+	}
+	return parseHistoricalChange(changeBytes)
+}
+
+// getRootID returns the root resulting from the change with [insertNumber].
+func (h *historyDB) getRootID(insertNumber uint64) (ids.ID, error) {
+	changeBytes, err := h.baseDB.Get(historyChangeKey(insertNumber))
+	if err != nil {
+		return ids.Empty, err
+	}
+	return parseHistoricalRootID(changeBytes)
+}
+
+// getInsertNumber returns the insert number of the most recent change
+// resulting in [rootID].
+// Returns database.ErrNotFound if no stored change resulted in [rootID].
+func (h *historyDB) getInsertNumber(rootID ids.ID) (uint64, error) {
+	return database.GetUInt64(h.baseDB, historyRootKey(rootID))
+}
+
+// clear removes all the stored changes.
+func (h *historyDB) clear() error {
+	if err := database.ClearPrefix(h.baseDB, historyPrefix, clearBatchSize); err != nil {
+		return err
+	}
+	h.historyMetadata = historyMetadata{}
+	return nil
+}
+
+func (m *historyMetadata) bytes() []byte {
+	w := codecWriter{
+		b: make([]byte, 0, 3*binary.MaxVarintLen64),
+	}
+	w.Uvarint(m.oldestInsertNumber)
+	w.Uvarint(m.nextInsertNumber)
+	w.Uvarint(m.size)
+	return w.b
+}
+
+func (m *historyMetadata) parse(b []byte) error {
+	r := codecReader{
+		b: b,
+	}
+	var err error
+	if m.oldestInsertNumber, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if m.nextInsertNumber, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if m.size, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if len(r.b) != 0 {
+		return errExtraSpace
+	}
+	return nil
+}
+
+func historyChangeKey(insertNumber uint64) []byte {
+	key := make([]byte, 0, len(historyPrefix)+1+insertNumberLen)
+	key = append(key, historyPrefix...)
+	key = append(key, historyChangePrefixByte)
+	return binary.BigEndian.AppendUint64(key, insertNumber)
+}
+
+func historyRootKey(rootID ids.ID) []byte {
+	key := make([]byte, 0, len(historyPrefix)+1+ids.IDLen)
+	key = append(key, historyPrefix...)
+	key = append(key, historyRootPrefixByte)
+	return append(key, rootID[:]...)
+}
+
+// encodeHistoricalChange encodes the root and value changes of [changes]. The
+// root is encoded first so that it can be parsed without parsing the values.
+func encodeHistoricalChange(changes *changeSummary) []byte {
+	keys := maps.Keys(changes.values)
+	utils.Sort(keys)
+
+	w := codecWriter{
+		b: make([]byte, 0, ids.IDLen+binary.MaxVarintLen64),
+	}
+	w.ID(changes.rootID)
+	w.Uvarint(uint64(len(keys)))
+	for _, key := range keys {
+		valueChange := changes.values[key]
+		w.Key(key)
+		w.MaybeBytes(valueChange.before)
+		w.MaybeBytes(valueChange.after)
+	}
+	return w.b
+}
+
+func parseHistoricalChange(b []byte) (*changeSummary, error) {
+	r := codecReader{
+		b:    b,
+		copy: true,
+	}
+	rootID, err := r.ID()
+	if err != nil {
+		return nil, err
+	}
+	numValues, err := r.Uvarint()
+	if err != nil {
+		return nil, err
+	}
+	// Each value change is encoded in at least one byte.
+	if numValues > uint64(len(r.b)) {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	changes := newChangeSummary(int(numValues))
+	changes.rootID = rootID
+	for i := uint64(0); i < numValues; i++ {
+		key, err := r.Key()
+		if err != nil {
+			return nil, err
+		}
+		before, err := r.MaybeBytes()
+		if err != nil {
+			return nil, err
+		}
+		after, err := r.MaybeBytes()
+		if err != nil {
+			return nil, err
+		}
+		changes.values[key] = &change[maybe.Maybe[[]byte]]{
+			before: before,
+			after:  after,
+		}
+	}
+	if len(r.b) != 0 {
+		return nil, errExtraSpace
+	}
+	return changes, nil
+}
+
+func parseHistoricalRootID(b []byte) (ids.ID, error) {
+	r := codecReader{
+		b: b,
+	}
+	return r.ID()
+}
+
+// newHistoricalView returns a view of [db] at [rootID] created by reverting
+// [values], the values at [rootID] of every key changed since.
+//
+// Assumes [db.commitLock] is read locked.
+func newHistoricalView(
+	ctx context.Context,
+	db *merkleDB,
+	rootID ids.ID,
+	values map[Key]maybe.Maybe[[]byte],
+) (*view, error) {
+	ops := make([]database.BatchOp, 0, len(values))
+	for key, value := range values {
+		ops = append(ops, database.BatchOp{
+			Key:    key.Bytes(),
+			Value:  value.Value(),
+			Delete: value.IsNothing(),
+		})
+	}
+	v, err := newView(db, db, ViewChanges{
+		BatchOps:     ops,
+		ConsumeBytes: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	calculatedRootID, err := v.GetMerkleRoot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if calculatedRootID != rootID {
+		return nil, fmt.Errorf("%w: reverted to %s rather than %s", errUnexpectedHistoricalRoot, calculatedRootID, rootID)
+	}
+	return v, nil
+}
@@ -0,0 +1,335 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+)
+
+func newPersistentHistoryConfig(length uint, size uint) Config {
+	config := newDefaultConfig()
+	config.HistoryLength = 0
+	config.PersistentHistoryLength = length
+	config.PersistentHistorySize = size
+	return config
+}
+
+// writeRandomChanges commits [numCommits] random changes to [db] and returns
+// the root after each commit.
+func writeRandomChanges(t *testing.T, r *rand.Rand, db *merkleDB, numCommits int) []ids.ID {
+	require := require.New(t)
+
+	roots := make([]ids.ID, 0, numCommits)
+	for i := 0; i < numCommits; i++ {
+		batch := db.NewBatch()
+		for j := 0; j < 10; j++ {
+			key := []byte(strconv.Itoa(r.Intn(50)))
+			if r.Intn(4) == 0 {
+				require.NoError(batch.Delete(key))
+				continue
+			}
+			value := make([]byte, r.Intn(32))
+			_, _ = r.Read(value)
+			require.NoError(batch.Put(key, value))
+		}
+		require.NoError(batch.Write())
+
+		root, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+		roots = append(roots, root)
+	}
+	return roots
+}
+
+func Test_HistoryDB_Restart(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		ctx    = context.Background()
+		r      = rand.New(rand.NewSource(0)) // #nosec G404
+		baseDB = memdb.New()
+	)
+	db, err := newDB(ctx, baseDB, newPersistentHistoryConfig(100, 0))
+	require.NoError(err)
+
+	roots := writeRandomChanges(t, r, db, 20)
+	require.NoError(db.Close())
+
+	config := newPersistentHistoryConfig(100, 0)
+	config.Reg = prometheus.NewRegistry()
+	db, err = newDB(ctx, baseDB, config)
+	require.NoError(err)
+
+	for i, root := range roots[:len(roots)-1] {
+		rangeProof, err := db.GetRangeProofAtRoot(ctx, root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+		require.NoError(err)
+		require.NoError(rangeProof.Verify(ctx, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), root, db.tokenSize, db.hasher))
+
+		endRoot := roots[len(roots)-1]
+		changeProof, err := db.GetChangeProof(ctx, root, endRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+		require.NoError(err)
+
+		// Applying the change proof to the trie at [root] must result in
+		// [endRoot].
+		historicalDB, err := newDB(ctx, memdb.New(), newDefaultConfig())
+		require.NoError(err)
+		for _, kv := range rangeProof.KeyValues {
+			require.NoError(historicalDB.Put(kv.Key, kv.Value))
+		}
+		require.NoError(historicalDB.VerifyChangeProof(ctx, changeProof, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), endRoot), i)
+	}
+}
+
+func Test_HistoryDB_GetValueAtRoot(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		ctx    = context.Background()
+		r      = rand.New(rand.NewSource(0)) // #nosec G404
+		baseDB = memdb.New()
+	)
+	db, err := newDB(ctx, baseDB, newPersistentHistoryConfig(100, 0))
+	require.NoError(err)
+
+	var (
+		roots  []ids.ID
+		values []map[string][]byte
+	)
+	for i := 0; i < 10; i++ {
+		roots = append(roots, writeRandomChanges(t, r, db, 1)...)
+
+		rootValues := make(map[string][]byte)
+		it := db.NewIterator()
+		for it.Next() {
+			rootValues[string(it.Key())] = it.Value()
+		}
+		require.NoError(it.Error())
+		it.Release()
+		values = append(values, rootValues)
+	}
+	require.NoError(db.Close())
+
+	config := newPersistentHistoryConfig(100, 0)
+	config.Reg = prometheus.NewRegistry()
+	db, err = newDB(ctx, baseDB, config)
+	require.NoError(err)
+
+	for i, root := range roots {
+		for j := 0; j < 50; j++ {
+			key := []byte(strconv.Itoa(j))
+			expectedValue, expectedOk := values[i][string(key)]
+
+			value, err := db.GetValueAtRoot(ctx, root, key)
+			if expectedOk {
+				require.NoError(err)
+				require.Equal(expectedValue, value)
+			} else {
+				require.ErrorIs(err, database.ErrNotFound)
+			}
+
+			proof, err := db.GetProofAtRoot(ctx, root, key)
+			require.NoError(err)
+			require.Equal(expectedOk, proof.Value.HasValue())
+			require.NoError(proof.Verify(ctx, root, db.tokenSize, db.hasher))
+		}
+	}
+
+	_, err = db.GetValueAtRoot(ctx, ids.GenerateTestID(), []byte("key"))
+	require.ErrorIs(err, ErrRootNotInHistory)
+}
+
+func Test_HistoryDB_Retention(t *testing.T) {
+	tests := []struct {
+		name        string
+		length      uint
+		size        uint
+		numCommits  int
+		expectedLen uint64
+	}{
+		{
+			name:        "length",
+			length:      5,
+			numCommits:  20,
+			expectedLen: 5,
+		},
+		{
+			name:        "size",
+			size:        1,
+			numCommits:  20,
+			expectedLen: 1,
+		},
+		{
+			name:        "unlimited length",
+			size:        1_000_000,
+			numCommits:  20,
+			expectedLen: 21,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			var (
+				ctx    = context.Background()
+				r      = rand.New(rand.NewSource(0)) // #nosec G404
+				baseDB = memdb.New()
+			)
+			db, err := newDB(ctx, baseDB, newPersistentHistoryConfig(test.length, test.size))
+			require.NoError(err)
+
+			roots := writeRandomChanges(t, r, db, test.numCommits)
+			require.Equal(test.expectedLen, db.historyDB.len())
+
+			for i, root := range roots[:len(roots)-1] {
+				_, err := db.GetRangeProofAtRoot(ctx, root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+				if uint64(len(roots)-i) > test.expectedLen {
+					require.ErrorIs(err, ErrInsufficientHistory)
+				} else {
+					require.NoError(err)
+				}
+			}
+
+			// Only the stored changes may be left on disk.
+			it := baseDB.NewIteratorWithPrefix(append(historyPrefix, historyChangePrefixByte))
+			defer it.Release()
+
+			numChanges := uint64(0)
+			for it.Next() {
+				numChanges++
+			}
+			require.NoError(it.Error())
+			require.Equal(test.expectedLen, numChanges)
+		})
+	}
+}
+
+func Test_HistoryDB_Discarded(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		ctx    = context.Background()
+		r      = rand.New(rand.NewSource(0)) // #nosec G404
+		baseDB = memdb.New()
+	)
+	db, err := newDB(ctx, baseDB, newPersistentHistoryConfig(100, 0))
+	require.NoError(err)
+	roots := writeRandomChanges(t, r, db, 5)
+	require.NoError(db.Close())
+
+	// Changes made while the history isn't stored can't be reverted, so the
+	// stored history must be discarded.
+	config := newDefaultConfig()
+	db, err = newDB(ctx, baseDB, config)
+	require.NoError(err)
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Close())
+
+	config = newPersistentHistoryConfig(100, 0)
+	config.Reg = prometheus.NewRegistry()
+	db, err = newDB(ctx, baseDB, config)
+	require.NoError(err)
+	require.Equal(uint64(1), db.historyDB.len())
+
+	_, err = db.GetRangeProofAtRoot(ctx, roots[0], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.ErrorIs(err, ErrInsufficientHistory)
+}
+
+func Test_HistoryDB_Clear(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		ctx    = context.Background()
+		r      = rand.New(rand.NewSource(0)) // #nosec G404
+		baseDB = memdb.New()
+	)
+	db, err := newDB(ctx, baseDB, newPersistentHistoryConfig(100, 0))
+	require.NoError(err)
+	roots := writeRandomChanges(t, r, db, 5)
+
+	require.NoError(db.Clear())
+	require.Equal(uint64(1), db.historyDB.len())
+
+	_, err = db.GetRangeProofAtRoot(ctx, roots[0], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.ErrorIs(err, ErrInsufficientHistory)
+
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	root, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	changeProof, err := db.GetChangeProof(ctx, ids.Empty, root, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.NoError(err)
+	require.Equal([]KeyChange{
+		{
+			Key:   []byte("key"),
+			Value: maybe.Some([]byte("value")),
+		},
+	}, changeProof.KeyChanges)
+}
+
+func Test_HistoryDB_UnwrittenRecord(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		ctx    = context.Background()
+		r      = rand.New(rand.NewSource(0)) // #nosec G404
+		baseDB = memdb.New()
+	)
+	db, err := newDB(ctx, baseDB, newPersistentHistoryConfig(3, 0))
+	require.NoError(err)
+	roots := writeRandomChanges(t, r, db, 3)
+
+	// Recording a change doesn't modify the history until its batch is
+	// written.
+	metadata := db.historyDB.historyMetadata
+	_, err = db.historyDB.record(baseDB.NewBatch(), &changeSummary{rootID: ids.GenerateTestID()})
+	require.NoError(err)
+	require.Equal(metadata, db.historyDB.historyMetadata)
+
+	roots = append(roots, writeRandomChanges(t, r, db, 1)...)
+	require.Equal(uint64(3), db.historyDB.len())
+
+	_, err = db.GetChangeProof(ctx, roots[1], roots[3], maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 100)
+	require.NoError(err)
+}
+
+func Test_HistoricalChange_Encoding(t *testing.T) {
+	require := require.New(t)
+
+	changes := newChangeSummary(2)
+	changes.rootID = ids.GenerateTestID()
+	changes.values[ToKey([]byte("added"))] = &change[maybe.Maybe[[]byte]]{
+		after: maybe.Some([]byte("value")),
+	}
+	changes.values[ToKey([]byte("removed"))] = &change[maybe.Maybe[[]byte]]{
+		before: maybe.Some([]byte{}),
+	}
+
+	changeBytes := encodeHistoricalChange(changes)
+	parsedChanges, err := parseHistoricalChange(changeBytes)
+	require.NoError(err)
+	require.Equal(changes.rootID, parsedChanges.rootID)
+	require.Equal(changes.values, parsedChanges.values)
+
+	rootID, err := parseHistoricalRootID(changeBytes)
+	require.NoError(err)
+	require.Equal(changes.rootID, rootID)
+
+	_, err = parseHistoricalChange(append(changeBytes, 0))
+	require.ErrorIs(err, errExtraSpace)
+
+	_, err = parseHistoricalChange(changeBytes[:len(changeBytes)-1])
+	require.ErrorIs(err, io.ErrUnexpectedEOF)
+}
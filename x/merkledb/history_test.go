@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/database/memdb"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils/maybe"
@@ -198,6 +199,11 @@ func Test_History_Bad_GetValueChanges_Input(t *testing.T) {
 	_, err = db.history.getValueChanges(root3, root2, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 1)
 	require.ErrorIs(err, ErrInsufficientHistory)
 
+	// root1 is the oldest root in the history, so there's nothing before it
+	// to search for root3 in.
+	_, err = db.history.getValueChanges(root3, root1, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), 1)
+	require.ErrorIs(err, ErrInsufficientHistory)
+
 	// Cause root1 to be removed from the history
 	batch = db.NewBatch()
 	require.NoError(batch.Put([]byte("key2"), []byte("value4")))
@@ -513,6 +519,63 @@ func Test_History_MaxLength(t *testing.T) {
 	require.NotContains(db.history.lastChanges, oldRoot)
 }
 
+func Test_History_GetValueAtRoot(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	config := newDefaultConfig()
+	config.HistoryLength = 2
+	db, err := newDB(ctx, memdb.New(), config)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	evictedRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	require.NoError(db.Put([]byte("key"), []byte("updated")))
+	require.NoError(db.Put([]byte("other"), []byte("value")))
+	oldRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	require.NoError(db.Delete([]byte("key")))
+	currentRoot, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	value, err := db.GetValueAtRoot(ctx, oldRoot, []byte("key"))
+	require.NoError(err)
+	require.Equal([]byte("updated"), value)
+
+	// Keys that weren't changed since [oldRoot] are read from the trie.
+	value, err = db.GetValueAtRoot(ctx, oldRoot, []byte("other"))
+	require.NoError(err)
+	require.Equal([]byte("value"), value)
+
+	_, err = db.GetValueAtRoot(ctx, oldRoot, []byte("missing"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	_, err = db.GetValueAtRoot(ctx, currentRoot, []byte("key"))
+	require.ErrorIs(err, database.ErrNotFound)
+
+	proof, err := db.GetProofAtRoot(ctx, oldRoot, []byte("key"))
+	require.NoError(err)
+	require.Equal(maybe.Some([]byte("updated")), proof.Value)
+	require.NoError(proof.Verify(ctx, oldRoot, db.tokenSize, db.hasher))
+
+	proof, err = db.GetProofAtRoot(ctx, currentRoot, []byte("key"))
+	require.NoError(err)
+	require.True(proof.Value.IsNothing())
+	require.NoError(proof.Verify(ctx, currentRoot, db.tokenSize, db.hasher))
+
+	_, err = db.GetValueAtRoot(ctx, evictedRoot, []byte("key"))
+	require.ErrorIs(err, ErrRootNotInHistory)
+
+	_, err = db.GetProofAtRoot(ctx, evictedRoot, []byte("key"))
+	require.ErrorIs(err, ErrRootNotInHistory)
+
+	_, err = db.GetValueAtRoot(ctx, ids.GenerateTestID(), []byte("key"))
+	require.ErrorIs(err, ErrRootNotInHistory)
+}
+
 func Test_Change_List(t *testing.T) {
 	require := require.New(t)
 
@@ -6,24 +6,23 @@ package merkledb
 import (
 	"github.com/skychains/chain/cache"
 	"github.com/skychains/chain/database"
-	"github.com/skychains/chain/utils"
 )
 
 // Holds intermediate nodes. That is, those without values.
-// Changes to this database aren't written to [baseDB] until
+// Changes to this database aren't written to [store] until
 // they're evicted from the [nodeCache] or Flush is called.
 type intermediateNodeDB struct {
-	bufferPool *utils.BytesPool
-
 	// The underlying storage.
-	// Keys written to [baseDB] are prefixed with [intermediateNodePrefix].
+	// Closed if writing to [store] fails.
 	baseDB database.Database
 
+	// Persists the nodes in [baseDB].
+	store nodeStore
+
 	// The write buffer contains nodes that have been changed but have not been written to disk.
 	// Note that a call to Put may cause a node to be evicted
 	// from the cache, which will call [OnEviction].
 	// A non-nil error returned from Put is considered fatal.
-	// Keys in [nodeCache] aren't prefixed with [intermediateNodePrefix].
 	writeBuffer onEvictCache[Key, *node]
 
 	// If a value is nil, the corresponding key isn't in the trie.
@@ -32,26 +31,23 @@ type intermediateNodeDB struct {
 	// the number of bytes to evict during an eviction batch
 	evictionBatchSize int
 	metrics           metrics
-	tokenSize         int
 	hasher            Hasher
 }
 
 func newIntermediateNodeDB(
 	db database.Database,
-	bufferPool *utils.BytesPool,
+	store nodeStore,
 	metrics metrics,
 	cacheSize int,
 	writeBufferSize int,
 	evictionBatchSize int,
-	tokenSize int,
 	hasher Hasher,
 ) *intermediateNodeDB {
 	result := &intermediateNodeDB{
 		metrics:           metrics,
 		baseDB:            db,
-		bufferPool:        bufferPool,
+		store:             store,
 		evictionBatchSize: evictionBatchSize,
-		tokenSize:         tokenSize,
 		hasher:            hasher,
 		nodeCache:         cache.NewSizedLRU(cacheSize, cacheEntrySize),
 	}
@@ -66,12 +62,11 @@ func newIntermediateNodeDB(
 
 // A non-nil error is considered fatal and closes [db.baseDB].
 func (db *intermediateNodeDB) onEviction(key Key, n *node) error {
-	writeBatch := db.baseDB.NewBatch()
-	totalSize := cacheEntrySize(key, n)
-	if err := db.addToBatch(writeBatch, key, n); err != nil {
-		_ = db.baseDB.Close()
-		return err
+	changes := map[Key]*node{
+		key: n,
 	}
+	totalSize := cacheEntrySize(key, n)
+	db.metrics.DatabaseNodeWrite()
 
 	// Evict the oldest [evictionBatchSize] nodes from the cache
 	// and write them to disk. We write a batch of them, rather than
@@ -85,29 +80,16 @@ func (db *intermediateNodeDB) onEviction(key Key, n *node) error {
 			break
 		}
 		totalSize += cacheEntrySize(key, n)
-		if err := db.addToBatch(writeBatch, key, n); err != nil {
-			_ = db.baseDB.Close()
-			return err
-		}
+		changes[key] = n
+		db.metrics.DatabaseNodeWrite()
 	}
-	if err := writeBatch.Write(); err != nil {
+	if err := db.store.Write(changes); err != nil {
 		_ = db.baseDB.Close()
 		return err
 	}
 	return nil
 }
 
-func (db *intermediateNodeDB) addToBatch(b database.KeyValueWriterDeleter, key Key, n *node) error {
-	dbKey := db.constructDBKey(key)
-	defer db.bufferPool.Put(dbKey)
-
-	db.metrics.DatabaseNodeWrite()
-	if n == nil {
-		return b.Delete(*dbKey)
-	}
-	return b.Put(*dbKey, n.bytes())
-}
-
 func (db *intermediateNodeDB) Get(key Key) (*node, error) {
 	if cachedValue, isCached := db.nodeCache.Get(key); isCached {
 		db.metrics.IntermediateNodeCacheHit()
@@ -125,11 +107,8 @@ func (db *intermediateNodeDB) Get(key Key) (*node, error) {
 	}
 	db.metrics.IntermediateNodeCacheMiss()
 
-	dbKey := db.constructDBKey(key)
-	defer db.bufferPool.Put(dbKey)
-
 	db.metrics.DatabaseNodeRead()
-	nodeBytes, err := db.baseDB.Get(*dbKey)
+	nodeBytes, err := db.store.Get(key)
 	if err != nil {
 		return nil, err
 	}
@@ -137,36 +116,6 @@ func (db *intermediateNodeDB) Get(key Key) (*node, error) {
 	return parseNode(db.hasher, key, nodeBytes)
 }
 
-// constructDBKey returns a key that can be used in [db.baseDB].
-// We need to be able to differentiate between two keys of equal
-// byte length but different bit length, so we add padding to differentiate.
-// Additionally, we add a prefix indicating it is part of the intermediateNodeDB.
-func (db *intermediateNodeDB) constructDBKey(key Key) *[]byte {
-	if db.tokenSize == 8 {
-		// For tokens of size byte, no padding is needed since byte
-		// length == token length
-		return addPrefixToKey(db.bufferPool, intermediateNodePrefix, key.Bytes())
-	}
-
-	var (
-		prefixLen              = len(intermediateNodePrefix)
-		prefixBitLen           = 8 * prefixLen
-		dualIndex              = dualBitIndex(db.tokenSize)
-		paddingByteValue  byte = 1 << dualIndex
-		paddingSliceValue      = []byte{paddingByteValue}
-		paddingKey             = Key{
-			value:  byteSliceToString(paddingSliceValue),
-			length: db.tokenSize,
-		}
-	)
-
-	bufferPtr := db.bufferPool.Get(bytesNeeded(prefixBitLen + key.length + db.tokenSize))
-	copy(*bufferPtr, intermediateNodePrefix)                          // add prefix
-	copy((*bufferPtr)[prefixLen:], key.Bytes())                       // add key
-	extendIntoBuffer(*bufferPtr, paddingKey, prefixBitLen+key.length) // add padding
-	return bufferPtr
-}
-
 func (db *intermediateNodeDB) Put(key Key, n *node) error {
 	db.nodeCache.Put(key, n)
 	return db.writeBuffer.Put(key, n)
@@ -192,5 +141,5 @@ func (db *intermediateNodeDB) Clear() error {
 		db.writeBuffer.size,
 		db.writeBuffer.onEviction,
 	)
-	return database.AtomicClearPrefix(db.baseDB, db.baseDB, intermediateNodePrefix)
+	return db.store.Clear()
 }
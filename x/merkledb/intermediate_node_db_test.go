@@ -4,7 +4,6 @@
 package merkledb
 
 import (
-	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -13,7 +12,6 @@ import (
 	"github.com/skychains/chain/database/memdb"
 	"github.com/skychains/chain/utils"
 	"github.com/skychains/chain/utils/maybe"
-	"github.com/skychains/chain/utils/units"
 )
 
 // Tests:
@@ -37,12 +35,11 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	baseDB := memdb.New()
 	db := newIntermediateNodeDB(
 		baseDB,
-		utils.NewBytesPool(),
+		newKeyNodeStore(baseDB, utils.NewBytesPool(), 4),
 		&mockMetrics{},
 		cacheSize,
 		bufferSize,
 		evictionBatchSize,
-		4,
 		DefaultHasher,
 	)
 
@@ -135,87 +132,6 @@ func Test_IntermediateNodeDB(t *testing.T) {
 	require.Equal(added+1, count)
 }
 
-func FuzzIntermediateNodeDBConstructDBKey(f *testing.F) {
-	bufferSize := 200
-	cacheSize := 200
-	evictionBatchSize := bufferSize
-	baseDB := memdb.New()
-
-	f.Fuzz(func(
-		t *testing.T,
-		key []byte,
-		tokenLength uint,
-	) {
-		require := require.New(t)
-		for _, tokenSize := range validTokenSizes {
-			db := newIntermediateNodeDB(
-				baseDB,
-				utils.NewBytesPool(),
-				&mockMetrics{},
-				cacheSize,
-				bufferSize,
-				evictionBatchSize,
-				tokenSize,
-				DefaultHasher,
-			)
-
-			p := ToKey(key)
-			uBitLength := tokenLength * uint(tokenSize)
-			if uBitLength >= uint(p.length) {
-				t.SkipNow()
-			}
-			p = p.Take(int(uBitLength))
-			constructedKey := db.constructDBKey(p)
-			baseLength := len(p.value) + len(intermediateNodePrefix)
-			require.Equal(intermediateNodePrefix, (*constructedKey)[:len(intermediateNodePrefix)])
-			switch {
-			case tokenSize == 8:
-				// for keys with tokens of size byte, no padding is added
-				require.Equal(p.Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
-			case p.hasPartialByte():
-				require.Len(*constructedKey, baseLength)
-				require.Equal(p.Extend(ToToken(1, tokenSize)).Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
-			default:
-				// when a whole number of bytes, there is an extra padding byte
-				require.Len(*constructedKey, baseLength+1)
-				require.Equal(p.Extend(ToToken(1, tokenSize)).Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
-			}
-		}
-	})
-}
-
-func Test_IntermediateNodeDB_ConstructDBKey_DirtyBuffer(t *testing.T) {
-	require := require.New(t)
-	cacheSize := 200
-	bufferSize := 200
-	evictionBatchSize := bufferSize
-	baseDB := memdb.New()
-	db := newIntermediateNodeDB(
-		baseDB,
-		utils.NewBytesPool(),
-		&mockMetrics{},
-		cacheSize,
-		bufferSize,
-		evictionBatchSize,
-		4,
-		DefaultHasher,
-	)
-
-	db.bufferPool.Put(&[]byte{0xFF, 0xFF, 0xFF})
-	constructedKey := db.constructDBKey(ToKey([]byte{}))
-	require.Len(*constructedKey, 2)
-	require.Equal(intermediateNodePrefix, (*constructedKey)[:len(intermediateNodePrefix)])
-	require.Equal(byte(16), (*constructedKey)[len(*constructedKey)-1])
-
-	db.bufferPool = utils.NewBytesPool()
-	db.bufferPool.Put(&[]byte{0xFF, 0xFF, 0xFF})
-	p := ToKey([]byte{0xF0}).Take(4)
-	constructedKey = db.constructDBKey(p)
-	require.Len(*constructedKey, 2)
-	require.Equal(intermediateNodePrefix, (*constructedKey)[:len(intermediateNodePrefix)])
-	require.Equal(p.Extend(ToToken(1, 4)).Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
-}
-
 func TestIntermediateNodeDBClear(t *testing.T) {
 	require := require.New(t)
 	cacheSize := 200
@@ -224,12 +140,11 @@ func TestIntermediateNodeDBClear(t *testing.T) {
 	baseDB := memdb.New()
 	db := newIntermediateNodeDB(
 		baseDB,
-		utils.NewBytesPool(),
+		newKeyNodeStore(baseDB, utils.NewBytesPool(), 4),
 		&mockMetrics{},
 		cacheSize,
 		bufferSize,
 		evictionBatchSize,
-		4,
 		DefaultHasher,
 	)
 
@@ -257,14 +172,14 @@ func TestIntermediateNodeDBDeleteEmptyKey(t *testing.T) {
 	bufferSize := 200
 	evictionBatchSize := bufferSize
 	baseDB := memdb.New()
+	store := newKeyNodeStore(baseDB, utils.NewBytesPool(), 4)
 	db := newIntermediateNodeDB(
 		baseDB,
-		utils.NewBytesPool(),
+		store,
 		&mockMetrics{},
 		cacheSize,
 		bufferSize,
 		evictionBatchSize,
-		4,
 		DefaultHasher,
 	)
 
@@ -272,7 +187,7 @@ func TestIntermediateNodeDBDeleteEmptyKey(t *testing.T) {
 	require.NoError(db.Put(emptyKey, newNode(emptyKey)))
 	require.NoError(db.Flush())
 
-	emptyDBKey := db.constructDBKey(emptyKey)
+	emptyDBKey := store.constructDBKey(emptyKey)
 	has, err := baseDB.Has(*emptyDBKey)
 	require.NoError(err)
 	require.True(has)
@@ -280,38 +195,8 @@ func TestIntermediateNodeDBDeleteEmptyKey(t *testing.T) {
 	require.NoError(db.Delete(ToKey([]byte{})))
 	require.NoError(db.Flush())
 
-	emptyDBKey = db.constructDBKey(emptyKey)
+	emptyDBKey = store.constructDBKey(emptyKey)
 	has, err = baseDB.Has(*emptyDBKey)
 	require.NoError(err)
 	require.False(has)
 }
-
-func Benchmark_IntermediateNodeDB_ConstructDBKey(b *testing.B) {
-	keyTokenSizes := []int{0, 1, 4, 16, 64, 256}
-	for _, tokenSize := range validTokenSizes {
-		db := newIntermediateNodeDB(
-			memdb.New(),
-			utils.NewBytesPool(),
-			&mockMetrics{},
-			units.MiB,
-			units.MiB,
-			units.MiB,
-			tokenSize,
-			DefaultHasher,
-		)
-
-		for _, keyTokenSize := range keyTokenSizes {
-			keyBitSize := keyTokenSize * tokenSize
-			keyBytes := make([]byte, bytesNeeded(keyBitSize))
-			key := Key{
-				length: keyBitSize,
-				value:  string(keyBytes),
-			}
-			b.Run(fmt.Sprintf("%d/%d", tokenSize, keyTokenSize), func(b *testing.B) {
-				for i := 0; i < b.N; i++ {
-					db.bufferPool.Put(db.constructDBKey(key))
-				}
-			})
-		}
-	}
-}
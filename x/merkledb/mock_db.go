@@ -3,7 +3,7 @@
 //
 // Generated by this command:
 //
-//	mockgen -source=x/merkledb/db.go -destination=x/merkledb/mock_db.go -package=merkledb -exclude_interfaces=ChangeProofer,RangeProofer,Clearer,Prefetcher
+//	mockgen -source=x/merkledb/db.go -destination=x/merkledb/mock_db.go -package=merkledb -exclude_interfaces=ChangeProofer,RangeProofer,Clearer,Prefetcher,HistoricalReader,Differ,Snapshotter
 //
 
 // Package merkledb is a generated GoMock package.
@@ -11,6 +11,7 @@ package merkledb
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 
 	database "github.com/skychains/chain/database"
@@ -126,6 +127,49 @@ func (mr *MockMerkleDBMockRecorder) Delete(key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockMerkleDB)(nil).Delete), key)
 }
 
+// DeleteRange mocks base method.
+func (m *MockMerkleDB) DeleteRange(start, limit []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRange", start, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRange indicates an expected call of DeleteRange.
+func (mr *MockMerkleDBMockRecorder) DeleteRange(start, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRange", reflect.TypeOf((*MockMerkleDB)(nil).DeleteRange), start, limit)
+}
+
+// Diff mocks base method.
+func (m *MockMerkleDB) Diff(ctx context.Context, fromRootID, toRootID ids.ID) (DiffIterator, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", ctx, fromRootID, toRootID)
+	ret0, _ := ret[0].(DiffIterator)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff.
+func (mr *MockMerkleDBMockRecorder) Diff(ctx, fromRootID, toRootID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockMerkleDB)(nil).Diff), ctx, fromRootID, toRootID)
+}
+
+// Export mocks base method.
+func (m *MockMerkleDB) Export(ctx context.Context, rootID ids.ID, w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, rootID, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockMerkleDBMockRecorder) Export(ctx, rootID, w any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockMerkleDB)(nil).Export), ctx, rootID, w)
+}
+
 // Get mocks base method.
 func (m *MockMerkleDB) Get(key []byte) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -171,6 +215,36 @@ func (mr *MockMerkleDBMockRecorder) GetMerkleRoot(ctx any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMerkleRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetMerkleRoot), ctx)
 }
 
+// GetMultiProof mocks base method.
+func (m *MockMerkleDB) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMultiProof", ctx, keys)
+	ret0, _ := ret[0].(*MultiProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMultiProof indicates an expected call of GetMultiProof.
+func (mr *MockMerkleDBMockRecorder) GetMultiProof(ctx, keys any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMultiProof", reflect.TypeOf((*MockMerkleDB)(nil).GetMultiProof), ctx, keys)
+}
+
+// GetPrefixProof mocks base method.
+func (m *MockMerkleDB) GetPrefixProof(ctx context.Context, prefix []byte) (*PrefixProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrefixProof", ctx, prefix)
+	ret0, _ := ret[0].(*PrefixProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrefixProof indicates an expected call of GetPrefixProof.
+func (mr *MockMerkleDBMockRecorder) GetPrefixProof(ctx, prefix any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrefixProof", reflect.TypeOf((*MockMerkleDB)(nil).GetPrefixProof), ctx, prefix)
+}
+
 // GetProof mocks base method.
 func (m *MockMerkleDB) GetProof(ctx context.Context, keyBytes []byte) (*Proof, error) {
 	m.ctrl.T.Helper()
@@ -186,6 +260,21 @@ func (mr *MockMerkleDBMockRecorder) GetProof(ctx, keyBytes any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProof", reflect.TypeOf((*MockMerkleDB)(nil).GetProof), ctx, keyBytes)
 }
 
+// GetProofAtRoot mocks base method.
+func (m *MockMerkleDB) GetProofAtRoot(ctx context.Context, rootID ids.ID, key []byte) (*Proof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProofAtRoot", ctx, rootID, key)
+	ret0, _ := ret[0].(*Proof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProofAtRoot indicates an expected call of GetProofAtRoot.
+func (mr *MockMerkleDBMockRecorder) GetProofAtRoot(ctx, rootID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProofAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetProofAtRoot), ctx, rootID, key)
+}
+
 // GetRangeProof mocks base method.
 func (m *MockMerkleDB) GetRangeProof(ctx context.Context, start, end maybe.Maybe[[]byte], maxLength int) (*RangeProof, error) {
 	m.ctrl.T.Helper()
@@ -231,6 +320,21 @@ func (mr *MockMerkleDBMockRecorder) GetValue(ctx, key any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValue", reflect.TypeOf((*MockMerkleDB)(nil).GetValue), ctx, key)
 }
 
+// GetValueAtRoot mocks base method.
+func (m *MockMerkleDB) GetValueAtRoot(ctx context.Context, rootID ids.ID, key []byte) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetValueAtRoot", ctx, rootID, key)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetValueAtRoot indicates an expected call of GetValueAtRoot.
+func (mr *MockMerkleDBMockRecorder) GetValueAtRoot(ctx, rootID, key any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetValueAtRoot", reflect.TypeOf((*MockMerkleDB)(nil).GetValueAtRoot), ctx, rootID, key)
+}
+
 // GetValues mocks base method.
 func (m *MockMerkleDB) GetValues(ctx context.Context, keys [][]byte) ([][]byte, []error) {
 	m.ctrl.T.Helper()
@@ -276,6 +380,20 @@ func (mr *MockMerkleDBMockRecorder) HealthCheck(arg0 any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HealthCheck", reflect.TypeOf((*MockMerkleDB)(nil).HealthCheck), arg0)
 }
 
+// Import mocks base method.
+func (m *MockMerkleDB) Import(ctx context.Context, r io.Reader, expectedRootID ids.ID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, r, expectedRootID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockMerkleDBMockRecorder) Import(ctx, r, expectedRootID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockMerkleDB)(nil).Import), ctx, r, expectedRootID)
+}
+
 // NewBatch mocks base method.
 func (m *MockMerkleDB) NewBatch() database.Batch {
 	m.ctrl.T.Helper()
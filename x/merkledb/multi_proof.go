@@ -0,0 +1,317 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+var (
+	ErrUnsortedProofNodes        = errors.New("proof nodes are not sorted by key or contain duplicates")
+	ErrProofNodeIndexOutOfBounds = errors.New("proof node index out of bounds")
+	ErrNilMultiProofKey          = errors.New("multi proof key is nil")
+	ErrProofPathNotFromRoot      = errors.New("proof path doesn't start at the root")
+	ErrProofNodeNotChild         = errors.New("proof node isn't the child of the previous node in the path")
+	ErrIncompleteProofPath       = errors.New("proof path ends before the position of the proven key")
+)
+
+// MultiProof is an inclusion/exclusion proof of many keys.
+// Proof nodes that are in the proof paths of multiple keys are only included
+// once, so a MultiProof is much smaller than a Proof for each key.
+type MultiProof struct {
+	// The nodes in the proof paths of all of [Keys].
+	// Sorted by increasing key, with no duplicates.
+	Nodes []ProofNode
+
+	// The proven keys.
+	// Sorted by increasing key, with no duplicates.
+	Keys []MultiProofKey
+}
+
+// MultiProofKey is the part of a MultiProof that proves a single key.
+type MultiProofKey struct {
+	// This is a proof that [Key] exists/doesn't exist.
+	Key Key
+
+	// Nothing if [Key] isn't in the trie.
+	// Otherwise, the value corresponding to [Key].
+	Value maybe.Maybe[[]byte]
+
+	// Indices into [MultiProof.Nodes] of the nodes in the proof path from
+	// root --> [Key] (or the node that would be where [Key] is if it doesn't
+	// exist).
+	Path []int
+}
+
+// getMultiProof returns a proof of the values of [keys] in [t].
+// [keys] may be unsorted and may contain duplicates.
+// Returns ErrEmptyProof if [t] is empty or [keys] is empty.
+// Assumes [t] doesn't change while this function is running.
+func getMultiProof(t Trie, keys [][]byte) (*MultiProof, error) {
+	if len(keys) == 0 {
+		return nil, ErrEmptyProof
+	}
+
+	keys = slices.Clone(keys)
+	slices.SortFunc(keys, bytes.Compare)
+	keys = slices.CompactFunc(keys, bytes.Equal)
+
+	var (
+		nodes = make(map[Key]ProofNode)
+		paths = make([][]Key, len(keys))
+		proof = &MultiProof{
+			Keys: make([]MultiProofKey, len(keys)),
+		}
+	)
+	for i, key := range keys {
+		keyProof, err := getProof(t, key)
+		if err != nil {
+			return nil, err
+		}
+
+		paths[i] = make([]Key, len(keyProof.Path))
+		for j, node := range keyProof.Path {
+			nodes[node.Key] = node
+			paths[i][j] = node.Key
+		}
+		proof.Keys[i] = MultiProofKey{
+			Key:   keyProof.Key,
+			Value: keyProof.Value,
+		}
+	}
+
+	proof.Nodes = make([]ProofNode, 0, len(nodes))
+	for _, node := range nodes {
+		proof.Nodes = append(proof.Nodes, node)
+	}
+	slices.SortFunc(proof.Nodes, func(a, b ProofNode) int {
+		return a.Key.Compare(b.Key)
+	})
+
+	nodeIndices := make(map[Key]int, len(proof.Nodes))
+	for i, node := range proof.Nodes {
+		nodeIndices[node.Key] = i
+	}
+	for i, path := range paths {
+		proof.Keys[i].Path = make([]int, len(path))
+		for j, key := range path {
+			proof.Keys[i].Path[j] = nodeIndices[key]
+		}
+	}
+	return proof, nil
+}
+
+// Verify returns nil if the trie given in [proof] has root [expectedRootID].
+// That is, this is a valid proof that each key in [proof.Keys] exists/doesn't
+// exist in the trie with root [expectedRootID].
+func (proof *MultiProof) Verify(
+	ctx context.Context,
+	expectedRootID ids.ID,
+	tokenSize int,
+	hasher Hasher,
+) error {
+	// Make sure the proof is well-formed.
+	if len(proof.Keys) == 0 {
+		return ErrEmptyProof
+	}
+
+	// Each node must be unique so that every path refers to the nodes that
+	// are hashed below.
+	for i := 1; i < len(proof.Nodes); i++ {
+		if proof.Nodes[i-1].Key.Compare(proof.Nodes[i].Key) >= 0 {
+			return ErrUnsortedProofNodes
+		}
+	}
+
+	for i, key := range proof.Keys {
+		if i > 0 && proof.Keys[i-1].Key.Compare(key.Key) >= 0 {
+			return ErrNonIncreasingValues
+		}
+
+		keyProof := Proof{
+			Path:  make([]ProofNode, len(key.Path)),
+			Key:   key.Key,
+			Value: key.Value,
+		}
+		for j, nodeIndex := range key.Path {
+			if nodeIndex < 0 || nodeIndex >= len(proof.Nodes) {
+				return fmt.Errorf("%w: %d", ErrProofNodeIndexOutOfBounds, nodeIndex)
+			}
+			keyProof.Path[j] = proof.Nodes[nodeIndex]
+		}
+		if err := keyProof.verifyPath(hasher); err != nil {
+			return err
+		}
+	}
+
+	// Don't bother locking [view] -- nobody else has a reference to it.
+	view, err := getStandaloneView(ctx, nil, tokenSize)
+	if err != nil {
+		return err
+	}
+
+	// Insert all proof nodes, descendants before their ancestors.
+	// Because the nodes are sorted, a node's descendants are after it.
+	for i := len(proof.Nodes) - 1; i >= 0; i-- {
+		proofNode := proof.Nodes[i]
+		if proofNode.Key.hasPartialByte() && !proofNode.ValueOrHash.IsNothing() {
+			return ErrPartialByteLengthWithValue
+		}
+
+		// Pass nothing because we are going to overwrite the value digest
+		// below.
+		n, err := view.insert(proofNode.Key, maybe.Nothing[[]byte]())
+		if err != nil {
+			return err
+		}
+		// We overwrite the valueDigest to be the hash provided in the proof
+		// node because we may not know the pre-image of the valueDigest.
+		n.valueDigest = proofNode.ValueOrHash
+
+		for index, childID := range proofNode.Children {
+			if _, ok := n.children[index]; ok {
+				// The child is a proof node, so its ID is calculated.
+				continue
+			}
+			// We only need the ID to be correct so that the calculated hash
+			// is correct.
+			n.setChildEntry(index, &child{
+				id: childID,
+			})
+		}
+	}
+
+	gotRootID, err := view.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if expectedRootID != gotRootID {
+		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, gotRootID, expectedRootID)
+	}
+
+	// The root matches, so [view] has the same structure as the proven trie.
+	// Each path must follow that structure, otherwise a path could prove the
+	// exclusion of a key using a node that isn't where the key would be.
+	for _, key := range proof.Keys {
+		if err := verifyMultiProofPath(view, proof.Nodes, key, tokenSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyMultiProofPath returns nil iff [key.Path] starts at the root of [view]
+// and follows the children of [view] towards [key.Key], ending at [key.Key] or
+// where [key.Key] would be if it isn't in the trie.
+// Assumes [key.Path] is non-empty and refers to nodes in [nodes].
+func verifyMultiProofPath(view *view, nodes []ProofNode, key MultiProofKey, tokenSize int) error {
+	n := view.root.Value()
+	if nodes[key.Path[0]].Key != n.key {
+		return ErrProofPathNotFromRoot
+	}
+
+	for _, nodeIndex := range key.Path[1:] {
+		if !key.Key.HasStrictPrefix(n.key) {
+			return ErrProofNodeNotForKey
+		}
+		token := key.Key.Token(n.key.length, tokenSize)
+		child, ok := n.children[token]
+		if !ok {
+			return ErrProofNodeNotChild
+		}
+		childKey := n.key.Extend(ToToken(token, tokenSize), child.compressedKey)
+		if nodes[nodeIndex].Key != childKey {
+			return ErrProofNodeNotChild
+		}
+
+		var err error
+		n, err = view.getNode(childKey, child.hasValue)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If the last node is a strict prefix of [key.Key], then [key.Key] can
+	// only be excluded if the last node has no child towards it.
+	if key.Key.HasStrictPrefix(n.key) {
+		if _, ok := n.children[key.Key.Token(n.key.length, tokenSize)]; ok {
+			return ErrIncompleteProofPath
+		}
+	}
+	return nil
+}
+
+func (proof *MultiProof) ToProto() *pb.MultiProof {
+	pbProof := &pb.MultiProof{
+		Nodes: make([]*pb.ProofNode, len(proof.Nodes)),
+		Keys:  make([]*pb.MultiProofKey, len(proof.Keys)),
+	}
+
+	for i, node := range proof.Nodes {
+		pbProof.Nodes[i] = node.ToProto()
+	}
+
+	for i, key := range proof.Keys {
+		pbKey := &pb.MultiProofKey{
+			Key: key.Key.Bytes(),
+			Value: &pb.MaybeBytes{
+				Value:     key.Value.Value(),
+				IsNothing: key.Value.IsNothing(),
+			},
+			Path: make([]uint32, len(key.Path)),
+		}
+		for j, nodeIndex := range key.Path {
+			pbKey.Path[j] = uint32(nodeIndex)
+		}
+		pbProof.Keys[i] = pbKey
+	}
+
+	return pbProof
+}
+
+func (proof *MultiProof) UnmarshalProto(pbProof *pb.MultiProof) error {
+	if pbProof == nil {
+		return ErrNilProof
+	}
+
+	proof.Nodes = make([]ProofNode, len(pbProof.Nodes))
+	for i, pbNode := range pbProof.Nodes {
+		if err := proof.Nodes[i].UnmarshalProto(pbNode); err != nil {
+			return err
+		}
+	}
+
+	proof.Keys = make([]MultiProofKey, len(pbProof.Keys))
+	for i, pbKey := range pbProof.Keys {
+		switch {
+		case pbKey == nil:
+			return ErrNilMultiProofKey
+		case pbKey.Value == nil:
+			return ErrNilValue
+		case pbKey.Value.IsNothing && len(pbKey.Value.Value) != 0:
+			return ErrInvalidMaybe
+		}
+
+		key := &proof.Keys[i]
+		key.Key = ToKey(pbKey.Key)
+		if !pbKey.Value.IsNothing {
+			key.Value = maybe.Some(pbKey.Value.Value)
+		}
+		key.Path = make([]int, len(pbKey.Path))
+		for j, nodeIndex := range pbKey.Path {
+			key.Path[j] = int(nodeIndex)
+		}
+	}
+
+	return nil
+}
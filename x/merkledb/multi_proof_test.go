@@ -0,0 +1,383 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+	"github.com/skychains/chain/utils/set"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+func Test_MultiProof_Empty(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	_, err = db.GetMultiProof(context.Background(), [][]byte{{0}})
+	require.ErrorIs(err, ErrEmptyProof)
+
+	writeBasicBatch(t, db)
+
+	_, err = db.GetMultiProof(context.Background(), nil)
+	require.ErrorIs(err, ErrEmptyProof)
+}
+
+func Test_MultiProof(t *testing.T) {
+	for _, bf := range validBranchFactors {
+		t.Run(fmt.Sprint(bf), func(t *testing.T) {
+			require := require.New(t)
+
+			ctx := context.Background()
+			db, err := getBasicDBWithBranchFactor(bf)
+			require.NoError(err)
+
+			r := rand.New(rand.NewSource(0)) // #nosec G404
+			for i := 0; i < 1_000; i++ {
+				key := make([]byte, r.Intn(4))
+				_, _ = r.Read(key)
+				value := make([]byte, r.Intn(64))
+				_, _ = r.Read(value)
+				require.NoError(db.Put(key, value))
+			}
+
+			keys := make([][]byte, 100)
+			for i := range keys {
+				keys[i] = make([]byte, r.Intn(4))
+				_, _ = r.Read(keys[i])
+			}
+			// Duplicate keys must only be proven once.
+			keys = append(keys, keys[0])
+
+			proof, err := db.GetMultiProof(ctx, keys)
+			require.NoError(err)
+
+			rootID, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+			require.NoError(proof.Verify(ctx, rootID, db.tokenSize, db.hasher))
+
+			uniqueKeys := set.Set[string]{}
+			numPathNodes := 0
+			for _, key := range keys {
+				keyProof, err := db.GetProof(ctx, key)
+				require.NoError(err)
+				numPathNodes += len(keyProof.Path)
+				uniqueKeys.Add(string(key))
+			}
+			require.Less(len(proof.Nodes), numPathNodes)
+
+			require.Len(proof.Keys, uniqueKeys.Len())
+			for _, key := range proof.Keys {
+				expectedValue, err := db.GetValue(ctx, key.Key.Bytes())
+				if err == database.ErrNotFound {
+					require.True(key.Value.IsNothing())
+					continue
+				}
+				require.NoError(err)
+				require.Equal(maybe.Some(expectedValue), key.Value)
+			}
+
+			require.ErrorIs(proof.Verify(ctx, ids.GenerateTestID(), db.tokenSize, db.hasher), ErrInvalidProof)
+		})
+	}
+}
+
+func Test_MultiProof_Verify_Bad_Data(t *testing.T) {
+	type test struct {
+		name        string
+		malform     func(proof *MultiProof)
+		expectedErr error
+	}
+
+	tests := []test{
+		{
+			name:        "happyPath",
+			malform:     func(*MultiProof) {},
+			expectedErr: nil,
+		},
+		{
+			name: "no keys",
+			malform: func(proof *MultiProof) {
+				proof.Keys = nil
+			},
+			expectedErr: ErrEmptyProof,
+		},
+		{
+			name: "empty path",
+			malform: func(proof *MultiProof) {
+				proof.Keys[0].Path = nil
+			},
+			expectedErr: ErrEmptyProof,
+		},
+		{
+			name: "duplicate node",
+			malform: func(proof *MultiProof) {
+				proof.Nodes = append(proof.Nodes, proof.Nodes[len(proof.Nodes)-1])
+			},
+			expectedErr: ErrUnsortedProofNodes,
+		},
+		{
+			name: "unsorted nodes",
+			malform: func(proof *MultiProof) {
+				proof.Nodes[0], proof.Nodes[1] = proof.Nodes[1], proof.Nodes[0]
+			},
+			expectedErr: ErrUnsortedProofNodes,
+		},
+		{
+			name: "unsorted keys",
+			malform: func(proof *MultiProof) {
+				proof.Keys[0], proof.Keys[1] = proof.Keys[1], proof.Keys[0]
+			},
+			expectedErr: ErrNonIncreasingValues,
+		},
+		{
+			name: "node index out of bounds",
+			malform: func(proof *MultiProof) {
+				proof.Keys[0].Path[0] = len(proof.Nodes)
+			},
+			expectedErr: ErrProofNodeIndexOutOfBounds,
+		},
+		{
+			name: "path to a different key",
+			malform: func(proof *MultiProof) {
+				proof.Keys[0].Path = proof.Keys[1].Path
+			},
+			expectedErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "path not from the root",
+			malform: func(proof *MultiProof) {
+				// Prove that the last key isn't in the trie with a node that
+				// diverges from it, but isn't where the key would be.
+				keyProof := &proof.Keys[len(proof.Keys)-1]
+				keyProof.Path = proof.Keys[0].Path[len(proof.Keys[0].Path)-1:]
+			},
+			expectedErr: ErrProofPathNotFromRoot,
+		},
+		{
+			name: "forged exclusion",
+			malform: func(proof *MultiProof) {
+				// Prove that a key in the trie isn't in the trie by ending its
+				// path at an ancestor.
+				keyProof := &proof.Keys[0]
+				keyProof.Path = keyProof.Path[:len(keyProof.Path)-1]
+				keyProof.Value = maybe.Nothing[[]byte]()
+			},
+			expectedErr: ErrIncompleteProofPath,
+		},
+		{
+			name: "mismatched value",
+			malform: func(proof *MultiProof) {
+				proof.Keys[1].Value = maybe.Some([]byte{10})
+			},
+			expectedErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "value of exclusion proof",
+			malform: func(proof *MultiProof) {
+				// The last key isn't in the trie.
+				proof.Keys[len(proof.Keys)-1].Value = maybe.Some([]byte{10})
+			},
+			expectedErr: ErrProofValueDoesntMatch,
+		},
+		{
+			name: "modified node",
+			malform: func(proof *MultiProof) {
+				lastNode := &proof.Nodes[len(proof.Nodes)-1]
+				lastNode.Children = map[byte]ids.ID{0: ids.GenerateTestID()}
+			},
+			expectedErr: ErrInvalidProof,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db, err := getBasicDB()
+			require.NoError(err)
+
+			writeBasicBatch(t, db)
+
+			proof, err := db.GetMultiProof(context.Background(), [][]byte{{1}, {2}, {3}, {5}})
+			require.NoError(err)
+			require.NotNil(proof)
+
+			tt.malform(proof)
+
+			err = proof.Verify(context.Background(), db.getMerkleRoot(), db.tokenSize, db.hasher)
+			require.ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
+func FuzzMultiProofVerification(f *testing.F) {
+	deletePortion := 0.25
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+		numKeyValues uint,
+		numKeys uint,
+	) {
+		rand := rand.New(rand.NewSource(randSeed)) // #nosec G404
+		require := require.New(t)
+		db, err := getBasicDB()
+		require.NoError(err)
+
+		// Insert a bunch of random key values.
+		insertRandomKeyValues(
+			require,
+			rand,
+			[]database.Database{db},
+			numKeyValues%1_000,
+			deletePortion,
+		)
+
+		if db.getMerkleRoot() == ids.Empty {
+			return
+		}
+
+		// Prove both keys that are in the trie and random keys.
+		keys := make([][]byte, 0, numKeys%100+1)
+		it := db.NewIterator()
+		for it.Next() && len(keys) < cap(keys)/2 {
+			keys = append(keys, it.Key())
+		}
+		it.Release()
+		for len(keys) < cap(keys) {
+			key := make([]byte, rand.Intn(32))
+			_, _ = rand.Read(key)
+			keys = append(keys, key)
+		}
+
+		proof, err := db.GetMultiProof(context.Background(), keys)
+		require.NoError(err)
+
+		rootID, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+
+		require.NoError(proof.Verify(context.Background(), rootID, db.tokenSize, db.hasher))
+	})
+}
+
+func FuzzMultiProofProtoMarshalUnmarshal(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+	) {
+		require := require.New(t)
+		rand := rand.New(rand.NewSource(randSeed)) // #nosec G404
+
+		// Make a random proof.
+		proof := MultiProof{
+			Nodes: make([]ProofNode, rand.Intn(32)),
+			Keys:  make([]MultiProofKey, rand.Intn(32)),
+		}
+		for i := range proof.Nodes {
+			proof.Nodes[i] = newRandomProofNode(rand)
+		}
+		for i := range proof.Keys {
+			key := make([]byte, rand.Intn(32))
+			_, _ = rand.Read(key)
+
+			value := maybe.Nothing[[]byte]()
+			if rand.Intn(2) == 1 {
+				valueBytes := make([]byte, rand.Intn(32))
+				_, _ = rand.Read(valueBytes)
+				value = maybe.Some(valueBytes)
+			}
+
+			path := make([]int, rand.Intn(32))
+			for j := range path {
+				path[j] = rand.Intn(32)
+			}
+
+			proof.Keys[i] = MultiProofKey{
+				Key:   ToKey(key),
+				Value: value,
+				Path:  path,
+			}
+		}
+
+		// Marshal and unmarshal it.
+		// Assert the unmarshaled one is the same as the original.
+		var unmarshaledProof MultiProof
+		protoProof := proof.ToProto()
+		require.NoError(unmarshaledProof.UnmarshalProto(protoProof))
+		require.Equal(proof, unmarshaledProof)
+
+		// Marshaling again should yield same result.
+		protoUnmarshaledProof := unmarshaledProof.ToProto()
+		require.Equal(protoProof, protoUnmarshaledProof)
+	})
+}
+
+func TestMultiProofProtoUnmarshal(t *testing.T) {
+	type test struct {
+		name        string
+		proof       *pb.MultiProof
+		expectedErr error
+	}
+
+	tests := []test{
+		{
+			name:        "nil",
+			proof:       nil,
+			expectedErr: ErrNilProof,
+		},
+		{
+			name: "nil node",
+			proof: &pb.MultiProof{
+				Nodes: []*pb.ProofNode{nil},
+			},
+			expectedErr: ErrNilProofNode,
+		},
+		{
+			name: "nil key",
+			proof: &pb.MultiProof{
+				Keys: []*pb.MultiProofKey{nil},
+			},
+			expectedErr: ErrNilMultiProofKey,
+		},
+		{
+			name: "nil value",
+			proof: &pb.MultiProof{
+				Keys: []*pb.MultiProofKey{{}},
+			},
+			expectedErr: ErrNilValue,
+		},
+		{
+			name: "invalid maybe",
+			proof: &pb.MultiProof{
+				Keys: []*pb.MultiProofKey{
+					{
+						Value: &pb.MaybeBytes{
+							Value:     []byte{1},
+							IsNothing: true,
+						},
+					},
+				},
+			},
+			expectedErr: ErrInvalidMaybe,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var proof MultiProof
+			err := proof.UnmarshalProto(tt.proof)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils"
+)
+
+const (
+	// KeyNodeStore stores each node without a value under its own key.
+	KeyNodeStore NodeStoreType = iota
+	// PagedNodeStore groups nodes without values by their trie path into
+	// pages, which are appended to a log. Pages that have been replaced are
+	// removed from the log by compaction.
+	PagedNodeStore
+)
+
+var (
+	_ nodeStore = (*keyNodeStore)(nil)
+	_ nodeStore = (*pagedNodeStore)(nil)
+
+	ErrInvalidNodeStore = errors.New("invalid node store")
+)
+
+// NodeStoreType determines how nodes without values are laid out on disk.
+type NodeStoreType byte
+
+func (t NodeStoreType) Valid() error {
+	switch t {
+	case KeyNodeStore, PagedNodeStore:
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown type %d", ErrInvalidNodeStore, t)
+	}
+}
+
+// nodeStore persists the encoded nodes without values of a trie.
+//
+// Get may be called concurrently, but not concurrently with Write or Clear.
+type nodeStore interface {
+	// Get returns the encoded node with [key].
+	// Returns database.ErrNotFound if there isn't one.
+	Get(key Key) ([]byte, error)
+
+	// Write atomically writes the nodes in [changes]. If a node is nil, the
+	// node with its key is deleted.
+	Write(changes map[Key]*node) error
+
+	// Clear deletes all of the nodes.
+	// Clear isn't atomic, because the intermediate nodes are rebuilt if the
+	// database isn't shut down cleanly.
+	Clear() error
+}
+
+func newNodeStore(
+	db database.Database,
+	bufferPool *utils.BytesPool,
+	storeType NodeStoreType,
+	pageDepth int,
+	pageCacheSize int,
+	tokenSize int,
+) (nodeStore, error) {
+	if storeType == PagedNodeStore {
+		return newPagedNodeStore(db, pageDepth, pageCacheSize)
+	}
+	return newKeyNodeStore(db, bufferPool, tokenSize), nil
+}
+
+// keyNodeStore stores each node under its key, prefixed with
+// [intermediateNodePrefix].
+type keyNodeStore struct {
+	baseDB     database.Database
+	bufferPool *utils.BytesPool
+	tokenSize  int
+}
+
+func newKeyNodeStore(
+	db database.Database,
+	bufferPool *utils.BytesPool,
+	tokenSize int,
+) *keyNodeStore {
+	return &keyNodeStore{
+		baseDB:     db,
+		bufferPool: bufferPool,
+		tokenSize:  tokenSize,
+	}
+}
+
+func (s *keyNodeStore) Get(key Key) ([]byte, error) {
+	dbKey := s.constructDBKey(key)
+	defer s.bufferPool.Put(dbKey)
+
+	return s.baseDB.Get(*dbKey)
+}
+
+func (s *keyNodeStore) Write(changes map[Key]*node) error {
+	batch := s.baseDB.NewBatch()
+	for key, n := range changes {
+		if err := s.addToBatch(batch, key, n); err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+func (s *keyNodeStore) addToBatch(b database.KeyValueWriterDeleter, key Key, n *node) error {
+	dbKey := s.constructDBKey(key)
+	defer s.bufferPool.Put(dbKey)
+
+	if n == nil {
+		return b.Delete(*dbKey)
+	}
+	return b.Put(*dbKey, n.bytes())
+}
+
+func (s *keyNodeStore) Clear() error {
+	return database.ClearPrefix(s.baseDB, intermediateNodePrefix, clearBatchSize)
+}
+
+// constructDBKey returns a key that can be used in [s.baseDB].
+// We need to be able to differentiate between two keys of equal
+// byte length but different bit length, so we add padding to differentiate.
+// Additionally, we add a prefix indicating it is an intermediate node.
+func (s *keyNodeStore) constructDBKey(key Key) *[]byte {
+	if s.tokenSize == 8 {
+		// For tokens of size byte, no padding is needed since byte
+		// length == token length
+		return addPrefixToKey(s.bufferPool, intermediateNodePrefix, key.Bytes())
+	}
+
+	var (
+		prefixLen              = len(intermediateNodePrefix)
+		prefixBitLen           = 8 * prefixLen
+		dualIndex              = dualBitIndex(s.tokenSize)
+		paddingByteValue  byte = 1 << dualIndex
+		paddingSliceValue      = []byte{paddingByteValue}
+		paddingKey             = Key{
+			value:  byteSliceToString(paddingSliceValue),
+			length: s.tokenSize,
+		}
+	)
+
+	bufferPtr := s.bufferPool.Get(bytesNeeded(prefixBitLen + key.length + s.tokenSize))
+	copy(*bufferPtr, intermediateNodePrefix)                          // add prefix
+	copy((*bufferPtr)[prefixLen:], key.Bytes())                       // add key
+	extendIntoBuffer(*bufferPtr, paddingKey, prefixBitLen+key.length) // add padding
+	return bufferPtr
+}
@@ -0,0 +1,374 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/units"
+)
+
+var nodeStoreTypes = []NodeStoreType{
+	KeyNodeStore,
+	PagedNodeStore,
+}
+
+func newTestNodeStore(t testing.TB, db database.Database, storeType NodeStoreType, tokenSize int) nodeStore {
+	store, err := newNodeStore(
+		db,
+		utils.NewBytesPool(),
+		storeType,
+		defaultNodePageDepth(tokenSize),
+		units.MiB,
+		tokenSize,
+	)
+	require.NoError(t, err)
+	return store
+}
+
+func TestNodeStore(t *testing.T) {
+	for _, storeType := range nodeStoreTypes {
+		for _, tokenSize := range validTokenSizes {
+			t.Run(fmt.Sprintf("%d/%d", storeType, tokenSize), func(t *testing.T) {
+				require := require.New(t)
+
+				store := newTestNodeStore(t, memdb.New(), storeType, tokenSize)
+
+				r := rand.New(rand.NewSource(0)) // #nosec G404
+				expected := map[Key]*node{}
+				for i := 0; i < 10; i++ {
+					changes := map[Key]*node{}
+					for j := 0; j < 100; j++ {
+						key := make([]byte, r.Intn(3))
+						_, _ = r.Read(key)
+						k := ToKey(key)
+						k = k.Take(r.Intn(k.length/tokenSize+1) * tokenSize)
+
+						if _, ok := expected[k]; ok && r.Intn(2) == 0 {
+							changes[k] = nil
+							continue
+						}
+						n := newNode(k)
+						n.addChild(newNode(k.Extend(ToToken(1, tokenSize))), tokenSize)
+						changes[k] = n
+					}
+					require.NoError(store.Write(changes))
+
+					for k, n := range changes {
+						if n == nil {
+							delete(expected, k)
+						} else {
+							expected[k] = n
+						}
+					}
+					for k, n := range expected {
+						nodeBytes, err := store.Get(k)
+						require.NoError(err)
+						require.Equal(n.bytes(), nodeBytes)
+					}
+				}
+
+				_, err := store.Get(ToKey([]byte{1, 2, 3, 4}))
+				require.ErrorIs(err, database.ErrNotFound)
+
+				require.NoError(store.Clear())
+				for k := range expected {
+					_, err := store.Get(k)
+					require.ErrorIs(err, database.ErrNotFound)
+				}
+			})
+		}
+	}
+}
+
+func TestNodeStoreChange(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	db, err := newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	for i := 0; i < 1_000; i++ {
+		key := make([]byte, r.Intn(8))
+		_, _ = r.Read(key)
+		require.NoError(db.Put(key, key))
+	}
+	rootID := db.getMerkleRoot()
+	require.NoError(db.Close())
+
+	for _, change := range []struct {
+		storeType NodeStoreType
+		pageDepth uint
+	}{
+		{storeType: PagedNodeStore},
+		{storeType: PagedNodeStore, pageDepth: 16},
+		{storeType: KeyNodeStore},
+	} {
+		config.NodeStore = change.storeType
+		config.NodePageDepth = change.pageDepth
+		db, err = newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+		require.NoError(err)
+		require.Equal(rootID, db.getMerkleRoot())
+
+		// Only the intermediate nodes of the current layout are stored.
+		it := baseDB.NewIteratorWithPrefix(intermediateNodePrefix)
+		require.Equal(change.storeType == KeyNodeStore, it.Next())
+		it.Release()
+		it = baseDB.NewIteratorWithPrefix(nodePagePrefix)
+		require.Equal(change.storeType == PagedNodeStore, it.Next())
+		it.Release()
+
+		require.NoError(db.Close())
+
+		// Reopening with the same layout doesn't rebuild the trie.
+		db, err = newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+		require.NoError(err)
+		require.Equal(rootID, db.getMerkleRoot())
+		require.NoError(db.Close())
+	}
+}
+
+func TestNodeStoreChangeInterrupted(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	config := newDefaultConfig()
+	db, err := newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	for i := 0; i < 1_000; i++ {
+		key := make([]byte, r.Intn(8))
+		_, _ = r.Read(key)
+		require.NoError(db.Put(key, key))
+	}
+	rootID := db.getMerkleRoot()
+	require.NoError(db.Close())
+
+	// Change the layout, but stop before the intermediate nodes are rebuilt.
+	config.NodeStore = PagedNodeStore
+	interrupted := &merkleDB{baseDB: baseDB}
+	storeChanged, err := interrupted.setNodeStore(config.NodeStore, defaultNodePageDepth(BranchFactorToTokenSize[config.BranchFactor]))
+	require.NoError(err)
+	require.True(storeChanged)
+
+	// The intermediate nodes are rebuilt when the db is reopened.
+	db, err = newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+	require.NoError(err)
+	require.Equal(rootID, db.getMerkleRoot())
+
+	it := baseDB.NewIteratorWithPrefix(nodePagePrefix)
+	require.True(it.Next())
+	it.Release()
+
+	require.NoError(db.Close())
+}
+
+func TestNodeStoreInvalidConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		storeType NodeStoreType
+		pageDepth uint
+	}{
+		{
+			name:      "unknown type",
+			storeType: PagedNodeStore + 1,
+		},
+		{
+			name:      "page depth not a multiple of the token size",
+			storeType: PagedNodeStore,
+			pageDepth: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := newDefaultConfig()
+			config.NodeStore = tt.storeType
+			config.NodePageDepth = tt.pageDepth
+			_, err := newDatabase(context.Background(), memdb.New(), config, &mockMetrics{})
+			require.ErrorIs(t, err, ErrInvalidNodeStore)
+		})
+	}
+}
+
+func FuzzKeyNodeStoreConstructDBKey(f *testing.F) {
+	baseDB := memdb.New()
+
+	f.Fuzz(func(
+		t *testing.T,
+		key []byte,
+		tokenLength uint,
+	) {
+		require := require.New(t)
+		for _, tokenSize := range validTokenSizes {
+			store := newKeyNodeStore(baseDB, utils.NewBytesPool(), tokenSize)
+
+			p := ToKey(key)
+			uBitLength := tokenLength * uint(tokenSize)
+			if uBitLength >= uint(p.length) {
+				t.SkipNow()
+			}
+			p = p.Take(int(uBitLength))
+			constructedKey := store.constructDBKey(p)
+			baseLength := len(p.value) + len(intermediateNodePrefix)
+			require.Equal(intermediateNodePrefix, (*constructedKey)[:len(intermediateNodePrefix)])
+			switch {
+			case tokenSize == 8:
+				// for keys with tokens of size byte, no padding is added
+				require.Equal(p.Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
+			case p.hasPartialByte():
+				require.Len(*constructedKey, baseLength)
+				require.Equal(p.Extend(ToToken(1, tokenSize)).Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
+			default:
+				// when a whole number of bytes, there is an extra padding byte
+				require.Len(*constructedKey, baseLength+1)
+				require.Equal(p.Extend(ToToken(1, tokenSize)).Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
+			}
+		}
+	})
+}
+
+func Test_KeyNodeStore_ConstructDBKey_DirtyBuffer(t *testing.T) {
+	require := require.New(t)
+	store := newKeyNodeStore(memdb.New(), utils.NewBytesPool(), 4)
+
+	store.bufferPool.Put(&[]byte{0xFF, 0xFF, 0xFF})
+	constructedKey := store.constructDBKey(ToKey([]byte{}))
+	require.Len(*constructedKey, 2)
+	require.Equal(intermediateNodePrefix, (*constructedKey)[:len(intermediateNodePrefix)])
+	require.Equal(byte(16), (*constructedKey)[len(*constructedKey)-1])
+
+	store.bufferPool = utils.NewBytesPool()
+	store.bufferPool.Put(&[]byte{0xFF, 0xFF, 0xFF})
+	p := ToKey([]byte{0xF0}).Take(4)
+	constructedKey = store.constructDBKey(p)
+	require.Len(*constructedKey, 2)
+	require.Equal(intermediateNodePrefix, (*constructedKey)[:len(intermediateNodePrefix)])
+	require.Equal(p.Extend(ToToken(1, 4)).Bytes(), (*constructedKey)[len(intermediateNodePrefix):])
+}
+
+func Benchmark_KeyNodeStore_ConstructDBKey(b *testing.B) {
+	keyTokenSizes := []int{0, 1, 4, 16, 64, 256}
+	for _, tokenSize := range validTokenSizes {
+		store := newKeyNodeStore(memdb.New(), utils.NewBytesPool(), tokenSize)
+
+		for _, keyTokenSize := range keyTokenSizes {
+			keyBitSize := keyTokenSize * tokenSize
+			keyBytes := make([]byte, bytesNeeded(keyBitSize))
+			key := Key{
+				length: keyBitSize,
+				value:  string(keyBytes),
+			}
+			b.Run(fmt.Sprintf("%d/%d", tokenSize, keyTokenSize), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					store.bufferPool.Put(store.constructDBKey(key))
+				}
+			})
+		}
+	}
+}
+
+// Benchmark_NodeStore_Commit measures committing random writes to a database
+// on disk, with each intermediate node layout. The write buffer is small so
+// that the intermediate nodes are written to disk during the benchmark.
+func Benchmark_NodeStore_Commit(b *testing.B) {
+	const (
+		initialKeys  = 100_000
+		keysPerBatch = 1_000
+	)
+	for _, storeType := range nodeStoreTypes {
+		for _, bf := range []BranchFactor{BranchFactor16, BranchFactor256} {
+			b.Run(fmt.Sprintf("%d/%d", storeType, bf), func(b *testing.B) {
+				require := require.New(b)
+
+				baseDB, err := leveldb.New(
+					b.TempDir(),
+					nil,
+					logging.NoLog{},
+					prometheus.NewRegistry(),
+				)
+				require.NoError(err)
+				defer func() {
+					require.NoError(baseDB.Close())
+				}()
+
+				config := newDefaultConfig()
+				config.BranchFactor = bf
+				config.NodeStore = storeType
+				config.IntermediateNodeCacheSize = units.MiB
+				config.IntermediateWriteBufferSize = units.MiB
+				config.IntermediateWriteBatchSize = 256 * units.KiB
+				config.NodePageCacheSize = units.MiB
+				db, err := newDatabase(context.Background(), baseDB, config, &mockMetrics{})
+				require.NoError(err)
+
+				r := rand.New(rand.NewSource(0)) // #nosec G404
+				writeRandomBatch := func(numKeys int) {
+					batch := db.NewBatch()
+					for i := 0; i < numKeys; i++ {
+						key := make([]byte, 32)
+						_, _ = r.Read(key)
+						require.NoError(batch.Put(key, key))
+					}
+					require.NoError(batch.Write())
+				}
+				for i := 0; i < initialKeys/keysPerBatch; i++ {
+					writeRandomBatch(keysPerBatch)
+				}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					writeRandomBatch(keysPerBatch)
+				}
+				b.StopTimer()
+
+				require.NoError(db.Close())
+			})
+		}
+	}
+}
+
+// Benchmark_NodeStore_Get measures reading intermediate nodes that aren't
+// cached, with each intermediate node layout.
+func Benchmark_NodeStore_Get(b *testing.B) {
+	for _, storeType := range nodeStoreTypes {
+		b.Run(fmt.Sprint(storeType), func(b *testing.B) {
+			require := require.New(b)
+
+			tokenSize := BranchFactorToTokenSize[BranchFactor16]
+			store := newTestNodeStore(b, memdb.New(), storeType, tokenSize)
+
+			r := rand.New(rand.NewSource(0)) // #nosec G404
+			keys := make([]Key, 10_000)
+			changes := make(map[Key]*node, len(keys))
+			for i := range keys {
+				key := make([]byte, 4)
+				_, _ = r.Read(key)
+				keys[i] = ToKey(key).Take(r.Intn(8) * tokenSize)
+
+				changes[keys[i]] = newNode(keys[i])
+			}
+			require.NoError(store.Write(changes))
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, err := store.Get(keys[i%len(keys)])
+				require.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,500 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"slices"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/skychains/chain/cache"
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/utils"
+	"github.com/skychains/chain/utils/units"
+)
+
+const (
+	nodePageIndexPrefixByte = 0
+	nodePageLogPrefixByte   = 1
+	nodePageMetadataByte    = 2
+	nodePageStalePrefixByte = 3
+
+	pageNumberLen = 8
+
+	// Compaction runs once the stale pages in the log are larger than both
+	// the live pages and [minNodePageCompactionSize].
+	minNodePageCompactionSize = units.MiB
+	// The maximum number of bytes of the log that are compacted after each
+	// write.
+	nodePageCompactionBatchSize = 4 * units.MiB
+
+	// Pages span at least this many bits of key by default.
+	minDefaultNodePageDepth = 8
+)
+
+var (
+	nodePageLogPrefix   = []byte{nodePagePrefix[0], nodePageLogPrefixByte}
+	nodePageMetadataKey = []byte{nodePagePrefix[0], nodePageMetadataByte}
+	nodePageStalePrefix = []byte{nodePagePrefix[0], nodePageStalePrefixByte}
+)
+
+// pagedNodeStore groups nodes by their trie path into pages. The page of a
+// node is identified by the node's key, truncated to a multiple of
+// [pageDepth] bits. So, each page holds the nodes of a subtrie that spans
+// [pageDepth] bits of key.
+//
+// Pages are never modified in place. Writing nodes appends a new version of
+// each changed page, containing all of the page's nodes, to the end of a log,
+// and the previous version of the page becomes stale. This turns the random
+// writes of individual nodes into sequential writes of pages. Compaction
+// removes pages from the start of the log. Stale pages are deleted, and live
+// pages are appended to the end of the log again.
+//
+// Pages are stored as:
+//
+//	nodePagePrefix + nodePageLogPrefixByte + pageNumber -> encoded page
+//	nodePagePrefix + nodePageIndexPrefixByte + page key -> pageNumber
+//	nodePagePrefix + nodePageStalePrefixByte + pageNumber -> nil
+//
+// where the second mapping records the latest version of each page, and the
+// third mapping records the pages in the log that are stale. Because both the
+// log and the stale pages are sorted by page number, compaction reads them
+// sequentially.
+type pagedNodeStore struct {
+	baseDB database.Database
+
+	// The number of bits of key that each page spans.
+	pageDepth int
+
+	// The latest versions of recently used pages.
+	// If a value is nil, the page doesn't have any nodes.
+	pageCache cache.Cacher[Key, *nodePage]
+
+	// Only updated once the pages it describes have been written.
+	nodePageMetadata
+}
+
+// nodePageMetadata describes the log.
+type nodePageMetadata struct {
+	// The pages in [oldestPageNumber, nextPageNumber) may be in the log.
+	oldestPageNumber uint64
+	nextPageNumber   uint64
+	// The number of bytes of the latest versions of the pages.
+	liveSize uint64
+	// The number of bytes of the pages that have been replaced.
+	staleSize uint64
+}
+
+// nodePageWrite holds the changes to a pagedNodeStore that are applied to it
+// once [batch] is written, so that the store is unchanged if [batch] isn't
+// written.
+type nodePageWrite struct {
+	batch    database.Batch
+	metadata nodePageMetadata
+	// The latest versions of the written pages.
+	// If a value is nil, the page doesn't have any nodes.
+	pages map[Key]*nodePage
+}
+
+// nodePage is a version of a page in the log.
+type nodePage struct {
+	number uint64
+	bytes  []byte
+}
+
+// newPagedNodeStore loads the pages stored in [db].
+func newPagedNodeStore(db database.Database, pageDepth int, cacheSize int) (*pagedNodeStore, error) {
+	s := &pagedNodeStore{
+		baseDB:    db,
+		pageDepth: pageDepth,
+		pageCache: cache.NewSizedLRU(cacheSize, nodePageCacheEntrySize),
+	}
+	metadataBytes, err := db.Get(nodePageMetadataKey)
+	switch err {
+	case nil:
+		return s, s.nodePageMetadata.parse(metadataBytes)
+	case database.ErrNotFound:
+		return s, nil
+	default:
+		return nil, err
+	}
+}
+
+func (s *pagedNodeStore) Get(key Key) ([]byte, error) {
+	pageBytes, _, err := s.getPage(s.pageKey(key))
+	if err != nil {
+		return nil, err
+	}
+	_, nodes, err := parseNodePage(pageBytes)
+	if err != nil {
+		return nil, err
+	}
+	nodeBytes, ok := nodes[key]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return nodeBytes, nil
+}
+
+func (s *pagedNodeStore) Write(changes map[Key]*node) error {
+	pageChanges := make(map[Key]map[Key]*node)
+	for key, n := range changes {
+		pageKey := s.pageKey(key)
+		nodes, ok := pageChanges[pageKey]
+		if !ok {
+			nodes = make(map[Key]*node)
+			pageChanges[pageKey] = nodes
+		}
+		nodes[key] = n
+	}
+
+	// Append the pages in order so that pages that are near each other in
+	// the trie are near each other in the log.
+	pageKeys := maps.Keys(pageChanges)
+	utils.Sort(pageKeys)
+
+	w := s.newWrite()
+	for _, pageKey := range pageKeys {
+		if err := s.writePage(w, pageKey, pageChanges[pageKey]); err != nil {
+			return err
+		}
+	}
+	if err := s.commit(w); err != nil {
+		return err
+	}
+	return s.compact()
+}
+
+func (s *pagedNodeStore) newWrite() *nodePageWrite {
+	return &nodePageWrite{
+		batch:    s.baseDB.NewBatch(),
+		metadata: s.nodePageMetadata,
+		pages:    make(map[Key]*nodePage),
+	}
+}
+
+// commit writes [w] and then applies it to [s].
+func (s *pagedNodeStore) commit(w *nodePageWrite) error {
+	if err := w.batch.Put(nodePageMetadataKey, w.metadata.bytes()); err != nil {
+		return err
+	}
+	if err := w.batch.Write(); err != nil {
+		return err
+	}
+
+	s.nodePageMetadata = w.metadata
+	for pageKey, page := range w.pages {
+		s.pageCache.Put(pageKey, page)
+	}
+	return nil
+}
+
+// writePage writes a new version of the page with [pageKey], with [changes]
+// applied, into [w].
+func (s *pagedNodeStore) writePage(
+	w *nodePageWrite,
+	pageKey Key,
+	changes map[Key]*node,
+) error {
+	nodes := make(map[Key][]byte, len(changes))
+	pageBytes, pageNumber, err := s.getPage(pageKey)
+	switch err {
+	case nil:
+		if _, nodes, err = parseNodePage(pageBytes); err != nil {
+			return err
+		}
+		if err := w.batch.Put(nodePageStaleKey(pageNumber), nil); err != nil {
+			return err
+		}
+		w.metadata.liveSize -= uint64(len(pageBytes))
+		w.metadata.staleSize += uint64(len(pageBytes))
+	case database.ErrNotFound:
+	default:
+		return err
+	}
+
+	for key, n := range changes {
+		if n == nil {
+			delete(nodes, key)
+		} else {
+			nodes[key] = n.bytes()
+		}
+	}
+	if len(nodes) == 0 {
+		w.pages[pageKey] = nil
+		return w.batch.Delete(nodePageIndexKey(pageKey))
+	}
+	return appendPage(w, pageKey, encodeNodePage(pageKey, nodes))
+}
+
+// appendPage writes [pageBytes] to the end of the log, as the latest version
+// of the page with [pageKey], into [w].
+func appendPage(
+	w *nodePageWrite,
+	pageKey Key,
+	pageBytes []byte,
+) error {
+	pageNumber := w.metadata.nextPageNumber
+	if err := w.batch.Put(nodePageLogKey(pageNumber), pageBytes); err != nil {
+		return err
+	}
+	if err := w.batch.Put(nodePageIndexKey(pageKey), database.PackUInt64(pageNumber)); err != nil {
+		return err
+	}
+	w.pages[pageKey] = &nodePage{
+		number: pageNumber,
+		bytes:  pageBytes,
+	}
+	w.metadata.nextPageNumber++
+	w.metadata.liveSize += uint64(len(pageBytes))
+	return nil
+}
+
+// getPage returns the latest version of the page with [pageKey] and its page
+// number.
+// Returns database.ErrNotFound if the page doesn't have any nodes.
+func (s *pagedNodeStore) getPage(pageKey Key) ([]byte, uint64, error) {
+	if page, ok := s.pageCache.Get(pageKey); ok {
+		if page == nil {
+			return nil, 0, database.ErrNotFound
+		}
+		return page.bytes, page.number, nil
+	}
+
+	pageNumber, err := database.GetUInt64(s.baseDB, nodePageIndexKey(pageKey))
+	if err == database.ErrNotFound {
+		s.pageCache.Put(pageKey, nil)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	pageBytes, err := s.baseDB.Get(nodePageLogKey(pageNumber))
+	if err != nil {
+		return nil, 0, err
+	}
+	s.pageCache.Put(pageKey, &nodePage{
+		number: pageNumber,
+		bytes:  pageBytes,
+	})
+	return pageBytes, pageNumber, nil
+}
+
+// compact removes up to [nodePageCompactionBatchSize] bytes of pages from the
+// start of the log, if most of the log is stale.
+func (s *pagedNodeStore) compact() error {
+	if s.staleSize <= s.liveSize || s.staleSize < minNodePageCompactionSize {
+		return nil
+	}
+
+	logIt := s.baseDB.NewIteratorWithStartAndPrefix(
+		nodePageLogKey(s.oldestPageNumber),
+		nodePageLogPrefix,
+	)
+	defer logIt.Release()
+
+	staleIt := s.baseDB.NewIteratorWithStartAndPrefix(
+		nodePageStaleKey(s.oldestPageNumber),
+		nodePageStalePrefix,
+	)
+	defer staleIt.Release()
+
+	var (
+		w             = s.newWrite()
+		compactedSize = 0
+		hasStale      = staleIt.Next()
+	)
+	for compactedSize < nodePageCompactionBatchSize && logIt.Next() {
+		logKey := slices.Clone(logIt.Key())
+		pageBytes := logIt.Value()
+		pageNumber := binary.BigEndian.Uint64(logKey[len(nodePageLogPrefix):])
+
+		if hasStale && bytes.Equal(staleIt.Key()[len(nodePageStalePrefix):], logKey[len(nodePageLogPrefix):]) {
+			if err := w.batch.Delete(slices.Clone(staleIt.Key())); err != nil {
+				return err
+			}
+			w.metadata.staleSize -= uint64(len(pageBytes))
+			hasStale = staleIt.Next()
+		} else {
+			// The page is live, so it's moved to the end of the log.
+			pageKey, err := parseNodePageKey(pageBytes)
+			if err != nil {
+				return err
+			}
+			w.metadata.liveSize -= uint64(len(pageBytes))
+			if err := appendPage(w, pageKey, slices.Clone(pageBytes)); err != nil {
+				return err
+			}
+		}
+
+		if err := w.batch.Delete(logKey); err != nil {
+			return err
+		}
+		compactedSize += len(pageBytes)
+		w.metadata.oldestPageNumber = pageNumber + 1
+	}
+	if err := logIt.Error(); err != nil {
+		return err
+	}
+	if err := staleIt.Error(); err != nil {
+		return err
+	}
+	return s.commit(w)
+}
+
+func (s *pagedNodeStore) Clear() error {
+	s.pageCache.Flush()
+	if err := database.ClearPrefix(s.baseDB, nodePagePrefix, clearBatchSize); err != nil {
+		return err
+	}
+	s.nodePageMetadata = nodePageMetadata{}
+	return nil
+}
+
+// pageKey returns the key of the page that the node with [key] is in.
+func (s *pagedNodeStore) pageKey(key Key) Key {
+	return key.Take(key.length - key.length%s.pageDepth)
+}
+
+func (m *nodePageMetadata) bytes() []byte {
+	w := codecWriter{
+		b: make([]byte, 0, 4*binary.MaxVarintLen64),
+	}
+	w.Uvarint(m.oldestPageNumber)
+	w.Uvarint(m.nextPageNumber)
+	w.Uvarint(m.liveSize)
+	w.Uvarint(m.staleSize)
+	return w.b
+}
+
+func (m *nodePageMetadata) parse(b []byte) error {
+	r := codecReader{
+		b: b,
+	}
+	var err error
+	if m.oldestPageNumber, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if m.nextPageNumber, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if m.liveSize, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if m.staleSize, err = r.Uvarint(); err != nil {
+		return err
+	}
+	if len(r.b) != 0 {
+		return errExtraSpace
+	}
+	return nil
+}
+
+// nodePageCacheEntrySize returns a rough approximation of the memory consumed
+// by caching [page].
+func nodePageCacheEntrySize(pageKey Key, page *nodePage) int {
+	if page == nil {
+		return cacheEntryOverHead + len(pageKey.Bytes())
+	}
+	return cacheEntryOverHead + len(pageKey.Bytes()) + pageNumberLen + len(page.bytes)
+}
+
+// defaultNodePageDepth returns the number of bits of key that pages span if
+// it isn't configured. Pages span two tokens, and at least
+// [minDefaultNodePageDepth] bits.
+func defaultNodePageDepth(tokenSize int) int {
+	return max(2*tokenSize, minDefaultNodePageDepth)
+}
+
+func nodePageLogKey(pageNumber uint64) []byte {
+	key := make([]byte, 0, len(nodePageLogPrefix)+pageNumberLen)
+	key = append(key, nodePageLogPrefix...)
+	return binary.BigEndian.AppendUint64(key, pageNumber)
+}
+
+func nodePageStaleKey(pageNumber uint64) []byte {
+	key := make([]byte, 0, len(nodePageStalePrefix)+pageNumberLen)
+	key = append(key, nodePageStalePrefix...)
+	return binary.BigEndian.AppendUint64(key, pageNumber)
+}
+
+func nodePageIndexKey(pageKey Key) []byte {
+	w := codecWriter{
+		b: make([]byte, 0, len(nodePagePrefix)+1+keySize(pageKey)),
+	}
+	w.b = append(w.b, nodePagePrefix...)
+	w.b = append(w.b, nodePageIndexPrefixByte)
+	w.Key(pageKey)
+	return w.b
+}
+
+// encodeNodePage encodes the page with [pageKey] that contains [nodes]. The
+// keys of the nodes are encoded relative to [pageKey].
+func encodeNodePage(pageKey Key, nodes map[Key][]byte) []byte {
+	keys := maps.Keys(nodes)
+	utils.Sort(keys)
+
+	size := keySize(pageKey) + binary.MaxVarintLen64
+	for _, key := range keys {
+		nodeBytes := nodes[key]
+		size += keySize(key) + binary.MaxVarintLen64 + len(nodeBytes)
+	}
+
+	w := codecWriter{
+		b: make([]byte, 0, size),
+	}
+	w.Key(pageKey)
+	w.Uvarint(uint64(len(keys)))
+	for _, key := range keys {
+		w.Key(key.Skip(pageKey.length))
+		w.Bytes(nodes[key])
+	}
+	return w.b
+}
+
+// parseNodePageKey returns the key of the encoded page [b].
+func parseNodePageKey(b []byte) (Key, error) {
+	r := codecReader{
+		b: b,
+	}
+	return r.Key()
+}
+
+// parseNodePage returns the key of the encoded page [b] and the encoded nodes
+// in it. The returned nodes reference [b].
+func parseNodePage(b []byte) (Key, map[Key][]byte, error) {
+	r := codecReader{
+		b: b,
+	}
+	pageKey, err := r.Key()
+	if err != nil {
+		return Key{}, nil, err
+	}
+	numNodes, err := r.Uvarint()
+	if err != nil {
+		return Key{}, nil, err
+	}
+	// Each node takes at least 2 bytes.
+	if numNodes > uint64(len(r.b))/2 {
+		return Key{}, nil, errTooManyChildren
+	}
+
+	nodes := make(map[Key][]byte, numNodes)
+	for i := uint64(0); i < numNodes; i++ {
+		keySuffix, err := r.Key()
+		if err != nil {
+			return Key{}, nil, err
+		}
+		nodeBytes, err := r.Bytes()
+		if err != nil {
+			return Key{}, nil, err
+		}
+		nodes[pageKey.Extend(keySuffix)] = nodeBytes
+	}
+	if len(r.b) != 0 {
+		return Key{}, nil, errExtraSpace
+	}
+	return pageKey, nodes, nil
+}
@@ -0,0 +1,186 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/utils/maybe"
+	"github.com/skychains/chain/utils/units"
+)
+
+func TestPagedNodeStorePageKey(t *testing.T) {
+	store := &pagedNodeStore{
+		pageDepth: 8,
+	}
+
+	tests := []struct {
+		key         Key
+		expectedKey Key
+	}{
+		{
+			key:         ToKey(nil),
+			expectedKey: ToKey(nil),
+		},
+		{
+			key:         ToKey([]byte{0xF0}).Take(4),
+			expectedKey: ToKey(nil),
+		},
+		{
+			key:         ToKey([]byte{0xF0}),
+			expectedKey: ToKey([]byte{0xF0}),
+		},
+		{
+			key:         ToKey([]byte{0xF0, 0x10}).Take(12),
+			expectedKey: ToKey([]byte{0xF0}),
+		},
+		{
+			key:         ToKey([]byte{0xF0, 0x10, 0x01}),
+			expectedKey: ToKey([]byte{0xF0, 0x10, 0x01}),
+		},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expectedKey, store.pageKey(tt.key))
+	}
+}
+
+func TestPagedNodeStoreCompaction(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	store, err := newPagedNodeStore(baseDB, 8, units.MiB)
+	require.NoError(err)
+
+	// Each write replaces the same pages, so all but the latest version of
+	// each page are stale.
+	keys := []Key{
+		ToKey(nil),
+		ToKey([]byte{1}),
+		ToKey([]byte{2, 3}),
+	}
+	value := make([]byte, 64*units.KiB)
+	var expected map[Key]*node
+	for i := 0; i < 100; i++ {
+		value[0] = byte(i)
+		expected = make(map[Key]*node, len(keys))
+		for _, key := range keys {
+			n := newNode(key)
+			n.setValue(DefaultHasher, maybe.Some(value))
+			expected[key] = n
+		}
+		require.NoError(store.Write(expected))
+
+		// Compaction keeps the stale pages from growing without bound.
+		require.Less(store.staleSize, uint64(2*minNodePageCompactionSize))
+	}
+
+	for key, n := range expected {
+		nodeBytes, err := store.Get(key)
+		require.NoError(err)
+		require.Equal(n.bytes(), nodeBytes)
+	}
+
+	// The stored pages match the tracked sizes.
+	it := baseDB.NewIteratorWithPrefix(nodePageLogPrefix)
+	defer it.Release()
+
+	var size uint64
+	for it.Next() {
+		size += uint64(len(it.Value()))
+	}
+	require.NoError(it.Error())
+	require.Equal(store.liveSize+store.staleSize, size)
+
+	// The metadata is reloaded from disk.
+	reloadedStore, err := newPagedNodeStore(baseDB, 8, units.MiB)
+	require.NoError(err)
+	require.Equal(store.oldestPageNumber, reloadedStore.oldestPageNumber)
+	require.Equal(store.nextPageNumber, reloadedStore.nextPageNumber)
+	require.Equal(store.liveSize, reloadedStore.liveSize)
+	require.Equal(store.staleSize, reloadedStore.staleSize)
+}
+
+func TestPagedNodeStoreDeletePage(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	store, err := newPagedNodeStore(baseDB, 8, units.MiB)
+	require.NoError(err)
+
+	key := ToKey([]byte{1})
+	require.NoError(store.Write(map[Key]*node{
+		key: newNode(key),
+	}))
+	require.NoError(store.Write(map[Key]*node{
+		key: nil,
+	}))
+
+	_, err = store.Get(key)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	// The only page is stale.
+	require.Zero(store.liveSize)
+	require.Positive(store.staleSize)
+}
+
+func TestPagedNodeStoreFailedWrite(t *testing.T) {
+	require := require.New(t)
+
+	baseDB := memdb.New()
+	store, err := newPagedNodeStore(baseDB, 8, units.MiB)
+	require.NoError(err)
+
+	key := ToKey([]byte{1})
+	require.NoError(store.Write(map[Key]*node{
+		key: newNode(key),
+	}))
+	metadata := store.nodePageMetadata
+	page, ok := store.pageCache.Get(store.pageKey(key))
+	require.True(ok)
+
+	// The store is unchanged if the pages can't be written.
+	require.NoError(baseDB.Close())
+	err = store.Write(map[Key]*node{
+		key: nil,
+	})
+	require.ErrorIs(err, database.ErrClosed)
+	require.Equal(metadata, store.nodePageMetadata)
+
+	cachedPage, ok := store.pageCache.Get(store.pageKey(key))
+	require.True(ok)
+	require.Equal(page, cachedPage)
+}
+
+func TestNodePageEncoding(t *testing.T) {
+	require := require.New(t)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	pageKey := ToKey([]byte{0xF0}).Take(4)
+	nodes := map[Key][]byte{}
+	for i := 0; i < 32; i++ {
+		suffix := make([]byte, r.Intn(4))
+		_, _ = r.Read(suffix)
+		nodeBytes := make([]byte, r.Intn(64))
+		_, _ = r.Read(nodeBytes)
+		nodes[pageKey.Extend(ToKey(suffix))] = nodeBytes
+	}
+
+	pageBytes := encodeNodePage(pageKey, nodes)
+	parsedPageKey, parsedNodes, err := parseNodePage(pageBytes)
+	require.NoError(err)
+	require.Equal(pageKey, parsedPageKey)
+	require.Equal(nodes, parsedNodes)
+
+	_, _, err = parseNodePage(append(pageBytes, 0))
+	require.ErrorIs(err, errExtraSpace)
+
+	_, _, err = parseNodePage(pageBytes[:len(pageBytes)-1])
+	require.ErrorIs(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,214 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+var (
+	ErrNilPrefixProof       = errors.New("prefix proof is nil")
+	ErrProofNodeUnderPrefix = errors.New("proof node has a key with the proven prefix")
+)
+
+// PrefixProof is a proof of all of the key-value pairs whose keys have a
+// given prefix. If [KeyValues] is empty, it's a proof that no key has the
+// prefix.
+type PrefixProof struct {
+	// The proof path from the root towards the prefix.
+	// Nodes whose keys have the prefix aren't included, since they're
+	// calculated from [KeyValues].
+	// The last node may be a node whose key diverges from the prefix.
+	Path []ProofNode
+
+	// All of the key-value pairs whose keys have the prefix.
+	// Sorted by increasing key, with no duplicates.
+	KeyValues []KeyValue
+}
+
+// getPrefixProof returns a proof of all of the key-value pairs in [t] whose
+// keys have [prefix].
+// Note that the size of the proof isn't bounded.
+// Returns ErrEmptyProof if [t] is empty.
+// Assumes [t] doesn't change while this function is running.
+func getPrefixProof(t Trie, prefix []byte) (*PrefixProof, error) {
+	keyProof, err := getProof(t, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &PrefixProof{
+		KeyValues: make([]KeyValue, 0, initKeyValuesSize),
+	}
+
+	prefixKey := ToKey(prefix)
+	for _, node := range keyProof.Path {
+		if node.Key.HasPrefix(prefixKey) {
+			// This node, and any after it, only contain keys with [prefix].
+			break
+		}
+		proof.Path = append(proof.Path, node)
+	}
+
+	it := t.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	for it.Next() {
+		// clone the value to prevent editing of the values stored within the trie
+		proof.KeyValues = append(proof.KeyValues, KeyValue{
+			Key:   it.Key(),
+			Value: slices.Clone(it.Value()),
+		})
+	}
+	return proof, it.Error()
+}
+
+// Verify returns nil if [proof] proves that [proof.KeyValues] are all of the
+// key-value pairs whose keys have [prefix] in the trie with root
+// [expectedRootID].
+func (proof *PrefixProof) Verify(
+	ctx context.Context,
+	prefix []byte,
+	expectedRootID ids.ID,
+	tokenSize int,
+) error {
+	if len(proof.Path) == 0 && len(proof.KeyValues) == 0 {
+		return ErrEmptyProof
+	}
+
+	// Make sure the key-value pairs are sorted and have [prefix].
+	for i, keyValue := range proof.KeyValues {
+		if !bytes.HasPrefix(keyValue.Key, prefix) {
+			return fmt.Errorf("%w: %x doesn't have prefix %x", ErrStateFromOutsideOfRange, keyValue.Key, prefix)
+		}
+		if i > 0 && bytes.Compare(proof.KeyValues[i-1].Key, keyValue.Key) >= 0 {
+			return ErrNonIncreasingValues
+		}
+	}
+
+	// Make sure the path leads to [prefix]. Every node but the last must be
+	// a strict prefix of [prefix]. The last node may diverge from it.
+	prefixKey := ToKey(prefix)
+	if err := verifyProofPath(proof.Path, maybe.Some(prefixKey)); err != nil {
+		return err
+	}
+	for _, node := range proof.Path {
+		if node.Key.HasPrefix(prefixKey) {
+			return ErrProofNodeUnderPrefix
+		}
+	}
+
+	// Insert all key-value pairs into the trie.
+	ops := make([]database.BatchOp, len(proof.KeyValues))
+	for i, kv := range proof.KeyValues {
+		ops[i] = database.BatchOp{
+			Key:   kv.Key,
+			Value: kv.Value,
+		}
+	}
+
+	// Don't need to lock [view] because nobody else has a reference to it.
+	view, err := getStandaloneView(ctx, ops, tokenSize)
+	if err != nil {
+		return err
+	}
+
+	// Insert the proof nodes, descendants before their ancestors, along with
+	// their children that aren't towards [prefix]. The child of each node
+	// towards [prefix] is either the next proof node or contains exactly the
+	// key-value pairs with [prefix], so its ID is calculated. This proves
+	// that no key-value pairs with [prefix] were omitted.
+	for i := len(proof.Path) - 1; i >= 0; i-- {
+		proofNode := proof.Path[i]
+
+		// Pass nothing because we are going to overwrite the value digest
+		// below.
+		n, err := view.insert(proofNode.Key, maybe.Nothing[[]byte]())
+		if err != nil {
+			return err
+		}
+		// We overwrite the valueDigest to be the hash provided in the proof
+		// node because we may not know the pre-image of the valueDigest.
+		n.valueDigest = proofNode.ValueOrHash
+
+		for index, childID := range proofNode.Children {
+			if prefixKey.HasPrefix(proofNode.Key.Extend(ToToken(index, tokenSize))) {
+				continue
+			}
+
+			var compressedKey Key
+			if existingChild, ok := n.children[index]; ok {
+				compressedKey = existingChild.compressedKey
+			}
+			// We only need the ID to be correct so that the calculated hash
+			// is correct.
+			n.setChildEntry(index, &child{
+				id:            childID,
+				compressedKey: compressedKey,
+			})
+		}
+	}
+
+	gotRootID, err := view.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if expectedRootID != gotRootID {
+		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, gotRootID, expectedRootID)
+	}
+	return nil
+}
+
+func (proof *PrefixProof) ToProto() *pb.PrefixProof {
+	path := make([]*pb.ProofNode, len(proof.Path))
+	for i, node := range proof.Path {
+		path[i] = node.ToProto()
+	}
+
+	keyValues := make([]*pb.KeyValue, len(proof.KeyValues))
+	for i, kv := range proof.KeyValues {
+		keyValues[i] = &pb.KeyValue{
+			Key:   kv.Key,
+			Value: kv.Value,
+		}
+	}
+
+	return &pb.PrefixProof{
+		Path:      path,
+		KeyValues: keyValues,
+	}
+}
+
+func (proof *PrefixProof) UnmarshalProto(pbProof *pb.PrefixProof) error {
+	if pbProof == nil {
+		return ErrNilPrefixProof
+	}
+
+	proof.Path = make([]ProofNode, len(pbProof.Path))
+	for i, protoNode := range pbProof.Path {
+		if err := proof.Path[i].UnmarshalProto(protoNode); err != nil {
+			return err
+		}
+	}
+
+	proof.KeyValues = make([]KeyValue, len(pbProof.KeyValues))
+	for i, kv := range pbProof.KeyValues {
+		proof.KeyValues[i] = KeyValue{
+			Key:   kv.GetKey(),
+			Value: kv.GetValue(),
+		}
+	}
+
+	return nil
+}
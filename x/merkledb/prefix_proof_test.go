@@ -0,0 +1,357 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+// Returns the key-value pairs in [db] whose keys have [prefix].
+func getPrefixKeyValues(t *testing.T, db database.Iteratee, prefix []byte) []KeyValue {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	keyValues := []KeyValue{}
+	for it.Next() {
+		keyValues = append(keyValues, KeyValue{
+			Key:   slices.Clone(it.Key()),
+			Value: slices.Clone(it.Value()),
+		})
+	}
+	require.NoError(t, it.Error())
+	return keyValues
+}
+
+func Test_PrefixProof_Empty(t *testing.T) {
+	require := require.New(t)
+
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	_, err = db.GetPrefixProof(context.Background(), []byte{0})
+	require.ErrorIs(err, ErrEmptyProof)
+
+	proof := &PrefixProof{}
+	err = proof.Verify(context.Background(), []byte{0}, ids.Empty, db.tokenSize)
+	require.ErrorIs(err, ErrEmptyProof)
+}
+
+func Test_PrefixProof(t *testing.T) {
+	for _, bf := range validBranchFactors {
+		t.Run(fmt.Sprint(bf), func(t *testing.T) {
+			require := require.New(t)
+
+			ctx := context.Background()
+			db, err := getBasicDBWithBranchFactor(bf)
+			require.NoError(err)
+
+			r := rand.New(rand.NewSource(0)) // #nosec G404
+			for i := 0; i < 1_000; i++ {
+				key := make([]byte, r.Intn(4))
+				_, _ = r.Read(key)
+				value := make([]byte, r.Intn(64))
+				_, _ = r.Read(value)
+				require.NoError(db.Put(key, value))
+			}
+
+			rootID, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+
+			prefixes := [][]byte{{}}
+			for i := 0; i < 100; i++ {
+				prefix := make([]byte, r.Intn(4)+1)
+				_, _ = r.Read(prefix)
+				prefixes = append(prefixes, prefix)
+			}
+			for _, prefix := range prefixes {
+				proof, err := db.GetPrefixProof(ctx, prefix)
+				require.NoError(err)
+				require.NoError(proof.Verify(ctx, prefix, rootID, db.tokenSize))
+				require.Equal(getPrefixKeyValues(t, db, prefix), proof.KeyValues)
+
+				err = proof.Verify(ctx, prefix, ids.GenerateTestID(), db.tokenSize)
+				require.ErrorIs(err, ErrInvalidProof)
+			}
+		})
+	}
+}
+
+func Test_PrefixProof_Verify_Bad_Data(t *testing.T) {
+	keys := [][]byte{
+		{0},
+		{1},
+		{1, 0},
+		{1, 1},
+		{1, 2, 3},
+		{2},
+		{3, 1},
+		{3, 2},
+		{4, 1, 1},
+		{4, 1, 2},
+	}
+
+	type test struct {
+		name        string
+		prefix      []byte
+		malform     func(proof *PrefixProof)
+		expectedErr error
+	}
+
+	tests := []test{
+		{
+			name:        "happyPath",
+			prefix:      []byte{1},
+			malform:     func(*PrefixProof) {},
+			expectedErr: nil,
+		},
+		{
+			name:        "happyPath no keys",
+			prefix:      []byte{1, 5},
+			malform:     func(*PrefixProof) {},
+			expectedErr: nil,
+		},
+		{
+			name:        "happyPath no keys with diverging node",
+			prefix:      []byte{4, 2},
+			malform:     func(*PrefixProof) {},
+			expectedErr: nil,
+		},
+		{
+			name:   "empty proof",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.Path = nil
+				proof.KeyValues = nil
+			},
+			expectedErr: ErrEmptyProof,
+		},
+		{
+			name:   "omitted key",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.KeyValues = proof.KeyValues[1:]
+			},
+			expectedErr: ErrInvalidProof,
+		},
+		{
+			name:   "omitted all keys",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.KeyValues = nil
+			},
+			expectedErr: ErrInvalidProof,
+		},
+		{
+			name:   "extra key",
+			prefix: []byte{1, 5},
+			malform: func(proof *PrefixProof) {
+				proof.KeyValues = []KeyValue{{Key: []byte{1, 5}, Value: []byte{1}}}
+			},
+			expectedErr: ErrInvalidProof,
+		},
+		{
+			name:   "modified value",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.KeyValues[1].Value = []byte{10}
+			},
+			expectedErr: ErrInvalidProof,
+		},
+		{
+			name:   "key without prefix",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.KeyValues = append(proof.KeyValues, KeyValue{Key: []byte{2}, Value: []byte{2}})
+			},
+			expectedErr: ErrStateFromOutsideOfRange,
+		},
+		{
+			name:   "unsorted keys",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.KeyValues[0], proof.KeyValues[1] = proof.KeyValues[1], proof.KeyValues[0]
+			},
+			expectedErr: ErrNonIncreasingValues,
+		},
+		{
+			name:   "node under prefix",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.Path = append(proof.Path, ProofNode{Key: ToKey([]byte{1})})
+			},
+			expectedErr: ErrProofNodeUnderPrefix,
+		},
+		{
+			name:   "node not on path",
+			prefix: []byte{1},
+			malform: func(proof *PrefixProof) {
+				proof.Path = append(
+					[]ProofNode{{Key: ToKey([]byte{2})}},
+					proof.Path...,
+				)
+			},
+			expectedErr: ErrProofNodeNotForKey,
+		},
+		{
+			name:   "omitted diverging node",
+			prefix: []byte{4, 2},
+			malform: func(proof *PrefixProof) {
+				proof.Path = proof.Path[:len(proof.Path)-1]
+			},
+			expectedErr: ErrInvalidProof,
+		},
+		{
+			name:   "modified node",
+			prefix: []byte{1, 5},
+			malform: func(proof *PrefixProof) {
+				lastNode := &proof.Path[len(proof.Path)-1]
+				lastNode.Children = map[byte]ids.ID{0: ids.GenerateTestID()}
+			},
+			expectedErr: ErrInvalidProof,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require := require.New(t)
+
+			db, err := getBasicDB()
+			require.NoError(err)
+
+			batch := db.NewBatch()
+			for _, key := range keys {
+				require.NoError(batch.Put(key, key))
+			}
+			require.NoError(batch.Write())
+
+			proof, err := db.GetPrefixProof(context.Background(), tt.prefix)
+			require.NoError(err)
+			require.NotNil(proof)
+
+			tt.malform(proof)
+
+			err = proof.Verify(context.Background(), tt.prefix, db.getMerkleRoot(), db.tokenSize)
+			require.ErrorIs(err, tt.expectedErr)
+		})
+	}
+}
+
+func FuzzPrefixProofVerification(f *testing.F) {
+	deletePortion := 0.25
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+		numKeyValues uint,
+		prefix []byte,
+	) {
+		rand := rand.New(rand.NewSource(randSeed)) // #nosec G404
+		require := require.New(t)
+		db, err := getBasicDB()
+		require.NoError(err)
+
+		// Insert a bunch of random key values.
+		insertRandomKeyValues(
+			require,
+			rand,
+			[]database.Database{db},
+			numKeyValues%1_000,
+			deletePortion,
+		)
+
+		if db.getMerkleRoot() == ids.Empty {
+			return
+		}
+
+		proof, err := db.GetPrefixProof(context.Background(), prefix)
+		require.NoError(err)
+
+		rootID, err := db.GetMerkleRoot(context.Background())
+		require.NoError(err)
+
+		require.NoError(proof.Verify(context.Background(), prefix, rootID, db.tokenSize))
+		require.Equal(getPrefixKeyValues(t, db, prefix), proof.KeyValues)
+	})
+}
+
+func FuzzPrefixProofProtoMarshalUnmarshal(f *testing.F) {
+	f.Fuzz(func(
+		t *testing.T,
+		randSeed int64,
+	) {
+		require := require.New(t)
+		rand := rand.New(rand.NewSource(randSeed)) // #nosec G404
+
+		// Make a random proof.
+		proof := PrefixProof{
+			Path:      make([]ProofNode, rand.Intn(32)),
+			KeyValues: make([]KeyValue, rand.Intn(32)),
+		}
+		for i := range proof.Path {
+			proof.Path[i] = newRandomProofNode(rand)
+		}
+		for i := range proof.KeyValues {
+			key := make([]byte, rand.Intn(32)+1)
+			_, _ = rand.Read(key)
+			value := make([]byte, rand.Intn(32)+1)
+			_, _ = rand.Read(value)
+			proof.KeyValues[i] = KeyValue{
+				Key:   key,
+				Value: value,
+			}
+		}
+
+		// Marshal and unmarshal it.
+		// Assert the unmarshaled one is the same as the original.
+		var unmarshaledProof PrefixProof
+		protoProof := proof.ToProto()
+		require.NoError(unmarshaledProof.UnmarshalProto(protoProof))
+		require.Equal(proof, unmarshaledProof)
+
+		// Marshaling again should yield same result.
+		protoUnmarshaledProof := unmarshaledProof.ToProto()
+		require.Equal(protoProof, protoUnmarshaledProof)
+	})
+}
+
+func TestPrefixProofProtoUnmarshal(t *testing.T) {
+	type test struct {
+		name        string
+		proof       *pb.PrefixProof
+		expectedErr error
+	}
+
+	tests := []test{
+		{
+			name:        "nil",
+			proof:       nil,
+			expectedErr: ErrNilPrefixProof,
+		},
+		{
+			name: "nil node",
+			proof: &pb.PrefixProof{
+				Path: []*pb.ProofNode{nil},
+			},
+			expectedErr: ErrNilProofNode,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var proof PrefixProof
+			err := proof.UnmarshalProto(tt.proof)
+			require.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -139,6 +139,38 @@ func (proof *Proof) Verify(
 	hasher Hasher,
 ) error {
 	// Make sure the proof is well-formed.
+	if err := proof.verifyPath(hasher); err != nil {
+		return err
+	}
+
+	// Don't bother locking [view] -- nobody else has a reference to it.
+	view, err := getStandaloneView(ctx, nil, tokenSize)
+	if err != nil {
+		return err
+	}
+
+	// Insert all proof nodes.
+	// [provenKey] is the key that we are proving exists, or the key
+	// that is the next key along the node path, proving that [proof.Key] doesn't exist in the trie.
+	provenKey := maybe.Some(proof.Path[len(proof.Path)-1].Key)
+
+	if err = addPathInfo(view, proof.Path, provenKey, provenKey); err != nil {
+		return err
+	}
+
+	gotRootID, err := view.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if expectedRootID != gotRootID {
+		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, gotRootID, expectedRootID)
+	}
+	return nil
+}
+
+// verifyPath returns nil if [proof.Path] is a well-formed path to [proof.Key]
+// whose last node matches [proof.Value].
+func (proof *Proof) verifyPath(hasher Hasher) error {
 	if len(proof.Path) == 0 {
 		return ErrEmptyProof
 	}
@@ -168,29 +200,6 @@ func (proof *Proof) Verify(
 		proof.Value.HasValue() {
 		return ErrProofValueDoesntMatch
 	}
-
-	// Don't bother locking [view] -- nobody else has a reference to it.
-	view, err := getStandaloneView(ctx, nil, tokenSize)
-	if err != nil {
-		return err
-	}
-
-	// Insert all proof nodes.
-	// [provenKey] is the key that we are proving exists, or the key
-	// that is the next key along the node path, proving that [proof.Key] doesn't exist in the trie.
-	provenKey := maybe.Some(lastNode.Key)
-
-	if err = addPathInfo(view, proof.Path, provenKey, provenKey); err != nil {
-		return err
-	}
-
-	gotRootID, err := view.GetMerkleRoot(ctx)
-	if err != nil {
-		return err
-	}
-	if expectedRootID != gotRootID {
-		return fmt.Errorf("%w:[%s], expected:[%s]", ErrInvalidProof, gotRootID, expectedRootID)
-	}
 	return nil
 }
 
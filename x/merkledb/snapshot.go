@@ -0,0 +1,276 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+	"github.com/skychains/chain/utils/units"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+const (
+	// SnapshotVersion is the version of the snapshot format written by Export.
+	SnapshotVersion = 1
+
+	// snapshotChunkLength is the maximum number of key-value pairs in each
+	// chunk of a snapshot.
+	snapshotChunkLength = 2048
+
+	// maxSnapshotChunkSize is the maximum size of an encoded chunk that Import
+	// will read.
+	maxSnapshotChunkSize = 512 * units.MiB
+)
+
+var (
+	snapshotMagic = []byte("merkledb")
+
+	ErrInvalidSnapshot            = errors.New("invalid snapshot")
+	ErrUnsupportedSnapshotVersion = errors.New("unsupported snapshot version")
+	ErrSnapshotRootMismatch       = errors.New("snapshot root mismatch")
+
+	errSnapshotTokenSizeMismatch = errors.New("snapshot token size mismatch")
+	errSnapshotChunkTooLarge     = errors.New("snapshot chunk too large")
+	errEmptySnapshotChunk        = errors.New("empty snapshot chunk")
+)
+
+type Snapshotter interface {
+	// Export writes a snapshot of the trie when its root was [rootID] to [w].
+	//
+	// A snapshot is a header followed by a sequence of range proofs, each of
+	// which can be verified against [rootID] on its own:
+	//
+	//	snapshot   := header chunk* terminator
+	//	header     := "merkledb" version tokenSize rootID
+	//	chunk      := length rangeProof
+	//	terminator := 0
+	//
+	// where [version], [tokenSize] and [length] are uvarints, [rootID] is 32
+	// bytes and [rangeProof] is a protobuf encoded RangeProof of [length]
+	// bytes. The chunks cover consecutive key ranges in increasing order.
+	//
+	// Returns [ErrInsufficientHistory] if [rootID] isn't the current root and
+	// isn't in the history.
+	Export(ctx context.Context, rootID ids.ID, w io.Writer) error
+
+	// Import replaces the contents of the database with the snapshot read
+	// from [r].
+	// Each chunk is verified against [expectedRootID] before it's committed,
+	// and the root of the database is checked once the snapshot is read.
+	// The database is only cleared once the first chunk has been verified, so
+	// it's unmodified if the header or the first chunk is invalid. If an error
+	// is returned after that, the previous contents of the database are lost
+	// and it only contains part of the snapshot.
+	// The database must not be written to while the snapshot is imported.
+	Import(ctx context.Context, r io.Reader, expectedRootID ids.ID) error
+}
+
+func (db *merkleDB) Export(ctx context.Context, rootID ids.ID, w io.Writer) error {
+	ctx, span := db.infoTracer.Start(ctx, "MerkleDB.Export")
+	defer span.End()
+
+	header := codecWriter{
+		b: make([]byte, 0, len(snapshotMagic)+2*binary.MaxVarintLen64+ids.IDLen),
+	}
+	header.b = append(header.b, snapshotMagic...)
+	header.Uvarint(SnapshotVersion)
+	header.Uvarint(uint64(db.tokenSize))
+	header.ID(rootID)
+	if _, err := w.Write(header.b); err != nil {
+		return err
+	}
+
+	start := maybe.Nothing[[]byte]()
+	for rootID != ids.Empty {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		proof, err := db.GetRangeProofAtRoot(ctx, rootID, start, maybe.Nothing[[]byte](), snapshotChunkLength)
+		if err != nil {
+			return err
+		}
+		if len(proof.KeyValues) == 0 {
+			// There are no keys after [start].
+			break
+		}
+
+		proofBytes, err := proto.Marshal(proof.ToProto())
+		if err != nil {
+			return err
+		}
+		chunk := binary.AppendUvarint(
+			make([]byte, 0, binary.MaxVarintLen64+len(proofBytes)),
+			uint64(len(proofBytes)),
+		)
+		chunk = append(chunk, proofBytes...)
+		if _, err := w.Write(chunk); err != nil {
+			return err
+		}
+
+		start = maybe.Some(nextKey(proof.KeyValues[len(proof.KeyValues)-1].Key))
+	}
+
+	_, err := w.Write(binary.AppendUvarint(nil, 0))
+	return err
+}
+
+func (db *merkleDB) Import(ctx context.Context, r io.Reader, expectedRootID ids.ID) error {
+	ctx, span := db.infoTracer.Start(ctx, "MerkleDB.Import")
+	defer span.End()
+
+	reader, ok := r.(snapshotReader)
+	if !ok {
+		reader = bufio.NewReader(r)
+	}
+
+	if err := readSnapshotHeader(reader, db.tokenSize, expectedRootID); err != nil {
+		return err
+	}
+
+	var (
+		start   = maybe.Nothing[[]byte]()
+		chunk   bytes.Buffer
+		cleared bool
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		chunkLen, err := binary.ReadUvarint(reader)
+		if err != nil {
+			return fmt.Errorf("%w: failed to read chunk length: %w", ErrInvalidSnapshot, unexpectedEOF(err))
+		}
+		if chunkLen == 0 {
+			break
+		}
+		if chunkLen > maxSnapshotChunkSize {
+			return fmt.Errorf("%w: %w: %d > %d", ErrInvalidSnapshot, errSnapshotChunkTooLarge, chunkLen, maxSnapshotChunkSize)
+		}
+
+		// Read into a growing buffer, rather than allocating [chunkLen] bytes
+		// up front, so a corrupt length can't cause a large allocation.
+		chunk.Reset()
+		if _, err := io.CopyN(&chunk, reader, int64(chunkLen)); err != nil {
+			return fmt.Errorf("%w: failed to read chunk: %w", ErrInvalidSnapshot, unexpectedEOF(err))
+		}
+
+		var pbProof pb.RangeProof
+		if err := proto.Unmarshal(chunk.Bytes(), &pbProof); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		}
+		var proof RangeProof
+		if err := proof.UnmarshalProto(&pbProof); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		}
+		if len(proof.KeyValues) == 0 {
+			return fmt.Errorf("%w: %w", ErrInvalidSnapshot, errEmptySnapshotChunk)
+		}
+
+		if err := proof.Verify(ctx, start, maybe.Nothing[[]byte](), expectedRootID, db.tokenSize, db.hasher); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidSnapshot, err)
+		}
+
+		// The database is cleared once the snapshot is known to be for
+		// [expectedRootID], so that an invalid snapshot doesn't remove the
+		// current contents.
+		if !cleared {
+			if err := db.Clear(); err != nil {
+				return err
+			}
+			cleared = true
+		}
+		if err := db.CommitRangeProof(ctx, start, maybe.Nothing[[]byte](), &proof); err != nil {
+			return err
+		}
+
+		start = maybe.Some(nextKey(proof.KeyValues[len(proof.KeyValues)-1].Key))
+	}
+
+	// An empty snapshot doesn't have any chunks.
+	if !cleared {
+		if err := db.Clear(); err != nil {
+			return err
+		}
+	}
+
+	// Each chunk only proves the keys up to its last key, so a snapshot that
+	// is missing its last chunks is only detected here.
+	rootID, err := db.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+	if rootID != expectedRootID {
+		return fmt.Errorf("%w: %w: got %s, expected %s", ErrInvalidSnapshot, ErrSnapshotRootMismatch, rootID, expectedRootID)
+	}
+	return nil
+}
+
+type snapshotReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+func readSnapshotHeader(r snapshotReader, tokenSize int, expectedRootID ids.ID) error {
+	magic := make([]byte, len(snapshotMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return fmt.Errorf("%w: failed to read header: %w", ErrInvalidSnapshot, unexpectedEOF(err))
+	}
+	if !bytes.Equal(magic, snapshotMagic) {
+		return fmt.Errorf("%w: unexpected magic %x", ErrInvalidSnapshot, magic)
+	}
+
+	version, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read version: %w", ErrInvalidSnapshot, unexpectedEOF(err))
+	}
+	if version != SnapshotVersion {
+		return fmt.Errorf("%w: %d", ErrUnsupportedSnapshotVersion, version)
+	}
+
+	snapshotTokenSize, err := binary.ReadUvarint(r)
+	if err != nil {
+		return fmt.Errorf("%w: failed to read token size: %w", ErrInvalidSnapshot, unexpectedEOF(err))
+	}
+	if snapshotTokenSize != uint64(tokenSize) {
+		return fmt.Errorf("%w: %w: got %d, expected %d", ErrInvalidSnapshot, errSnapshotTokenSizeMismatch, snapshotTokenSize, tokenSize)
+	}
+
+	var rootID ids.ID
+	if _, err := io.ReadFull(r, rootID[:]); err != nil {
+		return fmt.Errorf("%w: failed to read root: %w", ErrInvalidSnapshot, unexpectedEOF(err))
+	}
+	if rootID != expectedRootID {
+		return fmt.Errorf("%w: got %s, expected %s", ErrSnapshotRootMismatch, rootID, expectedRootID)
+	}
+	return nil
+}
+
+// nextKey returns the smallest key that is larger than [key].
+func nextKey(key []byte) []byte {
+	next := make([]byte, len(key)+1)
+	copy(next, key)
+	return next
+}
+
+// unexpectedEOF converts io.EOF into io.ErrUnexpectedEOF, as the snapshot
+// ends with a terminator rather than at the end of the reader.
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,324 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package merkledb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+// newSnapshotDB returns a database with enough keys that its snapshot has
+// multiple chunks.
+func newSnapshotDB(t *testing.T, bf BranchFactor) *merkleDB {
+	require := require.New(t)
+
+	db, err := getBasicDBWithBranchFactor(bf)
+	require.NoError(err)
+
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	batch := db.NewBatch()
+	for i := 0; i < 2*snapshotChunkLength+1; i++ {
+		key := make([]byte, r.Intn(8))
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(64))
+		_, _ = r.Read(value)
+		require.NoError(batch.Put(key, value))
+	}
+	require.NoError(batch.Write())
+	return db
+}
+
+// splitSnapshot returns the header and the chunks, including their length
+// prefixes, of [snapshot]. The terminator isn't included.
+func splitSnapshot(t *testing.T, snapshot []byte) ([]byte, [][]byte) {
+	require := require.New(t)
+
+	headerLen := len(snapshotMagic) + 2 + ids.IDLen
+	header := snapshot[:headerLen]
+	snapshot = snapshot[headerLen:]
+
+	var chunks [][]byte
+	for {
+		chunkLen, n := binary.Uvarint(snapshot)
+		require.Positive(n)
+		if chunkLen == 0 {
+			require.Len(snapshot, n)
+			return header, chunks
+		}
+		chunks = append(chunks, snapshot[:n+int(chunkLen)])
+		snapshot = snapshot[n+int(chunkLen):]
+	}
+}
+
+func requireSameKeyValues(t *testing.T, expected database.Iteratee, actual database.Iteratee) {
+	require := require.New(t)
+
+	expectedIt := expected.NewIterator()
+	defer expectedIt.Release()
+	actualIt := actual.NewIterator()
+	defer actualIt.Release()
+
+	for expectedIt.Next() {
+		require.True(actualIt.Next())
+		require.Equal(expectedIt.Key(), actualIt.Key())
+		require.Equal(expectedIt.Value(), actualIt.Value())
+	}
+	require.False(actualIt.Next())
+	require.NoError(expectedIt.Error())
+	require.NoError(actualIt.Error())
+}
+
+func Test_Snapshot_ExportImport(t *testing.T) {
+	for _, bf := range validBranchFactors {
+		t.Run(fmt.Sprint(bf), func(t *testing.T) {
+			require := require.New(t)
+
+			ctx := context.Background()
+			db := newSnapshotDB(t, bf)
+			rootID, err := db.GetMerkleRoot(ctx)
+			require.NoError(err)
+
+			snapshot := &bytes.Buffer{}
+			require.NoError(db.Export(ctx, rootID, snapshot))
+
+			_, chunks := splitSnapshot(t, snapshot.Bytes())
+			require.Len(chunks, 2)
+
+			importedDB, err := getBasicDBWithBranchFactor(bf)
+			require.NoError(err)
+			require.NoError(importedDB.Put([]byte("overwritten"), []byte("value")))
+
+			require.NoError(importedDB.Import(ctx, snapshot, rootID))
+
+			importedRootID, err := importedDB.GetMerkleRoot(ctx)
+			require.NoError(err)
+			require.Equal(rootID, importedRootID)
+			requireSameKeyValues(t, db, importedDB)
+		})
+	}
+}
+
+func Test_Snapshot_ExportHistoricalRoot(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	db := newSnapshotDB(t, BranchFactor16)
+	rootID, err := db.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	expectedDB, err := getBasicDB()
+	require.NoError(err)
+	it := db.NewIterator()
+	for it.Next() {
+		require.NoError(expectedDB.Put(it.Key(), it.Value()))
+	}
+	require.NoError(it.Error())
+	it.Release()
+
+	// Change the trie after [rootID].
+	require.NoError(db.Put([]byte("key"), []byte("value")))
+	require.NoError(db.Delete([]byte{}))
+
+	snapshot := &bytes.Buffer{}
+	require.NoError(db.Export(ctx, rootID, snapshot))
+
+	importedDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(importedDB.Import(ctx, snapshot, rootID))
+	requireSameKeyValues(t, expectedDB, importedDB)
+
+	err = db.Export(ctx, ids.GenerateTestID(), &bytes.Buffer{})
+	require.ErrorIs(err, ErrInsufficientHistory)
+}
+
+func Test_Snapshot_Empty(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	db, err := getBasicDB()
+	require.NoError(err)
+
+	snapshot := &bytes.Buffer{}
+	require.NoError(db.Export(ctx, ids.Empty, snapshot))
+
+	_, chunks := splitSnapshot(t, snapshot.Bytes())
+	require.Empty(chunks)
+
+	importedDB, err := getBasicDB()
+	require.NoError(err)
+	require.NoError(importedDB.Put([]byte("key"), []byte("value")))
+	require.NoError(importedDB.Import(ctx, snapshot, ids.Empty))
+
+	importedRootID, err := importedDB.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(ids.Empty, importedRootID)
+}
+
+func Test_Snapshot_Import_Bad_Data(t *testing.T) {
+	ctx := context.Background()
+	db := newSnapshotDB(t, BranchFactor16)
+	rootID, err := db.GetMerkleRoot(ctx)
+	require.NoError(t, err)
+
+	snapshot := &bytes.Buffer{}
+	require.NoError(t, db.Export(ctx, rootID, snapshot))
+	header, chunks := splitSnapshot(t, snapshot.Bytes())
+	terminator := []byte{0}
+
+	tests := []struct {
+		name         string
+		snapshot     [][]byte
+		expectedRoot ids.ID
+		expectedErr  error
+		// Whether the error is found before the database is cleared, which
+		// only happens once the first chunk has been verified.
+		unmodified bool
+	}{
+		{
+			name:         "wrong root",
+			snapshot:     [][]byte{snapshot.Bytes()},
+			expectedRoot: ids.GenerateTestID(),
+			expectedErr:  ErrSnapshotRootMismatch,
+			unmodified:   true,
+		},
+		{
+			name:         "invalid magic",
+			snapshot:     [][]byte{[]byte("notmerkledb")},
+			expectedRoot: rootID,
+			expectedErr:  ErrInvalidSnapshot,
+			unmodified:   true,
+		},
+		{
+			name: "unsupported version",
+			snapshot: [][]byte{
+				snapshotMagic,
+				{SnapshotVersion + 1},
+			},
+			expectedRoot: rootID,
+			expectedErr:  ErrUnsupportedSnapshotVersion,
+			unmodified:   true,
+		},
+		{
+			name: "token size mismatch",
+			snapshot: [][]byte{
+				snapshotMagic,
+				{SnapshotVersion, 8},
+				rootID[:],
+			},
+			expectedRoot: rootID,
+			expectedErr:  errSnapshotTokenSizeMismatch,
+			unmodified:   true,
+		},
+		{
+			name:         "truncated header",
+			snapshot:     [][]byte{header[:len(header)-1]},
+			expectedRoot: rootID,
+			expectedErr:  io.ErrUnexpectedEOF,
+			unmodified:   true,
+		},
+		{
+			name:         "truncated chunk",
+			snapshot:     [][]byte{header, chunks[0][:len(chunks[0])-1]},
+			expectedRoot: rootID,
+			expectedErr:  io.ErrUnexpectedEOF,
+			unmodified:   true,
+		},
+		{
+			name:         "missing terminator",
+			snapshot:     [][]byte{header, chunks[0], chunks[1]},
+			expectedRoot: rootID,
+			expectedErr:  io.ErrUnexpectedEOF,
+		},
+		{
+			name:         "missing chunk",
+			snapshot:     [][]byte{header, chunks[0], terminator},
+			expectedRoot: rootID,
+			expectedErr:  ErrSnapshotRootMismatch,
+		},
+		{
+			name:         "skipped chunk",
+			snapshot:     [][]byte{header, chunks[1], terminator},
+			expectedRoot: rootID,
+			expectedErr:  ErrInvalidSnapshot,
+			unmodified:   true,
+		},
+		{
+			name:         "repeated chunk",
+			snapshot:     [][]byte{header, chunks[0], chunks[0], terminator},
+			expectedRoot: rootID,
+			expectedErr:  ErrStateFromOutsideOfRange,
+		},
+		{
+			name: "modified chunk",
+			snapshot: [][]byte{
+				header,
+				func() []byte {
+					chunk := bytes.Clone(chunks[0])
+					chunk[len(chunk)-1]++
+					return chunk
+				}(),
+				terminator,
+			},
+			expectedRoot: rootID,
+			expectedErr:  ErrInvalidSnapshot,
+			unmodified:   true,
+		},
+		{
+			name: "empty chunk",
+			snapshot: [][]byte{
+				header,
+				func() []byte {
+					proofBytes, err := proto.Marshal(&pb.RangeProof{
+						StartProof: []*pb.ProofNode{(&ProofNode{}).ToProto()},
+					})
+					require.NoError(t, err)
+					return append(binary.AppendUvarint(nil, uint64(len(proofBytes))), proofBytes...)
+				}(),
+				terminator,
+			},
+			expectedRoot: rootID,
+			expectedErr:  errEmptySnapshotChunk,
+			unmodified:   true,
+		},
+		{
+			name:         "chunk too large",
+			snapshot:     [][]byte{header, binary.AppendUvarint(nil, maxSnapshotChunkSize+1)},
+			expectedRoot: rootID,
+			expectedErr:  errSnapshotChunkTooLarge,
+			unmodified:   true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require := require.New(t)
+
+			importedDB, err := getBasicDB()
+			require.NoError(err)
+			require.NoError(importedDB.Put([]byte("key"), []byte("value")))
+			initialRootID, err := importedDB.GetMerkleRoot(ctx)
+			require.NoError(err)
+
+			snapshot := bytes.NewReader(bytes.Join(test.snapshot, nil))
+			err = importedDB.Import(ctx, snapshot, test.expectedRoot)
+			require.ErrorIs(err, test.expectedErr)
+
+			importedRootID, err := importedDB.GetMerkleRoot(ctx)
+			require.NoError(err)
+			require.Equal(test.unmodified, initialRootID == importedRootID)
+		})
+	}
+}
@@ -76,6 +76,17 @@ type Trie interface {
 	// Returns ErrEmptyProof if the trie is empty.
 	GetRangeProof(ctx context.Context, start maybe.Maybe[[]byte], end maybe.Maybe[[]byte], maxLength int) (*RangeProof, error)
 
+	// GetMultiProof generates a proof of the values associated with [keys], or
+	// of their absence from the trie. Proof nodes shared by the proofs of
+	// multiple keys are only included once.
+	// Returns ErrEmptyProof if the trie is empty or [keys] is empty.
+	GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error)
+
+	// GetPrefixProof generates a proof of all of the key-value pairs whose
+	// keys have [prefix], or of there being none.
+	// Returns ErrEmptyProof if the trie is empty.
+	GetPrefixProof(ctx context.Context, prefix []byte) (*PrefixProof, error)
+
 	// NewView returns a new view on top of this Trie where the passed changes
 	// have been applied.
 	NewView(
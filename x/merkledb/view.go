@@ -4,6 +4,7 @@
 package merkledb
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"slices"
@@ -154,6 +155,13 @@ func newView(
 	}
 
 	for _, op := range changes.BatchOps {
+		if op.DeleteRange {
+			if err := v.recordRangeDeletion(op.Key, op.Limit); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		key := op.Key
 		if !changes.ConsumeBytes {
 			key = slices.Clone(op.Key)
@@ -450,6 +458,42 @@ func (v *view) GetProof(ctx context.Context, key []byte) (*Proof, error) {
 	return result, nil
 }
 
+func (v *view) GetMultiProof(ctx context.Context, keys [][]byte) (*MultiProof, error) {
+	_, span := v.db.infoTracer.Start(ctx, "MerkleDB.view.GetMultiProof")
+	defer span.End()
+
+	if err := v.applyValueChanges(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := getMultiProof(v, keys)
+	if err != nil {
+		return nil, err
+	}
+	if v.isInvalid() {
+		return nil, ErrInvalid
+	}
+	return result, nil
+}
+
+func (v *view) GetPrefixProof(ctx context.Context, prefix []byte) (*PrefixProof, error) {
+	_, span := v.db.infoTracer.Start(ctx, "MerkleDB.view.GetPrefixProof")
+	defer span.End()
+
+	if err := v.applyValueChanges(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := getPrefixProof(v, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if v.isInvalid() {
+		return nil, ErrInvalid
+	}
+	return result, nil
+}
+
 // GetRangeProof returns a range proof for (at least part of) the key range [start, end].
 // The returned proof's [KeyValues] has at most [maxLength] values.
 // [maxLength] must be > 0.
@@ -966,6 +1010,35 @@ func (v *view) recordValueChange(key Key, value maybe.Maybe[[]byte]) error {
 	return nil
 }
 
+// Records the removal of every key in [start, limit) from the parent trie as
+// well as every key in that range previously recorded in this view.
+// An empty [limit] is treated as a key after all keys.
+// Must not be called after [applyValueChanges] has returned.
+func (v *view) recordRangeDeletion(start, limit []byte) error {
+	it := v.getParentTrie().NewIteratorWithStart(start)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(limit) != 0 && bytes.Compare(key, limit) >= 0 {
+			break
+		}
+		if err := v.recordValueChange(toKey(slices.Clone(key)), maybe.Nothing[[]byte]()); err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+
+	for key, change := range v.changes.values {
+		if database.InRange(key.Bytes(), start, limit) {
+			change.after = maybe.Nothing[[]byte]()
+		}
+	}
+	return nil
+}
+
 // Retrieves a node with the given [key].
 // If the node is fetched from [v.parentTrie] and [id] isn't empty,
 // sets the node's ID to [id].
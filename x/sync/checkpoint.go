@@ -0,0 +1,140 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+	"github.com/skychains/chain/utils/wrappers"
+)
+
+var (
+	errInvalidCheckpoint         = errors.New("invalid checkpoint")
+	errInvalidCheckpointPriority = errors.New("invalid checkpoint priority")
+)
+
+// checkpoint is the progress of a Manager towards a target root.
+//
+// Checkpoints are stored in [ManagerConfig.CheckpointDB] with the target root
+// as the key. There is at most one checkpoint in the database.
+type checkpoint struct {
+	// Ranges whose key-value pairs have been synced to the local root of the
+	// range.
+	processed []*workItem
+	// Ranges that haven't been synced yet, including those that were being
+	// processed when the checkpoint was written.
+	outstanding []*workItem
+}
+
+// getCheckpoint returns the checkpoint in [db] and the target root it was
+// written for.
+// Returns database.ErrNotFound if there is no checkpoint.
+func getCheckpoint(db database.Database) (ids.ID, *checkpoint, error) {
+	it := db.NewIterator()
+	defer it.Release()
+
+	if !it.Next() {
+		if err := it.Error(); err != nil {
+			return ids.Empty, nil, err
+		}
+		return ids.Empty, nil, database.ErrNotFound
+	}
+
+	targetRoot, err := ids.ToID(it.Key())
+	if err != nil {
+		return ids.Empty, nil, fmt.Errorf("%w: %w", errInvalidCheckpoint, err)
+	}
+	c, err := parseCheckpoint(slices.Clone(it.Value()))
+	if err != nil {
+		return ids.Empty, nil, err
+	}
+	return targetRoot, c, it.Error()
+}
+
+// putCheckpoint replaces the checkpoint in [db] with [c] for [targetRoot].
+// [previousTargetRoot] is the target root of the current checkpoint.
+func putCheckpoint(db database.Database, previousTargetRoot ids.ID, targetRoot ids.ID, c *checkpoint) error {
+	batch := db.NewBatch()
+	if previousTargetRoot != targetRoot {
+		if err := batch.Delete(previousTargetRoot[:]); err != nil {
+			return err
+		}
+	}
+	if err := batch.Put(targetRoot[:], c.bytes()); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+func (c *checkpoint) bytes() []byte {
+	p := wrappers.Packer{
+		MaxSize: math.MaxInt32,
+	}
+	packWorkItems(&p, c.processed)
+	packWorkItems(&p, c.outstanding)
+	return p.Bytes
+}
+
+func parseCheckpoint(b []byte) (*checkpoint, error) {
+	p := wrappers.Packer{Bytes: b}
+	c := &checkpoint{
+		processed:   unpackWorkItems(&p),
+		outstanding: unpackWorkItems(&p),
+	}
+	if p.Errored() {
+		return nil, fmt.Errorf("%w: %w", errInvalidCheckpoint, p.Err)
+	}
+	if p.Offset != len(b) {
+		return nil, fmt.Errorf("%w: %d trailing bytes", errInvalidCheckpoint, len(b)-p.Offset)
+	}
+	return c, nil
+}
+
+func packWorkItems(p *wrappers.Packer, items []*workItem) {
+	p.PackInt(uint32(len(items)))
+	for _, item := range items {
+		p.PackByte(byte(item.priority))
+		p.PackFixedBytes(item.localRootID[:])
+		packMaybeBytes(p, item.start)
+		packMaybeBytes(p, item.end)
+	}
+}
+
+func unpackWorkItems(p *wrappers.Packer) []*workItem {
+	numItems := p.UnpackInt()
+	var items []*workItem
+	for i := uint32(0); i < numItems && !p.Errored(); i++ {
+		item := &workItem{
+			priority: priority(p.UnpackByte()),
+		}
+		if item.priority < lowPriority || item.priority > highPriority {
+			p.Add(fmt.Errorf("%w: %d", errInvalidCheckpointPriority, item.priority))
+		}
+		copy(item.localRootID[:], p.UnpackFixedBytes(ids.IDLen))
+		item.start = unpackMaybeBytes(p)
+		item.end = unpackMaybeBytes(p)
+		items = append(items, item)
+	}
+	return items
+}
+
+func packMaybeBytes(p *wrappers.Packer, value maybe.Maybe[[]byte]) {
+	p.PackBool(value.HasValue())
+	if value.HasValue() {
+		p.PackBytes(value.Value())
+	}
+}
+
+func unpackMaybeBytes(p *wrappers.Packer) maybe.Maybe[[]byte] {
+	if !p.UnpackBool() {
+		return maybe.Nothing[[]byte]()
+	}
+	return maybe.Some(p.UnpackBytes())
+}
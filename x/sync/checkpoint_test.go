@@ -0,0 +1,106 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/maybe"
+	"github.com/skychains/chain/utils/wrappers"
+)
+
+func TestCheckpointPutGet(t *testing.T) {
+	require := require.New(t)
+
+	db := memdb.New()
+	_, _, err := getCheckpoint(db)
+	require.ErrorIs(err, database.ErrNotFound)
+
+	firstRoot := ids.GenerateTestID()
+	firstCheckpoint := &checkpoint{
+		processed: []*workItem{
+			newWorkItem(firstRoot, maybe.Nothing[[]byte](), maybe.Some([]byte{1}), lowPriority),
+			newWorkItem(ids.GenerateTestID(), maybe.Some([]byte{2}), maybe.Some([]byte{3, 4}), medPriority),
+		},
+		outstanding: []*workItem{
+			newWorkItem(ids.Empty, maybe.Some([]byte{1}), maybe.Some([]byte{2}), highPriority),
+			newWorkItem(ids.Empty, maybe.Some([]byte{3, 4}), maybe.Nothing[[]byte](), lowPriority),
+		},
+	}
+	require.NoError(putCheckpoint(db, ids.Empty, firstRoot, firstCheckpoint))
+
+	gotRoot, gotCheckpoint, err := getCheckpoint(db)
+	require.NoError(err)
+	require.Equal(firstRoot, gotRoot)
+	require.Equal(firstCheckpoint, gotCheckpoint)
+
+	// Writing a checkpoint for a new target root replaces the old one.
+	secondRoot := ids.GenerateTestID()
+	secondCheckpoint := &checkpoint{
+		outstanding: []*workItem{
+			newWorkItem(firstRoot, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), highPriority),
+		},
+	}
+	require.NoError(putCheckpoint(db, firstRoot, secondRoot, secondCheckpoint))
+
+	gotRoot, gotCheckpoint, err = getCheckpoint(db)
+	require.NoError(err)
+	require.Equal(secondRoot, gotRoot)
+	require.Equal(secondCheckpoint, gotCheckpoint)
+	has, err := db.Has(firstRoot[:])
+	require.NoError(err)
+	require.False(has)
+}
+
+func TestParseCheckpointInvalid(t *testing.T) {
+	validBytes := (&checkpoint{
+		processed: []*workItem{
+			newWorkItem(ids.GenerateTestID(), maybe.Nothing[[]byte](), maybe.Some([]byte{1}), lowPriority),
+		},
+	}).bytes()
+
+	invalidPriority := wrappers.Packer{MaxSize: 1024}
+	packWorkItems(&invalidPriority, []*workItem{
+		newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), highPriority+1),
+	})
+	packWorkItems(&invalidPriority, nil)
+
+	tests := []struct {
+		name        string
+		bytes       []byte
+		expectedErr error
+	}{
+		{
+			name:        "empty",
+			bytes:       nil,
+			expectedErr: errInvalidCheckpoint,
+		},
+		{
+			name:        "truncated",
+			bytes:       validBytes[:len(validBytes)-1],
+			expectedErr: errInvalidCheckpoint,
+		},
+		{
+			name:        "trailing bytes",
+			bytes:       append(validBytes, 0),
+			expectedErr: errInvalidCheckpoint,
+		},
+		{
+			name:        "invalid priority",
+			bytes:       invalidPriority.Bytes,
+			expectedErr: errInvalidCheckpointPriority,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parseCheckpoint(test.bytes)
+			require.ErrorIs(t, err, test.expectedErr)
+		})
+	}
+}
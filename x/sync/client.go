@@ -14,6 +14,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/protobuf/proto"
 
+	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/maybe"
@@ -38,6 +39,19 @@ var (
 	errTooManyKeys                   = errors.New("response contains more than requested keys")
 	errTooManyBytes                  = errors.New("response contains more than requested bytes")
 	errUnexpectedChangeProofResponse = errors.New("unexpected response type")
+	errNoPeers                       = errors.New("no peers to send requests to")
+	errInvalidRequest                = errors.New("failed to create request")
+	errInvalidRequestRoot            = errors.New("invalid root in request")
+
+	// localErrs are returned while parsing a response because of this node,
+	// rather than because of the response, so peers aren't penalized for
+	// them.
+	localErrs = []error{
+		database.ErrClosed,
+		merkledb.ErrInvalid,
+		merkledb.ErrStartAfterEnd,
+		errInvalidRequestRoot,
+	}
 )
 
 // Client synchronously fetches data from the network
@@ -103,14 +117,29 @@ func NewClient(config *ClientConfig) (Client, error) {
 	}, nil
 }
 
-// GetChangeProof synchronously retrieves the change proof given by [req].
+// GetChangeProof synchronously retrieves the change proof given by [request].
+// The key and byte limits of [request] are lowered to the network client's
+// request limits for the peer it's sent to if they're larger.
 // Upon failure, retries until the context is expired.
 // The returned change proof is verified.
 func (c *client) GetChangeProof(
 	ctx context.Context,
-	req *pb.SyncGetChangeProofRequest,
+	request *pb.SyncGetChangeProofRequest,
 	db DB,
 ) (*merkledb.ChangeOrRangeProof, error) {
+	// [req] is the request that was most recently sent to a peer.
+	var req *pb.SyncGetChangeProofRequest
+	newRequest := func(keyLimit uint32, bytesLimit uint32) ([]byte, error) {
+		req = proto.Clone(request).(*pb.SyncGetChangeProofRequest)
+		req.KeyLimit = min(req.KeyLimit, keyLimit)
+		req.BytesLimit = min(req.BytesLimit, bytesLimit)
+		return proto.Marshal(&pb.Request{
+			Message: &pb.Request_ChangeProofRequest{
+				ChangeProofRequest: req,
+			},
+		})
+	}
+
 	parseFn := func(ctx context.Context, responseBytes []byte) (*merkledb.ChangeOrRangeProof, error) {
 		if len(responseBytes) > int(req.BytesLimit) {
 			return nil, fmt.Errorf("%w: (%d) > %d)", errTooManyBytes, len(responseBytes), req.BytesLimit)
@@ -143,7 +172,7 @@ func (c *client) GetChangeProof(
 
 			endRoot, err := ids.ToID(req.EndRootHash)
 			if err != nil {
-				return nil, err
+				return nil, fmt.Errorf("%w: %w", errInvalidRequestRoot, err)
 			}
 
 			if err := db.VerifyChangeProof(
@@ -193,15 +222,7 @@ func (c *client) GetChangeProof(
 		}
 	}
 
-	reqBytes, err := proto.Marshal(&pb.Request{
-		Message: &pb.Request_ChangeProofRequest{
-			ChangeProofRequest: req,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-	return getAndParse(ctx, c, reqBytes, parseFn)
+	return getAndParse(ctx, c, newRequest, parseFn)
 }
 
 // Verify [rangeProof] is a valid range proof for keys in [start, end] for
@@ -219,7 +240,7 @@ func verifyRangeProof(
 ) error {
 	root, err := ids.ToID(rootBytes)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", errInvalidRequestRoot, err)
 	}
 
 	// Ensure the response does not contain more than the maximum requested number of leaves.
@@ -243,13 +264,28 @@ func verifyRangeProof(
 	return nil
 }
 
-// GetRangeProof synchronously retrieves the range proof given by [req].
+// GetRangeProof synchronously retrieves the range proof given by [request].
+// The key and byte limits of [request] are lowered to the network client's
+// request limits for the peer it's sent to if they're larger.
 // Upon failure, retries until the context is expired.
 // The returned range proof is verified.
 func (c *client) GetRangeProof(
 	ctx context.Context,
-	req *pb.SyncGetRangeProofRequest,
+	request *pb.SyncGetRangeProofRequest,
 ) (*merkledb.RangeProof, error) {
+	// [req] is the request that was most recently sent to a peer.
+	var req *pb.SyncGetRangeProofRequest
+	newRequest := func(keyLimit uint32, bytesLimit uint32) ([]byte, error) {
+		req = proto.Clone(request).(*pb.SyncGetRangeProofRequest)
+		req.KeyLimit = min(req.KeyLimit, keyLimit)
+		req.BytesLimit = min(req.BytesLimit, bytesLimit)
+		return proto.Marshal(&pb.Request{
+			Message: &pb.Request_RangeProofRequest{
+				RangeProofRequest: req,
+			},
+		})
+	}
+
 	parseFn := func(ctx context.Context, responseBytes []byte) (*merkledb.RangeProof, error) {
 		if len(responseBytes) > int(req.BytesLimit) {
 			return nil, fmt.Errorf(
@@ -283,29 +319,24 @@ func (c *client) GetRangeProof(
 		return &rangeProof, nil
 	}
 
-	reqBytes, err := proto.Marshal(&pb.Request{
-		Message: &pb.Request_RangeProofRequest{
-			RangeProofRequest: req,
-		},
-	})
-	if err != nil {
-		return nil, err
-	}
-
-	return getAndParse(ctx, c, reqBytes, parseFn)
+	return getAndParse(ctx, c, newRequest, parseFn)
 }
 
-// getAndParse uses [client] to send [request] to an arbitrary peer.
+// getAndParse uses [client] to send a request to an arbitrary peer.
 // Returns the response to the request.
-// [parseFn] parses the raw response.
+// [newRequest] returns the request to send, given the request limits of the
+// peer it's sent to. [parseFn] parses the raw response to the request that was
+// most recently returned by [newRequest].
 // If the request is unsuccessful or the response can't be parsed,
 // retries the request to a different peer until [ctx] expires.
+// Peers that send responses that can't be parsed are reported to the network
+// client, unless the response couldn't be parsed because of this node.
 // Returns [errAppSendFailed] if we fail to send an AppRequest/AppResponse.
 // This should be treated as a fatal error.
 func getAndParse[T any](
 	ctx context.Context,
 	client *client,
-	request []byte,
+	newRequest func(keyLimit uint32, bytesLimit uint32) ([]byte, error),
 	parseFn func(context.Context, []byte) (*T, error),
 ) (*T, error) {
 	var (
@@ -314,15 +345,20 @@ func getAndParse[T any](
 	)
 	// Loop until the context is cancelled or we get a valid response.
 	for attempt := 1; ; attempt++ {
-		nodeID, responseBytes, err := client.get(ctx, request)
+		nodeID, responseBytes, err := client.get(ctx, newRequest)
 		if err == nil {
 			if response, err = parseFn(ctx, responseBytes); err == nil {
 				return response, nil
 			}
+			if ctx.Err() == nil && !isLocalError(err) {
+				// The peer responded, but its response was invalid.
+				client.networkClient.RegisterInvalidResponse(nodeID)
+			}
 		}
 
-		if errors.Is(err, errAppSendFailed) {
-			// Failing to send an AppRequest is a fatal error.
+		if errors.Is(err, errAppSendFailed) || errors.Is(err, errInvalidRequest) {
+			// Failing to create a request or send an AppRequest is a fatal
+			// error.
 			return nil, err
 		}
 
@@ -356,30 +392,53 @@ func getAndParse[T any](
 	}
 }
 
-// get sends [request] to an arbitrary peer and blocks
-// until the node receives a response, failure notification
-// or [ctx] is canceled.
+// isLocalError returns true if [err], returned while parsing a response, was
+// caused by this node rather than by the response.
+func isLocalError(err error) bool {
+	for _, localErr := range localErrs {
+		if errors.Is(err, localErr) {
+			return true
+		}
+	}
+	return false
+}
+
+// get sends the request returned by [newRequest], given the request limits of
+// the peer, to an arbitrary peer and blocks until the node receives a
+// response, failure notification or [ctx] is canceled.
 // Returns the peer's NodeID and response.
-// Returns [errAppSendFailed] if we failed to send an AppRequest/AppResponse.
-// This should be treated as fatal.
+// Returns [errAppSendFailed] if we failed to send an AppRequest/AppResponse
+// and [errInvalidRequest] if the request couldn't be created.
+// These should be treated as fatal.
 // It's safe to call this method multiple times concurrently.
-func (c *client) get(ctx context.Context, request []byte) (ids.NodeID, []byte, error) {
+func (c *client) get(
+	ctx context.Context,
+	newRequest func(keyLimit uint32, bytesLimit uint32) ([]byte, error),
+) (ids.NodeID, []byte, error) {
+	c.metrics.RequestMade()
+
 	var (
-		response []byte
 		nodeID   ids.NodeID
+		response []byte
 		err      error
 	)
-
-	c.metrics.RequestMade()
-
 	if len(c.stateSyncNodes) == 0 {
-		nodeID, response, err = c.networkClient.RequestAny(ctx, request)
+		// The network client selects the peer and sends the request to it in
+		// one step, so that concurrent requests are spread across peers.
+		nodeID, response, err = c.networkClient.RequestAny(ctx, newRequest)
 	} else {
 		// Get the next nodeID to query using the [nodeIdx] offset.
 		// If we're out of nodes, loop back to 0.
 		// We do this try to query a different node each time if possible.
 		nodeIdx := atomic.AddUint32(&c.stateSyncNodeIdx, 1)
 		nodeID = c.stateSyncNodes[nodeIdx%uint32(len(c.stateSyncNodes))]
+
+		var request []byte
+		request, err = newRequest(c.networkClient.RequestLimits(nodeID))
+		if err != nil {
+			c.metrics.RequestFailed()
+			return nodeID, nil, fmt.Errorf("%w: %w", errInvalidRequest, err)
+		}
 		response, err = c.networkClient.Request(ctx, nodeID, request)
 	}
 	if err != nil {
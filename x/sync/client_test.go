@@ -95,11 +95,15 @@ func sendRangeProofRequest(
 	})
 	require.NoError(err)
 
+	networkClient.EXPECT().RegisterInvalidResponse(serverNodeID).AnyTimes()
 	networkClient.EXPECT().RequestAny(
 		gomock.Any(), // ctx
-		gomock.Any(), // request
+		gomock.Any(), // newRequest
 	).DoAndReturn(
-		func(_ context.Context, request []byte) (ids.NodeID, []byte, error) {
+		func(_ context.Context, newRequest func(uint32, uint32) ([]byte, error)) (ids.NodeID, []byte, error) {
+			request, err := newRequest(maxKeyValuesLimit, maxByteSizeLimit)
+			require.NoError(err)
+
 			go func() {
 				// Get response from server
 				require.NoError(server.AppRequest(context.Background(), clientNodeID, 0, time.Now().Add(time.Hour), request))
@@ -394,11 +398,15 @@ func sendChangeProofRequest(
 
 	defer cancel() // avoid leaking a goroutine
 
+	networkClient.EXPECT().RegisterInvalidResponse(serverNodeID).AnyTimes()
 	networkClient.EXPECT().RequestAny(
 		gomock.Any(), // ctx
-		gomock.Any(), // request
+		gomock.Any(), // newRequest
 	).DoAndReturn(
-		func(_ context.Context, request []byte) (ids.NodeID, []byte, error) {
+		func(_ context.Context, newRequest func(uint32, uint32) ([]byte, error)) (ids.NodeID, []byte, error) {
+			request, err := newRequest(maxKeyValuesLimit, maxByteSizeLimit)
+			require.NoError(err)
+
 			go func() {
 				// Get response from server
 				require.NoError(server.AppRequest(context.Background(), clientNodeID, 0, time.Now().Add(time.Hour), request))
@@ -756,11 +764,13 @@ func TestAppRequestSendFailed(t *testing.T) {
 	)
 	require.NoError(err)
 
+	nodeID := ids.GenerateTestNodeID()
+
 	// Mock failure to send app request
 	networkClient.EXPECT().RequestAny(
 		gomock.Any(),
 		gomock.Any(),
-	).Return(ids.EmptyNodeID, nil, errAppSendFailed).Times(2)
+	).Return(nodeID, nil, errAppSendFailed).Times(2)
 
 	_, err = client.GetChangeProof(
 		context.Background(),
@@ -775,3 +785,121 @@ func TestAppRequestSendFailed(t *testing.T) {
 	)
 	require.ErrorIs(err, errAppSendFailed)
 }
+
+// Test that requests use the network client's request limits and that invalid
+// responses are reported to the network client.
+func TestRequestLimitsAndInvalidResponse(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	networkClient := NewMockNetworkClient(ctrl)
+
+	client, err := NewClient(
+		&ClientConfig{
+			NetworkClient: networkClient,
+			Log:           logging.NoLog{},
+			Metrics:       &mockMetrics{},
+			BranchFactor:  merkledb.BranchFactor16,
+		},
+	)
+	require.NoError(err)
+
+	var (
+		nodeID      = ids.GenerateTestNodeID()
+		rootID      = ids.GenerateTestID()
+		ctx, cancel = context.WithCancel(context.Background())
+		request     = &pb.SyncGetRangeProofRequest{
+			RootHash:   rootID[:],
+			StartKey:   &pb.MaybeBytes{IsNothing: true},
+			EndKey:     &pb.MaybeBytes{IsNothing: true},
+			KeyLimit:   maxKeyValuesLimit,
+			BytesLimit: maxByteSizeLimit,
+		}
+	)
+	defer cancel()
+
+	networkClient.EXPECT().RequestAny(
+		gomock.Any(),
+		gomock.Any(),
+	).DoAndReturn(
+		func(_ context.Context, newRequest func(uint32, uint32) ([]byte, error)) (ids.NodeID, []byte, error) {
+			requestBytes, err := newRequest(10, 1000)
+			require.NoError(err)
+
+			var sentRequest pb.Request
+			require.NoError(proto.Unmarshal(requestBytes, &sentRequest))
+			require.Equal(uint32(10), sentRequest.GetRangeProofRequest().KeyLimit)
+			require.Equal(uint32(1000), sentRequest.GetRangeProofRequest().BytesLimit)
+
+			// Respond with bytes that can't be parsed.
+			return nodeID, []byte{0xff}, nil
+		},
+	)
+	networkClient.EXPECT().RegisterInvalidResponse(nodeID).Do(
+		func(ids.NodeID) {
+			// Stop retrying.
+			cancel()
+		},
+	)
+
+	_, err = client.GetRangeProof(ctx, request)
+	require.ErrorIs(err, context.Canceled)
+
+	// The caller's request isn't modified.
+	require.Equal(uint32(maxKeyValuesLimit), request.KeyLimit)
+	require.Equal(uint32(maxByteSizeLimit), request.BytesLimit)
+}
+
+// Test that peers aren't reported for responses that can't be parsed because
+// of this node.
+func TestLocalErrorNotReported(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	networkClient := NewMockNetworkClient(ctrl)
+
+	client, err := NewClient(
+		&ClientConfig{
+			NetworkClient: networkClient,
+			Log:           logging.NoLog{},
+			Metrics:       &mockMetrics{},
+			BranchFactor:  merkledb.BranchFactor16,
+		},
+	)
+	require.NoError(err)
+
+	responseBytes, err := proto.Marshal(&pb.RangeProof{})
+	require.NoError(err)
+
+	var (
+		nodeID      = ids.GenerateTestNodeID()
+		ctx, cancel = context.WithCancel(context.Background())
+		numRequests int
+	)
+	defer cancel()
+
+	// RegisterInvalidResponse isn't expected to be called.
+	networkClient.EXPECT().RequestAny(
+		gomock.Any(),
+		gomock.Any(),
+	).DoAndReturn(
+		func(context.Context, func(uint32, uint32) ([]byte, error)) (ids.NodeID, []byte, error) {
+			numRequests++
+			if numRequests == 2 {
+				// Stop retrying.
+				cancel()
+			}
+			return nodeID, responseBytes, nil
+		},
+	).Times(2)
+
+	// The root of the request is invalid, so the response can't be verified.
+	_, err = client.GetRangeProof(ctx, &pb.SyncGetRangeProofRequest{
+		RootHash:   []byte{1},
+		StartKey:   &pb.MaybeBytes{IsNothing: true},
+		EndKey:     &pb.MaybeBytes{IsNothing: true},
+		KeyLimit:   maxKeyValuesLimit,
+		BytesLimit: maxByteSizeLimit,
+	})
+	require.ErrorIs(err, errInvalidRequestRoot)
+}
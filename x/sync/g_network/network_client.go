@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gnetwork
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/set"
+	"github.com/skychains/chain/version"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+	xsync "github.com/skychains/chain/x/sync"
+)
+
+var (
+	_ common.AppSender = (*requestSender)(nil)
+	_ common.AppSender = unsupportedSender{}
+
+	// The server and its clients aren't nodes, but the x/sync network client
+	// and server identify their peers by node ID.
+	clientNodeID = ids.EmptyNodeID
+	serverNodeID = ids.NodeID{1}
+
+	errUnsupportedMessage = errors.New("unsupported message")
+)
+
+// NewNetworkClient returns an [xsync.NetworkClient] whose only peer is the
+// server that [client] sends requests to.
+// Requests that aren't responded to within [requestTimeout] fail.
+func NewNetworkClient(
+	ctx context.Context,
+	client pb.NetworkClient,
+	requestTimeout time.Duration,
+	maxActiveRequests int64,
+	log logging.Logger,
+	metricsNamespace string,
+	registerer prometheus.Registerer,
+) (xsync.NetworkClient, error) {
+	sender := &requestSender{
+		client:         client,
+		requestTimeout: requestTimeout,
+		log:            log,
+	}
+	networkClient, err := xsync.NewNetworkClient(
+		sender,
+		clientNodeID,
+		maxActiveRequests,
+		log,
+		metricsNamespace,
+		registerer,
+		nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// [sender] must be given the network client before any requests are
+	// sent, because it delivers the responses to them.
+	sender.handler = networkClient
+	return networkClient, networkClient.Connected(ctx, serverNodeID, version.CurrentApp)
+}
+
+// requestSender sends the requests of an [xsync.NetworkClient] over gRPC, and
+// passes the responses back to it.
+type requestSender struct {
+	unsupportedSender
+
+	client         pb.NetworkClient
+	requestTimeout time.Duration
+	log            logging.Logger
+	handler        xsync.NetworkClient
+}
+
+func (s *requestSender) SendAppRequest(
+	ctx context.Context,
+	nodeIDs set.Set[ids.NodeID],
+	requestID uint32,
+	request []byte,
+) error {
+	for nodeID := range nodeIDs {
+		go s.request(ctx, nodeID, requestID, request)
+	}
+	return nil
+}
+
+// request sends [request] to the server, and passes its response, or the
+// failure of the request, to [s.handler].
+func (s *requestSender) request(
+	ctx context.Context,
+	nodeID ids.NodeID,
+	requestID uint32,
+	request []byte,
+) {
+	requestCtx, cancel := context.WithTimeout(ctx, s.requestTimeout)
+	defer cancel()
+
+	resp, err := s.client.Request(requestCtx, &pb.NetworkRequest{
+		Request: request,
+	})
+	if err != nil {
+		s.log.Debug("request failed",
+			zap.Stringer("nodeID", nodeID),
+			zap.Uint32("requestID", requestID),
+			zap.Error(err),
+		)
+		_ = s.handler.AppRequestFailed(ctx, nodeID, requestID)
+		return
+	}
+	_ = s.handler.AppResponse(ctx, nodeID, requestID, resp.Response)
+}
+
+// unsupportedSender fails to send every message. It's embedded to implement
+// the messages that aren't used to sync.
+type unsupportedSender struct{}
+
+func (unsupportedSender) SendAppRequest(context.Context, set.Set[ids.NodeID], uint32, []byte) error {
+	return errUnsupportedMessage
+}
+
+func (unsupportedSender) SendAppResponse(context.Context, ids.NodeID, uint32, []byte) error {
+	return errUnsupportedMessage
+}
+
+func (unsupportedSender) SendAppError(context.Context, ids.NodeID, uint32, int32, string) error {
+	return errUnsupportedMessage
+}
+
+func (unsupportedSender) SendAppGossip(context.Context, common.SendConfig, []byte) error {
+	return errUnsupportedMessage
+}
+
+func (unsupportedSender) SendCrossChainAppRequest(context.Context, ids.ID, uint32, []byte) error {
+	return errUnsupportedMessage
+}
+
+func (unsupportedSender) SendCrossChainAppResponse(context.Context, ids.ID, uint32, []byte) error {
+	return errUnsupportedMessage
+}
+
+func (unsupportedSender) SendCrossChainAppError(context.Context, ids.ID, uint32, int32, string) error {
+	return errUnsupportedMessage
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gnetwork
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/utils/logging"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+	xsync "github.com/skychains/chain/x/sync"
+)
+
+// defaultRequestTimeout is how long the server has to respond to a request
+// that doesn't have a deadline.
+const defaultRequestTimeout = 10 * time.Second
+
+var (
+	_ pb.NetworkServer = (*NetworkServer)(nil)
+	_ common.AppSender = (*responseSender)(nil)
+
+	errNoResponse = errors.New("server didn't respond to the request")
+)
+
+// NetworkServer serves the requests of x/sync clients over gRPC with an
+// [xsync.NetworkServer], as if they were AppRequests from a peer.
+type NetworkServer struct {
+	pb.UnsafeNetworkServer
+
+	server *xsync.NetworkServer
+	sender *responseSender
+}
+
+func NewNetworkServer(db xsync.DB, log logging.Logger) *NetworkServer {
+	sender := &responseSender{
+		responses: make(map[uint32]chan []byte),
+	}
+	return &NetworkServer{
+		server: xsync.NewNetworkServer(sender, db, log),
+		sender: sender,
+	}
+}
+
+func (s *NetworkServer) Request(
+	ctx context.Context,
+	req *pb.NetworkRequest,
+) (*pb.NetworkResponse, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultRequestTimeout)
+	}
+
+	requestID, responseChan := s.sender.register()
+	defer s.sender.unregister(requestID)
+
+	if err := s.server.AppRequest(ctx, clientNodeID, requestID, deadline, req.Request); err != nil {
+		return nil, err
+	}
+
+	// Requests are handled synchronously, so if there isn't a response, the
+	// request was dropped.
+	select {
+	case response := <-responseChan:
+		return &pb.NetworkResponse{
+			Response: response,
+		}, nil
+	default:
+		return nil, errNoResponse
+	}
+}
+
+// responseSender passes the responses of an [xsync.NetworkServer] to the
+// gRPC requests that are awaiting them.
+type responseSender struct {
+	unsupportedSender
+
+	lock          sync.Mutex
+	nextRequestID uint32
+	// requestID -> channel the response to the request is sent on
+	responses map[uint32]chan []byte
+}
+
+// register returns the ID to handle a request with, and the channel its
+// response will be sent on.
+func (s *responseSender) register() (uint32, chan []byte) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	requestID := s.nextRequestID
+	s.nextRequestID++
+
+	// The channel is buffered so that responding never blocks.
+	responseChan := make(chan []byte, 1)
+	s.responses[requestID] = responseChan
+	return requestID, responseChan
+}
+
+func (s *responseSender) unregister(requestID uint32) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.responses, requestID)
+}
+
+func (s *responseSender) SendAppResponse(
+	_ context.Context,
+	_ ids.NodeID,
+	requestID uint32,
+	response []byte,
+) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	responseChan, ok := s.responses[requestID]
+	if !ok {
+		// The request is no longer being awaited.
+		return nil
+	}
+
+	select {
+	case responseChan <- response:
+	default:
+		// Only the first response to a request is used.
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package gnetwork
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/skychains/chain/database/memdb"
+	"github.com/skychains/chain/trace"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/vms/rpcchainvm/grpcutils"
+	"github.com/skychains/chain/x/merkledb"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+	xsync "github.com/skychains/chain/x/sync"
+)
+
+func newDB(t *testing.T) merkledb.MerkleDB {
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		merkledb.Config{
+			BranchFactor:                merkledb.BranchFactor16,
+			Hasher:                      merkledb.DefaultHasher,
+			HistoryLength:               100,
+			ValueNodeCacheSize:          1_000,
+			IntermediateNodeCacheSize:   1_000,
+			IntermediateWriteBufferSize: 1_000,
+			IntermediateWriteBatchSize:  100,
+			Reg:                         prometheus.NewRegistry(),
+			Tracer:                      trace.Noop,
+		},
+	)
+	require.NoError(t, err)
+	return db
+}
+
+// Returns a client of a server that serves [db], which is stopped when the
+// test completes.
+func newTestNetworkClient(t *testing.T, db xsync.DB) pb.NetworkClient {
+	require := require.New(t)
+
+	listener, err := grpcutils.NewListener()
+	require.NoError(err)
+
+	server := grpcutils.NewServer()
+	pb.RegisterNetworkServer(server, NewNetworkServer(db, logging.NoLog{}))
+	go grpcutils.Serve(listener, server)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpcutils.Dial(listener.Addr().String())
+	require.NoError(err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return pb.NewNetworkClient(conn)
+}
+
+func TestNetworkSync(t *testing.T) {
+	require := require.New(t)
+
+	ctx := context.Background()
+	serverDB := newDB(t)
+	r := rand.New(rand.NewSource(0)) // #nosec G404
+	batch := serverDB.NewBatch()
+	for i := 0; i < 1_000; i++ {
+		key := make([]byte, r.Intn(32)+1)
+		_, _ = r.Read(key)
+		value := make([]byte, r.Intn(64))
+		_, _ = r.Read(value)
+		require.NoError(batch.Put(key, value))
+	}
+	require.NoError(batch.Write())
+
+	root, err := serverDB.GetMerkleRoot(ctx)
+	require.NoError(err)
+
+	reg := prometheus.NewRegistry()
+	networkClient, err := NewNetworkClient(
+		ctx,
+		newTestNetworkClient(t, serverDB),
+		time.Minute,
+		10,
+		logging.NoLog{},
+		"",
+		reg,
+	)
+	require.NoError(err)
+
+	syncMetrics, err := xsync.NewMetrics("", reg)
+	require.NoError(err)
+
+	client, err := xsync.NewClient(&xsync.ClientConfig{
+		NetworkClient: networkClient,
+		Log:           logging.NoLog{},
+		Metrics:       syncMetrics,
+		BranchFactor:  merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+
+	clientDB := newDB(t)
+	manager, err := xsync.NewManager(xsync.ManagerConfig{
+		DB:                    clientDB,
+		Client:                client,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		TargetRoot:            root,
+		BranchFactor:          merkledb.BranchFactor16,
+	})
+	require.NoError(err)
+	require.NoError(manager.Start(ctx))
+	require.NoError(manager.Wait(ctx))
+
+	clientRoot, err := clientDB.GetMerkleRoot(ctx)
+	require.NoError(err)
+	require.Equal(root, clientRoot)
+}
+
+func TestNetworkServerDroppedRequest(t *testing.T) {
+	client := newTestNetworkClient(t, newDB(t))
+
+	// The request can't be parsed, so it's dropped by the server.
+	_, err := client.Request(context.Background(), &pb.NetworkRequest{
+		Request: []byte{1},
+	})
+	require.ErrorContains(t, err, errNoResponse.Error())
+}
@@ -10,10 +10,12 @@ import (
 	"fmt"
 	"slices"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"golang.org/x/exp/maps"
 
+	"github.com/skychains/chain/database"
 	"github.com/skychains/chain/ids"
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/maybe"
@@ -26,6 +28,8 @@ import (
 const (
 	defaultRequestKeyLimit      = maxKeyValuesLimit
 	defaultRequestByteSizeLimit = maxByteSizeLimit
+
+	DefaultCheckpointFrequency = 10 * time.Second
 )
 
 var (
@@ -88,6 +92,19 @@ type Manager struct {
 	unprocessedWorkCond sync.Cond
 	// [workLock] must be held while accessing [processedWork].
 	processedWork *workHeap
+	// The work items currently being processed, as they were when processing
+	// started.
+	// [workLock] must be held while accessing [processingWork].
+	processingWork map[*workItem]workItem
+	// The target root of the checkpoint in [config.CheckpointDB].
+	// [workLock] must be held while accessing [checkpointRoot].
+	checkpointRoot ids.ID
+	// When the checkpoint was last written.
+	// [workLock] must be held while accessing [lastCheckpoint].
+	lastCheckpoint time.Time
+	// True if work has been completed since the checkpoint was last written.
+	// [workLock] must be held while accessing [checkpointStale].
+	checkpointStale bool
 
 	// When this is closed:
 	// - [closed] is true.
@@ -116,6 +133,17 @@ type ManagerConfig struct {
 	Log                   logging.Logger
 	TargetRoot            ids.ID
 	BranchFactor          merkledb.BranchFactor
+	// If non-nil, the progress of the sync is checkpointed to CheckpointDB so
+	// that it can be resumed after a restart, even if the target root has
+	// changed. CheckpointDB must only be used by the Manager syncing [DB], and
+	// must not be [DB] itself.
+	// The checkpoint is removed once the sync completes.
+	CheckpointDB database.Database
+	// CheckpointFrequency is the minimum time between rewrites of the
+	// checkpoint as work is completed. The checkpoint is always rewritten
+	// when the target root changes and when the Manager is closed.
+	// If zero, DefaultCheckpointFrequency is used.
+	CheckpointFrequency time.Duration
 }
 
 func NewManager(config ManagerConfig) (*Manager, error) {
@@ -132,12 +160,16 @@ func NewManager(config ManagerConfig) (*Manager, error) {
 	if err := config.BranchFactor.Valid(); err != nil {
 		return nil, err
 	}
+	if config.CheckpointFrequency == 0 {
+		config.CheckpointFrequency = DefaultCheckpointFrequency
+	}
 
 	m := &Manager{
 		config:          config,
 		doneChan:        make(chan struct{}),
 		unprocessedWork: newWorkHeap(),
 		processedWork:   newWorkHeap(),
+		processingWork:  make(map[*workItem]workItem),
 		tokenSize:       merkledb.BranchFactorToTokenSize[config.BranchFactor],
 	}
 	m.unprocessedWorkCond.L = &m.workLock
@@ -155,9 +187,9 @@ func (m *Manager) Start(ctx context.Context) error {
 
 	m.config.Log.Info("starting sync", zap.Stringer("target root", m.config.TargetRoot))
 
-	// Add work item to fetch the entire key range.
-	// Note that this will be the first work item to be processed.
-	m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+	if err := m.initializeWork(); err != nil {
+		return err
+	}
 
 	m.syncing = true
 	ctx, m.cancelCtx = context.WithCancel(ctx)
@@ -166,6 +198,118 @@ func (m *Manager) Start(ctx context.Context) error {
 	return nil
 }
 
+// initializeWork adds the work needed to sync to the target root.
+// If there is a checkpoint, the work is restored from it. Otherwise, the
+// entire key range will be fetched.
+// Assumes [m.workLock] is held.
+func (m *Manager) initializeWork() error {
+	if m.config.CheckpointDB == nil {
+		// Add work item to fetch the entire key range.
+		// Note that this will be the first work item to be processed.
+		m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+		return nil
+	}
+
+	checkpointRoot, c, err := getCheckpoint(m.config.CheckpointDB)
+	if err == database.ErrNotFound {
+		m.unprocessedWork.Insert(newWorkItem(ids.Empty, maybe.Nothing[[]byte](), maybe.Nothing[[]byte](), lowPriority))
+		m.checkpointRoot = m.config.TargetRoot
+		return m.checkpoint(m.config.TargetRoot)
+	}
+	if err != nil {
+		return err
+	}
+
+	m.config.Log.Info("resuming sync from checkpoint",
+		zap.Stringer("checkpoint root", checkpointRoot),
+		zap.Int("numProcessed", len(c.processed)),
+		zap.Int("numOutstanding", len(c.outstanding)),
+	)
+
+	for _, item := range c.processed {
+		if checkpointRoot == m.config.TargetRoot {
+			m.processedWork.MergeInsert(item)
+			continue
+		}
+
+		// The target root has changed since the checkpoint, so the processed
+		// ranges must be updated.
+		item.priority = highPriority
+		m.unprocessedWork.Insert(item)
+	}
+	for _, item := range c.outstanding {
+		// Outstanding ranges may have been partially updated after the
+		// checkpoint was written, so applying a change proof to them may not
+		// result in the expected root. Fetch them in their entirety instead.
+		item.localRootID = ids.Empty
+		m.unprocessedWork.Insert(item)
+	}
+
+	m.checkpointRoot = checkpointRoot
+	return m.checkpoint(m.config.TargetRoot)
+}
+
+// checkpoint writes the progress towards [targetRoot] to
+// [m.config.CheckpointDB], if it's set.
+// Assumes [m.workLock] is held.
+func (m *Manager) checkpoint(targetRoot ids.ID) error {
+	if m.config.CheckpointDB == nil {
+		return nil
+	}
+
+	select {
+	case <-m.doneChan:
+		// Work items aren't added to the closed work heaps, so the current
+		// progress may be incomplete. Keep the last checkpoint.
+		return nil
+	default:
+	}
+
+	c := &checkpoint{
+		processed:   m.processedWork.Items(),
+		outstanding: m.unprocessedWork.Items(),
+	}
+	for _, work := range m.processingWork {
+		work := work
+		c.outstanding = append(c.outstanding, &work)
+	}
+	if err := putCheckpoint(m.config.CheckpointDB, m.checkpointRoot, targetRoot, c); err != nil {
+		return err
+	}
+	m.checkpointRoot = targetRoot
+	m.lastCheckpoint = time.Now()
+	m.checkpointStale = false
+	return nil
+}
+
+// maybeCheckpoint records that work was completed and writes the progress
+// towards the current target root to [m.config.CheckpointDB], if it's set and
+// [m.config.CheckpointFrequency] has passed since the checkpoint was last
+// written. Rewriting the checkpoint is linear in the number of work items, so
+// it isn't rewritten every time a work item is completed.
+// Assumes [m.workLock] and [m.syncTargetLock] are held.
+func (m *Manager) maybeCheckpoint() error {
+	if m.config.CheckpointDB == nil {
+		return nil
+	}
+
+	m.checkpointStale = true
+	if time.Since(m.lastCheckpoint) < m.config.CheckpointFrequency {
+		return nil
+	}
+	return m.checkpoint(m.config.TargetRoot)
+}
+
+// deleteCheckpoint removes the checkpoint from [m.config.CheckpointDB], if
+// it's set.
+// Assumes [m.workLock] is held.
+func (m *Manager) deleteCheckpoint() error {
+	if m.config.CheckpointDB == nil {
+		return nil
+	}
+	return m.config.CheckpointDB.Delete(m.checkpointRoot[:])
+}
+
 // sync awaits signal on [m.unprocessedWorkCond], which indicates that there
 // is work to do or syncing completes.  If there is work, sync will dispatch a goroutine to do
 // the work.
@@ -191,6 +335,13 @@ func (m *Manager) sync(ctx context.Context) {
 			if m.processingWorkItems == 0 {
 				// There's no work to do, and there are no work items being processed
 				// which could cause work to be added, so we're done.
+				// If there was a fatal error, work may have been dropped, so
+				// the checkpoint is kept.
+				if m.Error() == nil {
+					if err := m.deleteCheckpoint(); err != nil {
+						m.setError(err)
+					}
+				}
 				return // [m.workLock] released by defer.
 			}
 			// There's no work to do.
@@ -201,6 +352,7 @@ func (m *Manager) sync(ctx context.Context) {
 		default:
 			m.processingWorkItems++
 			work := m.unprocessedWork.GetWork()
+			m.processingWork[work] = *work
 			go m.doWork(ctx, work)
 		}
 	}
@@ -208,9 +360,21 @@ func (m *Manager) sync(ctx context.Context) {
 
 // Close will stop the syncing process
 func (m *Manager) Close() {
+	m.syncTargetLock.RLock()
+	defer m.syncTargetLock.RUnlock()
+
 	m.workLock.Lock()
 	defer m.workLock.Unlock()
 
+	// Write the work completed since the last checkpoint so that it isn't
+	// fetched again when the sync is resumed.
+	if m.checkpointStale {
+		if err := m.checkpoint(m.config.TargetRoot); err != nil {
+			m.config.Log.Warn("failed to write checkpoint",
+				zap.Error(err),
+			)
+		}
+	}
 	m.close()
 }
 
@@ -640,7 +804,7 @@ func (m *Manager) UpdateSyncTarget(syncTargetRoot ids.ID) error {
 		// waiting on [m.unprocessedWorkCond].
 		m.unprocessedWorkCond.Signal()
 	}
-	return nil
+	return m.checkpoint(syncTargetRoot)
 }
 
 func (m *Manager) getTargetRoot() ids.ID {
@@ -676,6 +840,7 @@ func (m *Manager) setError(err error) {
 //
 // Assumes [m.workLock] is not held.
 func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestHandledKey maybe.Maybe[[]byte], rootID ids.ID, proofOfLargestKey []merkledb.ProofNode) {
+	var nextWork *workItem
 	if !maybe.Equal(largestHandledKey, work.end, bytes.Equal) {
 		// The largest handled key isn't equal to the end of the work item.
 		// Find the start of the next key range to fetch.
@@ -695,7 +860,7 @@ func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestH
 			largestHandledKey = work.end
 		} else {
 			// the full range wasn't completed, so enqueue a new work item for the range [nextStartKey, workItem.end]
-			m.enqueueWork(newWorkItem(work.localRootID, nextStartKey, work.end, work.priority))
+			nextWork = newWorkItem(work.localRootID, nextStartKey, work.end, work.priority)
 			largestHandledKey = nextStartKey
 		}
 	}
@@ -705,17 +870,32 @@ func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestH
 	m.syncTargetLock.RLock()
 	defer m.syncTargetLock.RUnlock()
 
+	// The remaining work and [work] are updated while holding [workLock] so
+	// that a checkpoint never contains overlapping ranges.
+	m.workLock.Lock()
+	defer func() {
+		m.workLock.Unlock()
+		m.unprocessedWorkCond.Signal()
+	}()
+
+	if nextWork != nil {
+		m.insertWork(nextWork)
+	}
+
 	stale := m.config.TargetRoot != rootID
 	if stale {
 		// the root has changed, so reinsert with high priority
-		m.enqueueWork(newWorkItem(rootID, work.start, largestHandledKey, highPriority))
+		m.insertWork(newWorkItem(rootID, work.start, largestHandledKey, highPriority))
 	} else {
-		m.workLock.Lock()
-		defer m.workLock.Unlock()
-
 		m.processedWork.MergeInsert(newWorkItem(rootID, work.start, largestHandledKey, work.priority))
 	}
 
+	delete(m.processingWork, work)
+	if err := m.maybeCheckpoint(); err != nil {
+		m.setError(err)
+		return
+	}
+
 	// completed the range [work.start, lastKey], log and record in the completed work heap
 	m.config.Log.Debug("completed range",
 		zap.Stringer("start", work.start),
@@ -728,14 +908,8 @@ func (m *Manager) completeWorkItem(ctx context.Context, work *workItem, largestH
 // Queue the given key range to be fetched and applied.
 // If there are sufficiently few unprocessed/processing work items,
 // splits the range into two items and queues them both.
-// Assumes [m.workLock] is not held.
-func (m *Manager) enqueueWork(work *workItem) {
-	m.workLock.Lock()
-	defer func() {
-		m.workLock.Unlock()
-		m.unprocessedWorkCond.Signal()
-	}()
-
+// Assumes [m.workLock] is held.
+func (m *Manager) insertWork(work *workItem) {
 	if m.processingWorkItems+m.unprocessedWork.Len() > 2*m.config.SimultaneousWorkLimit {
 		// There are too many work items already, don't split the range
 		m.unprocessedWork.Insert(work)
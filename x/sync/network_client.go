@@ -20,10 +20,22 @@ import (
 	"github.com/skychains/chain/utils/logging"
 	"github.com/skychains/chain/utils/set"
 	"github.com/skychains/chain/version"
+
+	safemath "github.com/skychains/chain/utils/math"
 )
 
-// Minimum amount of time to handle a request
-const minRequestHandlingDuration = 100 * time.Millisecond
+const (
+	// Minimum amount of time to handle a request
+	minRequestHandlingDuration = 100 * time.Millisecond
+
+	peerStatsHalflife = 5 * time.Minute
+
+	// A peer that sends an invalid response isn't selected for
+	// [initialBenchDuration], doubled for each invalid response it has sent,
+	// up to [maxBenchDuration].
+	initialBenchDuration = 10 * time.Second
+	maxBenchDuration     = 10 * time.Minute
+)
 
 var (
 	_ NetworkClient = (*networkClient)(nil)
@@ -35,13 +47,19 @@ var (
 
 // NetworkClient defines ability to send request / response through the Network
 type NetworkClient interface {
-	// RequestAny synchronously sends request to an arbitrary peer with a
-	// node version greater than or equal to minVersion.
-	// Returns response bytes, the ID of the chosen peer, and ErrRequestFailed if
-	// the request should be retried.
+	// RequestAny synchronously sends the request returned by [newRequest],
+	// given the request limits of the peer it's sent to, to the peer that the
+	// next request should be sent to, preferring fast peers that aren't busy
+	// with other requests.
+	// The peer is selected and the request is registered with it in one step,
+	// so concurrent requests are spread across peers.
+	// Returns the ID of the chosen peer, response bytes, and ErrRequestFailed
+	// if the request should be retried.
+	// Returns [errNoPeers] if there are no connected peers and
+	// [errInvalidRequest] if [newRequest] fails.
 	RequestAny(
 		ctx context.Context,
-		request []byte,
+		newRequest func(keyLimit uint32, bytesLimit uint32) ([]byte, error),
 	) (ids.NodeID, []byte, error)
 
 	// Sends [request] to [nodeID] and returns the response.
@@ -71,6 +89,16 @@ type NetworkClient interface {
 
 	// Removes given [nodeID] from the peer list.
 	Disconnected(context.Context, ids.NodeID) error
+
+	// RegisterInvalidResponse records that [nodeID] sent a response that
+	// couldn't be parsed or verified, so that the peer is less likely to be
+	// sent requests.
+	RegisterInvalidResponse(nodeID ids.NodeID)
+
+	// RequestLimits returns the key and byte limits that requests to [nodeID]
+	// should use so that responses are received promptly, based on the
+	// observed throughput of the peer.
+	RequestLimits(nodeID ids.NodeID) (keyLimit uint32, bytesLimit uint32)
 }
 
 type networkClient struct {
@@ -84,8 +112,108 @@ type networkClient struct {
 	activeRequests *semaphore.Weighted
 	// tracking of peers & bandwidth usage
 	peers *p2p.PeerTracker
+	// How the peers we've sent requests to have responded.
+	// [lock] must be held when accessing [peerStats].
+	peerStats map[ids.NodeID]*peerStats
 	// For sending messages to peers
 	appSender common.AppSender
+	metrics   networkClientMetrics
+}
+
+// peerStats tracks how a peer has responded to the requests sent to it.
+//
+// The stats of a peer are removed when it disconnects. Responses to requests
+// that were sent before then are recorded in the removed stats, so that they
+// don't affect the stats of the peer once it reconnects.
+type peerStats struct {
+	// The number of requests sent to the peer that haven't been responded to.
+	numOutstandingRequests int
+	// Average time, in seconds, for the peer to respond to a request.
+	latency safemath.Averager
+	// Average bandwidth, in bytes per second, of the peer's responses.
+	bandwidth safemath.Averager
+	// The number of responses from the peer that were invalid.
+	numInvalidResponses int
+	// The peer is only selected before this time if it's the only option.
+	benchedUntil time.Time
+	// Adapts the limits of requests to the peer to its response times.
+	requestLimiter *requestLimiter
+}
+
+func newPeerStats() *peerStats {
+	return &peerStats{
+		latency:        safemath.NewUninitializedAverager(peerStatsHalflife),
+		bandwidth:      safemath.NewUninitializedAverager(peerStatsHalflife),
+		requestLimiter: newRequestLimiter(),
+	}
+}
+
+func (s *peerStats) isBenched(now time.Time) bool {
+	return now.Before(s.benchedUntil)
+}
+
+// score returns how preferable it is to send a request to the peer.
+// Peers with a higher bandwidth and a lower latency are preferred, and peers
+// that have sent invalid responses or that are busy with other requests are
+// penalized. Benched peers have a negative score.
+func (s *peerStats) score(now time.Time) float64 {
+	if s.isBenched(now) {
+		return -1
+	}
+	penalty := (1 + s.latency.Read()) * float64((1+s.numInvalidResponses)*(1+s.numOutstandingRequests))
+	return s.bandwidth.Read() / penalty
+}
+
+type networkClientMetrics struct {
+	requestLatency   prometheus.Histogram
+	invalidResponses prometheus.Counter
+	numBenchedPeers  prometheus.Gauge
+	requestKeyLimit  prometheus.Gauge
+	requestByteLimit prometheus.Gauge
+}
+
+func newNetworkClientMetrics(namespace string, registerer prometheus.Registerer) (networkClientMetrics, error) {
+	m := networkClientMetrics{
+		requestLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_latency",
+			Help:      "time (in seconds) for peers to respond to requests",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		invalidResponses: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "invalid_responses",
+			Help:      "cumulative amount of responses from peers that were invalid",
+		}),
+		numBenchedPeers: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "num_benched_peers",
+			Help:      "number of peers that aren't being sent requests because they sent invalid responses",
+		}),
+		requestKeyLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "request_key_limit",
+			Help:      "maximum number of keys requested in the most recent proof request",
+		}),
+		requestByteLimit: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "request_byte_limit",
+			Help:      "maximum number of bytes requested in the most recent proof request",
+		}),
+	}
+	err := errors.Join(
+		registerer.Register(m.requestLatency),
+		registerer.Register(m.invalidResponses),
+		registerer.Register(m.numBenchedPeers),
+		registerer.Register(m.requestKeyLimit),
+		registerer.Register(m.requestByteLimit),
+	)
+	return m, err
+}
+
+func (m *networkClientMetrics) setRequestLimits(keyLimit uint32, bytesLimit uint32) {
+	m.requestKeyLimit.Set(float64(keyLimit))
+	m.requestByteLimit.Set(float64(bytesLimit))
 }
 
 func NewNetworkClient(
@@ -108,12 +236,21 @@ func NewNetworkClient(
 		return nil, fmt.Errorf("failed to create peer tracker: %w", err)
 	}
 
+	metrics, err := newNetworkClientMetrics(metricsNamespace, registerer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics: %w", err)
+	}
+
+	metrics.setRequestLimits(uint32(maxKeyValuesLimit), uint32(maxByteSizeLimit))
+
 	return &networkClient{
 		appSender:                  appSender,
 		outstandingRequestHandlers: make(map[uint32]ResponseHandler),
 		activeRequests:             semaphore.NewWeighted(maxActiveRequests),
 		peers:                      peerTracker,
+		peerStats:                  make(map[ids.NodeID]*peerStats),
 		log:                        log,
+		metrics:                    metrics,
 	}, nil
 }
 
@@ -194,7 +331,7 @@ func (c *networkClient) getRequestHandler(requestID uint32) (ResponseHandler, bo
 // If [errAppSendFailed] is returned this should be considered fatal.
 func (c *networkClient) RequestAny(
 	ctx context.Context,
-	request []byte,
+	newRequest func(keyLimit uint32, bytesLimit uint32) ([]byte, error),
 ) (ids.NodeID, []byte, error) {
 	// Take a slot from total [activeRequests] and block until a slot becomes available.
 	if err := c.activeRequests.Acquire(ctx, 1); err != nil {
@@ -202,30 +339,75 @@ func (c *networkClient) RequestAny(
 	}
 	defer c.activeRequests.Release(1)
 
-	nodeID, responseChan, err := c.sendRequestAny(ctx, request)
+	nodeID, stats, responseChan, err := c.sendRequestAny(ctx, newRequest)
 	if err != nil {
-		return ids.EmptyNodeID, nil, err
+		return nodeID, nil, err
 	}
 
-	response, err := c.awaitResponse(ctx, nodeID, responseChan)
+	response, err := c.awaitResponse(ctx, nodeID, stats, responseChan)
 	return nodeID, response, err
 }
 
+// sendRequestAny selects the peer to send a request to and sends it the
+// request returned by [newRequest] while holding [c.lock], so that the request
+// is counted in the peer's stats before another peer is selected.
 func (c *networkClient) sendRequestAny(
 	ctx context.Context,
-	request []byte,
-) (ids.NodeID, chan []byte, error) {
+	newRequest func(keyLimit uint32, bytesLimit uint32) ([]byte, error),
+) (ids.NodeID, *peerStats, chan []byte, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
+	nodeID, ok := c.selectPeer()
+	if !ok {
+		return ids.EmptyNodeID, nil, nil, errNoPeers
+	}
+
+	request, err := newRequest(c.requestLimits(nodeID))
+	if err != nil {
+		return nodeID, nil, nil, fmt.Errorf("%w: %w", errInvalidRequest, err)
+	}
+
+	stats, responseChan, err := c.sendRequestLocked(ctx, nodeID, request)
+	return nodeID, stats, responseChan, err
+}
+
+// selectPeer returns the peer to send a request to.
+//
+// If [c.peers] selects a peer that we haven't sent a request to, it's
+// returned so that new peers are discovered. Otherwise, the peer with the
+// highest score is returned. Benched peers are only returned if all of the
+// peers we've sent requests to are benched.
+//
+// Returns false if there are no connected peers.
+//
+// Assumes [c.lock] is held.
+func (c *networkClient) selectPeer() (ids.NodeID, bool) {
 	nodeID, ok := c.peers.SelectPeer()
 	if !ok {
-		numPeers := c.peers.Size()
-		return ids.EmptyNodeID, nil, fmt.Errorf("no peers found from %d peers", numPeers)
+		return ids.EmptyNodeID, false
+	}
+	stats, ok := c.peerStats[nodeID]
+	if !ok {
+		return nodeID, true
 	}
 
-	responseChan, err := c.sendRequestLocked(ctx, nodeID, request)
-	return nodeID, responseChan, err
+	var (
+		now        = time.Now()
+		bestScore  = stats.score(now)
+		numBenched int
+	)
+	for peerID, stats := range c.peerStats {
+		if stats.isBenched(now) {
+			numBenched++
+		}
+		if score := stats.score(now); score > bestScore {
+			nodeID = peerID
+			bestScore = score
+		}
+	}
+	c.metrics.numBenchedPeers.Set(float64(numBenched))
+	return nodeID, true
 }
 
 // If [errAppSendFailed] is returned this should be considered fatal.
@@ -241,27 +423,27 @@ func (c *networkClient) Request(
 	}
 	defer c.activeRequests.Release(1)
 
-	responseChan, err := c.sendRequest(ctx, nodeID, request)
+	stats, responseChan, err := c.sendRequest(ctx, nodeID, request)
 	if err != nil {
 		return nil, err
 	}
 
-	return c.awaitResponse(ctx, nodeID, responseChan)
+	return c.awaitResponse(ctx, nodeID, stats, responseChan)
 }
 
 func (c *networkClient) sendRequest(
 	ctx context.Context,
 	nodeID ids.NodeID,
 	request []byte,
-) (chan []byte, error) {
+) (*peerStats, chan []byte, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
 	return c.sendRequestLocked(ctx, nodeID, request)
 }
 
-// Sends [request] to [nodeID] and returns a channel that will populate the
-// response.
+// Sends [request] to [nodeID] and returns the stats that the response should
+// be recorded in and a channel that will populate the response.
 //
 // If [errAppSendFailed] is returned this should be considered fatal.
 //
@@ -273,7 +455,7 @@ func (c *networkClient) sendRequestLocked(
 	ctx context.Context,
 	nodeID ids.NodeID,
 	request []byte,
-) (chan []byte, error) {
+) (*peerStats, chan []byte, error) {
 	requestID := c.requestID
 	c.requestID++
 
@@ -284,6 +466,13 @@ func (c *networkClient) sendRequestLocked(
 	)
 	c.peers.RegisterRequest(nodeID)
 
+	stats, ok := c.peerStats[nodeID]
+	if !ok {
+		stats = newPeerStats()
+		c.peerStats[nodeID] = stats
+	}
+	stats.numOutstandingRequests++
+
 	// Send an app request to the peer.
 	nodeIDs := set.Of(nodeID)
 	// Cancellation is removed from this context to avoid erroring unexpectedly.
@@ -301,15 +490,16 @@ func (c *networkClient) sendRequestLocked(
 			zap.Int("requestLen", len(request)),
 			zap.Error(err),
 		)
-		return nil, fmt.Errorf("%w: %w", errAppSendFailed, err)
+		return nil, nil, fmt.Errorf("%w: %w", errAppSendFailed, err)
 	}
 
 	handler := newResponseHandler()
 	c.outstandingRequestHandlers[requestID] = handler
-	return handler.responseChan, nil
+	return stats, handler.responseChan, nil
 }
 
-// awaitResponse from [nodeID] and returns the response.
+// awaitResponse from [nodeID] and returns the response. The response, or the
+// failure, is recorded in [stats].
 //
 // Returns an error if the request failed or [ctx] is canceled.
 //
@@ -322,6 +512,7 @@ func (c *networkClient) sendRequestLocked(
 func (c *networkClient) awaitResponse(
 	ctx context.Context,
 	nodeID ids.NodeID,
+	stats *peerStats,
 	responseChan chan []byte,
 ) ([]byte, error) {
 	var (
@@ -332,17 +523,21 @@ func (c *networkClient) awaitResponse(
 	select {
 	case <-ctx.Done():
 		c.peers.RegisterFailure(nodeID)
+		c.registerCanceled(stats)
 		return nil, ctx.Err()
 	case response, responded = <-responseChan:
 	}
 	if !responded {
 		c.peers.RegisterFailure(nodeID)
+		c.registerFailure(stats)
 		return nil, errRequestFailed
 	}
 
-	elapsedSeconds := time.Since(startTime).Seconds()
+	elapsed := time.Since(startTime)
+	elapsedSeconds := elapsed.Seconds()
 	bandwidth := float64(len(response)) / (elapsedSeconds + epsilon)
 	c.peers.RegisterResponse(nodeID, bandwidth)
+	c.registerResponse(stats, elapsed, bandwidth)
 
 	c.log.Debug("received response from peer",
 		zap.Stringer("nodeID", nodeID),
@@ -351,6 +546,93 @@ func (c *networkClient) awaitResponse(
 	return response, nil
 }
 
+// registerResponse records, in the [stats] of the peer that a request was
+// sent to, that the peer responded after [elapsed] with [bandwidth] bytes per
+// second.
+//
+// Assumes [c.lock] is not held.
+func (c *networkClient) registerResponse(stats *peerStats, elapsed time.Duration, bandwidth float64) {
+	c.metrics.requestLatency.Observe(elapsed.Seconds())
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	stats.numOutstandingRequests--
+	stats.latency.Observe(elapsed.Seconds(), now)
+	stats.bandwidth.Observe(bandwidth, now)
+	stats.requestLimiter.RegisterResponse(elapsed)
+}
+
+// registerFailure records, in the [stats] of the peer that a request was sent
+// to, that the request failed.
+//
+// Assumes [c.lock] is not held.
+func (c *networkClient) registerFailure(stats *peerStats) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats.numOutstandingRequests--
+	stats.bandwidth.Observe(0, time.Now())
+	stats.requestLimiter.RegisterFailure()
+}
+
+// registerCanceled records, in the [stats] of the peer that a request was sent
+// to, that the request was canceled before the peer responded.
+//
+// Assumes [c.lock] is not held.
+func (c *networkClient) registerCanceled(stats *peerStats) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats.numOutstandingRequests--
+}
+
+func (c *networkClient) RegisterInvalidResponse(nodeID ids.NodeID) {
+	c.peers.RegisterFailure(nodeID)
+	c.metrics.invalidResponses.Inc()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stats, ok := c.peerStats[nodeID]
+	if !ok {
+		return
+	}
+	stats.numInvalidResponses++
+
+	benchDuration := maxBenchDuration
+	if shift := stats.numInvalidResponses - 1; shift < 16 {
+		benchDuration = min(initialBenchDuration<<shift, maxBenchDuration)
+	}
+	stats.benchedUntil = time.Now().Add(benchDuration)
+
+	c.log.Debug("benching peer",
+		zap.Stringer("nodeID", nodeID),
+		zap.Int("numInvalidResponses", stats.numInvalidResponses),
+		zap.Duration("duration", benchDuration),
+	)
+}
+
+func (c *networkClient) RequestLimits(nodeID ids.NodeID) (uint32, uint32) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.requestLimits(nodeID)
+}
+
+// Assumes [c.lock] is held.
+func (c *networkClient) requestLimits(nodeID ids.NodeID) (uint32, uint32) {
+	// Requests to peers that haven't been sent a request use the maximum
+	// limits.
+	keyLimit, bytesLimit := uint32(maxKeyValuesLimit), uint32(maxByteSizeLimit)
+	if stats, ok := c.peerStats[nodeID]; ok {
+		keyLimit, bytesLimit = stats.requestLimiter.Limits()
+	}
+	c.metrics.setRequestLimits(keyLimit, bytesLimit)
+	return keyLimit, bytesLimit
+}
+
 func (c *networkClient) Connected(
 	_ context.Context,
 	nodeID ids.NodeID,
@@ -364,5 +646,10 @@ func (c *networkClient) Connected(
 func (c *networkClient) Disconnected(_ context.Context, nodeID ids.NodeID) error {
 	c.log.Debug("disconnecting peer", zap.Stringer("nodeID", nodeID))
 	c.peers.Disconnected(nodeID)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.peerStats, nodeID)
 	return nil
 }
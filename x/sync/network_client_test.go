@@ -0,0 +1,220 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/snow/engine/common"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/version"
+
+	safemath "github.com/skychains/chain/utils/math"
+)
+
+func newTestNetworkClient(t *testing.T) *networkClient {
+	ctrl := gomock.NewController(t)
+
+	c, err := NewNetworkClient(
+		common.NewMockSender(ctrl),
+		ids.GenerateTestNodeID(),
+		1,
+		logging.NoLog{},
+		"",
+		prometheus.NewRegistry(),
+		nil,
+	)
+	require.NoError(t, err)
+	return c.(*networkClient)
+}
+
+// Adds [nodeID] as a peer that has been sent a request and that responded
+// with [bandwidth] after [latency] seconds.
+func addTestPeer(t *testing.T, c *networkClient, nodeID ids.NodeID, bandwidth float64, latency float64) {
+	require.NoError(t, c.Connected(context.Background(), nodeID, version.CurrentApp))
+	c.peers.RegisterRequest(nodeID)
+	c.peers.RegisterResponse(nodeID, bandwidth)
+	c.peerStats[nodeID] = &peerStats{
+		latency:        safemath.NewAverager(latency, peerStatsHalflife, time.Now()),
+		bandwidth:      safemath.NewAverager(bandwidth, peerStatsHalflife, time.Now()),
+		requestLimiter: newRequestLimiter(),
+	}
+}
+
+func TestNetworkClientSelectPeer(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestNetworkClient(t)
+	_, ok := c.selectPeer()
+	require.False(ok)
+
+	// A peer that hasn't been sent a request is selected so that it's
+	// discovered.
+	newPeer := ids.GenerateTestNodeID()
+	require.NoError(c.Connected(context.Background(), newPeer, version.CurrentApp))
+	nodeID, ok := c.selectPeer()
+	require.True(ok)
+	require.Equal(newPeer, nodeID)
+	require.NoError(c.Disconnected(context.Background(), newPeer))
+
+	slowPeer := ids.GenerateTestNodeID()
+	fastPeer := ids.GenerateTestNodeID()
+	addTestPeer(t, c, slowPeer, 10, 1)
+	addTestPeer(t, c, fastPeer, 100, 1)
+
+	// The fastest peer is preferred.
+	nodeID, ok = c.selectPeer()
+	require.True(ok)
+	require.Equal(fastPeer, nodeID)
+
+	// Peers that are busy are penalized.
+	c.peerStats[fastPeer].numOutstandingRequests = 10
+	nodeID, ok = c.selectPeer()
+	require.True(ok)
+	require.Equal(slowPeer, nodeID)
+	c.peerStats[fastPeer].numOutstandingRequests = 0
+
+	// Peers that send invalid responses are benched.
+	c.RegisterInvalidResponse(fastPeer)
+	require.Equal(1, c.peerStats[fastPeer].numInvalidResponses)
+	require.True(c.peerStats[fastPeer].isBenched(time.Now()))
+	nodeID, ok = c.selectPeer()
+	require.True(ok)
+	require.Equal(slowPeer, nodeID)
+
+	// Benched peers are selected if there are no other peers.
+	require.NoError(c.Disconnected(context.Background(), slowPeer))
+	nodeID, ok = c.selectPeer()
+	require.True(ok)
+	require.Equal(fastPeer, nodeID)
+
+	// Once the bench expires, the peer's invalid responses are still
+	// penalized.
+	c.peerStats[fastPeer].benchedUntil = time.Time{}
+	require.Equal(float64(25), c.peerStats[fastPeer].score(time.Now()))
+}
+
+func TestNetworkClientRequestAnySpreadsRequests(t *testing.T) {
+	require := require.New(t)
+
+	ctrl := gomock.NewController(t)
+	sender := common.NewMockSender(ctrl)
+	sender.EXPECT().SendAppRequest(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	c := newTestNetworkClient(t)
+	c.appSender = sender
+	peer0 := ids.GenerateTestNodeID()
+	peer1 := ids.GenerateTestNodeID()
+	addTestPeer(t, c, peer0, 100, 1)
+	addTestPeer(t, c, peer1, 100, 1)
+
+	newRequest := func(uint32, uint32) ([]byte, error) {
+		return []byte{1}, nil
+	}
+
+	// The first request is counted before the second peer is selected, so the
+	// requests are sent to different peers.
+	nodeID0, _, _, err := c.sendRequestAny(context.Background(), newRequest)
+	require.NoError(err)
+	nodeID1, _, _, err := c.sendRequestAny(context.Background(), newRequest)
+	require.NoError(err)
+	require.NotEqual(nodeID0, nodeID1)
+}
+
+func TestNetworkClientSelectPeerLatency(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestNetworkClient(t)
+	slowPeer := ids.GenerateTestNodeID()
+	fastPeer := ids.GenerateTestNodeID()
+	addTestPeer(t, c, slowPeer, 100, 10)
+	addTestPeer(t, c, fastPeer, 100, 0)
+
+	// Of peers with the same bandwidth, the peer with the lowest latency is
+	// preferred.
+	nodeID, ok := c.selectPeer()
+	require.True(ok)
+	require.Equal(fastPeer, nodeID)
+}
+
+func TestNetworkClientReconnectedPeer(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestNetworkClient(t)
+	nodeID := ids.GenerateTestNodeID()
+	addTestPeer(t, c, nodeID, 100, 1)
+
+	oldStats := c.peerStats[nodeID]
+	oldStats.numOutstandingRequests++
+
+	// The peer reconnects before responding.
+	require.NoError(c.Disconnected(context.Background(), nodeID))
+	addTestPeer(t, c, nodeID, 100, 1)
+	newStats := c.peerStats[nodeID]
+
+	// The failure is recorded in the stats that the request was sent with,
+	// so the stats of the reconnected peer are unaffected.
+	c.registerFailure(oldStats)
+	require.Zero(oldStats.numOutstandingRequests)
+	require.Zero(newStats.numOutstandingRequests)
+	require.Equal(float64(50), newStats.score(time.Now()))
+}
+
+func TestNetworkClientRequestLimits(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestNetworkClient(t)
+	slowPeer := ids.GenerateTestNodeID()
+	fastPeer := ids.GenerateTestNodeID()
+	addTestPeer(t, c, slowPeer, 10, 1)
+	addTestPeer(t, c, fastPeer, 100, 1)
+
+	// Slow responses only lower the limits of the peer that sent them.
+	c.peerStats[slowPeer].numOutstandingRequests++
+	c.registerResponse(c.peerStats[slowPeer], 2*targetResponseDuration, 10)
+
+	keyLimit, bytesLimit := c.RequestLimits(slowPeer)
+	require.Equal(uint32(maxKeyValuesLimit/2), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit/2), bytesLimit)
+
+	keyLimit, bytesLimit = c.RequestLimits(fastPeer)
+	require.Equal(uint32(maxKeyValuesLimit), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit), bytesLimit)
+
+	// Peers that haven't been sent a request use the maximum limits.
+	keyLimit, bytesLimit = c.RequestLimits(ids.GenerateTestNodeID())
+	require.Equal(uint32(maxKeyValuesLimit), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit), bytesLimit)
+}
+
+func TestNetworkClientBenchDuration(t *testing.T) {
+	require := require.New(t)
+
+	c := newTestNetworkClient(t)
+	nodeID := ids.GenerateTestNodeID()
+	addTestPeer(t, c, nodeID, 1, 1)
+
+	stats := c.peerStats[nodeID]
+	now := time.Now()
+	c.RegisterInvalidResponse(nodeID)
+	require.WithinDuration(now.Add(initialBenchDuration), stats.benchedUntil, time.Second)
+
+	now = time.Now()
+	c.RegisterInvalidResponse(nodeID)
+	require.WithinDuration(now.Add(2*initialBenchDuration), stats.benchedUntil, time.Second)
+
+	for i := 0; i < 64; i++ {
+		c.RegisterInvalidResponse(nodeID)
+	}
+	now = time.Now()
+	c.RegisterInvalidResponse(nodeID)
+	require.WithinDuration(now.Add(maxBenchDuration), stats.benchedUntil, time.Second)
+}
@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/skychains/chain/utils/units"
+)
+
+const (
+	// Request limits are increased while responses take less than half of
+	// [targetResponseDuration] and decreased while they take longer than it.
+	targetResponseDuration = time.Second
+
+	// The factor that request limits are increased by after a fast response.
+	requestLimitIncreaseFactor = 1.25
+	// The most that request limits are decreased by after a slow or failed
+	// response.
+	requestLimitDecreaseFactor = 0.5
+
+	minRequestKeyLimit   = 64
+	minRequestBytesLimit = 64 * units.KiB
+)
+
+// requestLimiter adapts the key and byte limits of requests to a peer to the
+// observed throughput of the peer, so that requests to slow peers don't time
+// out and requests to fast peers aren't needlessly small.
+//
+// Limits start at their maximum and are multiplicatively decreased when
+// responses are slow or requests fail, and multiplicatively increased when
+// responses are fast.
+type requestLimiter struct {
+	lock       sync.Mutex
+	keyLimit   float64
+	bytesLimit float64
+}
+
+func newRequestLimiter() *requestLimiter {
+	return &requestLimiter{
+		keyLimit:   maxKeyValuesLimit,
+		bytesLimit: maxByteSizeLimit,
+	}
+}
+
+// Limits returns the key and byte limits that requests should use.
+func (r *requestLimiter) Limits() (uint32, uint32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return uint32(r.keyLimit), uint32(r.bytesLimit)
+}
+
+// RegisterResponse records that a response was received [elapsed] after its
+// request was sent, and returns the updated limits.
+func (r *requestLimiter) RegisterResponse(elapsed time.Duration) (uint32, uint32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	switch {
+	case elapsed < targetResponseDuration/2:
+		r.scale(requestLimitIncreaseFactor)
+	case elapsed > targetResponseDuration:
+		// Shrink the limits in proportion to how slow the response was, so
+		// that a response of the same throughput would take about
+		// [targetResponseDuration].
+		r.scale(max(
+			float64(targetResponseDuration)/float64(elapsed),
+			requestLimitDecreaseFactor,
+		))
+	}
+	return uint32(r.keyLimit), uint32(r.bytesLimit)
+}
+
+// RegisterFailure records that a request failed, and returns the updated
+// limits.
+func (r *requestLimiter) RegisterFailure() (uint32, uint32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.scale(requestLimitDecreaseFactor)
+	return uint32(r.keyLimit), uint32(r.bytesLimit)
+}
+
+// Assumes [r.lock] is held.
+func (r *requestLimiter) scale(factor float64) {
+	r.keyLimit = min(max(r.keyLimit*factor, minRequestKeyLimit), maxKeyValuesLimit)
+	r.bytesLimit = min(max(r.bytesLimit*factor, minRequestBytesLimit), maxByteSizeLimit)
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package sync
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLimiter(t *testing.T) {
+	require := require.New(t)
+
+	r := newRequestLimiter()
+	keyLimit, bytesLimit := r.Limits()
+	require.Equal(uint32(maxKeyValuesLimit), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit), bytesLimit)
+
+	// Limits can't be increased past their maximum.
+	keyLimit, bytesLimit = r.RegisterResponse(0)
+	require.Equal(uint32(maxKeyValuesLimit), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit), bytesLimit)
+
+	// A response that takes twice as long as the target halves the limits.
+	keyLimit, bytesLimit = r.RegisterResponse(2 * targetResponseDuration)
+	require.Equal(uint32(maxKeyValuesLimit/2), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit/2), bytesLimit)
+
+	// A response that takes about the target duration doesn't change the
+	// limits.
+	keyLimit, bytesLimit = r.RegisterResponse(targetResponseDuration)
+	require.Equal(uint32(maxKeyValuesLimit/2), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit/2), bytesLimit)
+
+	// A very slow response decreases the limits by at most
+	// [requestLimitDecreaseFactor].
+	keyLimit, bytesLimit = r.RegisterResponse(100 * targetResponseDuration)
+	require.Equal(uint32(maxKeyValuesLimit/4), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit/4), bytesLimit)
+
+	// A fast response increases the limits.
+	keyLimit, bytesLimit = r.RegisterResponse(0)
+	require.Equal(uint32(maxKeyValuesLimit/4*requestLimitIncreaseFactor), keyLimit)
+	require.Equal(uint32(maxByteSizeLimit/4*requestLimitIncreaseFactor), bytesLimit)
+
+	// Limits can't be decreased past their minimum.
+	for i := 0; i < 32; i++ {
+		keyLimit, bytesLimit = r.RegisterFailure()
+	}
+	require.Equal(uint32(minRequestKeyLimit), keyLimit)
+	require.Equal(uint32(minRequestBytesLimit), bytesLimit)
+
+	gotKeyLimit, gotBytesLimit := r.Limits()
+	require.Equal(keyLimit, gotKeyLimit)
+	require.Equal(bytesLimit, gotBytesLimit)
+}
@@ -917,6 +917,230 @@ func Test_Sync_Result_Correct_Root_With_Sync_Restart(t *testing.T) {
 	require.Equal(syncRoot, newRoot)
 }
 
+func Test_Sync_Result_Correct_Root_With_Checkpoint_Restart(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	syncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	checkpointDB := memdb.New()
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		CheckpointDB:          checkpointDB,
+		CheckpointFrequency:   time.Hour,
+	})
+	require.NoError(err)
+	require.NotNil(syncer)
+	require.NoError(syncer.Start(context.Background()))
+
+	// Wait until we've processed some work
+	// before restarting the sync.
+	require.Eventually(
+		func() bool {
+			syncer.workLock.Lock()
+			defer syncer.workLock.Unlock()
+
+			return syncer.processedWork.Len() > 0
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+
+	// The checkpoint isn't rewritten until [CheckpointFrequency] has passed.
+	_, checkpoint, err := getCheckpoint(checkpointDB)
+	require.NoError(err)
+	require.Empty(checkpoint.processed)
+
+	// The checkpoint is rewritten when the sync is closed.
+	syncer.Close()
+
+	checkpointRoot, checkpoint, err := getCheckpoint(checkpointDB)
+	require.NoError(err)
+	require.Equal(syncRoot, checkpointRoot)
+	require.NotEmpty(checkpoint.processed)
+
+	newSyncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                newCallthroughSyncClient(ctrl, dbToSync),
+		TargetRoot:            syncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		CheckpointDB:          checkpointDB,
+	})
+	require.NoError(err)
+	require.NotNil(newSyncer)
+
+	require.NoError(newSyncer.Start(context.Background()))
+
+	// The processed work is restored rather than fetched again.
+	newSyncer.workLock.Lock()
+	require.Positive(newSyncer.processedWork.Len())
+	newSyncer.workLock.Unlock()
+
+	require.NoError(newSyncer.Wait(context.Background()))
+	require.NoError(newSyncer.Error())
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(syncRoot, newRoot)
+
+	// The checkpoint is removed once the sync completes.
+	_, _, err = getCheckpoint(checkpointDB)
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
+func Test_Sync_Result_Correct_Root_With_Checkpoint_Restart_After_Update(t *testing.T) {
+	require := require.New(t)
+	ctrl := gomock.NewController(t)
+
+	now := time.Now().UnixNano()
+	t.Logf("seed: %d", now)
+	r := rand.New(rand.NewSource(now)) // #nosec G404
+	dbToSync, err := generateTrie(t, r, 3*maxKeyValuesLimit)
+	require.NoError(err)
+	firstSyncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	// Serve proofs of the requested root so that [dbToSync] can be changed
+	// while syncing.
+	client := NewMockClient(ctrl)
+	client.EXPECT().GetRangeProof(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetRangeProofRequest) (*merkledb.RangeProof, error) {
+			rootID, err := ids.ToID(request.RootHash)
+			if err != nil {
+				return nil, err
+			}
+			return dbToSync.GetRangeProofAtRoot(
+				ctx,
+				rootID,
+				maybeBytesToMaybe(request.StartKey),
+				maybeBytesToMaybe(request.EndKey),
+				int(request.KeyLimit),
+			)
+		}).AnyTimes()
+	client.EXPECT().GetChangeProof(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, request *pb.SyncGetChangeProofRequest, _ DB) (*merkledb.ChangeOrRangeProof, error) {
+			startRoot, err := ids.ToID(request.StartRootHash)
+			if err != nil {
+				return nil, err
+			}
+
+			endRoot, err := ids.ToID(request.EndRootHash)
+			if err != nil {
+				return nil, err
+			}
+
+			changeProof, err := dbToSync.GetChangeProof(ctx, startRoot, endRoot, maybeBytesToMaybe(request.StartKey), maybeBytesToMaybe(request.EndKey), int(request.KeyLimit))
+			if err != nil {
+				return nil, err
+			}
+			return &merkledb.ChangeOrRangeProof{
+				ChangeProof: changeProof,
+			}, nil
+		}).AnyTimes()
+
+	db, err := merkledb.New(
+		context.Background(),
+		memdb.New(),
+		newDefaultDBConfig(),
+	)
+	require.NoError(err)
+	checkpointDB := memdb.New()
+
+	syncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            firstSyncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		CheckpointDB:          checkpointDB,
+	})
+	require.NoError(err)
+	require.NotNil(syncer)
+	require.NoError(syncer.Start(context.Background()))
+
+	// Wait until we've processed some work
+	// before updating the sync target.
+	require.Eventually(
+		func() bool {
+			syncer.workLock.Lock()
+			defer syncer.workLock.Unlock()
+
+			return syncer.processedWork.Len() > 0
+		},
+		5*time.Second,
+		5*time.Millisecond,
+	)
+
+	for x := 0; x < 100; x++ {
+		key := make([]byte, r.Intn(50))
+		_, err = r.Read(key)
+		require.NoError(err)
+
+		val := make([]byte, r.Intn(50))
+		_, err = r.Read(val)
+		require.NoError(err)
+
+		require.NoError(dbToSync.Put(key, val))
+	}
+	secondSyncRoot, err := dbToSync.GetMerkleRoot(context.Background())
+	require.NoError(err)
+
+	require.NoError(syncer.UpdateSyncTarget(secondSyncRoot))
+	syncer.Close()
+
+	// The checkpoint is moved to the new target root.
+	checkpointRoot, _, err := getCheckpoint(checkpointDB)
+	require.NoError(err)
+	require.Equal(secondSyncRoot, checkpointRoot)
+	has, err := checkpointDB.Has(firstSyncRoot[:])
+	require.NoError(err)
+	require.False(has)
+
+	newSyncer, err := NewManager(ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		TargetRoot:            secondSyncRoot,
+		SimultaneousWorkLimit: 5,
+		Log:                   logging.NoLog{},
+		BranchFactor:          merkledb.BranchFactor16,
+		CheckpointDB:          checkpointDB,
+	})
+	require.NoError(err)
+	require.NotNil(newSyncer)
+
+	require.NoError(newSyncer.Start(context.Background()))
+	require.NoError(newSyncer.Wait(context.Background()))
+	require.NoError(newSyncer.Error())
+
+	newRoot, err := db.GetMerkleRoot(context.Background())
+	require.NoError(err)
+	require.Equal(secondSyncRoot, newRoot)
+
+	_, _, err = getCheckpoint(checkpointDB)
+	require.ErrorIs(err, database.ErrNotFound)
+}
+
 func Test_Sync_Error_During_Sync(t *testing.T) {
 	require := require.New(t)
 	ctrl := gomock.NewController(t)
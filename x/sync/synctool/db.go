@@ -0,0 +1,80 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/pflag"
+
+	"github.com/skychains/chain/database"
+	"github.com/skychains/chain/database/factory"
+	"github.com/skychains/chain/database/leveldb"
+	"github.com/skychains/chain/trace"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/utils/units"
+	"github.com/skychains/chain/x/merkledb"
+)
+
+const (
+	defaultAddress      = "127.0.0.1:9660"
+	defaultBranchFactor = merkledb.BranchFactor16
+	historyLength       = 300
+	cacheSize           = 64 * units.MiB
+	writeBufferSize     = 32 * units.MiB
+	writeBatchSize      = 256 * units.KiB
+)
+
+var errDBDirRequired = errors.New("--db-dir is required")
+
+// dbFlags are the flags that describe the merkledb being served or synced.
+type dbFlags struct {
+	dir          string
+	dbType       string
+	branchFactor uint
+	logLevel     string
+}
+
+func (f *dbFlags) register(flags *pflag.FlagSet) {
+	flags.StringVar(&f.dir, "db-dir", "", "Path to the directory of the database")
+	flags.StringVar(&f.dbType, "db-type", leveldb.Name, "Database type of the database")
+	flags.UintVar(&f.branchFactor, "branch-factor", uint(defaultBranchFactor), "Branch factor of the merkledb")
+	flags.StringVar(&f.logLevel, "log-level", logging.Warn.String(), "Level of the logs written to stderr")
+}
+
+// open opens the merkledb described by [f], and returns the database it's
+// stored in so that it can be closed after the merkledb.
+func (f *dbFlags) open(
+	ctx context.Context,
+	log logging.Logger,
+) (merkledb.MerkleDB, database.Database, error) {
+	if f.dir == "" {
+		return nil, nil, errDBDirRequired
+	}
+
+	reg := prometheus.NewRegistry()
+	baseDB, err := factory.New(f.dbType, f.dir, nil, log, reg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := merkledb.New(ctx, baseDB, merkledb.Config{
+		BranchFactor:                merkledb.BranchFactor(f.branchFactor),
+		Hasher:                      merkledb.DefaultHasher,
+		HistoryLength:               historyLength,
+		ValueNodeCacheSize:          cacheSize,
+		IntermediateNodeCacheSize:   cacheSize,
+		IntermediateWriteBufferSize: writeBufferSize,
+		IntermediateWriteBatchSize:  writeBatchSize,
+		Reg:                         reg,
+		TraceLevel:                  merkledb.NoTrace,
+		Tracer:                      trace.Noop,
+	})
+	if err != nil {
+		return nil, nil, errors.Join(err, baseDB.Close())
+	}
+	return db, baseDB, nil
+}
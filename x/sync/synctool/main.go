@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skychains/chain/utils/logging"
+)
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "synctool",
+		Short: "Serve and sync merkledb databases with x/sync, without running a node",
+	}
+	rootCmd.AddCommand(
+		newServeCmd(),
+		newSyncCmd(),
+	)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "synctool failed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func newLogger(level string) (logging.Logger, error) {
+	logLevel, err := logging.ToLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	return logging.NewLogger(
+		"synctool",
+		logging.NewWrappedCore(
+			logLevel,
+			os.Stderr,
+			logging.Colors.ConsoleEncoder(),
+		),
+	), nil
+}
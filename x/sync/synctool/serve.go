@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/spf13/cobra"
+
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/vms/rpcchainvm/grpcutils"
+	"github.com/skychains/chain/x/sync/g_network"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+func newServeCmd() *cobra.Command {
+	var (
+		dbFlags dbFlags
+		address string
+	)
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a merkledb to x/sync clients",
+		Long:  "Serve the range proofs and change proofs of a merkledb to x/sync clients over gRPC, with the same request handlers that a node uses to serve its peers. The database must not be open in another process. Change proofs can only be served for the roots the database has had since it was opened.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			log, err := newLogger(dbFlags.logLevel)
+			if err != nil {
+				return err
+			}
+			return serve(cmd.Context(), cmd.OutOrStdout(), log, &dbFlags, address)
+		},
+	}
+	dbFlags.register(cmd.Flags())
+	cmd.Flags().StringVar(&address, "address", defaultAddress, "Address to serve requests on")
+	return cmd
+}
+
+// serve serves the merkledb described by [dbFlags] on [address] until [ctx]
+// is canceled.
+func serve(
+	ctx context.Context,
+	out io.Writer,
+	log logging.Logger,
+	dbFlags *dbFlags,
+	address string,
+) (err error) {
+	db, baseDB, err := dbFlags.open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, db.Close(), baseDB.Close())
+	}()
+
+	root, err := db.GetMerkleRoot(ctx)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	server := grpcutils.NewServer()
+	pb.RegisterNetworkServer(server, gnetwork.NewNetworkServer(db, log))
+
+	fmt.Fprintf(out, "serving root %s on %s\n", root, listener.Addr())
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		server.GracefulStop()
+		return <-errChan
+	case err := <-errChan:
+		return err
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/skychains/chain/ids"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+)
+
+const (
+	rangeProofRequest  = "range proof"
+	changeProofRequest = "change proof"
+	unknownRequest     = "unknown"
+)
+
+// requestTypeStats are the statistics of the requests of a type.
+type requestTypeStats struct {
+	numRequests int
+	numFailed   int
+	numKeys     int
+	numBytes    int
+	latency     time.Duration
+}
+
+// requestStats prints the statistics of each request sent to the server, and
+// tracks the statistics of all of them.
+type requestStats struct {
+	out       io.Writer
+	startTime time.Time
+
+	lock          sync.Mutex
+	nextRequestID int
+	total         requestTypeStats
+	// request type -> statistics of the requests of that type
+	byType map[string]*requestTypeStats
+}
+
+func newRequestStats(out io.Writer) *requestStats {
+	return &requestStats{
+		out:       out,
+		startTime: time.Now(),
+		byType:    make(map[string]*requestTypeStats),
+	}
+}
+
+// intercept is a [grpc.UnaryClientInterceptor] that records the statistics of
+// each request.
+func (s *requestStats) intercept(
+	ctx context.Context,
+	method string,
+	req interface{},
+	reply interface{},
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	startTime := time.Now()
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	latency := time.Since(startTime)
+
+	var (
+		requestBytes  []byte
+		responseBytes []byte
+	)
+	if req, ok := req.(*pb.NetworkRequest); ok {
+		requestBytes = req.Request
+	}
+	if reply, ok := reply.(*pb.NetworkResponse); ok && err == nil {
+		responseBytes = reply.Response
+	}
+	s.record(requestBytes, responseBytes, latency, err)
+	return err
+}
+
+// record prints and tracks the statistics of a request that took [latency]
+// to be responded to with [responseBytes], or to fail with [err].
+func (s *requestStats) record(
+	requestBytes []byte,
+	responseBytes []byte,
+	latency time.Duration,
+	err error,
+) {
+	requestType, description, numKeys := describeRequest(requestBytes, responseBytes)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	requestID := s.nextRequestID
+	s.nextRequestID++
+
+	typeStats, ok := s.byType[requestType]
+	if !ok {
+		typeStats = &requestTypeStats{}
+		s.byType[requestType] = typeStats
+	}
+	for _, stats := range []*requestTypeStats{typeStats, &s.total} {
+		stats.numRequests++
+		stats.latency += latency
+		if err != nil {
+			stats.numFailed++
+			continue
+		}
+		stats.numKeys += numKeys
+		stats.numBytes += len(responseBytes)
+	}
+
+	if err != nil {
+		fmt.Fprintf(s.out, "request %d: %s (%s) failed after %s: %v\n", requestID, requestType, description, latency, err)
+		return
+	}
+	fmt.Fprintf(s.out, "request %d: %s (%s) responded with %d keys in %d bytes after %s\n", requestID, requestType, description, numKeys, len(responseBytes), latency)
+}
+
+// printProgress prints the root of the database being synced and the
+// statistics of all of the requests so far.
+func (s *requestStats) printProgress(localRoot ids.ID) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	elapsed := time.Since(s.startTime)
+	fmt.Fprintf(
+		s.out,
+		"progress: %s elapsed, local root %s, %d requests (%d failed), %d keys, %d bytes (%.0f bytes/s)\n",
+		elapsed.Round(time.Second),
+		localRoot,
+		s.total.numRequests,
+		s.total.numFailed,
+		s.total.numKeys,
+		s.total.numBytes,
+		float64(s.total.numBytes)/elapsed.Seconds(),
+	)
+}
+
+// printSummary prints the statistics of the requests of each type.
+func (s *requestStats) printSummary() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "type\trequests\tfailed\tkeys\tbytes\tavg latency\t")
+	for _, requestType := range []string{rangeProofRequest, changeProofRequest, unknownRequest} {
+		stats, ok := s.byType[requestType]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(
+			w,
+			"%s\t%d\t%d\t%d\t%d\t%s\t\n",
+			requestType,
+			stats.numRequests,
+			stats.numFailed,
+			stats.numKeys,
+			stats.numBytes,
+			stats.latency/time.Duration(stats.numRequests),
+		)
+	}
+	return w.Flush()
+}
+
+// describeRequest returns the type of the request [requestBytes], a
+// description of what it requested, and the number of keys in its response
+// [responseBytes].
+func describeRequest(requestBytes []byte, responseBytes []byte) (string, string, int) {
+	var req pb.Request
+	if err := proto.Unmarshal(requestBytes, &req); err != nil {
+		return unknownRequest, err.Error(), 0
+	}
+
+	switch req := req.GetMessage().(type) {
+	case *pb.Request_RangeProofRequest:
+		r := req.RangeProofRequest
+		description := fmt.Sprintf(
+			"root %x, key limit %d, bytes limit %d",
+			r.RootHash,
+			r.KeyLimit,
+			r.BytesLimit,
+		)
+
+		var resp pb.RangeProof
+		if err := proto.Unmarshal(responseBytes, &resp); err != nil {
+			return rangeProofRequest, description, 0
+		}
+		return rangeProofRequest, description, len(resp.KeyValues)
+	case *pb.Request_ChangeProofRequest:
+		r := req.ChangeProofRequest
+		description := fmt.Sprintf(
+			"roots %x to %x, key limit %d, bytes limit %d",
+			r.StartRootHash,
+			r.EndRootHash,
+			r.KeyLimit,
+			r.BytesLimit,
+		)
+
+		var resp pb.SyncGetChangeProofResponse
+		if err := proto.Unmarshal(responseBytes, &resp); err != nil {
+			return changeProofRequest, description, 0
+		}
+		// The server may respond with a range proof if it can't create a
+		// change proof.
+		if rangeProof := resp.GetRangeProof(); rangeProof != nil {
+			return changeProofRequest, description + ", served as a range proof", len(rangeProof.KeyValues)
+		}
+		return changeProofRequest, description, len(resp.GetChangeProof().GetKeyChanges())
+	default:
+		return unknownRequest, fmt.Sprintf("%T", req), 0
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (C) 2019-2024, Lux Partners Limited. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/cobra"
+
+	"github.com/skychains/chain/ids"
+	"github.com/skychains/chain/utils/logging"
+	"github.com/skychains/chain/vms/rpcchainvm/grpcutils"
+	"github.com/skychains/chain/x/merkledb"
+	"github.com/skychains/chain/x/sync/g_network"
+
+	pb "github.com/skychains/chain/proto/pb/sync"
+	xsync "github.com/skychains/chain/x/sync"
+)
+
+const metricsNamespace = "synctool"
+
+var errRootRequired = errors.New("--root is required")
+
+type syncFlags struct {
+	address               string
+	root                  string
+	requestTimeout        time.Duration
+	maxActiveRequests     int64
+	simultaneousWorkLimit int
+	progressInterval      time.Duration
+}
+
+func newSyncCmd() *cobra.Command {
+	var (
+		dbFlags   dbFlags
+		syncFlags syncFlags
+	)
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync a merkledb to a root from a server",
+		Long:  "Sync a merkledb to a root from a server started with the serve command, with the same x/sync client that a node uses to sync from its peers. The statistics of each request are printed as it completes, the progress of the sync is printed periodically, and a summary of the requests is printed once the sync completes.",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if syncFlags.root == "" {
+				return errRootRequired
+			}
+			root, err := ids.FromString(syncFlags.root)
+			if err != nil {
+				return fmt.Errorf("invalid --root: %w", err)
+			}
+
+			log, err := newLogger(dbFlags.logLevel)
+			if err != nil {
+				return err
+			}
+			return syncDB(cmd.Context(), cmd.OutOrStdout(), log, &dbFlags, &syncFlags, root)
+		},
+	}
+	dbFlags.register(cmd.Flags())
+	cmd.Flags().StringVar(&syncFlags.address, "address", defaultAddress, "Address of the server to sync from")
+	cmd.Flags().StringVar(&syncFlags.root, "root", "", "Root to sync the database to")
+	cmd.Flags().DurationVar(&syncFlags.requestTimeout, "request-timeout", 10*time.Second, "Time the server has to respond to each request")
+	cmd.Flags().Int64Var(&syncFlags.maxActiveRequests, "max-active-requests", 32, "Maximum number of requests that are sent to the server at once")
+	cmd.Flags().IntVar(&syncFlags.simultaneousWorkLimit, "simultaneous-work-limit", 8, "Maximum number of key ranges that are synced at once")
+	cmd.Flags().DurationVar(&syncFlags.progressInterval, "progress-interval", 5*time.Second, "How often the progress of the sync is printed")
+	return cmd
+}
+
+// syncDB syncs the merkledb described by [dbFlags] to [root] from the server
+// described by [syncFlags].
+func syncDB(
+	ctx context.Context,
+	out io.Writer,
+	log logging.Logger,
+	dbFlags *dbFlags,
+	syncFlags *syncFlags,
+	root ids.ID,
+) (err error) {
+	db, baseDB, err := dbFlags.open(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, db.Close(), baseDB.Close())
+	}()
+
+	stats := newRequestStats(out)
+	conn, err := grpcutils.Dial(
+		syncFlags.address,
+		grpcutils.WithChainUnaryInterceptor(stats.intercept),
+	)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, conn.Close())
+	}()
+
+	reg := prometheus.NewRegistry()
+	networkClient, err := gnetwork.NewNetworkClient(
+		ctx,
+		pb.NewNetworkClient(conn),
+		syncFlags.requestTimeout,
+		syncFlags.maxActiveRequests,
+		log,
+		metricsNamespace,
+		reg,
+	)
+	if err != nil {
+		return err
+	}
+
+	syncMetrics, err := xsync.NewMetrics(metricsNamespace, reg)
+	if err != nil {
+		return err
+	}
+
+	branchFactor := merkledb.BranchFactor(dbFlags.branchFactor)
+	client, err := xsync.NewClient(&xsync.ClientConfig{
+		NetworkClient: networkClient,
+		Log:           log,
+		Metrics:       syncMetrics,
+		BranchFactor:  branchFactor,
+	})
+	if err != nil {
+		return err
+	}
+
+	manager, err := xsync.NewManager(xsync.ManagerConfig{
+		DB:                    db,
+		Client:                client,
+		SimultaneousWorkLimit: syncFlags.simultaneousWorkLimit,
+		Log:                   log,
+		TargetRoot:            root,
+		BranchFactor:          branchFactor,
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "syncing to root %s from %s\n", root, syncFlags.address)
+	if err := manager.Start(ctx); err != nil {
+		return err
+	}
+	defer manager.Close()
+
+	doneChan := make(chan error, 1)
+	go func() {
+		doneChan <- manager.Wait(ctx)
+	}()
+
+	ticker := time.NewTicker(syncFlags.progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-doneChan:
+			if err != nil {
+				return err
+			}
+			stats.printProgress(root)
+			fmt.Fprintf(out, "synced to root %s\n", root)
+			return stats.printSummary()
+		case <-ticker.C:
+			localRoot, err := db.GetMerkleRoot(ctx)
+			if err != nil {
+				return err
+			}
+			stats.printProgress(localRoot)
+		}
+	}
+}
@@ -56,6 +56,7 @@ func Test_WorkHeap_Insert_GetWork(t *testing.T) {
 		[]*workItem{mediumPriorityItem, highPriorityItem, lowPriorityItem},
 		got,
 	)
+	require.Equal(got, h.Items())
 
 	// Ensure priorities are in right order.
 	gotItem := h.GetWork()